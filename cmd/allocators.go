@@ -0,0 +1,323 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"resourcemanagement.controlplane/pkg/cpudaemon"
+)
+
+// allocatorFactory constructs allocator selected with -allocator. Allocators register their factories
+// with registerAllocator in init() of their file, so that new allocators need no changes of getAllocator.
+type allocatorFactory struct {
+	name        string   // value of -allocator, followed by =ARG if the allocator takes an argument
+	description string   // one line description listed by -allocator=help
+	arg         string   // name of the required argument, eg. NUM_NAMESPACES, empty if there is none
+	options     []string // names of allocator specific options accepted by the allocator
+	topology    bool     // takes cpus from the topology, such allocators can be mixed by -namespace-allocators
+	stateless   bool     // keeps no bookkeeping beyond the state, so that it can be replaced without restart
+	create      func(arg string, args ctlParameters, cgroups cpudaemon.CgroupController) (cpudaemon.Allocator, error)
+}
+
+var allocators = map[string]allocatorFactory{}
+
+// registerAllocator adds allocator factory to the registry. Names shall be unique.
+func registerAllocator(f allocatorFactory) {
+	if _, ok := allocators[f.name]; ok {
+		panic(fmt.Sprintf("allocator %s registered twice", f.name))
+	}
+	allocators[f.name] = f
+}
+
+// sortedAllocators returns registered allocator factories ordered by name.
+func sortedAllocators() []allocatorFactory {
+	factories := make([]allocatorFactory, 0, len(allocators))
+	for _, f := range allocators {
+		factories = append(factories, f)
+	}
+	sort.Slice(factories, func(i, j int) bool {
+		return factories[i].name < factories[j].name
+	})
+	return factories
+}
+
+// allocatorOptions returns names of allocator specific options of all registered allocators.
+func allocatorOptions() map[string]struct{} {
+	options := map[string]struct{}{}
+	for _, f := range allocators {
+		for _, o := range f.options {
+			options[o] = struct{}{}
+		}
+	}
+	return options
+}
+
+// printAllocators writes registered allocators with their arguments and options, for -allocator=help.
+func printAllocators(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ALLOCATOR\tOPTIONS\tDESCRIPTION")
+	for _, f := range sortedAllocators() {
+		name := f.name
+		if f.arg != "" {
+			name += "=" + f.arg
+		}
+		options := make([]string, 0, len(f.options))
+		for _, o := range f.options {
+			options = append(options, "-"+o)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", name, strings.Join(options, " "), f.description)
+	}
+	tw.Flush()
+}
+
+// lookupAllocator returns factory of allocator given as -allocator value, eg. numa-namespace=2, and
+// its argument. Allocator specific options set to non-default values shall be accepted by the allocator.
+func lookupAllocator(allocator string, flags *flag.FlagSet) (allocatorFactory, string, error) {
+	name, arg, hasArg := strings.Cut(allocator, "=")
+	f, ok := allocators[name]
+	if !ok {
+		return f, "", usageErrorf("unknown allocator %s, -allocator=help lists available allocators", allocator)
+	}
+	if f.arg != "" && !hasArg {
+		return f, "", usageErrorf("allocator %s requires argument, format is %s=%s", name, name, f.arg)
+	}
+	if f.arg == "" && hasArg {
+		return f, "", usageErrorf("allocator %s takes no argument", name)
+	}
+
+	accepted := map[string]struct{}{}
+	for _, o := range f.options {
+		accepted[o] = struct{}{}
+	}
+	for option := range allocatorOptions() {
+		fl := flags.Lookup(option)
+		if fl == nil || fl.Value.String() == fl.DefValue {
+			continue
+		}
+		if _, ok := accepted[option]; !ok {
+			return f, "", usageErrorf(
+				"option '%s' is not available for %s allocator, -allocator=help lists options of allocators",
+				option,
+				name,
+			)
+		}
+	}
+	return f, arg, nil
+}
+
+func getAllocator(args ctlParameters) (cpudaemon.Allocator, error) {
+	cgroupController, err := getCgroupController(args)
+	if err != nil {
+		return nil, err
+	}
+	f, arg, err := lookupAllocator(args.allocator, flag.CommandLine)
+	if err != nil {
+		return nil, err
+	}
+	return f.create(arg, args, cgroupController)
+}
+
+// getNamespaceAllocators creates allocators selectable by namespace configurations, given with
+// -namespace-allocators as comma separated -allocator values, by allocator name. The allocators and the
+// allocator of the daemon shall all take cpus from the topology, so that they do not allocate same cpus.
+func getNamespaceAllocators(args ctlParameters) (map[string]cpudaemon.Allocator, error) {
+	if args.nsAllocators == "" {
+		return nil, nil
+	}
+	daemonAllocator, _, _ := strings.Cut(args.allocator, "=")
+	if f, ok := allocators[daemonAllocator]; ok && !f.topology {
+		return nil, usageErrorf("namespace allocators cannot be mixed with %s allocator", daemonAllocator)
+	}
+	cgroupController, err := getCgroupController(args)
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]cpudaemon.Allocator{}
+	for _, allocator := range strings.Split(args.nsAllocators, ",") {
+		f, arg, err := lookupAllocator(strings.TrimSpace(allocator), flag.CommandLine)
+		if err != nil {
+			return nil, err
+		}
+		if !f.topology {
+			return nil, usageErrorf("%s allocator cannot be mixed with other allocators", f.name)
+		}
+		if _, ok := res[f.name]; ok || f.name == daemonAllocator {
+			return nil, usageErrorf("namespace allocator %s given twice or as -allocator", f.name)
+		}
+		if res[f.name], err = f.create(arg, args, cgroupController); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// getCgroupController returns cgroup controller of allocators, for -runtime and -cgroup-driver.
+func getCgroupController(args ctlParameters) (cpudaemon.CgroupController, error) {
+	cR, err := parseRuntime(args.runtime)
+	if err != nil {
+		return nil, err
+	}
+	driver, err := parseCGroupDriver(args.cgroupDriver)
+	if err != nil {
+		return nil, err
+	}
+
+	var cgroupOpts []cpudaemon.CgroupOption
+	if args.partitions {
+		cgroupOpts = append(cgroupOpts, cpudaemon.WithCpusetPartitions())
+	}
+	if cR == cpudaemon.Kind {
+		kubepods, err := cpudaemon.DetectKindKubepodsCgroup(args.cgroupPath, cpudaemon.DetectCgroupVersion())
+		if err != nil {
+			return nil, err
+		}
+		args.logger.Info("kind kubepods cgroup detected", "cgroup", kubepods)
+		cgroupOpts = append(cgroupOpts, cpudaemon.WithKindKubepodsCgroup(kubepods))
+	}
+	return cpudaemon.NewCgroupController(cR, driver, args.logger, cgroupOpts...), nil
+}
+
+// parseSMTPolicy checks if -smt-policy makes the numa allocator take whole physical cores.
+func parseSMTPolicy(policy string) (bool, error) {
+	switch policy {
+	case "none":
+		return false, nil
+	case "full-core":
+		return true, nil
+	default:
+		return false, usageErrorf("unknown smt policy %s, available are: none, full-core", policy)
+	}
+}
+
+// parseNumNamespaces returns number of namespaces given as argument of numa-namespace allocators.
+func parseNumNamespaces(arg string) (int, error) {
+	numNamespaces, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, usageErrorf("cannot read number of namespaces %s. format is [0-9]+", arg)
+	}
+	if numNamespaces <= 0 {
+		return 0, usageErrorf("number of namespaces must be greater than 0. it is %d", numNamespaces)
+	}
+	return numNamespaces, nil
+}
+
+// newNamespaceAllocator returns numa-namespace allocator configured with namespace specific options.
+func newNamespaceAllocator(
+	args ctlParameters,
+	arg string,
+	cgroupController cpudaemon.CgroupController,
+	exclusive bool,
+) (cpudaemon.Allocator, error) {
+	numNamespaces, err := parseNumNamespaces(arg)
+	if err != nil {
+		return nil, err
+	}
+	a := cpudaemon.NewNumaPerNamespaceAllocator(
+		numNamespaces,
+		cgroupController,
+		exclusive,
+		args.memoryPinning,
+		args.logger,
+	)
+	if args.softPinning {
+		a.EnableBurstableSoftPinning()
+	}
+	if args.bucketWeights {
+		a.EnableBucketWeights()
+	}
+	return withNamespaceMemoryNodes(a, args.namespaceMems)
+}
+
+// withNamespaceMemoryNodes sets memory nodes of namespaces given as semicolon separated list of
+// namespace=nodes pairs, eg. "team-a=0;team-b=1,3".
+func withNamespaceMemoryNodes(
+	a *cpudaemon.NumaPerNamespaceAllocator,
+	namespaceMems string,
+) (cpudaemon.Allocator, error) {
+	if namespaceMems == "" {
+		return a, nil
+	}
+	nodes := map[string]string{}
+	for _, entry := range strings.Split(namespaceMems, ";") {
+		namespace, mems, ok := strings.Cut(entry, "=")
+		if !ok || namespace == "" {
+			return nil, usageErrorf("invalid namespace memory nodes %s, expected namespace=nodes", entry)
+		}
+		nodeSet, err := cpudaemon.CPUSetFromString(mems)
+		if err != nil || nodeSet.Count() == 0 {
+			return nil, usageErrorf("invalid memory nodes of namespace %s: %s", namespace, mems)
+		}
+		nodes[namespace] = nodeSet.ToCpuString()
+	}
+	a.SetNamespaceMemoryNodes(nodes)
+	return a, nil
+}
+
+func init() {
+	namespaceOptions := []string{"mem", "namespace-mems", "burstable-soft-pinning", "bucket-cpu-weights"}
+
+	registerAllocator(allocatorFactory{
+		name:        "default",
+		description: "exclusive cpus of guaranteed containers taken sequentially from available cpus",
+		create: func(_ string, _ ctlParameters, cgroups cpudaemon.CgroupController) (cpudaemon.Allocator, error) {
+			return cpudaemon.NewDefaultAllocator(cgroups), nil
+		},
+	})
+	registerAllocator(allocatorFactory{
+		name:        "numa",
+		description: "exclusive cpus of guaranteed containers with minimal topology distance",
+		options:     []string{"mem", "numa-placement", "smt-policy"},
+		topology:    true,
+		stateless:   true,
+		create: func(_ string, args ctlParameters, cgroups cpudaemon.CgroupController) (cpudaemon.Allocator, error) {
+			placement, err := parseNumaPlacement(args.numaPlacement)
+			if err != nil {
+				return nil, err
+			}
+			fullCores, err := parseSMTPolicy(args.smtPolicy)
+			if err != nil {
+				return nil, err
+			}
+			a := cpudaemon.NewNumaAwareAllocatorWithPlacement(cgroups, args.memoryPinning, placement)
+			if fullCores {
+				a.EnableFullCores()
+			}
+			return a, nil
+		},
+	})
+	registerAllocator(allocatorFactory{
+		name:        "scatter",
+		description: "exclusive cpus of guaranteed containers spread evenly across numa nodes",
+		options:     []string{"mem"},
+		topology:    true,
+		stateless:   true,
+		create: func(_ string, args ctlParameters, cgroups cpudaemon.CgroupController) (cpudaemon.Allocator, error) {
+			return cpudaemon.NewScatterAllocator(cgroups, args.memoryPinning), nil
+		},
+	})
+	registerAllocator(allocatorFactory{
+		name:        "numa-namespace",
+		description: "each namespace isolated in separate numa nodes",
+		arg:         "NUM_NAMESPACES",
+		options:     namespaceOptions,
+		topology:    true,
+		create: func(arg string, args ctlParameters, cgroups cpudaemon.CgroupController) (cpudaemon.Allocator, error) {
+			return newNamespaceAllocator(args, arg, cgroups, false)
+		},
+	})
+	registerAllocator(allocatorFactory{
+		name:        "numa-namespace-exclusive",
+		description: "numa-namespace with cpus of guaranteed containers not shared with other containers",
+		arg:         "NUM_NAMESPACES",
+		options:     namespaceOptions,
+		topology:    true,
+		create: func(arg string, args ctlParameters, cgroups cpudaemon.CgroupController) (cpudaemon.Allocator, error) {
+			return newNamespaceAllocator(args, arg, cgroups, true)
+		},
+	})
+}
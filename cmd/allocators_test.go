@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/cpudaemon"
+)
+
+// allocatorFlags returns flag set with allocator specific options, as declared by main.
+func allocatorFlags() *flag.FlagSet {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Bool("mem", false, "")
+	flags.String("numa-placement", "distance", "")
+	flags.String("smt-policy", "none", "")
+	flags.String("namespace-mems", "", "")
+	flags.Bool("burstable-soft-pinning", false, "")
+	flags.Bool("bucket-cpu-weights", false, "")
+	return flags
+}
+
+func TestLookupAllocator(t *testing.T) {
+	f, arg, err := lookupAllocator("numa-namespace=2", allocatorFlags())
+
+	require.Nil(t, err)
+	assert.Equal(t, "numa-namespace", f.name)
+	assert.Equal(t, "2", arg)
+}
+
+func TestLookupAllocatorFails(t *testing.T) {
+	for _, allocator := range []string{"unknown", "numa-namespace", "numa=2"} {
+		_, _, err := lookupAllocator(allocator, allocatorFlags())
+
+		assert.Equal(t, exitUsage, exitCode(err), allocator)
+	}
+}
+
+func TestLookupAllocatorRejectsOptionsOfOtherAllocators(t *testing.T) {
+	flags := allocatorFlags()
+	require.Nil(t, flags.Parse([]string{"-numa-placement", "pack"}))
+
+	_, _, err := lookupAllocator("numa-namespace=2", flags)
+
+	assert.Equal(t, exitUsage, exitCode(err))
+	_, _, err = lookupAllocator("scatter", flags)
+	assert.Equal(t, exitUsage, exitCode(err))
+	_, _, err = lookupAllocator("numa", flags)
+	assert.Nil(t, err)
+}
+
+func TestParseSMTPolicy(t *testing.T) {
+	fullCores, err := parseSMTPolicy("full-core")
+	require.Nil(t, err)
+	assert.True(t, fullCores)
+	fullCores, err = parseSMTPolicy("none")
+	require.Nil(t, err)
+	assert.False(t, fullCores)
+	_, err = parseSMTPolicy("half-core")
+	assert.Equal(t, exitUsage, exitCode(err))
+}
+
+func TestCreateNumaAllocatorWithFullCores(t *testing.T) {
+	args := validParameters()
+	args.smtPolicy = "full-core"
+
+	a, err := allocators["numa"].create("", args, nil)
+
+	require.Nil(t, err)
+	config, ok := cpudaemon.NewStaticPolocy(a).AllocatorConfig(&cpudaemon.DaemonState{})
+	require.True(t, ok)
+	assert.True(t, config.FullCores)
+}
+
+func TestParseNumNamespaces(t *testing.T) {
+	n, err := parseNumNamespaces("3")
+	require.Nil(t, err)
+	assert.Equal(t, 3, n)
+
+	for _, arg := range []string{"", "two", "0", "-1"} {
+		_, err := parseNumNamespaces(arg)
+		assert.Equal(t, exitUsage, exitCode(err), arg)
+	}
+}
+
+func TestWithNamespaceMemoryNodes(t *testing.T) {
+	a := cpudaemon.NewNumaPerNamespaceAllocator(2, nil, false, false, validParameters().logger)
+
+	_, err := withNamespaceMemoryNodes(a, "team-a=0;team-b=1,3")
+	assert.Nil(t, err)
+
+	for _, mems := range []string{"team-a", "=0", "team-a=x", "team-a="} {
+		_, err := withNamespaceMemoryNodes(a, mems)
+		assert.Equal(t, exitUsage, exitCode(err), mems)
+	}
+}
+
+func TestCreateNamespaceAllocatorFailsForInvalidArgument(t *testing.T) {
+	_, err := allocators["numa-namespace"].create("x", validParameters(), nil)
+
+	assert.Equal(t, exitUsage, exitCode(err))
+}
+
+func TestGetNamespaceAllocators(t *testing.T) {
+	args := validParameters()
+	args.allocator = "numa"
+	args.nsAllocators = "scatter, numa-namespace-exclusive=2"
+
+	res, err := getNamespaceAllocators(args)
+
+	require.Nil(t, err)
+	assert.IsType(t, &cpudaemon.ScatterAllocator{}, res["scatter"])
+	assert.IsType(t, &cpudaemon.NumaPerNamespaceAllocator{}, res["numa-namespace-exclusive"])
+	args.nsAllocators = ""
+	res, err = getNamespaceAllocators(args)
+	require.Nil(t, err)
+	assert.Nil(t, res)
+}
+
+func TestGetNamespaceAllocatorsFails(t *testing.T) {
+	for daemonAllocator, nsAllocators := range map[string]string{
+		"default":                    "scatter",
+		"numa":                       "default",
+		"scatter":                    "scatter",
+		"numa-namespace=2":           "numa-namespace=4",
+		"numa-namespace-exclusive=2": "numa,numa",
+	} {
+		args := validParameters()
+		args.allocator, args.nsAllocators = daemonAllocator, nsAllocators
+
+		_, err := getNamespaceAllocators(args)
+
+		assert.Equal(t, exitUsage, exitCode(err), nsAllocators)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"resourcemanagement.controlplane/pkg/cpudaemon"
+)
+
+// reloadableOptions returns names of options which can be given in the -config file: the allocator with
+// its options and reserved cpus, applied by the daemon, and the namespace prefix, applied by the agent.
+func reloadableOptions() map[string]struct{} {
+	options := allocatorOptions()
+	for _, o := range []string{"allocator", "reserved-cpus", "namespace-prefix"} {
+		options[o] = struct{}{}
+	}
+	return options
+}
+
+// configFile applies options of the -config file over the command line ones. Command line values of the
+// options are remembered, so that options removed from the file get them back when the file is reloaded.
+type configFile struct {
+	path    string
+	flags   *flag.FlagSet     // flags bound to parameters of the process
+	args    *ctlParameters    // parameters the flags are bound to
+	cmdline map[string]string // command line values of reloadable options
+}
+
+func newConfigFile(path string, flags *flag.FlagSet, args *ctlParameters) *configFile {
+	c := &configFile{path: path, flags: flags, args: args, cmdline: map[string]string{}}
+	for name := range reloadableOptions() {
+		if f := flags.Lookup(name); f != nil {
+			c.cmdline[name] = f.Value.String()
+		}
+	}
+	return c
+}
+
+// load reads the file and returns parameters with its options applied. The file lists options as
+// name=value, one per line, eg. allocator=numa; empty lines and lines starting with # are skipped.
+func (c *configFile) load() (ctlParameters, error) {
+	b, err := os.ReadFile(c.path)
+	if err != nil {
+		return ctlParameters{}, err
+	}
+	reloadable := reloadableOptions()
+	values := map[string]string{}
+	for i, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return ctlParameters{}, usageErrorf("%s:%d: expected name=value, got %q", c.path, i+1, line)
+		}
+		name = strings.TrimLeft(strings.TrimSpace(name), "-")
+		if _, ok := reloadable[name]; !ok {
+			return ctlParameters{}, usageErrorf("%s:%d: option %s cannot be given in config file", c.path, i+1, name)
+		}
+		values[name] = strings.TrimSpace(value)
+	}
+	for name, value := range c.cmdline {
+		if v, ok := values[name]; ok {
+			value = v
+		}
+		if err := c.flags.Set(name, value); err != nil {
+			return ctlParameters{}, usageErrorf("%s: invalid value %q of option %s: %v", c.path, value, name, err)
+		}
+	}
+	return *c.args, nil
+}
+
+// watchConfig applies the config file whenever hangup receives. Failed reloads are logged, the
+// configuration applied before stays in effect then.
+func watchConfig(c *configFile, hangup <-chan struct{}, apply func(ctlParameters) error, logger logr.Logger) {
+	for range hangup {
+		args, err := c.load()
+		if err == nil {
+			err = apply(args)
+		}
+		if err != nil {
+			logger.Error(err, "cannot reload configuration", "path", c.path)
+			continue
+		}
+		logger.Info("configuration reloaded", "path", c.path)
+	}
+}
+
+// reloadableDaemon is the daemon as seen by daemonReloader.
+type reloadableDaemon interface {
+	Reload(config cpudaemon.ReloadConfig) error
+}
+
+// daemonReloader applies reloaded parameters to the running daemon: the default allocator is replaced
+// if it or its options changed, reserved cpus are applied always unless kubepods cpuset is managed.
+type daemonReloader struct {
+	daemon    reloadableDaemon
+	flags     *flag.FlagSet
+	allocator string // -allocator value of the running allocator
+	key       string // allocatorKey of the running allocator
+}
+
+func newDaemonReloader(daemon reloadableDaemon, flags *flag.FlagSet, args ctlParameters) *daemonReloader {
+	return &daemonReloader{daemon: daemon, flags: flags, allocator: args.allocator, key: allocatorKey(args, flags)}
+}
+
+// allocatorKey identifies the allocator created from the parameters: -allocator value and values of
+// allocator specific options.
+func allocatorKey(args ctlParameters, flags *flag.FlagSet) string {
+	options := []string{}
+	for name := range allocatorOptions() {
+		if f := flags.Lookup(name); f != nil {
+			options = append(options, name+"="+f.Value.String())
+		}
+	}
+	sort.Strings(options)
+	return strings.Join(append([]string{args.allocator}, options...), " ")
+}
+
+func (r *daemonReloader) apply(args ctlParameters) error {
+	config := cpudaemon.ReloadConfig{ReservedCPUs: cpudaemon.CPUSet{}}
+	if args.kubepodsCpus != "" {
+		config.ReservedCPUs = nil // reserved cpus of managed kubepods cpuset are fixed at startup
+	}
+	if args.reservedCpus != "" {
+		cpus, err := cpudaemon.CPUSetFromString(args.reservedCpus)
+		if err != nil {
+			return usageErrorf("cannot parse reserved cpus %s: %v", args.reservedCpus, err)
+		}
+		config.ReservedCPUs = cpus
+	}
+	key := allocatorKey(args, r.flags)
+	if key != r.key {
+		policy, err := r.policy(args)
+		if err != nil {
+			return err
+		}
+		config.Policy = policy
+	}
+	if err := r.daemon.Reload(config); err != nil {
+		return err
+	}
+	r.allocator, r.key = args.allocator, key
+	return nil
+}
+
+// policy returns static policy of the reloaded allocator. Allocators keeping no bookkeeping beyond the
+// state can replace each other, as pods keeping the replaced allocator are managed by the new one after
+// restart. Other allocators can only be changed by restart: the default allocator keeps its cpus apart
+// and namespace allocators keep buckets of namespaces, which a new allocator would not know.
+func (r *daemonReloader) policy(args ctlParameters) (cpudaemon.Policy, error) {
+	name, _, _ := strings.Cut(r.allocator, "=")
+	prev := allocators[name]
+	f, arg, err := lookupAllocator(args.allocator, r.flags)
+	if err != nil {
+		return nil, err
+	}
+	if !f.stateless || !prev.stateless {
+		return nil, usageErrorf("allocator %s cannot be replaced by %s without restart", prev.name, f.name)
+	}
+	if _, err := getNamespaceAllocators(args); err != nil {
+		return nil, err
+	}
+	cgroupController, err := getCgroupController(args)
+	if err != nil {
+		return nil, err
+	}
+	allocator, err := f.create(arg, args, cgroupController)
+	if err != nil {
+		return nil, err
+	}
+	return cpudaemon.NewStaticPolocy(allocator), nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/cpudaemon"
+)
+
+// configFlags returns flag set with reloadable options and dport bound to the parameters, as declared by
+// main.
+func configFlags(args *ctlParameters) *flag.FlagSet {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.StringVar(&args.allocator, "allocator", "default", "")
+	flags.StringVar(&args.reservedCpus, "reserved-cpus", "", "")
+	flags.StringVar(&args.namespacePrefix, "namespace-prefix", "", "")
+	flags.BoolVar(&args.memoryPinning, "mem", false, "")
+	flags.StringVar(&args.numaPlacement, "numa-placement", "distance", "")
+	flags.StringVar(&args.smtPolicy, "smt-policy", "none", "")
+	flags.StringVar(&args.namespaceMems, "namespace-mems", "", "")
+	flags.BoolVar(&args.softPinning, "burstable-soft-pinning", false, "")
+	flags.BoolVar(&args.bucketWeights, "bucket-cpu-weights", false, "")
+	flags.IntVar(&args.daemonPort, "dport", defaultDaemonPort, "")
+	return flags
+}
+
+func writeConfigFile(t *testing.T, path string, content string) {
+	require.Nil(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestConfigFileLoad(t *testing.T) {
+	args := validParameters()
+	flags := configFlags(&args)
+	require.Nil(t, flags.Parse([]string{"-allocator", "numa", "-reserved-cpus", "0"}))
+	path := filepath.Join(t.TempDir(), "ctlplane.conf")
+	writeConfigFile(t, path, "# reloaded on SIGHUP\n\nallocator=scatter\n-namespace-prefix = test-\n")
+	c := newConfigFile(path, flags, &args)
+
+	loaded, err := c.load()
+
+	require.Nil(t, err)
+	assert.Equal(t, "scatter", loaded.allocator)
+	assert.Equal(t, "0", loaded.reservedCpus)
+	assert.Equal(t, "test-", loaded.namespacePrefix)
+
+	writeConfigFile(t, path, "reserved-cpus=0-1\n")
+	loaded, err = c.load()
+
+	require.Nil(t, err)
+	assert.Equal(t, "numa", loaded.allocator, "options removed from the file get command line values back")
+	assert.Equal(t, "0-1", loaded.reservedCpus)
+	assert.Empty(t, loaded.namespacePrefix)
+}
+
+func TestConfigFileLoadFails(t *testing.T) {
+	for name, content := range map[string]string{
+		"not reloadable": "dport=1",
+		"no value":       "allocator",
+		"invalid value":  "mem=maybe",
+	} {
+		args := validParameters()
+		path := filepath.Join(t.TempDir(), "ctlplane.conf")
+		writeConfigFile(t, path, content)
+
+		_, err := newConfigFile(path, configFlags(&args), &args).load()
+
+		assert.Equal(t, exitUsage, exitCode(err), name)
+	}
+
+	args := validParameters()
+	_, err := newConfigFile(filepath.Join(t.TempDir(), "missing"), configFlags(&args), &args).load()
+	assert.NotNil(t, err)
+}
+
+type fakeReloadableDaemon struct {
+	reloads []cpudaemon.ReloadConfig
+	err     error
+}
+
+func (d *fakeReloadableDaemon) Reload(config cpudaemon.ReloadConfig) error {
+	d.reloads = append(d.reloads, config)
+	return d.err
+}
+
+func TestDaemonReloaderReplacesChangedAllocator(t *testing.T) {
+	args := validParameters()
+	flags := configFlags(&args)
+	require.Nil(t, flags.Parse([]string{"-allocator", "numa"}))
+	daemon := fakeReloadableDaemon{}
+	r := newDaemonReloader(&daemon, flags, args)
+
+	args.reservedCpus = "0-1"
+	require.Nil(t, r.apply(args))
+	require.Nil(t, flags.Set("allocator", "scatter"))
+	require.Nil(t, r.apply(args))
+	require.Nil(t, r.apply(args))
+	require.Nil(t, flags.Set("smt-policy", "full-core"))
+	require.Nil(t, flags.Set("allocator", "numa"))
+	require.Nil(t, r.apply(args))
+
+	require.Len(t, daemon.reloads, 4)
+	assert.Equal(t, "0,1", daemon.reloads[0].ReservedCPUs.ToCpuString())
+	assert.Nil(t, daemon.reloads[0].Policy, "unchanged allocator is kept")
+	assert.NotNil(t, daemon.reloads[1].Policy)
+	assert.Nil(t, daemon.reloads[2].Policy)
+	assert.NotNil(t, daemon.reloads[3].Policy, "allocator is replaced when its options change")
+}
+
+func TestDaemonReloaderKeepsReservedCpusOfKubepodsCpuset(t *testing.T) {
+	args := validParameters()
+	flags := configFlags(&args)
+	daemon := fakeReloadableDaemon{}
+	r := newDaemonReloader(&daemon, flags, args)
+
+	args.kubepodsCpus = "0"
+	require.Nil(t, r.apply(args))
+
+	require.Len(t, daemon.reloads, 1)
+	assert.Nil(t, daemon.reloads[0].ReservedCPUs)
+}
+
+func TestDaemonReloaderRetriesFailedReload(t *testing.T) {
+	args := validParameters()
+	flags := configFlags(&args)
+	require.Nil(t, flags.Parse([]string{"-allocator", "numa"}))
+	daemon := fakeReloadableDaemon{err: errors.New("cpus in use")}
+	r := newDaemonReloader(&daemon, flags, args)
+
+	require.Nil(t, flags.Set("allocator", "scatter"))
+	assert.NotNil(t, r.apply(args))
+	daemon.err = nil
+	require.Nil(t, r.apply(args))
+
+	require.Len(t, daemon.reloads, 2)
+	assert.NotNil(t, daemon.reloads[1].Policy)
+	assert.Equal(t, "scatter", r.allocator)
+}
+
+func TestDaemonReloaderFails(t *testing.T) {
+	for _, tc := range []struct {
+		from string
+		to   []string
+	}{
+		{"default", []string{"-allocator", "numa"}},
+		{"numa", []string{"-allocator", "default"}},
+		{"numa", []string{"-allocator", "numa-namespace=2"}},
+		{"numa-namespace=2", []string{"-allocator", "scatter"}},
+		{"numa-namespace=2", []string{"-burstable-soft-pinning"}},
+		{"scatter", []string{"-allocator", "unknown"}},
+		{"numa-namespace=2", []string{"-reserved-cpus", "x"}},
+	} {
+		args := validParameters()
+		flags := configFlags(&args)
+		require.Nil(t, flags.Parse([]string{"-allocator", tc.from}))
+		daemon := fakeReloadableDaemon{}
+		r := newDaemonReloader(&daemon, flags, args)
+		require.Nil(t, flags.Parse(tc.to))
+
+		assert.Equal(t, exitUsage, exitCode(r.apply(args)), tc.from, tc.to)
+		assert.Empty(t, daemon.reloads, tc.from, tc.to)
+	}
+}
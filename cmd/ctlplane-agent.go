@@ -2,47 +2,121 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 
 	"github.com/go-logr/logr"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/klog/v2"
 	"resourcemanagement.controlplane/pkg/agent"
+	"resourcemanagement.controlplane/pkg/client"
+	"resourcemanagement.controlplane/pkg/cpudaemon"
 	"resourcemanagement.controlplane/pkg/ctlplaneapi"
 )
 
-func runAgent(daemonPort int, nodeName string, namespacePrefix string, logger logr.Logger) {
+func parseStaticPodsPolicy(policy string) (agent.StaticPodsPolicy, error) {
+	val, ok := map[string]agent.StaticPodsPolicy{
+		"pin":  agent.StaticPodsPin,
+		"skip": agent.StaticPodsSkip,
+	}[policy]
+	if !ok {
+		return val, usageErrorf("unknown static pods policy %s", policy)
+	}
+	return val, nil
+}
+
+// getAgentOptions returns options of the agent pod informer and of pods served by the agent.
+func getAgentOptions(args ctlParameters) ([]agent.Option, error) {
+	if args.informerResync < 0 {
+		return nil, usageErrorf("informer resync period shall not be negative, got %s", args.informerResync)
+	}
+	excluded, err := labels.ConvertSelectorToLabelsMap(args.excludeLabels)
+	if err != nil {
+		return nil, usageErrorf("cannot parse excluded labels %q: %v", args.excludeLabels, err)
+	}
+	staticPods, err := parseStaticPodsPolicy(args.staticPods)
+	if err != nil {
+		return nil, err
+	}
+	return []agent.Option{
+		agent.WithInformerResync(args.informerResync),
+		agent.WithLabelSelector(args.podSelector),
+		agent.WithExcludedLabels(excluded),
+		agent.WithStaticPods(staticPods),
+	}, nil
+}
+
+func runAgent(
+	daemonAddr string,
+	nodeName string,
+	namespacePrefix string,
+	selfCpus string,
+	namespaceConfigs bool,
+	optionsFile *configFile,
+	agentOptions []agent.Option,
+	channelOptions ctlplaneapi.ChannelOptions,
+	logger logr.Logger,
+) error {
 	config, err := rest.InClusterConfig()
 	if err != nil {
-		klog.Fatal(err)
+		return err
 	}
 	clusterClient, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		klog.Fatal(err)
+		return err
+	}
+
+	logger.Info("connecting to ctlplane daemon gRPC", "address", daemonAddr)
+	c, err := client.New(daemonAddr, client.WithChannelOptions(channelOptions))
+	if err != nil {
+		return err
 	}
+	defer c.Close()
 
-	logger.Info("connecting to ctlplane daemon gRPC", "address", "localhost", "port", daemonPort)
-	conn, err := grpc.Dial(fmt.Sprintf("localhost:%d", daemonPort), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	err = pinSelf(selfCpus, logger, func() (cpudaemon.CPUSet, error) {
+		config, err := c.GetConfig(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		cpus := cpudaemon.CPUSet{}
+		for _, cpu := range client.Cpus(config.ReservedCpus) {
+			cpus.Add(cpu)
+		}
+		return cpus, nil
+	})
 	if err != nil {
-		klog.Fatal(err)
+		return err
 	}
-	defer conn.Close()
 
-	ctlPlaneClient = ctlplaneapi.NewControlPlaneClient(conn)
+	ctlPlaneClient = c.API()
 	ctx, ctxCancel := context.WithCancel(logr.NewContext(context.Background(), logger))
 	defer ctxCancel()
 
-	agent := agent.NewAgent(ctx, ctlPlaneClient, namespacePrefix)
+	agent := agent.NewAgent(ctx, ctlPlaneClient, namespacePrefix, agentOptions...)
 	if err := agent.Run(clusterClient, nodeName); err != nil {
-		klog.Fatal(err)
+		return err
+	}
+	if namespaceConfigs {
+		dynamicClient, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return err
+		}
+		if err := agent.RunNamespaceConfigs(dynamicClient); err != nil {
+			return err
+		}
+	}
+
+	if optionsFile != nil {
+		go watchConfig(optionsFile, notifyHangup(), func(args ctlParameters) error {
+			agent.SetNamespacePrefix(args.namespacePrefix)
+			return nil
+		}, logger)
 	}
 
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, os.Interrupt)
 	<-signalChan
+	return nil
 }
@@ -1,37 +1,61 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
 	"os"
-	"strconv"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
 	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/events"
+	"resourcemanagement.controlplane/pkg/metrics"
 	"resourcemanagement.controlplane/pkg/numautils"
+	"resourcemanagement.controlplane/pkg/preflight"
 	"resourcemanagement.controlplane/pkg/utils"
+	"resourcemanagement.controlplane/pkg/version"
 
 	"resourcemanagement.controlplane/pkg/cpudaemon"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
-const defaultDaemonPort = 31000
+const (
+	defaultDaemonPort        = 31000
+	metricsReadHeaderTimeout = 10 * time.Second
+	defaultKeepaliveTimeout  = 20 * time.Second
+	defaultTombstoneTTL      = 5 * time.Minute
+	defaultKubeletRefresh    = 10 * time.Second
+
+	exitFailure = 1 // exit status of runtime failures
+	exitUsage   = 2 // exit status of invalid command line arguments, as with flag parsing errors
+)
 
 var (
 	ctlPlaneClient ctlplaneapi.ControlPlaneClient
+
+	errPreflightFailed    = errors.New("preflight checks failed")
+	errSimulationDiverged = errors.New("simulation diverged from the allocation trace")
 )
 
 type ctlParameters struct {
 	daemonPort      int         // ctlplane daemon port
 	memoryPinning   bool        // also do memory pinning
+	memNamespaces   string      // namespaces with memory pinning enabled
 	runtime         string      // container runtime
 	cgroupPath      string      // path to the system cgroup fs
 	nodeName        string      // agent node name
@@ -40,91 +64,563 @@ type ctlParameters struct {
 	allocator       string      // allocator to use
 	namespacePrefix string      // required namespace prefix
 	cgroupDriver    string      // either cgroupfs or systemd
+	excludeCpus     string      // cpus not managed by the daemon
+	excludeNodes    string      // numa nodes not managed by the daemon
+	managedCpus     string      // if set, only these cpus are managed by the daemon
+	exclusiveCap    int         // percent of cpus which can be exclusively allocated
+	metricsAddr     string      // address of the metrics endpoint
+	numaPlacement   string      // numa node selection strategy of numa allocator
+	smtPolicy       string      // sharing of physical cores between guaranteed containers by numa allocator
+	grpcReflection  bool        // enables gRPC server reflection
 	logger          logr.Logger // logger
+
+	channelOptions ctlplaneapi.ChannelOptions // keepalive and compression of agent-daemon channel
+	tombstoneTTL   time.Duration              // how long ids of deleted pods are remembered
+	kubeletState   string                     // path to kubelet cpu manager state
+	kubeletMode    string                     // coexistence mode with kubelet cpu manager
+	kubeletRefresh time.Duration              // interval of kubelet cpu manager state checks
+	leaseInterval  time.Duration              // interval of exclusive cpu lease expiration checks
+	gcInterval     time.Duration              // interval of orphaned allocations collection
+	usageInterval  time.Duration              // interval of accounting cpu time pinned to namespaces
+	onInvalid      string                     // action on pod request validation failure
+	onPartial      string                     // action on pods whose containers do not all fit
+	isolatedCpus   string                     // path of exported isolated cpus file, empty disables export
+	irqbalanceHup  bool                       // signal irqbalance when isolated cpus change
+	partitions     bool                       // make cgroups of exclusive containers cpuset partition roots
+	cgroupCheck    bool                       // verify at startup that cgroups can be modified
+	runtimeSocket  string                     // container runtime socket verified by preflight checks
+	namespaceMems  string                     // memory nodes of namespaces for numa-namespace allocators
+	listen         string                     // addresses of the daemon gRPC server, defaults to dport
+	daemonAddr     string                     // gRPC target of the daemon used by the agent, defaults to dport
+	socket         string                     // unix socket replacing dport for both the daemon and the agent
+	logSampleRate  float64                    // fraction of successful requests logged by the daemon
+	softPinning    bool                       // pin burstable containers to cpus sized to their request
+	sharedPool     bool                       // restrict besteffort and burstable parent cgroups to the shared pool
+	kubepodsCpus   string                     // cpus left out of kubepods cgroup cpuset, empty disables management
+	reservedCpus   string                     // cpus reserved for system and kubelet, never allocated to containers
+	bucketWeights  bool                       // set cpu weight of shared containers proportional to their request
+	webhookURL     string                     // url allocation events are posted to, empty disables the webhook
+	webhookBuffer  int                        // number of allocation events waiting for webhook delivery
+	webhookRetries int                        // number of retries of failed webhook deliveries
+	eventsFormat   string                     // format of allocation events, json or cloudevents
+	eventsSource   string                     // source attribute of CloudEvents, defaults to /ctlplane/<node>
+	textfilePath   string                     // path of the node-exporter textfile, empty disables the export
+	textfileEvery  time.Duration              // interval of metrics textfile writes
+	retryBackoff   time.Duration              // retry hint of the second consecutive failure of a pod
+	retryMax       time.Duration              // the longest retry hint of repeated pod failures
+	requestTimeout time.Duration              // maximum processing time of pod requests, 0 if not limited
+	profiles       string                     // allocation profiles selected by pod annotation
+	stateSaveDelay time.Duration              // delay of state file writes, changes are journaled meanwhile
+	stateEncoding  string                     // format of the state file
+	cgroupRetry    time.Duration              // delay of re-apply of cpusets of containers whose cgroups do not exist yet
+	cgroupRetries  int                        // re-applies of cpusets of containers whose cgroups do not exist yet
+	podCgroups     bool                       // set cpuset of pod cgroups to cpus allocated to their containers
+	informerResync time.Duration              // resync period of the agent pod informer
+	podSelector    string                     // label selector of pods watched by the agent
+	excludeLabels  string                     // label values of pods ignored by the agent
+	checkNodeName  bool                       // reject pod requests meant for another node
+	staticPods     string                     // how the agent serves static pods
+	borrowInterval time.Duration              // interval of cpu borrowing checks, 0 disables borrowing
+	borrowThrottle float64                    // fraction of throttled periods making a burstable container throttled
+	borrowChecks   int                        // consecutive checks after which cpus are borrowed or returned
+	allocTrace     string                     // allocation trace written by the daemon and replayed by simulate
+	policyTiers    string                     // namespaces routed to their own policies
+	reconcile      bool                       // re-apply container cpusets overwritten by other components
+	reconcileEvery time.Duration              // interval of container cpuset reconciliation
+	pressure       string                     // pressure checked before exclusive allocations, none disables
+	pressureLimit  float64                    // pressure above which exclusive allocations are refused
+	pressureShared int                        // shared pool size below which pressure is checked
+	topologyReload time.Duration              // interval of checks of the node info path for topology changes
+	selfCpus       string                     // cpus the process pins itself to, reserved cpus if "reserved"
+	nsAllocators   string                     // allocators selectable by ControlPlaneConfig of namespaces
+	nsConfigs      bool                       // the agent applies ControlPlaneConfig resources to the daemon
+	configPath     string                     // file of options re-read on SIGHUP, empty if none
+	config         *configFile                // options of configPath applied over the command line, nil if none
 }
 
-func readNumberFromCommandOrPanic(cmd, prefix string) int {
-	numNamespaces, err := strconv.Atoi(cmd[len(prefix)+1:])
-	if err != nil {
-		klog.Fatalf("cannot read number of namespaces %s. format is %s=[0-9]+", cmd, prefix)
-	}
-	if numNamespaces <= 0 {
-		klog.Fatalf("number of namespaces must be greater than 0. it is %d", numNamespaces)
-	}
-	return numNamespaces
+// usageError reports invalid command line arguments, the process exits with exitUsage then.
+type usageError struct {
+	msg string
 }
 
-func getAllocator(args ctlParameters) cpudaemon.Allocator {
-	cR := parseRuntime(args.runtime)
-	driver := parseCGroupDriver(args.cgroupDriver)
+func (e usageError) Error() string {
+	return e.msg
+}
 
-	cgroupController := cpudaemon.NewCgroupController(cR, driver, args.logger)
+// usageErrorf formats usageError.
+func usageErrorf(format string, args ...interface{}) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
 
-	if args.allocator == "default" {
-		if args.memoryPinning {
-			klog.Fatal("option 'use memory pinning' is available only for numa-aware allocators")
-		}
-		return cpudaemon.NewDefaultAllocator(cgroupController)
-	}
-	if args.allocator == "numa" {
-		return cpudaemon.NewNumaAwareAllocator(cgroupController, args.memoryPinning)
+// exitCode returns exit status of the process failed with the error.
+func exitCode(err error) int {
+	var usageErr usageError
+	if errors.As(err, &usageErr) {
+		return exitUsage
 	}
-	if strings.HasPrefix(args.allocator, "numa-namespace=") {
-		numNamespaces := readNumberFromCommandOrPanic(args.allocator, "numa-namespace")
-		return cpudaemon.NewNumaPerNamespaceAllocator(
-			numNamespaces,
-			cgroupController,
-			false,
-			args.memoryPinning,
-			args.logger,
-		)
-	}
-	if strings.HasPrefix(args.allocator, "numa-namespace-exclusive=") {
-		numNamespaces := readNumberFromCommandOrPanic(args.allocator, "numa-namespace-exclusive")
-		return cpudaemon.NewNumaPerNamespaceAllocator(
-			numNamespaces,
-			cgroupController,
-			true,
-			args.memoryPinning,
-			args.logger,
-		)
+	return exitFailure
+}
+
+func parseNumaPlacement(placement string) (cpudaemon.PlacementPipeline, error) {
+	enoughCpus := []cpudaemon.NodeFilter{cpudaemon.EnoughCpusFilter{}}
+	distance := cpudaemon.WeightedScorer{Scorer: cpudaemon.DistanceScorer{}, Weight: 1}
+	switch placement {
+	case "distance":
+		return cpudaemon.DefaultPlacementPipeline(), nil
+	case "spread":
+		return cpudaemon.PlacementPipeline{
+			Filters: enoughCpus,
+			Scorers: []cpudaemon.WeightedScorer{{Scorer: cpudaemon.FreeCpusScorer{}, Weight: 1}},
+		}, nil
+	case "pack":
+		return cpudaemon.PlacementPipeline{
+			Filters: enoughCpus,
+			Scorers: []cpudaemon.WeightedScorer{{Scorer: cpudaemon.FreeCpusScorer{}, Weight: -1}},
+		}, nil
+	case "pod-locality":
+		return cpudaemon.PlacementPipeline{
+			Filters: enoughCpus,
+			Scorers: []cpudaemon.WeightedScorer{
+				distance,
+				{Scorer: cpudaemon.PodLocalityScorer{}, Weight: 1},
+			},
+		}, nil
 	}
-	klog.Fatalf("unknown allocator %s", args.allocator)
-	return nil
+	return cpudaemon.PlacementPipeline{}, usageErrorf("unknown numa placement %s", placement)
 }
 
-func parseRuntime(runtime string) cpudaemon.ContainerRuntime {
+func parseRuntime(runtime string) (cpudaemon.ContainerRuntime, error) {
 	val, ok := map[string]cpudaemon.ContainerRuntime{
 		"containerd": cpudaemon.ContainerdRunc,
 		"kind":       cpudaemon.Kind,
 		"docker":     cpudaemon.Docker,
 	}[runtime]
 	if !ok {
-		klog.Fatalf("unknown runtime %s", runtime)
+		return val, usageErrorf("unknown runtime %s", runtime)
 	}
-	return val
+	return val, nil
 }
 
-func parseCGroupDriver(driver string) cpudaemon.CGroupDriver {
+func parseCGroupDriver(driver string) (cpudaemon.CGroupDriver, error) {
 	val, ok := map[string]cpudaemon.CGroupDriver{
 		"systemd":  cpudaemon.DriverSystemd,
 		"cgroupfs": cpudaemon.DriverCgroupfs,
 	}[driver]
 	if !ok {
-		klog.Fatalf("unknown cgroup driver %s", driver)
+		return val, usageErrorf("unknown cgroup driver %s", driver)
+	}
+	return val, nil
+}
+
+func parseKubeletCoexistence(mode string) (cpudaemon.KubeletCoexistence, error) {
+	val, ok := map[string]cpudaemon.KubeletCoexistence{
+		"refuse":    cpudaemon.KubeletCoexistenceRefuse,
+		"cooperate": cpudaemon.KubeletCoexistenceCooperate,
+		"ignore":    cpudaemon.KubeletCoexistenceIgnore,
+	}[mode]
+	if !ok {
+		return val, usageErrorf("unknown kubelet cpu manager mode %s", mode)
+	}
+	return val, nil
+}
+
+func parseValidationFailureAction(action string) (cpudaemon.ValidationFailureAction, error) {
+	val, ok := map[string]cpudaemon.ValidationFailureAction{
+		"reject":      cpudaemon.ValidationFailureReject,
+		"best-effort": cpudaemon.ValidationFailureBestEffort,
+	}[action]
+	if !ok {
+		return val, usageErrorf("unknown validation failure action %s", action)
+	}
+	return val, nil
+}
+
+func parsePartialAllocationAction(action string) (cpudaemon.PartialAllocationAction, error) {
+	val, ok := map[string]cpudaemon.PartialAllocationAction{
+		"reject":      cpudaemon.PartialAllocationReject,
+		"shared-pool": cpudaemon.PartialAllocationSharedPool,
+	}[action]
+	if !ok {
+		return val, usageErrorf("unknown partial allocation action %s", action)
+	}
+	return val, nil
+}
+
+func parseStateEncoding(encoding string) (cpudaemon.StateEncoding, error) {
+	val, ok := map[string]cpudaemon.StateEncoding{
+		"auto": cpudaemon.StateEncodingAuto,
+		"json": cpudaemon.StateEncodingJSON,
+		"gob":  cpudaemon.StateEncodingGob,
+	}[encoding]
+	if !ok {
+		return val, usageErrorf("unknown state encoding %s", encoding)
+	}
+	return val, nil
+}
+
+func parsePressureSource(source string) (cpudaemon.PressureSource, error) {
+	val, ok := map[string]cpudaemon.PressureSource{
+		"none":    cpudaemon.PressureNone,
+		"loadavg": cpudaemon.PressureLoadAverage,
+		"psi":     cpudaemon.PressurePSI,
+	}[source]
+	if !ok {
+		return val, usageErrorf("unknown pressure source %s", source)
+	}
+	return val, nil
+}
+
+// parseSelfCpus parses -self-cpus: empty value disables pinning of the process, "reserved" pins it to
+// cpus reserved by the daemon, other values are cpusets.
+func parseSelfCpus(spec string) (cpus cpudaemon.CPUSet, reserved bool, err error) {
+	switch spec {
+	case "":
+		return nil, false, nil
+	case "reserved":
+		return nil, true, nil
+	}
+	cpus, err = cpudaemon.CPUSetFromString(spec)
+	if err != nil || cpus.Count() == 0 {
+		return nil, false, usageErrorf("cannot parse self cpus %s: expected reserved or cpuset", spec)
+	}
+	return cpus, false, nil
+}
+
+// pinSelf pins threads of the process to cpus given with -self-cpus. Reserved cpus are resolved with
+// reservedCpus.
+func pinSelf(spec string, logger logr.Logger, reservedCpus func() (cpudaemon.CPUSet, error)) error {
+	cpus, reserved, err := parseSelfCpus(spec)
+	if err != nil || (cpus == nil && !reserved) {
+		return err
+	}
+	if reserved {
+		if cpus, err = reservedCpus(); err != nil {
+			return fmt.Errorf("cannot get reserved cpus: %w", err)
+		}
+	}
+	if err := cpudaemon.PinProcess(cpus); err != nil {
+		return err
+	}
+	logger.Info("process pinned", "cpus", cpus)
+	return nil
+}
+
+// getEventSink returns sink of allocation events posting them to the webhook, or writing them to stdout
+// if the webhook url is "-".
+func getEventSink(args ctlParameters) (events.Sink, error) {
+	format, err := events.ParseFormat(args.eventsFormat)
+	if err != nil {
+		return nil, usageErrorf("%v", err)
+	}
+	source := args.eventsSource
+	if source == "" {
+		node := os.Getenv("NODE_NAME")
+		if node == "" {
+			node, _ = os.Hostname()
+		}
+		source = "/ctlplane/" + node
+	}
+	if args.webhookURL == "-" {
+		return events.NewWriterSink(os.Stdout, args.logger, events.WithWriterFormat(format, source)), nil
+	}
+	if args.webhookBuffer <= 0 || args.webhookRetries < 0 {
+		return nil, usageErrorf("events webhook buffer shall be positive and number of retries shall not be negative")
+	}
+	return events.NewWebhookSink(
+		args.webhookURL,
+		args.logger,
+		events.WithWebhookBuffer(args.webhookBuffer),
+		events.WithWebhookRetries(args.webhookRetries, time.Second),
+		events.WithWebhookFormat(format, source),
+	), nil
+}
+
+func getDaemonOptions(args ctlParameters) ([]cpudaemon.Option, error) {
+	opts := []cpudaemon.Option{}
+	if args.excludeCpus != "" {
+		cpus, err := cpudaemon.CPUSetFromString(args.excludeCpus)
+		if err != nil {
+			return nil, usageErrorf("cannot parse excluded cpus %s: %v", args.excludeCpus, err)
+		}
+		opts = append(opts, cpudaemon.WithExcludedCpus(cpus))
+	}
+	if args.excludeNodes != "" {
+		nodes, err := cpudaemon.CPUSetFromString(args.excludeNodes)
+		if err != nil {
+			return nil, usageErrorf("cannot parse excluded numa nodes %s: %v", args.excludeNodes, err)
+		}
+		opts = append(opts, cpudaemon.WithExcludedNumaNodes(nodes.Sorted()))
+	}
+	if args.managedCpus != "" {
+		cpus, err := cpudaemon.CPUSetFromString(args.managedCpus)
+		if err != nil {
+			return nil, usageErrorf("cannot parse managed cpus %s: %v", args.managedCpus, err)
+		}
+		opts = append(opts, cpudaemon.WithManagedCpus(cpus))
+	}
+	if args.kubepodsCpus != "" && args.reservedCpus != "" {
+		return nil, usageErrorf("-reserved-cpus cannot be used with -kubepods-reserved-cpus, which reserves cpus itself")
+	}
+	if args.kubepodsCpus != "" {
+		cpus, err := cpudaemon.CPUSetFromString(args.kubepodsCpus)
+		if err != nil {
+			return nil, usageErrorf("cannot parse kubepods reserved cpus %s: %v", args.kubepodsCpus, err)
+		}
+		opts = append(opts, cpudaemon.WithKubepodsCpuset(cpus))
+	}
+	if args.reservedCpus != "" {
+		cpus, err := cpudaemon.CPUSetFromString(args.reservedCpus)
+		if err != nil {
+			return nil, usageErrorf("cannot parse reserved cpus %s: %v", args.reservedCpus, err)
+		}
+		opts = append(opts, cpudaemon.WithReservedCpus(cpus))
+	}
+	if args.memNamespaces != "" {
+		opts = append(opts, cpudaemon.WithMemoryPinningNamespaces(strings.Split(args.memNamespaces, ",")))
+	}
+	opts = append(opts, cpudaemon.WithExclusiveCpusCap(args.exclusiveCap))
+	opts = append(opts, cpudaemon.WithTombstoneTTL(args.tombstoneTTL))
+	kubeletMode, err := parseKubeletCoexistence(args.kubeletMode)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, cpudaemon.WithKubeletCPUManager(args.kubeletState, kubeletMode))
+	onInvalid, err := parseValidationFailureAction(args.onInvalid)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, cpudaemon.WithValidationFailureAction(onInvalid))
+	onPartial, err := parsePartialAllocationAction(args.onPartial)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, cpudaemon.WithPartialAllocationAction(onPartial))
+	if args.cgroupCheck {
+		opts = append(opts, cpudaemon.WithCgroupWriteCheck())
+	}
+	if args.sharedPool {
+		opts = append(opts, cpudaemon.WithSharedPoolCgroups())
+	}
+	if args.podCgroups {
+		opts = append(opts, cpudaemon.WithPodCgroupPinning())
 	}
-	return val
+	if args.borrowInterval > 0 {
+		opts = append(opts, cpudaemon.WithCPUBorrowing(args.borrowThrottle, args.borrowChecks))
+	}
+	pressure, err := parsePressureSource(args.pressure)
+	if err != nil {
+		return nil, err
+	}
+	if pressure != cpudaemon.PressureNone {
+		opts = append(opts, cpudaemon.WithPressureGuardrail(pressure, args.pressureLimit, args.pressureShared))
+	}
+	if args.checkNodeName {
+		nodeName := os.Getenv("NODE_NAME")
+		if nodeName == "" {
+			nodeName = args.nodeName
+		}
+		if nodeName == "" {
+			return nil, usageErrorf("node name validation requires NODE_NAME environment variable or -agent-host")
+		}
+		opts = append(opts, cpudaemon.WithNodeName(nodeName))
+	}
+	if args.webhookURL != "" {
+		sink, err := getEventSink(args)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, cpudaemon.WithEventSink(sink))
+	}
+	if args.isolatedCpus != "" {
+		opts = append(opts, cpudaemon.WithIsolatedCpusExport(args.isolatedCpus, args.irqbalanceHup))
+	}
+	if args.profiles != "" {
+		profiles, err := cpudaemon.ParseProfiles(args.profiles)
+		if err != nil {
+			return nil, usageErrorf("cannot parse allocation profiles: %v", err)
+		}
+		opts = append(opts, cpudaemon.WithProfiles(profiles))
+	}
+	if args.stateSaveDelay != 0 {
+		opts = append(opts, cpudaemon.WithStateSaveDelay(args.stateSaveDelay))
+	}
+	if args.allocTrace != "" {
+		opts = append(opts, cpudaemon.WithAllocationTrace(args.allocTrace))
+	}
+	encoding, err := parseStateEncoding(args.stateEncoding)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, cpudaemon.WithStateEncoding(encoding))
+	opts = append(opts, cpudaemon.WithCgroupRetry(args.cgroupRetry, args.cgroupRetries))
+	namespaceAllocators, err := getNamespaceAllocators(args)
+	if err != nil {
+		return nil, err
+	}
+	if namespaceAllocators != nil {
+		opts = append(opts, cpudaemon.WithNamespaceAllocators(namespaceAllocators))
+	}
+	return opts, nil
+}
+
+// serveMetrics serves metrics on -metrics-addr in background. Failures to listen are returned, later
+// failures of the server are logged.
+func serveMetrics(args ctlParameters) error {
+	if args.metricsAddr == "" {
+		return nil
+	}
+	l, err := net.Listen("tcp", args.metricsAddr)
+	if err != nil {
+		return fmt.Errorf("cannot serve metrics: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	srv := &http.Server{
+		Addr:              args.metricsAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: metricsReadHeaderTimeout,
+	}
+	go func() {
+		if err := srv.Serve(l); err != nil {
+			args.logger.Error(err, "metrics server failed")
+		}
+	}()
+	return nil
+}
+
+// listenAddresses returns addresses of the daemon gRPC server given with -listen, or the -dport tcp
+// port on all interfaces.
+func listenAddresses(args ctlParameters) ([]ctlplaneapi.ListenAddress, error) {
+	if args.listen == "" {
+		return []ctlplaneapi.ListenAddress{{Network: "tcp", Address: fmt.Sprintf(":%d", args.daemonPort)}}, nil
+	}
+	addresses, err := ctlplaneapi.ParseListenAddresses(args.listen)
+	if err != nil {
+		return nil, usageErrorf("cannot parse listen addresses %s: %v", args.listen, err)
+	}
+	return addresses, nil
+}
+
+// applySocket sets both -listen and -daemon-addr to the -socket unix socket, so that the daemon serves
+// only the socket and the agent dials it.
+func applySocket(args *ctlParameters) error {
+	if args.socket == "" {
+		return nil
+	}
+	if args.listen != "" || args.daemonAddr != "" {
+		return usageErrorf("-socket cannot be used with -listen nor -daemon-addr")
+	}
+	if !filepath.IsAbs(args.socket) {
+		return usageErrorf("socket path shall be absolute, got %s", args.socket)
+	}
+	args.listen = ctlplaneapi.ListenAddress{Network: "unix", Address: args.socket}.String()
+	args.daemonAddr = args.listen
+	return nil
+}
+
+// checkDaemonParameters validates parameters of the daemon which are not parsed by other helpers.
+func checkDaemonParameters(args ctlParameters) error {
+	if args.logSampleRate < 0 || args.logSampleRate > 1 {
+		return usageErrorf("request log sample rate shall be in range [0, 1], got %f", args.logSampleRate)
+	}
+	if args.retryBackoff < 0 || args.retryMax < args.retryBackoff {
+		return usageErrorf(
+			"retry backoff shall not be negative nor exceed its maximum, got %s and %s",
+			args.retryBackoff,
+			args.retryMax,
+		)
+	}
+	if args.requestTimeout < 0 {
+		return usageErrorf("request timeout shall not be negative, got %s", args.requestTimeout)
+	}
+	if args.textfilePath != "" && args.textfileEvery <= 0 {
+		return usageErrorf("metrics textfile interval shall be positive, got %s", args.textfileEvery)
+	}
+	if args.borrowInterval < 0 {
+		return usageErrorf("cpu borrowing interval shall not be negative, got %s", args.borrowInterval)
+	}
+	if args.borrowInterval > 0 && (args.borrowThrottle <= 0 || args.borrowThrottle > 1 || args.borrowChecks <= 0) {
+		return usageErrorf(
+			"cpu borrowing throttling shall be in range (0, 1] and checks shall be positive, got %g and %d",
+			args.borrowThrottle,
+			args.borrowChecks,
+		)
+	}
+	if args.borrowInterval > 0 && (args.sharedPool || args.partitions) {
+		return usageErrorf("cpu borrowing cannot be used with -shared-pool-cgroups nor -cpuset-partitions")
+	}
+	if args.reconcile && args.reconcileEvery <= 0 {
+		return usageErrorf("cgroup reconciliation interval shall be positive, got %s", args.reconcileEvery)
+	}
+	if args.topologyReload < 0 {
+		return usageErrorf("topology reload interval shall not be negative, got %s", args.topologyReload)
+	}
+	if _, _, err := parseSelfCpus(args.selfCpus); err != nil {
+		return err
+	}
+	if args.pressure != "none" && (args.pressureLimit <= 0 || args.pressureShared < 0) {
+		return usageErrorf(
+			"pressure threshold shall be positive and minimal shared cpus shall not be negative, got %g and %d",
+			args.pressureLimit,
+			args.pressureShared,
+		)
+	}
+	return nil
 }
 
-func runDaemon(args ctlParameters) {
-	l, err := net.Listen("tcp", fmt.Sprintf(":%d", args.daemonPort))
+// getPolicyTiers parses -policy-tiers, tiers with static policy share the policy of the daemon.
+func getPolicyTiers(args ctlParameters, static cpudaemon.Policy) ([]cpudaemon.PolicyTier, error) {
+	if args.policyTiers == "" {
+		return nil, nil
+	}
+	tiers, err := cpudaemon.ParsePolicyTiers(args.policyTiers, map[string]cpudaemon.Policy{
+		"static":  static,
+		"observe": cpudaemon.NewObservePolicy(),
+	})
 	if err != nil {
-		klog.Fatal(err.Error())
+		return nil, usageErrorf("cannot parse policy tiers: %v", err)
 	}
+	return tiers, nil
+}
 
-	srv := grpc.NewServer()
-	allocator := getAllocator(args)
+func runDaemon(args ctlParameters) error {
+	if err := checkDaemonParameters(args); err != nil {
+		return err
+	}
+	addresses, err := listenAddresses(args)
+	if err != nil {
+		return err
+	}
+	allocator, err := getAllocator(args)
+	if err != nil {
+		return err
+	}
+	opts, err := getDaemonOptions(args)
+	if err != nil {
+		return err
+	}
 	policy := cpudaemon.NewStaticPolocy(allocator)
+	tiers, err := getPolicyTiers(args, policy)
+	if err != nil {
+		return err
+	}
+	if tiers != nil {
+		opts = append(opts, cpudaemon.WithPolicyTiers(tiers))
+	}
+
+	listeners := []net.Listener{}
+	for _, addr := range addresses {
+		l, err := addr.Listen()
+		if err != nil {
+			return err
+		}
+		args.logger.Info("listening", "address", addr.String())
+		listeners = append(listeners, l)
+	}
+	srv := grpc.NewServer(append(
+		args.channelOptions.ServerOptions(),
+		grpc.ChainUnaryInterceptor(ctlplaneapi.NewLoggingInterceptor(args.logger, args.logSampleRate)),
+	)...)
 
 	args.logger.Info(
 		"starting control plane server",
@@ -134,32 +630,191 @@ func runDaemon(args ctlParameters) {
 		args.allocator,
 		"policy",
 		"static",
+		"policyTiers",
+		args.policyTiers,
 	)
 
-	daemon, err := cpudaemon.New(args.cgroupPath, args.numaPath, args.statePath, policy, args.logger)
+	daemon, err := cpudaemon.New(
+		args.cgroupPath,
+		args.numaPath,
+		args.statePath,
+		policy,
+		args.logger,
+		opts...,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = pinSelf(args.selfCpus, args.logger, func() (cpudaemon.CPUSet, error) {
+		config, err := daemon.GetConfig(context.Background(), &ctlplaneapi.GetConfigRequest{})
+		if err != nil {
+			return nil, err
+		}
+		return cpudaemon.CPUSetFromBucketList(config.ReservedCPUs), nil
+	})
 	if err != nil {
-		klog.Fatal(err)
+		return err
+	}
+	if err := serveMetrics(args); err != nil {
+		return err
+	}
+	go daemon.WatchKubeletCPUManager(args.kubeletRefresh, nil)
+	go daemon.RunLeaseExpiration(args.leaseInterval, nil)
+	go daemon.RunGarbageCollection(args.gcInterval, nil)
+	go daemon.RunUsageAccounting(args.usageInterval, nil)
+	if args.reconcile {
+		go daemon.RunCgroupReconciliation(args.reconcileEvery, nil)
 	}
+	go daemon.RunCPUBorrowing(args.borrowInterval, nil)
+	go daemon.WatchTopology(args.topologyReload, notifyHangup(), nil)
+	if args.config != nil {
+		reloader := newDaemonReloader(daemon, args.config.flags, args)
+		go watchConfig(args.config, notifyHangup(), reloader.apply, args.logger)
+	}
+	go metrics.RunTextfileExport(args.textfilePath, args.textfileEvery, nil, args.logger)
 
-	svc := ctlplaneapi.NewServer(daemon)
+	svc := ctlplaneapi.NewServer(
+		daemon,
+		ctlplaneapi.WithRetryBackoff(args.retryBackoff, args.retryMax),
+		ctlplaneapi.WithRequestTimeout(args.requestTimeout),
+	)
 	healthSvc := health.NewServer()
 
 	ctlplaneapi.RegisterControlPlaneServer(srv, svc)
 	grpc_health_v1.RegisterHealthServer(srv, healthSvc) //nolint: nosnakecase
-
-	err = srv.Serve(l)
-	if err != nil {
-		klog.Fatal(err)
+	if args.grpcReflection {
+		args.logger.Info("gRPC server reflection enabled")
+		reflection.Register(srv)
 	}
+
+	return ctlplaneapi.Serve(srv, listeners)
 }
 
-func runAgentMode(args ctlParameters) {
+// notifyHangup returns channel receiving whenever the process gets SIGHUP.
+func notifyHangup() <-chan struct{} {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	hangup := make(chan struct{})
+	go func() {
+		for range signals {
+			hangup <- struct{}{}
+		}
+	}()
+	return hangup
+}
+
+func runAgentMode(args ctlParameters) error {
 	if os.Getenv("NODE_NAME") != "" {
 		args.nodeName = os.Getenv("NODE_NAME")
 	} else if args.nodeName == "" {
-		klog.Fatal("Running in agent mode with unknown agent node name!")
+		return usageErrorf("running in agent mode with unknown agent node name, set NODE_NAME or -agent-host")
+	}
+	daemonAddr := args.daemonAddr
+	if daemonAddr == "" {
+		daemonAddr = fmt.Sprintf("localhost:%d", args.daemonPort)
+	}
+	if _, _, err := parseSelfCpus(args.selfCpus); err != nil {
+		return err
+	}
+	agentOptions, err := getAgentOptions(args)
+	if err != nil {
+		return err
+	}
+	return runAgent(
+		daemonAddr,
+		args.nodeName,
+		args.namespacePrefix,
+		args.selfCpus,
+		args.nsConfigs,
+		args.config,
+		agentOptions,
+		args.channelOptions,
+		args.logger,
+	)
+}
+
+// runPreflight verifies node prerequisites of the daemon and returns errPreflightFailed if any check
+// failed.
+func runPreflight(args ctlParameters) error {
+	socket := args.runtimeSocket
+	if socket == "" {
+		socket = preflight.DefaultRuntimeSockets[args.runtime]
+	}
+	addresses, err := listenAddresses(args)
+	if err != nil {
+		return err
+	}
+	tcpAddrs := []string{}
+	for _, addr := range addresses {
+		if addr.Network == "tcp" {
+			tcpAddrs = append(tcpAddrs, addr.Address)
+		}
+	}
+	results := preflight.Run(preflight.Config{
+		CgroupPath:    args.cgroupPath,
+		NumaPath:      args.numaPath,
+		StatePath:     args.statePath,
+		CgroupDriver:  args.cgroupDriver,
+		RuntimeSocket: socket,
+		ListenAddrs:   tcpAddrs,
+		MetricsAddr:   args.metricsAddr,
+	})
+	if !preflight.Report(os.Stdout, results) {
+		return errPreflightFailed
 	}
-	runAgent(args.daemonPort, args.nodeName, args.namespacePrefix, args.logger)
+	return nil
+}
+
+// runSimulate replays the allocation trace with the allocator and daemon options given on the command line,
+// and returns errSimulationDiverged if cpu availability differs from the traced one.
+func runSimulate(args ctlParameters) error {
+	if args.allocTrace == "" {
+		return usageErrorf("simulate requires the allocation trace given with -allocation-trace")
+	}
+	args.logger = logr.Discard()
+	allocator, err := getAllocator(args)
+	if err != nil {
+		return err
+	}
+	opts, err := getDaemonOptions(args)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(args.allocTrace)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	replay, err := cpudaemon.ReplayAllocationTrace(
+		context.Background(),
+		f,
+		cpudaemon.NewStaticPolocy(allocator),
+		args.logger,
+		opts...,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot replay allocation trace %s: %w", args.allocTrace, err)
+	}
+	reportSimulation(os.Stdout, replay)
+	if len(replay.Divergences) > 0 {
+		return errSimulationDiverged
+	}
+	return nil
+}
+
+// reportSimulation prints divergences and summary of the replay.
+func reportSimulation(w io.Writer, replay cpudaemon.TraceReplay) {
+	for _, d := range replay.Divergences {
+		fmt.Fprintf(w, "DIVERGED %s\n", d)
+	}
+	fmt.Fprintf(
+		w,
+		"replayed %d entries, %d diverged, free cpus: %s\n",
+		replay.Entries,
+		len(replay.Divergences),
+		replay.Free,
+	)
 }
 
 func createLogger() logr.Logger {
@@ -170,34 +825,83 @@ func createLogger() logr.Logger {
 }
 
 // normalizePath returns absolute path with symlinks evaluated.
-func normalizePath(path string, notExistOk bool) string {
+func normalizePath(path string, notExistOk bool) (string, error) {
 	realPath, err := utils.EvaluateRealPath(path)
 	if err != nil {
 		if notExistOk && errors.Is(err, os.ErrNotExist) { // file does not exist,
-			return path
+			return path, nil
 		}
-		klog.Fatal(err)
+		return "", err
+	}
+	return realPath, nil
+}
+
+// normalizePaths normalizes paths of cgroups, sysfs node info and the state file.
+func normalizePaths(args *ctlParameters) error {
+	var err error
+	if args.cgroupPath, err = normalizePath(args.cgroupPath, false); err != nil {
+		return err
+	}
+	if args.numaPath, err = normalizePath(args.numaPath, false); err != nil {
+		return err
 	}
-	return realPath
+	args.statePath, err = normalizePath(args.statePath, true)
+	return err
+}
+
+// exit logs the error which made the process fail and exits with its exit code, see exitCode.
+func exit(err error) {
+	klog.ErrorS(err, "ctlplane failed", "exitCode", exitCode(err))
+	klog.Flush()
+	os.Exit(exitCode(err))
 }
 
 func main() {
 	args := ctlParameters{}
 	agentMode := false
+	printVersion := false
+	preflightMode := len(os.Args) > 1 && os.Args[1] == "preflight"
+	simulateMode := len(os.Args) > 1 && os.Args[1] == "simulate"
 
 	flag.BoolVar(&agentMode, "a", false, "Run Controlplane agent")
+	flag.BoolVar(&printVersion, "version", false, "Print version, commit and build date of the binary and exit")
 	flag.BoolVar(
 		&args.memoryPinning,
 		"mem",
 		false,
 		"Pin memory togeter with cpu (valid only for numa-aware allocators)",
 	)
+	flag.StringVar(
+		&args.memNamespaces,
+		"mem-namespaces",
+		"",
+		"Comma separated list of namespaces with memory pinning enabled (valid only for numa-aware allocators)",
+	)
 	flag.IntVar(&args.daemonPort, "dport", defaultDaemonPort, "Specify Control Plane Daemon port")
+	flag.StringVar(
+		&args.listen,
+		"listen",
+		"",
+		"Comma separated addresses of the daemon gRPC server, eg. localhost:31000,unix:///run/ctlplane/daemon.sock. Defaults to -dport on all interfaces",
+	)
+	flag.StringVar(
+		&args.daemonAddr,
+		"daemon-addr",
+		"",
+		"gRPC target of the daemon used by the agent, eg. unix:///run/ctlplane/daemon.sock. Defaults to localhost and -dport",
+	)
+	flag.StringVar(
+		&args.socket,
+		"socket",
+		"",
+		"Unix socket path, eg. /var/run/ctlplane.sock, served by the daemon instead of -dport and dialed by the agent;"+
+			" sets both -listen and -daemon-addr",
+	)
 	flag.StringVar(
 		&args.allocator,
 		"allocator",
 		"default",
-		"Allocator to use. Available are: default, numa, numa-namespace=NUM_NAMESPACES",
+		"Allocator to use, eg. numa-namespace=2. -allocator=help lists available allocators with their options",
 	)
 	flag.StringVar(&args.cgroupPath, "cpath", "/sys/fs/cgroup/", "Specify Path to cgroupds")
 	flag.StringVar(&args.numaPath, "npath", numautils.LinuxTopologyPath, "Specify Path to sysfs node info")
@@ -211,9 +915,387 @@ func main() {
 		"Container Runtime (Default: containerd, Possible values: containerd, docker, kind)",
 	)
 	flag.StringVar(&args.cgroupDriver, "cgroup-driver", "systemd", "Set cgroup driver used by kubelet. Values: systemd, cgroupfs")
+	flag.StringVar(&args.excludeCpus, "exclude-cpus", "", "Cpus not managed by the daemon, in cpuset format (eg. 0-3,8)")
+	flag.StringVar(&args.excludeNodes, "exclude-numa-nodes", "", "Numa nodes not managed by the daemon, in cpuset format (eg. 0,1)")
+	flag.StringVar(
+		&args.managedCpus,
+		"managed-cpus",
+		"",
+		"If set, only these cpus are managed by the daemon, in cpuset format (eg. 8-63). Allows running multiple daemons with disjoint cpus and separate state files",
+	)
+	flag.StringVar(
+		&args.kubepodsCpus,
+		"kubepods-reserved-cpus",
+		"",
+		"If set, the daemon sets cpuset of the kubepods cgroup to all cpus except these ones, in cpuset format (eg. 0-1), so that no pod runs on them."+
+			" Reserved cpus are then not detected; cannot be used with -reserved-cpus",
+	)
+	flag.StringVar(
+		&args.reservedCpus,
+		"reserved-cpus",
+		"",
+		"Cpus reserved for system and kubelet daemons, in cpuset format (eg. 0-3). They are never allocated to containers,"+
+			" in addition to cpus detected outside of kubepods cgroup, whose cpuset is left to kubelet; cannot be used with -kubepods-reserved-cpus",
+	)
+	flag.IntVar(
+		&args.exclusiveCap,
+		"exclusive-cpus-cap",
+		100,
+		"Maximal percent of managed cpus which can be exclusively allocated to guaranteed containers",
+	)
+	flag.StringVar(
+		&args.numaPlacement,
+		"numa-placement",
+		"distance",
+		"Numa node selection of numa allocator. Available are: distance, spread, pack, pod-locality",
+	)
+	flag.StringVar(
+		&args.smtPolicy,
+		"smt-policy",
+		"none",
+		"Hyperthread allocation of numa allocator. Available are: none, full-core (guaranteed containers get whole"+
+			" physical cores, requests are rounded up to full cores)",
+	)
+	flag.DurationVar(
+		&args.tombstoneTTL,
+		"tombstone-ttl",
+		defaultTombstoneTTL,
+		"How long ids of deleted pods are remembered, so that late updates of these pods are rejected. 0 disables",
+	)
+	flag.StringVar(
+		&args.kubeletState,
+		"kubelet-cpu-manager-state",
+		cpudaemon.DefaultKubeletCPUManagerStatePath,
+		"Path to kubelet cpu manager state, used to detect kubelet cpu manager static policy",
+	)
+	flag.StringVar(
+		&args.kubeletMode,
+		"kubelet-cpu-manager-mode",
+		"refuse",
+		"Behaviour when kubelet cpu manager static policy is enabled. Values: refuse, cooperate, ignore",
+	)
+	flag.DurationVar(
+		&args.kubeletRefresh,
+		"kubelet-cpu-manager-refresh",
+		defaultKubeletRefresh,
+		"Interval of kubelet cpu manager state checks in cooperate mode",
+	)
+	flag.DurationVar(
+		&args.leaseInterval,
+		"lease-check-interval",
+		10*time.Second,
+		"Interval of exclusive cpu lease expiration checks",
+	)
+	flag.DurationVar(
+		&args.gcInterval,
+		"gc-interval",
+		time.Minute,
+		"Interval of freeing allocations of containers not belonging to any pod, 0 disables",
+	)
+	flag.DurationVar(
+		&args.usageInterval,
+		"usage-interval",
+		time.Minute,
+		"Interval of accounting cpu seconds pinned to namespaces, 0 accounts them only on allocation changes",
+	)
+	flag.BoolVar(
+		&args.reconcile,
+		"cgroup-reconcile",
+		false,
+		"Periodically re-apply cpusets of container cgroups overwritten by kubelet or other components",
+	)
+	flag.DurationVar(
+		&args.reconcileEvery,
+		"cgroup-reconcile-interval",
+		time.Minute,
+		"Interval of checks of container cpusets with -cgroup-reconcile",
+	)
+	flag.DurationVar(
+		&args.topologyReload,
+		"topology-reload-interval",
+		0,
+		"Interval of checks of -npath for topology changes, 0 reloads the topology only on SIGHUP",
+	)
+	flag.StringVar(
+		&args.pressure,
+		"pressure-source",
+		"none",
+		"Pressure checked before exclusive allocations shrinking the shared pool. Values: none, loadavg, psi",
+	)
+	flag.Float64Var(
+		&args.pressureLimit,
+		"pressure-threshold",
+		0,
+		"Pressure refusing exclusive allocations: 1 minute load average, or cpu PSI avg10 percent with psi",
+	)
+	flag.IntVar(
+		&args.pressureShared,
+		"pressure-min-shared-cpus",
+		0,
+		"Shared pool size below which exclusive allocations check -pressure-source",
+	)
+	flag.StringVar(
+		&args.selfCpus,
+		"self-cpus",
+		"",
+		"Cpus the daemon or agent pins itself to at startup: reserved (cpus reserved by the daemon) or cpuset, eg. 0-1",
+	)
+	flag.StringVar(
+		&args.nsAllocators,
+		"namespace-allocators",
+		"",
+		"Comma separated allocators selectable by ControlPlaneConfig of namespaces, eg. scatter,numa-namespace=2",
+	)
+	flag.BoolVar(
+		&args.nsConfigs,
+		"namespace-configs",
+		false,
+		"The agent applies ControlPlaneConfig custom resources configuring allocations of their namespaces",
+	)
+	flag.StringVar(
+		&args.configPath,
+		"config",
+		"",
+		"File of options applied over the command line and re-read on SIGHUP: allocator with its options, reserved-cpus, namespace-prefix",
+	)
+	flag.StringVar(
+		&args.onInvalid,
+		"validation-failure",
+		"reject",
+		"Action on invalid pod requests. Values: reject, best-effort (pod runs unpinned in shared pool)",
+	)
+	flag.StringVar(
+		&args.onPartial,
+		"partial-allocation",
+		"reject",
+		"Action on pods whose containers do not all fit. Values: reject, shared-pool (non-critical containers run in shared pool)",
+	)
+	flag.StringVar(
+		&args.isolatedCpus,
+		"isolated-cpus-file",
+		"",
+		"If set, exclusively allocated cpus are written to this file and to its .irqbalance environment file",
+	)
+	flag.BoolVar(
+		&args.irqbalanceHup,
+		"irqbalance-hup",
+		false,
+		"Send SIGHUP to irqbalance when exclusively allocated cpus change",
+	)
+	flag.BoolVar(
+		&args.partitions,
+		"cpuset-partitions",
+		false,
+		"Make cgroups of containers with exclusive cpus cpuset partition roots (cgroups v2 only)",
+	)
+	flag.BoolVar(
+		&args.cgroupCheck,
+		"cgroup-write-check",
+		true,
+		"Verify at startup that cgroups can be modified, fail if cgroup filesystem is read-only or privileges are missing",
+	)
+	flag.BoolVar(
+		&args.sharedPool,
+		"shared-pool-cgroups",
+		false,
+		"Restrict cpusets of besteffort and burstable parent cgroups to cpus not exclusively allocated (cgroups v2 only)",
+	)
+	flag.BoolVar(
+		&args.podCgroups,
+		"pod-cgroup-pinning",
+		false,
+		"Set cpusets of pod cgroups to union of cpus allocated to their containers (cgroups v2 only)",
+	)
+	flag.DurationVar(
+		&args.borrowInterval,
+		"cpu-borrowing-interval",
+		0,
+		"Interval of checks lending idle exclusive cpus to throttled burstable containers of the same bucket (numa-namespace-exclusive allocator, cgroups v2 only), 0 disables",
+	)
+	flag.Float64Var(
+		&args.borrowThrottle,
+		"cpu-borrowing-throttling",
+		0.2,
+		"Fraction of throttled cpu periods from which a burstable container is throttled in a cpu borrowing check",
+	)
+	flag.IntVar(
+		&args.borrowChecks,
+		"cpu-borrowing-checks",
+		3,
+		"Number of consecutive cpu borrowing checks a container shall be throttled to borrow cpus, or not throttled to return them",
+	)
+	flag.BoolVar(
+		&args.checkNodeName,
+		"check-node-name",
+		false,
+		"Reject pod requests meant for another node than NODE_NAME environment variable or -agent-host",
+	)
+	flag.StringVar(&args.staticPods, "static-pods", "pin", "How the agent serves static pods: pin (identified by manifest hash), skip")
+	flag.DurationVar(&args.informerResync, "informer-resync", 0, "Resync period of the agent pod informer, 0 disables resync")
+	flag.StringVar(&args.podSelector, "pod-selector", "", "If set, the agent watches only pods matching this label selector")
+	flag.StringVar(
+		&args.excludeLabels,
+		"exclude-labels",
+		"app=ctlplane-daemonset",
+		"Pods having any of these labels are ignored by the agent, eg. app=ctlplane-daemonset,tier=system; empty watches all pods",
+	)
+	flag.BoolVar(
+		&args.grpcReflection,
+		"grpc-reflection",
+		false,
+		"Enables gRPC server reflection, so that tools like grpcurl can list and call the daemon API",
+	)
+	flag.DurationVar(
+		&args.channelOptions.KeepaliveTime,
+		"grpc-keepalive-time",
+		0,
+		"Interval of keepalive pings on agent-daemon gRPC channel, 0 disables keepalive. Shall be the same for agent and daemon",
+	)
+	flag.DurationVar(
+		&args.channelOptions.KeepaliveTimeout,
+		"grpc-keepalive-timeout",
+		defaultKeepaliveTimeout,
+		"Time to wait for keepalive ping acknowledgement before closing agent-daemon gRPC channel",
+	)
+	flag.BoolVar(
+		&args.channelOptions.Compression,
+		"grpc-compression",
+		false,
+		"Enables gzip compression of agent-daemon gRPC channel",
+	)
+	flag.Float64Var(
+		&args.logSampleRate,
+		"request-log-sample",
+		1,
+		"Fraction of successful gRPC requests logged by the daemon, in range [0, 1]. Failed requests are always logged",
+	)
+	flag.StringVar(
+		&args.webhookURL,
+		"events-webhook",
+		"",
+		"If set, allocated and freed events of containers are posted as JSON to this url (eg. http://tracker:8080/events),"+
+			" or written to stdout if set to -",
+	)
+	flag.StringVar(&args.eventsFormat, "events-format", "json", "Format of allocation events: json or cloudevents")
+	flag.StringVar(&args.eventsSource, "events-source", "", "Source attribute of CloudEvents, defaults to /ctlplane/<node name>")
+	flag.IntVar(&args.webhookBuffer, "events-webhook-buffer", 1024, "Number of events waiting for webhook delivery, next events are dropped")
+	flag.IntVar(&args.webhookRetries, "events-webhook-retries", 5, "Number of retries of failed webhook deliveries, with exponential backoff from 1s")
+	flag.StringVar(
+		&args.textfilePath,
+		"metrics-textfile",
+		"",
+		"If set, metrics are periodically written to this file for node-exporter textfile collector"+
+			" (eg. /var/lib/node_exporter/textfile_collector/ctlplane.prom)",
+	)
+	flag.DurationVar(&args.textfileEvery, "metrics-textfile-interval", 30*time.Second, "Interval of metrics textfile writes")
+	flag.DurationVar(
+		&args.retryBackoff,
+		"retry-backoff",
+		time.Second,
+		"Retry hint reported with the second consecutive allocation failure of a pod, doubled with every next one, 0 disables",
+	)
+	flag.DurationVar(&args.retryMax, "retry-backoff-max", 5*time.Minute, "The longest retry hint of repeated allocation failures")
+	flag.DurationVar(
+		&args.requestTimeout,
+		"request-timeout",
+		0,
+		"Maximum processing time of create, update and delete pod requests, 0 disables the limit",
+	)
+	flag.StringVar(
+		&args.profiles,
+		"profiles",
+		"",
+		"Allocation profiles selected by ctlplane.intel.com/profile pod annotation,"+
+			" eg. latency=memory-pinning,compact;throughput=no-memory-pinning,scatter",
+	)
+	flag.StringVar(
+		&args.policyTiers,
+		"policy-tiers",
+		"",
+		"Namespaces managed by their own policy, static or observe, eg. prod=static:shop,payments;dev=observe:sandbox."+
+			" Pods of other namespaces are managed by the static policy",
+	)
+	flag.DurationVar(
+		&args.stateSaveDelay,
+		"state-save-delay",
+		0,
+		"If positive, state changes are journaled and the state file is written at most this long after a change, 0 writes it on every change",
+	)
+	flag.StringVar(
+		&args.stateEncoding,
+		"state-encoding",
+		"auto",
+		"Format of the state file. Values: json, gob, auto (gob for state files with .gob extension, json otherwise)",
+	)
+	flag.DurationVar(
+		&args.cgroupRetry,
+		"cgroup-retry-delay",
+		time.Second,
+		"Delay of re-apply of cpusets of containers whose cgroups do not exist yet",
+	)
+	flag.IntVar(
+		&args.cgroupRetries,
+		"cgroup-retry-attempts",
+		10,
+		"Number of re-applies of cpusets of containers whose cgroups do not exist yet, 0 fails such requests",
+	)
+	flag.StringVar(&args.metricsAddr, "metrics-addr", "", "If set, serves prometheus metrics on given address (eg. :9090)")
+	flag.StringVar(
+		&args.namespaceMems,
+		"namespace-mems",
+		"",
+		"Memory nodes of namespaces for numa-namespace allocators, eg. team-a=0;team-b=1,3",
+	)
+	flag.BoolVar(
+		&args.softPinning,
+		"burstable-soft-pinning",
+		false,
+		"Pin burstable containers to as many shared cpus of their namespace as they request (valid only for numa-namespace allocators)",
+	)
+	flag.BoolVar(
+		&args.bucketWeights,
+		"bucket-cpu-weights",
+		false,
+		"Set cpu weight of containers sharing cpus of their namespace proportional to their request (valid only for numa-namespace allocators)",
+	)
+	flag.StringVar(
+		&args.allocTrace,
+		"allocation-trace",
+		"",
+		"If set, inputs of allocation decisions are appended to this file, which is replayed by simulate command",
+	)
+	flag.StringVar(
+		&args.runtimeSocket,
+		"runtime-socket",
+		"",
+		"Container runtime socket verified by preflight command, defaults to the socket of -runtime",
+	)
 
+	if preflightMode {
+		_ = flag.CommandLine.Parse(os.Args[2:])
+		if err := runPreflight(args); err != nil {
+			exit(err)
+		}
+		return
+	}
+	if simulateMode {
+		_ = flag.CommandLine.Parse(os.Args[2:])
+		if err := runSimulate(args); err != nil {
+			exit(err)
+		}
+		return
+	}
 	flag.Parse() // after declaring flags we need to call it
+	if printVersion {
+		fmt.Println(version.Get())
+		return
+	}
+	if args.allocator == "help" {
+		printAllocators(os.Stdout)
+		return
+	}
 	args.logger = createLogger()
+	args.logger.Info("ctlplane build", "version", version.Version, "commit", version.Commit, "buildDate", version.BuildDate)
 
 	defer func() {
 		err := recover()
@@ -222,14 +1304,27 @@ func main() {
 		}
 	}()
 
-	args.cgroupPath = normalizePath(args.cgroupPath, false)
-	args.numaPath = normalizePath(args.numaPath, false)
-	args.statePath = normalizePath(args.statePath, true)
+	if err := normalizePaths(&args); err != nil {
+		exit(err)
+	}
+	if args.configPath != "" {
+		args.config = newConfigFile(args.configPath, flag.CommandLine, &args)
+		if _, err := args.config.load(); err != nil {
+			exit(err)
+		}
+	}
+	if err := applySocket(&args); err != nil {
+		exit(err)
+	}
 
+	var err error
 	switch {
 	case agentMode:
-		runAgentMode(args)
+		err = runAgentMode(args)
 	default:
-		runDaemon(args)
+		err = runDaemon(args)
+	}
+	if err != nil {
+		exit(err)
 	}
 }
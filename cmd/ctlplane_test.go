@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/cpudaemon"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/events"
+)
+
+// validParameters returns daemon parameters with defaults of the command line flags.
+func validParameters() ctlParameters {
+	return ctlParameters{
+		runtime:        "containerd",
+		cgroupDriver:   "systemd",
+		allocator:      "default",
+		numaPlacement:  "distance",
+		smtPolicy:      "none",
+		kubeletMode:    "refuse",
+		onInvalid:      "reject",
+		onPartial:      "reject",
+		stateEncoding:  "auto",
+		staticPods:     "pin",
+		pressure:       "none",
+		exclusiveCap:   100,
+		logSampleRate:  1,
+		retryBackoff:   time.Second,
+		retryMax:       time.Minute,
+		webhookBuffer:  1,
+		eventsFormat:   "json",
+		textfileEvery:  time.Second,
+		borrowThrottle: 0.2,
+		borrowChecks:   3,
+		daemonPort:     defaultDaemonPort,
+		excludeLabels:  "app=ctlplane-daemonset",
+		logger:         logr.Discard(),
+		informerResync: 0,
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, exitUsage, exitCode(usageErrorf("unknown runtime %s", "crio")))
+	assert.Equal(t, exitUsage, exitCode(errors.Join(errors.New("context"), usageErrorf("invalid"))))
+	assert.Equal(t, exitFailure, exitCode(errors.New("cannot listen")))
+	assert.Equal(t, exitFailure, exitCode(errPreflightFailed))
+	assert.Equal(t, exitFailure, exitCode(errSimulationDiverged))
+}
+
+func TestParseHelpers(t *testing.T) {
+	runtime, err := parseRuntime("docker")
+	require.Nil(t, err)
+	assert.Equal(t, cpudaemon.Docker, runtime)
+	driver, err := parseCGroupDriver("cgroupfs")
+	require.Nil(t, err)
+	assert.Equal(t, cpudaemon.DriverCgroupfs, driver)
+	mode, err := parseKubeletCoexistence("cooperate")
+	require.Nil(t, err)
+	assert.Equal(t, cpudaemon.KubeletCoexistenceCooperate, mode)
+	onInvalid, err := parseValidationFailureAction("best-effort")
+	require.Nil(t, err)
+	assert.Equal(t, cpudaemon.ValidationFailureBestEffort, onInvalid)
+	onPartial, err := parsePartialAllocationAction("shared-pool")
+	require.Nil(t, err)
+	assert.Equal(t, cpudaemon.PartialAllocationSharedPool, onPartial)
+	encoding, err := parseStateEncoding("gob")
+	require.Nil(t, err)
+	assert.Equal(t, cpudaemon.StateEncodingGob, encoding)
+	pressure, err := parsePressureSource("psi")
+	require.Nil(t, err)
+	assert.Equal(t, cpudaemon.PressurePSI, pressure)
+	_, err = parseNumaPlacement("pack")
+	assert.Nil(t, err)
+	selfCpus, reserved, err := parseSelfCpus("0-1")
+	require.Nil(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, "0,1", selfCpus.String())
+	_, reserved, err = parseSelfCpus("reserved")
+	require.Nil(t, err)
+	assert.True(t, reserved)
+}
+
+func TestPinSelf(t *testing.T) {
+	reservedCpus := func() (cpudaemon.CPUSet, error) {
+		t.Fatal("reserved cpus shall not be resolved")
+		return nil, nil
+	}
+	assert.Nil(t, pinSelf("", logr.Discard(), reservedCpus))
+
+	err := pinSelf("reserved", logr.Discard(), func() (cpudaemon.CPUSet, error) {
+		return nil, errors.New("daemon unavailable")
+	})
+	assert.ErrorContains(t, err, "daemon unavailable")
+
+	err = pinSelf("reserved", logr.Discard(), func() (cpudaemon.CPUSet, error) {
+		return cpudaemon.CPUSet{}, nil
+	})
+	assert.NotNil(t, err, "no reserved cpus to pin to")
+
+	assert.Equal(t, exitUsage, exitCode(pinSelf("0-", logr.Discard(), reservedCpus)))
+}
+
+func TestParseHelpersFailWithUsageError(t *testing.T) {
+	parsers := map[string]func() error{
+		"runtime":        func() error { _, err := parseRuntime("crio"); return err },
+		"cgroup driver":  func() error { _, err := parseCGroupDriver("unknown"); return err },
+		"kubelet mode":   func() error { _, err := parseKubeletCoexistence("unknown"); return err },
+		"on invalid":     func() error { _, err := parseValidationFailureAction("unknown"); return err },
+		"on partial":     func() error { _, err := parsePartialAllocationAction("unknown"); return err },
+		"state encoding": func() error { _, err := parseStateEncoding("xml"); return err },
+		"numa placement": func() error { _, err := parseNumaPlacement("random"); return err },
+		"static pods":    func() error { _, err := parseStaticPodsPolicy("unknown"); return err },
+		"pressure":       func() error { _, err := parsePressureSource("unknown"); return err },
+	}
+	for name, parse := range parsers {
+		err := parse()
+		assert.Equal(t, exitUsage, exitCode(err), name)
+		assert.Contains(t, err.Error(), "unknown", name)
+	}
+}
+
+func TestGetDaemonOptions(t *testing.T) {
+	args := validParameters()
+	args.excludeCpus = "0-1"
+	args.profiles = "latency=memory-pinning"
+
+	opts, err := getDaemonOptions(args)
+
+	require.Nil(t, err)
+	assert.NotEmpty(t, opts)
+}
+
+func TestGetPolicyTiers(t *testing.T) {
+	args := validParameters()
+	static := cpudaemon.NewStaticPolocy(nil)
+
+	tiers, err := getPolicyTiers(args, static)
+	require.Nil(t, err)
+	assert.Nil(t, tiers)
+
+	args.policyTiers = "prod=static:shop,payments;dev=observe:sandbox"
+	tiers, err = getPolicyTiers(args, static)
+	require.Nil(t, err)
+	require.Len(t, tiers, 2)
+	assert.Equal(t, static, tiers[0].Policy)
+	assert.Equal(t, []string{"shop", "payments"}, tiers[0].Namespaces)
+	assert.Equal(t, cpudaemon.NewObservePolicy(), tiers[1].Policy)
+
+	args.policyTiers = "dev=random:sandbox"
+	_, err = getPolicyTiers(args, static)
+	assert.Equal(t, exitUsage, exitCode(err))
+}
+
+func TestGetEventSink(t *testing.T) {
+	args := validParameters()
+	args.webhookURL, args.eventsFormat = "-", "cloudevents"
+
+	sink, err := getEventSink(args)
+
+	require.Nil(t, err)
+	assert.IsType(t, &events.WriterSink{}, sink)
+}
+
+func TestGetDaemonOptionsFails(t *testing.T) {
+	invalid := map[string]func(*ctlParameters){
+		"excluded cpus":  func(a *ctlParameters) { a.excludeCpus = "a-b" },
+		"excluded nodes": func(a *ctlParameters) { a.excludeNodes = "x" },
+		"managed cpus":   func(a *ctlParameters) { a.managedCpus = "1-" },
+		"reserved cpus":  func(a *ctlParameters) { a.reservedCpus = "-" },
+		"kubepods cpus":  func(a *ctlParameters) { a.kubepodsCpus = "-" },
+		"both reserved":  func(a *ctlParameters) { a.reservedCpus, a.kubepodsCpus = "0", "1" },
+		"profiles":       func(a *ctlParameters) { a.profiles = "latency=unknown" },
+		"webhook":        func(a *ctlParameters) { a.webhookURL, a.webhookBuffer = "http://tracker", 0 },
+		"events format":  func(a *ctlParameters) { a.webhookURL, a.eventsFormat = "-", "xml" },
+		"state encoding": func(a *ctlParameters) { a.stateEncoding = "xml" },
+		"kubelet mode":   func(a *ctlParameters) { a.kubeletMode = "unknown" },
+		"pressure":       func(a *ctlParameters) { a.pressure = "unknown" },
+	}
+	for name, modify := range invalid {
+		args := validParameters()
+		modify(&args)
+
+		_, err := getDaemonOptions(args)
+
+		assert.Equal(t, exitUsage, exitCode(err), name)
+	}
+}
+
+func TestCheckDaemonParameters(t *testing.T) {
+	assert.Nil(t, checkDaemonParameters(validParameters()))
+
+	invalid := map[string]func(*ctlParameters){
+		"log sample rate":   func(a *ctlParameters) { a.logSampleRate = 1.5 },
+		"retry backoff":     func(a *ctlParameters) { a.retryBackoff = time.Hour },
+		"request timeout":   func(a *ctlParameters) { a.requestTimeout = -time.Second },
+		"textfile interval": func(a *ctlParameters) { a.textfilePath, a.textfileEvery = "metrics.prom", 0 },
+		"borrow interval":   func(a *ctlParameters) { a.borrowInterval = -time.Second },
+		"borrow throttling": func(a *ctlParameters) { a.borrowInterval, a.borrowThrottle = time.Second, 0 },
+		"borrow checks":     func(a *ctlParameters) { a.borrowInterval, a.borrowChecks = time.Second, 0 },
+		"borrow partitions": func(a *ctlParameters) { a.borrowInterval, a.partitions = time.Second, true },
+		"reconcile":         func(a *ctlParameters) { a.reconcile, a.reconcileEvery = true, 0 },
+		"pressure":          func(a *ctlParameters) { a.pressure, a.pressureLimit = "loadavg", 0 },
+		"topology reload":   func(a *ctlParameters) { a.topologyReload = -time.Second },
+		"self cpus":         func(a *ctlParameters) { a.selfCpus = "housekeeping" },
+	}
+	for name, modify := range invalid {
+		args := validParameters()
+		modify(&args)
+
+		assert.Equal(t, exitUsage, exitCode(checkDaemonParameters(args)), name)
+	}
+}
+
+func TestRunSimulate(t *testing.T) {
+	args := validParameters()
+	assert.Equal(t, exitUsage, exitCode(runSimulate(args)))
+
+	args.allocTrace = filepath.Join(t.TempDir(), "allocation.trace")
+	assert.NotNil(t, runSimulate(args), "missing trace file")
+
+	require.Nil(t, os.WriteFile(args.allocTrace, nil, 0o644))
+	assert.Nil(t, runSimulate(args))
+}
+
+func TestReportSimulation(t *testing.T) {
+	out := bytes.Buffer{}
+	reportSimulation(&out, cpudaemon.TraceReplay{
+		Entries: 3,
+		Divergences: []cpudaemon.TraceDivergence{
+			{Line: 2, Time: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), Op: "CreatePod", Reason: "differs"},
+		},
+		Free: cpudaemon.CPUSet{4: struct{}{}, 5: struct{}{}},
+	})
+
+	assert.Equal(
+		t,
+		"DIVERGED line 2 (CreatePod at 2023-06-01T00:00:00Z): differs\n"+
+			"replayed 3 entries, 1 diverged, free cpus: 4,5\n",
+		out.String(),
+	)
+}
+
+func TestListenAddresses(t *testing.T) {
+	args := validParameters()
+	addresses, err := listenAddresses(args)
+	require.Nil(t, err)
+	require.Len(t, addresses, 1)
+	assert.Equal(t, ":31000", addresses[0].Address)
+
+	args.listen = "localhost:1,localhost:1"
+	_, err = listenAddresses(args)
+	assert.Equal(t, exitUsage, exitCode(err))
+}
+
+func TestApplySocket(t *testing.T) {
+	args := validParameters()
+	args.socket = "/var/run/ctlplane.sock"
+	require.Nil(t, applySocket(&args))
+
+	addresses, err := listenAddresses(args)
+	require.Nil(t, err)
+	assert.Equal(t, []ctlplaneapi.ListenAddress{{Network: "unix", Address: "/var/run/ctlplane.sock"}}, addresses)
+	assert.Equal(t, "unix:///var/run/ctlplane.sock", args.daemonAddr)
+
+	for name, modify := range map[string]func(*ctlParameters){
+		"listen":        func(a *ctlParameters) { a.listen = "localhost:1" },
+		"daemon addr":   func(a *ctlParameters) { a.daemonAddr = "localhost:1" },
+		"relative path": func(a *ctlParameters) { a.socket = "ctlplane.sock" },
+	} {
+		args := validParameters()
+		args.socket = "/var/run/ctlplane.sock"
+		modify(&args)
+		assert.Equal(t, exitUsage, exitCode(applySocket(&args)), name)
+	}
+}
+
+func TestGetAgentOptions(t *testing.T) {
+	opts, err := getAgentOptions(validParameters())
+	require.Nil(t, err)
+	assert.Len(t, opts, 4)
+
+	invalid := map[string]func(*ctlParameters){
+		"informer resync": func(a *ctlParameters) { a.informerResync = -time.Second },
+		"excluded labels": func(a *ctlParameters) { a.excludeLabels = "app" },
+		"static pods":     func(a *ctlParameters) { a.staticPods = "unknown" },
+	}
+	for name, modify := range invalid {
+		args := validParameters()
+		modify(&args)
+
+		_, err := getAgentOptions(args)
+
+		assert.Equal(t, exitUsage, exitCode(err), name)
+	}
+}
+
+func TestRunAgentModeRequiresNodeName(t *testing.T) {
+	t.Setenv("NODE_NAME", "")
+
+	err := runAgentMode(validParameters())
+
+	assert.Equal(t, exitUsage, exitCode(err))
+}
@@ -5,18 +5,27 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"resourcemanagement.controlplane/pkg/ctlplaneapi"
 )
@@ -24,8 +33,14 @@ import (
 const (
 	defaultTimeout          = 5 * time.Second
 	maxUnsuccesfullAttempts = 3
+	eventComponent          = "ctlplane-agent"
+	runtimeMismatchReason   = "RuntimeMismatch"
+	cpusReleasedReason      = "CpusReleased"
 )
 
+// DefaultExcludedLabels are labels of pods ignored by the agent, by default the pods of ctlplane itself.
+var DefaultExcludedLabels = map[string]string{"app": "ctlplane-daemonset"}
+
 var ErrCannotSync = errors.New("cannot sync with k8s")
 
 // Agent observes k8s for pod lifecycle events.
@@ -38,21 +53,174 @@ type Agent struct {
 	callTimeout                        time.Duration
 	logger                             logr.Logger
 	numConsecutiveUnsuccessfulAttempts uint
+	recorder                           record.EventRecorder
+	node                               *corev1.ObjectReference
+	runtimeMismatchReported            bool // warning event about runtime mismatch is emitted only once
+	backoffs                           map[types.UID]*podBackoff
+	resync                             time.Duration     // resync period of the pod informer, 0 disables resync
+	labelSelector                      string            // additional label selector of watched pods
+	excludedLabels                     map[string]string // pods with any of these labels are not watched
+	staticPods                         StaticPodsPolicy
+}
+
+// StaticPodsPolicy selects how the agent serves static pods, which kubelet creates from manifest files
+// and reports to the api server as mirror pods.
+type StaticPodsPolicy int
+
+const (
+	// StaticPodsPin allocates static pods like other pods. They are identified by hash of their
+	// manifest, which kubelet uses as their uid.
+	StaticPodsPin StaticPodsPolicy = iota
+	// StaticPodsSkip ignores static pods, eg. control plane pods, so they are not managed by the daemon.
+	StaticPodsSkip
+)
+
+// Option configures the agent.
+type Option func(*Agent)
+
+// WithInformerResync sets resync period of the pod informer. On resync all pods of the node are
+// delivered again as updates. Zero (the default) disables resync.
+func WithInformerResync(period time.Duration) Option {
+	return func(a *Agent) {
+		a.resync = period
+	}
+}
+
+// WithLabelSelector restricts watched pods to pods matching given label selector, eg. `tier=batch`.
+func WithLabelSelector(selector string) Option {
+	return func(a *Agent) {
+		a.labelSelector = selector
+	}
+}
+
+// WithExcludedLabels replaces DefaultExcludedLabels: pods having any of given label values are not
+// watched. Empty map makes the agent watch all pods of the node, including ctlplane pods.
+func WithExcludedLabels(excluded map[string]string) Option {
+	return func(a *Agent) {
+		a.excludedLabels = excluded
+	}
+}
+
+// podBackoff holds failures of a pod whose allocation keeps failing.
+type podBackoff struct {
+	failures  int
+	lastError string      // repeated errors are logged only with high verbosity
+	until     time.Time   // updates of the pod are not sent before this time
+	pod       *corev1.Pod // the latest version of the pod, sent when the backoff ends
+	timer     *time.Timer
+
+	containers       string              // containers sent by the last full update of the pod, see containersKey
+	failedContainers map[string]struct{} // containers failed by the last update, retried by partial update
+}
+
+// recordFailedContainers records containers of the pod failed by the update, as reported by the daemon
+// with the error. Containers sent by full updates are remembered, so that failed containers are retried
+// only as long as containers of the pod do not change, eg. are not restarted with new ids.
+func (b *podBackoff) recordFailedContainers(req *ctlplaneapi.UpdatePodRequest, reply *ctlplaneapi.PodAllocationReply) {
+	b.failedContainers = make(map[string]struct{})
+	for _, c := range reply.GetContainerStatuses() {
+		if c.Status == ctlplaneapi.ContainerStatus_FAILED {
+			b.failedContainers[c.ContainerId] = struct{}{}
+		}
+	}
+	if !req.Partial {
+		b.containers = containersKey(req.Containers)
+	}
+}
+
+// retryFailedContainers turns the update of the pod into partial update of containers failed by the
+// previous update, so that containers allocated before are not sent again. Returns false and keeps the
+// full update if no container failed or containers of the pod changed since.
+func (b *podBackoff) retryFailedContainers(req *ctlplaneapi.UpdatePodRequest) bool {
+	if len(b.failedContainers) == 0 || b.containers == "" || containersKey(req.Containers) != b.containers {
+		return false
+	}
+	failed := make([]*ctlplaneapi.ContainerInfo, 0, len(b.failedContainers))
+	for _, c := range req.Containers {
+		if _, ok := b.failedContainers[c.ContainerId]; ok {
+			failed = append(failed, c)
+		}
+	}
+	req.Containers, req.Partial = failed, true
+	return true
+}
+
+// containersKey returns the containers, including their resources, encoded into a single comparable
+// string.
+func containersKey(containers []*ctlplaneapi.ContainerInfo) string {
+	encoded := make([]string, 0, len(containers))
+	for _, c := range containers {
+		b, err := proto.MarshalOptions{Deterministic: true}.Marshal(c)
+		if err != nil {
+			return ""
+		}
+		encoded = append(encoded, string(b))
+	}
+	sort.Strings(encoded)
+	return strings.Join(encoded, "\x00")
+}
+
+func containerIDs(containers []*ctlplaneapi.ContainerInfo) []string {
+	ids := make([]string, 0, len(containers))
+	for _, c := range containers {
+		ids = append(ids, c.ContainerId)
+	}
+	return ids
 }
 
 // NewAgent returns new agent with fields properly initialized.
-func NewAgent(context context.Context, ctlPlaneClient ctlplaneapi.ControlPlaneClient, namespacePrefix string) *Agent {
+func NewAgent(
+	context context.Context,
+	ctlPlaneClient ctlplaneapi.ControlPlaneClient,
+	namespacePrefix string,
+	opts ...Option,
+) *Agent {
 	logger, err := logr.FromContext(context)
 	if err != nil {
 		klog.Fatal("no logger provided")
 	}
-	return &Agent{
+	a := &Agent{
 		ctlPlaneClient:  ctlPlaneClient,
 		namespacePrefix: namespacePrefix,
 		addedPods:       make(map[types.UID]bool),
+		backoffs:        make(map[types.UID]*podBackoff),
 		ctx:             context,
 		callTimeout:     defaultTimeout,
 		logger:          logger.WithName("agent"),
+		excludedLabels:  DefaultExcludedLabels,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// podSelector returns label selector of pods watched by the agent: the configured selector and
+// exclusion of each excluded label value.
+func (a *Agent) podSelector() (string, error) {
+	selector, err := labels.Parse(a.labelSelector)
+	if err != nil {
+		return "", fmt.Errorf("invalid label selector %q: %w", a.labelSelector, err)
+	}
+	excluded, err := labels.ValidatedSelectorFromSet(a.excludedLabels)
+	if err != nil {
+		return "", fmt.Errorf("invalid excluded labels: %w", err)
+	}
+	requirements, _ := excluded.Requirements()
+	for _, r := range requirements {
+		notEqual, err := labels.NewRequirement(r.Key(), selection.NotEquals, r.Values().List())
+		if err != nil {
+			return "", fmt.Errorf("invalid excluded labels: %w", err)
+		}
+		selector = selector.Add(*notEqual)
+	}
+	return selector.String(), nil
+}
+
+// WithStaticPods sets how the agent serves static pods, StaticPodsPin by default.
+func WithStaticPods(policy StaticPodsPolicy) Option {
+	return func(a *Agent) {
+		a.staticPods = policy
 	}
 }
 
@@ -62,9 +230,14 @@ func (a *Agent) context() (context.Context, context.CancelFunc) {
 
 // Run runs agent loop in a goroutine.
 func (a *Agent) Run(clusterClient kubernetes.Interface, nodeName string) error {
-	factory := informers.NewSharedInformerFactoryWithOptions(clusterClient, 0, informers.WithNamespace(""),
+	labelSelector, err := a.podSelector()
+	if err != nil {
+		a.logger.Error(err, "cannot watch pods")
+		return err
+	}
+	factory := informers.NewSharedInformerFactoryWithOptions(clusterClient, a.resync, informers.WithNamespace(""),
 		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
-			o.LabelSelector = "app!=ctlplane-daemonset"
+			o.LabelSelector = labelSelector
 			o.FieldSelector = fmt.Sprintf("spec.nodeName=%s", nodeName)
 		}),
 	)
@@ -73,15 +246,23 @@ func (a *Agent) Run(clusterClient kubernetes.Interface, nodeName string) error {
 
 	defer runtime.HandleCrash()
 
+	if a.recorder == nil {
+		broadcaster := record.NewBroadcaster()
+		broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clusterClient.CoreV1().Events("")})
+		a.recorder = broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: eventComponent, Host: nodeName})
+	}
+	a.node = nodeReference(nodeName)
+
 	go factory.Start(a.ctx.Done())
 
-	a.logger.Info("syncing cache")
+	a.logger.Info("syncing cache", "labelSelector", labelSelector, "resync", a.resync)
 	synced := cache.WaitForNamedCacheSync("ctlplane-agent:"+nodeName, a.ctx.Done(), informer.HasSynced)
 	if !synced {
 		a.logger.Error(ErrCannotSync, "could not sync k8s state")
 		return ErrCannotSync
 	}
 
+	a.createExistingPods(informer.GetStore().List())
 	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		UpdateFunc: a.update,
 		DeleteFunc: a.delete,
@@ -105,40 +286,32 @@ func (a *Agent) update(_ interface{}, newobj interface{}) {
 		return
 	}
 
-	logger = logger.WithValues("PID", p.UID)
+	pid := podID(p)
+	logger = logger.WithValues("PID", pid)
 
-	if !strings.HasPrefix(p.Namespace, a.namespacePrefix) {
-		logger.V(2).Info("pod namespace does not contain prefix", "namespace", p.Namespace, "prefix", a.namespacePrefix)
+	if !a.readyForAllocation(p, logger) {
 		return
 	}
-
-	if p.DeletionTimestamp != nil {
-		logger.Info("pod has deletion timestamp, ignoring")
-		return
-	}
-
-	allContainersReady := true
-	for _, c := range p.Status.ContainerStatuses {
-		if c.ContainerID == "" || !c.Ready {
-			allContainersReady = false
-			break
-		}
-	}
-	logger.V(2).Info("received pod update", "allContainersReady", allContainersReady)
-
-	if !allContainersReady || len(p.Status.ContainerStatuses) != len(p.Spec.Containers) {
+	if b, ok := a.backoffs[pid]; ok && time.Now().Before(b.until) {
+		b.pod = p
+		logger.V(2).Info("allocation backed off", "retryAt", b.until)
 		return
 	}
 
 	var (
-		reply *ctlplaneapi.PodAllocationReply
-		err   error
+		reply  *ctlplaneapi.PodAllocationReply
+		update *ctlplaneapi.UpdatePodRequest
+		err    error
 	)
-	if a.addedPods[p.UID] {
+	if a.addedPods[pid] {
 		in, reqErr := GetUpdatePodRequest(p)
 		if reqErr != nil {
 			err = reqErr
 		} else {
+			if b, ok := a.backoffs[pid]; ok && b.retryFailedContainers(in) {
+				logger.Info("retrying failed containers", "containers", containerIDs(in.Containers))
+			}
+			update = in
 			logger.Info("sending update pod req")
 			ctx, cancel := a.context()
 			defer cancel()
@@ -153,19 +326,230 @@ func (a *Agent) update(_ interface{}, newobj interface{}) {
 			ctx, cancel := a.context()
 			defer cancel()
 			reply, err = a.ctlPlaneClient.CreatePod(ctx, in)
-			a.addedPods[p.UID] = true
+			a.addedPods[pid] = true
 		}
 	}
 
+	if ctlplaneapi.IsStaleRequest(err) {
+		logger.V(2).Info("daemon applied newer version of the pod, request ignored", "error", err.Error())
+		return
+	}
 	if err != nil {
-		logger.Error(err, "allocation error")
-		a.unsuccessfulAttempt()
+		a.allocationFailed(p, err, logger)
+		logFailedContainers(logger, ctlplaneapi.PartialReply(err))
+		if update != nil {
+			a.backoffs[pid].recordFailedContainers(update, ctlplaneapi.PartialReply(err))
+		}
+		a.reportAllocationError(ctlplaneapi.ErrorInfo(err))
+		// repeated failures of a single pod, reported by the daemon with retry hint, do not mean that
+		// the daemon is broken
+		if ctlplaneapi.RetryDelay(err) == 0 {
+			a.unsuccessfulAttempt()
+		}
 	} else {
-		logger.Info("allocation done", "reply", reply)
+		a.clearBackoff(pid)
+		logAllocation(logger, reply)
 		a.successfulAttempt()
 	}
 }
 
+// allocationFailed logs allocation error of the pod, unless it is the same as the previous one, and
+// backs off the pod if the daemon asked for it: updates of the pod are not sent until the retry delay
+// passes, then the latest version of the pod is sent.
+func (a *Agent) allocationFailed(p *corev1.Pod, err error, logger logr.Logger) {
+	pid := podID(p)
+	b, ok := a.backoffs[pid]
+	if !ok {
+		b = &podBackoff{}
+		a.backoffs[pid] = b
+	}
+	b.failures++
+	if err.Error() != b.lastError {
+		logger.Error(err, "allocation error", "failures", b.failures)
+		b.lastError = err.Error()
+	} else {
+		logger.V(2).Info("allocation error repeated", "error", err.Error(), "failures", b.failures)
+	}
+
+	delay := ctlplaneapi.RetryDelay(err)
+	if delay <= 0 {
+		return
+	}
+	logger.Info("backing off pod", "delay", delay)
+	b.until = time.Now().Add(delay)
+	b.pod = p
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	b.timer = time.AfterFunc(delay, func() { a.retry(pid) })
+}
+
+// retry sends the latest version of the pod after its backoff ends.
+func (a *Agent) retry(uid types.UID) {
+	a.mu.Lock()
+	b, ok := a.backoffs[uid]
+	var pod *corev1.Pod
+	if ok {
+		pod = b.pod
+	}
+	a.mu.Unlock()
+	if pod == nil || a.ctx.Err() != nil {
+		return
+	}
+	a.update(nil, pod)
+}
+
+func (a *Agent) clearBackoff(uid types.UID) {
+	if b, ok := a.backoffs[uid]; ok && b.timer != nil {
+		b.timer.Stop()
+	}
+	delete(a.backoffs, uid)
+}
+
+// SetNamespacePrefix changes the prefix of namespaces served by the agent, eg. when its configuration is
+// reloaded. Pods of namespaces served from now on are created on their next update or resync, pods created
+// before keep their allocation until deleted.
+func (a *Agent) SetNamespacePrefix(prefix string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.namespacePrefix = prefix
+}
+
+// servedPod checks if the pod is in a namespace served by the agent and is not a skipped static pod.
+func (a *Agent) servedPod(p *corev1.Pod, logger logr.Logger) bool {
+	if !strings.HasPrefix(p.Namespace, a.namespacePrefix) {
+		logger.V(2).Info("pod namespace does not contain prefix", "namespace", p.Namespace, "prefix", a.namespacePrefix)
+		return false
+	}
+	if a.staticPods == StaticPodsSkip && isMirrorPod(p) {
+		logger.V(2).Info("static pod skipped", "name", p.Name, "namespace", p.Namespace)
+		return false
+	}
+	return true
+}
+
+// readyForAllocation checks if the pod is served by the agent and all its containers are running.
+func (a *Agent) readyForAllocation(p *corev1.Pod, logger logr.Logger) bool {
+	if !a.servedPod(p, logger) {
+		return false
+	}
+
+	if p.DeletionTimestamp != nil {
+		logger.Info("pod has deletion timestamp, ignoring")
+		return false
+	}
+
+	allContainersReady := true
+	for _, c := range p.Status.ContainerStatuses {
+		if c.ContainerID == "" || !c.Ready {
+			allContainersReady = false
+			break
+		}
+	}
+	logger.V(2).Info("received pod update", "allContainersReady", allContainersReady)
+
+	return allContainersReady && len(p.Status.ContainerStatuses) == len(p.Spec.Containers)
+}
+
+// createExistingPods allocates all pods running on the node when the agent starts, using a single
+// CreatePods call.
+func (a *Agent) createExistingPods(objs []interface{}) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	logger := a.logger.WithName("createExisting")
+	req := ctlplaneapi.CreatePodsRequest{}
+	for _, obj := range objs {
+		p, ok := obj.(*corev1.Pod)
+		if !ok || a.addedPods[podID(p)] || !a.readyForAllocation(p, logger.WithValues("PID", podID(p))) {
+			continue
+		}
+		in, err := GetCreatePodRequest(p)
+		if err != nil {
+			logger.Error(err, "cannot create pod request", "PID", podID(p))
+			continue
+		}
+		req.Pods = append(req.Pods, in)
+	}
+	if len(req.Pods) == 0 {
+		return
+	}
+
+	logger.Info("sending create pods req", "pods", len(req.Pods))
+	ctx, cancel := a.context()
+	defer cancel()
+	reply, err := a.ctlPlaneClient.CreatePods(ctx, &req)
+	if err != nil {
+		logger.Error(err, "allocation error")
+		a.unsuccessfulAttempt()
+		return
+	}
+	for _, result := range reply.Results {
+		a.addedPods[types.UID(result.PodId)] = true
+		podLogger := logger.WithValues("PID", result.PodId)
+		if result.Error != "" {
+			podLogger.Error(errors.New(result.Error), "allocation error") //nolint: goerr113
+			a.reportAllocationError(&errdetails.ErrorInfo{Reason: result.ErrorReason, Metadata: result.ErrorMetadata})
+			continue
+		}
+		logAllocation(podLogger, result.Reply)
+	}
+	a.successfulAttempt()
+}
+
+// nodeReference returns reference of the node object, used as the subject of agent events.
+func nodeReference(nodeName string) *corev1.ObjectReference {
+	return &corev1.ObjectReference{Kind: "Node", Name: nodeName, UID: types.UID(nodeName)}
+}
+
+// reportAllocationError emits a warning event on the node object when the daemon reports runtime
+// mismatch, which usually means misconfiguration of the daemon. The event is emitted only once.
+func (a *Agent) reportAllocationError(info *errdetails.ErrorInfo) {
+	if info == nil || info.Reason != ctlplaneapi.ReasonRuntimeMismatch || a.runtimeMismatchReported {
+		return
+	}
+	a.runtimeMismatchReported = true
+	if a.recorder == nil || a.node == nil {
+		return
+	}
+	a.recorder.Eventf(
+		a.node,
+		corev1.EventTypeWarning,
+		runtimeMismatchReason,
+		"Container id prefix %q does not match runtime configured in ctlplane daemon (expected %q), check -runtime option",
+		info.Metadata["containerIdPrefix"],
+		info.Metadata["expectedPrefix"],
+	)
+}
+
+func logAllocation(logger logr.Logger, reply *ctlplaneapi.PodAllocationReply) {
+	logger.Info("allocation done", "reply", reply)
+	if reply.Unmanaged {
+		logger.Info("pod is not managed by the daemon, it runs in shared pool", "reason", reply.UnmanagedReason)
+	}
+	for _, c := range reply.ContainersAllocations {
+		logger.V(2).Info(
+			"container allocation",
+			"cid", c.ContainerId,
+			"qos", c.Qos,
+			"exclusive", c.Exclusive,
+			"memoryNodes", c.MemoryNodes,
+			"cgroupPath", c.CgroupPath,
+		)
+	}
+}
+
+// logFailedContainers logs containers the daemon could not allocate, as reported with error of partially
+// failed request. Containers allocated by the request are kept, the next update of the pod retries only
+// the failed ones, see retryFailedContainers.
+func logFailedContainers(logger logr.Logger, reply *ctlplaneapi.PodAllocationReply) {
+	for _, c := range reply.GetContainerStatuses() {
+		if c.Status == ctlplaneapi.ContainerStatus_FAILED {
+			logger.Info("container allocation failed", "cid", c.ContainerId, "reason", c.Reason)
+		}
+	}
+}
+
 // delete is invoked after pod has been deleted.
 func (a *Agent) delete(obj interface{}) {
 	a.mu.Lock()
@@ -180,10 +564,10 @@ func (a *Agent) delete(obj interface{}) {
 		return
 	}
 
-	logger = logger.WithValues("PID", p.UID)
+	pid := podID(p)
+	logger = logger.WithValues("PID", pid)
 
-	if !strings.HasPrefix(p.Namespace, a.namespacePrefix) {
-		logger.V(2).Info("pod namespace does not contain prefix", "namespace", p.Namespace, "prefix", a.namespacePrefix)
+	if !a.addedPods[pid] && !a.servedPod(p, logger) { // added pods are deleted even if no longer served
 		return
 	}
 
@@ -192,17 +576,51 @@ func (a *Agent) delete(obj interface{}) {
 	ctx, cancel := a.context()
 	defer cancel()
 	reply, err := a.ctlPlaneClient.DeletePod(ctx, in)
-	delete(a.addedPods, p.UID)
+	delete(a.addedPods, pid)
+	a.clearBackoff(pid)
 
 	if err != nil {
 		logger.Error(err, "deletion failed")
 		a.unsuccessfulAttempt()
 	} else {
 		logger.Info("deletion done", "reply", reply)
+		a.reportReleasedCpus(p, reply)
 		a.successfulAttempt()
 	}
 }
 
+// reportReleasedCpus emits an event on the deleted pod listing cpus freed by its containers and pools they
+// returned to.
+func (a *Agent) reportReleasedCpus(p *corev1.Pod, reply *ctlplaneapi.PodAllocationReply) {
+	if a.recorder == nil || reply == nil {
+		return
+	}
+	released := []string{}
+	for _, c := range reply.ContainersAllocations {
+		if len(c.CpuSet) == 0 {
+			continue
+		}
+		released = append(released, fmt.Sprintf("%s: %s to %s pool", c.ContainerId, cpuSetString(c.CpuSet), c.ReleasedPool))
+	}
+	if len(released) == 0 {
+		return
+	}
+	a.recorder.Eventf(p, corev1.EventTypeNormal, cpusReleasedReason, "Released cpus %s", strings.Join(released, ", "))
+}
+
+// cpuSetString formats cpu buckets in cpuset list format, eg. "1-3,5".
+func cpuSetString(cpuSet []*ctlplaneapi.CPUSet) string {
+	buckets := make([]string, 0, len(cpuSet))
+	for _, b := range cpuSet {
+		if b.StartCPU == b.EndCPU {
+			buckets = append(buckets, strconv.Itoa(int(b.StartCPU)))
+		} else {
+			buckets = append(buckets, fmt.Sprintf("%d-%d", b.StartCPU, b.EndCPU))
+		}
+	}
+	return strings.Join(buckets, ",")
+}
+
 func (a *Agent) successfulAttempt() {
 	a.numConsecutiveUnsuccessfulAttempts = 0
 }
@@ -10,8 +10,14 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 
 	"resourcemanagement.controlplane/pkg/ctlplaneapi"
 )
@@ -47,6 +53,150 @@ func (c *ControlPlaneClientMock) DeletePod(
 	return args.Get(0).(*ctlplaneapi.PodAllocationReply), args.Error(1)
 }
 
+func (c *ControlPlaneClientMock) CreatePods(
+	ctx context.Context,
+	in *ctlplaneapi.CreatePodsRequest,
+	opts ...grpc.CallOption,
+) (*ctlplaneapi.CreatePodsReply, error) {
+	args := c.Called(ctx, in)
+	return args.Get(0).(*ctlplaneapi.CreatePodsReply), args.Error(1)
+}
+
+func (c *ControlPlaneClientMock) GetPod(
+	ctx context.Context,
+	in *ctlplaneapi.GetPodRequest,
+	opts ...grpc.CallOption,
+) (*ctlplaneapi.PodAllocationReply, error) {
+	args := c.Called(ctx, in)
+	return args.Get(0).(*ctlplaneapi.PodAllocationReply), args.Error(1)
+}
+
+func (c *ControlPlaneClientMock) ListPods(
+	ctx context.Context,
+	in *ctlplaneapi.ListPodsRequest,
+	opts ...grpc.CallOption,
+) (*ctlplaneapi.ListPodsReply, error) {
+	args := c.Called(ctx, in)
+	return args.Get(0).(*ctlplaneapi.ListPodsReply), args.Error(1)
+}
+
+func (c *ControlPlaneClientMock) GetContainer(
+	ctx context.Context,
+	in *ctlplaneapi.GetContainerRequest,
+	opts ...grpc.CallOption,
+) (*ctlplaneapi.ContainerAllocationReply, error) {
+	args := c.Called(ctx, in)
+	return args.Get(0).(*ctlplaneapi.ContainerAllocationReply), args.Error(1)
+}
+
+func (c *ControlPlaneClientMock) GetDaemonInfo(
+	ctx context.Context,
+	in *ctlplaneapi.GetDaemonInfoRequest,
+	opts ...grpc.CallOption,
+) (*ctlplaneapi.DaemonInfoReply, error) {
+	args := c.Called(ctx, in)
+	return args.Get(0).(*ctlplaneapi.DaemonInfoReply), args.Error(1)
+}
+
+func (c *ControlPlaneClientMock) GetConfig(
+	ctx context.Context,
+	in *ctlplaneapi.GetConfigRequest,
+	opts ...grpc.CallOption,
+) (*ctlplaneapi.ConfigReply, error) {
+	args := c.Called(ctx, in)
+	return args.Get(0).(*ctlplaneapi.ConfigReply), args.Error(1)
+}
+
+func (c *ControlPlaneClientMock) GetAllocations(
+	ctx context.Context,
+	in *ctlplaneapi.GetAllocationsRequest,
+	opts ...grpc.CallOption,
+) (*ctlplaneapi.AllocationsReply, error) {
+	args := c.Called(ctx, in)
+	return args.Get(0).(*ctlplaneapi.AllocationsReply), args.Error(1)
+}
+
+func (c *ControlPlaneClientMock) MigrateContainer(
+	ctx context.Context,
+	in *ctlplaneapi.MigrateContainerRequest,
+	opts ...grpc.CallOption,
+) (*ctlplaneapi.ContainerAllocationReply, error) {
+	args := c.Called(ctx, in)
+	return args.Get(0).(*ctlplaneapi.ContainerAllocationReply), args.Error(1)
+}
+
+func (c *ControlPlaneClientMock) PlanDefragmentation(
+	ctx context.Context,
+	in *ctlplaneapi.PlanDefragmentationRequest,
+	opts ...grpc.CallOption,
+) (*ctlplaneapi.DefragmentationPlanReply, error) {
+	args := c.Called(ctx, in)
+	return args.Get(0).(*ctlplaneapi.DefragmentationPlanReply), args.Error(1)
+}
+
+func (c *ControlPlaneClientMock) CreateNamespaceBucket(
+	ctx context.Context,
+	in *ctlplaneapi.CreateNamespaceBucketRequest,
+	opts ...grpc.CallOption,
+) (*ctlplaneapi.NamespaceBucketReply, error) {
+	args := c.Called(ctx, in)
+	return args.Get(0).(*ctlplaneapi.NamespaceBucketReply), args.Error(1)
+}
+
+func (c *ControlPlaneClientMock) DeleteNamespaceBucket(
+	ctx context.Context,
+	in *ctlplaneapi.DeleteNamespaceBucketRequest,
+	opts ...grpc.CallOption,
+) (*ctlplaneapi.NamespaceBucketReply, error) {
+	args := c.Called(ctx, in)
+	return args.Get(0).(*ctlplaneapi.NamespaceBucketReply), args.Error(1)
+}
+
+func (c *ControlPlaneClientMock) ReserveCPUPool(
+	ctx context.Context,
+	in *ctlplaneapi.ReserveCPUPoolRequest,
+	opts ...grpc.CallOption,
+) (*ctlplaneapi.CPUPoolReply, error) {
+	args := c.Called(ctx, in)
+	return args.Get(0).(*ctlplaneapi.CPUPoolReply), args.Error(1)
+}
+
+func (c *ControlPlaneClientMock) ReleaseCPUPool(
+	ctx context.Context,
+	in *ctlplaneapi.ReleaseCPUPoolRequest,
+	opts ...grpc.CallOption,
+) (*ctlplaneapi.CPUPoolReply, error) {
+	args := c.Called(ctx, in)
+	return args.Get(0).(*ctlplaneapi.CPUPoolReply), args.Error(1)
+}
+
+func (c *ControlPlaneClientMock) SetNamespaceConfig(
+	ctx context.Context,
+	in *ctlplaneapi.SetNamespaceConfigRequest,
+	opts ...grpc.CallOption,
+) (*ctlplaneapi.NamespaceConfigReply, error) {
+	args := c.Called(ctx, in)
+	return args.Get(0).(*ctlplaneapi.NamespaceConfigReply), args.Error(1)
+}
+
+func (c *ControlPlaneClientMock) DeleteNamespaceConfig(
+	ctx context.Context,
+	in *ctlplaneapi.DeleteNamespaceConfigRequest,
+	opts ...grpc.CallOption,
+) (*ctlplaneapi.NamespaceConfigReply, error) {
+	args := c.Called(ctx, in)
+	return args.Get(0).(*ctlplaneapi.NamespaceConfigReply), args.Error(1)
+}
+
+func (c *ControlPlaneClientMock) ListUsage(
+	ctx context.Context,
+	in *ctlplaneapi.ListUsageRequest,
+	opts ...grpc.CallOption,
+) (*ctlplaneapi.ListUsageReply, error) {
+	args := c.Called(ctx, in)
+	return args.Get(0).(*ctlplaneapi.ListUsageReply), args.Error(1)
+}
+
 var _ ctlplaneapi.ControlPlaneClient = &ControlPlaneClientMock{}
 var testCtx = logr.NewContext(context.TODO(), logr.Discard())
 
@@ -166,3 +316,420 @@ func TestDeleteIgnoresNamespaceWithWrongPrefix(t *testing.T) {
 
 	mock.AssertExpectations(t)
 }
+
+func TestSetNamespacePrefix(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	pod := genTestPods()
+	podCreateRequest, err := GetCreatePodRequest(&pod)
+	require.Nil(t, err)
+	agent := NewAgent(testCtx, &cpMock, "test")
+
+	agent.update(struct{}{}, &pod)
+	agent.SetNamespacePrefix("")
+	cpMock.On("CreatePod", mock.Anything, podCreateRequest).Return(&ctlplaneapi.PodAllocationReply{}, nil)
+	agent.update(struct{}{}, &pod)
+
+	cpMock.AssertExpectations(t)
+}
+
+func TestDeletePodAddedBeforeNamespacePrefixChanged(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	pod := genTestPods()
+	podCreateRequest, err := GetCreatePodRequest(&pod)
+	require.Nil(t, err)
+	agent := NewAgent(testCtx, &cpMock, "")
+
+	cpMock.On("CreatePod", mock.Anything, podCreateRequest).Return(&ctlplaneapi.PodAllocationReply{}, nil)
+	agent.update(struct{}{}, &pod)
+	agent.SetNamespacePrefix("test")
+	cpMock.On("DeletePod", mock.Anything, GetDeletePodRequest(&pod)).Return(&ctlplaneapi.PodAllocationReply{}, nil)
+	agent.delete(&pod)
+
+	cpMock.AssertExpectations(t)
+}
+
+func TestCreateExistingPodsSendsSingleBatch(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	first := genTestPods()
+	second := genTestPods()
+	second.UID = "456"
+	notReady := genTestPods()
+	notReady.UID = "789"
+	notReady.Status.ContainerStatuses[0].Ready = false
+	firstRequest, err := GetCreatePodRequest(&first)
+	require.Nil(t, err)
+	secondRequest, err := GetCreatePodRequest(&second)
+	require.Nil(t, err)
+	updateRequest, err := GetUpdatePodRequest(&first)
+	require.Nil(t, err)
+	agent := NewAgent(testCtx, &cpMock, "")
+
+	cpMock.On(
+		"CreatePods",
+		mock.Anything,
+		&ctlplaneapi.CreatePodsRequest{Pods: []*ctlplaneapi.CreatePodRequest{firstRequest, secondRequest}},
+	).Return(&ctlplaneapi.CreatePodsReply{
+		Results: []*ctlplaneapi.CreatePodResult{
+			{PodId: firstRequest.PodId, Reply: &ctlplaneapi.PodAllocationReply{}},
+			{PodId: secondRequest.PodId, Error: "no cpus"},
+		},
+	}, nil)
+	agent.createExistingPods([]interface{}{&first, &notReady, &second})
+	cpMock.On("UpdatePod", mock.Anything, updateRequest).Return(&ctlplaneapi.PodAllocationReply{}, nil)
+	agent.update(struct{}{}, &first)
+
+	cpMock.AssertExpectations(t)
+	assert.Equal(t, uint(0), agent.numConsecutiveUnsuccessfulAttempts)
+}
+
+func TestCreateExistingPodsWithoutReadyPods(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	pod := genTestPods()
+	pod.Status.ContainerStatuses[0].Ready = false
+	agent := NewAgent(testCtx, &cpMock, "")
+
+	agent.createExistingPods([]interface{}{&pod})
+
+	cpMock.AssertExpectations(t)
+}
+
+func TestCreateExistingPodsFailure(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	pod := genTestPods()
+	createRequest, err := GetCreatePodRequest(&pod)
+	require.Nil(t, err)
+	agent := NewAgent(testCtx, &cpMock, "")
+
+	err = errors.New("daemon unavailable") //nolint
+	cpMock.On("CreatePods", mock.Anything, mock.Anything).Return(&ctlplaneapi.CreatePodsReply{}, err)
+	agent.createExistingPods([]interface{}{&pod})
+	assert.Equal(t, uint(1), agent.numConsecutiveUnsuccessfulAttempts)
+
+	cpMock.On("CreatePod", mock.Anything, createRequest).Return(&ctlplaneapi.PodAllocationReply{}, nil)
+	agent.update(struct{}{}, &pod)
+	cpMock.AssertExpectations(t)
+}
+
+func runtimeMismatchErrorForTest(t *testing.T) error {
+	s, err := status.New(codes.Unavailable, "runtime mismatch").WithDetails(&errdetails.ErrorInfo{
+		Reason:   ctlplaneapi.ReasonRuntimeMismatch,
+		Metadata: map[string]string{"containerIdPrefix": "docker://", "expectedPrefix": "containerd://"},
+	})
+	require.Nil(t, err)
+	return s.Err()
+}
+
+func newAgentWithRecorder(cpMock *ControlPlaneClientMock) (*Agent, *record.FakeRecorder) {
+	agent := NewAgent(testCtx, cpMock, "")
+	recorder := record.NewFakeRecorder(10)
+	agent.recorder = recorder
+	agent.node = nodeReference("node")
+	return agent, recorder
+}
+
+func TestRuntimeMismatchEmitsNodeEventOnce(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	pod := genTestPods()
+	agent, recorder := newAgentWithRecorder(&cpMock)
+	cpMock.On("CreatePod", mock.Anything, mock.Anything).
+		Return(&ctlplaneapi.PodAllocationReply{}, runtimeMismatchErrorForTest(t))
+	cpMock.On("UpdatePod", mock.Anything, mock.Anything).
+		Return(&ctlplaneapi.PodAllocationReply{}, runtimeMismatchErrorForTest(t))
+
+	agent.update(struct{}{}, &pod)
+	agent.update(struct{}{}, &pod)
+
+	require.Len(t, recorder.Events, 1)
+	event := <-recorder.Events
+	assert.Contains(t, event, "Warning RuntimeMismatch")
+	assert.Contains(t, event, `"docker://"`)
+	assert.Contains(t, event, `"containerd://"`)
+}
+
+func TestDeletePodEmitsReleasedCpusEvent(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	pod := genTestPods()
+	agent, recorder := newAgentWithRecorder(&cpMock)
+	cpMock.On("DeletePod", mock.Anything, mock.Anything).Return(&ctlplaneapi.PodAllocationReply{
+		ContainersAllocations: []*ctlplaneapi.ContainerAllocationInfo{
+			{
+				ContainerId:  "containerd://a",
+				CpuSet:       []*ctlplaneapi.CPUSet{{StartCPU: 1, EndCPU: 3}, {StartCPU: 5, EndCPU: 5}},
+				ReleasedPool: "shared",
+			},
+			{ContainerId: "containerd://b"},
+		},
+	}, nil)
+
+	agent.delete(&pod)
+
+	require.Len(t, recorder.Events, 1)
+	event := <-recorder.Events
+	assert.Contains(t, event, "Normal CpusReleased")
+	assert.Contains(t, event, "containerd://a: 1-3,5 to shared pool")
+	assert.NotContains(t, event, "containerd://b")
+}
+
+func TestDeletePodWithoutReleasedCpusEmitsNoEvent(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	pod := genTestPods()
+	agent, recorder := newAgentWithRecorder(&cpMock)
+	cpMock.On("DeletePod", mock.Anything, mock.Anything).Return(&ctlplaneapi.PodAllocationReply{}, nil)
+
+	agent.delete(&pod)
+
+	assert.Empty(t, recorder.Events)
+}
+
+func TestOtherErrorsEmitNoNodeEvent(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	pod := genTestPods()
+	agent, recorder := newAgentWithRecorder(&cpMock)
+	cpMock.On("CreatePod", mock.Anything, mock.Anything).
+		Return(&ctlplaneapi.PodAllocationReply{}, status.Error(codes.Unavailable, "no cpus"))
+
+	agent.update(struct{}{}, &pod)
+
+	assert.Empty(t, recorder.Events)
+}
+
+func TestCreateExistingPodsRuntimeMismatchEmitsNodeEvent(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	pod := genTestPods()
+	agent, recorder := newAgentWithRecorder(&cpMock)
+	cpMock.On("CreatePods", mock.Anything, mock.Anything).Return(&ctlplaneapi.CreatePodsReply{
+		Results: []*ctlplaneapi.CreatePodResult{{
+			PodId:         string(pod.UID),
+			Error:         "runtime mismatch",
+			ErrorReason:   ctlplaneapi.ReasonRuntimeMismatch,
+			ErrorMetadata: map[string]string{"containerIdPrefix": "docker://", "expectedPrefix": "containerd://"},
+		}},
+	}, nil)
+
+	agent.createExistingPods([]interface{}{&pod})
+
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "Warning RuntimeMismatch")
+}
+
+func retryErrorForTest(t *testing.T, delay time.Duration) error {
+	s, err := status.New(codes.Unavailable, "no cpus").WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(delay),
+	})
+	require.Nil(t, err)
+	return s.Err()
+}
+
+func TestUpdateBacksOffPodOnRetryHint(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	pod := genTestPods()
+	agent := NewAgent(testCtx, &cpMock, "")
+	cpMock.On("CreatePod", mock.Anything, mock.Anything).
+		Return(&ctlplaneapi.PodAllocationReply{}, retryErrorForTest(t, time.Hour))
+
+	agent.update(struct{}{}, &pod)
+	agent.update(struct{}{}, &pod)
+
+	cpMock.AssertNumberOfCalls(t, "CreatePod", 1)
+	assert.Equal(t, uint(0), agent.numConsecutiveUnsuccessfulAttempts)
+	require.Contains(t, agent.backoffs, pod.UID)
+	assert.Equal(t, 1, agent.backoffs[pod.UID].failures)
+
+	cpMock.On("DeletePod", mock.Anything, mock.Anything).Return(&ctlplaneapi.PodAllocationReply{}, nil)
+	agent.delete(&pod)
+	assert.NotContains(t, agent.backoffs, pod.UID)
+}
+
+func TestUpdateRetriesPodAfterBackoff(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	pod := genTestPods()
+	updateRequest, err := GetUpdatePodRequest(&pod)
+	require.Nil(t, err)
+	agent := NewAgent(testCtx, &cpMock, "")
+	cpMock.On("CreatePod", mock.Anything, mock.Anything).
+		Return(&ctlplaneapi.PodAllocationReply{}, retryErrorForTest(t, 10*time.Millisecond))
+	cpMock.On("UpdatePod", mock.Anything, updateRequest).Return(&ctlplaneapi.PodAllocationReply{}, nil)
+
+	agent.update(struct{}{}, &pod)
+
+	assert.Eventually(t, func() bool {
+		agent.mu.Lock()
+		defer agent.mu.Unlock()
+		return len(agent.backoffs) == 0
+	}, time.Second, time.Millisecond)
+	cpMock.AssertExpectations(t)
+}
+
+func TestRepeatedErrorsAreCounted(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	pod := genTestPods()
+	agent := NewAgent(testCtx, &cpMock, "")
+	err := errors.New("no cpus") //nolint
+	cpMock.On("CreatePod", mock.Anything, mock.Anything).Return(&ctlplaneapi.PodAllocationReply{}, err)
+	cpMock.On("UpdatePod", mock.Anything, mock.Anything).Return(&ctlplaneapi.PodAllocationReply{}, err)
+
+	agent.update(struct{}{}, &pod)
+	agent.update(struct{}{}, &pod)
+
+	require.Contains(t, agent.backoffs, pod.UID)
+	assert.Equal(t, 2, agent.backoffs[pod.UID].failures)
+	assert.Equal(t, "no cpus", agent.backoffs[pod.UID].lastError)
+	assert.True(t, agent.backoffs[pod.UID].until.IsZero())
+}
+
+func TestStaleRequestIsIgnored(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	pod := genTestPods()
+	agent := NewAgent(testCtx, &cpMock, "")
+	cpMock.On("CreatePod", mock.Anything, mock.Anything).Return(&ctlplaneapi.PodAllocationReply{}, nil)
+	cpMock.On("UpdatePod", mock.Anything, mock.Anything).
+		Return(&ctlplaneapi.PodAllocationReply{}, status.Error(codes.Aborted, "stale"))
+
+	agent.update(struct{}{}, &pod)
+	agent.update(struct{}{}, &pod)
+
+	assert.NotContains(t, agent.backoffs, pod.UID)
+	assert.Zero(t, agent.numConsecutiveUnsuccessfulAttempts)
+}
+
+// partialFailureForTest returns error of update which failed the given container of the pod.
+func partialFailureForTest(t *testing.T, failed string) error {
+	s, err := status.New(codes.Unavailable, "no cpus").WithDetails(&ctlplaneapi.PodAllocationReply{
+		ContainerStatuses: []*ctlplaneapi.ContainerStatusInfo{
+			{ContainerId: "id test container 1", Status: ctlplaneapi.ContainerStatus_SKIPPED},
+			{ContainerId: failed, Status: ctlplaneapi.ContainerStatus_FAILED, Reason: "no cpus"},
+		},
+	})
+	require.Nil(t, err)
+	return s.Err()
+}
+
+func fullUpdate(r *ctlplaneapi.UpdatePodRequest) bool {
+	return !r.Partial && len(r.Containers) == 3
+}
+
+func TestUpdateRetriesOnlyFailedContainers(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	pod := genTestPods()
+	agent := NewAgent(testCtx, &cpMock, "")
+	cpMock.On("CreatePod", mock.Anything, mock.Anything).Return(&ctlplaneapi.PodAllocationReply{}, nil)
+	cpMock.On("UpdatePod", mock.Anything, mock.MatchedBy(fullUpdate)).
+		Return(&ctlplaneapi.PodAllocationReply{}, partialFailureForTest(t, "id test container 2")).Once()
+	cpMock.On("UpdatePod", mock.Anything, mock.MatchedBy(func(r *ctlplaneapi.UpdatePodRequest) bool {
+		return r.Partial && len(r.Containers) == 1 && r.Containers[0].ContainerId == "id test container 2"
+	})).Return(&ctlplaneapi.PodAllocationReply{}, nil).Once()
+	cpMock.On("UpdatePod", mock.Anything, mock.MatchedBy(fullUpdate)).Return(&ctlplaneapi.PodAllocationReply{}, nil).Once()
+
+	for i := 0; i < 4; i++ {
+		agent.update(struct{}{}, &pod)
+	}
+
+	cpMock.AssertExpectations(t)
+	assert.NotContains(t, agent.backoffs, pod.UID)
+}
+
+func TestUpdateOfRestartedContainersIsNotPartial(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	pod := genTestPods()
+	agent := NewAgent(testCtx, &cpMock, "")
+	cpMock.On("CreatePod", mock.Anything, mock.Anything).Return(&ctlplaneapi.PodAllocationReply{}, nil)
+	cpMock.On("UpdatePod", mock.Anything, mock.MatchedBy(fullUpdate)).
+		Return(&ctlplaneapi.PodAllocationReply{}, partialFailureForTest(t, "id test container 2")).Once()
+	cpMock.On("UpdatePod", mock.Anything, mock.MatchedBy(fullUpdate)).Return(&ctlplaneapi.PodAllocationReply{}, nil).Once()
+
+	agent.update(struct{}{}, &pod)
+	agent.update(struct{}{}, &pod)
+	pod.Status.ContainerStatuses[0].ContainerID = "id restarted container 1"
+	agent.update(struct{}{}, &pod)
+
+	cpMock.AssertExpectations(t)
+}
+
+func TestPodSelectorExcludesCtlplanePodsByDefault(t *testing.T) {
+	agent := NewAgent(testCtx, &ControlPlaneClientMock{}, "")
+
+	selector, err := agent.podSelector()
+
+	require.Nil(t, err)
+	assert.Equal(t, "app!=ctlplane-daemonset", selector)
+	assert.Zero(t, agent.resync)
+}
+
+func TestPodSelectorWithOptions(t *testing.T) {
+	agent := NewAgent(testCtx, &ControlPlaneClientMock{}, "",
+		WithInformerResync(time.Minute),
+		WithLabelSelector("tier in (batch,latency)"),
+		WithExcludedLabels(map[string]string{"tier": "system", "app": "monitoring"}),
+	)
+
+	selector, err := agent.podSelector()
+
+	require.Nil(t, err)
+	assert.Equal(t, "app!=monitoring,tier in (batch,latency),tier!=system", selector)
+	assert.Equal(t, time.Minute, agent.resync)
+}
+
+func TestPodSelectorWithoutExcludedLabels(t *testing.T) {
+	agent := NewAgent(testCtx, &ControlPlaneClientMock{}, "", WithExcludedLabels(map[string]string{}))
+
+	selector, err := agent.podSelector()
+
+	require.Nil(t, err)
+	assert.Empty(t, selector)
+}
+
+func TestRunFailsWithInvalidSelector(t *testing.T) {
+	for _, opt := range []Option{
+		WithLabelSelector("tier in batch"),
+		WithExcludedLabels(map[string]string{"app": "not valid"}),
+	} {
+		agent := NewAgent(testCtx, &ControlPlaneClientMock{}, "", opt)
+
+		assert.NotNil(t, agent.Run(nil, "node"))
+	}
+}
+
+func genMirrorPod() corev1.Pod {
+	pod := genTestPods()
+	pod.Annotations = map[string]string{corev1.MirrorPodAnnotationKey: "8f2e9c1ab4d7"}
+	return pod
+}
+
+func TestMirrorPodIsIdentifiedByConfigHash(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	pod := genMirrorPod()
+	agent := NewAgent(testCtx, &cpMock, "")
+	cpMock.On("CreatePod", mock.Anything, mock.Anything).Return(&ctlplaneapi.PodAllocationReply{}, nil).Once()
+	cpMock.On("UpdatePod", mock.Anything, mock.Anything).Return(&ctlplaneapi.PodAllocationReply{}, nil).Once()
+	cpMock.On("DeletePod", mock.Anything, mock.Anything).Return(&ctlplaneapi.PodAllocationReply{}, nil).Once()
+
+	agent.update(struct{}{}, &pod)
+	recreated := genMirrorPod()
+	recreated.UID = "456"
+	agent.update(struct{}{}, &recreated)
+	agent.delete(&recreated)
+
+	cpMock.AssertExpectations(t)
+	assert.Equal(t, "8f2e9c1ab4d7", cpMock.Calls[0].Arguments.Get(1).(*ctlplaneapi.CreatePodRequest).PodId)
+	assert.Equal(t, "8f2e9c1ab4d7", cpMock.Calls[1].Arguments.Get(1).(*ctlplaneapi.UpdatePodRequest).PodId)
+	assert.Equal(t, "8f2e9c1ab4d7", cpMock.Calls[2].Arguments.Get(1).(*ctlplaneapi.DeletePodRequest).PodId)
+	assert.Empty(t, agent.addedPods)
+}
+
+func TestStaticPodsAreSkipped(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	pod := genMirrorPod()
+	regular := genTestPods()
+	regular.UID = "456"
+	agent := NewAgent(testCtx, &cpMock, "", WithStaticPods(StaticPodsSkip))
+	cpMock.On("CreatePods", mock.Anything, mock.Anything).Return(&ctlplaneapi.CreatePodsReply{}, nil).Once()
+
+	agent.createExistingPods([]interface{}{&pod, &regular})
+	agent.update(struct{}{}, &pod)
+	agent.delete(&pod)
+
+	cpMock.AssertExpectations(t)
+	batch := cpMock.Calls[0].Arguments.Get(1).(*ctlplaneapi.CreatePodsRequest)
+	require.Len(t, batch.Pods, 1)
+	assert.Equal(t, "456", batch.Pods[0].PodId)
+}
@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"resourcemanagement.controlplane/pkg/crd"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// RunNamespaceConfigs watches ControlPlaneConfig resources of all namespaces and applies them to the
+// daemon: created and updated resources configure allocations of their namespace, deleted ones remove
+// the configuration. Pods already running keep their allocation.
+func (a *Agent) RunNamespaceConfigs(dynamicClient dynamic.Interface) error {
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, a.resync)
+	informer := factory.ForResource(crd.GroupVersionResource).Informer()
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    a.setNamespaceConfig,
+		UpdateFunc: func(_, newobj interface{}) { a.setNamespaceConfig(newobj) },
+		DeleteFunc: a.deleteNamespaceConfig,
+	})
+	if err != nil {
+		return err
+	}
+
+	go factory.Start(a.ctx.Done())
+
+	if !cache.WaitForNamedCacheSync("ctlplane-agent:"+crd.Resource, a.ctx.Done(), informer.HasSynced) {
+		a.logger.Error(ErrCannotSync, "could not sync namespace configurations")
+		return ErrCannotSync
+	}
+	a.logger.Info("namespace configurations synced")
+	return nil
+}
+
+// namespaceConfig returns ControlPlaneConfig of the informer object, nil if it is not applied.
+func (a *Agent) namespaceConfig(obj interface{}) *crd.ControlPlaneConfig {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		a.logger.Info("unexpected namespace configuration object", "object", obj)
+		return nil
+	}
+	if u.GetName() != crd.ConfigName {
+		a.logger.Info(
+			"ignoring namespace configuration, only "+crd.ConfigName+" one is applied",
+			"namespace", u.GetNamespace(),
+			"name", u.GetName(),
+		)
+		return nil
+	}
+	config, err := crd.FromUnstructured(u)
+	if err != nil {
+		a.logger.Error(err, "ignoring namespace configuration")
+		return nil
+	}
+	return config
+}
+
+func (a *Agent) setNamespaceConfig(obj interface{}) {
+	config := a.namespaceConfig(obj)
+	if config == nil {
+		return
+	}
+	ctx, cancel := a.context()
+	defer cancel()
+	reply, err := a.ctlPlaneClient.SetNamespaceConfig(ctx, &ctlplaneapi.SetNamespaceConfigRequest{
+		Namespace:  config.Namespace,
+		Allocator:  config.Spec.Allocator,
+		Exclusive:  config.Spec.IsExclusive(),
+		BucketCpus: uint32(config.Spec.BucketCpus),
+	})
+	if err != nil {
+		a.logger.Error(err, "cannot configure namespace", "namespace", config.Namespace)
+		return
+	}
+	a.logger.Info(
+		"namespace configured",
+		"namespace", reply.Namespace,
+		"allocator", reply.Allocator,
+		"exclusive", reply.Exclusive,
+		"bucketCpus", reply.BucketCpus,
+	)
+}
+
+func (a *Agent) deleteNamespaceConfig(obj interface{}) {
+	config := a.namespaceConfig(obj)
+	if config == nil {
+		return
+	}
+	ctx, cancel := a.context()
+	defer cancel()
+	_, err := a.ctlPlaneClient.DeleteNamespaceConfig(ctx, &ctlplaneapi.DeleteNamespaceConfigRequest{
+		Namespace: config.Namespace,
+	})
+	if err == nil || status.Code(err) == codes.NotFound { // not configured if setting it failed
+		a.logger.Info("namespace configuration deleted", "namespace", config.Namespace)
+		return
+	}
+	a.logger.Error(err, "cannot delete namespace configuration", "namespace", config.Namespace)
+}
@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+	"resourcemanagement.controlplane/pkg/crd"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+func genNamespaceConfig(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": crd.Group + "/" + crd.Version,
+		"kind":       crd.Kind,
+		"metadata":   map[string]interface{}{"name": name, "namespace": "shop"},
+		"spec":       spec,
+	}}
+}
+
+func TestSetNamespaceConfig(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	cpMock.On("SetNamespaceConfig", mock.Anything, &ctlplaneapi.SetNamespaceConfigRequest{
+		Namespace:  "shop",
+		Allocator:  "numa-namespace",
+		Exclusive:  true,
+		BucketCpus: 2,
+	}).Return(&ctlplaneapi.NamespaceConfigReply{}, nil)
+	agent := NewAgent(testCtx, &cpMock, "")
+
+	agent.setNamespaceConfig(genNamespaceConfig(crd.ConfigName, map[string]interface{}{
+		"allocator":  "numa-namespace",
+		"bucketCpus": int64(2),
+	}))
+
+	cpMock.AssertExpectations(t)
+}
+
+func TestInvalidNamespaceConfigsAreIgnored(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	agent := NewAgent(testCtx, &cpMock, "")
+
+	agent.setNamespaceConfig(genNamespaceConfig("other", map[string]interface{}{}))
+	agent.setNamespaceConfig(genNamespaceConfig(crd.ConfigName, map[string]interface{}{"bucketCpus": int64(-1)}))
+	agent.deleteNamespaceConfig(genNamespaceConfig("other", map[string]interface{}{}))
+
+	cpMock.AssertExpectations(t)
+}
+
+func TestDeleteNamespaceConfig(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	cpMock.On("DeleteNamespaceConfig", mock.Anything, &ctlplaneapi.DeleteNamespaceConfigRequest{Namespace: "shop"}).
+		Return(&ctlplaneapi.NamespaceConfigReply{}, status.Error(codes.NotFound, "not configured")).Twice()
+	agent := NewAgent(testCtx, &cpMock, "")
+	config := genNamespaceConfig(crd.ConfigName, map[string]interface{}{})
+
+	agent.deleteNamespaceConfig(config)
+	agent.deleteNamespaceConfig(cache.DeletedFinalStateUnknown{Key: "shop/default", Obj: config})
+
+	cpMock.AssertExpectations(t)
+}
+
+func TestRunNamespaceConfigsAppliesExistingConfigs(t *testing.T) {
+	cpMock := ControlPlaneClientMock{}
+	applied := make(chan struct{})
+	cpMock.On("SetNamespaceConfig", mock.Anything, &ctlplaneapi.SetNamespaceConfigRequest{
+		Namespace: "shop",
+		Allocator: "scatter",
+	}).Return(&ctlplaneapi.NamespaceConfigReply{}, nil).Run(func(mock.Arguments) { close(applied) })
+	ctx, cancel := context.WithCancel(testCtx)
+	defer cancel()
+	agent := NewAgent(ctx, &cpMock, "")
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{crd.GroupVersionResource: crd.Kind + "List"},
+		genNamespaceConfig(crd.ConfigName, map[string]interface{}{"allocator": "scatter", "exclusive": false}),
+	)
+
+	require.Nil(t, agent.RunNamespaceConfigs(dynamicClient))
+
+	select {
+	case <-applied:
+	case <-time.After(time.Second):
+		assert.Fail(t, "existing configuration is not applied")
+	}
+}
@@ -4,20 +4,37 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
 	"resourcemanagement.controlplane/pkg/ctlplaneapi"
 )
 
+// MemoryPinningAnnotation enables ("true") or disables ("false") memory pinning for the pod,
+// overriding daemon configuration.
+const MemoryPinningAnnotation = "ctlplane.intel.com/memory-pinning"
+
+// ExclusiveLeaseAnnotation sets duration (eg. "30m") after which exclusive cpus of the pod return to the
+// shared pool. The lease is renewed by every pod update, eg. by changing the annotation.
+const ExclusiveLeaseAnnotation = "ctlplane.intel.com/exclusive-lease"
+
+// PodMetadataPrefix selects pod labels and annotations passed to the daemon, so that daemon-side
+// policies can use them without dedicated request fields.
+const PodMetadataPrefix = "ctlplane.intel.com/"
+
 var (
 	ErrNotRepresentable = errors.New("value not representable as int64")
 	ErrCountingOverflow = errors.New("values sum is not representable as int32")
+	ErrWrongAnnotation  = errors.New("wrong annotation value")
 )
 
 // GetCreatePodRequest creates CreatePodRequest from pod spec.
 func GetCreatePodRequest(pod *corev1.Pod) (*ctlplaneapi.CreatePodRequest, error) {
-	podID := pod.GetUID()
+	podID := podID(pod)
 
 	containerInfo, resourceInfo, err := createPodResources(pod)
 
@@ -25,12 +42,28 @@ func GetCreatePodRequest(pod *corev1.Pod) (*ctlplaneapi.CreatePodRequest, error)
 		return nil, err
 	}
 
+	memoryPinning, err := getMemoryPinning(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	lease, err := getExclusiveLease(pod)
+	if err != nil {
+		return nil, err
+	}
+
 	createPodRequest := &ctlplaneapi.CreatePodRequest{
-		PodId:        string(podID),
-		PodName:      pod.Name,
-		PodNamespace: pod.Namespace,
-		Resources:    resourceInfo,
-		Containers:   containerInfo,
+		PodId:                 string(podID),
+		PodName:               pod.Name,
+		PodNamespace:          pod.Namespace,
+		Resources:             resourceInfo,
+		Containers:            containerInfo,
+		MemoryPinning:         memoryPinning,
+		ExclusiveLeaseSeconds: lease,
+		Labels:                selectPodMetadata(pod.Labels),
+		Annotations:           selectPodMetadata(pod.Annotations),
+		Generation:            podGeneration(pod),
+		NodeName:              pod.Spec.NodeName,
 	}
 
 	return createPodRequest, nil
@@ -38,7 +71,7 @@ func GetCreatePodRequest(pod *corev1.Pod) (*ctlplaneapi.CreatePodRequest, error)
 
 // GetUpdatePodRequest creates UpdatePodRequest from pod spec.
 func GetUpdatePodRequest(pod *corev1.Pod) (*ctlplaneapi.UpdatePodRequest, error) {
-	podID := pod.GetUID()
+	podID := podID(pod)
 
 	containerInfo, resourceInfo, err := createPodResources(pod)
 
@@ -46,21 +79,66 @@ func GetUpdatePodRequest(pod *corev1.Pod) (*ctlplaneapi.UpdatePodRequest, error)
 		return nil, err
 	}
 
+	memoryPinning, err := getMemoryPinning(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	lease, err := getExclusiveLease(pod)
+	if err != nil {
+		return nil, err
+	}
+
 	updatePodRequest := &ctlplaneapi.UpdatePodRequest{
-		PodId:      string(podID),
-		Resources:  resourceInfo,
-		Containers: containerInfo,
+		PodId:                 string(podID),
+		Resources:             resourceInfo,
+		Containers:            containerInfo,
+		MemoryPinning:         memoryPinning,
+		ExclusiveLeaseSeconds: lease,
+		Labels:                selectPodMetadata(pod.Labels),
+		Annotations:           selectPodMetadata(pod.Annotations),
+		Generation:            podGeneration(pod),
+		NodeName:              pod.Spec.NodeName,
 	}
 
 	return updatePodRequest, nil
 }
 
+// isMirrorPod returns true if the pod is the api server mirror of a static pod created by kubelet from
+// a manifest file.
+func isMirrorPod(pod *corev1.Pod) bool {
+	_, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]
+	return ok
+}
+
+// podID returns id of the pod used by the daemon. Kubelet runs static pods with uid set to hash of
+// their manifest, which names their cgroups, while uid of the mirror pod differs and changes whenever
+// the mirror pod is recreated. The hash, stored in the mirror pod annotation, is used as id of mirror
+// pods.
+func podID(pod *corev1.Pod) types.UID {
+	if hash := pod.Annotations[corev1.MirrorPodAnnotationKey]; hash != "" {
+		return types.UID(hash)
+	}
+	return pod.GetUID()
+}
+
+// podGeneration returns resource version of the pod, which grows with every change of the pod, so that
+// the daemon can reject requests delayed behind newer ones. Returns 0 if the version is not a number.
+func podGeneration(pod *corev1.Pod) int64 {
+	generation, err := strconv.ParseInt(pod.ResourceVersion, 10, 64)
+	if err != nil || generation < 0 {
+		return 0
+	}
+	return generation
+}
+
 // GetDeletePodRequest creates DeletePodRequest from pod spec.
 func GetDeletePodRequest(pod *corev1.Pod) *ctlplaneapi.DeletePodRequest {
-	podID := pod.GetUID()
+	podID := podID(pod)
 
 	deletePodRequest := &ctlplaneapi.DeletePodRequest{
-		PodId: string(podID),
+		PodId:    string(podID),
+		NodeName: pod.Spec.NodeName,
 	}
 
 	return deletePodRequest
@@ -178,6 +256,51 @@ func getContainerResources(resourceList corev1.ResourceList) (int32, []byte, err
 	return int32(cpus), memory, nil
 }
 
+func getMemoryPinning(pod *corev1.Pod) (ctlplaneapi.MemoryPinning, error) {
+	value, ok := pod.Annotations[MemoryPinningAnnotation]
+	if !ok {
+		return ctlplaneapi.MemoryPinning_MEMORY_PINNING_DEFAULT, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return ctlplaneapi.MemoryPinning_MEMORY_PINNING_DEFAULT,
+			fmt.Errorf("%w: %s=%s", ErrWrongAnnotation, MemoryPinningAnnotation, value)
+	}
+	if enabled {
+		return ctlplaneapi.MemoryPinning_MEMORY_PINNING_ENABLED, nil
+	}
+	return ctlplaneapi.MemoryPinning_MEMORY_PINNING_DISABLED, nil
+}
+
+// getExclusiveLease returns exclusive cpus lease of the pod in seconds, rounded up, 0 if not set.
+func getExclusiveLease(pod *corev1.Pod) (uint32, error) {
+	value, ok := pod.Annotations[ExclusiveLeaseAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	lease, err := time.ParseDuration(value)
+	seconds := math.Ceil(lease.Seconds())
+	if err != nil || seconds <= 0 || seconds > math.MaxUint32 {
+		return 0, fmt.Errorf("%w: %s=%s", ErrWrongAnnotation, ExclusiveLeaseAnnotation, value)
+	}
+	return uint32(seconds), nil
+}
+
+// selectPodMetadata returns labels or annotations with PodMetadataPrefix, nil if there are none.
+func selectPodMetadata(metadata map[string]string) map[string]string {
+	var selected map[string]string
+	for key, value := range metadata {
+		if !strings.HasPrefix(key, PodMetadataPrefix) {
+			continue
+		}
+		if selected == nil {
+			selected = make(map[string]string)
+		}
+		selected[key] = value
+	}
+	return selected
+}
+
 func getContainerID(name string, pod *corev1.Pod) string {
 	for _, containerStatus := range pod.Status.ContainerStatuses {
 		if containerStatus.Name == name {
@@ -12,6 +12,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"resourcemanagement.controlplane/pkg/ctlplaneapi"
 )
 
@@ -139,6 +140,148 @@ func TestGetCreatePodRequest(t *testing.T) {
 	assertContainersEqualWithTestPod(t, pR.Containers)
 }
 
+func TestGetPodRequestMemoryPinning(t *testing.T) {
+	testCases := []struct {
+		annotation    string
+		memoryPinning ctlplaneapi.MemoryPinning
+		isError       bool
+	}{
+		{"true", ctlplaneapi.MemoryPinning_MEMORY_PINNING_ENABLED, false},
+		{"false", ctlplaneapi.MemoryPinning_MEMORY_PINNING_DISABLED, false},
+		{"maybe", ctlplaneapi.MemoryPinning_MEMORY_PINNING_DEFAULT, true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.annotation, func(t *testing.T) {
+			pod := genTestPods()
+			pod.Annotations = map[string]string{MemoryPinningAnnotation: testCase.annotation}
+			cR, err := GetCreatePodRequest(&pod)
+			uR, uErr := GetUpdatePodRequest(&pod)
+			if testCase.isError {
+				assert.ErrorIs(t, err, ErrWrongAnnotation)
+				assert.ErrorIs(t, uErr, ErrWrongAnnotation)
+				return
+			}
+			require.Nil(t, err)
+			require.Nil(t, uErr)
+			assert.Equal(t, testCase.memoryPinning, cR.MemoryPinning)
+			assert.Equal(t, testCase.memoryPinning, uR.MemoryPinning)
+		})
+	}
+
+	pod := genTestPods()
+	pR, err := GetCreatePodRequest(&pod)
+	require.Nil(t, err)
+	assert.Equal(t, ctlplaneapi.MemoryPinning_MEMORY_PINNING_DEFAULT, pR.MemoryPinning)
+	uR, err := GetUpdatePodRequest(&pod)
+	require.Nil(t, err)
+	assert.Equal(t, ctlplaneapi.MemoryPinning_MEMORY_PINNING_DEFAULT, uR.MemoryPinning)
+}
+
+func TestGetPodRequestExclusiveLease(t *testing.T) {
+	testCases := []struct {
+		annotation string
+		seconds    uint32
+		isError    bool
+	}{
+		{"30m", 1800, false},
+		{"1500ms", 2, false},
+		{"0s", 0, true},
+		{"-1m", 0, true},
+		{"forever", 0, true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.annotation, func(t *testing.T) {
+			pod := genTestPods()
+			pod.Annotations = map[string]string{ExclusiveLeaseAnnotation: testCase.annotation}
+			cR, err := GetCreatePodRequest(&pod)
+			uR, uErr := GetUpdatePodRequest(&pod)
+			if testCase.isError {
+				assert.ErrorIs(t, err, ErrWrongAnnotation)
+				assert.ErrorIs(t, uErr, ErrWrongAnnotation)
+				return
+			}
+			require.Nil(t, err)
+			require.Nil(t, uErr)
+			assert.Equal(t, testCase.seconds, cR.ExclusiveLeaseSeconds)
+			assert.Equal(t, testCase.seconds, uR.ExclusiveLeaseSeconds)
+		})
+	}
+
+	pod := genTestPods()
+	pR, err := GetCreatePodRequest(&pod)
+	require.Nil(t, err)
+	assert.Zero(t, pR.ExclusiveLeaseSeconds)
+}
+
+func TestGetPodRequestMetadata(t *testing.T) {
+	pod := genTestPods()
+	pod.Labels = map[string]string{"app": "test", "ctlplane.intel.com/tier": "gold"}
+	pod.Annotations = map[string]string{"ctlplane.intel.com/smt": "off", "kubernetes.io/psp": "default"}
+
+	cR, err := GetCreatePodRequest(&pod)
+	require.Nil(t, err)
+	uR, err := GetUpdatePodRequest(&pod)
+	require.Nil(t, err)
+
+	for _, labels := range []map[string]string{cR.Labels, uR.Labels} {
+		assert.Equal(t, map[string]string{"ctlplane.intel.com/tier": "gold"}, labels)
+	}
+	for _, annotations := range []map[string]string{cR.Annotations, uR.Annotations} {
+		assert.Equal(t, map[string]string{"ctlplane.intel.com/smt": "off"}, annotations)
+	}
+
+	pod = genTestPods()
+	cR, err = GetCreatePodRequest(&pod)
+	require.Nil(t, err)
+	assert.Nil(t, cR.Labels)
+	assert.Nil(t, cR.Annotations)
+}
+
+func TestGetPodRequestGeneration(t *testing.T) {
+	pod := genTestPods()
+	pod.ResourceVersion = "4711"
+
+	cR, err := GetCreatePodRequest(&pod)
+	require.Nil(t, err)
+	uR, err := GetUpdatePodRequest(&pod)
+	require.Nil(t, err)
+
+	assert.Equal(t, int64(4711), cR.Generation)
+	assert.Equal(t, int64(4711), uR.Generation)
+
+	pod.ResourceVersion = "opaque"
+	uR, err = GetUpdatePodRequest(&pod)
+	require.Nil(t, err)
+	assert.Zero(t, uR.Generation)
+}
+
+func TestGetPodRequestNodeName(t *testing.T) {
+	pod := genTestPods()
+	pod.Spec.NodeName = "node-1"
+
+	cR, err := GetCreatePodRequest(&pod)
+	require.Nil(t, err)
+	uR, err := GetUpdatePodRequest(&pod)
+	require.Nil(t, err)
+	dR := GetDeletePodRequest(&pod)
+
+	assert.Equal(t, "node-1", cR.NodeName)
+	assert.Equal(t, "node-1", uR.NodeName)
+	assert.Equal(t, "node-1", dR.NodeName)
+}
+
+func TestPodID(t *testing.T) {
+	pod := genTestPods()
+	assert.Equal(t, pod.UID, podID(&pod))
+	assert.False(t, isMirrorPod(&pod))
+
+	pod.Annotations = map[string]string{corev1.MirrorPodAnnotationKey: "8f2e9c1ab4d7"}
+	assert.Equal(t, types.UID("8f2e9c1ab4d7"), podID(&pod))
+	assert.True(t, isMirrorPod(&pod))
+}
+
 func TestGetUpdatePodRequest(t *testing.T) {
 	pod := genTestPods()
 	pR, err := GetUpdatePodRequest(&pod)
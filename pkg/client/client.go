@@ -0,0 +1,316 @@
+// Package client is a Go client of the ctlplane daemon for third-party integrations, eg. IRQ tuners or
+// autoscalers reading cpus allocated to containers. It wraps the generated ControlPlaneClient with
+// dialing, TLS setup, retries of failed calls and typed helpers of common queries.
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"sort"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+const (
+	defaultRetries      = 3
+	defaultRetryBackoff = 100 * time.Millisecond
+)
+
+// Client calls the ctlplane daemon. It is safe for concurrent use.
+type Client struct {
+	conn    *grpc.ClientConn // nil if the client does not own the connection
+	api     ctlplaneapi.ControlPlaneClient
+	options options
+}
+
+// Option configures optional behaviour of the client.
+type Option func(*options)
+
+type options struct {
+	channel      ctlplaneapi.ChannelOptions
+	tls          *tls.Config // nil for plaintext connection
+	retries      int         // retries of failed calls, 0 disables retries
+	retryBackoff time.Duration
+	dialOptions  []grpc.DialOption
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		retries:      defaultRetries,
+		retryBackoff: defaultRetryBackoff,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithChannelOptions sets keepalive and compression of the connection, which shall match options of the
+// daemon.
+func WithChannelOptions(channel ctlplaneapi.ChannelOptions) Option {
+	return func(o *options) {
+		o.channel = channel
+	}
+}
+
+// WithTLS makes the client connect with TLS, eg. to a daemon exposed through TLS terminating proxy. The
+// connection is plaintext by default, as served by the daemon. See LoadTLSConfig.
+func WithTLS(config *tls.Config) Option {
+	return func(o *options) {
+		o.tls = config
+	}
+}
+
+// WithRetries sets how many times failed calls are retried, 0 disables retries. Calls are retried while
+// the daemon is unavailable or if it asks to retry the request later; the backoff doubles with every
+// retry, and the delay asked by the daemon is waited if it is longer. Defaults to 3 retries with 100ms
+// initial backoff.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(o *options) {
+		o.retries = retries
+		o.retryBackoff = backoff
+	}
+}
+
+// WithDialOptions adds gRPC dial options, eg. interceptors or a context dialer.
+func WithDialOptions(dialOptions ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, dialOptions...)
+	}
+}
+
+// New returns client of the daemon at given gRPC target, eg. localhost:31000 or
+// unix:///run/ctlplane/daemon.sock. The connection is established in background, Close releases it.
+func New(target string, opts ...Option) (*Client, error) {
+	o := newOptions(opts)
+	creds := insecure.NewCredentials()
+	if o.tls != nil {
+		creds = credentials.NewTLS(o.tls)
+	}
+	dialOptions := append(o.channel.DialOptions(), grpc.WithTransportCredentials(creds))
+	conn, err := grpc.Dial(target, append(dialOptions, o.dialOptions...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, api: ctlplaneapi.NewControlPlaneClient(conn), options: o}, nil
+}
+
+// NewFromAPI returns client calling the daemon with given gRPC client, whose connection is owned by the
+// caller. Dial and TLS options are ignored.
+func NewFromAPI(api ctlplaneapi.ControlPlaneClient, opts ...Option) *Client {
+	return &Client{api: api, options: newOptions(opts)}
+}
+
+// Close closes the connection of the client, if the client owns it.
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// API returns the generated gRPC client, for calls without typed helpers. Its calls are not retried.
+func (c *Client) API() ctlplaneapi.ControlPlaneClient {
+	return c.api
+}
+
+// retryable checks if the failed call can be retried: the daemon is unavailable, or it asked to retry
+// the request later.
+func retryable(err error) bool {
+	return status.Code(err) == codes.Unavailable || ctlplaneapi.RetryDelay(err) > 0
+}
+
+// call invokes the method with retries configured in the client. Returns the last error if the call does
+// not succeed or the context is done while waiting for a retry.
+func call[Req any, Rep any](
+	ctx context.Context,
+	c *Client,
+	method func(context.Context, Req, ...grpc.CallOption) (Rep, error),
+	req Req,
+) (Rep, error) {
+	backoff := c.options.retryBackoff
+	for retry := 0; ; retry++ {
+		rep, err := method(ctx, req)
+		if err == nil || retry >= c.options.retries || !retryable(err) {
+			return rep, err
+		}
+		delay := backoff
+		if asked := ctlplaneapi.RetryDelay(err); asked > delay {
+			delay = asked
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return rep, err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// CreatePod allocates cpus to the pod.
+func (c *Client) CreatePod(
+	ctx context.Context,
+	req *ctlplaneapi.CreatePodRequest,
+) (*ctlplaneapi.PodAllocationReply, error) {
+	return call(ctx, c, c.api.CreatePod, req)
+}
+
+// UpdatePod changes allocation of the pod.
+func (c *Client) UpdatePod(
+	ctx context.Context,
+	req *ctlplaneapi.UpdatePodRequest,
+) (*ctlplaneapi.PodAllocationReply, error) {
+	return call(ctx, c, c.api.UpdatePod, req)
+}
+
+// DeletePod frees cpus of the pod.
+func (c *Client) DeletePod(ctx context.Context, podID string) (*ctlplaneapi.PodAllocationReply, error) {
+	return call(ctx, c, c.api.DeletePod, &ctlplaneapi.DeletePodRequest{PodId: podID})
+}
+
+// GetPod returns allocation of the pod.
+func (c *Client) GetPod(ctx context.Context, podID string) (*ctlplaneapi.PodAllocationReply, error) {
+	return call(ctx, c, c.api.GetPod, &ctlplaneapi.GetPodRequest{PodId: podID})
+}
+
+// ListPods returns allocations of all pods managed by the daemon.
+func (c *Client) ListPods(ctx context.Context) ([]*ctlplaneapi.PodAllocationReply, error) {
+	reply, err := call(ctx, c, c.api.ListPods, &ctlplaneapi.ListPodsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return reply.Pods, nil
+}
+
+// GetContainer returns allocation of the container of the pod.
+func (c *Client) GetContainer(
+	ctx context.Context,
+	podID string,
+	containerName string,
+) (*ctlplaneapi.ContainerAllocationInfo, error) {
+	reply, err := call(ctx, c, c.api.GetContainer, &ctlplaneapi.GetContainerRequest{
+		PodId:         podID,
+		ContainerName: containerName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply.Allocation, nil
+}
+
+// ContainerCpus returns sorted cpus of the container of the pod.
+func (c *Client) ContainerCpus(ctx context.Context, podID string, containerName string) ([]int, error) {
+	allocation, err := c.GetContainer(ctx, podID, containerName)
+	if err != nil {
+		return nil, err
+	}
+	return Cpus(allocation.GetCpuSet()), nil
+}
+
+// ExclusiveCpus returns sorted cpus exclusively allocated to containers of all pods, eg. cpus which IRQ
+// tuners keep free of interrupts.
+func (c *Client) ExclusiveCpus(ctx context.Context) ([]int, error) {
+	pods, err := c.ListPods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cpus := []*ctlplaneapi.CPUSet{}
+	for _, pod := range pods {
+		for _, container := range pod.ContainersAllocations {
+			if container.Exclusive {
+				cpus = append(cpus, container.CpuSet...)
+			}
+		}
+	}
+	return Cpus(cpus), nil
+}
+
+// GetConfig returns live configuration of the daemon and its allocator.
+func (c *Client) GetConfig(ctx context.Context) (*ctlplaneapi.ConfigReply, error) {
+	return call(ctx, c, c.api.GetConfig, &ctlplaneapi.GetConfigRequest{})
+}
+
+// GetAllocations returns snapshot of allocations of all pods, available cpus and topology summary.
+func (c *Client) GetAllocations(ctx context.Context) (*ctlplaneapi.AllocationsReply, error) {
+	return call(ctx, c, c.api.GetAllocations, &ctlplaneapi.GetAllocationsRequest{})
+}
+
+// ReserveCPUPool reserves named pool of cpus on one of given numa nodes, any node if none is given, which
+// no pod allocation uses until the pool is released.
+func (c *Client) ReserveCPUPool(ctx context.Context, name string, cpus int, nodes ...int) (*ctlplaneapi.CPUPoolReply, error) {
+	numaNodes := make([]uint32, 0, len(nodes))
+	for _, node := range nodes {
+		numaNodes = append(numaNodes, uint32(node))
+	}
+	return call(ctx, c, c.api.ReserveCPUPool, &ctlplaneapi.ReserveCPUPoolRequest{
+		Name:      name,
+		Cpus:      uint32(cpus),
+		NumaNodes: numaNodes,
+	})
+}
+
+// ReleaseCPUPool releases the reserved cpu pool.
+func (c *Client) ReleaseCPUPool(ctx context.Context, name string) (*ctlplaneapi.CPUPoolReply, error) {
+	return call(ctx, c, c.api.ReleaseCPUPool, &ctlplaneapi.ReleaseCPUPoolRequest{Name: name})
+}
+
+// SetNamespaceConfig configures allocator, exclusivity of cpus of guaranteed containers and minimal cpus
+// of the bucket of new pods of the namespace. Empty allocator selects the allocator of the daemon.
+func (c *Client) SetNamespaceConfig(
+	ctx context.Context,
+	namespace string,
+	allocator string,
+	exclusive bool,
+	bucketCpus int,
+) (*ctlplaneapi.NamespaceConfigReply, error) {
+	return call(ctx, c, c.api.SetNamespaceConfig, &ctlplaneapi.SetNamespaceConfigRequest{
+		Namespace:  namespace,
+		Allocator:  allocator,
+		Exclusive:  exclusive,
+		BucketCpus: uint32(bucketCpus),
+	})
+}
+
+// DeleteNamespaceConfig removes configuration of the namespace.
+func (c *Client) DeleteNamespaceConfig(ctx context.Context, namespace string) (*ctlplaneapi.NamespaceConfigReply, error) {
+	return call(ctx, c, c.api.DeleteNamespaceConfig, &ctlplaneapi.DeleteNamespaceConfigRequest{Namespace: namespace})
+}
+
+// ListUsage returns cpu seconds of exclusive cpus pinned to and requested by guaranteed containers of the
+// namespace, or of all namespaces if namespace is empty, eg. for chargeback of exclusive cores.
+func (c *Client) ListUsage(ctx context.Context, namespace string) ([]*ctlplaneapi.NamespaceUsageReply, error) {
+	reply, err := call(ctx, c, c.api.ListUsage, &ctlplaneapi.ListUsageRequest{Namespace: namespace})
+	if err != nil {
+		return nil, err
+	}
+	return reply.Namespaces, nil
+}
+
+// GetDaemonInfo returns build and cgroup version of the daemon.
+func (c *Client) GetDaemonInfo(ctx context.Context) (*ctlplaneapi.DaemonInfoReply, error) {
+	return call(ctx, c, c.api.GetDaemonInfo, &ctlplaneapi.GetDaemonInfoRequest{})
+}
+
+// Cpus returns sorted ids of cpus of the cpu ranges, as reported by the daemon.
+func Cpus(sets []*ctlplaneapi.CPUSet) []int {
+	unique := map[int]struct{}{}
+	for _, set := range sets {
+		for cpu := set.StartCPU; cpu <= set.EndCPU; cpu++ {
+			unique[int(cpu)] = struct{}{}
+		}
+	}
+	cpus := make([]int, 0, len(unique))
+	for cpu := range unique {
+		cpus = append(cpus, cpu)
+	}
+	sort.Ints(cpus)
+	return cpus
+}
@@ -0,0 +1,267 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// fakeDaemon serves allocations of a single pod and fails given number of calls before serving them.
+type fakeDaemon struct {
+	ctlplaneapi.UnimplementedControlPlaneServer
+
+	mu       sync.Mutex
+	failures []error // errors returned by the next calls
+	calls    int
+}
+
+func (d *fakeDaemon) fail() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls++
+	if len(d.failures) == 0 {
+		return nil
+	}
+	err := d.failures[0]
+	d.failures = d.failures[1:]
+	return err
+}
+
+func testPod() *ctlplaneapi.PodAllocationReply {
+	return &ctlplaneapi.PodAllocationReply{
+		PodId: "pod",
+		ContainersAllocations: []*ctlplaneapi.ContainerAllocationInfo{
+			{
+				ContainerId: "exclusive",
+				CpuSet:      []*ctlplaneapi.CPUSet{{StartCPU: 4, EndCPU: 5}, {StartCPU: 1, EndCPU: 1}},
+				Exclusive:   true,
+			},
+			{
+				ContainerId: "shared",
+				CpuSet:      []*ctlplaneapi.CPUSet{{StartCPU: 0, EndCPU: 7}},
+			},
+		},
+	}
+}
+
+func (d *fakeDaemon) ListPods(context.Context, *ctlplaneapi.ListPodsRequest) (*ctlplaneapi.ListPodsReply, error) {
+	if err := d.fail(); err != nil {
+		return nil, err
+	}
+	return &ctlplaneapi.ListPodsReply{Pods: []*ctlplaneapi.PodAllocationReply{testPod()}}, nil
+}
+
+func (d *fakeDaemon) GetContainer(
+	_ context.Context,
+	req *ctlplaneapi.GetContainerRequest,
+) (*ctlplaneapi.ContainerAllocationReply, error) {
+	if err := d.fail(); err != nil {
+		return nil, err
+	}
+	if req.PodId != "pod" {
+		return nil, status.Error(codes.NotFound, "pod not found")
+	}
+	return &ctlplaneapi.ContainerAllocationReply{
+		PodId:         req.PodId,
+		ContainerName: req.ContainerName,
+		Allocation:    testPod().ContainersAllocations[0],
+	}, nil
+}
+
+func (d *fakeDaemon) ReserveCPUPool(
+	_ context.Context,
+	req *ctlplaneapi.ReserveCPUPoolRequest,
+) (*ctlplaneapi.CPUPoolReply, error) {
+	if err := d.fail(); err != nil {
+		return nil, err
+	}
+	return &ctlplaneapi.CPUPoolReply{
+		Name:     req.Name,
+		NumaNode: int32(req.NumaNodes[len(req.NumaNodes)-1]),
+		CpuSet:   []*ctlplaneapi.CPUSet{{StartCPU: 4, EndCPU: 4 + int32(req.Cpus) - 1}},
+	}, nil
+}
+
+func (d *fakeDaemon) SetNamespaceConfig(
+	_ context.Context,
+	req *ctlplaneapi.SetNamespaceConfigRequest,
+) (*ctlplaneapi.NamespaceConfigReply, error) {
+	if err := d.fail(); err != nil {
+		return nil, err
+	}
+	return &ctlplaneapi.NamespaceConfigReply{
+		Namespace:  req.Namespace,
+		Allocator:  req.Allocator,
+		Exclusive:  req.Exclusive,
+		BucketCpus: req.BucketCpus,
+	}, nil
+}
+
+func (d *fakeDaemon) ListUsage(
+	_ context.Context,
+	req *ctlplaneapi.ListUsageRequest,
+) (*ctlplaneapi.ListUsageReply, error) {
+	if err := d.fail(); err != nil {
+		return nil, err
+	}
+	return &ctlplaneapi.ListUsageReply{Namespaces: []*ctlplaneapi.NamespaceUsageReply{
+		{Namespace: req.Namespace, PinnedCpuSeconds: 120, RequestedCpuSeconds: 60, PinnedCpus: 2, RequestedCpus: 1},
+	}}, nil
+}
+
+// newTestClient returns client of the fake daemon served over in-memory connection.
+func newTestClient(t *testing.T, d *fakeDaemon, opts ...Option) *Client {
+	listener := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	ctlplaneapi.RegisterControlPlaneServer(s, d)
+	go func() {
+		_ = s.Serve(listener)
+	}()
+	c, err := New("passthrough:///daemon", append(opts, WithDialOptions(
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+	))...)
+	require.Nil(t, err)
+	t.Cleanup(func() {
+		c.Close()
+		s.Stop()
+	})
+	return c
+}
+
+func retryError(delay time.Duration) error {
+	s, err := status.New(codes.Internal, "cannot allocate").WithDetails(
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)},
+	)
+	if err != nil {
+		panic(err)
+	}
+	return s.Err()
+}
+
+func TestClientHelpers(t *testing.T) {
+	c := newTestClient(t, &fakeDaemon{})
+	ctx := context.Background()
+
+	pods, err := c.ListPods(ctx)
+	require.Nil(t, err)
+	assert.Len(t, pods, 1)
+	cpus, err := c.ExclusiveCpus(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, []int{1, 4, 5}, cpus)
+	cpus, err = c.ContainerCpus(ctx, "pod", "exclusive")
+	require.Nil(t, err)
+	assert.Equal(t, []int{1, 4, 5}, cpus)
+	_, err = c.ContainerCpus(ctx, "other", "exclusive")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestClientReserveCPUPool(t *testing.T) {
+	c := newTestClient(t, &fakeDaemon{})
+
+	pool, err := c.ReserveCPUPool(context.Background(), "dpdk", 4, 0, 1)
+
+	require.Nil(t, err)
+	assert.Equal(t, "dpdk", pool.Name)
+	assert.Equal(t, int32(1), pool.NumaNode)
+	assert.Equal(t, []int{4, 5, 6, 7}, Cpus(pool.CpuSet))
+}
+
+func TestClientSetNamespaceConfig(t *testing.T) {
+	c := newTestClient(t, &fakeDaemon{})
+
+	config, err := c.SetNamespaceConfig(context.Background(), "shop", "numa-namespace", true, 2)
+
+	require.Nil(t, err)
+	assert.Equal(t, "shop", config.Namespace)
+	assert.Equal(t, "numa-namespace", config.Allocator)
+	assert.True(t, config.Exclusive)
+	assert.Equal(t, uint32(2), config.BucketCpus)
+}
+
+func TestClientListUsage(t *testing.T) {
+	c := newTestClient(t, &fakeDaemon{})
+
+	usage, err := c.ListUsage(context.Background(), "shop")
+
+	require.Nil(t, err)
+	require.Len(t, usage, 1)
+	assert.Equal(t, "shop", usage[0].Namespace)
+	assert.Equal(t, 120.0, usage[0].PinnedCpuSeconds)
+	assert.Equal(t, 60.0, usage[0].RequestedCpuSeconds)
+}
+
+func TestClientRetriesFailedCalls(t *testing.T) {
+	d := &fakeDaemon{failures: []error{
+		status.Error(codes.Unavailable, "daemon restarting"),
+		retryError(time.Millisecond),
+	}}
+	c := newTestClient(t, d, WithRetries(2, time.Millisecond))
+
+	_, err := c.ListPods(context.Background())
+
+	require.Nil(t, err)
+	assert.Equal(t, 3, d.calls)
+}
+
+func TestClientDoesNotRetryPermanentErrors(t *testing.T) {
+	d := &fakeDaemon{failures: []error{status.Error(codes.InvalidArgument, "invalid request")}}
+	c := newTestClient(t, d, WithRetries(2, time.Millisecond))
+
+	_, err := c.ListPods(context.Background())
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, 1, d.calls)
+}
+
+func TestClientGivesUpAfterRetries(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "daemon restarting")
+	d := &fakeDaemon{failures: []error{unavailable, unavailable, unavailable}}
+	c := newTestClient(t, d, WithRetries(1, time.Millisecond))
+
+	_, err := c.ListPods(context.Background())
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 2, d.calls)
+}
+
+func TestClientStopsRetryingWhenContextIsDone(t *testing.T) {
+	d := &fakeDaemon{failures: []error{retryError(time.Hour)}}
+	c := newTestClient(t, d)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.ListPods(ctx)
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, 1, d.calls)
+}
+
+func TestNewFromAPI(t *testing.T) {
+	served := newTestClient(t, &fakeDaemon{})
+	c := NewFromAPI(served.API())
+
+	cpus, err := c.ExclusiveCpus(context.Background())
+
+	require.Nil(t, err)
+	assert.Equal(t, []int{1, 4, 5}, cpus)
+	assert.Nil(t, c.Close(), "connection is owned by the caller")
+}
+
+func TestCpus(t *testing.T) {
+	assert.Equal(t, []int{}, Cpus(nil))
+	assert.Equal(t, []int{0, 1, 2, 7}, Cpus([]*ctlplaneapi.CPUSet{{StartCPU: 7, EndCPU: 7}, {StartCPU: 0, EndCPU: 2}}))
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/test/bufconn"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// writeCertificate writes self-signed certificate of the daemon host and its key, returns their paths.
+func writeCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "daemon"},
+		DNSNames:              []string{"daemon"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.Nil(t, err)
+
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	require.Nil(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.Nil(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return certFile, keyFile
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	certFile, keyFile := writeCertificate(t, t.TempDir())
+
+	config, err := LoadTLSConfig(certFile, certFile, keyFile)
+
+	require.Nil(t, err)
+	assert.NotNil(t, config.RootCAs)
+	assert.Len(t, config.Certificates, 1)
+	config, err = LoadTLSConfig("", "", "")
+	require.Nil(t, err)
+	assert.Nil(t, config.RootCAs, "system roots shall be used")
+}
+
+func TestLoadTLSConfigFails(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir)
+	notPem := filepath.Join(dir, "ca.txt")
+	require.Nil(t, os.WriteFile(notPem, []byte("no certificates"), 0o600))
+
+	for name, files := range map[string][]string{
+		"missing ca":    {filepath.Join(dir, "missing.crt"), "", ""},
+		"ca not pem":    {notPem, "", ""},
+		"key not given": {certFile, certFile, ""},
+		"key mismatch":  {"", keyFile, certFile},
+	} {
+		_, err := LoadTLSConfig(files[0], files[1], files[2])
+		assert.NotNil(t, err, name)
+	}
+}
+
+func TestClientWithTLS(t *testing.T) {
+	certFile, keyFile := writeCertificate(t, t.TempDir())
+	serverCreds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+	require.Nil(t, err)
+	listener := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(grpc.Creds(serverCreds))
+	ctlplaneapi.RegisterControlPlaneServer(s, &fakeDaemon{})
+	go func() {
+		_ = s.Serve(listener)
+	}()
+	defer s.Stop()
+	config, err := LoadTLSConfig(certFile, "", "")
+	require.Nil(t, err)
+
+	c, err := New("passthrough:///daemon", WithTLS(config), WithRetries(0, 0), WithDialOptions(
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+	))
+	require.Nil(t, err)
+	defer c.Close()
+	cpus, err := c.ExclusiveCpus(context.Background())
+
+	require.Nil(t, err)
+	assert.Equal(t, []int{1, 4, 5}, cpus)
+}
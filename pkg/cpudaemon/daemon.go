@@ -2,13 +2,24 @@
 package cpudaemon
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-logr/logr"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/events"
+	"resourcemanagement.controlplane/pkg/metrics"
+	"resourcemanagement.controlplane/pkg/version"
 )
 
 // CGroupDriver stores cgroup driver used by kubelet.
@@ -34,6 +45,15 @@ const (
 	RuntimeError
 	ConfigurationError
 	NotImplemented
+	ExclusiveCpusCapExceeded
+	NamespaceNotFound
+	StaleRequest
+	CgroupNotReady
+	NodeMismatch
+	RequestAborted
+	SystemPressure
+	CPUPoolNotFound
+	CPUPoolConflict
 )
 
 // QoS pod and containers quality of service type.
@@ -59,6 +79,17 @@ func QoSFromLimit[T int | int32 | int64](limitCpu, requestCpu T) QoS {
 	return BestEffort
 }
 
+func (q QoS) toQoSClass() ctlplaneapi.QoSClass {
+	switch q {
+	case Guaranteed:
+		return ctlplaneapi.QoSClass_GUARANTEED
+	case Burstable:
+		return ctlplaneapi.QoSClass_BURSTABLE
+	default:
+		return ctlplaneapi.QoSClass_BEST_EFFORT
+	}
+}
+
 // DaemonError Custom Error type.
 type DaemonError struct {
 	ErrorType    DError
@@ -70,6 +101,70 @@ func (d DaemonError) Error() string {
 	return "Daemon Error: " + d.ErrorMessage
 }
 
+// GRPCStatus returns gRPC status of the error: NotFound for missing pods, containers and cpu pools,
+// Aborted for requests older than the applied pod version, FailedPrecondition for requests meant for
+// another node, ResourceExhausted for exclusive allocations refused under system pressure, AlreadyExists
+// for cpu pools reserved with different parameters, Unavailable otherwise.
+func (d DaemonError) GRPCStatus() *status.Status {
+	switch d.ErrorType {
+	case PodNotFound, ContainerNotFound, NamespaceNotFound, CPUPoolNotFound:
+		return status.New(codes.NotFound, d.Error())
+	case StaleRequest:
+		return status.New(codes.Aborted, d.Error())
+	case NodeMismatch:
+		return status.New(codes.FailedPrecondition, d.Error())
+	case SystemPressure:
+		return status.New(codes.ResourceExhausted, d.Error())
+	case CPUPoolConflict:
+		return status.New(codes.AlreadyExists, d.Error())
+	default:
+		return status.New(codes.Unavailable, d.Error())
+	}
+}
+
+// RuntimeMismatchError is returned when the container id does not match the container runtime configured
+// in the daemon. Its gRPC status carries both container id prefixes in ErrorInfo details.
+type RuntimeMismatchError struct {
+	DaemonError
+	ContainerIDPrefix string
+	ExpectedPrefix    string
+}
+
+func newRuntimeMismatchError(cid string, expectedPrefix string) RuntimeMismatchError {
+	prefix := ""
+	if i := strings.Index(cid, "://"); i >= 0 {
+		prefix = cid[:i+len("://")]
+	}
+	return RuntimeMismatchError{
+		DaemonError: DaemonError{
+			ErrorType: ConfigurationError,
+			ErrorMessage: fmt.Sprintf(
+				"Control Plane configured runtime does not match pod runtime: container id prefix %q, expected %q",
+				prefix,
+				expectedPrefix,
+			),
+		},
+		ContainerIDPrefix: prefix,
+		ExpectedPrefix:    expectedPrefix,
+	}
+}
+
+// GRPCStatus returns gRPC status of the error with ErrorInfo details.
+func (e RuntimeMismatchError) GRPCStatus() *status.Status {
+	s, err := e.DaemonError.GRPCStatus().WithDetails(&errdetails.ErrorInfo{
+		Reason: ctlplaneapi.ReasonRuntimeMismatch,
+		Domain: ctlplaneapi.ErrorDomain,
+		Metadata: map[string]string{
+			"containerIdPrefix": e.ContainerIDPrefix,
+			"expectedPrefix":    e.ExpectedPrefix,
+		},
+	})
+	if err != nil {
+		return e.DaemonError.GRPCStatus()
+	}
+	return s
+}
+
 type failedContainer struct {
 	cid string
 	err error
@@ -94,10 +189,20 @@ func (e failedContainersErrors) Error() string {
 
 // PodMetadata represent a pod resource in the daemon.
 type PodMetadata struct {
-	PID        string
-	Name       string
-	Namespace  string
-	Containers []Container
+	PID             string
+	Name            string
+	Namespace       string
+	Containers      []Container
+	MemoryPinning   ctlplaneapi.MemoryPinning
+	Placement       ctlplaneapi.Placement
+	LeaseExpiry     time.Time         // expiry of exclusive cpus lease, zero if exclusivity is permanent
+	LeaseExpired    bool              // true if guaranteed containers were moved to the shared pool
+	Unmanaged       bool              // true if pod request failed validation and containers run in the shared pool
+	UnmanagedReason string            // validation error of unmanaged pod
+	Labels          map[string]string // pod labels passed by the agent
+	Annotations     map[string]string // pod annotations passed by the agent
+	Generation      int64             // version of the pod applied by the last request, 0 if unknown
+	Tier            string            // policy tier of the pod namespace at creation, empty for default policy
 }
 
 // ContainerRuntime represents different CRI used by k8s.
@@ -118,21 +223,42 @@ func (cr ContainerRuntime) String() string {
 	}[cr]
 }
 
-// Container Represents a container in the Daemon.
+// Container Represents a container in the Daemon. Namespace and QoS class of the pod are kept with the
+// container, so that allocators do not depend on pod metadata, which may already be removed.
 type Container struct {
-	CID  string
-	PID  string
-	Name string
-	Cpus int
-	QS   QoS
+	CID       string
+	PID       string
+	Name      string
+	Cpus      int
+	QS        QoS
+	Namespace string // namespace of the pod
+	PodQS     QoS    // QoS class of the pod
+
+	SharedPoolFallback bool // guaranteed container running in the shared pool, as its cpus did not fit
 }
 
 // Daemon holds a state of the daemon.
 type Daemon struct {
 	state   DaemonState
-	policy  Policy
+	policy  Policy // default policy, used for pods of namespaces not assigned to any policy tier
 	stateMu sync.Mutex
 	logger  logr.Logger
+	options daemonOptions
+	claim   *instanceClaim
+
+	exportedIsolatedCpus CPUSet                            // nil until isolated cpus are exported for the first time
+	appliedSharedPool    CPUSet                            // nil until shared pool is applied for the first time
+	appliedPodCpusets    map[string]string                 // maps pod id to cpuset applied to its cgroup
+	publishedEvents      map[string]events.AllocationEvent // last published allocation of each container
+	readState            atomic.Pointer[DaemonState]       // copy of the state served by read requests
+	tierPolicies         map[string]Policy                 // maps name of policy tier to its policy
+	journal              *os.File                          // journal of state changes, nil until the first change
+	saveTimer            *time.Timer                       // pending write of the state file, nil if none
+	cgroupRetryTimer     *time.Timer                       // pending re-apply of deferred cgroup updates, nil if none
+	dryRun               bool                              // works on a copy of the state, nothing is applied or saved
+	cpuStats             map[string]cpuStatSample          // last cpu.stat of managed containers, used by cpu borrowing
+	trace                *os.File                          // allocation trace, nil if disabled
+	numaPath             string                            // node info path the topology is loaded from
 }
 
 type containerUpdated struct {
@@ -140,97 +266,254 @@ type containerUpdated struct {
 	wanted  Container
 }
 
-// GetState Daemon State getter.
+// GetState returns text form of the state published by the last completed update.
 func (d *Daemon) GetState() string {
-	return fmt.Sprint(d.state)
+	return fmt.Sprint(*d.readableState())
 }
 
 // New constrcuts a new daemon.
-func New(cPath, numaPath, statePath string, p Policy, logger logr.Logger) (*Daemon, error) {
-	s, err := newState(cPath, numaPath, statePath)
+func New(cPath, numaPath, statePath string, p Policy, logger logr.Logger, opts ...Option) (*Daemon, error) {
+	options := newDaemonOptions(opts)
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	var claim *instanceClaim
+	if options.managedCPUs != nil {
+		var err error
+		if claim, err = claimManagedCpus(statePath, options.managedCPUs); err != nil {
+			return nil, err
+		}
+	}
+	s, err := newState(cPath, numaPath, statePath, opts...)
 	if err != nil {
+		claim.release()
 		return nil, err
 	}
+	if options.cgroupWriteCheck {
+		if err := checkCgroupWritable(cPath, s.CgroupVersion); err != nil {
+			claim.release()
+			return nil, err
+		}
+	}
+	if options.kubepodsReservedCPUs != nil {
+		if err := applyKubepodsCpuset(cPath, s.CgroupVersion, s.allCpus()); err != nil {
+			claim.release()
+			return nil, err
+		}
+	}
+	tiers := tierPolicies(options.policyTiers)
+	for name, policy := range namespaceAllocatorPolicies(options.namespaceAllocators) {
+		tiers[name] = policy
+	}
 	d := Daemon{
-		state:  *s,
-		policy: p,
-		logger: logger.WithName("daemon"),
+		state:   *s,
+		policy:  p,
+		logger:  logger.WithName("daemon"),
+		options: options,
+		claim:   claim,
+
+		tierPolicies: tiers,
+		numaPath:     numaPath,
+	}
+	d.logger.Info("cgroup version detected", "version", s.CgroupVersion)
+	if len(s.ReservedCPUs) > 0 {
+		d.logger.Info("reserved cpus are not managed", "cpus", CPUSetFromBucketList(s.ReservedCPUs))
+	}
+	if len(s.ManagedCPUs) > 0 {
+		d.logger.Info("managing only subset of cpus", "cpus", CPUSetFromBucketList(s.ManagedCPUs))
+	}
+	if err := d.checkKubeletCPUManager(); err != nil {
+		claim.release()
+		return nil, err
+	}
+	if err := d.checkNumaNodes(); err != nil {
+		claim.release()
+		return nil, err
+	}
+	d.checkPolicyTiers()
+	d.checkSharedPoolSupport()
+	d.checkPodCgroupPinningSupport()
+	d.checkCPUBorrowingSupport()
+	d.updateFragmentationMetrics()
+	d.updateAllocationMetrics()
+	d.exportIsolatedCpus()
+	d.applySharedPool()
+	d.applyPodCgroups()
+	d.publishAllocationEvents()
+	d.publishReadState()
+	if options.allocationTracePath != "" {
+		if err := d.openAllocationTrace(); err != nil {
+			claim.release()
+			return nil, err
+		}
 	}
 
 	return &d, nil
 }
 
-func (d *Daemon) rollbackContainers(podID string, containers []*ctlplaneapi.ContainerInfo) {
-	for _, container := range containers {
-		c := containerFromRequest(d.logger, container, podID)
-		d.logger.Info("rolling back container", "cid", container.ContainerId)
-		err := d.policy.ClearContainer(c, &d.state)
-		d.logger.Error(err, "failed to roll back container", "cid", container.ContainerId)
+// Close writes pending changes of the state to the state file and releases the claim of managed cpus,
+// so that they can be managed by another daemon instance.
+func (d *Daemon) Close() {
+	d.stateMu.Lock()
+	if d.cgroupRetryTimer != nil {
+		d.cgroupRetryTimer.Stop()
+		d.cgroupRetryTimer = nil
+	}
+	d.writeStateFile()
+	if d.journal != nil {
+		d.journal.Close()
+		d.journal = nil
+	}
+	d.closeAllocationTrace()
+	d.stateMu.Unlock()
+	d.claim.release()
+}
+
+// rollbackContainers frees resources of successfully assigned containers and reverts their cpusets to
+// the default ones. Rollback is not aborted by cancellation of the request, so that no cpus leak.
+func (d *Daemon) rollbackContainers(assigned []Container) {
+	ctx := context.Background()
+	for _, c := range assigned {
+		d.logger.Info("rolling back container", "cid", c.CID)
+		if err := d.policyFor(c).DeleteContainer(ctx, c, &d.state); err != nil {
+			d.logger.Error(err, "failed to free container resources", "cid", c.CID)
+		}
+		if err := d.policyFor(c).ClearContainer(ctx, c, &d.state); err != nil {
+			d.logger.Error(err, "failed to roll back container", "cid", c.CID)
+		}
+		d.state.clearMemoryNodes(c.CID)
+		d.state.clearCgroupPath(c.CID)
+		d.state.clearAllocationExplanation(c.CID)
 	}
 }
 
 // CreatePod Creates a pod with given resource allocation for the parent pod and all.
-// Error handling: either all containers were added successfully or pod creation fails.
-func (d *Daemon) CreatePod(req *ctlplaneapi.CreatePodRequest) (*ctlplaneapi.AllocatedPodResources, error) {
+// Error handling: either all containers were added successfully or pod creation fails, unless non-critical
+// containers fall back to the shared pool, see assignPodContainer. Dry run requests compute the allocation
+// on a copy of the state, see dryRunDaemon.
+func (d *Daemon) CreatePod(ctx context.Context, req *ctlplaneapi.CreatePodRequest) (*ctlplaneapi.AllocatedPodResources, error) {
+	if req.DryRun && !d.dryRun {
+		return d.dryRunCreatePod(ctx, req)
+	}
+	if err := d.checkNodeName(req.PodId, req.NodeName); err != nil {
+		d.logger.Error(err, "cannot create pod")
+		return nil, err
+	}
 	if err := ctlplaneapi.ValidateCreatePodRequest(req); err != nil {
 		d.logger.Error(err, "validation error")
+		if d.bestEffortValidation(req.PodId) {
+			return d.createUnmanagedPod(req, err)
+		}
 		return nil, DaemonError{ErrorType: PodSpecError, ErrorMessage: err.Error()}
 	}
+	profile, err := d.podProfile(req.Annotations)
+	if err != nil {
+		d.logger.Error(err, "cannot create pod")
+		return nil, err
+	}
 
 	d.stateMu.Lock()
 	defer d.stateMu.Unlock()
+	defer d.traceRequest(traceCreatePod, req, d.traceHash())
 
-	d.logger.Info("create pod allocation", "request", req)
+	if ctx.Err() != nil {
+		err := newRequestAbortedError(req.PodId, ctx.Err(), 0, len(req.Containers), "")
+		d.logger.Error(err, "cannot create pod")
+		return nil, err
+	}
+	if current, ok := d.state.Pods[req.PodId]; ok {
+		if err := checkGeneration(current, req.Generation); err != nil {
+			d.logger.Error(err, "cannot create pod")
+			return nil, err
+		}
+	}
 
+	now := time.Now()
 	podMeta := PodMetadata{
-		PID:       req.PodId,
-		Name:      req.PodName,
-		Namespace: req.PodNamespace,
+		PID:           req.PodId,
+		Name:          req.PodName,
+		Namespace:     req.PodNamespace,
+		MemoryPinning: d.getMemoryPinning(req.MemoryPinning, req.PodNamespace, profile),
+		LeaseExpiry:   leaseExpiry(now, req.ExclusiveLeaseSeconds),
+		Placement:     profile.placement(req.Resources.GetCpuAffinity()),
+		Labels:        req.Labels,
+		Annotations:   req.Annotations,
+		Generation:    req.Generation,
+		Tier:          d.namespaceTier(req.PodNamespace),
 	}
 
 	d.state.Pods[req.PodId] = podMeta
 	containersCpus := []ctlplaneapi.AllocatedContainerResource{}
+	snapshot := d.state.snapshot()
 
-	for i, it := range req.Containers {
-		c := containerFromRequest(d.logger, it, req.PodId)
-		err := d.policy.AssignContainer(c, &d.state)
+	containers := containersFromRequest(d.logger, req.Containers, req.PodId, req.PodNamespace)
+	critical := criticalContainers(req.Annotations)
+
+	for _, c := range sortedByPriority(containers, critical) {
+		c, err := d.assignPodContainer(ctx, c, critical)
 
 		if err != nil {
+			err = abortedOr(ctx, err, req.PodId, len(podMeta.Containers), len(containers), c.CID)
 			d.logger.Error(err, "cannot assign container", "container", c)
-			d.rollbackContainers(req.PodId, req.Containers[:i])
+			d.rollbackContainers(podMeta.Containers)
+			// do not rely on allocators to release everything they have taken
+			d.state.restore(snapshot)
+			d.state.clearMemoryNodes(c.CID)
+			d.state.clearCgroupPath(c.CID)
+			d.state.clearAllocationExplanation(c.CID)
 			delete(d.state.Pods, req.PodId)
-			return nil, err
+			status, reason := failedResult(err)
+			results := containerResults{c.CID: {ContainerID: c.CID, Status: status, Reason: reason}}
+			return &ctlplaneapi.AllocatedPodResources{
+				PodID:            req.PodId,
+				ContainerResults: results.ordered(requestContainerIDs(req.Containers), resultRolledBack),
+			}, err
 		}
 
-		containersCpus = append(containersCpus, ctlplaneapi.AllocatedContainerResource{
-			ContainerID: it.ContainerId,
-			CPUSet:      d.state.Allocated[it.ContainerId],
-		})
 		podMeta.Containers = append(podMeta.Containers, c)
 		d.state.Pods[req.PodId] = podMeta
+		containers = withContainer(containers, c)
+	}
+
+	// keep the request order in pod metadata and in the reply
+	podMeta.Containers = containers
+	d.state.Pods[req.PodId] = podMeta
+	for _, c := range containers {
+		d.state.setAllocatedAt(c.CID, now)
+		containersCpus = append(containersCpus, d.state.allocatedContainerResource(c))
 	}
 
 	if err := d.saveState(); err != nil {
 		return nil, *err
 	}
 
-	d.logger.Info("pod allocation created")
+	results := containerResults{}
+	results.add(containerIDs(containers), nil, resultAllocated)
 	return &ctlplaneapi.AllocatedPodResources{
 		ContainerResources: containersCpus,
+		ContainerResults:   results.ordered(containerIDs(containers), resultUnchanged),
 	}, nil
 }
 
 // DeletePod Deletes pod and children containers allocations.
-// Error handling: all containers are deleted from the state, event if some error happens before.
-func (d *Daemon) DeletePod(req *ctlplaneapi.DeletePodRequest) error {
+// Error handling: all containers are deleted from the state, event if some error happens before. Returns
+// allocations freed by the deletion, see releasedResources.
+func (d *Daemon) DeletePod(
+	ctx context.Context,
+	req *ctlplaneapi.DeletePodRequest,
+) (*ctlplaneapi.AllocatedPodResources, error) {
+	if err := d.checkNodeName(req.PodId, req.NodeName); err != nil {
+		d.logger.Error(err, "cannot delete pod")
+		return nil, err
+	}
 	if err := ctlplaneapi.ValidateDeletePodRequest(req); err != nil {
 		d.logger.Error(err, "validation error")
-		return DaemonError{ErrorType: PodSpecError, ErrorMessage: err.Error()}
+		return nil, DaemonError{ErrorType: PodSpecError, ErrorMessage: err.Error()}
 	}
 	d.stateMu.Lock()
 	defer d.stateMu.Unlock()
+	defer d.traceRequest(traceDeletePod, req, d.traceHash())
 
-	d.logger.Info("delete pod allocation", "request", req)
 	pod, ok := d.state.Pods[req.PodId]
 	if !ok {
 		err := DaemonError{
@@ -238,80 +521,173 @@ func (d *Daemon) DeletePod(req *ctlplaneapi.DeletePodRequest) error {
 			ErrorMessage: "Pod not found in CPU State",
 		}
 		d.logger.Error(err, "cannot delete pod")
-		return err
+		return nil, err
+	}
+	// once started, deletion is not aborted, see deleteContainers
+	if ctx.Err() != nil {
+		err := newRequestAbortedError(req.PodId, ctx.Err(), 0, len(pod.Containers), "")
+		d.logger.Error(err, "cannot delete pod")
+		return nil, err
 	}
 
+	released := d.releasedResources(allocatedContainers(pod))
 	var err error
-	if err = d.deleteContainers(pod.Containers); err != nil {
+	if err = d.deleteContainers(allocatedContainers(pod)); err != nil {
 		d.logger.Error(err, "cannot delete containers") // ignore deletion errors
 	}
 
+	for _, c := range pod.Containers {
+		d.state.clearCgroupPath(c.CID) // also containers of the pod with expired lease
+		d.state.clearAllocationExplanation(c.CID)
+	}
 	delete(d.state.Pods, req.PodId)
+	d.state.addTombstone(req.PodId, time.Now(), d.options.tombstoneTTL)
 
 	if err := d.saveState(); err != nil {
 		d.logger.Error(err, "cannot save state")
 	}
-
-	d.logger.Info("pod allocation deleted")
-	return err
+	return &ctlplaneapi.AllocatedPodResources{PodID: req.PodId, ContainerResources: released}, err
 }
 
 // UpdatePod Creates a pod with given resource allocation for the parent pod and all.
-// Error handling: this function is reentrant.
-func (d *Daemon) UpdatePod(req *ctlplaneapi.UpdatePodRequest) (*ctlplaneapi.AllocatedPodResources, error) {
-	if err := ctlplaneapi.ValidateUpdatePodRequest(req); err != nil {
-		d.logger.Error(err, "validation error")
-		return nil, DaemonError{ErrorType: PodSpecError, ErrorMessage: err.Error()}
+// Error handling: this function is reentrant. Dry run requests compute the allocation on a copy of the
+// state, see dryRunDaemon. Partial requests, eg. retries of failed containers, update only listed
+// containers and keep the others.
+func (d *Daemon) UpdatePod(ctx context.Context, req *ctlplaneapi.UpdatePodRequest) (*ctlplaneapi.AllocatedPodResources, error) {
+	if req.DryRun && !d.dryRun {
+		return d.dryRunUpdatePod(ctx, req)
+	}
+	if err := d.checkNodeName(req.PodId, req.NodeName); err != nil {
+		d.logger.Error(err, "cannot update pod")
+		return nil, err
+	}
+	validationErr := ctlplaneapi.ValidateUpdatePodRequest(req)
+	if validationErr != nil {
+		d.logger.Error(validationErr, "validation error")
+		if !d.bestEffortValidation(req.PodId) {
+			return nil, DaemonError{ErrorType: PodSpecError, ErrorMessage: validationErr.Error()}
+		}
+	}
+	profile, err := d.podProfile(req.Annotations)
+	if err != nil {
+		d.logger.Error(err, "cannot update pod")
+		return nil, err
+	}
+
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	defer d.traceRequest(traceUpdatePod, req, d.traceHash())
+
+	if ctx.Err() != nil {
+		err := newRequestAbortedError(req.PodId, ctx.Err(), 0, len(req.Containers), "")
+		d.logger.Error(err, "cannot update pod")
+		return nil, err
 	}
 	if _, ok := d.state.Pods[req.PodId]; !ok {
 		err := DaemonError{
 			ErrorType:    PodNotFound,
 			ErrorMessage: fmt.Sprintf("Pod %s does not exist, cannot update", req.PodId),
 		}
+		if d.state.recentlyDeleted(req.PodId, time.Now(), d.options.tombstoneTTL) {
+			err.ErrorMessage = fmt.Sprintf("Pod %s was recently deleted, cannot update", req.PodId)
+			metrics.RecentlyDeletedPodUpdates.Inc()
+		}
 		d.logger.Error(err, "cannot update pod")
 		return nil, err
 	}
 
-	d.stateMu.Lock()
-	defer d.stateMu.Unlock()
+	if err := checkGeneration(d.state.Pods[req.PodId], req.Generation); err != nil {
+		d.logger.Error(err, "cannot update pod")
+		return nil, err
+	}
 
-	containersCpus := []ctlplaneapi.AllocatedContainerResource{}
+	if validationErr != nil {
+		return d.makePodUnmanaged(req.PodId, validationErr)
+	}
 
-	d.logger.Info("update pod allocation", "request", req)
+	containersCpus := []ctlplaneapi.AllocatedContainerResource{}
 
 	pod := d.state.Pods[req.PodId]
+	pod.Unmanaged, pod.UnmanagedReason = false, ""
+	if pod.LeaseExpired {
+		if err := d.renewLease(ctx, &pod); err != nil {
+			return nil, abortedOr(ctx, err, req.PodId, 0, len(req.Containers), "")
+		}
+		d.state.Pods[req.PodId] = pod
+	}
+	if placement := profile.placement(req.Resources.GetCpuAffinity()); placement != pod.Placement {
+		if err := d.repackPod(ctx, &pod, placement); err != nil {
+			return nil, abortedOr(ctx, err, req.PodId, 0, len(req.Containers), "")
+		}
+	}
+	if pinning := d.getMemoryPinning(req.MemoryPinning, pod.Namespace, profile); pinning != pod.MemoryPinning {
+		if err := d.repinPodMemory(ctx, &pod, pinning); err != nil {
+			return nil, abortedOr(ctx, err, req.PodId, 0, len(req.Containers), "")
+		}
+	}
+	pod.LeaseExpiry = leaseExpiry(time.Now(), req.ExclusiveLeaseSeconds)
+	pod.Labels, pod.Annotations = req.Labels, req.Annotations
+	if req.Generation > 0 {
+		pod.Generation = req.Generation
+	}
 	pC := pod.Containers
+	wanted := keepSharedPoolFallbacks(pC, containersFromRequest(d.logger, req.Containers, req.PodId, pod.Namespace))
 
-	// pods present in current set, not present in request
-	deleted := getDeletedContainers(pC, req.Containers)
+	// pods present in current set, not present in request; partial requests keep them
+	unlisted := getDeletedContainers(pC, req.Containers)
+	deleted := unlisted
+	if req.Partial {
+		deleted = nil
+	}
 	d.logger.V(2).Info("deleted containers", "containers", deleted)
 	deletedErr := d.deleteContainers(deleted)
 
 	// pods present in current set, and present in request, but with different parameters
-	updated := getChangedContainers(d.logger, pC, req.Containers)
+	updated := getChangedContainers(pC, wanted)
 	d.logger.V(2).Info("updated containers", "containers", updated)
-	cpus, updatedContainers, updatedErr := d.updateContainers(updated)
+	cpus, updatedContainers, updatedErr := d.updateContainers(ctx, updated)
 	containersCpus = append(containersCpus, cpus...)
 
 	// pods not present in current set, present in request
-	added := getAddedContainers(d.logger, pC, req.Containers, req.PodId)
+	added := getAddedContainers(pC, wanted)
 	d.logger.V(2).Info("added containers", "containers", added)
-	cpus, addedContainers, addedErr := d.addContainers(added)
+	cpus, addedContainers, addedErr := d.addContainers(ctx, added, criticalContainers(req.Annotations))
 	containersCpus = append(containersCpus, cpus...)
 
 	pod.Containers = make([]Container, 0, len(req.Containers))
-	pod.Containers = append(pod.Containers, getNotModifiedContainers(d.logger, pC, req.Containers)...)
+	pod.Containers = append(pod.Containers, getNotModifiedContainers(pC, wanted)...)
 	pod.Containers = append(pod.Containers, updatedContainers...)
 	pod.Containers = append(pod.Containers, addedContainers...)
+	if req.Partial {
+		pod.Containers = append(pod.Containers, unlisted...)
+	}
 	d.state.Pods[req.PodId] = pod
 
 	if err := d.saveState(); err != nil {
 		return nil, *err
 	}
-	d.logger.Info("pod allocation updated")
 
+	// containers of the request in its order, followed by the deleted ones
+	results := containerResults{}
+	results.add(containerIDs(deleted), deletedErr, resultDeleted)
+	results.add(updatedContainerIDs(updated), updatedErr, resultUpdated)
+	results.add(containerIDs(added), addedErr, resultAllocated)
+	podResources := &ctlplaneapi.AllocatedPodResources{
+		ContainerResources: containersCpus,
+		ContainerResults: results.ordered(
+			append(requestContainerIDs(req.Containers), containerIDs(deleted)...), resultUnchanged,
+		),
+	}
+
+	if ctx.Err() != nil && (addedErr != nil || updatedErr != nil) {
+		// changes applied before the request was aborted are kept, the agent repeats the update
+		processed := len(updatedContainers) + len(addedContainers)
+		err := newRequestAbortedError(req.PodId, ctx.Err(), processed, len(updated)+len(added), "")
+		d.logger.Error(err, "cannot update pod")
+		return podResources, err
+	}
 	if deletedErr != nil || addedErr != nil || updatedErr != nil {
-		return &ctlplaneapi.AllocatedPodResources{ContainerResources: containersCpus}, DaemonError{
+		return podResources, DaemonError{
 			ErrorMessage: fmt.Sprintf("Delete errors: %s, Add errors: %s, Update errors: %s",
 				errOrNil(deletedErr),
 				errOrNil(addedErr),
@@ -320,11 +696,187 @@ func (d *Daemon) UpdatePod(req *ctlplaneapi.UpdatePodRequest) (*ctlplaneapi.Allo
 			ErrorType: RuntimeError,
 		}
 	}
-	return &ctlplaneapi.AllocatedPodResources{
-		ContainerResources: containersCpus,
+	return podResources, nil
+}
+
+// GetPod returns current allocation of the pod. It reads the state published by the last completed
+// update, without waiting for the state lock.
+func (d *Daemon) GetPod(_ context.Context, req *ctlplaneapi.GetPodRequest) (*ctlplaneapi.AllocatedPodResources, error) {
+	if err := ctlplaneapi.ValidateGetPodRequest(req); err != nil {
+		d.logger.Error(err, "validation error")
+		return nil, DaemonError{ErrorType: PodSpecError, ErrorMessage: err.Error()}
+	}
+
+	s := d.readableState()
+	pod, ok := s.Pods[req.PodId]
+	if !ok {
+		return nil, DaemonError{
+			ErrorType:    PodNotFound,
+			ErrorMessage: fmt.Sprintf("Pod %s does not exist", req.PodId),
+		}
+	}
+	podResources := s.allocatedPodResources(pod)
+	return &podResources, nil
+}
+
+// ListPods returns current allocations of all pods, ordered by pod id.
+func (d *Daemon) ListPods(_ context.Context, _ *ctlplaneapi.ListPodsRequest) ([]ctlplaneapi.AllocatedPodResources, error) {
+	return d.readableState().sortedPods(), nil
+}
+
+// GetContainer returns current allocation of the container selected by pod id and container name, so
+// that allocations can be inspected without knowing container runtime ids. Like GetPod, it reads the
+// published state.
+func (d *Daemon) GetContainer(_ context.Context, req *ctlplaneapi.GetContainerRequest) (*ctlplaneapi.AllocatedContainerResource, error) {
+	if err := ctlplaneapi.ValidateGetContainerRequest(req); err != nil {
+		d.logger.Error(err, "validation error")
+		return nil, DaemonError{ErrorType: PodSpecError, ErrorMessage: err.Error()}
+	}
+
+	s := d.readableState()
+	pod, ok := s.Pods[req.PodId]
+	if !ok {
+		return nil, DaemonError{
+			ErrorType:    PodNotFound,
+			ErrorMessage: fmt.Sprintf("Pod %s does not exist", req.PodId),
+		}
+	}
+	for _, c := range pod.Containers {
+		if c.Name == req.ContainerName {
+			container := s.allocatedContainerResource(c)
+			return &container, nil
+		}
+	}
+	return nil, DaemonError{
+		ErrorType:    ContainerNotFound,
+		ErrorMessage: fmt.Sprintf("Container %s does not exist in pod %s", req.ContainerName, req.PodId),
+	}
+}
+
+// GetDaemonInfo returns information about the daemon, eg. cgroup version detected at startup and build of
+// the daemon.
+func (d *Daemon) GetDaemonInfo(_ context.Context, req *ctlplaneapi.GetDaemonInfoRequest) (*ctlplaneapi.DaemonInfo, error) {
+	return &ctlplaneapi.DaemonInfo{
+		CgroupVersion: ctlplaneapi.CgroupVersion(d.readableState().CgroupVersion),
+		Build:         version.Get(),
+		PolicyTiers:   d.options.policyTiersInfo(),
 	}, nil
 }
 
+// CreateNamespaceBucket creates cpu bucket of the namespace before any pod of the namespace arrives. The
+// bucket is kept until it is deleted, even if the namespace has no pods.
+func (d *Daemon) CreateNamespaceBucket(
+	_ context.Context,
+	req *ctlplaneapi.CreateNamespaceBucketRequest,
+) (*ctlplaneapi.NamespaceBucketInfo, error) {
+	if err := ctlplaneapi.ValidateCreateNamespaceBucketRequest(req); err != nil {
+		d.logger.Error(err, "validation error")
+		return nil, DaemonError{ErrorType: PodSpecError, ErrorMessage: err.Error()}
+	}
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	p, ok := d.policy.(NamespaceBucketPolicy)
+	if !ok {
+		return nil, errNamespaceBucketsNotSupported
+	}
+	defer d.traceRequest(traceCreateNamespaceBucket, req, d.traceHash())
+
+	bucket, err := p.CreateNamespaceBucket(req.Namespace, int(req.MinCpus), uint64(req.CpuWeight), &d.state)
+	if err != nil {
+		return nil, err
+	}
+	return &bucket, nil
+}
+
+// DeleteNamespaceBucket releases cpu bucket of the namespace. Bucket still used by containers of the
+// namespace is released together with the last of them.
+func (d *Daemon) DeleteNamespaceBucket(
+	_ context.Context,
+	req *ctlplaneapi.DeleteNamespaceBucketRequest,
+) (*ctlplaneapi.NamespaceBucketInfo, error) {
+	if err := ctlplaneapi.ValidateDeleteNamespaceBucketRequest(req); err != nil {
+		d.logger.Error(err, "validation error")
+		return nil, DaemonError{ErrorType: PodSpecError, ErrorMessage: err.Error()}
+	}
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	p, ok := d.policy.(NamespaceBucketPolicy)
+	if !ok {
+		return nil, errNamespaceBucketsNotSupported
+	}
+	defer d.traceRequest(traceDeleteNamespaceBucket, req, d.traceHash())
+
+	bucket, err := p.DeleteNamespaceBucket(req.Namespace, &d.state)
+	if err != nil {
+		return nil, err
+	}
+	return &bucket, nil
+}
+
+// allocatedPodResources describes current allocation of the pod.
+func (d *DaemonState) allocatedPodResources(pod PodMetadata) ctlplaneapi.AllocatedPodResources {
+	podResources := ctlplaneapi.AllocatedPodResources{
+		PodID:              pod.PID,
+		ContainerResources: make([]ctlplaneapi.AllocatedContainerResource, 0, len(pod.Containers)),
+		Unmanaged:          pod.Unmanaged,
+		UnmanagedReason:    pod.UnmanagedReason,
+	}
+	for _, c := range pod.Containers {
+		podResources.ContainerResources = append(podResources.ContainerResources, d.allocatedContainerResource(c))
+	}
+	return podResources
+}
+
+// checkGeneration rejects requests carrying older version of the pod than the one already applied, eg.
+// updates delayed by agent resync. Requests and pods without known version are never rejected.
+func checkGeneration(pod PodMetadata, generation int64) error {
+	if generation <= 0 || generation >= pod.Generation {
+		return nil
+	}
+	metrics.StalePodRequests.Inc()
+	return DaemonError{
+		ErrorType: StaleRequest,
+		ErrorMessage: fmt.Sprintf(
+			"Pod %s request of generation %d is older than applied generation %d", pod.PID, generation, pod.Generation,
+		),
+	}
+}
+
+// checkNodeName rejects pod requests meant for another node than the one the daemon runs on, eg. sent
+// to a wrong node when host ports of daemons collide behind a misconfigured service. Requests without
+// node name, eg. from older agents, and daemons without configured node name accept all requests.
+func (d *Daemon) checkNodeName(pid, nodeName string) error {
+	if nodeName == "" || d.options.nodeName == "" || nodeName == d.options.nodeName {
+		return nil
+	}
+	metrics.MisroutedPodRequests.Inc()
+	return DaemonError{
+		ErrorType: NodeMismatch,
+		ErrorMessage: fmt.Sprintf(
+			"Pod %s request is meant for node %s, the daemon runs on node %s", pid, nodeName, d.options.nodeName,
+		),
+	}
+}
+
+// getMemoryPinning returns pod memory pinning setting. Pod setting takes precedence over namespace
+// configuration.
+func (d *Daemon) getMemoryPinning(
+	pinning ctlplaneapi.MemoryPinning,
+	namespace string,
+	profile Profile,
+) ctlplaneapi.MemoryPinning {
+	if pinning != ctlplaneapi.MemoryPinning_MEMORY_PINNING_DEFAULT {
+		return pinning
+	}
+	if profile.MemoryPinning != ctlplaneapi.MemoryPinning_MEMORY_PINNING_DEFAULT {
+		return profile.MemoryPinning
+	}
+	if _, ok := d.options.memoryPinningNamespaces[namespace]; ok {
+		return ctlplaneapi.MemoryPinning_MEMORY_PINNING_ENABLED
+	}
+	return ctlplaneapi.MemoryPinning_MEMORY_PINNING_DEFAULT
+}
+
 func errOrNil(err error) string {
 	if err != nil {
 		return err.Error()
@@ -333,64 +885,203 @@ func errOrNil(err error) string {
 }
 
 func (d *Daemon) saveState() *DaemonError {
+	if d.dryRun {
+		return nil
+	}
 	d.logger.Info("saving state")
-	if err := d.state.SaveState(); err != nil {
+	d.accountUsage(time.Now())
+	d.updateFragmentationMetrics()
+	d.updateAllocationMetrics()
+	d.exportIsolatedCpus()
+	d.applySharedPool()
+	d.applyPodCgroups()
+	d.publishAllocationEvents()
+	d.scheduleCgroupRetry()
+	prev := d.readableState()
+	d.logStateDiff(prev)
+	d.publishReadState()
+	if err := d.persistState(prev); err != nil {
 		d.logger.Error(err, "cannot save daemon state")
 		return &DaemonError{RuntimeError, "Cannot save daemon state: " + err.Error()}
 	}
 	return nil
 }
 
+// deleteContainers frees resources of deleted containers. Deletion is not aborted by cancellation of the
+// request, as partially deleted pods would leak cpus.
 func (d *Daemon) deleteContainers(deleted []Container) error {
+	ctx := context.Background()
 	failed := failedContainersErrors{}
 	for _, it := range deleted {
-		if err := d.policy.DeleteContainer(it, &d.state); err != nil {
+		if err := d.policyFor(it).DeleteContainer(ctx, it, &d.state); err != nil {
 			failed = append(failed, failedContainer{it.CID, err})
 		}
+		if _, ok := d.state.Allocated[it.CID]; ok {
+			d.logger.Info("removing allocation left by the allocator", "cid", it.CID)
+			delete(d.state.Allocated, it.CID)
+		}
+		d.state.clearMemoryNodes(it.CID)
+		d.state.clearCgroupPath(it.CID)
+		d.state.clearAllocationExplanation(it.CID)
+		d.state.setBorrowedCpus(it.CID, CPUSet{})
+		d.state.releaseAllocatedAt(it.CID, time.Now())
 	}
 	return failed.ErrorOrNil()
 }
 
-func (d *Daemon) updateContainers(updated []containerUpdated) ([]ctlplaneapi.AllocatedContainerResource, []Container, error) {
+func (d *Daemon) updateContainers(
+	ctx context.Context,
+	updated []containerUpdated,
+) ([]ctlplaneapi.AllocatedContainerResource, []Container, error) {
 	allocatedContainers := []ctlplaneapi.AllocatedContainerResource{}
 	failed := failedContainersErrors{}
 	updatedContainers := []Container{}
 
 	for _, it := range updated {
-		err := d.policy.DeleteContainer(it.current, &d.state)
-		if err != nil {
-			failed = append(failed, failedContainer{it.current.CID, err})
+		if !requiresReallocation(it.current, it.wanted) && !d.resizesSharedContainer(it.current, it.wanted) {
+			d.logger.V(2).Info("container change does not affect cpus, updating metadata only", "cid", it.wanted.CID)
+			updatedContainers = append(updatedContainers, it.wanted)
 			continue
 		}
-		err = d.policy.AssignContainer(it.wanted, &d.state)
-		if err != nil {
+		if err := d.reallocateContainer(ctx, it); err != nil {
 			failed = append(failed, failedContainer{it.current.CID, err})
 			continue
 		}
-		allocatedContainers = append(allocatedContainers, ctlplaneapi.AllocatedContainerResource{
-			ContainerID: it.wanted.CID,
-			CPUSet:      d.state.Allocated[it.wanted.CID],
-		})
+		now := time.Now()
+		d.state.releaseAllocatedAt(it.current.CID, now)
+		d.state.setAllocatedAt(it.wanted.CID, now)
+		allocatedContainers = append(allocatedContainers, d.state.allocatedContainerResource(it.wanted))
 		updatedContainers = append(updatedContainers, it.wanted)
 	}
 	return allocatedContainers, updatedContainers, failed.ErrorOrNil()
 }
 
-func (d *Daemon) addContainers(added []Container) ([]ctlplaneapi.AllocatedContainerResource, []Container, error) {
+// sortedBySize returns containers ordered by descending number of cpus, so that big containers are
+// allocated before small ones fragment the topology. Order of equally sized containers is preserved.
+func sortedBySize(containers []Container) []Container {
+	sorted := append([]Container{}, containers...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Cpus > sorted[j].Cpus
+	})
+	return sorted
+}
+
+// allocatedContainerResource describes current allocation of the container.
+func (d *DaemonState) allocatedContainerResource(c Container) ctlplaneapi.AllocatedContainerResource {
+	return ctlplaneapi.AllocatedContainerResource{
+		ContainerID:     c.CID,
+		CPUSet:          d.Allocated[c.CID],
+		QoS:             c.QS.toQoSClass(),
+		MemoryNodes:     d.getMemoryNodes(c.CID),
+		Exclusive:       c.QS == Guaranteed && !d.Pods[c.PID].LeaseExpired,
+		AllocatedAt:     d.getAllocatedAt(c.CID),
+		CgroupPath:      d.getCgroupPath(c.CID),
+		Explanation:     d.getAllocationExplanation(c.CID),
+		EffectiveCPUSet: d.getEffectiveCPUs(c.CID),
+	}
+}
+
+// reallocateContainer frees container cpus and assigns them again according to the wanted container
+// specification. Allocators are hinted to prefer the just freed cpus.
+func (d *Daemon) reallocateContainer(ctx context.Context, it containerUpdated) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := d.checkExclusiveCpusCap(it.wanted); err != nil {
+		return err
+	}
+	if err := d.checkSystemPressure(it.wanted); err != nil {
+		return err
+	}
+	d.state.setAllocationHint(it.wanted.CID, CPUSetFromBucketList(d.state.Allocated[it.current.CID]))
+	defer d.state.clearAllocationHint(it.wanted.CID)
+
+	policy := d.policyFor(it.current)
+	if err := policy.DeleteContainer(ctx, it.current, &d.state); err != nil {
+		return err
+	}
+	// allocators do not touch the cpuset of non-guaranteed containers, so widen it back to the
+	// shared pool before the new assignment
+	if it.current.QS == Guaranteed && it.wanted.QS != Guaranteed {
+		if err := policy.ClearContainer(ctx, it.current, &d.state); err != nil {
+			return err
+		}
+	}
+	return policy.AssignContainer(ctx, it.wanted, &d.state)
+}
+
+// assignContainer assigns cpus to the container, unless the request was canceled, the assignment would
+// exceed the cap of exclusive cpus or it is refused under system pressure.
+func (d *Daemon) assignContainer(ctx context.Context, c Container) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := d.checkExclusiveCpusCap(c); err != nil {
+		return err
+	}
+	if err := d.checkSystemPressure(c); err != nil {
+		return err
+	}
+	return d.policyFor(c).AssignContainer(ctx, c, &d.state)
+}
+
+// checkExclusiveCpusCap verifies that exclusive allocation of the container cpus does not exceed the
+// configured cap of managed cpus. Cpus currently allocated to the container are not counted, as they
+// are freed on reallocation.
+func (d *Daemon) checkExclusiveCpusCap(c Container) error {
+	if c.QS != Guaranteed || d.options.exclusiveCpusCap == maxExclusiveCpusCap {
+		return nil
+	}
+	limit := len(d.state.Topology.Topology.GetLeafs()) * d.options.exclusiveCpusCap / maxExclusiveCpusCap
+	allocated := d.state.exclusiveCpusCount(c.CID)
+	if allocated+c.Cpus <= limit {
+		return nil
+	}
+	metrics.ExclusiveCpusCapExceeded.Inc()
+	return DaemonError{
+		ErrorType: ExclusiveCpusCapExceeded,
+		ErrorMessage: fmt.Sprintf(
+			"cannot allocate %d exclusive cpus, %d of %d allowed cpus already allocated",
+			c.Cpus,
+			allocated,
+			limit,
+		),
+	}
+}
+
+// requiresReallocation checks if container change affects its cpu allocation. Only guaranteed containers
+// get exclusive cpus, so changes not touching QoS class nor number of guaranteed cpus (e.g. memory
+// changes) do not need to go through the policy.
+func requiresReallocation(current, wanted Container) bool {
+	if (current.QS == Guaranteed) != (wanted.QS == Guaranteed) {
+		return true
+	}
+	return wanted.QS == Guaranteed && current.Cpus != wanted.Cpus
+}
+
+func (d *Daemon) addContainers(
+	ctx context.Context,
+	added []Container,
+	critical map[string]struct{},
+) ([]ctlplaneapi.AllocatedContainerResource, []Container, error) {
 	allocatedContainers := []ctlplaneapi.AllocatedContainerResource{}
 	addedContainers := []Container{}
 	failed := failedContainersErrors{}
 
 	for _, it := range added {
-		err := d.policy.AssignContainer(it, &d.state)
+		snapshot := d.state.snapshot()
+		it, err := d.assignPodContainer(ctx, it, critical)
 		if err != nil {
+			// cpus taken before the failure, eg. of the cgroup write, shall not stay allocated to the container
+			d.state.restore(snapshot)
+			d.state.clearMemoryNodes(it.CID)
+			d.state.clearCgroupPath(it.CID)
+			d.state.clearAllocationExplanation(it.CID)
 			failed = append(failed, failedContainer{it.CID, err})
 			continue
 		}
-		allocatedContainers = append(allocatedContainers, ctlplaneapi.AllocatedContainerResource{
-			ContainerID: it.CID,
-			CPUSet:      d.state.Allocated[it.CID],
-		})
+		d.state.setAllocatedAt(it.CID, time.Now())
+		allocatedContainers = append(allocatedContainers, d.state.allocatedContainerResource(it))
 		addedContainers = append(addedContainers, it)
 	}
 	return allocatedContainers, addedContainers, failed.ErrorOrNil()
@@ -413,54 +1104,86 @@ func getDeletedContainers(current []Container, wanted []*ctlplaneapi.ContainerIn
 	return deleted
 }
 
-func getChangedContainers(logger logr.Logger, current []Container, wanted []*ctlplaneapi.ContainerInfo) []containerUpdated {
+func getChangedContainers(current []Container, wanted []Container) []containerUpdated {
 	changed := make([]containerUpdated, 0, len(wanted))
 	for _, cc := range wanted {
 		for _, oc := range current {
-			if oc.CID == cc.ContainerId {
-				if ccr := containerFromRequest(logger, cc, oc.PID); oc != ccr {
-					changed = append(changed, containerUpdated{
-						current: oc,
-						wanted:  ccr,
-					})
-				}
+			if oc.CID == cc.CID && oc != cc {
+				changed = append(changed, containerUpdated{
+					current: oc,
+					wanted:  cc,
+				})
 			}
 		}
 	}
 	return changed
 }
 
-func getNotModifiedContainers(logger logr.Logger, current []Container, wanted []*ctlplaneapi.ContainerInfo) []Container {
+func getNotModifiedContainers(current []Container, wanted []Container) []Container {
 	notChanged := make([]Container, 0, len(wanted))
 	for _, cc := range wanted {
 		for _, oc := range current {
-			if oc.CID == cc.ContainerId {
-				if ccr := containerFromRequest(logger, cc, oc.PID); oc == ccr {
-					notChanged = append(notChanged, oc)
-				}
+			if oc == cc {
+				notChanged = append(notChanged, oc)
 			}
 		}
 	}
 	return notChanged
 }
 
-func getAddedContainers(logger logr.Logger, current []Container, wanted []*ctlplaneapi.ContainerInfo, podID string) []Container {
+func getAddedContainers(current []Container, wanted []Container) []Container {
 	added := make([]Container, 0, len(wanted))
 	for _, cc := range wanted {
 		exist := false
 		for _, oc := range current {
-			if oc.CID == cc.ContainerId {
+			if oc.CID == cc.CID {
 				exist = true
 				break
 			}
 		}
 		if !exist {
-			added = append(added, containerFromRequest(logger, cc, podID))
+			added = append(added, cc)
 		}
 	}
 	return added
 }
 
+// containersFromRequest returns containers of the pod request in the request order, with namespace and
+// QoS class of the pod.
+func containersFromRequest(
+	logger logr.Logger,
+	reqs []*ctlplaneapi.ContainerInfo,
+	podID string,
+	namespace string,
+) []Container {
+	containers := make([]Container, 0, len(reqs))
+	for _, it := range reqs {
+		c := containerFromRequest(logger, it, podID)
+		c.Namespace = namespace
+		containers = append(containers, c)
+	}
+	podQS := podQoS(containers)
+	for i := range containers {
+		containers[i].PodQS = podQS
+	}
+	return containers
+}
+
+// podQoS returns QoS class of the pod with given containers: guaranteed if all containers are
+// guaranteed, best effort if all are best effort, burstable otherwise.
+func podQoS(containers []Container) QoS {
+	if len(containers) == 0 {
+		return BestEffort
+	}
+	qs := containers[0].QS
+	for _, c := range containers[1:] {
+		if c.QS != qs {
+			return Burstable
+		}
+	}
+	return qs
+}
+
 func containerFromRequest(logger logr.Logger, req *ctlplaneapi.ContainerInfo, podID string) Container {
 	qs := BestEffort
 	rm := resource.Quantity{}
@@ -0,0 +1,78 @@
+package cpudaemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+// RequestAbortedError is returned when the pod request is canceled or its deadline passes before all
+// containers of the pod are processed. Its gRPC status is Canceled or DeadlineExceeded, with progress of
+// the request in ErrorInfo details.
+type RequestAbortedError struct {
+	DaemonError
+	Processed int    // number of containers processed before the request was aborted
+	Total     int    // number of containers to be processed by the request
+	Container string // id of the container being processed when the request was aborted, if any
+	Err       error  // error of the request context
+}
+
+func newRequestAbortedError(pid string, err error, processed, total int, cid string) RequestAbortedError {
+	metrics.AbortedPodRequests.Inc()
+	return RequestAbortedError{
+		DaemonError: DaemonError{
+			ErrorType: RequestAborted,
+			ErrorMessage: fmt.Sprintf(
+				"Pod %s request aborted after %d of %d containers: %v", pid, processed, total, err,
+			),
+		},
+		Processed: processed,
+		Total:     total,
+		Container: cid,
+		Err:       err,
+	}
+}
+
+// Unwrap returns error of the request context, so that aborted requests match context.Canceled and
+// context.DeadlineExceeded.
+func (e RequestAbortedError) Unwrap() error {
+	return e.Err
+}
+
+// GRPCStatus returns gRPC status of the error with ErrorInfo details.
+func (e RequestAbortedError) GRPCStatus() *status.Status {
+	code := codes.Canceled
+	if errors.Is(e.Err, context.DeadlineExceeded) {
+		code = codes.DeadlineExceeded
+	}
+	s := status.New(code, e.Error())
+	withDetails, err := s.WithDetails(&errdetails.ErrorInfo{
+		Reason: ctlplaneapi.ReasonRequestAborted,
+		Domain: ctlplaneapi.ErrorDomain,
+		Metadata: map[string]string{
+			"processedContainers": strconv.Itoa(e.Processed),
+			"totalContainers":     strconv.Itoa(e.Total),
+			"abortedContainer":    e.Container,
+		},
+	})
+	if err != nil {
+		return s
+	}
+	return withDetails
+}
+
+// abortedOr returns RequestAbortedError describing progress of the pod request if its context is done,
+// the error of the request otherwise.
+func abortedOr(ctx context.Context, err error, pid string, processed, total int, cid string) error {
+	if ctx.Err() == nil {
+		return err
+	}
+	return newRequestAbortedError(pid, ctx.Err(), processed, total, cid)
+}
@@ -0,0 +1,100 @@
+package cpudaemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+func TestRequestAbortedErrorGRPCStatus(t *testing.T) {
+	err := newRequestAbortedError("pid", context.DeadlineExceeded, 1, 3, "cid")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	info := ctlplaneapi.ErrorInfo(err)
+	require.NotNil(t, info)
+	assert.Equal(t, ctlplaneapi.ReasonRequestAborted, info.Reason)
+	assert.Equal(t, map[string]string{
+		"processedContainers": "1",
+		"totalContainers":     "3",
+		"abortedContainer":    "cid",
+	}, info.Metadata)
+
+	assert.Equal(t, codes.Canceled, status.Code(newRequestAbortedError("pid", context.Canceled, 0, 1, "")))
+}
+
+func TestAbortedOrKeepsErrorsOfLiveRequests(t *testing.T) {
+	fail := errors.New("cgroup error")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	assert.Equal(t, fail, abortedOr(ctx, fail, "pid", 0, 1, "cid"))
+	cancel()
+	assert.ErrorAs(t, abortedOr(ctx, fail, "pid", 0, 1, "cid"), &RequestAbortedError{})
+}
+
+func TestTimedOutCreatePodReportsProgress(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+	require.Nil(t, err)
+	p := createTestPod(3)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	aborted := testutil.ToFloat64(metrics.AbortedPodRequests)
+
+	// cgroup write of the second container is stuck until the deadline passes
+	m.On("AssignContainer", p.containers[2], &d.state).Return(nil).Once()
+	m.On("AssignContainer", p.containers[1], &d.state).Return(context.DeadlineExceeded).Run(func(mock.Arguments) {
+		<-ctx.Done()
+	}).Once()
+	m.On("DeleteContainer", p.containers[2], &d.state).Return(nil).Once()
+	m.On("ClearContainer", p.containers[2], &d.state).Return(nil).Once()
+
+	_, err = d.CreatePod(ctx, &ctlplaneapi.CreatePodRequest{
+		PodId:        p.pid,
+		PodName:      p.name,
+		PodNamespace: p.namespace,
+		Resources:    p.resources,
+		Containers:   p.containersResources,
+	})
+
+	require.NotNil(t, err)
+	m.AssertExpectations(t)
+	assert.NotContains(t, d.state.Pods, p.pid)
+	assert.Empty(t, d.state.Allocated)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	var abortedErr RequestAbortedError
+	require.ErrorAs(t, err, &abortedErr)
+	assert.Equal(t, 1, abortedErr.Processed)
+	assert.Equal(t, 3, abortedErr.Total)
+	assert.Equal(t, p.containers[1].CID, abortedErr.Container)
+	assert.Equal(t, aborted+1, testutil.ToFloat64(metrics.AbortedPodRequests))
+}
+
+func TestWriteUntilDoneAbandonsBlockedWrite(t *testing.T) {
+	cgc := newCgroupControllerForTest(0)
+	unblock := make(chan struct{})
+	defer close(unblock)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := cgc.writeUntilDone(ctx, "/cgroup", func() error {
+		<-unblock
+		return nil
+	})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, cgc.writeUntilDone(context.Background(), "/cgroup", func() error { return nil }))
+}
@@ -0,0 +1,74 @@
+package cpudaemon
+
+import (
+	"fmt"
+	"strings"
+
+	"resourcemanagement.controlplane/pkg/numautils"
+)
+
+// sharedPoolExplanation explains allocation of containers which default and numa allocators do not pin.
+const sharedPoolExplanation = "%s allocator: container has no exclusive cpus, it is not pinned"
+
+// lowestCommonNode returns the deepest topology node containing all given cpus, which is the tree node
+// the cpus were taken from when taken together.
+func lowestCommonNode(root *numautils.TopologyNode, cpus CPUSet) *numautils.TopologyNode {
+	node := root
+	for {
+		var next *numautils.TopologyNode
+		for _, child := range node.Children {
+			if cpus.Count() > 0 && cpus.Clone().RemoveAll(nodeCpuSet(child)).Count() == 0 {
+				next = child
+				break
+			}
+		}
+		if next == nil || next.IsLeaf() {
+			return node
+		}
+		node = next
+	}
+}
+
+// countCpus returns number of cpus with the noun, eg. "1 cpu" or "2 cpus".
+func countCpus(n int) string {
+	if n == 1 {
+		return "1 cpu"
+	}
+	return fmt.Sprintf("%d cpus", n)
+}
+
+// describeNode returns human readable name of the topology node, eg. "node 1".
+func describeNode(node *numautils.TopologyNode) string {
+	if node.Type == numautils.Machine {
+		return "machine"
+	}
+	return fmt.Sprintf("%s %d", node.Type, node.Value)
+}
+
+// placementLevel returns type of the first level topology nodes, which placement pipelines select from.
+func placementLevel(t *numautils.NumaTopology) string {
+	if len(t.Topology.Children) == 0 {
+		return "topology node"
+	}
+	return t.Topology.Children[0].Type.String()
+}
+
+// placementName returns name of the placement of the pod as used in allocation explanations.
+func placementName(pod PodMetadata) string {
+	name := strings.ToLower(pod.Placement.String()) + " placement"
+	if group := pod.Annotations[SpreadGroupAnnotation]; group != "" {
+		name += fmt.Sprintf(" in spread group %s", group)
+	}
+	return name
+}
+
+// fallbackExplanation prefixes explanation of the container moved to the shared pool with the reason.
+func fallbackExplanation(reason error, explanation string) string {
+	return fmt.Sprintf("exclusive cpus do not fit (%s), container moved to the shared pool; %s", reason, explanation)
+}
+
+// nonExclusiveExplanation explains allocation of guaranteed container of a namespace configured without
+// exclusive cpus.
+func nonExclusiveExplanation(explanation string) string {
+	return "namespace configured without exclusive cpus, container runs in the shared pool; " + explanation
+}
@@ -0,0 +1,114 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/numautils/testtopo"
+)
+
+// createExplainedPods creates guaranteed pod with two containers and pod requesting no cpus, returns
+// explanations of containers of both pods by container id.
+func createExplainedPods(t *testing.T, d *Daemon) map[string]string {
+	explanations := map[string]string{}
+	for _, req := range []*ctlplaneapi.CreatePodRequest{createPodRequest(createTestPod(2)), createZeroCpusPodRequest()} {
+		reply, err := d.CreatePod(context.Background(), req)
+		require.Nil(t, err)
+		for _, c := range reply.ContainerResources {
+			explanations[c.ContainerID] = c.Explanation
+		}
+	}
+	return explanations
+}
+
+func TestDefaultAllocatorExplainsAllocations(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewDefaultAllocator(newMockedCgroups())))
+
+	explanations := createExplainedPods(t, d)
+
+	assert.Equal(t, map[string]string{
+		"testCid-1":     "default allocator: first 2 cpus of free range 0-127",
+		"testCid-0":     "default allocator: first 1 cpu of free range 2-127",
+		"bestEffortCid": "default allocator: container has no exclusive cpus, it is not pinned",
+		"burstableCid":  "default allocator: container has no exclusive cpus, it is not pinned",
+	}, explanations)
+}
+
+func TestNumaAllocatorExplainsAllocations(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)))
+
+	explanations := createExplainedPods(t, d)
+
+	assert.Equal(t, map[string]string{
+		"testCid-1":     "numa allocator: compact placement selected node 0 with score 0, 2 cpus taken from node 0",
+		"testCid-0":     "numa allocator: compact placement selected node 0 with score 2049, 1 cpu taken from node 0",
+		"bestEffortCid": "numa allocator: container has no exclusive cpus, it is not pinned",
+		"burstableCid":  "numa allocator: container has no exclusive cpus, it is not pinned",
+	}, explanations)
+}
+
+func TestNumaAllocatorExplainsHintedAllocation(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)))
+	c := Container{CID: "cid", PID: "pid", Cpus: 2, QS: Guaranteed}
+	d.state.setAllocationHint(c.CID, CPUSet{2: {}, 4: {}})
+
+	require.Nil(t, d.policy.AssignContainer(context.Background(), c, &d.state))
+
+	assert.Equal(t, "numa allocator: allocation hint 2,4 preferred, 2 cpus taken from node 1", d.state.Explanations[c.CID])
+}
+
+func TestNumaPerNamespaceAllocatorExplainsAllocations(t *testing.T) {
+	a := NewNumaPerNamespaceAllocator(2, newMockedCgroups(), true, false, logr.Discard())
+	d := newTestDaemon(t, NewStaticPolocy(a))
+
+	explanations := createExplainedPods(t, d)
+
+	bucket := "numa-namespace-exclusive allocator: namespace testPid uses bucket 0 (cpus 1,3,5,7), "
+	assert.Equal(t, map[string]string{
+		"testCid-1":     bucket + "2 cpus taken exclusively",
+		"testCid-0":     bucket + "1 cpu taken exclusively",
+		"bestEffortCid": bucket + "runs on cpus not taken exclusively",
+		"burstableCid":  bucket + "runs on cpus not taken exclusively",
+	}, explanations)
+}
+
+func TestAllocationExplanationIsReportedAndForgotten(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewDefaultAllocator(newMockedCgroups())))
+	p := createTestPod(1)
+	_, err := d.CreatePod(context.Background(), createPodRequest(p))
+	require.Nil(t, err)
+
+	req := &ctlplaneapi.GetContainerRequest{PodId: p.pid, ContainerName: "testCid-0"}
+	c, err := d.GetContainer(context.Background(), req)
+
+	require.Nil(t, err)
+	assert.Equal(t, "default allocator: first 1 cpu of free range 0-127", c.Explanation)
+	assert.Equal(t, d.state.Explanations, d.readableState().Explanations)
+
+	_, err = d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: p.pid})
+
+	require.Nil(t, err)
+	assert.Empty(t, d.state.Explanations)
+}
+
+func TestLowestCommonNode(t *testing.T) {
+	spec := testtopo.Spec{Sockets: 2, DiesPerSocket: 1, CoresPerDie: 2, ThreadsPerCore: 2}
+	topology, err := spec.Topology()
+	require.Nil(t, err)
+
+	for cpus, expected := range map[string]string{
+		"0,4": "core 0",
+		"0,1": "package 0",
+		"0,2": "machine",
+		"":    "machine",
+	} {
+		cpuSet, err := CPUSetFromString(cpus)
+		require.Nil(t, err)
+
+		assert.Equal(t, expected, describeNode(lowestCommonNode(topology.Topology, cpuSet)), cpus)
+	}
+}
@@ -0,0 +1,307 @@
+package cpudaemon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/protobuf/proto"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// Operations recorded in the allocation trace.
+const (
+	traceStart                 = "Start"
+	traceCreatePod             = "CreatePod"
+	traceUpdatePod             = "UpdatePod"
+	traceDeletePod             = "DeletePod"
+	traceMigrateContainer      = "MigrateContainer"
+	tracePlanDefragmentation   = "PlanDefragmentation"
+	traceCreateNamespaceBucket = "CreateNamespaceBucket"
+	traceDeleteNamespaceBucket = "DeleteNamespaceBucket"
+	traceCollectOrphans        = "CollectOrphans"
+	traceExpireLeases          = "ExpireLeases"
+	traceKubeletCpus           = "KubeletCpus"
+	traceReserveCPUPool        = "ReserveCPUPool"
+	traceReleaseCPUPool        = "ReleaseCPUPool"
+	traceSetNamespaceConfig    = "SetNamespaceConfig"
+	traceDeleteNamespaceConfig = "DeleteNamespaceConfig"
+)
+
+// traceEntry is a line of the allocation trace. Each daemon start writes an entry with the whole state,
+// followed by entries of requests and of changes made by the daemon itself. Availability hashes of the
+// state before and after each change let the replay detect where it diverges from the traced daemon.
+type traceEntry struct {
+	Time    time.Time `json:"time"`
+	Op      string    `json:"op"`
+	State   []byte    `json:"state,omitempty"`   // encoded state, only in start entries
+	Request []byte    `json:"request,omitempty"` // request in protobuf wire format
+	Targets []string  `json:"targets,omitempty"` // pods, containers or cpus changed by the daemon itself
+	Before  string    `json:"before,omitempty"`
+	After   string    `json:"after"`
+}
+
+// TraceDivergence describes an entry of the allocation trace whose replay leads to cpu availability
+// different from the one of the traced daemon.
+type TraceDivergence struct {
+	Line   int
+	Time   time.Time
+	Op     string
+	Reason string
+}
+
+func (d TraceDivergence) String() string {
+	return fmt.Sprintf("line %d (%s at %s): %s", d.Line, d.Op, d.Time.Format(time.RFC3339Nano), d.Reason)
+}
+
+// TraceReplay is the result of the replay of an allocation trace.
+type TraceReplay struct {
+	Entries     int               // replayed entries, without start entries
+	Divergences []TraceDivergence // entries whose replay diverged from the trace
+	Free        CPUSet            // cpus not exclusively allocated at the end of the replay
+}
+
+// WithAllocationTrace makes the daemon append inputs of its allocation decisions to the trace file: the
+// state at startup, requests changing allocations and changes made by the daemon itself, each with hash
+// of cpu availability. The trace can be replayed with ReplayAllocationTrace.
+func WithAllocationTrace(path string) Option {
+	return func(o *daemonOptions) {
+		o.allocationTracePath = path
+	}
+}
+
+// availabilityHash returns hash of cpus available for allocation and of exclusively allocated cpus.
+func (d *DaemonState) availabilityHash() string {
+	h := fnv.New64a()
+	fmt.Fprintf(
+		h,
+		"%s|%s|%s",
+		topologyAvailableCpus(&d.Topology),
+		CPUSetFromBucketList(d.AvailableCPUs),
+		d.exclusiveCpus(),
+	)
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// openAllocationTrace opens the trace file for appending and records the current state to it.
+func (d *Daemon) openAllocationTrace() error {
+	f, err := os.OpenFile(d.options.allocationTracePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, daemonFilePermission)
+	if err != nil {
+		return DaemonError{ErrorType: ConfigurationError, ErrorMessage: "cannot open allocation trace: " + err.Error()}
+	}
+	state, err := d.state.encode()
+	if err != nil {
+		f.Close()
+		return DaemonError{ErrorType: RuntimeError, ErrorMessage: "cannot encode state: " + err.Error()}
+	}
+	d.trace = f
+	d.writeTraceEntry(traceEntry{Time: time.Now(), Op: traceStart, State: state, After: d.state.availabilityHash()})
+	return nil
+}
+
+// traceHash returns availability hash of the state if the allocation trace is enabled, so that the hash is
+// not computed otherwise. Shall be called with the state lock held.
+func (d *Daemon) traceHash() string {
+	if d.trace == nil {
+		return ""
+	}
+	return d.state.availabilityHash()
+}
+
+// traceRequest records the request processed by the daemon, given the availability hash before it. Shall be
+// deferred by request handlers right after the state lock is taken, so that entries are ordered as the
+// requests changed the state.
+func (d *Daemon) traceRequest(op string, req proto.Message, before string) {
+	if d.trace == nil {
+		return
+	}
+	b, err := proto.Marshal(req)
+	if err != nil {
+		d.logger.Error(err, "cannot trace request", "op", op)
+		return
+	}
+	d.writeTraceEntry(traceEntry{Time: time.Now(), Op: op, Request: b, Before: before, After: d.traceHash()})
+}
+
+// traceChange records the change of allocations made by the daemon itself to given targets. Shall be
+// called with the state lock held.
+func (d *Daemon) traceChange(op string, targets []string, before string, now time.Time) {
+	if d.trace == nil {
+		return
+	}
+	d.writeTraceEntry(traceEntry{Time: now, Op: op, Targets: targets, Before: before, After: d.traceHash()})
+}
+
+func (d *Daemon) writeTraceEntry(e traceEntry) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		d.logger.Error(err, "cannot encode allocation trace entry", "op", e.Op)
+		return
+	}
+	if _, err := d.trace.Write(append(b, '\n')); err != nil {
+		d.logger.Error(err, "cannot write allocation trace", "op", e.Op)
+	}
+}
+
+// closeAllocationTrace closes the trace file. Shall be called with the state lock held.
+func (d *Daemon) closeAllocationTrace() {
+	if d.trace != nil {
+		d.trace.Close()
+		d.trace = nil
+	}
+}
+
+// traceReplayers replay entries of the allocation trace with the daemon. Errors of replayed requests are
+// not returned, as the traced daemon possibly failed them as well; divergence is detected by hashes.
+var traceReplayers = map[string]func(ctx context.Context, d *Daemon, e traceEntry) error{
+	traceCreatePod:             replayRequest((*Daemon).CreatePod),
+	traceUpdatePod:             replayRequest((*Daemon).UpdatePod),
+	traceDeletePod:             replayRequest((*Daemon).DeletePod),
+	traceMigrateContainer:      replayRequest((*Daemon).MigrateContainer),
+	tracePlanDefragmentation:   replayRequest((*Daemon).PlanDefragmentation),
+	traceCreateNamespaceBucket: replayRequest((*Daemon).CreateNamespaceBucket),
+	traceDeleteNamespaceBucket: replayRequest((*Daemon).DeleteNamespaceBucket),
+	traceReserveCPUPool:        replayRequest((*Daemon).ReserveCPUPool),
+	traceReleaseCPUPool:        replayRequest((*Daemon).ReleaseCPUPool),
+	traceSetNamespaceConfig:    replayRequest((*Daemon).SetNamespaceConfig),
+	traceDeleteNamespaceConfig: replayRequest((*Daemon).DeleteNamespaceConfig),
+	traceCollectOrphans: func(ctx context.Context, d *Daemon, e traceEntry) error {
+		d.freeOrphans(ctx, e.Targets, e.Time)
+		return nil
+	},
+	traceExpireLeases: func(_ context.Context, d *Daemon, e traceEntry) error {
+		for _, pid := range e.Targets {
+			if pod, ok := d.state.Pods[pid]; ok {
+				d.expireLease(&pod)
+				d.state.Pods[pid] = pod
+			}
+		}
+		return nil
+	},
+	traceKubeletCpus: func(_ context.Context, d *Daemon, e traceEntry) error {
+		if len(e.Targets) != 1 {
+			return fmt.Errorf("expected kubelet cpus, got %v", e.Targets)
+		}
+		cpus, err := CPUSetFromString(e.Targets[0])
+		if err != nil {
+			return err
+		}
+		d.state.setKubeletCpus(cpus)
+		return nil
+	},
+}
+
+// replayRequest returns replayer of requests handled by the daemon method.
+func replayRequest[R any, PR interface {
+	*R
+	proto.Message
+}, T any](handle func(*Daemon, context.Context, PR) (T, error)) func(context.Context, *Daemon, traceEntry) error {
+	return func(ctx context.Context, d *Daemon, e traceEntry) error {
+		req := PR(new(R))
+		if err := proto.Unmarshal(e.Request, req); err != nil {
+			return err
+		}
+		if _, err := handle(d, ctx, req); err != nil {
+			d.logger.V(1).Info("replayed request failed", "op", e.Op, "error", err)
+		}
+		return nil
+	}
+}
+
+// ReplayAllocationTrace replays the allocation trace with the policy, as a dry run changing no cgroups.
+// The replay starts over from the state recorded by each daemon start, with a fresh copy of the policy,
+// and reports entries after which cpu availability differs from the traced one. Policy shall support
+// dry runs.
+func ReplayAllocationTrace(
+	ctx context.Context,
+	r io.Reader,
+	p Policy,
+	logger logr.Logger,
+	opts ...Option,
+) (TraceReplay, error) {
+	replay := TraceReplay{}
+	dryRun, ok := p.(DryRunPolicy)
+	if !ok {
+		return replay, errDryRunNotSupported
+	}
+	options := newDaemonOptions(opts)
+	options.allocationTracePath = ""
+	var d *Daemon
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxJournalEntrySize)
+	for line := 1; scanner.Scan(); line++ {
+		e := traceEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return replay, fmt.Errorf("line %d: %w", line, err)
+		}
+		if e.Op == traceStart {
+			var err error
+			if d, err = newReplayDaemon(e.State, dryRun, logger, options); err != nil {
+				return replay, fmt.Errorf("line %d: %w", line, err)
+			}
+			continue
+		}
+		if d == nil {
+			return replay, fmt.Errorf("line %d: trace shall begin with %s entry", line, traceStart)
+		}
+		replayer, ok := traceReplayers[e.Op]
+		if !ok {
+			return replay, fmt.Errorf("line %d: unknown operation %s", line, e.Op)
+		}
+		replay.Entries++
+		divergence := TraceDivergence{Line: line, Time: e.Time, Op: e.Op}
+		if hash := d.state.availabilityHash(); e.Before != "" && hash != e.Before {
+			divergence.Reason = "cpu availability before the entry differs"
+		}
+		if err := replayer(ctx, d, e); err != nil {
+			return replay, fmt.Errorf("line %d: %w", line, err)
+		}
+		if divergence.Reason == "" && d.state.availabilityHash() != e.After {
+			divergence.Reason = "cpu availability after the entry differs"
+		}
+		if divergence.Reason != "" {
+			replay.Divergences = append(replay.Divergences, divergence)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return replay, err
+	}
+	if d != nil {
+		replay.Free = d.state.sharedPool()
+	}
+	return replay, nil
+}
+
+// newReplayDaemon returns dry run daemon working on the encoded state with a copy of the policy.
+func newReplayDaemon(state []byte, p DryRunPolicy, logger logr.Logger, options daemonOptions) (*Daemon, error) {
+	s, err := DaemonStateFromReader(bytes.NewReader(state))
+	if err != nil {
+		return nil, err
+	}
+	policy, ok := p.DryRun()
+	if !ok {
+		return nil, errDryRunNotSupported
+	}
+	if s.Allocated == nil {
+		s.Allocated = make(map[string][]ctlplaneapi.CPUBucket)
+	}
+	if s.Pods == nil {
+		s.Pods = make(map[string]PodMetadata)
+	}
+	return &Daemon{
+		state:   s,
+		policy:  policy,
+		logger:  logger.WithName("replay"),
+		options: options,
+		dryRun:  true,
+	}, nil
+}
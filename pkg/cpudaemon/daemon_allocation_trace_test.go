@@ -0,0 +1,146 @@
+package cpudaemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// traceTestPods creates, updates and deletes pods of the traced daemon.
+func traceTestPods(t *testing.T, d *Daemon) {
+	ctx := context.Background()
+	_, err := d.CreatePod(ctx, createPodRequest(createTestPod(2)))
+	require.Nil(t, err)
+	other := createPodRequest(createTestPod(1))
+	other.PodId = "otherPid"
+	other.Containers[0].ContainerId = "otherCid"
+	_, err = d.CreatePod(ctx, other)
+	require.Nil(t, err)
+	update := createPodRequest(createTestPod(1))
+	_, err = d.UpdatePod(ctx, &ctlplaneapi.UpdatePodRequest{
+		PodId:      update.PodId,
+		Resources:  update.Resources,
+		Containers: update.Containers,
+	})
+	require.Nil(t, err)
+	_, err = d.DeletePod(ctx, &ctlplaneapi.DeletePodRequest{PodId: "otherPid"})
+	require.Nil(t, err)
+	_, err = d.DeletePod(ctx, &ctlplaneapi.DeletePodRequest{PodId: "unknownPid"})
+	require.NotNil(t, err)
+}
+
+func replayTrace(t *testing.T, trace string, p Policy) TraceReplay {
+	f, err := os.Open(trace)
+	require.Nil(t, err)
+	defer f.Close()
+	replay, err := ReplayAllocationTrace(context.Background(), f, p, logr.Discard())
+	require.Nil(t, err)
+	return replay
+}
+
+func TestAllocationTraceReplay(t *testing.T) {
+	for _, allocator := range []string{"default", "numa", "numa-namespace"} {
+		t.Run(allocator, func(t *testing.T) {
+			trace := filepath.Join(t.TempDir(), "allocation.trace")
+			d := newTestDaemon(
+				t, NewStaticPolocy(builtinAllocators()[allocator](newMockedCgroups())), WithAllocationTrace(trace),
+			)
+			traceTestPods(t, d)
+			d.Close()
+
+			replay := replayTrace(t, trace, NewStaticPolocy(builtinAllocators()[allocator](&CgroupsMock{})))
+
+			assert.Empty(t, replay.Divergences)
+			assert.Equal(t, 5, replay.Entries)
+			assert.Equal(t, d.state.sharedPool(), replay.Free)
+		})
+	}
+}
+
+func TestAllocationTraceReplayStartsOverWithEachDaemonStart(t *testing.T) {
+	trace := filepath.Join(t.TempDir(), "allocation.trace")
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)), WithAllocationTrace(trace))
+	traceTestPods(t, d)
+	d.Close()
+	restarted, err := New(
+		"testdata/no_state", "testdata/node_info", d.state.StatePath,
+		NewStaticPolocy(NewNumaAwareAllocator(dryRunController{}, false)), logr.Discard(),
+		WithAllocationTrace(trace),
+	)
+	require.Nil(t, err)
+	_, err = restarted.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: "testPid"})
+	require.Nil(t, err)
+	restarted.Close()
+
+	replay := replayTrace(t, trace, NewStaticPolocy(NewNumaAwareAllocator(&CgroupsMock{}, false)))
+
+	assert.Empty(t, replay.Divergences)
+	assert.Equal(t, 6, replay.Entries)
+	assert.Equal(t, restarted.state.sharedPool(), replay.Free)
+}
+
+func TestAllocationTraceReplayDetectsDivergence(t *testing.T) {
+	trace := filepath.Join(t.TempDir(), "allocation.trace")
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)), WithAllocationTrace(trace))
+	traceTestPods(t, d)
+	d.Close()
+
+	replay := replayTrace(t, trace, NewStaticPolocy(NewDefaultAllocator(&CgroupsMock{})))
+
+	require.NotEmpty(t, replay.Divergences)
+	assert.Equal(t, 2, replay.Divergences[0].Line)
+	assert.Equal(t, traceCreatePod, replay.Divergences[0].Op)
+	assert.Equal(t, "cpu availability after the entry differs", replay.Divergences[0].Reason)
+	assert.Contains(t, replay.Divergences[0].String(), "line 2 (CreatePod at ")
+}
+
+func TestAllocationTraceRecordsOrphansCollection(t *testing.T) {
+	d := newDaemonForGC(t)
+	allocateForTest(t, &d.state, "orphan", cpuSetForTest(t, "3-4"))
+	d.options.allocationTracePath = filepath.Join(t.TempDir(), "allocation.trace")
+	require.Nil(t, d.openAllocationTrace())
+
+	assert.Equal(t, 1, d.collectOrphans())
+	d.Close()
+
+	replay := replayTrace(t, d.options.allocationTracePath, NewStaticPolocy(NewDefaultAllocator(&CgroupsMock{})))
+	assert.Empty(t, replay.Divergences)
+	assert.Equal(t, 1, replay.Entries)
+	assert.True(t, replay.Free.Contains(3))
+}
+
+func TestAllocationTraceIsNotWrittenByDryRun(t *testing.T) {
+	trace := filepath.Join(t.TempDir(), "allocation.trace")
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)), WithAllocationTrace(trace))
+	req := createPodRequest(createTestPod(1))
+	req.DryRun = true
+
+	_, err := d.CreatePod(context.Background(), req)
+	require.Nil(t, err)
+	d.Close()
+
+	b, err := os.ReadFile(trace)
+	require.Nil(t, err)
+	assert.Equal(t, 1, strings.Count(string(b), "\n"), "only the start entry is expected")
+}
+
+func TestReplayAllocationTraceFails(t *testing.T) {
+	p := NewStaticPolocy(NewDefaultAllocator(&CgroupsMock{}))
+	for name, trace := range map[string]string{
+		"not json":          "trace\n",
+		"no start entry":    `{"op":"CreatePod","after":"0"}` + "\n",
+		"unknown operation": `{"op":"Start","state":"e30=","after":"0"}` + "\n" + `{"op":"Reboot","after":"0"}` + "\n",
+	} {
+		_, err := ReplayAllocationTrace(context.Background(), strings.NewReader(trace), p, logr.Discard())
+		assert.NotNil(t, err, name)
+	}
+	_, err := ReplayAllocationTrace(context.Background(), strings.NewReader(""), &MockedPolicy{}, logr.Discard())
+	assert.ErrorIs(t, err, errDryRunNotSupported)
+}
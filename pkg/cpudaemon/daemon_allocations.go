@@ -0,0 +1,54 @@
+package cpudaemon
+
+import (
+	"context"
+	"sort"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// GetAllocations returns snapshot of the daemon state: allocations of all pods, cpus available for
+// allocation, reserved cpu pools and summary of the topology. Like ListPods, it reads the published state, so the snapshot
+// is consistent without blocking updates.
+func (d *Daemon) GetAllocations(_ context.Context, _ *ctlplaneapi.GetAllocationsRequest) (*ctlplaneapi.Allocations, error) {
+	s := d.readableState()
+	allocations := ctlplaneapi.Allocations{
+		Pods:          s.sortedPods(),
+		AvailableCPUs: cloneBuckets(s.AvailableCPUs),
+		NumaNodes:     []ctlplaneapi.NumaNodeAllocation{},
+		CPUPools:      s.sortedCPUPools(),
+	}
+	if s.Topology.Topology == nil {
+		return &allocations, nil
+	}
+	allocations.Cpus = len(s.Topology.Topology.GetLeafs())
+	allocations.ThreadsPerCore = s.Topology.ThreadsPerCore()
+	available := CPUSetFromBucketList(s.AvailableCPUs)
+	for _, node := range s.Topology.Topology.Children {
+		all, free := CPUSet{}, CPUSet{}
+		for _, leaf := range node.GetLeafs() {
+			all.Add(leaf.Value)
+			if leaf.Available() && available.Contains(leaf.Value) {
+				free.Add(leaf.Value)
+			}
+		}
+		allocations.NumaNodes = append(allocations.NumaNodes, ctlplaneapi.NumaNodeAllocation{
+			Node:          node.Value,
+			CPUSet:        all.ToCompactBucketList(),
+			AvailableCPUs: free.ToCompactBucketList(),
+		})
+	}
+	return &allocations, nil
+}
+
+// sortedPods returns allocations of all pods of the state sorted by pod id.
+func (d *DaemonState) sortedPods() []ctlplaneapi.AllocatedPodResources {
+	pods := make([]ctlplaneapi.AllocatedPodResources, 0, len(d.Pods))
+	for _, pod := range d.Pods {
+		pods = append(pods, d.allocatedPodResources(pod))
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].PodID < pods[j].PodID
+	})
+	return pods
+}
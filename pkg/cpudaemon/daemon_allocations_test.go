@@ -0,0 +1,50 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+func TestGetAllocations(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)))
+	p := podWithPlacement(ctlplaneapi.Placement_DEFAULT)
+	createPodForRepack(t, d, p)
+
+	allocations, err := d.GetAllocations(context.Background(), &ctlplaneapi.GetAllocationsRequest{})
+
+	require.Nil(t, err)
+	require.Len(t, allocations.Pods, 1)
+	assert.Equal(t, p.pid, allocations.Pods[0].PodID)
+	require.Len(t, allocations.Pods[0].ContainerResources, 2)
+	assert.Equal(t, 8, allocations.Cpus)
+	assert.Equal(t, 1, allocations.ThreadsPerCore)
+	require.Len(t, allocations.NumaNodes, 2)
+	all, free := CPUSet{}, CPUSet{}
+	for _, node := range allocations.NumaNodes {
+		assert.Equal(t, 4, CPUSetFromBucketList(node.CPUSet).Count())
+		all = all.Merge(CPUSetFromBucketList(node.CPUSet))
+		free = free.Merge(CPUSetFromBucketList(node.AvailableCPUs))
+	}
+	assert.Equal(t, 8, all.Count())
+	assert.Equal(t, 6, free.Count())
+	for _, c := range allocations.Pods[0].ContainerResources {
+		for _, cpu := range CPUSetFromBucketList(c.CPUSet).Sorted() {
+			assert.False(t, free.Contains(cpu), "allocated cpu %d reported as available", cpu)
+		}
+	}
+}
+
+func TestGetAllocationsWithoutPublishedState(t *testing.T) {
+	d := Daemon{}
+
+	allocations, err := d.GetAllocations(context.Background(), &ctlplaneapi.GetAllocationsRequest{})
+
+	require.Nil(t, err)
+	assert.Empty(t, allocations.Pods)
+	assert.Empty(t, allocations.NumaNodes)
+	assert.Zero(t, allocations.Cpus)
+}
@@ -1,13 +1,17 @@
 package cpudaemon
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
 	"resourcemanagement.controlplane/pkg/utils"
 
 	"github.com/containerd/cgroups"
@@ -21,30 +25,60 @@ const ResourceNotSet = ""
 
 // Allocator interface to take cpu.
 type Allocator interface {
-	takeCpus(c Container, s *DaemonState) error
-	freeCpus(c Container, s *DaemonState) error
-	clearCpus(c Container, s *DaemonState) error
+	takeCpus(ctx context.Context, c Container, s *DaemonState) error
+	freeCpus(ctx context.Context, c Container, s *DaemonState) error
+	clearCpus(ctx context.Context, c Container, s *DaemonState) error
 }
 
 // CgroupControllerImpl CgroupController interface implementation.
 type CgroupControllerImpl struct {
-	containerRuntime ContainerRuntime
-	cgroupDriver     CGroupDriver
-	logger           logr.Logger
+	containerRuntime   ContainerRuntime
+	cgroupDriver       CGroupDriver
+	logger             logr.Logger
+	emptyCgroupWait    time.Duration // how long to wait for a live task before pinning empty cgroup
+	cpusetPartitions   bool          // make cgroups of containers with exclusive cpus partition roots
+	partitionParents   *partitionParents
+	cgroupVersion      CgroupVersion
+	kindKubepodsCgroup string // parent of container cgroups with Kind runtime
+	faults             cgroupFaultHook
 }
 
 // NewCgroupController returns initialized CgroupControllerImpl instance.
-func NewCgroupController(containerRuntime ContainerRuntime, cgroupDriver CGroupDriver, logger logr.Logger) CgroupControllerImpl {
-	return CgroupControllerImpl{containerRuntime, cgroupDriver, logger.WithName("cgroupController")}
+func NewCgroupController(
+	containerRuntime ContainerRuntime,
+	cgroupDriver CGroupDriver,
+	logger logr.Logger,
+	opts ...CgroupOption,
+) CgroupControllerImpl {
+	cgc := CgroupControllerImpl{
+		containerRuntime:   containerRuntime,
+		cgroupDriver:       cgroupDriver,
+		logger:             logger.WithName("cgroupController"),
+		emptyCgroupWait:    defaultEmptyCgroupWait,
+		cgroupVersion:      DetectCgroupVersion(),
+		kindKubepodsCgroup: KindKubepodsCgroup,
+	}
+	for _, opt := range opts {
+		opt(&cgc)
+	}
+	return cgc
 }
 
 // CgroupController interface to cgroup library to control cpusets.
 type CgroupController interface {
-	UpdateCPUSet(path string, c Container, cpuSet string, memSet string) error
+	UpdateCPUSet(ctx context.Context, path string, c Container, cpuSet string, memSet string) error
 }
 
 var _ CgroupController = CgroupControllerImpl{}
 
+// CgroupPathResolver is implemented by cgroup controllers able to tell which cgroup directory is
+// written by the cpuset update of the container, so that the path can be recorded in the state.
+type CgroupPathResolver interface {
+	CgroupPath(path string, c Container) string
+}
+
+var _ CgroupPathResolver = CgroupControllerImpl{}
+
 // DefaultAllocator simple static allocator without NUMA.
 type DefaultAllocator struct {
 	ctrl CgroupController
@@ -67,7 +101,7 @@ func newAllocator(ct CgroupController) *DefaultAllocator {
 // SliceName returns path to container cgroup leaf slice in cgroupfs.
 func SliceName(c Container, r ContainerRuntime, d CGroupDriver) string {
 	if r == Kind {
-		return sliceNameKind(c)
+		return sliceNameKind(c, KindKubepodsCgroup)
 	}
 	if d == DriverSystemd {
 		return sliceNameDockerContainerdWithSystemd(c, r)
@@ -75,11 +109,21 @@ func SliceName(c Container, r ContainerRuntime, d CGroupDriver) string {
 	return sliceNameDockerContainerdWithCgroupfs(c, r)
 }
 
-func sliceNameKind(c Container) string {
+// sliceName returns path to container cgroup leaf slice, with kubepods cgroup of the kind node detected
+// for Kind runtime.
+func (cgc CgroupControllerImpl) sliceName(c Container) string {
+	if cgc.containerRuntime == Kind {
+		return sliceNameKind(c, cgc.kindKubepodsCgroup)
+	}
+	return SliceName(c, cgc.containerRuntime, cgc.cgroupDriver)
+}
+
+func sliceNameKind(c Container, kubepods string) string {
 	podType := [3]string{"", "besteffort/", "burstable/"}
 	return fmt.Sprintf(
-		"kubelet/kubepods/%spod%s/%s",
-		podType[c.QS],
+		"%s/%spod%s/%s",
+		kubepods,
+		podType[c.cgroupQoS()],
 		c.PID,
 		strings.ReplaceAll(c.CID, "containerd://", ""),
 	)
@@ -92,8 +136,8 @@ func sliceNameDockerContainerdWithSystemd(c Container, r ContainerRuntime) strin
 	runtimeURLPrefix := [2]string{"docker://", "containerd://"}
 	return fmt.Sprintf(
 		"/kubepods.slice/%skubepods%s-pod%s.slice/%s-%s.scope",
-		sliceType[c.QS],
-		podType[c.QS],
+		sliceType[c.cgroupQoS()],
+		podType[c.cgroupQoS()],
 		strings.ReplaceAll(c.PID, "-", "_"),
 		runtimeTypePrefix[r],
 		strings.ReplaceAll(c.CID, runtimeURLPrefix[r], ""),
@@ -105,14 +149,22 @@ func sliceNameDockerContainerdWithCgroupfs(c Container, r ContainerRuntime) stri
 	runtimeURLPrefix := [2]string{"docker://", "containerd://"}
 	return fmt.Sprintf(
 		"/kubepods/%spod%s/%s",
-		sliceType[c.QS],
+		sliceType[c.cgroupQoS()],
 		c.PID,
 		strings.ReplaceAll(c.CID, runtimeURLPrefix[r], ""),
 	)
 }
 
-func (d *DefaultAllocator) takeCpus(c Container, s *DaemonState) error {
-	if c.QS != Guaranteed {
+// takesExclusiveCpus checks if the container gets exclusive cpus from allocators. Containers requesting
+// no cpus run in the shared pool of the allocator whatever their QoS class, so that pods without
+// requests are never rejected.
+func takesExclusiveCpus(c Container) bool {
+	return c.QS == Guaranteed && c.Cpus > 0
+}
+
+func (d *DefaultAllocator) takeCpus(ctx context.Context, c Container, s *DaemonState) error {
+	if !takesExclusiveCpus(c) {
+		s.setAllocationExplanation(c.CID, fmt.Sprintf(sharedPoolExplanation, "default"))
 		return nil
 	}
 	for i, b := range s.AvailableCPUs {
@@ -126,6 +178,9 @@ func (d *DefaultAllocator) takeCpus(c Container, s *DaemonState) error {
 					EndCPU:   eCPU,
 				},
 			}
+			s.setAllocationExplanation(c.CID, fmt.Sprintf(
+				"default allocator: first %s of free range %d-%d", countCpus(c.Cpus), b.StartCPU, b.EndCPU,
+			))
 
 			var t string
 			if sCPU == eCPU {
@@ -133,7 +188,7 @@ func (d *DefaultAllocator) takeCpus(c Container, s *DaemonState) error {
 			} else {
 				t = strconv.Itoa(sCPU) + "-" + strconv.Itoa(eCPU)
 			}
-			return d.ctrl.UpdateCPUSet(s.CGroupPath, c, t, ResourceNotSet)
+			return updateContainerCPUSet(ctx, d.ctrl, s, c, t, ResourceNotSet)
 		}
 	}
 	return DaemonError{
@@ -142,8 +197,8 @@ func (d *DefaultAllocator) takeCpus(c Container, s *DaemonState) error {
 	}
 }
 
-func (d *DefaultAllocator) freeCpus(c Container, s *DaemonState) error {
-	if c.QS != Guaranteed {
+func (d *DefaultAllocator) freeCpus(ctx context.Context, c Container, s *DaemonState) error {
+	if !takesExclusiveCpus(c) {
 		return nil
 	}
 
@@ -164,65 +219,150 @@ func (d *DefaultAllocator) freeCpus(c Container, s *DaemonState) error {
 	return nil
 }
 
-func (d *DefaultAllocator) clearCpus(c Container, s *DaemonState) error {
+func (d *DefaultAllocator) clearCpus(ctx context.Context, c Container, s *DaemonState) error {
 	var allCpus []ctlplaneapi.CPUBucket
 	allCpus = append(allCpus, s.AvailableCPUs...)
 	for _, allocated := range s.Allocated {
 		allCpus = append(allCpus, allocated...)
 	}
 	cpuSet := CPUSetFromBucketList(allCpus)
-	return d.ctrl.UpdateCPUSet(s.CGroupPath, c, cpuSet.ToCpuString(), ResourceNotSet)
+	return updateContainerCPUSet(ctx, d.ctrl, s, c, cpuSet.ToCpuString(), ResourceNotSet)
+}
+
+// updateContainerCPUSet updates container cpuset and records memory nodes the container is pinned to.
+// If the controller supports partitions, the container is made partition root while its cpus are
+// exclusive.
+func updateContainerCPUSet(ctx context.Context, ctrl CgroupController, s *DaemonState, c Container, cpuSet string, memSet string) error {
+	pc, partitions := ctrl.(PartitionController)
+	exclusive := exclusiveCPUSet(s, c, cpuSet)
+	if partitions && !exclusive {
+		pc.SetPartition(s.CGroupPath, c, false)
+	}
+	if err := ctrl.UpdateCPUSet(ctx, s.CGroupPath, c, cpuSet, memSet); err != nil {
+		if !isRetryable(err) || !s.deferCgroupUpdate(ctrl, c, cpuSet, memSet, err) {
+			return err
+		}
+		s.setMemoryNodes(c.CID, memSet)
+		return nil
+	}
+	delete(s.deferredCgroupUpdates, c.CID)
+	s.recordCgroupUpdate(ctrl, c, cpuSet, memSet)
+	if partitions && exclusive {
+		pc.SetPartition(s.CGroupPath, c, true)
+	}
+	s.setMemoryNodes(c.CID, memSet)
+	if r, ok := ctrl.(CgroupPathResolver); ok {
+		s.setCgroupPath(c.CID, r.CgroupPath(s.CGroupPath, c))
+	}
+	if v, ok := ctrl.(CPUSetVerifier); ok {
+		s.setEffectiveCPUs(c.CID, v.VerifyCPUSet(s.CGroupPath, c, cpuSet))
+	}
+	return nil
 }
 
 // UpdateCPUSet updates the cpu set of a given child process.
-func (cgc CgroupControllerImpl) UpdateCPUSet(pPath string, c Container, cSet string, memSet string) error {
+func (cgc CgroupControllerImpl) UpdateCPUSet(ctx context.Context, pPath string, c Container, cSet string, memSet string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	runtimeURLPrefix := [2]string{"docker://", "containerd://"}
 	if cgc.containerRuntime == Kind || cgc.containerRuntime != Kind &&
 		strings.Contains(c.CID, runtimeURLPrefix[cgc.containerRuntime]) {
-		slice := SliceName(c, cgc.containerRuntime, cgc.cgroupDriver)
+		slice := cgc.sliceName(c)
 		cgc.logger.V(2).Info("allocating cgroup", "cgroupPath", pPath, "slicePath", slice, "cpuSet", cSet, "memSet", memSet)
+		if dir := cgc.CgroupPath(pPath, c); !cgroupExists(dir) {
+			return newCgroupNotReadyError(dir)
+		}
 
-		if cgroups.Mode() == cgroups.Unified {
-			return cgc.updateCgroupsV2(pPath, slice, cSet, memSet)
+		update := cgc.updateCgroupsV1
+		if cgc.cgroupVersion.unified() {
+			update = cgc.updateCgroupsV2
+		}
+		err := update(ctx, pPath, slice, cSet, memSet)
+		if errors.Is(err, os.ErrNotExist) {
+			// the cgroup was removed after the check, eg. by restarted container, and may be created again
+			return newCgroupNotReadyError(cgc.CgroupPath(pPath, c))
 		}
-		return cgc.updateCgroupsV1(pPath, slice, cSet, memSet)
+		return err
 	}
 
-	return DaemonError{
-		ErrorType:    ConfigurationError,
-		ErrorMessage: "Control Plane configured runtime does not match pod runtime",
+	metrics.RuntimeMismatches.Inc()
+	return newRuntimeMismatchError(c.CID, runtimeURLPrefix[cgc.containerRuntime])
+}
+
+// CgroupPath returns the cpuset cgroup directory of the container under given cgroup root.
+func (cgc CgroupControllerImpl) CgroupPath(pPath string, c Container) string {
+	slice := cgc.sliceName(c)
+	if cgc.cgroupVersion.unified() {
+		return path.Join(pPath, slice)
 	}
+	return path.Join(pPath, "cpuset", slice)
 }
 
-func (cgc CgroupControllerImpl) updateCgroupsV1(pPath, slice, cSet, memSet string) error {
+func (cgc CgroupControllerImpl) updateCgroupsV1(ctx context.Context, pPath, slice, cSet, memSet string) error {
 	outputPath := path.Join(pPath, "cpuset", slice)
 	if err := utils.ValidatePathInsideBase(outputPath, pPath); err != nil {
 		return err
 	}
+	cgc.waitForTasks(ctx, outputPath)
+	if err := ctx.Err(); err != nil {
+		return err // cgroup is left untouched if the request is canceled while waiting
+	}
 
-	ctrl := cgroups.NewCpuset(pPath)
-	err := ctrl.Update(slice, &specs.LinuxResources{
-		CPU: &specs.LinuxCPU{
-			Cpus: cSet,
-			Mems: memSet,
-		},
+	return cgc.writeUntilDone(ctx, outputPath, func() error {
+		if err := cgc.injectFault(writeCpuset, outputPath); err != nil {
+			return err
+		}
+		ctrl := cgroups.NewCpuset(pPath)
+		err := ctrl.Update(slice, &specs.LinuxResources{
+			CPU: &specs.LinuxCPU{
+				Cpus: cSet,
+				Mems: memSet,
+			},
+		})
+		// if we set the memory pinning we should enable memory_migrate in cgroups v1
+		if err == nil && memSet != "" {
+			migratePath := path.Join(pPath, "cpuset", slice, "cpuset.memory_migrate")
+			err = cgc.writeFile(writeMemoryMigrate, migratePath, "1")
+		}
+		return err
 	})
-	// if we set the memory pinning we should enable memory_migrate in cgroups v1
-	if err == nil && memSet != "" {
-		migratePath := path.Join(pPath, "cpuset", slice, "cpuset.memory_migrate")
-		err = os.WriteFile(migratePath, []byte("1"), os.FileMode(0))
-	}
-	return err
 }
 
-func (cgc CgroupControllerImpl) updateCgroupsV2(pPath, slice, cSet, memSet string) error {
+func (cgc CgroupControllerImpl) updateCgroupsV2(ctx context.Context, pPath, slice, cSet, memSet string) error {
 	outputPath := path.Join(pPath, slice)
 	if err := utils.ValidatePathInsideBase(outputPath, pPath); err != nil {
 		return err
 	}
+	cgc.waitForTasks(ctx, outputPath)
+	if err := ctx.Err(); err != nil {
+		return err // cgroup is left untouched if the request is canceled while waiting
+	}
+
+	return cgc.writeUntilDone(ctx, outputPath, func() error {
+		if err := cgc.injectFault(writeCpuset, outputPath); err != nil {
+			return err
+		}
+		res := cgroupsv2.Resources{CPU: &cgroupsv2.CPU{Cpus: cSet, Mems: memSet}}
+		_, err := cgroupsv2.NewManager(pPath, slice, &res)
+		// memory migration in cgroups v2 is always enabled, no need to set it as in cgroupsv1
+		return err
+	})
+}
 
-	res := cgroupsv2.Resources{CPU: &cgroupsv2.CPU{Cpus: cSet, Mems: memSet}}
-	_, err := cgroupsv2.NewManager(pPath, slice, &res)
-	// memory migration in cgroups v2 is always enabled, no need to set it as in cgroupsv1
-	return err
+// writeUntilDone runs the cgroup write and waits for it until the context is done. Writes blocked in the
+// kernel cannot be interrupted: such write is abandoned and finishes in the background, so that it does
+// not hold the request, and the daemon state lock, forever.
+func (cgc CgroupControllerImpl) writeUntilDone(ctx context.Context, dir string, write func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- write()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		cgc.logger.Info("cgroup write abandoned", "path", dir, "error", ctx.Err())
+		return ctx.Err()
+	}
 }
@@ -0,0 +1,180 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// builtinAllocators returns constructors of all allocators of the package. Allocators added to the
+// package shall be added here, so that they are checked by testAllocator.
+func builtinAllocators() map[string]func(CgroupController) Allocator {
+	return map[string]func(CgroupController) Allocator{
+		"default": func(m CgroupController) Allocator { return NewDefaultAllocator(m) },
+		"numa":    func(m CgroupController) Allocator { return NewNumaAwareAllocator(m, false) },
+		"scatter": func(m CgroupController) Allocator { return NewScatterAllocator(m, false) },
+		"numa-namespace": func(m CgroupController) Allocator {
+			return NewNumaPerNamespaceAllocator(2, m, false, false, logr.Discard())
+		},
+		"numa-namespace-exclusive": func(m CgroupController) Allocator {
+			return NewNumaPerNamespaceAllocator(2, m, true, false, logr.Discard())
+		},
+	}
+}
+
+func TestBuiltinAllocatorsConformance(t *testing.T) {
+	for name, newAllocator := range builtinAllocators() {
+		t.Run(name, func(t *testing.T) {
+			testAllocator(t, newAllocator)
+		})
+	}
+}
+
+// allocatorSnapshot is the part of the daemon state managed by allocators.
+type allocatorSnapshot struct {
+	available         []ctlplaneapi.CPUBucket
+	allocated         map[string]CPUSet
+	topologyAvailable CPUSet
+}
+
+func takeAllocatorSnapshot(s *DaemonState) allocatorSnapshot {
+	allocated := make(map[string]CPUSet, len(s.Allocated))
+	for cid, buckets := range s.Allocated {
+		allocated[cid] = CPUSetFromBucketList(buckets)
+	}
+	return allocatorSnapshot{
+		available:         append([]ctlplaneapi.CPUBucket{}, s.AvailableCPUs...),
+		allocated:         allocated,
+		topologyAvailable: topologyAvailableCpus(&s.Topology),
+	}
+}
+
+// conformanceContainers returns containers of each QoS class, in the same namespace.
+func conformanceContainers() (Container, Container, Container) {
+	container := func(cid string, cpus int, qs QoS) Container {
+		return Container{CID: cid, PID: "conformancePid", Name: cid, Namespace: "conformance", Cpus: cpus, QS: qs}
+	}
+	return container("guaranteedCid", 2, Guaranteed),
+		container("burstableCid", 1, Burstable),
+		container("bestEffortCid", 0, BestEffort)
+}
+
+// testAllocator checks invariants every allocator shall keep, similarly to fstest.TestFS:
+//   - guaranteed containers get exactly the requested number of cpus, not shared with other guaranteed
+//     containers, and written to their cgroups,
+//   - containers of other QoS classes take no exclusive cpus,
+//   - freeing a container restores the state from before it was taken, for each QoS class,
+//   - failed requests fail with the documented error types and leave the state unchanged,
+//   - clearing a container does not change the state and writes the same cpus every time.
+func testAllocator(t *testing.T, newAllocator func(CgroupController) Allocator) {
+	ctx := context.Background()
+	setup := func(t *testing.T) (Allocator, *DaemonState, *CgroupsMock) {
+		daemonStateFile, tearDown := setupTest()
+		t.Cleanup(func() { tearDown(t) })
+		m := CgroupsMock{}
+		m.On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		s, err := newState("testdata/no_state", "testdata/node_info", daemonStateFile)
+		require.Nil(t, err)
+		return newAllocator(&m), s, &m
+	}
+	lastCpuset := func(t *testing.T, m *CgroupsMock, cid string) CPUSet {
+		require.NotEmpty(t, m.Calls)
+		call := m.Calls[len(m.Calls)-1]
+		require.Equal(t, cid, call.Arguments.Get(1).(Container).CID)
+		cpus, err := CPUSetFromString(call.Arguments.String(2))
+		require.Nil(t, err)
+		return cpus
+	}
+
+	t.Run("guaranteed containers get exclusive cpus", func(t *testing.T) {
+		a, s, m := setup(t)
+		first, _, _ := conformanceContainers()
+		second := first
+		second.CID, second.Name, second.Cpus = "secondCid", "secondCid", 1
+
+		require.Nil(t, a.takeCpus(ctx, first, s))
+		firstCpus := CPUSetFromBucketList(s.Allocated[first.CID])
+		assert.Equal(t, first.Cpus, firstCpus.Count())
+		assert.Equal(t, firstCpus, lastCpuset(t, m, first.CID))
+		require.Nil(t, a.takeCpus(ctx, second, s))
+		secondCpus := CPUSetFromBucketList(s.Allocated[second.CID])
+		assert.Equal(t, second.Cpus, secondCpus.Count())
+		assert.Equal(t, secondCpus, lastCpuset(t, m, second.CID))
+
+		assert.Equal(t, firstCpus, firstCpus.Clone().RemoveAll(secondCpus), "exclusive cpus shall not be shared")
+	})
+
+	t.Run("shared containers take no exclusive cpus", func(t *testing.T) {
+		a, s, _ := setup(t)
+		guaranteed, burstable, bestEffort := conformanceContainers()
+		require.Nil(t, a.takeCpus(ctx, guaranteed, s))
+		before := takeAllocatorSnapshot(s)
+
+		for _, c := range []Container{burstable, bestEffort} {
+			require.Nil(t, a.takeCpus(ctx, c, s), c.QS)
+			after := takeAllocatorSnapshot(s)
+			assert.Equal(t, before.available, after.available, c.QS)
+			assert.Equal(t, before.topologyAvailable, after.topologyAvailable, c.QS)
+		}
+	})
+
+	t.Run("free restores state of take", func(t *testing.T) {
+		a, s, _ := setup(t)
+		guaranteed, burstable, bestEffort := conformanceContainers()
+		for _, c := range []Container{guaranteed, burstable, bestEffort} {
+			before := takeAllocatorSnapshot(s)
+
+			require.Nil(t, a.takeCpus(ctx, c, s), c.QS)
+			require.Nil(t, a.freeCpus(ctx, c, s), c.QS)
+
+			assert.Equal(t, before, takeAllocatorSnapshot(s), c.QS)
+		}
+	})
+
+	t.Run("take of too many cpus fails", func(t *testing.T) {
+		a, s, _ := setup(t)
+		guaranteed, _, _ := conformanceContainers()
+		guaranteed.Cpus = 1024
+		before := takeAllocatorSnapshot(s)
+
+		err := a.takeCpus(ctx, guaranteed, s)
+
+		var daemonErr DaemonError
+		require.ErrorAs(t, err, &daemonErr)
+		assert.Equal(t, CpusNotAvailable, daemonErr.ErrorType)
+		assert.Equal(t, before, takeAllocatorSnapshot(s))
+	})
+
+	t.Run("free of unknown container fails", func(t *testing.T) {
+		a, s, _ := setup(t)
+		guaranteed, _, _ := conformanceContainers()
+		before := takeAllocatorSnapshot(s)
+
+		err := a.freeCpus(ctx, guaranteed, s)
+
+		var daemonErr DaemonError
+		require.ErrorAs(t, err, &daemonErr)
+		assert.Equal(t, ContainerNotFound, daemonErr.ErrorType)
+		assert.Equal(t, before, takeAllocatorSnapshot(s))
+	})
+
+	t.Run("clear is idempotent", func(t *testing.T) {
+		a, s, m := setup(t)
+		guaranteed, _, _ := conformanceContainers()
+		require.Nil(t, a.clearCpus(ctx, guaranteed, s))
+		cleared := lastCpuset(t, m, guaranteed.CID)
+		require.Nil(t, a.takeCpus(ctx, guaranteed, s))
+		before := takeAllocatorSnapshot(s)
+
+		for i := 0; i < 2; i++ {
+			require.Nil(t, a.clearCpus(ctx, guaranteed, s))
+			assert.Equal(t, cleared, lastCpuset(t, m, guaranteed.CID), "clear shall not depend on allocations")
+			assert.Equal(t, before, takeAllocatorSnapshot(s))
+		}
+	})
+}
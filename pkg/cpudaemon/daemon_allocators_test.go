@@ -1,22 +1,31 @@
 package cpudaemon
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 
 	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
 
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type CgroupsMock struct {
 	mock.Mock
 }
 
-func (m *CgroupsMock) UpdateCPUSet(pP string, c Container, cpu string, mem string) error {
+func (m *CgroupsMock) UpdateCPUSet(_ context.Context, pP string, c Container, cpu string, mem string) error {
 	args := m.Called(pP, c, cpu, mem)
 	return args.Error(0)
 }
@@ -28,7 +37,7 @@ func newMockedPolicy(m CgroupController) *DefaultAllocator {
 func takeCPUs(t *testing.T, d *DefaultAllocator, ctrl *CgroupsMock, st *DaemonState, c Container, s int, e int) {
 	ctrl.On("UpdateCPUSet", st.CGroupPath, c, strconv.Itoa(s)+"-"+strconv.Itoa(e), ResourceNotSet).Return(nil)
 	// check no error
-	assert.Nil(t, d.takeCpus(c, st))
+	assert.Nil(t, d.takeCpus(context.Background(), c, st))
 	// check list of allocated containers
 	v, ok := st.Allocated[c.CID]
 	assert.True(t, ok)
@@ -50,7 +59,7 @@ func takeCPUs(t *testing.T, d *DefaultAllocator, ctrl *CgroupsMock, st *DaemonSt
 }
 
 func deleteContainer(t *testing.T, d *DefaultAllocator, st *DaemonState, c Container, nS int) {
-	assert.Nil(t, d.freeCpus(c, st))
+	assert.Nil(t, d.freeCpus(context.Background(), c, st))
 	_, ok := st.Allocated[c.CID]
 	assert.False(t, ok)
 	assert.Equal(t,
@@ -99,7 +108,7 @@ func TestErrorNoCPUsAvailableOnTake(t *testing.T) {
 		Cpus: 129,
 		QS:   Guaranteed,
 	}
-	err = d.takeCpus(c, s)
+	err = d.takeCpus(context.Background(), c, s)
 	assert.Equal(t, DaemonError{
 		ErrorType:    CpusNotAvailable,
 		ErrorMessage: "No available cpus for take request",
@@ -119,11 +128,30 @@ func TestErrorWrongRuntimeConfiguration(t *testing.T) {
 		Cpus: 10,
 		QS:   Guaranteed,
 	}
-	err = d.takeCpus(c, st)
-	assert.Equal(t, DaemonError{
-		ErrorType:    ConfigurationError,
-		ErrorMessage: "Control Plane configured runtime does not match pod runtime",
+	mismatches := testutil.ToFloat64(metrics.RuntimeMismatches)
+	err = d.takeCpus(context.Background(), c, st)
+	assert.Equal(t, RuntimeMismatchError{
+		DaemonError: DaemonError{
+			ErrorType: ConfigurationError,
+			ErrorMessage: "Control Plane configured runtime does not match pod runtime: " +
+				"container id prefix \"containerd://\", expected \"docker://\"",
+		},
+		ContainerIDPrefix: "containerd://",
+		ExpectedPrefix:    "docker://",
 	}, err)
+	assert.Equal(t, mismatches+1, testutil.ToFloat64(metrics.RuntimeMismatches))
+}
+
+func TestRuntimeMismatchErrorStatus(t *testing.T) {
+	err := newRuntimeMismatchError("cid-without-prefix", "containerd://")
+
+	s, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Unavailable, s.Code())
+	info := ctlplaneapi.ErrorInfo(err)
+	require.NotNil(t, info)
+	assert.Equal(t, ctlplaneapi.ReasonRuntimeMismatch, info.Reason)
+	assert.Equal(t, map[string]string{"containerIdPrefix": "", "expectedPrefix": "containerd://"}, info.Metadata)
 }
 func TestTakeAndDeleteContainer(t *testing.T) {
 	daemonStateFile, tearDown := setupTest()
@@ -168,7 +196,24 @@ func TestDefaultAllocatorClearCPU(t *testing.T) {
 	require.Nil(t, err)
 
 	mockCtrl.On("UpdateCPUSet", st.CGroupPath, c, expectedCpuSet.ToCpuString(), ResourceNotSet).Return(nil)
-	assert.Nil(t, d.clearCpus(c, st))
+	assert.Nil(t, d.clearCpus(context.Background(), c, st))
+
+	mockCtrl.AssertExpectations(t)
+}
+
+func TestDefaultAllocatorClearCPUExcludesReservedCpus(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	mockCtrl := CgroupsMock{}
+	st, err := newState("testdata/no_state", "testdata/node_info", daemonStateFile, WithReservedCpus(CPUSet{0: {}, 1: {}}))
+	require.Nil(t, err)
+	d := newMockedPolicy(&mockCtrl)
+	c := Container{PID: "test_pod_id1", CID: "test_container_iud1", QS: BestEffort}
+	expectedCpuSet, err := CPUSetFromString("2-127")
+	require.Nil(t, err)
+
+	mockCtrl.On("UpdateCPUSet", st.CGroupPath, c, expectedCpuSet.ToCpuString(), ResourceNotSet).Return(nil)
+	assert.Nil(t, d.clearCpus(context.Background(), c, st))
 
 	mockCtrl.AssertExpectations(t)
 }
@@ -190,3 +235,178 @@ func TestSliceNameCgroupfs(t *testing.T) {
 	expectedSlice := "/kubepods/burstable/podpid-01/cid"
 	assert.Equal(t, expectedSlice, SliceName(container, Docker, DriverCgroupfs))
 }
+
+type ResolvingCgroupsMock struct {
+	CgroupsMock
+}
+
+func (m *ResolvingCgroupsMock) CgroupPath(pP string, c Container) string {
+	return pP + "/" + c.CID
+}
+
+func TestCgroupPath(t *testing.T) {
+	container := Container{CID: "docker://cid", PID: "pid-01", QS: Burstable}
+	v2 := NewCgroupController(Docker, DriverCgroupfs, logr.Discard(), WithControllerCgroupVersion(CgroupV2))
+	assert.Equal(t, "/sys/fs/cgroup/kubepods/burstable/podpid-01/cid", v2.CgroupPath("/sys/fs/cgroup", container))
+	v1 := NewCgroupController(Docker, DriverCgroupfs, logr.Discard(), WithControllerCgroupVersion(CgroupV1))
+	assert.Equal(t, "/sys/fs/cgroup/cpuset/kubepods/burstable/podpid-01/cid", v1.CgroupPath("/sys/fs/cgroup", container))
+}
+
+func TestUpdateContainerCPUSetRecordsCgroupPath(t *testing.T) {
+	s := &DaemonState{CGroupPath: "/cgroup", Pods: map[string]PodMetadata{}}
+	c := Container{CID: "cid", PID: "pid", Cpus: 2, QS: Burstable}
+	m := ResolvingCgroupsMock{}
+	m.On("UpdateCPUSet", s.CGroupPath, c, "0-1", ResourceNotSet).Return(fmt.Errorf("failure")).Once() //nolint
+	m.On("UpdateCPUSet", s.CGroupPath, c, "0-1", ResourceNotSet).Return(nil).Once()
+
+	assert.NotNil(t, updateContainerCPUSet(context.Background(), &m, s, c, "0-1", ResourceNotSet))
+	assert.Empty(t, s.getCgroupPath(c.CID))
+
+	require.Nil(t, updateContainerCPUSet(context.Background(), &m, s, c, "0-1", ResourceNotSet))
+	assert.Equal(t, "/cgroup/cid", s.getCgroupPath(c.CID))
+}
+
+// BenchmarkUpdateCPUSet measures cpuset updates of containers of churning pods, with cgroup version
+// resolved once by the controller.
+func BenchmarkUpdateCPUSet(b *testing.B) {
+	const pods = 100
+	for _, version := range []CgroupVersion{CgroupV1, CgroupV2} {
+		b.Run(version.String(), func(b *testing.B) {
+			dir := b.TempDir()
+			ctrl := NewCgroupController(ContainerdRunc, DriverSystemd, logr.Discard(), WithControllerCgroupVersion(version))
+			containers := make([]Container, 0, pods)
+			for i := 0; i < pods; i++ {
+				c := Container{CID: fmt.Sprintf("containerd://c%d", i), PID: fmt.Sprintf("p%d", i), QS: Guaranteed}
+				cgroup := ctrl.CgroupPath(dir, c)
+				require.Nil(b, os.MkdirAll(cgroup, 0o755))
+				require.Nil(b, os.WriteFile(filepath.Join(cgroup, "cgroup.procs"), []byte("1\n"), 0o600))
+				for parent := filepath.Dir(cgroup); strings.HasPrefix(parent, dir); parent = filepath.Dir(parent) {
+					require.Nil(b, os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte{}, 0o600))
+				}
+				containers = append(containers, c)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				require.Nil(b, ctrl.UpdateCPUSet(context.Background(), dir, containers[i%pods], strconv.Itoa(i%8), ""))
+			}
+		})
+	}
+}
+
+// BenchmarkCgroupVersion compares cgroup version resolved by the controller with statfs based detection,
+// which would otherwise run on every cpuset update.
+func BenchmarkCgroupVersion(b *testing.B) {
+	b.Run("statfs", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = cgroupFsVersion("/sys/fs/cgroup")
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		ctrl := NewCgroupController(ContainerdRunc, DriverSystemd, logr.Discard())
+		for i := 0; i < b.N; i++ {
+			_ = ctrl.cgroupVersion.unified()
+		}
+	})
+}
+
+func TestUpdateCPUSetOfCanceledRequest(t *testing.T) {
+	dir := t.TempDir()
+	c := Container{CID: "containerd://cid", PID: "pid", QS: Guaranteed}
+	ctrl := NewCgroupController(ContainerdRunc, DriverSystemd, logr.Discard(), WithControllerCgroupVersion(CgroupV2))
+	require.Nil(t, os.MkdirAll(ctrl.CgroupPath(dir, c), 0o755))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := ctrl.UpdateCPUSet(ctx, dir, c, "1", "")
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoFileExists(t, filepath.Join(ctrl.CgroupPath(dir, c), "cpuset.cpus"))
+}
+
+// createZeroCpusPodRequest returns request of a pod in namespace of createTestPod, with best effort
+// container and burstable container limiting cpus only.
+func createZeroCpusPodRequest() *ctlplaneapi.CreatePodRequest {
+	bestEffort := ctlplaneapi.ResourceInfo{
+		RequestedMemory: newQuantityAsBytes(0),
+		LimitMemory:     newQuantityAsBytes(0),
+	}
+	burstable := ctlplaneapi.ResourceInfo{
+		LimitCpus:       2,
+		RequestedMemory: newQuantityAsBytes(0),
+		LimitMemory:     newQuantityAsBytes(0),
+	}
+	return &ctlplaneapi.CreatePodRequest{
+		PodId:        "zeroPid",
+		PodName:      "zeroPid",
+		PodNamespace: "testPid",
+		Resources:    &burstable,
+		Containers: []*ctlplaneapi.ContainerInfo{
+			{ContainerId: "bestEffortCid", ContainerName: "bestEffortCid", Resources: &bestEffort},
+			{ContainerId: "burstableCid", ContainerName: "burstableCid", Resources: &burstable},
+		},
+	}
+}
+
+func TestZeroCpusContainersRunInSharedPool(t *testing.T) {
+	for name, newAllocator := range builtinAllocators() {
+		t.Run(name, func(t *testing.T) {
+			daemonStateFile, tearDown := setupTest()
+			defer tearDown(t)
+			m := CgroupsMock{}
+			m.On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			d, err := New(
+				"testdata/no_state", "testdata/node_info", daemonStateFile,
+				NewStaticPolocy(newAllocator(&m)), logr.Discard(),
+			)
+			require.Nil(t, err)
+			_, err = d.CreatePod(context.Background(), createPodRequest(createTestPod(1)))
+			require.Nil(t, err)
+			exclusive := CPUSetFromBucketList(d.state.Allocated["testCid-0"])
+			available := append([]ctlplaneapi.CPUBucket{}, d.state.AvailableCPUs...)
+			topologyAvailable := topologyAvailableCpus(&d.state.Topology)
+
+			reply, err := d.CreatePod(context.Background(), createZeroCpusPodRequest())
+
+			require.Nil(t, err)
+			require.Len(t, reply.ContainerResources, 2)
+			for _, r := range reply.ContainerResources {
+				assert.False(t, r.Exclusive, r.ContainerID)
+				if name == "numa-namespace" {
+					continue // shared containers run on the whole bucket in non-exclusive mode
+				}
+				shared := CPUSetFromBucketList(d.state.Allocated[r.ContainerID])
+				assert.Equal(t, shared, shared.Clone().RemoveAll(exclusive), "%s runs on exclusive cpus", r.ContainerID)
+			}
+			assert.Equal(t, available, d.state.AvailableCPUs)
+			assert.Equal(t, topologyAvailable, topologyAvailableCpus(&d.state.Topology))
+
+			_, err = d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: "zeroPid"})
+
+			require.Nil(t, err)
+			assert.Equal(t, available, d.state.AvailableCPUs)
+			assert.Equal(t, topologyAvailable, topologyAvailableCpus(&d.state.Topology))
+			assert.Equal(t, exclusive, CPUSetFromBucketList(d.state.Allocated["testCid-0"]))
+		})
+	}
+}
+
+func TestGuaranteedContainerWithoutCpusGetsNoExclusiveCpus(t *testing.T) {
+	m := CgroupsMock{}
+	m.On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	c := Container{CID: "cid", PID: "pid", Name: "cid", Namespace: "ns", QS: Guaranteed}
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	for name, newAllocator := range builtinAllocators() {
+		a := newAllocator(&m)
+		s, err := newState("testdata/no_state", "testdata/node_info", daemonStateFile)
+		require.Nil(t, err, name)
+		available := append([]ctlplaneapi.CPUBucket{}, s.AvailableCPUs...)
+		topologyAvailable := topologyAvailableCpus(&s.Topology)
+
+		require.Nil(t, a.takeCpus(context.Background(), c, s), name)
+		assert.Equal(t, available, s.AvailableCPUs, name)
+		assert.Equal(t, topologyAvailable, topologyAvailableCpus(&s.Topology), name)
+		require.Nil(t, a.freeCpus(context.Background(), c, s), name)
+		assert.Equal(t, topologyAvailable, topologyAvailableCpus(&s.Topology), name)
+	}
+}
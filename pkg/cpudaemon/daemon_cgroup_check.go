@@ -0,0 +1,52 @@
+package cpudaemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// cgroupWriteCheckName is the name of the cgroup created and removed by the startup write check.
+const cgroupWriteCheckName = "ctlplane-write-check"
+
+// checkCgroupWritable verifies that the daemon can modify cgroups by creating and removing a probe
+// cgroup in the kubepods cgroup (or the root cgroup if kubepods cannot be found). It detects read-only
+// cgroup mounts and missing privileges at startup, instead of failing each allocation later.
+func checkCgroupWritable(cgroupPath string, version CgroupVersion) error {
+	parent, _ := version.cpusetPaths(cgroupPath)
+	for _, kubepods := range kubepodsCgroups {
+		if info, err := os.Stat(filepath.Join(parent, kubepods)); err == nil && info.IsDir() {
+			parent = filepath.Join(parent, kubepods)
+			break
+		}
+	}
+
+	probe := filepath.Join(parent, cgroupWriteCheckName)
+	err := os.Mkdir(probe, 0o755)
+	if err == nil || errors.Is(err, os.ErrExist) {
+		err = os.Remove(probe)
+	}
+	if err != nil {
+		return cgroupWriteError(parent, err)
+	}
+	return nil
+}
+
+// cgroupWriteError returns configuration error with remediation of the failed cgroup write.
+func cgroupWriteError(path string, err error) error {
+	var remediation string
+	switch {
+	case errors.Is(err, syscall.EROFS):
+		remediation = "cgroup filesystem is mounted read-only, mount host /sys/fs/cgroup read-write into the daemon container"
+	case errors.Is(err, syscall.EPERM), errors.Is(err, syscall.EACCES):
+		remediation = "missing privileges to modify cgroups, run the daemon container privileged"
+	default:
+		remediation = "check -cpath option and cgroup mounts of the daemon container"
+	}
+	return DaemonError{
+		ErrorType:    ConfigurationError,
+		ErrorMessage: fmt.Sprintf("cannot write cgroups in %s: %s (%s)", path, remediation, err.Error()),
+	}
+}
@@ -0,0 +1,97 @@
+package cpudaemon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCgroupFsForTest returns directory with kubepods cgroup for both cgroups v1 and v2 layouts.
+func newCgroupFsForTest(t *testing.T) string {
+	dir := t.TempDir()
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, "kubepods.slice"), 0o755))
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, "cpuset", "kubepods.slice"), 0o755))
+	return dir
+}
+
+func probeExists(dir string) bool {
+	for _, parent := range []string{dir, filepath.Join(dir, "cpuset")} {
+		if _, err := os.Stat(filepath.Join(parent, "kubepods.slice", cgroupWriteCheckName)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheckCgroupWritable(t *testing.T) {
+	for _, version := range []CgroupVersion{CgroupV1, CgroupV2} {
+		dir := newCgroupFsForTest(t)
+
+		assert.Nil(t, checkCgroupWritable(dir, version))
+		assert.False(t, probeExists(dir))
+	}
+}
+
+func TestCheckCgroupWritableRemovesLeftoverProbe(t *testing.T) {
+	dir := newCgroupFsForTest(t)
+	require.Nil(t, os.Mkdir(filepath.Join(dir, "kubepods.slice", cgroupWriteCheckName), 0o755))
+	require.Nil(t, os.Mkdir(filepath.Join(dir, "cpuset", "kubepods.slice", cgroupWriteCheckName), 0o755))
+
+	assert.Nil(t, checkCgroupWritable(dir, CgroupV1))
+	assert.Nil(t, checkCgroupWritable(dir, CgroupV2))
+}
+
+func TestCheckCgroupWritableFailsOnMissingCgroup(t *testing.T) {
+	err := checkCgroupWritable(filepath.Join(t.TempDir(), "missing", "cgroup"), CgroupV2)
+
+	var dErr DaemonError
+	require.ErrorAs(t, err, &dErr)
+	assert.Equal(t, ConfigurationError, dErr.ErrorType)
+	assert.Contains(t, dErr.ErrorMessage, "-cpath")
+}
+
+func TestCgroupWriteError(t *testing.T) {
+	tc := []struct {
+		err         error
+		remediation string
+	}{
+		{syscall.EROFS, "read-only"},
+		{syscall.EPERM, "privileged"},
+		{syscall.EACCES, "privileged"},
+		{errors.New("other"), "-cpath"},
+	}
+	for _, tt := range tc {
+		err := cgroupWriteError("/sys/fs/cgroup", &os.PathError{Op: "mkdir", Path: "probe", Err: tt.err})
+
+		var dErr DaemonError
+		require.ErrorAs(t, err, &dErr)
+		assert.Equal(t, ConfigurationError, dErr.ErrorType)
+		assert.Contains(t, dErr.ErrorMessage, tt.remediation)
+		assert.Contains(t, dErr.ErrorMessage, tt.err.Error())
+	}
+}
+
+func TestNewWithCgroupWriteCheck(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+
+	d, err := New(
+		"testdata/no_state",
+		"testdata/node_info",
+		daemonStateFile,
+		&MockedPolicy{},
+		logr.Discard(),
+		WithCgroupWriteCheck(),
+	)
+
+	require.Nil(t, err)
+	assert.NotNil(t, d)
+	assert.NoDirExists(t, "testdata/no_state/"+cgroupWriteCheckName)
+	assert.NoDirExists(t, "testdata/no_state/cpuset/"+cgroupWriteCheckName)
+}
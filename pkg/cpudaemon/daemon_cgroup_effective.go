@@ -0,0 +1,60 @@
+package cpudaemon
+
+import (
+	"path"
+
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+// CPUSetVerifier is implemented by cgroup controllers able to read back cpus the container cgroup
+// effectively runs on after the cpuset update. They may differ from the written cpus, eg. when cpuset
+// of a parent cgroup silently narrows them.
+type CPUSetVerifier interface {
+	VerifyCPUSet(path string, c Container, cpuSet string) string
+}
+
+var _ CPUSetVerifier = CgroupControllerImpl{}
+
+// VerifyCPUSet reads cpuset.cpus.effective (cpuset.effective_cpus on cgroups v1) of the container cgroup
+// and returns it. Mismatches with the written cpuSet are logged and counted. Empty string is returned
+// if the effective cpus cannot be read.
+func (cgc CgroupControllerImpl) VerifyCPUSet(pPath string, c Container, cpuSet string) string {
+	file := path.Join(cgc.CgroupPath(pPath, c), "cpuset.cpus.effective")
+	if !cgc.cgroupVersion.unified() {
+		file = path.Join(cgc.CgroupPath(pPath, c), "cpuset.effective_cpus")
+	}
+	effective, err := LoadCpuSet(file)
+	if err != nil {
+		cgc.logger.Error(err, "cannot read back effective cpuset", "path", file)
+		return ""
+	}
+	res := CPUSetFromBucketList(effective).ToCpuString()
+	if expected, err := CPUSetFromString(cpuSet); err == nil && expected.ToCpuString() != res {
+		metrics.EffectiveCpusetMismatches.Inc()
+		cgc.logger.Info(
+			"effective cpuset differs from the written one",
+			"cid", c.CID,
+			"path", file,
+			"cpus", cpuSet,
+			"effective", res,
+		)
+	}
+	return res
+}
+
+// setEffectiveCPUs records cpus read back from the container cgroup, nothing if they were not read.
+func (d *DaemonState) setEffectiveCPUs(cid string, cpus string) {
+	if cpus == "" {
+		delete(d.EffectiveCPUs, cid)
+		return
+	}
+	if d.EffectiveCPUs == nil {
+		d.EffectiveCPUs = make(map[string]string)
+	}
+	d.EffectiveCPUs[cid] = cpus
+}
+
+// getEffectiveCPUs returns cpus read back from the container cgroup, empty string if not verified.
+func (d *DaemonState) getEffectiveCPUs(cid string) string {
+	return d.EffectiveCPUs[cid]
+}
@@ -0,0 +1,84 @@
+package cpudaemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+func writeEffectiveCpuset(t *testing.T, ctrl CgroupControllerImpl, dir string, c Container, file, cpus string) {
+	cgroup := ctrl.CgroupPath(dir, c)
+	require.Nil(t, os.MkdirAll(cgroup, 0o755))
+	require.Nil(t, os.WriteFile(filepath.Join(cgroup, file), []byte(cpus+"\n"), 0o644))
+}
+
+func TestVerifyCPUSet(t *testing.T) {
+	c := Container{CID: "containerd://cid", PID: "pid", QS: Guaranteed}
+	tc := []struct {
+		version CgroupVersion
+		file    string
+	}{
+		{CgroupV2, "cpuset.cpus.effective"},
+		{CgroupV1, "cpuset.effective_cpus"},
+	}
+	for _, tt := range tc {
+		dir := t.TempDir()
+		ctrl := NewCgroupController(ContainerdRunc, DriverSystemd, logr.Discard(), WithControllerCgroupVersion(tt.version))
+		writeEffectiveCpuset(t, ctrl, dir, c, tt.file, "2-3")
+
+		before := testutil.ToFloat64(metrics.EffectiveCpusetMismatches)
+		assert.Equal(t, "2,3", ctrl.VerifyCPUSet(dir, c, "2,3"), tt.version)
+		assert.Equal(t, before, testutil.ToFloat64(metrics.EffectiveCpusetMismatches), tt.version)
+
+		assert.Equal(t, "2,3", ctrl.VerifyCPUSet(dir, c, "1-3"), tt.version)
+		assert.Equal(t, before+1, testutil.ToFloat64(metrics.EffectiveCpusetMismatches), tt.version)
+	}
+}
+
+func TestVerifyCPUSetOfMissingCgroup(t *testing.T) {
+	ctrl := NewCgroupController(ContainerdRunc, DriverSystemd, logr.Discard(), WithControllerCgroupVersion(CgroupV2))
+
+	assert.Equal(t, "", ctrl.VerifyCPUSet(t.TempDir(), Container{CID: "containerd://cid", PID: "pid"}, "1"))
+}
+
+// effectiveCgroups writes cpusets of containers and reports them narrowed to the cpus of the parent.
+type effectiveCgroups struct {
+	fileCgroups
+	parent CPUSet
+}
+
+func (e effectiveCgroups) VerifyCPUSet(_ string, _ Container, cpuSet string) string {
+	cpus, _ := CPUSetFromString(cpuSet)
+	effective := CPUSet{}
+	for cpu := range cpus {
+		if e.parent.Contains(cpu) {
+			effective.Add(cpu)
+		}
+	}
+	return effective.ToCpuString()
+}
+
+func TestUpdateContainerCPUSetRecordsEffectiveCpus(t *testing.T) {
+	d, c := newDaemonForReconcileTest(t)
+	parent, err := CPUSetFromString("0-2")
+	require.Nil(t, err)
+	ctrl := effectiveCgroups{parent: parent}
+
+	require.Nil(t, updateContainerCPUSet(context.Background(), ctrl, &d.state, c, "2-3", ResourceNotSet))
+	assert.Equal(t, "2", d.state.getEffectiveCPUs(c.CID))
+	assert.Equal(t, "2", d.state.allocatedContainerResource(c).EffectiveCPUSet)
+
+	// controllers unable to verify cpusets leave the recorded cpus untouched
+	require.Nil(t, updateContainerCPUSet(context.Background(), fileCgroups{}, &d.state, c, "2-3", ResourceNotSet))
+	assert.Equal(t, "2", d.state.getEffectiveCPUs(c.CID))
+
+	d.state.clearCgroupPath(c.CID)
+	assert.Equal(t, "", d.state.getEffectiveCPUs(c.CID))
+}
@@ -0,0 +1,39 @@
+package cpudaemon
+
+import (
+	"os"
+)
+
+// cgroupWriteStep names a write of the cgroup controller at which faults can be injected.
+type cgroupWriteStep string
+
+const (
+	writeCpuset        cgroupWriteStep = "cpuset"         // cpus and memory nodes of the container cgroup
+	writeMemoryMigrate cgroupWriteStep = "memory_migrate" // memory migration of the container cgroup on v1
+	writePartition     cgroupWriteStep = "partition"      // cpuset partition type of the container cgroup
+	writeCPUWeight     cgroupWriteStep = "cpu_weight"     // cpu.weight, or cpu.shares on v1
+)
+
+// cgroupFaultHook is called by the cgroup controller before each write step with the written file, or
+// the cgroup directory for cpuset writes done by the cgroups library. Error returned by the hook fails
+// the step as if the write failed, eg. with EBUSY, ENOENT or EPERM. The hook is set only by tests, so that
+// rollback and retry paths of the daemon run against the real controller.
+type cgroupFaultHook interface {
+	beforeWrite(step cgroupWriteStep, path string) error
+}
+
+// injectFault returns the fault injected at the write step, nil if there is no hook.
+func (cgc CgroupControllerImpl) injectFault(step cgroupWriteStep, path string) error {
+	if cgc.faults == nil {
+		return nil
+	}
+	return cgc.faults.beforeWrite(step, path)
+}
+
+// writeFile writes the cgroup file unless a fault is injected at the write step.
+func (cgc CgroupControllerImpl) writeFile(step cgroupWriteStep, file string, data string) error {
+	if err := cgc.injectFault(step, file); err != nil {
+		return err
+	}
+	return os.WriteFile(file, []byte(data), os.FileMode(0))
+}
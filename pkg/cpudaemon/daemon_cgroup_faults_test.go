@@ -0,0 +1,283 @@
+package cpudaemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+// injectedFaults fails write steps of the cgroup controller with queued errnos, each errno fails one write
+// of its step. Other writes succeed.
+type injectedFaults struct {
+	mu     sync.Mutex
+	faults map[cgroupWriteStep][]syscall.Errno
+	writes map[cgroupWriteStep]int // number of attempted writes of each step, including failed ones
+}
+
+func newInjectedFaults() *injectedFaults {
+	return &injectedFaults{faults: map[cgroupWriteStep][]syscall.Errno{}, writes: map[cgroupWriteStep]int{}}
+}
+
+// fail queues errnos failing the next writes of the step, zero errno lets the write pass.
+func (f *injectedFaults) fail(step cgroupWriteStep, errnos ...syscall.Errno) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults[step] = append(f.faults[step], errnos...)
+}
+
+func (f *injectedFaults) attempted(step cgroupWriteStep) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.writes[step]
+}
+
+func (f *injectedFaults) beforeWrite(step cgroupWriteStep, path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.writes[step]++
+	if len(f.faults[step]) == 0 {
+		return nil
+	}
+	errno := f.faults[step][0]
+	f.faults[step] = f.faults[step][1:]
+	if errno == 0 {
+		return nil
+	}
+	return &os.PathError{Op: "write", Path: path, Err: errno}
+}
+
+// newControllerWithFaults returns the controller writing cgroups of given version, with faults injected by
+// the returned hook.
+func newControllerWithFaults(version CgroupVersion, opts ...CgroupOption) (CgroupControllerImpl, *injectedFaults) {
+	faults := newInjectedFaults()
+	opts = append(opts, WithControllerCgroupVersion(version))
+	ctrl := NewCgroupController(ContainerdRunc, DriverSystemd, logr.Discard(), opts...)
+	ctrl.faults = faults
+	ctrl.emptyCgroupWait = 0
+	return ctrl, faults
+}
+
+// createContainerCgroups creates cgroups of containers under dir, with a live task and controllers enabled in
+// parents as required by the cgroups library.
+func createContainerCgroups(t *testing.T, ctrl CgroupControllerImpl, dir string, containers ...Container) {
+	for _, c := range containers {
+		cgroup := ctrl.CgroupPath(dir, c)
+		require.Nil(t, os.MkdirAll(cgroup, 0o755))
+		require.Nil(t, os.WriteFile(filepath.Join(cgroup, "cgroup.procs"), []byte("1\n"), 0o600))
+		for parent := filepath.Dir(cgroup); strings.HasPrefix(parent, dir); parent = filepath.Dir(parent) {
+			require.Nil(t, os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte{}, 0o600))
+		}
+	}
+}
+
+// containerdPod returns test pod with n containers of containerd runtime.
+func containerdPod(n int) (PodMetaData, *ctlplaneapi.CreatePodRequest) {
+	p := createTestPod(n)
+	for i := range p.containers {
+		p.containers[i].CID = "containerd://" + p.containers[i].CID
+		p.containersResources[i].ContainerId = p.containers[i].CID
+	}
+	return p, createPodRequest(p)
+}
+
+// newDaemonWithFaults returns daemon writing cgroups of pod containers in a temporary directory with the
+// controller injecting faults.
+func newDaemonWithFaults(t *testing.T, p PodMetaData, opts ...Option) (*Daemon, *injectedFaults) {
+	dir := t.TempDir()
+	ctrl, faults := newControllerWithFaults(CgroupV2)
+	createContainerCgroups(t, ctrl, dir, p.containers...)
+	d := newTestDaemon(t, NewStaticPolocy(NewDefaultAllocator(ctrl)), opts...)
+	d.state.CGroupPath = dir
+	return d, faults
+}
+
+// readCgroupCpus returns cpus written to the cgroup of the container.
+func readCgroupCpus(t *testing.T, d *Daemon, c Container) string {
+	b, err := os.ReadFile(filepath.Join(d.state.getCgroupPath(c.CID), "cpuset.cpus"))
+	require.Nil(t, err)
+	cpus, err := CPUSetFromString(strings.TrimSpace(string(b)))
+	require.Nil(t, err)
+	return cpus.ToCpuString()
+}
+
+func TestInjectedFaultFailsCgroupWrite(t *testing.T) {
+	c := Container{CID: "containerd://cid", PID: "pid", QS: Guaranteed}
+	for _, version := range []CgroupVersion{CgroupV1, CgroupV2} {
+		dir := t.TempDir()
+		ctrl, faults := newControllerWithFaults(version)
+		createContainerCgroups(t, ctrl, dir, c)
+		faults.fail(writeCpuset, syscall.EPERM)
+
+		err := ctrl.UpdateCPUSet(context.Background(), dir, c, "1", "")
+
+		assert.ErrorIs(t, err, syscall.EPERM, version)
+		assert.False(t, isRetryable(err), version)
+		assert.Nil(t, ctrl.UpdateCPUSet(context.Background(), dir, c, "1", ""), version)
+		assert.Equal(t, 2, faults.attempted(writeCpuset), version)
+	}
+}
+
+func TestInjectedFaultOfMemoryMigrationFailsCgroupWrite(t *testing.T) {
+	dir := t.TempDir()
+	c := Container{CID: "containerd://cid", PID: "pid", QS: Guaranteed}
+	ctrl, faults := newControllerWithFaults(CgroupV1)
+	createContainerCgroups(t, ctrl, dir, c)
+	faults.fail(writeMemoryMigrate, syscall.EBUSY)
+
+	err := ctrl.UpdateCPUSet(context.Background(), dir, c, "1", "0")
+
+	assert.ErrorIs(t, err, syscall.EBUSY)
+	assert.Equal(t, 1, faults.attempted(writeCpuset))
+}
+
+func TestMissingCgroupFileIsReportedAsNotReady(t *testing.T) {
+	dir := t.TempDir()
+	c := Container{CID: "containerd://cid", PID: "pid", QS: Guaranteed}
+	ctrl, faults := newControllerWithFaults(CgroupV2)
+	createContainerCgroups(t, ctrl, dir, c)
+	faults.fail(writeCpuset, syscall.ENOENT)
+
+	err := ctrl.UpdateCPUSet(context.Background(), dir, c, "1", "")
+
+	var notReady CgroupNotReadyError
+	require.ErrorAs(t, err, &notReady)
+	assert.Equal(t, ctrl.CgroupPath(dir, c), notReady.Path)
+	assert.True(t, isRetryable(err))
+}
+
+func TestCgroupWriteFailureRollsBackPodCreation(t *testing.T) {
+	p, req := containerdPod(2)
+	d, faults := newDaemonWithFaults(t, p)
+	available := append([]ctlplaneapi.CPUBucket{}, d.state.AvailableCPUs...)
+	// containers are allocated from the biggest one, the second one fails
+	faults.fail(writeCpuset, 0, syscall.EBUSY)
+
+	reply, err := d.CreatePod(context.Background(), req)
+
+	require.NotNil(t, err)
+	assert.ErrorIs(t, err, syscall.EBUSY)
+	require.NotNil(t, reply)
+	assert.Equal(t, []ctlplaneapi.ContainerResult{
+		{ContainerID: p.containers[0].CID, Status: ctlplaneapi.ContainerStatus_FAILED, Reason: reply.ContainerResults[0].Reason},
+		{ContainerID: p.containers[1].CID, Status: ctlplaneapi.ContainerStatus_SKIPPED, Reason: resultRolledBack},
+	}, reply.ContainerResults)
+	assert.Contains(t, reply.ContainerResults[0].Reason, "device or resource busy")
+	assert.Empty(t, d.state.Allocated)
+	assert.Equal(t, available, d.state.AvailableCPUs)
+	assert.NotContains(t, d.state.Pods, p.pid)
+}
+
+func TestCgroupWriteFailureOfUpdatedContainerIsReported(t *testing.T) {
+	p, req := containerdPod(2)
+	d, faults := newDaemonWithFaults(t, p)
+	_, err := d.CreatePod(context.Background(), &ctlplaneapi.CreatePodRequest{
+		PodId:        req.PodId,
+		PodName:      req.PodName,
+		PodNamespace: req.PodNamespace,
+		Resources:    req.Resources,
+		Containers:   req.Containers[:1],
+	})
+	require.Nil(t, err)
+	cpus := readCgroupCpus(t, d, p.containers[0])
+	available := append([]ctlplaneapi.CPUBucket{}, d.state.AvailableCPUs...)
+	faults.fail(writeCpuset, syscall.EPERM)
+
+	reply, err := d.UpdatePod(context.Background(), &ctlplaneapi.UpdatePodRequest{
+		PodId:      req.PodId,
+		Resources:  req.Resources,
+		Containers: req.Containers,
+	})
+
+	require.NotNil(t, err)
+	require.NotNil(t, reply)
+	assert.Equal(t, []ctlplaneapi.ContainerStatus{
+		ctlplaneapi.ContainerStatus_SKIPPED,
+		ctlplaneapi.ContainerStatus_FAILED,
+	}, statuses(reply.ContainerResults))
+	assert.Equal(t, p.containers[1].CID, reply.ContainerResults[1].ContainerID)
+	assert.Contains(t, reply.ContainerResults[1].Reason, "operation not permitted")
+	assert.Equal(t, cpus, readCgroupCpus(t, d, p.containers[0]), "running container shall keep its cpus")
+	assert.NotContains(t, d.state.Allocated, p.containers[1].CID, "cpus of the failed container shall be freed")
+	assert.Equal(t, available, d.state.AvailableCPUs)
+}
+
+func TestVanishedCgroupIsRetried(t *testing.T) {
+	p, req := containerdPod(1)
+	d, faults := newDaemonWithFaults(t, p, WithCgroupRetry(time.Hour, 3))
+	deferred := testutil.ToFloat64(metrics.DeferredCgroupUpdates)
+	faults.fail(writeCpuset, syscall.ENOENT)
+
+	_, err := d.CreatePod(context.Background(), req)
+
+	require.Nil(t, err)
+	require.Contains(t, d.state.deferredCgroupUpdates, p.containers[0].CID)
+	assert.Equal(t, deferred+1, testutil.ToFloat64(metrics.DeferredCgroupUpdates))
+	assert.Contains(t, d.state.Allocated, p.containers[0].CID)
+
+	d.retryCgroupUpdates()
+
+	assert.Empty(t, d.state.deferredCgroupUpdates)
+	assert.Equal(t, 2, faults.attempted(writeCpuset))
+	assert.Equal(t, CPUSetFromBucketList(d.state.Allocated[p.containers[0].CID]).ToCpuString(), readCgroupCpus(t, d, p.containers[0]))
+}
+
+func TestRepeatedlyVanishedCgroupIsGivenUp(t *testing.T) {
+	p, req := containerdPod(1)
+	d, faults := newDaemonWithFaults(t, p, WithCgroupRetry(time.Hour, 2))
+	exhausted := testutil.ToFloat64(metrics.ExhaustedCgroupUpdates)
+	faults.fail(writeCpuset, syscall.ENOENT, syscall.ENOENT, syscall.ENOENT)
+
+	_, err := d.CreatePod(context.Background(), req)
+	require.Nil(t, err)
+	d.retryCgroupUpdates()
+	d.retryCgroupUpdates()
+
+	assert.Empty(t, d.state.deferredCgroupUpdates)
+	assert.Equal(t, exhausted+1, testutil.ToFloat64(metrics.ExhaustedCgroupUpdates))
+	assert.Contains(t, d.state.Allocated, p.containers[0].CID, "the container keeps its allocation")
+}
+
+func TestRejectedPartitionFallsBackToMember(t *testing.T) {
+	dir := t.TempDir()
+	c := Container{CID: "containerd://cid", PID: "pid", QS: Guaranteed}
+	ctrl, faults := newControllerWithFaults(CgroupV2, WithCpusetPartitions())
+	createPartitionCgroups(t, ctrl, dir, c)
+	fallbacks := testutil.ToFloat64(metrics.CpusetPartitionFallbacks)
+	faults.fail(writePartition, syscall.EBUSY)
+
+	ctrl.SetPartition(dir, c, true)
+
+	partition, err := readPartition(ctrl.CgroupPath(dir, c))
+	require.Nil(t, err)
+	assert.Equal(t, partitionMember, partition)
+	assert.Equal(t, 2, faults.attempted(writePartition))
+	assert.Equal(t, fallbacks+1, testutil.ToFloat64(metrics.CpusetPartitionFallbacks))
+}
+
+func TestFailedCPUWeightWriteKeepsWeight(t *testing.T) {
+	dir := t.TempDir()
+	c := Container{CID: "containerd://cid", PID: "pid", QS: Burstable}
+	ctrl, faults := newControllerWithFaults(CgroupV2)
+	createContainerCgroups(t, ctrl, dir, c)
+	weight := filepath.Join(ctrl.CgroupPath(dir, c), "cpu.weight")
+	require.Nil(t, os.WriteFile(weight, []byte("100"), 0o600))
+	faults.fail(writeCPUWeight, syscall.EPERM)
+
+	ctrl.SetCPUWeight(dir, c, 300)
+
+	b, err := os.ReadFile(weight)
+	require.Nil(t, err)
+	assert.Equal(t, "100", string(b))
+}
@@ -0,0 +1,239 @@
+package cpudaemon
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"resourcemanagement.controlplane/pkg/metrics"
+	"resourcemanagement.controlplane/pkg/utils"
+)
+
+const (
+	partitionFile   = "cpuset.cpus.partition"
+	partitionRoot   = "root"
+	partitionMember = "member"
+)
+
+// CgroupOption configures optional behaviour of the cgroup controller.
+type CgroupOption func(*CgroupControllerImpl)
+
+// WithCpusetPartitions makes cgroups of containers with exclusive cpus cpuset partition roots on
+// cgroups v2, so that the kernel enforces that no other cgroup uses their cpus. The kernel requires
+// parents of a partition root to be partition roots too, so parent cgroups (eg. pod and kubepods cgroups)
+// are made partition roots first. If the kernel does not support partitions or rejects any partition of
+// the chain, parents are restored and the container keeps a regular cpuset.
+func WithCpusetPartitions() CgroupOption {
+	return func(cgc *CgroupControllerImpl) {
+		cgc.cpusetPartitions = true
+		cgc.partitionParents = &partitionParents{promoted: make(map[string]struct{})}
+	}
+}
+
+// WithControllerCgroupVersion overrides cgroup version detected when the controller is created.
+func WithControllerCgroupVersion(version CgroupVersion) CgroupOption {
+	return func(cgc *CgroupControllerImpl) {
+		cgc.cgroupVersion = version
+	}
+}
+
+// partitionParents records parent cgroups made partition roots by the controller, so that they are made
+// partition members again once no partition root is left below them. Parents which were partition roots
+// before (eg. configured by the administrator) are never changed.
+type partitionParents struct {
+	mu       sync.Mutex
+	promoted map[string]struct{}
+}
+
+// PartitionController is implemented by cgroup controllers able to make cpus of the container
+// exclusive on the kernel level. SetPartition is called before the cpuset update of the container
+// which is not exclusive anymore, and after the cpuset update of the container which becomes exclusive.
+type PartitionController interface {
+	SetPartition(path string, c Container, exclusive bool)
+}
+
+var _ PartitionController = CgroupControllerImpl{}
+
+// SetPartition makes the container cgroup and its parents below the cgroup root cpuset partition roots
+// if its cpus are exclusive, or makes the container cgroup partition member otherwise, together with
+// parents left without partition roots. It does nothing unless cpuset partitions are enabled on
+// cgroups v2.
+func (cgc CgroupControllerImpl) SetPartition(pPath string, c Container, exclusive bool) {
+	if !cgc.cpusetPartitions || !cgc.cgroupVersion.unified() {
+		return
+	}
+	dir := path.Join(pPath, cgc.sliceName(c))
+	if err := utils.ValidatePathInsideBase(dir, pPath); err != nil {
+		cgc.logger.Error(err, "invalid cgroup path", "path", dir)
+		return
+	}
+	parents := partitionParentDirs(pPath, dir)
+
+	cgc.partitionParents.mu.Lock()
+	defer cgc.partitionParents.mu.Unlock()
+	if exclusive {
+		cgc.setPartitionChain(parents, dir)
+		return
+	}
+	cgc.setPartition(dir, false)
+	cgc.releasePartitionParents(parents)
+}
+
+// partitionParentDirs returns directories of cgroups between the cgroup root and the cgroup in dir,
+// ordered from the cgroup root.
+func partitionParentDirs(root string, dir string) []string {
+	parents := []string{}
+	for parent := path.Dir(dir); len(parent) > len(root) && strings.HasPrefix(parent, root); parent = path.Dir(parent) {
+		parents = append([]string{parent}, parents...)
+	}
+	return parents
+}
+
+// setPartitionChain makes parents, from the cgroup root down, and then the cgroup in dir partition roots.
+// If any of them cannot be made a partition root, parents made partition roots by this call are made
+// members again and the cgroup keeps a regular cpuset.
+func (cgc CgroupControllerImpl) setPartitionChain(parents []string, dir string) {
+	promoted := []string{}
+	for _, parent := range parents {
+		current, err := readPartition(parent)
+		if err != nil {
+			cgc.logger.V(2).Info("cpuset partitions not supported", "path", parent, "error", err)
+			cgc.restorePartitionParents(promoted)
+			return
+		}
+		if current == partitionRoot {
+			continue
+		}
+		if err := cgc.makePartitionRoot(parent); err != nil {
+			metrics.CpusetPartitionFallbacks.Inc()
+			cgc.logger.Info("cannot make parent cgroup cpuset partition root, using regular cpuset", "path", dir, "parent", parent, "error", err)
+			cgc.restorePartitionParents(append(promoted, parent))
+			return
+		}
+		promoted = append(promoted, parent)
+	}
+	if !cgc.setPartition(dir, true) {
+		cgc.restorePartitionParents(promoted)
+		return
+	}
+	for _, parent := range promoted {
+		cgc.partitionParents.promoted[parent] = struct{}{}
+	}
+}
+
+// restorePartitionParents makes given parents partition members again, from the innermost one.
+func (cgc CgroupControllerImpl) restorePartitionParents(parents []string) {
+	for i := len(parents) - 1; i >= 0; i-- {
+		if err := cgc.writeFile(writePartition, path.Join(parents[i], partitionFile), partitionMember); err != nil {
+			cgc.logger.Error(err, "cannot make cgroup cpuset partition member", "path", parents[i])
+		}
+	}
+}
+
+// releasePartitionParents makes parents made partition roots by the controller partition members again,
+// from the innermost one, as long as no partition root is left below them. Records of parents removed
+// meanwhile (eg. cgroups of deleted pods) are dropped.
+func (cgc CgroupControllerImpl) releasePartitionParents(parents []string) {
+	for parent := range cgc.partitionParents.promoted {
+		if _, err := os.Stat(parent); err != nil {
+			delete(cgc.partitionParents.promoted, parent)
+		}
+	}
+	for i := len(parents) - 1; i >= 0; i-- {
+		if _, ok := cgc.partitionParents.promoted[parents[i]]; !ok || hasPartitionRootChild(parents[i]) {
+			return
+		}
+		if !cgc.setPartition(parents[i], false) {
+			return
+		}
+		delete(cgc.partitionParents.promoted, parents[i])
+	}
+}
+
+// hasPartitionRootChild checks if any child cgroup of the cgroup in dir is a partition root.
+func hasPartitionRootChild(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return true
+	}
+	for _, e := range entries {
+		if partition, err := readPartition(path.Join(dir, e.Name())); e.IsDir() && err == nil && partition == partitionRoot {
+			return true
+		}
+	}
+	return false
+}
+
+// makePartitionRoot makes the cgroup in dir partition root, returns error if the kernel rejects it.
+func (cgc CgroupControllerImpl) makePartitionRoot(dir string) error {
+	if err := cgc.writeFile(writePartition, path.Join(dir, partitionFile), partitionRoot); err != nil {
+		return err
+	}
+	// invalid partitions are reported as eg. "root invalid (cpu list is not exclusive)"
+	current, err := readPartition(dir)
+	if err == nil && current != partitionRoot {
+		err = fmt.Errorf("partition rejected by the kernel: %s", current)
+	}
+	return err
+}
+
+// setPartition sets partition type of the cgroup in given directory and returns true if it is set.
+// Cgroups which the kernel refuses to make valid partition roots (eg. because their cpus are used by
+// sibling cgroups) are reverted to members.
+func (cgc CgroupControllerImpl) setPartition(dir string, exclusive bool) bool {
+	current, err := readPartition(dir)
+	if err != nil {
+		cgc.logger.V(2).Info("cpuset partitions not supported", "path", dir, "error", err)
+		return false
+	}
+	wanted := partitionMember
+	if exclusive {
+		wanted = partitionRoot
+	}
+	if current == wanted {
+		return true
+	}
+
+	file := path.Join(dir, partitionFile)
+	if exclusive {
+		err = cgc.makePartitionRoot(dir)
+	} else {
+		err = cgc.writeFile(writePartition, file, wanted)
+	}
+	if err == nil {
+		cgc.logger.V(2).Info("cpuset partition set", "path", dir, "partition", wanted)
+		return true
+	}
+	if !exclusive {
+		cgc.logger.Error(err, "cannot make cgroup cpuset partition member", "path", dir)
+		return false
+	}
+	metrics.CpusetPartitionFallbacks.Inc()
+	cgc.logger.Info("cannot make cgroup cpuset partition root, using regular cpuset", "path", dir, "error", err)
+	if err := cgc.writeFile(writePartition, file, partitionMember); err != nil {
+		cgc.logger.Error(err, "cannot make cgroup cpuset partition member", "path", dir)
+	}
+	return false
+}
+
+func readPartition(dir string) (string, error) {
+	b, err := os.ReadFile(path.Join(dir, partitionFile))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// exclusiveCPUSet checks if the cpuset written to the container consists only of cpus allocated to it.
+func exclusiveCPUSet(s *DaemonState, c Container, cpuSet string) bool {
+	if c.QS != Guaranteed {
+		return false
+	}
+	cpus, err := CPUSetFromString(cpuSet)
+	if err != nil || cpus.Count() == 0 {
+		return false
+	}
+	return cpus.ToCpuString() == CPUSetFromBucketList(s.Allocated[c.CID]).ToCpuString()
+}
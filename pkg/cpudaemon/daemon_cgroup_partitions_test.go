@@ -0,0 +1,174 @@
+package cpudaemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+type PartitionCgroupsMock struct {
+	CgroupsMock
+}
+
+func (m *PartitionCgroupsMock) SetPartition(pP string, c Container, exclusive bool) {
+	m.Called(pP, c, exclusive)
+}
+
+func newPartitionForTest(t *testing.T, partition string) string {
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, partitionFile), []byte(partition+"\n"), 0o600))
+	return dir
+}
+
+func readPartitionForTest(t *testing.T, dir string) string {
+	partition, err := readPartition(dir)
+	require.Nil(t, err)
+	return partition
+}
+
+// createPartitionCgroups creates cgroups of containers under dir, with the cgroups and their parents being
+// partition members.
+func createPartitionCgroups(t *testing.T, ctrl CgroupControllerImpl, dir string, containers ...Container) {
+	createContainerCgroups(t, ctrl, dir, containers...)
+	for _, c := range containers {
+		for cgroup := ctrl.CgroupPath(dir, c); cgroup != dir; cgroup = filepath.Dir(cgroup) {
+			require.Nil(t, os.WriteFile(filepath.Join(cgroup, partitionFile), []byte(partitionMember+"\n"), 0o600))
+		}
+	}
+}
+
+// readPartitionChainForTest returns partitions of the cgroup of the container and its parents below dir,
+// from the cgroup root.
+func readPartitionChainForTest(t *testing.T, ctrl CgroupControllerImpl, dir string, c Container) []string {
+	chain := []string{}
+	for cgroup := ctrl.CgroupPath(dir, c); cgroup != dir; cgroup = filepath.Dir(cgroup) {
+		chain = append([]string{readPartitionForTest(t, cgroup)}, chain...)
+	}
+	return chain
+}
+
+func TestSetPartitionRoot(t *testing.T) {
+	dir := newPartitionForTest(t, partitionMember)
+
+	NewCgroupController(Docker, DriverSystemd, logr.Discard(), WithCpusetPartitions()).setPartition(dir, true)
+
+	assert.Equal(t, partitionRoot, readPartitionForTest(t, dir))
+}
+
+func TestSetPartitionMember(t *testing.T) {
+	for _, partition := range []string{partitionRoot, "root invalid (cpu list is not exclusive)"} {
+		dir := newPartitionForTest(t, partition)
+
+		NewCgroupController(Docker, DriverSystemd, logr.Discard(), WithCpusetPartitions()).setPartition(dir, false)
+
+		assert.Equal(t, partitionMember, readPartitionForTest(t, dir))
+	}
+}
+
+func TestSetPartitionWithoutKernelSupport(t *testing.T) {
+	dir := t.TempDir()
+
+	NewCgroupController(Docker, DriverSystemd, logr.Discard(), WithCpusetPartitions()).setPartition(dir, true)
+
+	assert.NoFileExists(t, filepath.Join(dir, partitionFile))
+}
+
+func TestSetPartitionDisabled(t *testing.T) {
+	dir := newPartitionForTest(t, partitionMember)
+	c := Container{CID: "docker://cid", PID: "pid", QS: Guaranteed}
+
+	NewCgroupController(Docker, DriverSystemd, logr.Discard()).SetPartition(dir, c, true)
+
+	assert.Equal(t, partitionMember, readPartitionForTest(t, dir))
+}
+
+func TestSetPartitionMakesParentsRoots(t *testing.T) {
+	dir := t.TempDir()
+	c := Container{CID: "containerd://cid", PID: "pid", QS: Guaranteed}
+	ctrl, _ := newControllerWithFaults(CgroupV2, WithCpusetPartitions())
+	createPartitionCgroups(t, ctrl, dir, c)
+
+	ctrl.SetPartition(dir, c, true)
+	assert.Equal(t, []string{partitionRoot, partitionRoot, partitionRoot}, readPartitionChainForTest(t, ctrl, dir, c))
+
+	ctrl.SetPartition(dir, c, false)
+	assert.Equal(t, []string{partitionMember, partitionMember, partitionMember}, readPartitionChainForTest(t, ctrl, dir, c))
+}
+
+func TestSetPartitionKeepsParentsOfOtherPartitions(t *testing.T) {
+	dir := t.TempDir()
+	first := Container{CID: "containerd://first", PID: "pid", QS: Guaranteed}
+	second := Container{CID: "containerd://second", PID: "pid", QS: Guaranteed}
+	ctrl, _ := newControllerWithFaults(CgroupV2, WithCpusetPartitions())
+	createPartitionCgroups(t, ctrl, dir, first, second)
+	kubepods := filepath.Join(dir, "kubepods.slice")
+	require.Nil(t, os.WriteFile(filepath.Join(kubepods, partitionFile), []byte(partitionRoot), 0o600))
+
+	ctrl.SetPartition(dir, first, true)
+	ctrl.SetPartition(dir, second, true)
+	ctrl.SetPartition(dir, first, false)
+	assert.Equal(t, []string{partitionRoot, partitionRoot, partitionMember}, readPartitionChainForTest(t, ctrl, dir, first))
+	assert.Equal(t, []string{partitionRoot, partitionRoot, partitionRoot}, readPartitionChainForTest(t, ctrl, dir, second))
+
+	ctrl.SetPartition(dir, second, false)
+	assert.Equal(t, []string{partitionRoot, partitionMember, partitionMember}, readPartitionChainForTest(t, ctrl, dir, second),
+		"kubepods cgroup was a partition root before")
+}
+
+func TestRejectedParentPartitionRestoresParents(t *testing.T) {
+	dir := t.TempDir()
+	c := Container{CID: "containerd://cid", PID: "pid", QS: Guaranteed}
+	ctrl, faults := newControllerWithFaults(CgroupV2, WithCpusetPartitions())
+	createPartitionCgroups(t, ctrl, dir, c)
+	fallbacks := testutil.ToFloat64(metrics.CpusetPartitionFallbacks)
+	faults.fail(writePartition, 0, syscall.EINVAL) // pod cgroup cpus are not exclusive
+
+	ctrl.SetPartition(dir, c, true)
+
+	assert.Equal(t, []string{partitionMember, partitionMember, partitionMember}, readPartitionChainForTest(t, ctrl, dir, c))
+	assert.Equal(t, fallbacks+1, testutil.ToFloat64(metrics.CpusetPartitionFallbacks))
+}
+
+func TestExclusiveCPUSet(t *testing.T) {
+	s := &DaemonState{Allocated: map[string][]ctlplaneapi.CPUBucket{"cid": {{StartCPU: 1, EndCPU: 2}}}}
+	guaranteed := Container{CID: "cid", QS: Guaranteed}
+
+	assert.True(t, exclusiveCPUSet(s, guaranteed, "1-2"))
+	assert.True(t, exclusiveCPUSet(s, guaranteed, "2,1"))
+	assert.False(t, exclusiveCPUSet(s, guaranteed, "0-7"))
+	assert.False(t, exclusiveCPUSet(s, Container{CID: "cid", QS: Burstable}, "1-2"))
+	assert.False(t, exclusiveCPUSet(s, Container{CID: "other", QS: Guaranteed}, ""))
+}
+
+func TestUpdateContainerCPUSetSetsPartition(t *testing.T) {
+	s := &DaemonState{
+		CGroupPath: "/sys/fs/cgroup",
+		Allocated:  map[string][]ctlplaneapi.CPUBucket{"cid": {{StartCPU: 1, EndCPU: 2}}},
+		Pods:       map[string]PodMetadata{},
+	}
+	c := Container{CID: "cid", PID: "pid", Cpus: 2, QS: Guaranteed}
+	m := PartitionCgroupsMock{}
+	var calls []string
+	m.On("UpdateCPUSet", s.CGroupPath, c, mock.Anything, ResourceNotSet).
+		Run(func(mock.Arguments) { calls = append(calls, "cpuset") }).Return(nil)
+	m.On("SetPartition", s.CGroupPath, c, true).
+		Run(func(mock.Arguments) { calls = append(calls, "root") }).Once()
+	m.On("SetPartition", s.CGroupPath, c, false).
+		Run(func(mock.Arguments) { calls = append(calls, "member") }).Once()
+
+	require.Nil(t, updateContainerCPUSet(context.Background(), &m, s, c, "1-2", ResourceNotSet))
+	require.Nil(t, updateContainerCPUSet(context.Background(), &m, s, c, "0-7", ResourceNotSet))
+
+	assert.Equal(t, []string{"cpuset", "root", "member", "cpuset"}, calls)
+	m.AssertExpectations(t)
+}
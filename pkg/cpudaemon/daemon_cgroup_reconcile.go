@@ -0,0 +1,93 @@
+package cpudaemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+// appliedCgroupUpdate is the last cpuset update written to the cgroup of a container. The reconciler
+// re-applies it when the cpuset of the cgroup drifts from it.
+type appliedCgroupUpdate struct {
+	ctrl      CgroupController
+	container Container
+	cpuSet    string
+	memSet    string
+}
+
+// recordCgroupUpdate remembers cpuset update successfully written to the cgroup of the container.
+func (d *DaemonState) recordCgroupUpdate(ctrl CgroupController, c Container, cpuSet, memSet string) {
+	if d.appliedCgroupUpdates == nil {
+		d.appliedCgroupUpdates = make(map[string]appliedCgroupUpdate)
+	}
+	d.appliedCgroupUpdates[c.CID] = appliedCgroupUpdate{ctrl: ctrl, container: c, cpuSet: cpuSet, memSet: memSet}
+}
+
+// RunCgroupReconciliation checks every interval, until stop is closed, if cpusets of container cgroups
+// written by the daemon were overwritten, eg. by kubelet or container runtime, and re-applies them.
+// Non-positive interval disables the reconciliation.
+func (d *Daemon) RunCgroupReconciliation(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			d.reconcileCgroups()
+		}
+	}
+}
+
+// reconcileCgroups re-reads cpuset.cpus of cgroups of containers whose cpusets were written by the
+// daemon, re-applies the last written cpuset to those which drifted and returns their number. Cgroups
+// which do not exist (eg. of restarting containers) and those waiting for deferred updates are skipped.
+func (d *Daemon) reconcileCgroups() int {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+
+	repaired := 0
+	for cid, u := range d.state.appliedCgroupUpdates {
+		if !d.state.hasContainer(cid) {
+			delete(d.state.appliedCgroupUpdates, cid)
+			continue
+		}
+		cgroupPath := d.state.getCgroupPath(cid)
+		if _, deferred := d.state.deferredCgroupUpdates[cid]; deferred || cgroupPath == "" {
+			continue
+		}
+		current, err := LoadCpuSet(filepath.Join(cgroupPath, "cpuset.cpus"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			d.logger.Error(err, "cannot read container cpuset", "cid", cid)
+			continue
+		}
+		expected, err := CPUSetFromString(u.cpuSet)
+		if err != nil || CPUSetFromBucketList(current).ToCpuString() == expected.ToCpuString() {
+			continue
+		}
+		d.logger.Info(
+			"container cpuset drifted, re-applying",
+			"cid", cid,
+			"cpus", CPUSetFromBucketList(current),
+			"expected", expected,
+		)
+		if err := updateContainerCPUSet(context.Background(), u.ctrl, &d.state, u.container, u.cpuSet, u.memSet); err != nil {
+			d.logger.Error(err, "cannot re-apply container cpuset", "cid", cid)
+			continue
+		}
+		repaired++
+	}
+	metrics.CgroupDriftRepairs.Add(float64(repaired))
+	d.scheduleCgroupRetry()
+	return repaired
+}
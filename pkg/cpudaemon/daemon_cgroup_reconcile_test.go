@@ -0,0 +1,109 @@
+package cpudaemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+// fileCgroups writes cpusets of containers to cpuset.cpus files in directories named after container ids.
+type fileCgroups struct{}
+
+func (fileCgroups) UpdateCPUSet(_ context.Context, pP string, c Container, cpuSet string, _ string) error {
+	dir := filepath.Join(pP, c.CID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "cpuset.cpus"), []byte(cpuSet+"\n"), 0o644)
+}
+
+func (fileCgroups) CgroupPath(pP string, c Container) string {
+	return filepath.Join(pP, c.CID)
+}
+
+func newDaemonForReconcileTest(t *testing.T) (*Daemon, Container) {
+	c := Container{CID: "cid", PID: "pid", Cpus: 2, QS: Guaranteed}
+	d := &Daemon{
+		state: DaemonState{
+			CGroupPath: t.TempDir(),
+			Allocated:  map[string][]ctlplaneapi.CPUBucket{},
+			Pods:       map[string]PodMetadata{c.PID: {PID: c.PID, Containers: []Container{c}}},
+		},
+		logger: logr.Discard(),
+	}
+	require.Nil(t, updateContainerCPUSet(context.Background(), fileCgroups{}, &d.state, c, "2-3", ResourceNotSet))
+	return d, c
+}
+
+func readCpuset(t *testing.T, d *Daemon, c Container) string {
+	cpus, err := os.ReadFile(filepath.Join(d.state.getCgroupPath(c.CID), "cpuset.cpus"))
+	require.Nil(t, err)
+	return string(cpus)
+}
+
+func TestReconcileCgroupsRepairsDrift(t *testing.T) {
+	d, c := newDaemonForReconcileTest(t)
+	repairs := testutil.ToFloat64(metrics.CgroupDriftRepairs)
+	require.Nil(t, os.WriteFile(filepath.Join(d.state.getCgroupPath(c.CID), "cpuset.cpus"), []byte("0-7\n"), 0o644))
+
+	assert.Equal(t, 1, d.reconcileCgroups())
+
+	assert.Equal(t, "2-3\n", readCpuset(t, d, c))
+	assert.Equal(t, repairs+1, testutil.ToFloat64(metrics.CgroupDriftRepairs))
+}
+
+func TestReconcileCgroupsKeepsMatchingCpusets(t *testing.T) {
+	d, c := newDaemonForReconcileTest(t)
+	// the same cpus written in another format are not a drift
+	require.Nil(t, os.WriteFile(filepath.Join(d.state.getCgroupPath(c.CID), "cpuset.cpus"), []byte("2,3\n"), 0o644))
+
+	assert.Zero(t, d.reconcileCgroups())
+	assert.Equal(t, "2,3\n", readCpuset(t, d, c))
+}
+
+func TestReconcileCgroupsSkipsMissingCgroups(t *testing.T) {
+	d, c := newDaemonForReconcileTest(t)
+	require.Nil(t, os.RemoveAll(d.state.getCgroupPath(c.CID)))
+
+	assert.Zero(t, d.reconcileCgroups())
+	assert.NoDirExists(t, d.state.getCgroupPath(c.CID), "missing cgroup shall not be created")
+}
+
+func TestReconcileCgroupsForgetsDeletedContainers(t *testing.T) {
+	d, c := newDaemonForReconcileTest(t)
+	delete(d.state.Pods, c.PID)
+
+	assert.Zero(t, d.reconcileCgroups())
+	assert.Empty(t, d.state.appliedCgroupUpdates)
+}
+
+func TestRunCgroupReconciliation(t *testing.T) {
+	d, c := newDaemonForReconcileTest(t)
+	require.Nil(t, os.WriteFile(filepath.Join(d.state.getCgroupPath(c.CID), "cpuset.cpus"), []byte("0-7\n"), 0o644))
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		d.RunCgroupReconciliation(10*time.Millisecond, stop)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		d.stateMu.Lock()
+		defer d.stateMu.Unlock()
+		return readCpuset(t, d, c) == "2-3\n"
+	}, 3*time.Second, 10*time.Millisecond)
+	close(stop)
+	<-done
+
+	d.RunCgroupReconciliation(0, nil) // returns immediately
+}
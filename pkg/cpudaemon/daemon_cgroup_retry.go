@@ -0,0 +1,135 @@
+package cpudaemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+const (
+	defaultCgroupRetryDelay    = time.Second
+	defaultCgroupRetryAttempts = 10
+)
+
+// CgroupNotReadyError is returned by the cgroup controller when the cgroup of the container does not
+// exist yet, eg. because the agent reported the container before its systemd scope was created. The
+// error is retryable: unless deferred updates are disabled, the daemon keeps the allocation and
+// re-applies the cpuset of the container later.
+type CgroupNotReadyError struct {
+	DaemonError
+	Path string
+}
+
+func newCgroupNotReadyError(path string) CgroupNotReadyError {
+	return CgroupNotReadyError{
+		DaemonError: DaemonError{
+			ErrorType:    CgroupNotReady,
+			ErrorMessage: fmt.Sprintf("cgroup %s does not exist yet", path),
+		},
+		Path: path,
+	}
+}
+
+// Retryable reports that the failed update may succeed when repeated later.
+func (e CgroupNotReadyError) Retryable() bool {
+	return true
+}
+
+// isRetryable returns true if the error reports a cgroup update which may succeed when repeated later.
+func isRetryable(err error) bool {
+	var r interface{ Retryable() bool }
+	return errors.As(err, &r) && r.Retryable()
+}
+
+// cgroupExists returns false if the cgroup directory does not exist. Other errors are reported by the
+// cpuset write itself.
+func cgroupExists(dir string) bool {
+	_, err := os.Stat(dir)
+	return !errors.Is(err, os.ErrNotExist)
+}
+
+// deferredCgroupUpdate is a cpuset update of a container whose cgroup did not exist yet.
+type deferredCgroupUpdate struct {
+	ctrl      CgroupController
+	container Container
+	cpuSet    string
+	memSet    string
+	err       error // error of the last attempt
+	attempts  int   // number of failed re-applies
+}
+
+// deferCgroupUpdate records cpuset update of the container to be re-applied later. Re-applies of the
+// same update are counted as attempts. Returns false if deferred updates are disabled.
+func (d *DaemonState) deferCgroupUpdate(ctrl CgroupController, c Container, cpuSet, memSet string, err error) bool {
+	if d.cgroupRetryAttempts <= 0 {
+		return false
+	}
+	if d.deferredCgroupUpdates == nil {
+		d.deferredCgroupUpdates = make(map[string]deferredCgroupUpdate)
+	}
+	u := deferredCgroupUpdate{ctrl: ctrl, container: c, cpuSet: cpuSet, memSet: memSet, err: err}
+	if prev, ok := d.deferredCgroupUpdates[c.CID]; ok && prev.cpuSet == cpuSet && prev.memSet == memSet {
+		u.attempts = prev.attempts + 1
+	} else {
+		metrics.DeferredCgroupUpdates.Inc()
+	}
+	d.deferredCgroupUpdates[c.CID] = u
+	return true
+}
+
+// hasContainer returns true if the container belongs to any pod.
+func (d *DaemonState) hasContainer(cid string) bool {
+	for _, pod := range d.Pods {
+		for _, c := range pod.Containers {
+			if c.CID == cid {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scheduleCgroupRetry schedules re-apply of deferred cgroup updates, unless it is already scheduled.
+func (d *Daemon) scheduleCgroupRetry() {
+	if len(d.state.deferredCgroupUpdates) == 0 || d.cgroupRetryTimer != nil {
+		return
+	}
+	d.cgroupRetryTimer = time.AfterFunc(d.options.cgroupRetryDelay, d.retryCgroupUpdates)
+}
+
+// retryCgroupUpdates re-applies deferred cgroup updates of containers which still exist. Updates failing
+// the configured number of times are dropped: the container keeps its allocation, but is not pinned.
+func (d *Daemon) retryCgroupUpdates() {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	d.cgroupRetryTimer = nil
+
+	for cid, u := range d.state.deferredCgroupUpdates {
+		if !d.state.hasContainer(cid) {
+			delete(d.state.deferredCgroupUpdates, cid)
+			continue
+		}
+		if err := updateContainerCPUSet(context.Background(), u.ctrl, &d.state, u.container, u.cpuSet, u.memSet); err != nil {
+			d.logger.Error(err, "cannot apply deferred cgroup update", "cid", cid)
+			delete(d.state.deferredCgroupUpdates, cid)
+			continue
+		}
+		failed, ok := d.state.deferredCgroupUpdates[cid]
+		if !ok {
+			d.logger.Info("deferred cgroup update applied", "cid", cid, "cpuSet", u.cpuSet)
+			continue
+		}
+		if failed.attempts >= d.state.cgroupRetryAttempts {
+			d.logger.Error(failed.err, "giving up deferred cgroup update", "cid", cid, "attempts", failed.attempts)
+			metrics.ExhaustedCgroupUpdates.Inc()
+			delete(d.state.deferredCgroupUpdates, cid)
+		}
+	}
+	if err := d.saveState(); err != nil {
+		d.logger.Error(err, "cannot save state")
+	}
+}
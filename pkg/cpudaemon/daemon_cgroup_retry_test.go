@@ -0,0 +1,126 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+func TestUpdateCPUSetReportsMissingCgroup(t *testing.T) {
+	dir := t.TempDir()
+	c := Container{CID: "containerd://cid", PID: "pid", QS: Guaranteed}
+	for _, version := range []CgroupVersion{CgroupV1, CgroupV2} {
+		ctrl := NewCgroupController(ContainerdRunc, DriverSystemd, logr.Discard(), WithControllerCgroupVersion(version))
+
+		err := ctrl.UpdateCPUSet(context.Background(), dir, c, "1", "")
+
+		var notReady CgroupNotReadyError
+		require.ErrorAs(t, err, &notReady)
+		assert.Equal(t, ctrl.CgroupPath(dir, c), notReady.Path)
+		assert.True(t, isRetryable(err))
+		assert.NoDirExists(t, notReady.Path, "missing cgroup shall not be created by the daemon")
+	}
+}
+
+func TestUpdateContainerCPUSetDefersMissingCgroup(t *testing.T) {
+	c := Container{CID: "cid", PID: "pid", QS: Guaranteed}
+	m := CgroupsMock{}
+	m.On("UpdateCPUSet", "/cgroup", c, "1", "0").Return(newCgroupNotReadyError("/cgroup/cid"))
+	s := &DaemonState{CGroupPath: "/cgroup", cgroupRetryAttempts: 3}
+	deferred := testutil.ToFloat64(metrics.DeferredCgroupUpdates)
+
+	require.Nil(t, updateContainerCPUSet(context.Background(), &m, s, c, "1", "0"))
+	require.Nil(t, updateContainerCPUSet(context.Background(), &m, s, c, "1", "0"))
+
+	require.Contains(t, s.deferredCgroupUpdates, c.CID)
+	assert.Equal(t, 1, s.deferredCgroupUpdates[c.CID].attempts)
+	assert.Equal(t, "0", s.getMemoryNodes(c.CID))
+	assert.Equal(t, deferred+1, testutil.ToFloat64(metrics.DeferredCgroupUpdates))
+}
+
+func TestUpdateContainerCPUSetFailsOnMissingCgroupWithoutRetries(t *testing.T) {
+	c := Container{CID: "cid", PID: "pid", QS: Guaranteed}
+	m := CgroupsMock{}
+	m.On("UpdateCPUSet", "/cgroup", c, "1", "").Return(newCgroupNotReadyError("/cgroup/cid"))
+	s := &DaemonState{CGroupPath: "/cgroup"}
+
+	err := updateContainerCPUSet(context.Background(), &m, s, c, "1", "")
+
+	assert.ErrorAs(t, err, &CgroupNotReadyError{})
+	assert.Empty(t, s.deferredCgroupUpdates)
+}
+
+func TestCreatePodWithMissingCgroupIsRetried(t *testing.T) {
+	m := CgroupsMock{}
+	p := createTestPod(1)
+	d := newTestDaemon(t, NewStaticPolocy(NewDefaultAllocator(&m)), WithCgroupRetry(time.Hour, 3))
+	m.On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(newCgroupNotReadyError("/cgroup/cid")).Once()
+
+	_, err := d.CreatePod(context.Background(), &ctlplaneapi.CreatePodRequest{
+		PodId:        p.pid,
+		PodName:      p.name,
+		PodNamespace: p.namespace,
+		Resources:    p.resources,
+		Containers:   p.containersResources,
+	})
+
+	require.Nil(t, err)
+	require.Contains(t, d.state.deferredCgroupUpdates, p.containers[0].CID)
+	require.NotNil(t, d.cgroupRetryTimer)
+
+	m.On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	d.cgroupRetryTimer.Stop()
+	d.retryCgroupUpdates()
+
+	assert.Empty(t, d.state.deferredCgroupUpdates)
+	assert.Nil(t, d.cgroupRetryTimer)
+	m.AssertNumberOfCalls(t, "UpdateCPUSet", 2)
+}
+
+func TestRetryCgroupUpdatesGivesUp(t *testing.T) {
+	m := CgroupsMock{}
+	c := Container{CID: "cid", PID: "pid", QS: Guaranteed}
+	m.On("UpdateCPUSet", mock.Anything, c, "1", "").Return(newCgroupNotReadyError("/cgroup/cid"))
+	d := newTestDaemon(t, NewStaticPolocy(NewDefaultAllocator(&m)), WithCgroupRetry(time.Hour, 2))
+	d.state.Pods["pid"] = PodMetadata{PID: "pid", Containers: []Container{c}}
+	require.True(t, d.state.deferCgroupUpdate(&m, c, "1", "", newCgroupNotReadyError("/cgroup/cid")))
+	exhausted := testutil.ToFloat64(metrics.ExhaustedCgroupUpdates)
+
+	d.retryCgroupUpdates()
+	assert.Contains(t, d.state.deferredCgroupUpdates, c.CID)
+	d.cgroupRetryTimer.Stop()
+	d.retryCgroupUpdates()
+
+	assert.Empty(t, d.state.deferredCgroupUpdates)
+	assert.Equal(t, exhausted+1, testutil.ToFloat64(metrics.ExhaustedCgroupUpdates))
+}
+
+func TestRetryCgroupUpdatesDropsDeletedContainers(t *testing.T) {
+	m := CgroupsMock{}
+	c := Container{CID: "cid", PID: "pid", QS: Guaranteed}
+	d := newTestDaemon(t, NewStaticPolocy(NewDefaultAllocator(&m)), WithCgroupRetry(time.Hour, 2))
+	require.True(t, d.state.deferCgroupUpdate(&m, c, "1", "", newCgroupNotReadyError("/cgroup/cid")))
+
+	d.retryCgroupUpdates()
+
+	assert.Empty(t, d.state.deferredCgroupUpdates)
+	m.AssertNotCalled(t, "UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestInvalidCgroupRetryFails(t *testing.T) {
+	for _, opt := range []Option{WithCgroupRetry(time.Second, -1), WithCgroupRetry(0, 1)} {
+		o := newDaemonOptions([]Option{opt})
+		assert.NotNil(t, o.validate())
+	}
+	o := newDaemonOptions([]Option{WithCgroupRetry(0, 0)})
+	assert.Nil(t, o.validate())
+}
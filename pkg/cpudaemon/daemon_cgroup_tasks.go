@@ -0,0 +1,58 @@
+package cpudaemon
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+const (
+	// defaultEmptyCgroupWait is how long the cgroup controller waits for a live task in the container
+	// cgroup before its cpuset is written anyway.
+	defaultEmptyCgroupWait  = 500 * time.Millisecond
+	emptyCgroupPollInterval = 50 * time.Millisecond
+)
+
+// hasTasks checks if the cgroup in given directory contains at least one process.
+func hasTasks(dir string) (bool, error) {
+	b, err := os.ReadFile(path.Join(dir, "cgroup.procs"))
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(b)) != "", nil
+}
+
+// waitForTasks waits until the cgroup in given directory contains a live task. Cgroups which stay empty
+// (eg. of containers which already exited, or mismatched between runtime and the daemon) are still
+// pinned, but are logged and counted, as such allocation is most likely wasted. It returns whether the
+// cgroup has tasks. Waiting ends early when the context is done.
+func (cgc CgroupControllerImpl) waitForTasks(ctx context.Context, dir string) bool {
+	deadline := time.Now().Add(cgc.emptyCgroupWait)
+	for {
+		ok, err := hasTasks(dir)
+		if err != nil {
+			// missing cgroup is reported by the cpuset write itself
+			cgc.logger.V(2).Info("cannot read tasks of cgroup", "path", dir, "error", err)
+			return false
+		}
+		if ok {
+			return true
+		}
+		if !time.Now().Before(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			cgc.logger.V(2).Info("waiting for tasks of cgroup aborted", "path", dir, "error", ctx.Err())
+			return false
+		case <-time.After(emptyCgroupPollInterval):
+		}
+	}
+	metrics.EmptyCgroupPins.Inc()
+	cgc.logger.Info("pinning cgroup without live tasks", "path", dir, "waited", cgc.emptyCgroupWait)
+	return false
+}
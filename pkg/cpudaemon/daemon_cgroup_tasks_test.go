@@ -0,0 +1,84 @@
+package cpudaemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+func newCgroupForTest(t *testing.T, procs string) string {
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte(procs), 0o600))
+	return dir
+}
+
+func newCgroupControllerForTest(wait time.Duration) CgroupControllerImpl {
+	cgc := NewCgroupController(Docker, DriverSystemd, logr.Discard())
+	cgc.emptyCgroupWait = wait
+	return cgc
+}
+
+func TestHasTasks(t *testing.T) {
+	ok, err := hasTasks(newCgroupForTest(t, "123\n456\n"))
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	ok, err = hasTasks(newCgroupForTest(t, ""))
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	_, err = hasTasks(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestWaitForTasksOfLiveCgroup(t *testing.T) {
+	pins := testutil.ToFloat64(metrics.EmptyCgroupPins)
+
+	assert.True(t, newCgroupControllerForTest(time.Second).waitForTasks(context.Background(), newCgroupForTest(t, "1\n")))
+	assert.Equal(t, pins, testutil.ToFloat64(metrics.EmptyCgroupPins))
+}
+
+func TestWaitForTasksCountsEmptyCgroup(t *testing.T) {
+	pins := testutil.ToFloat64(metrics.EmptyCgroupPins)
+
+	assert.False(t, newCgroupControllerForTest(0).waitForTasks(context.Background(), newCgroupForTest(t, "")))
+	assert.Equal(t, pins+1, testutil.ToFloat64(metrics.EmptyCgroupPins))
+}
+
+func TestWaitForTasksUntilTaskStarts(t *testing.T) {
+	dir := newCgroupForTest(t, "")
+	pins := testutil.ToFloat64(metrics.EmptyCgroupPins)
+	go func() {
+		time.Sleep(2 * emptyCgroupPollInterval)
+		_ = os.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte("1\n"), 0o600)
+	}()
+
+	assert.True(t, newCgroupControllerForTest(10*time.Second).waitForTasks(context.Background(), dir))
+	assert.Equal(t, pins, testutil.ToFloat64(metrics.EmptyCgroupPins))
+}
+
+func TestWaitForTasksIgnoresMissingCgroup(t *testing.T) {
+	pins := testutil.ToFloat64(metrics.EmptyCgroupPins)
+
+	assert.False(t, newCgroupControllerForTest(time.Second).waitForTasks(context.Background(), filepath.Join(t.TempDir(), "missing")))
+	assert.Equal(t, pins, testutil.ToFloat64(metrics.EmptyCgroupPins))
+}
+
+func TestWaitForTasksEndsOnCanceledContext(t *testing.T) {
+	pins := testutil.ToFloat64(metrics.EmptyCgroupPins)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	assert.False(t, newCgroupControllerForTest(10*time.Second).waitForTasks(ctx, newCgroupForTest(t, "")))
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, pins, testutil.ToFloat64(metrics.EmptyCgroupPins), "aborted wait is not counted")
+}
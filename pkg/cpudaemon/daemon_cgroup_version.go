@@ -0,0 +1,95 @@
+package cpudaemon
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/containerd/cgroups"
+	"golang.org/x/sys/unix"
+)
+
+// CgroupVersion is the version of cgroups the daemon manages cpusets with. It is detected once at
+// startup and stored in the daemon state, so that all cgroup updates use the same hierarchy layout.
+// Values match ctlplaneapi.CgroupVersion.
+type CgroupVersion int
+
+const (
+	// CgroupVersionUnknown is used when no cgroup filesystem is found. Cgroups v1 layout is assumed.
+	CgroupVersionUnknown CgroupVersion = iota
+	// CgroupV1 is used on legacy and hybrid hosts, where cpusets are managed in the v1 cpuset hierarchy.
+	CgroupV1
+	// CgroupV2 is used on hosts with unified hierarchy.
+	CgroupV2
+)
+
+func (v CgroupVersion) String() string {
+	return []string{"unknown", "v1", "v2"}[v]
+}
+
+// DetectCgroupVersion returns version of cgroups mounted on /sys/fs/cgroup of the daemon.
+func DetectCgroupVersion() CgroupVersion {
+	switch cgroups.Mode() {
+	case cgroups.Unified:
+		return CgroupV2
+	case cgroups.Legacy, cgroups.Hybrid:
+		return CgroupV1
+	default:
+		return CgroupVersionUnknown
+	}
+}
+
+func (v CgroupVersion) unified() bool {
+	return v == CgroupV2
+}
+
+// cpusetPaths returns cgroup directory of cpuset controller under given cgroup root and its file with
+// cpus available to the cgroup.
+func (v CgroupVersion) cpusetPaths(cgroupPath string) (string, string) {
+	if v.unified() {
+		return cgroupPath, "cpuset.cpus.effective"
+	}
+	return filepath.Join(cgroupPath, "cpuset"), "cpuset.cpus"
+}
+
+// cgroupFsVersion returns version of cgroup filesystem mounted on given path, which is the hierarchy
+// used by kubelet. Returns CgroupVersionUnknown if the path is not a cgroup mount.
+func cgroupFsVersion(cgroupPath string) CgroupVersion {
+	var st unix.Statfs_t
+	if err := unix.Statfs(cgroupPath, &st); err == nil && st.Type == unix.CGROUP2_SUPER_MAGIC {
+		return CgroupV2
+	}
+	if err := unix.Statfs(filepath.Join(cgroupPath, "cpuset"), &st); err == nil && st.Type == unix.CGROUP_SUPER_MAGIC {
+		return CgroupV1
+	}
+	return CgroupVersionUnknown
+}
+
+// validateCgroupVersion refuses mixing of cgroup versions: the cgroup filesystem given to the daemon
+// (and used by kubelet) and the state file written by previous daemon run must use the cgroup version
+// detected by the daemon. Unknown versions are not validated.
+func validateCgroupVersion(version, fsVersion, stateVersion CgroupVersion, cgroupPath string) error {
+	if version == CgroupVersionUnknown {
+		return nil
+	}
+	if fsVersion != CgroupVersionUnknown && fsVersion != version {
+		return DaemonError{
+			ErrorType: ConfigurationError,
+			ErrorMessage: fmt.Sprintf(
+				"cgroup filesystem in %s uses cgroups %s while the daemon detected cgroups %s, "+
+					"mount host /sys/fs/cgroup into the daemon container and set -cpath to it",
+				cgroupPath, fsVersion, version,
+			),
+		}
+	}
+	if stateVersion != CgroupVersionUnknown && stateVersion != version {
+		return DaemonError{
+			ErrorType: ConfigurationError,
+			ErrorMessage: fmt.Sprintf(
+				"state file was written with cgroups %s while the daemon detected cgroups %s, "+
+					"remove the state file after the node is migrated to cgroups %s",
+				stateVersion, version, version,
+			),
+		}
+	}
+	return nil
+}
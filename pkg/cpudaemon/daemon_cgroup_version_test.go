@@ -0,0 +1,73 @@
+package cpudaemon
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCgroupVersionCpusetPaths(t *testing.T) {
+	dir, file := CgroupV2.cpusetPaths("/sys/fs/cgroup")
+	assert.Equal(t, "/sys/fs/cgroup", dir)
+	assert.Equal(t, "cpuset.cpus.effective", file)
+
+	for _, v := range []CgroupVersion{CgroupV1, CgroupVersionUnknown} {
+		dir, file = v.cpusetPaths("/sys/fs/cgroup")
+		assert.Equal(t, "/sys/fs/cgroup/cpuset", dir)
+		assert.Equal(t, "cpuset.cpus", file)
+	}
+}
+
+func TestCgroupFsVersionOfRegularDirectory(t *testing.T) {
+	assert.Equal(t, CgroupVersionUnknown, cgroupFsVersion(t.TempDir()))
+	assert.Equal(t, CgroupVersionUnknown, cgroupFsVersion(filepath.Join(t.TempDir(), "missing")))
+}
+
+func TestValidateCgroupVersion(t *testing.T) {
+	tc := []struct {
+		version, fsVersion, stateVersion CgroupVersion
+		valid                            bool
+	}{
+		{CgroupV2, CgroupV2, CgroupV2, true},
+		{CgroupV1, CgroupV1, CgroupV1, true},
+		{CgroupV2, CgroupVersionUnknown, CgroupVersionUnknown, true},
+		{CgroupVersionUnknown, CgroupV1, CgroupV2, true},
+		{CgroupV2, CgroupV1, CgroupVersionUnknown, false},
+		{CgroupV1, CgroupV2, CgroupVersionUnknown, false},
+		{CgroupV2, CgroupVersionUnknown, CgroupV1, false},
+	}
+	for _, tt := range tc {
+		err := validateCgroupVersion(tt.version, tt.fsVersion, tt.stateVersion, "/sys/fs/cgroup")
+		if tt.valid {
+			assert.Nil(t, err, "version %s, fs %s, state %s", tt.version, tt.fsVersion, tt.stateVersion)
+			continue
+		}
+		var dErr DaemonError
+		require.ErrorAs(t, err, &dErr, "version %s, fs %s, state %s", tt.version, tt.fsVersion, tt.stateVersion)
+		assert.Equal(t, ConfigurationError, dErr.ErrorType)
+	}
+}
+
+func TestNewRefusesStateOfOtherCgroupVersion(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "daemon.state")
+	d, err := New(
+		"testdata/no_state", "testdata/node_info", statePath, &MockedPolicy{}, logr.Discard(),
+		WithCgroupVersion(CgroupV2),
+	)
+	require.Nil(t, err)
+	assert.Equal(t, CgroupV2, readStateFile(t, statePath).CgroupVersion)
+	d.Close()
+
+	_, err = New(
+		"testdata/no_state", "testdata/node_info", statePath, &MockedPolicy{}, logr.Discard(),
+		WithCgroupVersion(CgroupV1),
+	)
+
+	var dErr DaemonError
+	require.ErrorAs(t, err, &dErr)
+	assert.Equal(t, ConfigurationError, dErr.ErrorType)
+	assert.Contains(t, dErr.ErrorMessage, "cgroups v2")
+}
@@ -0,0 +1,64 @@
+package cpudaemon
+
+import (
+	"path"
+	"strconv"
+
+	"resourcemanagement.controlplane/pkg/utils"
+)
+
+const (
+	cpuWeightPerCpu = 100   // cgroups v2 cpu.weight per requested cpu, the default weight is one cpu
+	minCPUWeight    = 1     // minimal cgroups v2 cpu.weight
+	maxCPUWeight    = 10000 // maximal cgroups v2 cpu.weight
+	minCPUShares    = 2     // minimal cgroups v1 cpu.shares
+	maxCPUShares    = 262144
+)
+
+// WeightController is implemented by cgroup controllers able to set cpu weight of the container, which
+// decides how cpu time is split between containers sharing cpus.
+type WeightController interface {
+	SetCPUWeight(path string, c Container, weight uint64)
+}
+
+var _ WeightController = CgroupControllerImpl{}
+
+// SetCPUWeight sets cpu.weight of the container cgroup on cgroups v2, or equivalent cpu.shares on
+// cgroups v1. Failures are logged only, as the container keeps the weight set by kubelet.
+func (cgc CgroupControllerImpl) SetCPUWeight(pPath string, c Container, weight uint64) {
+	slice := cgc.sliceName(c)
+	file := path.Join(pPath, slice, "cpu.weight")
+	value := strconv.FormatUint(weight, 10)
+	if !cgc.cgroupVersion.unified() {
+		file = path.Join(pPath, "cpu", slice, "cpu.shares")
+		value = strconv.FormatUint(weightToShares(weight), 10)
+	}
+	if err := utils.ValidatePathInsideBase(file, pPath); err != nil {
+		cgc.logger.Error(err, "invalid cgroup path", "path", file)
+		return
+	}
+	if err := cgc.writeFile(writeCPUWeight, file, value); err != nil {
+		cgc.logger.Error(err, "cannot set cpu weight", "path", file, "weight", weight)
+		return
+	}
+	cgc.logger.V(2).Info("cpu weight set", "path", file, "weight", weight)
+}
+
+// containerCPUWeight returns cpu weight proportional to cpus requested by the container, with given
+// weight per cpu. Containers requesting no whole cpus get the minimal weight.
+func containerCPUWeight(c Container, perCpu uint64) uint64 {
+	weight := uint64(c.Cpus) * perCpu
+	if c.Cpus <= 0 {
+		weight = minCPUWeight
+	}
+	if weight > maxCPUWeight {
+		weight = maxCPUWeight
+	}
+	return weight
+}
+
+// weightToShares converts cgroups v2 cpu.weight to cgroups v1 cpu.shares, inversely to the conversion
+// used by container runtimes.
+func weightToShares(weight uint64) uint64 {
+	return minCPUShares + ((weight-minCPUWeight)*(maxCPUShares-minCPUShares))/(maxCPUWeight-minCPUWeight)
+}
@@ -0,0 +1,66 @@
+package cpudaemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type WeightCgroupsMock struct {
+	CgroupsMock
+}
+
+func (m *WeightCgroupsMock) SetCPUWeight(pP string, c Container, weight uint64) {
+	m.Called(pP, c, weight)
+}
+
+func TestContainerCPUWeight(t *testing.T) {
+	tc := []struct {
+		cpus   int
+		weight uint64
+	}{
+		{cpus: 0, weight: 1},
+		{cpus: 1, weight: 100},
+		{cpus: 3, weight: 300},
+		{cpus: 200, weight: 10000},
+	}
+	for _, tt := range tc {
+		assert.Equal(t, tt.weight, containerCPUWeight(Container{Cpus: tt.cpus}, cpuWeightPerCpu), tt.cpus)
+	}
+}
+
+func TestWeightToShares(t *testing.T) {
+	assert.Equal(t, uint64(2), weightToShares(1))
+	assert.Equal(t, uint64(2597), weightToShares(100))
+	assert.Equal(t, uint64(262144), weightToShares(10000))
+}
+
+func TestSetCPUWeight(t *testing.T) {
+	c := Container{CID: "docker://cid", PID: "pid", QS: Burstable}
+	slice := SliceName(c, Docker, DriverSystemd)
+	tc := []struct {
+		version CgroupVersion
+		file    string
+		value   string
+	}{
+		{CgroupV2, filepath.Join(slice, "cpu.weight"), "300"},
+		{CgroupV1, filepath.Join("cpu", slice, "cpu.shares"), "7840"},
+	}
+	for _, tt := range tc {
+		dir := t.TempDir()
+		file := filepath.Join(dir, tt.file)
+		require.Nil(t, os.MkdirAll(filepath.Dir(file), 0o755))
+		require.Nil(t, os.WriteFile(file, []byte{}, 0o600))
+
+		ctrl := NewCgroupController(Docker, DriverSystemd, logr.Discard(), WithControllerCgroupVersion(tt.version))
+		ctrl.SetCPUWeight(dir, c, 300)
+
+		b, err := os.ReadFile(file)
+		require.Nil(t, err)
+		assert.Equal(t, tt.value, string(b))
+	}
+}
@@ -0,0 +1,121 @@
+package cpudaemon
+
+import (
+	"context"
+	"sort"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// ConfigPolicy is implemented by policies able to report live configuration of their allocator.
+type ConfigPolicy interface {
+	AllocatorConfig(s *DaemonState) (ctlplaneapi.AllocatorConfig, bool)
+}
+
+var _ ConfigPolicy = &StaticPolicy{}
+
+// configuredAllocator is implemented by allocators reporting their configuration.
+type configuredAllocator interface {
+	config(s *DaemonState) ctlplaneapi.AllocatorConfig
+}
+
+var (
+	_ configuredAllocator = &DefaultAllocator{}
+	_ configuredAllocator = &NumaAwareAllocator{}
+	_ configuredAllocator = &NumaPerNamespaceAllocator{}
+	_ configuredAllocator = &ScatterAllocator{}
+)
+
+// GetConfig returns live configuration of the daemon and its allocator, so that clients can check
+// capabilities of the daemon before sending requests.
+func (d *Daemon) GetConfig(_ context.Context, _ *ctlplaneapi.GetConfigRequest) (*ctlplaneapi.DaemonConfig, error) {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+
+	config := ctlplaneapi.DaemonConfig{
+		ReservedCPUs: d.state.ReservedCPUs,
+		ExcludedCPUs: d.state.ExcludedCPUs,
+		ManagedCPUs:  d.state.ManagedCPUs,
+		KubeletCPUs:  d.state.KubeletCPUs,
+	}
+	if p, ok := d.policy.(ConfigPolicy); ok {
+		if a, ok := p.AllocatorConfig(&d.state); ok {
+			config.Allocator = &a
+		}
+	}
+	return &config, nil
+}
+
+// AllocatorConfig returns live configuration of the allocator, if the allocator reports it.
+func (p *StaticPolicy) AllocatorConfig(s *DaemonState) (ctlplaneapi.AllocatorConfig, bool) {
+	a, ok := p.allocator.(configuredAllocator)
+	if !ok {
+		return ctlplaneapi.AllocatorConfig{}, false
+	}
+	return a.config(s), true
+}
+
+func (d *DefaultAllocator) config(_ *DaemonState) ctlplaneapi.AllocatorConfig {
+	return ctlplaneapi.AllocatorConfig{
+		Name:      "default",
+		Exclusive: true,
+	}
+}
+
+func (d *NumaAwareAllocator) config(_ *DaemonState) ctlplaneapi.AllocatorConfig {
+	return ctlplaneapi.AllocatorConfig{
+		Name:          "numa",
+		Exclusive:     true,
+		MemoryPinning: d.memoryPinning,
+		FullCores:     d.fullCores,
+	}
+}
+
+func (d *ScatterAllocator) config(_ *DaemonState) ctlplaneapi.AllocatorConfig {
+	return ctlplaneapi.AllocatorConfig{
+		Name:          "scatter",
+		Exclusive:     true,
+		MemoryPinning: d.numa.memoryPinning,
+	}
+}
+
+// config reports all buckets of the allocator, including ones without namespaces.
+func (d *NumaPerNamespaceAllocator) config(s *DaemonState) ctlplaneapi.AllocatorConfig {
+	name := "numa-namespace"
+	if d.exclusive {
+		name = "numa-namespace-exclusive"
+	}
+	namespaces := make(map[int][]string, d.NumBuckets)
+	for namespace, index := range d.NamespaceToBucket {
+		namespaces[index] = append(namespaces[index], namespace)
+	}
+	buckets := make([]ctlplaneapi.CPUBucketConfig, 0, d.NumBuckets)
+	for i := 0; i < d.NumBuckets; i++ {
+		cpus := CPUSet{}
+		for _, cpu := range d.bucketCpus(s, i) {
+			cpus.Add(cpu.Value)
+		}
+		sort.Strings(namespaces[i])
+		buckets = append(buckets, ctlplaneapi.CPUBucketConfig{
+			Bucket:     i,
+			CPUSet:     cpus.ToCompactBucketList(),
+			Namespaces: namespaces[i],
+		})
+	}
+	var mems map[string]string
+	if len(d.namespaceMemoryNodes) > 0 {
+		mems = make(map[string]string, len(d.namespaceMemoryNodes))
+		for namespace, nodes := range d.namespaceMemoryNodes {
+			mems[namespace] = nodes
+		}
+	}
+	return ctlplaneapi.AllocatorConfig{
+		Name:                 name,
+		Exclusive:            d.exclusive,
+		MemoryPinning:        d.memoryPinning,
+		BurstableSoftPinning: d.burstableSoftPinning,
+		BucketWeights:        d.bucketWeights,
+		Buckets:              buckets,
+		NamespaceMemoryNodes: mems,
+	}
+}
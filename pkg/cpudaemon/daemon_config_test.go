@@ -0,0 +1,62 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+func TestNumaNamespaceAllocatorConfig(t *testing.T) {
+	s := getTestDaemonState(t.TempDir(), 4)
+	allocator := newMockedNumaPerNamespaceAllocator(2, true)
+	allocator.EnableBucketWeights()
+	allocator.SetNamespaceMemoryNodes(map[string]string{"team-b": "1"})
+	allocator.NamespaceToBucket = map[string]int{"team-b": 0, "team-a": 0}
+
+	config := allocator.config(s)
+
+	assert.Equal(t, ctlplaneapi.AllocatorConfig{
+		Name:          "numa-namespace-exclusive",
+		Exclusive:     true,
+		MemoryPinning: true,
+		BucketWeights: true,
+		Buckets: []ctlplaneapi.CPUBucketConfig{
+			{Bucket: 0, CPUSet: []ctlplaneapi.CPUBucket{{StartCPU: 0, EndCPU: 1}}, Namespaces: []string{"team-a", "team-b"}},
+			{Bucket: 1, CPUSet: []ctlplaneapi.CPUBucket{{StartCPU: 2, EndCPU: 3}}},
+		},
+		NamespaceMemoryNodes: map[string]string{"team-b": "1"},
+	}, config)
+}
+
+func TestGetConfig(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	d, err := New(
+		"testdata/no_state", "testdata/node_info", daemonStateFile,
+		NewStaticPolocy(NewNumaAwareAllocator(&CgroupsMock{}, true)), logr.Discard(),
+		WithExcludedCpus(CPUSet{8: {}}),
+	)
+	require.Nil(t, err)
+
+	config, err := d.GetConfig(context.Background(), &ctlplaneapi.GetConfigRequest{})
+
+	require.Nil(t, err)
+	assert.Equal(t, &ctlplaneapi.AllocatorConfig{Name: "numa", Exclusive: true, MemoryPinning: true}, config.Allocator)
+	assert.Equal(t, []ctlplaneapi.CPUBucket{{StartCPU: 8, EndCPU: 8}}, config.ExcludedCPUs)
+}
+
+func TestGetConfigWithoutAllocatorConfig(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &MockedPolicy{}, logr.Discard())
+	require.Nil(t, err)
+
+	config, err := d.GetConfig(context.Background(), &ctlplaneapi.GetConfigRequest{})
+
+	require.Nil(t, err)
+	assert.Nil(t, config.Allocator)
+}
@@ -0,0 +1,86 @@
+package cpudaemon
+
+import (
+	"context"
+	"errors"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// Reasons of container results which are not failures.
+const (
+	resultAllocated  = "allocated"
+	resultUpdated    = "updated"
+	resultDeleted    = "deleted"
+	resultUnchanged  = "unchanged"
+	resultAborted    = "request aborted"
+	resultRolledBack = "pod creation rolled back"
+)
+
+// containerResults collects results of containers processed by a pod request, by container id.
+type containerResults map[string]ctlplaneapi.ContainerResult
+
+// add records results of processed containers. Containers listed in err failed, or were skipped if the
+// request was aborted before they were processed; the others succeeded with the given reason.
+func (r containerResults) add(cids []string, err error, reason string) {
+	failed := failedContainersErrors{}
+	errors.As(err, &failed)
+	errs := make(map[string]error, len(failed))
+	for _, f := range failed {
+		errs[f.cid] = f.err
+	}
+	for _, cid := range cids {
+		res := ctlplaneapi.ContainerResult{ContainerID: cid, Status: ctlplaneapi.ContainerStatus_SUCCESS, Reason: reason}
+		if err, ok := errs[cid]; ok {
+			res.Status, res.Reason = failedResult(err)
+		}
+		r[cid] = res
+	}
+}
+
+// ordered returns results of containers in the given order. Containers without recorded result were
+// skipped for the given reason.
+func (r containerResults) ordered(cids []string, reason string) []ctlplaneapi.ContainerResult {
+	res := make([]ctlplaneapi.ContainerResult, 0, len(cids))
+	for _, cid := range cids {
+		result, ok := r[cid]
+		if !ok {
+			result = ctlplaneapi.ContainerResult{ContainerID: cid, Status: ctlplaneapi.ContainerStatus_SKIPPED, Reason: reason}
+		}
+		res = append(res, result)
+	}
+	return res
+}
+
+// failedResult returns status and reason of the container which could not be processed. Containers not
+// processed because the request was aborted are skipped, the agent repeats the request.
+func failedResult(err error) (ctlplaneapi.ContainerStatus, string) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return ctlplaneapi.ContainerStatus_SKIPPED, resultAborted
+	}
+	return ctlplaneapi.ContainerStatus_FAILED, err.Error()
+}
+
+func containerIDs(containers []Container) []string {
+	cids := make([]string, 0, len(containers))
+	for _, c := range containers {
+		cids = append(cids, c.CID)
+	}
+	return cids
+}
+
+func updatedContainerIDs(updated []containerUpdated) []string {
+	cids := make([]string, 0, len(updated))
+	for _, it := range updated {
+		cids = append(cids, it.wanted.CID)
+	}
+	return cids
+}
+
+func requestContainerIDs(containers []*ctlplaneapi.ContainerInfo) []string {
+	cids := make([]string, 0, len(containers))
+	for _, c := range containers {
+		cids = append(cids, c.ContainerId)
+	}
+	return cids
+}
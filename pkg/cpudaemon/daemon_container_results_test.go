@@ -0,0 +1,96 @@
+package cpudaemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+func TestContainerResults(t *testing.T) {
+	r := containerResults{}
+	r.add([]string{"c1", "c2", "c3"}, failedContainersErrors{
+		{"c2", errors.New("no cpus")},
+		{"c3", context.Canceled},
+	}, resultAllocated)
+
+	assert.Equal(t, []ctlplaneapi.ContainerResult{
+		{ContainerID: "c0", Status: ctlplaneapi.ContainerStatus_SKIPPED, Reason: resultUnchanged},
+		{ContainerID: "c1", Status: ctlplaneapi.ContainerStatus_SUCCESS, Reason: resultAllocated},
+		{ContainerID: "c2", Status: ctlplaneapi.ContainerStatus_FAILED, Reason: "no cpus"},
+		{ContainerID: "c3", Status: ctlplaneapi.ContainerStatus_SKIPPED, Reason: resultAborted},
+	}, r.ordered([]string{"c0", "c1", "c2", "c3"}, resultUnchanged))
+}
+
+func statuses(results []ctlplaneapi.ContainerResult) []ctlplaneapi.ContainerStatus {
+	res := []ctlplaneapi.ContainerStatus{}
+	for _, r := range results {
+		res = append(res, r.Status)
+	}
+	return res
+}
+
+func TestCreatePodReportsContainerResults(t *testing.T) {
+	d := newDaemonForCriticalContainersTest(t)
+	p, req := createCriticalPodRequest("")
+	req.Containers = req.Containers[:2]
+
+	reply, err := d.CreatePod(context.Background(), req)
+
+	require.Nil(t, err)
+	assert.Equal(t, []ctlplaneapi.ContainerResult{
+		{ContainerID: p.containers[0].CID, Status: ctlplaneapi.ContainerStatus_SUCCESS, Reason: resultAllocated},
+		{ContainerID: p.containers[1].CID, Status: ctlplaneapi.ContainerStatus_SUCCESS, Reason: resultAllocated},
+	}, reply.ContainerResults)
+}
+
+func TestFailedCreatePodReportsContainerResults(t *testing.T) {
+	d := newDaemonForCriticalContainersTest(t)
+	p, req := createCriticalPodRequest("")
+
+	// containers are allocated from the biggest one, the second one exceeds the cap of exclusive cpus
+	reply, err := d.CreatePod(context.Background(), req)
+
+	require.NotNil(t, err)
+	require.NotNil(t, reply)
+	require.Len(t, reply.ContainerResults, 3)
+	assert.Equal(t, []ctlplaneapi.ContainerStatus{
+		ctlplaneapi.ContainerStatus_SKIPPED,
+		ctlplaneapi.ContainerStatus_FAILED,
+		ctlplaneapi.ContainerStatus_SKIPPED,
+	}, statuses(reply.ContainerResults))
+	assert.Equal(t, p.containers[1].CID, reply.ContainerResults[1].ContainerID)
+	assert.Contains(t, reply.ContainerResults[1].Reason, "cannot allocate 2 exclusive cpus")
+	assert.Equal(t, resultRolledBack, reply.ContainerResults[0].Reason)
+	assert.Empty(t, reply.ContainerResources)
+}
+
+func TestFailedUpdatePodReportsContainerResults(t *testing.T) {
+	d := newDaemonForCriticalContainersTest(t)
+	p, req := createCriticalPodRequest("")
+	all := req.Containers
+	req.Containers = []*ctlplaneapi.ContainerInfo{all[0], all[2]}
+	_, err := d.CreatePod(context.Background(), req)
+	require.Nil(t, err)
+
+	// the first container is deleted, the added one does not fit next to the 3 cpus container
+	reply, err := d.UpdatePod(context.Background(), &ctlplaneapi.UpdatePodRequest{
+		PodId:      req.PodId,
+		Resources:  req.Resources,
+		Containers: []*ctlplaneapi.ContainerInfo{all[1], all[2]},
+	})
+
+	require.NotNil(t, err)
+	require.NotNil(t, reply)
+	require.Len(t, reply.ContainerResults, 3)
+	assert.Equal(t, p.containers[1].CID, reply.ContainerResults[0].ContainerID)
+	assert.Equal(t, ctlplaneapi.ContainerStatus_FAILED, reply.ContainerResults[0].Status)
+	assert.Contains(t, reply.ContainerResults[0].Reason, "cannot allocate 2 exclusive cpus")
+	assert.Equal(t, []ctlplaneapi.ContainerResult{
+		{ContainerID: p.containers[2].CID, Status: ctlplaneapi.ContainerStatus_SKIPPED, Reason: resultUnchanged},
+		{ContainerID: p.containers[0].CID, Status: ctlplaneapi.ContainerStatus_SUCCESS, Reason: resultDeleted},
+	}, reply.ContainerResults[1:])
+}
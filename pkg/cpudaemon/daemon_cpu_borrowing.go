@@ -0,0 +1,354 @@
+package cpudaemon
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+// borrowingIdleUsage is the usage of exclusive cpus (fraction of their time) below which their guaranteed
+// owner is considered idle, so that its cpus can be lent to throttled containers.
+const borrowingIdleUsage = 0.1
+
+// CPUBorrowingPolicy is implemented by policies able to lend exclusive cpus of guaranteed containers to
+// throttled burstable containers sharing cpus of the same bucket.
+type CPUBorrowingPolicy interface {
+	LendableCpus(c Container, s *DaemonState) CPUSet
+	SetSharedCpus(ctx context.Context, c Container, cpus CPUSet, s *DaemonState) error
+}
+
+var _ CPUBorrowingPolicy = &StaticPolicy{}
+
+// borrowingAllocator is implemented by allocators able to lend exclusive cpus to shared containers.
+type borrowingAllocator interface {
+	lendableCpus(c Container, s *DaemonState) CPUSet
+	setSharedCpus(ctx context.Context, c Container, cpus CPUSet, s *DaemonState) error
+}
+
+var _ borrowingAllocator = &NumaPerNamespaceAllocator{}
+
+// cpuStat holds counters of cpu.stat file of a cgroup.
+type cpuStat struct {
+	usage     uint64 // cpu time used, in microseconds
+	periods   uint64 // enforcement periods of cpu quota
+	throttled uint64 // periods in which the cgroup was throttled
+}
+
+// cpuStatSample is the last cpu.stat of a container, together with the outcome of its checks.
+type cpuStatSample struct {
+	stat            cpuStat
+	at              time.Time
+	throttledChecks int  // consecutive checks in which the container was throttled
+	calmChecks      int  // consecutive checks in which the container was not throttled
+	idle            bool // exclusive cpus of the container were idle since the previous check
+}
+
+// RunCPUBorrowing checks every interval, until stop is closed, throttling of burstable containers and
+// usage of exclusive cpus, and lends idle exclusive cpus to persistently throttled containers. Borrowed
+// cpus are returned as soon as their owners use them. It does nothing unless enabled by WithCPUBorrowing.
+func (d *Daemon) RunCPUBorrowing(interval time.Duration, stop <-chan struct{}) {
+	if !d.options.cpuBorrowing || interval <= 0 {
+		return
+	}
+	d.stateMu.Lock()
+	_, ok := d.policy.(CPUBorrowingPolicy)
+	d.stateMu.Unlock()
+	if !ok {
+		d.logger.Info("cpu borrowing is not supported by the policy")
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			d.borrowCpus(now)
+		}
+	}
+}
+
+// checkCPUBorrowingSupport disables cpu borrowing on cgroups v1, where throttling and usage of
+// containers are kept in hierarchies other than cpuset.
+func (d *Daemon) checkCPUBorrowingSupport() {
+	if d.options.cpuBorrowing && !d.state.CgroupVersion.unified() {
+		d.logger.Info("cpu borrowing is supported only with cgroups v2, exclusive cpus are not lent")
+		d.options.cpuBorrowing = false
+	}
+}
+
+// borrowCpus samples cpu.stat of managed containers and updates cpus borrowed by burstable containers:
+// cpus of owners which are not idle anymore are returned first, then containers throttled in
+// borrowingChecks consecutive checks borrow idle exclusive cpus of their bucket. Containers not throttled
+// in as many checks return all borrowed cpus.
+func (d *Daemon) borrowCpus(now time.Time) {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	p, ok := d.policy.(CPUBorrowingPolicy)
+	if !ok {
+		return
+	}
+
+	d.state.reconcileBorrowedCpus()
+	d.sampleCPUStats(now)
+	idle := d.idleExclusiveCpus()
+
+	changed := false
+	for _, c := range d.state.burstableContainers() {
+		borrowed := CPUSetFromBucketList(d.state.Borrowed[c.CID])
+		wanted := d.wantedBorrowedCpus(p, c, borrowed, idle)
+		if wanted.ToCpuString() == borrowed.ToCpuString() {
+			continue
+		}
+		cpus := CPUSetFromBucketList(d.state.Allocated[c.CID]).RemoveAll(borrowed).Merge(wanted)
+		if err := p.SetSharedCpus(context.Background(), c, cpus, &d.state); err != nil {
+			d.logger.Error(err, "cannot update borrowed cpus", "cid", c.CID, "borrowed", wanted)
+			continue
+		}
+		reclaimed := borrowed.Clone().RemoveAll(idle)
+		d.logger.Info("borrowed cpus updated", "cid", c.CID, "borrowed", wanted, "reclaimed", reclaimed)
+		metrics.ReclaimedCpus.Add(float64(reclaimed.Count()))
+		d.state.setBorrowedCpus(c.CID, wanted)
+		changed = true
+	}
+	metrics.BorrowedCpus.Set(float64(d.state.borrowedCpus().Count()))
+	if !changed {
+		return
+	}
+	if err := d.saveState(); err != nil {
+		d.logger.Error(err, "cannot save state")
+	}
+}
+
+// wantedBorrowedCpus returns cpus the container shall borrow: borrowed cpus whose owners are still idle,
+// together with all idle lendable cpus if the container is persistently throttled.
+func (d *Daemon) wantedBorrowedCpus(p CPUBorrowingPolicy, c Container, borrowed, idle CPUSet) CPUSet {
+	sample := d.cpuStats[c.CID]
+	wanted := CPUSet{}
+	if sample.calmChecks >= d.options.borrowingChecks {
+		return wanted
+	}
+	lendable := p.LendableCpus(c, &d.state)
+	for cpu := range borrowed {
+		if idle.Contains(cpu) && lendable.Contains(cpu) {
+			wanted.Add(cpu)
+		}
+	}
+	if sample.throttledChecks >= d.options.borrowingChecks {
+		for cpu := range lendable {
+			if idle.Contains(cpu) {
+				wanted.Add(cpu)
+			}
+		}
+	}
+	return wanted
+}
+
+// sampleCPUStats reads cpu.stat of managed containers and updates their throttling and idleness. Samples
+// of containers which are not managed anymore are forgotten.
+func (d *Daemon) sampleCPUStats(now time.Time) {
+	samples := make(map[string]cpuStatSample, len(d.cpuStats))
+	for _, pod := range d.state.Pods {
+		for _, c := range allocatedContainers(pod) {
+			dir := d.state.getCgroupPath(c.CID)
+			if _, ok := d.state.Allocated[c.CID]; !ok || dir == "" {
+				continue
+			}
+			stat, err := readCPUStat(dir)
+			if err != nil {
+				d.logger.V(2).Info("cannot read cpu.stat", "cid", c.CID, "error", err.Error())
+				continue
+			}
+			sample := cpuStatSample{stat: stat, at: now}
+			if prev, ok := d.cpuStats[c.CID]; ok {
+				sample.throttledChecks, sample.calmChecks = prev.throttledChecks, prev.calmChecks
+				d.checkCPUStat(c, prev, &sample)
+			}
+			samples[c.CID] = sample
+		}
+	}
+	d.cpuStats = samples
+}
+
+// checkCPUStat updates throttling of burstable containers and idleness of guaranteed ones since the
+// previous sample.
+func (d *Daemon) checkCPUStat(c Container, prev cpuStatSample, sample *cpuStatSample) {
+	switch c.QS {
+	case Burstable:
+		periods := sample.stat.periods - prev.stat.periods
+		throttled := sample.stat.throttled - prev.stat.throttled
+		if periods > 0 && float64(throttled)/float64(periods) >= d.options.borrowingThrottling {
+			sample.throttledChecks++
+			sample.calmChecks = 0
+		} else {
+			sample.calmChecks++
+			sample.throttledChecks = 0
+		}
+	case Guaranteed:
+		cpus := CPUSetFromBucketList(d.state.Allocated[c.CID]).Count()
+		elapsed := sample.at.Sub(prev.at).Microseconds()
+		if cpus == 0 || elapsed <= 0 || sample.stat.usage < prev.stat.usage {
+			return
+		}
+		usage := float64(sample.stat.usage-prev.stat.usage) / float64(elapsed*int64(cpus))
+		sample.idle = usage < borrowingIdleUsage
+	}
+}
+
+// idleExclusiveCpus returns exclusive cpus of guaranteed containers which were idle since the previous
+// check.
+func (d *Daemon) idleExclusiveCpus() CPUSet {
+	idle := CPUSet{}
+	for _, pod := range d.state.Pods {
+		for _, c := range allocatedContainers(pod) {
+			if c.QS == Guaranteed && d.cpuStats[c.CID].idle {
+				idle.Merge(CPUSetFromBucketList(d.state.Allocated[c.CID]))
+			}
+		}
+	}
+	return idle
+}
+
+// readCPUStat reads usage and throttling counters from cpu.stat file of cgroups v2 in given cgroup
+// directory.
+func readCPUStat(dir string) (cpuStat, error) {
+	f, err := os.Open(filepath.Join(dir, "cpu.stat"))
+	if err != nil {
+		return cpuStat{}, err
+	}
+	defer f.Close()
+
+	stat := cpuStat{}
+	fields := map[string]*uint64{
+		"usage_usec":   &stat.usage,
+		"nr_periods":   &stat.periods,
+		"nr_throttled": &stat.throttled,
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), " ")
+		field, known := fields[key]
+		if !ok || !known {
+			continue
+		}
+		if *field, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return cpuStat{}, fmt.Errorf("cannot parse %s of cpu.stat in %s: %w", key, dir, err)
+		}
+	}
+	return stat, scanner.Err()
+}
+
+// burstableContainers returns allocated burstable containers, ordered by ids.
+func (d *DaemonState) burstableContainers() []Container {
+	containers := []Container{}
+	for _, pod := range d.Pods {
+		for _, c := range allocatedContainers(pod) {
+			if _, ok := d.Allocated[c.CID]; ok && c.QS == Burstable {
+				containers = append(containers, c)
+			}
+		}
+	}
+	sort.Slice(containers, func(i, j int) bool { return containers[i].CID < containers[j].CID })
+	return containers
+}
+
+// setBorrowedCpus records exclusive cpus lent to the container, which are also part of its allocation.
+func (d *DaemonState) setBorrowedCpus(cid string, cpus CPUSet) {
+	if cpus.Count() == 0 {
+		delete(d.Borrowed, cid)
+		return
+	}
+	if d.Borrowed == nil {
+		d.Borrowed = make(map[string][]ctlplaneapi.CPUBucket)
+	}
+	d.Borrowed[cid] = cpus.ToBucketList()
+}
+
+// borrowedCpus returns exclusive cpus lent to any container.
+func (d *DaemonState) borrowedCpus() CPUSet {
+	cpus := CPUSet{}
+	for _, borrowed := range d.Borrowed {
+		cpus.Merge(CPUSetFromBucketList(borrowed))
+	}
+	return cpus
+}
+
+// reconcileBorrowedCpus forgets borrowed cpus which are not allocated to the borrowing container anymore
+// (eg. removed when a guaranteed container took them) or are not exclusive anymore (eg. returned to the
+// shared pool when their owner was deleted).
+func (d *DaemonState) reconcileBorrowedCpus() {
+	exclusive := d.exclusiveCpus()
+	for cid, buckets := range d.Borrowed {
+		allocated := CPUSetFromBucketList(d.Allocated[cid])
+		kept := CPUSet{}
+		for cpu := range CPUSetFromBucketList(buckets) {
+			if allocated.Contains(cpu) && exclusive.Contains(cpu) {
+				kept.Add(cpu)
+			}
+		}
+		d.setBorrowedCpus(cid, kept)
+	}
+}
+
+// LendableCpus returns exclusive cpus which can be lent to the container, if the allocator supports it.
+func (p *StaticPolicy) LendableCpus(c Container, s *DaemonState) CPUSet {
+	a, ok := p.allocator.(borrowingAllocator)
+	if !ok {
+		return CPUSet{}
+	}
+	return a.lendableCpus(c, s)
+}
+
+// SetSharedCpus sets cpus of the shared container, including borrowed ones, if the allocator supports
+// cpu borrowing.
+func (p *StaticPolicy) SetSharedCpus(ctx context.Context, c Container, cpus CPUSet, s *DaemonState) error {
+	a, ok := p.allocator.(borrowingAllocator)
+	if !ok {
+		return DaemonError{
+			ErrorType:    NotImplemented,
+			ErrorMessage: "cpu borrowing is not supported by the allocator",
+		}
+	}
+	return a.setSharedCpus(ctx, c, cpus, s)
+}
+
+// lendableCpus returns cpus of the namespace bucket of the burstable container taken exclusively by
+// guaranteed containers. Only exclusive allocators lend cpus, as shared containers of other allocators
+// use all cpus of their bucket anyway. Soft pinned containers do not borrow cpus.
+func (d *NumaPerNamespaceAllocator) lendableCpus(c Container, s *DaemonState) CPUSet {
+	cpus := CPUSet{}
+	if !d.exclusive || c.QS != Burstable || d.softPinned(c) {
+		return cpus
+	}
+	bucket, err := d.getBucket(s, c.Namespace)
+	if err != nil {
+		return cpus
+	}
+	for _, cpu := range bucket {
+		if !cpu.Available() {
+			cpus.Add(cpu.Value)
+		}
+	}
+	return cpus
+}
+
+// setSharedCpus updates cpus of the shared container. The container keeps its cpus if the update fails.
+func (d *NumaPerNamespaceAllocator) setSharedCpus(ctx context.Context, c Container, cpus CPUSet, s *DaemonState) error {
+	original := s.Allocated[c.CID]
+	s.Allocated[c.CID] = cpus.ToBucketList()
+	if err := updateContainerCPUSet(ctx, d.ctrl, s, c, cpus.ToCpuString(), d.memoryNodes(c, s, cpus)); err != nil {
+		s.Allocated[c.CID] = original
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package cpudaemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+const (
+	ownerCid     = "testCid-0"
+	burstableCid = "burstableCid"
+)
+
+func newDaemonForBorrowingTest(t *testing.T) (*Daemon, *CgroupsMock) {
+	m := newMockedCgroups()
+	d := newTestDaemon(
+		t, NewStaticPolocy(NewNumaPerNamespaceAllocator(1, m, true, false, logr.Discard())),
+		WithCPUBorrowing(0.5, 2),
+		WithCgroupVersion(CgroupV2),
+	)
+	return d, m
+}
+
+// createBorrowingPods creates guaranteed pod with one exclusive cpu and burstable pod in the same
+// namespace, with cgroups in temporary directories. Returns cgroup directories of both containers.
+func createBorrowingPods(t *testing.T, d *Daemon) (string, string) {
+	owner := createTestPod(1)
+	_, err := d.CreatePod(context.Background(), createPodRequest(owner))
+	require.Nil(t, err)
+
+	burstable := createPodRequest(createTestPod(1))
+	burstable.PodId = "burstablePid"
+	burstable.Containers[0].ContainerId = burstableCid
+	burstable.Containers[0].Resources = &ctlplaneapi.ResourceInfo{
+		RequestedCpus:   1,
+		LimitCpus:       2,
+		RequestedMemory: newQuantityAsBytes(8),
+		LimitMemory:     newQuantityAsBytes(8),
+	}
+	burstable.Resources = burstable.Containers[0].Resources
+	_, err = d.CreatePod(context.Background(), burstable)
+	require.Nil(t, err)
+
+	ownerDir, burstableDir := t.TempDir(), t.TempDir()
+	d.state.setCgroupPath(ownerCid, ownerDir)
+	d.state.setCgroupPath(burstableCid, burstableDir)
+	return ownerDir, burstableDir
+}
+
+func writeCPUStat(t *testing.T, dir string, usage, periods, throttled uint64) {
+	content := fmt.Sprintf(
+		"usage_usec %d\nuser_usec 0\nsystem_usec 0\nnr_periods %d\nnr_throttled %d\nthrottled_usec 0\n",
+		usage, periods, throttled,
+	)
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "cpu.stat"), []byte(content), 0o644))
+}
+
+// borrowingCheck writes cpu.stat of both containers and runs the check second after the previous one.
+func borrowingCheck(t *testing.T, d *Daemon, now *time.Time, ownerDir, burstableDir string, stats ...uint64) {
+	writeCPUStat(t, ownerDir, stats[0], 0, 0)
+	writeCPUStat(t, burstableDir, 0, stats[1], stats[2])
+	*now = now.Add(time.Second)
+	d.borrowCpus(*now)
+}
+
+func TestThrottledContainerBorrowsIdleCpus(t *testing.T) {
+	d, m := newDaemonForBorrowingTest(t)
+	ownerDir, burstableDir := createBorrowingPods(t, d)
+	ownerCpus := CPUSetFromBucketList(d.state.Allocated[ownerCid])
+	shared := CPUSetFromBucketList(d.state.Allocated[burstableCid])
+	require.False(t, shared.Contains(ownerCpus.Sorted()[0]))
+	now := time.Now()
+
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 0, 0, 0)
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 1000, 100, 80)
+	assert.Empty(t, d.state.Borrowed, "throttling shall persist")
+
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 2000, 200, 160)
+
+	expanded := shared.Clone().Merge(ownerCpus)
+	assert.Equal(t, expanded, CPUSetFromBucketList(d.state.Allocated[burstableCid]))
+	assert.Equal(t, ownerCpus, CPUSetFromBucketList(d.state.Borrowed[burstableCid]))
+	assert.Equal(t, ownerCpus, CPUSetFromBucketList(d.state.Allocated[ownerCid]), "owner keeps its cpus")
+	assert.Equal(t, d.state.Borrowed, d.readableState().Borrowed)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.BorrowedCpus))
+	m.AssertCalled(t, "UpdateCPUSet", mock.Anything, mock.MatchedBy(func(c Container) bool {
+		return c.CID == burstableCid
+	}), expanded.ToCpuString(), "")
+}
+
+func TestBorrowedCpusAreReclaimedByOwner(t *testing.T) {
+	d, _ := newDaemonForBorrowingTest(t)
+	ownerDir, burstableDir := createBorrowingPods(t, d)
+	shared := CPUSetFromBucketList(d.state.Allocated[burstableCid])
+	now := time.Now()
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 0, 0, 0)
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 0, 100, 80)
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 0, 200, 160)
+	require.NotEmpty(t, d.state.Borrowed)
+	reclaimed := testutil.ToFloat64(metrics.ReclaimedCpus)
+
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 900000, 300, 240)
+
+	assert.Empty(t, d.state.Borrowed)
+	assert.Equal(t, shared, CPUSetFromBucketList(d.state.Allocated[burstableCid]))
+	assert.Equal(t, reclaimed+1, testutil.ToFloat64(metrics.ReclaimedCpus))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.BorrowedCpus))
+}
+
+func TestBorrowedCpusAreReturnedWhenThrottlingStops(t *testing.T) {
+	d, _ := newDaemonForBorrowingTest(t)
+	ownerDir, burstableDir := createBorrowingPods(t, d)
+	shared := CPUSetFromBucketList(d.state.Allocated[burstableCid])
+	now := time.Now()
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 0, 0, 0)
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 0, 100, 80)
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 0, 200, 160)
+	require.NotEmpty(t, d.state.Borrowed)
+
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 0, 300, 160)
+	assert.NotEmpty(t, d.state.Borrowed, "cpus shall be kept until throttling stops for the number of checks")
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 0, 400, 170)
+
+	assert.Empty(t, d.state.Borrowed)
+	assert.Equal(t, shared, CPUSetFromBucketList(d.state.Allocated[burstableCid]))
+}
+
+func TestBorrowedCpusAreForgottenWithOwner(t *testing.T) {
+	d, _ := newDaemonForBorrowingTest(t)
+	ownerDir, burstableDir := createBorrowingPods(t, d)
+	ownerCpus := CPUSetFromBucketList(d.state.Allocated[ownerCid])
+	now := time.Now()
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 0, 0, 0)
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 0, 100, 80)
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 0, 200, 160)
+	require.NotEmpty(t, d.state.Borrowed)
+
+	_, err := d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: "testPid"})
+	require.Nil(t, err)
+	d.state.reconcileBorrowedCpus()
+
+	assert.Empty(t, d.state.Borrowed)
+	assert.True(t, CPUSetFromBucketList(d.state.Allocated[burstableCid]).Contains(ownerCpus.Sorted()[0]),
+		"freed cpus stay in the shared pool")
+}
+
+func TestBorrowingFailureKeepsAllocation(t *testing.T) {
+	d, m := newDaemonForBorrowingTest(t)
+	ownerDir, burstableDir := createBorrowingPods(t, d)
+	shared := d.state.Allocated[burstableCid]
+	now := time.Now()
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 0, 0, 0)
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 0, 100, 80)
+	m.ExpectedCalls = nil
+	m.On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("write error"))
+
+	borrowingCheck(t, d, &now, ownerDir, burstableDir, 0, 200, 160)
+
+	assert.Empty(t, d.state.Borrowed)
+	assert.Equal(t, shared, d.state.Allocated[burstableCid])
+}
+
+func TestReadCPUStat(t *testing.T) {
+	dir := t.TempDir()
+	writeCPUStat(t, dir, 1234, 50, 7)
+
+	stat, err := readCPUStat(dir)
+
+	require.Nil(t, err)
+	assert.Equal(t, cpuStat{usage: 1234, periods: 50, throttled: 7}, stat)
+	_, err = readCPUStat(t.TempDir())
+	assert.NotNil(t, err)
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "cpu.stat"), []byte("nr_periods x\n"), 0o644))
+	_, err = readCPUStat(dir)
+	assert.NotNil(t, err)
+}
+
+func TestCPUBorrowingOptions(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithCPUBorrowing(0, 1)},
+		{WithCPUBorrowing(1.5, 1)},
+		{WithCPUBorrowing(0.5, 0)},
+		{WithCPUBorrowing(0.5, 1), WithSharedPoolCgroups()},
+	} {
+		o := newDaemonOptions(opts)
+		assert.NotNil(t, o.validate())
+	}
+	o := newDaemonOptions([]Option{WithCPUBorrowing(0.5, 1)})
+	assert.Nil(t, o.validate())
+}
+
+func TestCPUBorrowingRequiresCgroupsV2(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	d, err := New(
+		"testdata/no_state", "testdata/node_info", daemonStateFile,
+		NewStaticPolocy(NewDefaultAllocator(&CgroupsMock{})), logr.Discard(),
+		WithCPUBorrowing(0.5, 2),
+		WithCgroupVersion(CgroupV1),
+	)
+	require.Nil(t, err)
+
+	assert.False(t, d.options.cpuBorrowing)
+}
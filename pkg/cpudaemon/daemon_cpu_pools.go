@@ -0,0 +1,194 @@
+package cpudaemon
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// CPUPool is a set of cpus of a single numa node reserved for workloads running outside of kubernetes.
+type CPUPool struct {
+	NumaNode int
+	CPUs     []ctlplaneapi.CPUBucket
+}
+
+func (p CPUPool) info(name string) ctlplaneapi.CPUPoolInfo {
+	return ctlplaneapi.CPUPoolInfo{
+		Name:     name,
+		NumaNode: p.NumaNode,
+		CPUSet:   cloneBuckets(p.CPUs),
+	}
+}
+
+// ReserveCPUPool reserves named pool of cpus which no pod allocation may use, so that workloads running
+// outside of kubernetes, eg. DPDK applications, can coordinate with the daemon instead of competing for
+// cpus. Reserving an existing pool returns it, if it matches the request.
+func (d *Daemon) ReserveCPUPool(
+	_ context.Context,
+	req *ctlplaneapi.ReserveCPUPoolRequest,
+) (*ctlplaneapi.CPUPoolInfo, error) {
+	if err := ctlplaneapi.ValidateReserveCPUPoolRequest(req); err != nil {
+		d.logger.Error(err, "validation error")
+		return nil, DaemonError{ErrorType: PodSpecError, ErrorMessage: err.Error()}
+	}
+
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	defer d.traceRequest(traceReserveCPUPool, req, d.traceHash())
+
+	if pool, ok := d.state.CPUPools[req.Name]; ok {
+		if CPUSetFromBucketList(pool.CPUs).Count() != int(req.Cpus) || !allowedNode(req.NumaNodes, pool.NumaNode) {
+			return nil, DaemonError{
+				ErrorType: CPUPoolConflict,
+				ErrorMessage: fmt.Sprintf(
+					"cpu pool %s is already reserved with cpus %s on numa node %d",
+					req.Name,
+					CPUSetFromBucketList(pool.CPUs),
+					pool.NumaNode,
+				),
+			}
+		}
+		info := pool.info(req.Name)
+		return &info, nil
+	}
+	pool, err := d.state.reserveCPUPool(int(req.Cpus), req.NumaNodes)
+	if err != nil {
+		return nil, err
+	}
+	if d.state.CPUPools == nil {
+		d.state.CPUPools = make(map[string]CPUPool)
+	}
+	d.state.CPUPools[req.Name] = pool
+	d.logger.Info("reserved cpu pool", "name", req.Name, "node", pool.NumaNode, "cpus", CPUSetFromBucketList(pool.CPUs))
+
+	if err := d.saveState(); err != nil {
+		return nil, *err
+	}
+	info := pool.info(req.Name)
+	return &info, nil
+}
+
+// ReleaseCPUPool releases the reserved cpu pool, its cpus become available for pod allocations again.
+func (d *Daemon) ReleaseCPUPool(
+	_ context.Context,
+	req *ctlplaneapi.ReleaseCPUPoolRequest,
+) (*ctlplaneapi.CPUPoolInfo, error) {
+	if err := ctlplaneapi.ValidateReleaseCPUPoolRequest(req); err != nil {
+		d.logger.Error(err, "validation error")
+		return nil, DaemonError{ErrorType: PodSpecError, ErrorMessage: err.Error()}
+	}
+
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	defer d.traceRequest(traceReleaseCPUPool, req, d.traceHash())
+
+	pool, ok := d.state.CPUPools[req.Name]
+	if !ok {
+		return nil, DaemonError{
+			ErrorType:    CPUPoolNotFound,
+			ErrorMessage: fmt.Sprintf("cpu pool %s is not reserved", req.Name),
+		}
+	}
+	d.state.releaseCPUPool(req.Name)
+	d.logger.Info("released cpu pool", "name", req.Name, "cpus", CPUSetFromBucketList(pool.CPUs))
+
+	if err := d.saveState(); err != nil {
+		return nil, *err
+	}
+	info := pool.info(req.Name)
+	info.Released = true
+	return &info, nil
+}
+
+// reserveCPUPool takes given number of free cpus from the allowed numa node with the most free cpus,
+// any node if none is listed. Cpus are taken in topology order, so that threads of a core stay together.
+func (d *DaemonState) reserveCPUPool(n int, nodes []uint32) (CPUPool, error) {
+	if d.Topology.Topology == nil {
+		return CPUPool{}, DaemonError{ErrorType: UnknownTopology, ErrorMessage: "topology is not discovered"}
+	}
+	available := CPUSetFromBucketList(d.AvailableCPUs)
+	selected, free := -1, []int{}
+	for _, node := range d.Topology.Topology.Children {
+		if !allowedNode(nodes, node.Value) {
+			continue
+		}
+		cpus := []int{}
+		for _, leaf := range node.GetLeafs() {
+			if leaf.Available() && available.Contains(leaf.Value) {
+				cpus = append(cpus, leaf.Value)
+			}
+		}
+		if len(cpus) >= n && len(cpus) > len(free) {
+			selected, free = node.Value, cpus
+		}
+	}
+	if selected < 0 {
+		return CPUPool{}, DaemonError{
+			ErrorType:    CpusNotAvailable,
+			ErrorMessage: fmt.Sprintf("no allowed numa node has %d free cpus", n),
+		}
+	}
+
+	reserved := CPUSet{}
+	for _, cpu := range free[:n] {
+		if err := d.Topology.TakeCpu(cpu); err != nil {
+			return CPUPool{}, DaemonError{ErrorType: RuntimeError, ErrorMessage: err.Error()}
+		}
+		available.Remove(cpu)
+		reserved.Add(cpu)
+	}
+	d.AvailableCPUs = available.ToCompactBucketList()
+	return CPUPool{NumaNode: selected, CPUs: reserved.ToCompactBucketList()}, nil
+}
+
+// releaseCPUPool removes the pool and returns its cpus both to the list of available cpus and to the
+// topology.
+func (d *DaemonState) releaseCPUPool(name string) {
+	cpus := CPUSetFromBucketList(d.CPUPools[name].CPUs)
+	delete(d.CPUPools, name)
+	if len(d.CPUPools) == 0 {
+		d.CPUPools = nil
+	}
+	available := CPUSetFromBucketList(d.AvailableCPUs)
+	for cpu := range cpus {
+		available.Add(cpu)
+		_ = d.Topology.Return(cpu)
+	}
+	d.AvailableCPUs = available.ToCompactBucketList()
+}
+
+// cpuPoolCpus returns cpus of all reserved pools.
+func (d *DaemonState) cpuPoolCpus() CPUSet {
+	cpus := CPUSet{}
+	for _, pool := range d.CPUPools {
+		cpus.Merge(CPUSetFromBucketList(pool.CPUs))
+	}
+	return cpus
+}
+
+// sortedCPUPools returns reserved cpu pools sorted by name.
+func (d *DaemonState) sortedCPUPools() []ctlplaneapi.CPUPoolInfo {
+	pools := make([]ctlplaneapi.CPUPoolInfo, 0, len(d.CPUPools))
+	for name, pool := range d.CPUPools {
+		pools = append(pools, pool.info(name))
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].Name < pools[j].Name
+	})
+	return pools
+}
+
+// allowedNode checks if the numa node is listed, all nodes are allowed by an empty list.
+func allowedNode(nodes []uint32, node int) bool {
+	if len(nodes) == 0 {
+		return true
+	}
+	for _, n := range nodes {
+		if int(n) == node {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// overlapping returns number of cpus of a which are also in b.
+func overlapping(a []ctlplaneapi.CPUBucket, b CPUSet) int {
+	cpus := CPUSetFromBucketList(a)
+	return cpus.Count() - cpus.Clone().RemoveAll(b).Count()
+}
+
+func topologyAvailable(d *Daemon, cpus CPUSet) bool {
+	for _, leaf := range d.state.Topology.Topology.GetLeafs() {
+		if cpus.Contains(leaf.Value) && leaf.Available() {
+			return true
+		}
+	}
+	return false
+}
+
+func TestReserveCPUPool(t *testing.T) {
+	// node 0: cpus 1,3,5,7, node 1: cpus 2,4,6,8
+	d := newTestDaemon(t, NewStaticPolocy(NewDefaultAllocator(newMockedCgroups())))
+	ctx := context.Background()
+
+	pool, err := d.ReserveCPUPool(ctx, &ctlplaneapi.ReserveCPUPoolRequest{Name: "dpdk", Cpus: 3, NumaNodes: []uint32{1}})
+
+	require.Nil(t, err)
+	assert.Equal(t, "dpdk", pool.Name)
+	assert.Equal(t, 1, pool.NumaNode)
+	cpus := CPUSetFromBucketList(pool.CPUSet)
+	assert.Equal(t, 3, cpus.Count())
+	node1, err := CPUSetFromString("2,4,6,8")
+	require.Nil(t, err)
+	assert.Equal(t, 3, overlapping(pool.CPUSet, node1))
+	assert.Zero(t, overlapping(d.state.AvailableCPUs, cpus))
+	assert.False(t, topologyAvailable(d, cpus))
+	assert.Zero(t, overlapping(d.state.sharedPool().ToCompactBucketList(), cpus))
+
+	again, err := d.ReserveCPUPool(ctx, &ctlplaneapi.ReserveCPUPoolRequest{Name: "dpdk", Cpus: 3})
+	require.Nil(t, err)
+	assert.Equal(t, pool, again)
+
+	_, err = d.ReserveCPUPool(ctx, &ctlplaneapi.ReserveCPUPoolRequest{Name: "dpdk", Cpus: 2})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	_, err = d.ReserveCPUPool(ctx, &ctlplaneapi.ReserveCPUPoolRequest{Name: "dpdk", Cpus: 3, NumaNodes: []uint32{0}})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	_, err = d.ReserveCPUPool(ctx, &ctlplaneapi.ReserveCPUPoolRequest{Name: "vpp", Cpus: 2, NumaNodes: []uint32{1}})
+	assert.NotNil(t, err, "only one cpu left on node 1")
+
+	allocations, err := d.GetAllocations(ctx, &ctlplaneapi.GetAllocationsRequest{})
+	require.Nil(t, err)
+	assert.Equal(t, []ctlplaneapi.CPUPoolInfo{*pool}, allocations.CPUPools)
+}
+
+func TestReserveCPUPoolPrefersNodeWithMostFreeCpus(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewDefaultAllocator(newMockedCgroups())))
+	ctx := context.Background()
+	_, err := d.ReserveCPUPool(ctx, &ctlplaneapi.ReserveCPUPoolRequest{Name: "dpdk", Cpus: 2, NumaNodes: []uint32{0}})
+	require.Nil(t, err)
+
+	pool, err := d.ReserveCPUPool(ctx, &ctlplaneapi.ReserveCPUPoolRequest{Name: "vpp", Cpus: 2})
+
+	require.Nil(t, err)
+	assert.Equal(t, 1, pool.NumaNode)
+}
+
+func TestCPUPoolIsNotAllocatedToPods(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewDefaultAllocator(newMockedCgroups())))
+	ctx := context.Background()
+	pool, err := d.ReserveCPUPool(ctx, &ctlplaneapi.ReserveCPUPoolRequest{Name: "dpdk", Cpus: 4})
+	require.Nil(t, err)
+	p := createTestPod(2)
+
+	reply, err := d.CreatePod(ctx, createPodRequest(p))
+
+	require.Nil(t, err)
+	for _, c := range reply.ContainerResources {
+		assert.Zero(t, overlapping(c.CPUSet, CPUSetFromBucketList(pool.CPUSet)))
+	}
+}
+
+func TestReleaseCPUPool(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewDefaultAllocator(newMockedCgroups())))
+	ctx := context.Background()
+	pool, err := d.ReserveCPUPool(ctx, &ctlplaneapi.ReserveCPUPoolRequest{Name: "dpdk", Cpus: 2})
+	require.Nil(t, err)
+
+	restarted, err := New("testdata/no_state", "testdata/node_info", d.state.StatePath, &MockedPolicy{}, logr.Discard())
+	require.Nil(t, err)
+	assert.Contains(t, restarted.state.CPUPools, "dpdk", "pools shall be persisted")
+
+	released, err := d.ReleaseCPUPool(ctx, &ctlplaneapi.ReleaseCPUPoolRequest{Name: "dpdk"})
+
+	require.Nil(t, err)
+	assert.True(t, released.Released)
+	assert.Equal(t, pool.CPUSet, released.CPUSet)
+	cpus := CPUSetFromBucketList(pool.CPUSet)
+	assert.Equal(t, cpus.Count(), overlapping(d.state.AvailableCPUs, cpus))
+	assert.True(t, topologyAvailable(d, cpus))
+	assert.Nil(t, d.state.CPUPools)
+
+	_, err = d.ReleaseCPUPool(ctx, &ctlplaneapi.ReleaseCPUPoolRequest{Name: "dpdk"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -107,6 +107,21 @@ func (c CPUSet) ToBucketList() []ctlplaneapi.CPUBucket {
 	return newBuckets
 }
 
+// ToCompactBucketList converts CPUSet to CPUBucket list where consecutive cpuids are merged into
+// single bucket, sorted by cpuid.
+func (c CPUSet) ToCompactBucketList() []ctlplaneapi.CPUBucket {
+	newBuckets := []ctlplaneapi.CPUBucket{}
+	for _, cpu := range c.Sorted() {
+		last := len(newBuckets) - 1
+		if last >= 0 && newBuckets[last].EndCPU == cpu-1 {
+			newBuckets[last].EndCPU = cpu
+			continue
+		}
+		newBuckets = append(newBuckets, ctlplaneapi.CPUBucket{StartCPU: cpu, EndCPU: cpu})
+	}
+	return newBuckets
+}
+
 // Merge sums all cpus from two sets.
 func (c CPUSet) Merge(other CPUSet) CPUSet {
 	for cpu := range other {
@@ -142,3 +142,16 @@ func TestCPUSetFromStringWithNewline(t *testing.T) {
 
 	assert.Equal(t, []int{}, fst.Sorted())
 }
+
+func TestCPUSetToCompactBucketList(t *testing.T) {
+	cpuSet, err := CPUSetFromString("0,2-5,7,8,10")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []ctlplaneapi.CPUBucket{
+		{StartCPU: 0, EndCPU: 0},
+		{StartCPU: 2, EndCPU: 5},
+		{StartCPU: 7, EndCPU: 8},
+		{StartCPU: 10, EndCPU: 10},
+	}, cpuSet.ToCompactBucketList())
+	assert.Empty(t, CPUSet{}.ToCompactBucketList())
+}
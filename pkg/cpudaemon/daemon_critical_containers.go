@@ -0,0 +1,134 @@
+package cpudaemon
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+// CriticalContainersAnnotation lists names of critical containers of the pod, separated by commas (eg.
+// "app,db"). Critical containers are allocated before other containers of the pod.
+const CriticalContainersAnnotation = "ctlplane.intel.com/critical-containers"
+
+// criticalContainers returns names of critical containers listed in pod annotations.
+func criticalContainers(annotations map[string]string) map[string]struct{} {
+	critical := map[string]struct{}{}
+	for _, name := range strings.Split(annotations[CriticalContainersAnnotation], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			critical[name] = struct{}{}
+		}
+	}
+	return critical
+}
+
+// sortedByPriority returns critical containers followed by the other ones, both ordered by size as in
+// sortedBySize.
+func sortedByPriority(containers []Container, critical map[string]struct{}) []Container {
+	sorted := sortedBySize(containers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		_, ci := critical[sorted[i].Name]
+		_, cj := critical[sorted[j].Name]
+		return ci && !cj
+	})
+	return sorted
+}
+
+// sharedPoolFallbackAllowed checks if the container may run in the shared pool when its exclusive cpus do
+// not fit. Only guaranteed containers of pods listing critical containers can fall back, provided they
+// are not critical themselves.
+func (d *Daemon) sharedPoolFallbackAllowed(c Container, critical map[string]struct{}) bool {
+	if d.options.partialAllocationAction != PartialAllocationSharedPool || len(critical) == 0 {
+		return false
+	}
+	_, ok := critical[c.Name]
+	return !ok && c.QS == Guaranteed
+}
+
+// notFitting returns true if the assignment failed because there are not enough cpus for the container.
+func notFitting(err error) bool {
+	var daemonErr DaemonError
+	if !errors.As(err, &daemonErr) {
+		return false
+	}
+	switch daemonErr.ErrorType {
+	case CpusNotAvailable, ExclusiveCpusCapExceeded, SystemPressure:
+		return true
+	default:
+		return false
+	}
+}
+
+// assignPodContainer assigns the container of a pod. Guaranteed containers which do not fit are moved to
+// the shared pool if allowed by sharedPoolFallbackAllowed: they are assigned as burstable containers and
+// marked with SharedPoolFallback. Guaranteed containers of namespaces configured without exclusive cpus
+// are moved there at once. Returns the container as assigned.
+func (d *Daemon) assignPodContainer(ctx context.Context, c Container, critical map[string]struct{}) (Container, error) {
+	if c.QS == Guaranteed && !d.state.exclusiveNamespace(c.Namespace) {
+		c.QS, c.SharedPoolFallback = Burstable, true
+		if err := d.assignContainer(ctx, c); err != nil {
+			return c, err
+		}
+		d.state.setAllocationExplanation(c.CID, nonExclusiveExplanation(d.state.getAllocationExplanation(c.CID)))
+		return c, nil
+	}
+	if !d.sharedPoolFallbackAllowed(c, critical) {
+		return c, d.assignContainer(ctx, c)
+	}
+	snapshot := d.state.snapshot()
+	err := d.assignContainer(ctx, c)
+	if err == nil || ctx.Err() != nil || !notFitting(err) {
+		return c, err
+	}
+	d.logger.Info("container does not fit, it runs in the shared pool", "cid", c.CID, "reason", err.Error())
+	d.state.restore(snapshot)
+	d.state.clearMemoryNodes(c.CID)
+	d.state.clearCgroupPath(c.CID)
+	d.state.clearAllocationExplanation(c.CID)
+	c.QS, c.SharedPoolFallback = Burstable, true
+	if err := d.assignContainer(ctx, c); err != nil {
+		return c, err
+	}
+	d.state.setAllocationExplanation(c.CID, fallbackExplanation(err, d.state.getAllocationExplanation(c.CID)))
+	metrics.SharedPoolFallbacks.Inc()
+	return c, nil
+}
+
+// cgroupQoS returns QoS class selecting the cgroup of the container. Containers which fell back to the
+// shared pool stay in cgroups of guaranteed containers.
+func (c Container) cgroupQoS() QoS {
+	if c.SharedPoolFallback {
+		return Guaranteed
+	}
+	return c.QS
+}
+
+// keepSharedPoolFallbacks keeps wanted containers which fell back to the shared pool there, so that updates
+// of the pod do not fail on containers which still do not fit. Containers get exclusive cpus again when
+// they are recreated.
+func keepSharedPoolFallbacks(current []Container, wanted []Container) []Container {
+	fallbacks := map[string]struct{}{}
+	for _, c := range current {
+		if c.SharedPoolFallback {
+			fallbacks[c.CID] = struct{}{}
+		}
+	}
+	for i, c := range wanted {
+		if _, ok := fallbacks[c.CID]; ok && c.QS == Guaranteed {
+			wanted[i].QS, wanted[i].SharedPoolFallback = Burstable, true
+		}
+	}
+	return wanted
+}
+
+// withContainer returns containers with the container of the same id replaced by the given one.
+func withContainer(containers []Container, c Container) []Container {
+	for i := range containers {
+		if containers[i].CID == c.CID {
+			containers[i] = c
+		}
+	}
+	return containers
+}
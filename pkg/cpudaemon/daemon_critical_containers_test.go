@@ -0,0 +1,149 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+func newDaemonForCriticalContainersTest(t *testing.T, opts ...Option) *Daemon {
+	opts = append(opts, WithExclusiveCpusCap(50)) // 4 out of 8 cpus
+	return newTestDaemon(t, NewStaticPolocy(NewDefaultAllocator(newMockedCgroups())), opts...)
+}
+
+// createCriticalPodRequest returns request of the pod with containers taking 1, 2 and 3 cpus.
+func createCriticalPodRequest(critical string) (PodMetaData, *ctlplaneapi.CreatePodRequest) {
+	p := createTestPod(3)
+	req := createPodRequest(p)
+	req.Annotations = map[string]string{CriticalContainersAnnotation: critical}
+	return p, req
+}
+
+func TestCriticalContainers(t *testing.T) {
+	assert.Empty(t, criticalContainers(nil))
+	assert.Equal(
+		t,
+		map[string]struct{}{"app": {}, "db": {}},
+		criticalContainers(map[string]string{CriticalContainersAnnotation: "app, db,"}),
+	)
+}
+
+func TestSortedByPriority(t *testing.T) {
+	containers := []Container{{Name: "a", Cpus: 1}, {Name: "b", Cpus: 3}, {Name: "c", Cpus: 2}, {Name: "d", Cpus: 4}}
+
+	sorted := sortedByPriority(containers, map[string]struct{}{"a": {}, "c": {}})
+
+	names := []string{}
+	for _, c := range sorted {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"c", "a", "d", "b"}, names)
+}
+
+func TestCreatePodRunsNonCriticalContainerInSharedPool(t *testing.T) {
+	d := newDaemonForCriticalContainersTest(t, WithPartialAllocationAction(PartialAllocationSharedPool))
+	p, req := createCriticalPodRequest("testCid-0")
+	fallbacks := testutil.ToFloat64(metrics.SharedPoolFallbacks)
+
+	reply, err := d.CreatePod(context.Background(), req)
+
+	require.Nil(t, err)
+	require.Len(t, reply.ContainerResources, 3)
+	fallback := reply.ContainerResources[1]
+	assert.Equal(t, p.containers[1].CID, fallback.ContainerID)
+	assert.Equal(t, ctlplaneapi.QoSClass_BURSTABLE, fallback.QoS)
+	assert.False(t, fallback.Exclusive)
+	assert.Empty(t, fallback.CPUSet)
+	assert.Contains(t, fallback.Explanation, "container moved to the shared pool")
+	for _, i := range []int{0, 2} {
+		assert.True(t, reply.ContainerResources[i].Exclusive)
+		assert.Equal(t, i+1, CPUSetFromBucketList(reply.ContainerResources[i].CPUSet).Count())
+	}
+
+	c := d.state.Pods[p.pid].Containers[1]
+	assert.True(t, c.SharedPoolFallback)
+	assert.Equal(t, Burstable, c.QS)
+	assert.Equal(t, fallbacks+1, testutil.ToFloat64(metrics.SharedPoolFallbacks))
+}
+
+func TestCreatePodFailsIfCriticalContainerDoesNotFit(t *testing.T) {
+	d := newDaemonForCriticalContainersTest(t, WithPartialAllocationAction(PartialAllocationSharedPool))
+	_, req := createCriticalPodRequest("testCid-1,testCid-2")
+
+	_, err := d.CreatePod(context.Background(), req)
+
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, ExclusiveCpusCapExceeded, daemonErr.ErrorType)
+	assert.Empty(t, d.state.Pods)
+	assert.Empty(t, d.state.Allocated)
+}
+
+func TestCreatePodFailsIfPartialAllocationIsRejected(t *testing.T) {
+	d := newDaemonForCriticalContainersTest(t)
+	_, req := createCriticalPodRequest("testCid-0")
+
+	_, err := d.CreatePod(context.Background(), req)
+
+	assert.NotNil(t, err)
+	assert.Empty(t, d.state.Pods)
+}
+
+func TestUpdatePodKeepsContainerInSharedPool(t *testing.T) {
+	d := newDaemonForCriticalContainersTest(t, WithPartialAllocationAction(PartialAllocationSharedPool))
+	p, req := createCriticalPodRequest("testCid-0")
+	_, err := d.CreatePod(context.Background(), req)
+	require.Nil(t, err)
+
+	reply, err := d.UpdatePod(context.Background(), &ctlplaneapi.UpdatePodRequest{
+		PodId:       p.pid,
+		Resources:   p.resources,
+		Containers:  p.containersResources,
+		Annotations: req.Annotations,
+	})
+
+	require.Nil(t, err)
+	assert.Empty(t, reply.ContainerResources, "no container shall be reallocated")
+	c := d.state.Pods[p.pid].Containers
+	require.Len(t, c, 3)
+	assert.True(t, c[1].SharedPoolFallback)
+	assert.Equal(t, p.containers[1].CID, c[1].CID)
+}
+
+func TestUpdatePodRunsAddedNonCriticalContainerInSharedPool(t *testing.T) {
+	d := newDaemonForCriticalContainersTest(t, WithPartialAllocationAction(PartialAllocationSharedPool))
+	p := createTestPod(3)
+	req := createPodRequest(p)
+	req.Containers = req.Containers[2:]
+	_, err := d.CreatePod(context.Background(), req)
+	require.Nil(t, err)
+	// container of 3 cpus is allocated, only 1 more cpu fits
+	p.containersResources[0].Resources.RequestedCpus = 2
+	p.containersResources[0].Resources.LimitCpus = 2
+
+	reply, err := d.UpdatePod(context.Background(), &ctlplaneapi.UpdatePodRequest{
+		PodId:       p.pid,
+		Resources:   p.resources,
+		Containers:  []*ctlplaneapi.ContainerInfo{p.containersResources[0], p.containersResources[2]},
+		Annotations: map[string]string{CriticalContainersAnnotation: "testCid-2"},
+	})
+
+	require.Nil(t, err)
+	require.Len(t, reply.ContainerResources, 1)
+	assert.Equal(t, ctlplaneapi.QoSClass_BURSTABLE, reply.ContainerResources[0].QoS)
+}
+
+func TestSharedPoolFallbackKeepsGuaranteedCgroup(t *testing.T) {
+	c := Container{CID: "containerd://cid", PID: "pid", QS: Guaranteed}
+	fallback := c
+	fallback.QS, fallback.SharedPoolFallback = Burstable, true
+
+	for _, driver := range []CGroupDriver{DriverSystemd, DriverCgroupfs} {
+		assert.Equal(t, SliceName(c, ContainerdRunc, driver), SliceName(fallback, ContainerdRunc, driver))
+	}
+}
@@ -0,0 +1,255 @@
+package cpudaemon
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/numautils"
+)
+
+// DefragmentationPolicy is implemented by policies able to plan container migrations which make room for
+// exclusive cpus on a single numa node.
+type DefragmentationPolicy interface {
+	PlanDefragmentation(cpus int, s *DaemonState) (*ctlplaneapi.DefragmentationPlan, error)
+}
+
+var _ DefragmentationPolicy = &StaticPolicy{}
+
+// defragmentingAllocator is implemented by allocators able to plan container migrations.
+type defragmentingAllocator interface {
+	planDefragmentation(cpus int, s *DaemonState) (*ctlplaneapi.DefragmentationPlan, error)
+}
+
+var _ defragmentingAllocator = &NumaAwareAllocator{}
+
+var errDefragmentationNotSupported = DaemonError{
+	ErrorType:    NotImplemented,
+	ErrorMessage: "defragmentation is not supported by the allocator",
+}
+
+// PlanDefragmentation returns container migrations after which given number of cpus fits on a single numa
+// node. If requested, the migrations are executed one by one with MigrateContainer; migrations executed
+// before a failing one are kept.
+func (d *Daemon) PlanDefragmentation(
+	ctx context.Context,
+	req *ctlplaneapi.PlanDefragmentationRequest,
+) (*ctlplaneapi.DefragmentationPlan, error) {
+	if err := ctlplaneapi.ValidatePlanDefragmentationRequest(req); err != nil {
+		d.logger.Error(err, "validation error")
+		return nil, DaemonError{ErrorType: PodSpecError, ErrorMessage: err.Error()}
+	}
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	p, ok := d.policy.(DefragmentationPolicy)
+	if !ok {
+		return nil, errDefragmentationNotSupported
+	}
+	defer d.traceRequest(tracePlanDefragmentation, req, d.traceHash())
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	plan, err := p.PlanDefragmentation(int(req.Cpus), &d.state)
+	if err != nil {
+		d.logger.Error(err, "cannot plan defragmentation", "cpus", req.Cpus)
+		return nil, err
+	}
+	d.logger.Info("defragmentation planned", "cpus", req.Cpus, "node", plan.Node, "migrations", len(plan.Migrations))
+	if !req.Execute || len(plan.Migrations) == 0 {
+		return plan, nil
+	}
+	if err := d.executeDefragmentation(ctx, plan); err != nil {
+		return nil, err
+	}
+	plan.Executed = true
+	return plan, nil
+}
+
+// executeDefragmentation executes migrations of the plan and saves the state. Shall be called with the
+// state lock held.
+func (d *Daemon) executeDefragmentation(ctx context.Context, plan *ctlplaneapi.DefragmentationPlan) error {
+	p, ok := d.policy.(MigrationPolicy)
+	if !ok {
+		return errMigrationNotSupported
+	}
+	var migrationErr error
+	for _, m := range plan.Migrations {
+		c, err := findContainer(&d.state, m.ContainerID)
+		if err != nil {
+			migrationErr = DaemonError{ErrorType: ContainerNotFound, ErrorMessage: err.Error()}
+			break
+		}
+		if err := p.MigrateContainer(ctx, c, m.TargetNode, &d.state); err != nil {
+			d.logger.Error(err, "cannot migrate container", "cid", c.CID, "node", m.TargetNode)
+			migrationErr = err
+			break
+		}
+		d.logger.Info("container migrated", "cid", c.CID, "node", m.TargetNode, "cpus", d.state.Allocated[c.CID])
+	}
+	if err := d.saveState(); err != nil {
+		return *err
+	}
+	return migrationErr
+}
+
+// PlanDefragmentation plans container migrations, if the allocator supports it.
+func (p *StaticPolicy) PlanDefragmentation(cpus int, s *DaemonState) (*ctlplaneapi.DefragmentationPlan, error) {
+	a, ok := p.allocator.(defragmentingAllocator)
+	if !ok {
+		return nil, errDefragmentationNotSupported
+	}
+	return a.planDefragmentation(cpus, s)
+}
+
+// movableContainer is a container with exclusive cpus, together with number of its cpus on each numa node.
+type movableContainer struct {
+	c      Container
+	shares map[int]int
+}
+
+// planDefragmentation plans migrations for each numa node large enough for the cpus and returns the plan
+// with the least migrations, then the least migrated cpus. Containers with the most cpus on the node are
+// migrated first, each to the node with the least free cpus left after the migration (best fit).
+func (d *NumaAwareAllocator) planDefragmentation(cpus int, s *DaemonState) (*ctlplaneapi.DefragmentationPlan, error) {
+	nodes := numaNodes(&s.Topology)
+	free := make(map[int]int, len(nodes))
+	for _, node := range nodes {
+		n, err := numaNode(&s.Topology, node)
+		if err != nil {
+			return nil, err
+		}
+		free[node] = n.NumAvailable
+	}
+	containers := movableContainers(s)
+
+	var best *ctlplaneapi.DefragmentationPlan
+	bestCpus := 0
+	for _, node := range nodes {
+		if len(s.Topology.CpusOnNodes([]int{node})) < cpus {
+			continue
+		}
+		plan, ok := planNodeDefragmentation(node, cpus, nodes, copyFreeCpus(free), containers)
+		if !ok {
+			continue
+		}
+		migrated := 0
+		for _, m := range plan.Migrations {
+			migrated += m.Cpus
+		}
+		if best == nil || len(plan.Migrations) < len(best.Migrations) ||
+			(len(plan.Migrations) == len(best.Migrations) && migrated < bestCpus) {
+			best, bestCpus = plan, migrated
+		}
+	}
+	if best == nil {
+		return nil, DaemonError{
+			ErrorType:    CpusNotAvailable,
+			ErrorMessage: fmt.Sprintf("%d cpus cannot fit on a single numa node", cpus),
+		}
+	}
+	return best, nil
+}
+
+// planNodeDefragmentation plans migrations making room for the cpus on the node. Free cpus of the nodes
+// are updated as migrations are planned, so that each migration fits after the previous ones.
+func planNodeDefragmentation(
+	node, cpus int,
+	nodes []int,
+	free map[int]int,
+	containers []movableContainer,
+) (*ctlplaneapi.DefragmentationPlan, bool) {
+	candidates := []movableContainer{}
+	for _, m := range containers {
+		if m.shares[node] > 0 {
+			candidates = append(candidates, m)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].shares[node] > candidates[j].shares[node]
+	})
+
+	plan := &ctlplaneapi.DefragmentationPlan{Node: node, Migrations: []ctlplaneapi.ContainerMigration{}}
+	for _, m := range candidates {
+		if free[node] >= cpus {
+			break
+		}
+		target, ok := bestFitNode(m, node, nodes, free)
+		if !ok {
+			continue
+		}
+		for n, share := range m.shares {
+			free[n] += share
+		}
+		free[target] -= m.c.Cpus
+		plan.Migrations = append(plan.Migrations, ctlplaneapi.ContainerMigration{
+			PodID:         m.c.PID,
+			ContainerName: m.c.Name,
+			ContainerID:   m.c.CID,
+			Cpus:          m.c.Cpus,
+			SourceNode:    node,
+			TargetNode:    target,
+		})
+	}
+	return plan, free[node] >= cpus
+}
+
+// bestFitNode returns the node other than the source one, which fits the container with the least free
+// cpus left. Cpus the container already has on a node count as free.
+func bestFitNode(m movableContainer, source int, nodes []int, free map[int]int) (int, bool) {
+	target, left := 0, -1
+	for _, node := range nodes {
+		if node == source {
+			continue
+		}
+		l := free[node] + m.shares[node] - m.c.Cpus
+		if l >= 0 && (left < 0 || l < left) {
+			target, left = node, l
+		}
+	}
+	return target, left >= 0
+}
+
+// movableContainers returns guaranteed containers with exclusive cpus, ordered by ids.
+func movableContainers(s *DaemonState) []movableContainer {
+	containers := []movableContainer{}
+	for _, pod := range s.Pods {
+		for _, c := range pod.Containers {
+			allocated, ok := s.Allocated[c.CID]
+			if !ok || c.QS != Guaranteed {
+				continue
+			}
+			shares := map[int]int{}
+			for _, cpu := range CPUSetFromBucketList(allocated).Sorted() {
+				shares[s.Topology.CpuInformation[cpu].Node]++
+			}
+			containers = append(containers, movableContainer{c: c, shares: shares})
+		}
+	}
+	sort.Slice(containers, func(i, j int) bool { return containers[i].c.CID < containers[j].c.CID })
+	return containers
+}
+
+// numaNodes returns sorted ids of numa nodes of the topology.
+func numaNodes(t *numautils.NumaTopology) []int {
+	seen := map[int]struct{}{}
+	nodes := []int{}
+	for _, cpuInfo := range t.CpuInformation {
+		if _, ok := seen[cpuInfo.Node]; !ok {
+			seen[cpuInfo.Node] = struct{}{}
+			nodes = append(nodes, cpuInfo.Node)
+		}
+	}
+	sort.Ints(nodes)
+	return nodes
+}
+
+// copyFreeCpus returns copy of free cpus of numa nodes.
+func copyFreeCpus(free map[int]int) map[int]int {
+	copied := make(map[int]int, len(free))
+	for node, n := range free {
+		copied[node] = n
+	}
+	return copied
+}
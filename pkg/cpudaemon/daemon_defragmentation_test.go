@@ -0,0 +1,113 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// createFragmentedPod creates pod with container of 2 cpus on node 0 and container of 1 cpu on node 1, leaving
+// 2 free cpus on node 0 and 3 on node 1.
+func createFragmentedPod(t *testing.T, d *Daemon, m *CgroupsMock) PodMetaData {
+	p, _ := createPodForMigration(t, d, m)
+	m.On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	for i, node := range []uint32{1, 0} {
+		_, err := d.MigrateContainer(
+			context.Background(),
+			&ctlplaneapi.MigrateContainerRequest{ContainerId: p.containers[i].CID, TargetNode: node},
+		)
+		require.Nil(t, err)
+	}
+	return p
+}
+
+func TestPlanDefragmentation(t *testing.T) {
+	d, m := newDaemonForMigrationTest(t, newNumaAllocatorForMigrationTest)
+	p := createFragmentedPod(t, d, m)
+	allocated := d.state.Allocated[p.containers[0].CID]
+
+	plan, err := d.PlanDefragmentation(context.Background(), &ctlplaneapi.PlanDefragmentationRequest{Cpus: 4})
+
+	require.Nil(t, err)
+	assert.Equal(t, &ctlplaneapi.DefragmentationPlan{
+		Node: 1,
+		Migrations: []ctlplaneapi.ContainerMigration{{
+			PodID:         p.pid,
+			ContainerName: p.containers[0].Name,
+			ContainerID:   p.containers[0].CID,
+			Cpus:          1,
+			SourceNode:    1,
+			TargetNode:    0,
+		}},
+	}, plan)
+	assert.Equal(t, allocated, d.state.Allocated[p.containers[0].CID], "plan shall not be executed")
+}
+
+func TestPlanDefragmentationWithoutMigrations(t *testing.T) {
+	d, m := newDaemonForMigrationTest(t, newNumaAllocatorForMigrationTest)
+	createFragmentedPod(t, d, m)
+
+	plan, err := d.PlanDefragmentation(
+		context.Background(),
+		&ctlplaneapi.PlanDefragmentationRequest{Cpus: 3, Execute: true},
+	)
+
+	require.Nil(t, err)
+	assert.Equal(t, 1, plan.Node)
+	assert.Empty(t, plan.Migrations)
+	assert.False(t, plan.Executed)
+}
+
+func TestPlanDefragmentationExecutesPlan(t *testing.T) {
+	d, m := newDaemonForMigrationTest(t, newNumaAllocatorForMigrationTest)
+	p := createFragmentedPod(t, d, m)
+
+	plan, err := d.PlanDefragmentation(
+		context.Background(),
+		&ctlplaneapi.PlanDefragmentationRequest{Cpus: 4, Execute: true},
+	)
+
+	require.Nil(t, err)
+	assert.True(t, plan.Executed)
+	assert.Equal(t, map[int]struct{}{0: {}}, cpuNodes(d, p.containers[0].CID))
+	assert.Equal(t, map[int]struct{}{0: {}}, cpuNodes(d, p.containers[1].CID))
+	node, err := numaNode(&d.state.Topology, 1)
+	require.Nil(t, err)
+	assert.Equal(t, 4, node.NumAvailable)
+	assert.Equal(t, d.state.Allocated, d.readableState().Allocated, "migrations shall be published")
+}
+
+func TestPlanDefragmentationFailsIfCpusDoNotFitOnNode(t *testing.T) {
+	d, m := newDaemonForMigrationTest(t, newNumaAllocatorForMigrationTest)
+	createFragmentedPod(t, d, m)
+
+	for _, cpus := range []uint32{0, 5} {
+		_, err := d.PlanDefragmentation(context.Background(), &ctlplaneapi.PlanDefragmentationRequest{Cpus: cpus})
+
+		assert.NotNil(t, err)
+	}
+}
+
+func TestPlanDefragmentationNotSupported(t *testing.T) {
+	d, _ := newDaemonForMigrationTest(t, func(ctrl CgroupController) Allocator { return NewDefaultAllocator(ctrl) })
+
+	_, err := d.PlanDefragmentation(context.Background(), &ctlplaneapi.PlanDefragmentationRequest{Cpus: 1})
+
+	assert.ErrorIs(t, err, errDefragmentationNotSupported)
+}
+
+func TestBestFitNode(t *testing.T) {
+	m := movableContainer{c: Container{Cpus: 2}, shares: map[int]int{0: 2}}
+	free := map[int]int{0: 1, 1: 3, 2: 2, 3: 1}
+
+	node, ok := bestFitNode(m, 0, []int{0, 1, 2, 3}, free)
+
+	assert.True(t, ok)
+	assert.Equal(t, 2, node)
+	_, ok = bestFitNode(m, 0, []int{0, 3}, free)
+	assert.False(t, ok)
+}
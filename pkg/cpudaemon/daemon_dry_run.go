@@ -0,0 +1,149 @@
+package cpudaemon
+
+import (
+	"context"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// DryRunPolicy is implemented by policies able to compute allocations without applying them.
+type DryRunPolicy interface {
+	// DryRun returns a copy of the policy which changes no cgroups and whose allocator state is
+	// independent of the original one. Returns false if the allocator cannot be copied.
+	DryRun() (Policy, bool)
+}
+
+var _ DryRunPolicy = &StaticPolicy{}
+
+// dryRunAllocator is implemented by allocators able to copy themselves for dry runs.
+type dryRunAllocator interface {
+	dryRun() Allocator
+}
+
+var (
+	_ dryRunAllocator = &DefaultAllocator{}
+	_ dryRunAllocator = &NumaAwareAllocator{}
+	_ dryRunAllocator = &NumaPerNamespaceAllocator{}
+	_ dryRunAllocator = &ScatterAllocator{}
+)
+
+var errDryRunNotSupported = DaemonError{
+	ErrorType:    NotImplemented,
+	ErrorMessage: "dry run is not supported by the policy",
+}
+
+// dryRunController is the cgroup controller of dry runs, it changes no cgroups.
+type dryRunController struct{}
+
+var _ CgroupController = dryRunController{}
+
+func (dryRunController) UpdateCPUSet(ctx context.Context, _ string, _ Container, _ string, _ string) error {
+	return ctx.Err()
+}
+
+// DryRun returns the policy with a copy of the allocator, if the allocator can be copied.
+func (p *StaticPolicy) DryRun() (Policy, bool) {
+	a, ok := p.allocator.(dryRunAllocator)
+	if !ok {
+		return nil, false
+	}
+	return NewStaticPolocy(a.dryRun()), true
+}
+
+func (d *DefaultAllocator) dryRun() Allocator {
+	return newAllocator(dryRunController{})
+}
+
+func (d *NumaAwareAllocator) dryRun() Allocator {
+	a := *d
+	a.ctrl = dryRunController{}
+	return &a
+}
+
+func (d *ScatterAllocator) dryRun() Allocator {
+	a := *d
+	a.numa.ctrl = dryRunController{}
+	return &a
+}
+
+// dryRun copies namespace buckets, so that buckets created by the dry run are not kept.
+func (d *NumaPerNamespaceAllocator) dryRun() Allocator {
+	a := *d
+	a.ctrl = dryRunController{}
+	a.NamespaceToBucket = make(map[string]int, len(d.NamespaceToBucket))
+	for namespace, index := range d.NamespaceToBucket {
+		a.NamespaceToBucket[namespace] = index
+	}
+	a.BucketToNumContainers = make(map[int]int, len(d.BucketToNumContainers))
+	for index, n := range d.BucketToNumContainers {
+		a.BucketToNumContainers[index] = n
+	}
+	a.pinnedNamespaces = make(map[string]struct{}, len(d.pinnedNamespaces))
+	for namespace := range d.pinnedNamespaces {
+		a.pinnedNamespaces[namespace] = struct{}{}
+	}
+	a.namespaceWeights = make(map[string]uint64, len(d.namespaceWeights))
+	for namespace, weight := range d.namespaceWeights {
+		a.namespaceWeights[namespace] = weight
+	}
+	return &a
+}
+
+// dryRunDaemon returns daemon working on a copy of the state with a copy of the policy. Requests
+// processed by it compute allocations with the same allocator logic, but change neither the state nor
+// cgroups, and nothing is saved or published. Shall be called with the state lock held.
+func (d *Daemon) dryRunDaemon() (*Daemon, error) {
+	p, ok := d.policy.(DryRunPolicy)
+	if !ok {
+		return nil, errDryRunNotSupported
+	}
+	policy, ok := p.DryRun()
+	if !ok {
+		return nil, errDryRunNotSupported
+	}
+	tiers, err := d.dryRunTierPolicies(policy)
+	if err != nil {
+		return nil, err
+	}
+	s := d.state.clone()
+	s.tombstones = cloneMap(d.state.tombstones)
+	return &Daemon{
+		state:   *s,
+		policy:  policy,
+		logger:  d.logger.WithName("dryRun"),
+		options: d.options,
+		dryRun:  true,
+
+		tierPolicies: tiers,
+	}, nil
+}
+
+// dryRunCreatePod computes allocation of the pod as CreatePod would, without applying it.
+func (d *Daemon) dryRunCreatePod(
+	ctx context.Context,
+	req *ctlplaneapi.CreatePodRequest,
+) (*ctlplaneapi.AllocatedPodResources, error) {
+	d.stateMu.Lock()
+	dry, err := d.dryRunDaemon()
+	d.stateMu.Unlock()
+	if err != nil {
+		d.logger.Error(err, "cannot create pod")
+		return nil, err
+	}
+	return dry.CreatePod(ctx, req)
+}
+
+// dryRunUpdatePod computes allocation of the pod as UpdatePod would, without applying it.
+func (d *Daemon) dryRunUpdatePod(
+	ctx context.Context,
+	req *ctlplaneapi.UpdatePodRequest,
+) (*ctlplaneapi.AllocatedPodResources, error) {
+	d.stateMu.Lock()
+	dry, err := d.dryRunDaemon()
+	d.stateMu.Unlock()
+	if err != nil {
+		d.logger.Error(err, "cannot update pod")
+		return nil, err
+	}
+	return dry.UpdatePod(ctx, req)
+}
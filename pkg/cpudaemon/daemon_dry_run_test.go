@@ -0,0 +1,108 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+func newDaemonForDryRunTest(t *testing.T) (*Daemon, *CgroupsMock, *NumaPerNamespaceAllocator) {
+	m := CgroupsMock{}
+	a := NewNumaPerNamespaceAllocator(2, &m, true, false, logr.Discard())
+	return newTestDaemon(t, NewStaticPolocy(a)), &m, a
+}
+
+func createPodRequest(p PodMetaData) *ctlplaneapi.CreatePodRequest {
+	return &ctlplaneapi.CreatePodRequest{
+		PodId:        p.pid,
+		PodName:      p.name,
+		PodNamespace: p.namespace,
+		Resources:    p.resources,
+		Containers:   p.containersResources,
+	}
+}
+
+func TestDryRunCreatePodChangesNothing(t *testing.T) {
+	d, m, a := newDaemonForDryRunTest(t)
+	p := createTestPod(2)
+	req := createPodRequest(p)
+	req.DryRun = true
+
+	dryRun, err := d.CreatePod(context.Background(), req)
+
+	require.Nil(t, err)
+	require.Len(t, dryRun.ContainerResources, 2)
+	m.AssertNotCalled(t, "UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.Empty(t, d.state.Pods)
+	assert.Empty(t, d.state.Allocated)
+	assert.Empty(t, a.NamespaceToBucket)
+	assert.Empty(t, d.readableState().Pods)
+
+	m.On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	req.DryRun = false
+	created, err := d.CreatePod(context.Background(), req)
+
+	require.Nil(t, err)
+	for i, c := range created.ContainerResources {
+		assert.Equal(t, c.CPUSet, dryRun.ContainerResources[i].CPUSet, "dry run shall use the same allocator logic")
+	}
+}
+
+func TestDryRunUpdatePodChangesNothing(t *testing.T) {
+	d, m, _ := newDaemonForDryRunTest(t)
+	p := createTestPod(1)
+	m.On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	_, err := d.CreatePod(context.Background(), createPodRequest(p))
+	require.Nil(t, err)
+	allocated := d.state.Allocated[p.containers[0].CID]
+	m.Calls = nil
+
+	p.containersResources[0].Resources.RequestedCpus = 3
+	p.containersResources[0].Resources.LimitCpus = 3
+	resized, err := d.UpdatePod(context.Background(), &ctlplaneapi.UpdatePodRequest{
+		PodId:      p.pid,
+		Resources:  p.resources,
+		Containers: p.containersResources,
+		DryRun:     true,
+	})
+
+	require.Nil(t, err)
+	require.Len(t, resized.ContainerResources, 1)
+	assert.Equal(t, 3, CPUSetFromBucketList(resized.ContainerResources[0].CPUSet).Count())
+	assert.Empty(t, m.Calls)
+	assert.Equal(t, allocated, d.state.Allocated[p.containers[0].CID])
+	assert.Equal(t, 1, d.state.Pods[p.pid].Containers[0].Cpus)
+}
+
+func TestDryRunFailsIfPolicyCannotBeCopied(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+	require.Nil(t, err)
+	req := createPodRequest(createTestPod(1))
+	req.DryRun = true
+
+	_, err = d.CreatePod(context.Background(), req)
+
+	assert.Equal(t, errDryRunNotSupported, err)
+	m.AssertNotCalled(t, "AssignContainer", mock.Anything, mock.Anything)
+}
+
+func TestNumaNamespaceDryRunCopiesBuckets(t *testing.T) {
+	a := newMockedNumaPerNamespaceAllocator(2, true)
+	a.NamespaceToBucket["team-a"] = 1
+
+	dry := a.dryRun().(*NumaPerNamespaceAllocator)
+	dry.NamespaceToBucket["team-b"] = 0
+	dry.BucketToNumContainers[1]++
+
+	assert.Equal(t, map[string]int{"team-a": 1}, a.NamespaceToBucket)
+	assert.Empty(t, a.BucketToNumContainers)
+	assert.Equal(t, dryRunController{}, dry.ctrl)
+}
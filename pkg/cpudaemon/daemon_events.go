@@ -0,0 +1,73 @@
+package cpudaemon
+
+import (
+	"sort"
+	"time"
+
+	"resourcemanagement.controlplane/pkg/events"
+)
+
+// allocationEvents returns allocated event of each container with allocated cpus.
+func (d *Daemon) allocationEvents(now time.Time) map[string]events.AllocationEvent {
+	res := make(map[string]events.AllocationEvent)
+	for _, pod := range d.state.Pods {
+		for _, c := range pod.Containers {
+			allocated, ok := d.state.Allocated[c.CID]
+			if !ok {
+				continue
+			}
+			res[c.CID] = events.AllocationEvent{
+				Type:          events.Allocated,
+				Time:          now,
+				PodID:         pod.PID,
+				PodName:       pod.Name,
+				PodNamespace:  pod.Namespace,
+				ContainerID:   c.CID,
+				ContainerName: c.Name,
+				Cpus:          CPUSetFromBucketList(allocated).ToCpuString(),
+				Exclusive:     c.QS == Guaranteed && !pod.LeaseExpired,
+				MemoryNodes:   d.state.getMemoryNodes(c.CID),
+			}
+		}
+	}
+	return res
+}
+
+// publishAllocationEvents publishes events of containers whose allocation changed since the last call:
+// allocated events for new and changed allocations, freed events for released ones. On the first call
+// all current allocations are published, so that consumers can catch up after the daemon restart.
+func (d *Daemon) publishAllocationEvents() {
+	if d.options.eventSink == nil {
+		return
+	}
+	now := time.Now()
+	current := d.allocationEvents(now)
+
+	published := []events.AllocationEvent{}
+	for cid, e := range d.publishedEvents {
+		if _, ok := current[cid]; !ok {
+			e.Type, e.Time = events.Freed, now
+			published = append(published, e)
+		}
+	}
+	for cid, e := range current {
+		if last, ok := d.publishedEvents[cid]; !ok || !sameAllocation(last, e) {
+			published = append(published, e)
+		}
+	}
+	sort.SliceStable(published, func(i, j int) bool {
+		if published[i].Type != published[j].Type {
+			return published[i].Type == events.Freed // freed cpus first, as they may be allocated again
+		}
+		return published[i].ContainerID < published[j].ContainerID
+	})
+	for _, e := range published {
+		d.options.eventSink.Publish(e)
+	}
+	d.publishedEvents = current
+}
+
+func sameAllocation(a, b events.AllocationEvent) bool {
+	a.Time, b.Time = time.Time{}, time.Time{}
+	return a == b
+}
@@ -0,0 +1,98 @@
+package cpudaemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/events"
+)
+
+type eventRecorder struct {
+	published []events.AllocationEvent
+}
+
+func (r *eventRecorder) Publish(e events.AllocationEvent) {
+	r.published = append(r.published, e)
+}
+
+func newDaemonForEvents() (*Daemon, *eventRecorder) {
+	recorder := &eventRecorder{}
+	d := &Daemon{
+		state: DaemonState{
+			Pods: map[string]PodMetadata{
+				"pod": {
+					PID:       "pod",
+					Name:      "name",
+					Namespace: "namespace",
+					Containers: []Container{
+						{CID: "guaranteed", PID: "pod", Name: "g", Cpus: 2, QS: Guaranteed},
+						{CID: "burstable", PID: "pod", Name: "b", Cpus: 1, QS: Burstable},
+					},
+				},
+			},
+			Allocated: map[string][]ctlplaneapi.CPUBucket{
+				"guaranteed": {{StartCPU: 1, EndCPU: 2}},
+				"burstable":  {{StartCPU: 3, EndCPU: 7}},
+			},
+		},
+		logger:  logr.Discard(),
+		options: newDaemonOptions([]Option{WithEventSink(recorder)}),
+	}
+	return d, recorder
+}
+
+func TestPublishAllocationEventsAtStartup(t *testing.T) {
+	d, recorder := newDaemonForEvents()
+
+	d.publishAllocationEvents()
+
+	require.Len(t, recorder.published, 2)
+	e := recorder.published[1]
+	assert.False(t, e.Time.IsZero())
+	e.Time = time.Time{}
+	assert.Equal(t, events.AllocationEvent{
+		Type:          events.Allocated,
+		PodID:         "pod",
+		PodName:       "name",
+		PodNamespace:  "namespace",
+		ContainerID:   "guaranteed",
+		ContainerName: "g",
+		Cpus:          "1,2",
+		Exclusive:     true,
+	}, e)
+	assert.Equal(t, "burstable", recorder.published[0].ContainerID)
+	assert.False(t, recorder.published[0].Exclusive)
+}
+
+func TestPublishAllocationEventsOnlyForChanges(t *testing.T) {
+	d, recorder := newDaemonForEvents()
+	d.publishAllocationEvents()
+	recorder.published = nil
+
+	d.publishAllocationEvents()
+	assert.Empty(t, recorder.published)
+
+	delete(d.state.Allocated, "guaranteed")
+	d.state.Allocated["burstable"] = []ctlplaneapi.CPUBucket{{StartCPU: 1, EndCPU: 7}}
+	d.publishAllocationEvents()
+
+	require.Len(t, recorder.published, 2)
+	assert.Equal(t, events.Freed, recorder.published[0].Type)
+	assert.Equal(t, "guaranteed", recorder.published[0].ContainerID)
+	assert.Equal(t, "1,2", recorder.published[0].Cpus)
+	assert.Equal(t, events.Allocated, recorder.published[1].Type)
+	assert.Equal(t, "1,2,3,4,5,6,7", recorder.published[1].Cpus)
+}
+
+func TestPublishAllocationEventsDisabled(t *testing.T) {
+	d, _ := newDaemonForEvents()
+	d.options = newDaemonOptions(nil)
+
+	d.publishAllocationEvents()
+
+	assert.Nil(t, d.publishedEvents)
+}
@@ -0,0 +1,77 @@
+package cpudaemon
+
+import (
+	"strconv"
+
+	"resourcemanagement.controlplane/pkg/metrics"
+	"resourcemanagement.controlplane/pkg/numautils"
+)
+
+// nodeFragmentation describes free cpus of a first level topology node.
+type nodeFragmentation struct {
+	free         int // number of free cpus
+	largestBlock int // the largest number of adjacent free cpus, in topology order
+}
+
+// score returns fragmentation score of the node: 0 if all free cpus are adjacent (or there are no free
+// cpus), approaching 1 as free cpus get scattered.
+func (f nodeFragmentation) score() float64 {
+	if f.free == 0 {
+		return 0
+	}
+	return 1 - float64(f.largestBlock)/float64(f.free)
+}
+
+// fragmentation computes fragmentation of free cpus of the node. Cpu is free if it is available both in
+// the topology (used by numa allocators) and in the list of available cpus (used by default allocator).
+func (d *DaemonState) fragmentation(node *numautils.TopologyNode) nodeFragmentation {
+	available := CPUSetFromBucketList(d.AvailableCPUs)
+	f := nodeFragmentation{}
+	block := 0
+	for _, leaf := range node.GetLeafs() {
+		if !leaf.Available() || !available.Contains(leaf.Value) {
+			block = 0
+			continue
+		}
+		f.free++
+		block++
+		if block > f.largestBlock {
+			f.largestBlock = block
+		}
+	}
+	return f
+}
+
+// updateFragmentationMetrics exports number of free cpus and fragmentation of each numa node.
+func (d *Daemon) updateFragmentationMetrics() {
+	for _, node := range d.state.Topology.Topology.Children {
+		f := d.state.fragmentation(node)
+		label := strconv.Itoa(node.Value)
+		metrics.NumaNodeFreeCpus.WithLabelValues(label).Set(float64(f.free))
+		metrics.NumaNodeFragmentation.WithLabelValues(label).Set(f.score())
+	}
+}
+
+// updateAllocationMetrics exports number of containers with allocated cpus and number of free cpus
+// which can still be exclusively allocated, taking the exclusive cpus cap into account.
+func (d *Daemon) updateAllocationMetrics() {
+	allocated := 0
+	for _, cpus := range d.state.Allocated {
+		if len(cpus) > 0 {
+			allocated++
+		}
+	}
+	free := 0
+	for _, node := range d.state.Topology.Topology.Children {
+		free += d.state.fragmentation(node).free
+	}
+	limit := len(d.state.Topology.Topology.GetLeafs()) * d.options.exclusiveCpusCap / maxExclusiveCpusCap
+	if remaining := limit - d.state.exclusiveCpusCount(""); remaining < free {
+		free = remaining
+	}
+	if free < 0 {
+		free = 0
+	}
+	metrics.AllocatedContainers.Set(float64(allocated))
+	metrics.FreeExclusiveCpus.Set(float64(free))
+}
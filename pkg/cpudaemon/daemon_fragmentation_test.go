@@ -0,0 +1,95 @@
+package cpudaemon
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+func getFragmentationTestState(t *testing.T) *DaemonState {
+	s := getPlacementTestState(t)
+	s.AvailableCPUs = []ctlplaneapi.CPUBucket{{StartCPU: 0, EndCPU: 7}}
+	return s
+}
+
+func TestFragmentationOfFreeNode(t *testing.T) {
+	s := getFragmentationTestState(t)
+
+	f := s.fragmentation(s.Topology.Topology.Children[0])
+
+	assert.Equal(t, nodeFragmentation{free: 4, largestBlock: 4}, f)
+	assert.Zero(t, f.score())
+}
+
+func TestFragmentationWithCpusTakenFromTopology(t *testing.T) {
+	s := getFragmentationTestState(t)
+	require.Nil(t, s.Topology.TakeCpu(1))
+
+	f := s.fragmentation(s.Topology.Topology.Children[0])
+	assert.Equal(t, nodeFragmentation{free: 3, largestBlock: 2}, f)
+	assert.InDelta(t, 1.0/3, f.score(), 1e-9)
+
+	require.Nil(t, s.Topology.TakeCpu(3))
+	f = s.fragmentation(s.Topology.Topology.Children[0])
+	assert.Equal(t, nodeFragmentation{free: 2, largestBlock: 1}, f)
+	assert.InDelta(t, 0.5, f.score(), 1e-9)
+}
+
+func TestFragmentationWithCpusTakenFromAvailableCpus(t *testing.T) {
+	s := getFragmentationTestState(t)
+	s.AvailableCPUs = []ctlplaneapi.CPUBucket{{StartCPU: 0, EndCPU: 4}, {StartCPU: 6, EndCPU: 7}}
+
+	f := s.fragmentation(s.Topology.Topology.Children[1])
+
+	assert.Equal(t, nodeFragmentation{free: 3, largestBlock: 2}, f)
+}
+
+func TestFragmentationWithoutFreeCpus(t *testing.T) {
+	s := getFragmentationTestState(t)
+	s.AvailableCPUs = []ctlplaneapi.CPUBucket{{StartCPU: 0, EndCPU: 3}}
+
+	f := s.fragmentation(s.Topology.Topology.Children[1])
+
+	assert.Equal(t, nodeFragmentation{}, f)
+	assert.Zero(t, f.score())
+}
+
+func TestUpdateFragmentationMetrics(t *testing.T) {
+	d := Daemon{state: *getFragmentationTestState(t)}
+	require.Nil(t, d.state.Topology.TakeCpu(5))
+
+	d.updateFragmentationMetrics()
+
+	assert.Equal(t, 4.0, testutil.ToFloat64(metrics.NumaNodeFreeCpus.WithLabelValues("0")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.NumaNodeFragmentation.WithLabelValues("0")))
+	assert.Equal(t, 3.0, testutil.ToFloat64(metrics.NumaNodeFreeCpus.WithLabelValues("1")))
+	assert.InDelta(t, 1.0/3, testutil.ToFloat64(metrics.NumaNodeFragmentation.WithLabelValues("1")), 1e-9)
+}
+
+func TestUpdateAllocationMetrics(t *testing.T) {
+	d := Daemon{state: *getFragmentationTestState(t), options: newDaemonOptions(nil)}
+	d.state.Pods = map[string]PodMetadata{"pod": {
+		PID: "pod",
+		Containers: []Container{
+			{CID: "guaranteed", PID: "pod", Cpus: 1, QS: Guaranteed},
+			{CID: "burstable", PID: "pod", QS: Burstable},
+		},
+	}}
+	d.state.Allocated = map[string][]ctlplaneapi.CPUBucket{
+		"guaranteed": {{StartCPU: 5, EndCPU: 5}},
+		"burstable":  {{StartCPU: 0, EndCPU: 7}},
+	}
+	require.Nil(t, d.state.Topology.TakeCpu(5))
+
+	d.updateAllocationMetrics()
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.AllocatedContainers))
+	assert.Equal(t, 7.0, testutil.ToFloat64(metrics.FreeExclusiveCpus))
+
+	d.options.exclusiveCpusCap = 50
+	d.updateAllocationMetrics()
+	assert.Equal(t, 3.0, testutil.ToFloat64(metrics.FreeExclusiveCpus))
+}
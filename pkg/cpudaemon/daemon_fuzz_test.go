@@ -1,6 +1,7 @@
 package cpudaemon
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -122,7 +123,7 @@ func FuzzCreatePod(f *testing.F) {
 
 		req := createPodRequestForFuzzing(pid, podName, namespace, cid, containerName, numContainers, reqCpu, limCpu, reqMem, limMem)
 
-		resp, err := d.CreatePod(req)
+		resp, err := d.CreatePod(context.Background(), req)
 
 		if err != nil {
 			derr := DaemonError{}
@@ -165,7 +166,7 @@ func FuzzDeletePod(f *testing.F) {
 		}
 
 		req := ctlplaneapi.DeletePodRequest{PodId: pid}
-		err = d.DeletePod(&req)
+		_, err = d.DeletePod(context.Background(), &req)
 
 		if err != nil {
 			derr := DaemonError{}
@@ -223,7 +224,7 @@ func FuzzUpdatePod(f *testing.F) {
 		})
 
 		req := createPodRequestForFuzzing(pid, podName, namespace, cid, containerName, numContainers, reqCpu, limCpu, reqMem, limMem)
-		_, err = d.CreatePod(req)
+		_, err = d.CreatePod(context.Background(), req)
 
 		// We add pod and want to continue only if it was added successfully
 		if err != nil {
@@ -232,7 +233,7 @@ func FuzzUpdatePod(f *testing.F) {
 
 		reqUpdate := updatePodRequestFromCreate(t, req, numDel, numUpdate)
 		t.Log(reqUpdate)
-		resp, err := d.UpdatePod(reqUpdate)
+		resp, err := d.UpdatePod(context.Background(), reqUpdate)
 
 		require.Nil(t, err)
 		require.Equal(t, numUpdate, uint(len(resp.ContainerResources)))
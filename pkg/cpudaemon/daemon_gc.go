@@ -0,0 +1,136 @@
+package cpudaemon
+
+import (
+	"context"
+	"time"
+
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+// RunGarbageCollection checks every interval, until stop is closed, for allocations of containers not
+// referenced by any pod and frees them. Non-positive interval disables the collection.
+func (d *Daemon) RunGarbageCollection(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			d.collectOrphans()
+		}
+	}
+}
+
+// collectOrphans frees allocations of containers not referenced by any pod, which can be left after
+// partial failures, and returns their number.
+func (d *Daemon) collectOrphans() int {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+
+	orphans := d.state.orphanedContainers()
+	if len(orphans) == 0 {
+		return 0
+	}
+	for _, cid := range orphans {
+		d.logger.Info("freeing orphaned allocation", "cid", cid, "cpus", CPUSetFromBucketList(d.state.Allocated[cid]))
+	}
+	before, now := d.traceHash(), time.Now()
+	d.freeOrphans(context.Background(), orphans, now)
+	d.traceChange(traceCollectOrphans, orphans, before, now)
+	metrics.OrphanedAllocations.Add(float64(len(orphans)))
+
+	if err := d.saveState(); err != nil {
+		d.logger.Error(err, "cannot save state")
+	}
+	return len(orphans)
+}
+
+// OrphanCollectingPolicy is implemented by policies keeping bookkeeping of allocated containers outside
+// of the state, which shall follow the state after allocations of orphaned containers are freed.
+type OrphanCollectingPolicy interface {
+	CollectOrphans(ctx context.Context, freed CPUSet, s *DaemonState) error
+}
+
+var _ OrphanCollectingPolicy = &StaticPolicy{}
+
+// orphanCollectingAllocator is implemented by allocators with bookkeeping of allocated containers.
+type orphanCollectingAllocator interface {
+	collectOrphans(ctx context.Context, freed CPUSet, s *DaemonState) error
+}
+
+var _ orphanCollectingAllocator = &NumaPerNamespaceAllocator{}
+
+// CollectOrphans updates bookkeeping of the allocator after orphaned allocations are freed, given cpus
+// returned to the pool.
+func (p *StaticPolicy) CollectOrphans(ctx context.Context, freed CPUSet, s *DaemonState) error {
+	if a, ok := p.allocator.(orphanCollectingAllocator); ok {
+		return a.collectOrphans(ctx, freed, s)
+	}
+	return nil
+}
+
+// freeOrphans frees allocations of given containers and lets all policies update their bookkeeping.
+// Orphans have no pod metadata, so they cannot be deleted by policies like containers of pods.
+func (d *Daemon) freeOrphans(ctx context.Context, orphans []string, now time.Time) {
+	freed := d.state.freeOrphans(orphans, now)
+	policies := []Policy{d.policy}
+	for _, p := range d.tierPolicies {
+		policies = append(policies, p)
+	}
+	for _, p := range policies {
+		if p, ok := p.(OrphanCollectingPolicy); ok {
+			if err := p.CollectOrphans(ctx, freed, &d.state); err != nil {
+				d.logger.Error(err, "cannot update policy after freeing orphaned allocations")
+			}
+		}
+	}
+}
+
+// orphanedContainers returns ids of allocated containers which do not belong to any pod.
+func (d *DaemonState) orphanedContainers() []string {
+	referenced := make(map[string]struct{})
+	for _, pod := range d.Pods {
+		for _, c := range pod.Containers {
+			referenced[c.CID] = struct{}{}
+		}
+	}
+	orphans := []string{}
+	for cid := range d.Allocated {
+		if _, ok := referenced[cid]; !ok {
+			orphans = append(orphans, cid)
+		}
+	}
+	return orphans
+}
+
+// freeOrphans removes allocations of given containers, returns their cpus to the pool and returns the
+// freed cpus. Cpus still allocated to other containers (eg. shared by a namespace) stay allocated.
+// Orphans have no pod metadata, so cpus are returned both to the list of available cpus and to the
+// topology, regardless of the allocator.
+func (d *DaemonState) freeOrphans(orphans []string, now time.Time) CPUSet {
+	freed := CPUSet{}
+	for _, cid := range orphans {
+		freed.Merge(CPUSetFromBucketList(d.Allocated[cid]))
+		delete(d.Allocated, cid)
+		d.clearMemoryNodes(cid)
+		d.clearCgroupPath(cid)
+		d.clearAllocationExplanation(cid)
+		d.setBorrowedCpus(cid, CPUSet{})
+		d.releaseAllocatedAt(cid, now)
+	}
+	for _, buckets := range d.Allocated {
+		freed.RemoveAll(CPUSetFromBucketList(buckets))
+	}
+
+	available := CPUSetFromBucketList(d.AvailableCPUs)
+	for cpu := range freed {
+		available.Add(cpu)
+		_ = d.Topology.Return(cpu)
+	}
+	d.AvailableCPUs = available.ToCompactBucketList()
+	return freed
+}
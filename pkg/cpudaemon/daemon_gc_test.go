@@ -0,0 +1,139 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+func cpuSetForTest(t *testing.T, cpus string) CPUSet {
+	s, err := CPUSetFromString(cpus)
+	require.Nil(t, err)
+	return s
+}
+
+// allocateForTest marks cpus as allocated to the container, as allocators do.
+func allocateForTest(t *testing.T, s *DaemonState, cid string, cpus CPUSet) {
+	for cpu := range cpus {
+		require.Nil(t, s.Topology.TakeCpu(cpu))
+	}
+	s.AvailableCPUs = CPUSetFromBucketList(s.AvailableCPUs).RemoveAll(cpus).ToCompactBucketList()
+	s.Allocated[cid] = cpus.ToCompactBucketList()
+}
+
+// newDaemonForGC returns daemon with a guaranteed container of a pod allocated to cpus 1-2.
+func newDaemonForGC(t *testing.T) *Daemon {
+	d := newTestDaemon(t, &MockedPolicy{})
+	d.state.Pods["pod"] = PodMetadata{
+		PID:        "pod",
+		Containers: []Container{{CID: "container", PID: "pod", Cpus: 2, QS: Guaranteed}},
+	}
+	allocateForTest(t, &d.state, "container", cpuSetForTest(t, "1-2"))
+	return d
+}
+
+func TestCollectOrphansFreesOrphanedAllocations(t *testing.T) {
+	d := newDaemonForGC(t)
+	allocateForTest(t, &d.state, "orphan", cpuSetForTest(t, "3-4"))
+	d.state.setAllocatedAt("orphan", time.Now())
+	orphans := testutil.ToFloat64(metrics.OrphanedAllocations)
+
+	assert.Equal(t, 1, d.collectOrphans())
+
+	assert.NotContains(t, d.state.Allocated, "orphan")
+	assert.NotContains(t, d.state.AllocatedAt, "orphan")
+	assert.Contains(t, d.state.Allocated, "container")
+	available := CPUSetFromBucketList(d.state.AvailableCPUs)
+	assert.True(t, available.Contains(3))
+	assert.True(t, available.Contains(4))
+	assert.False(t, available.Contains(1))
+	for _, cpu := range []int{3, 4} {
+		node, err := d.state.Topology.FindCpu(cpu)
+		require.Nil(t, err)
+		assert.Equal(t, 1, node.NumAvailable)
+	}
+	assert.Equal(t, orphans+1, testutil.ToFloat64(metrics.OrphanedAllocations))
+}
+
+func TestCollectOrphansKeepsCpusOfOtherContainers(t *testing.T) {
+	d := newDaemonForGC(t)
+	// orphan shares cpus with the container, like containers of one namespace
+	d.state.Allocated["orphan"] = []ctlplaneapi.CPUBucket{{StartCPU: 1, EndCPU: 2}}
+
+	assert.Equal(t, 1, d.collectOrphans())
+
+	assert.False(t, CPUSetFromBucketList(d.state.AvailableCPUs).Contains(1))
+	node, err := d.state.Topology.FindCpu(1)
+	require.Nil(t, err)
+	assert.Equal(t, 0, node.NumAvailable)
+}
+
+func TestCollectOrphansUpdatesNamespaceBuckets(t *testing.T) {
+	s := getTestDaemonState(t.TempDir(), 4)
+	allocator := newMockedNumaPerNamespaceAllocator(1, true)
+	allocator.ctrl = newMockedCgroups()
+	guaranteed, burstable := getGuaranteedAndBurstableContainers()
+	burstable.PID, burstable.Namespace = "pod2", guaranteed.Namespace
+	for _, c := range []Container{burstable, guaranteed} {
+		require.Nil(t, allocator.takeCpus(context.Background(), c, s))
+		addContainerToState(s, c)
+	}
+	assertCpuState(t, s, &burstable, "1-3")
+
+	delete(s.Pods, guaranteed.PID)
+	freed := s.freeOrphans([]string{guaranteed.CID}, time.Now())
+	require.Nil(t, allocator.collectOrphans(context.Background(), freed, s))
+
+	assert.Equal(t, map[int]int{0: 1}, allocator.BucketToNumContainers)
+	assertCpuState(t, s, &burstable, "0-3")
+
+	delete(s.Pods, burstable.PID)
+	freed = s.freeOrphans([]string{burstable.CID}, time.Now())
+	require.Nil(t, allocator.collectOrphans(context.Background(), freed, s))
+
+	assert.Empty(t, allocator.BucketToNumContainers)
+	assert.Empty(t, allocator.NamespaceToBucket)
+}
+
+func TestCollectOrphansWithoutOrphans(t *testing.T) {
+	d := newDaemonForGC(t)
+	available := d.state.AvailableCPUs
+
+	assert.Equal(t, 0, d.collectOrphans())
+
+	assert.Equal(t, available, d.state.AvailableCPUs)
+	assert.Contains(t, d.state.Allocated, "container")
+}
+
+func TestRunGarbageCollection(t *testing.T) {
+	d := newDaemonForGC(t)
+	allocateForTest(t, &d.state, "orphan", cpuSetForTest(t, "3"))
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		d.RunGarbageCollection(10*time.Millisecond, stop)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		d.stateMu.Lock()
+		defer d.stateMu.Unlock()
+		_, ok := d.state.Allocated["orphan"]
+		return !ok
+	}, 3*time.Second, 10*time.Millisecond)
+	close(stop)
+	<-done
+}
+
+func TestRunGarbageCollectionDisabled(t *testing.T) {
+	d := newDaemonForGC(t)
+
+	d.RunGarbageCollection(0, nil) // returns immediately
+}
@@ -0,0 +1,128 @@
+package cpudaemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// managedCpusClaimSuffix is appended to the state path to create the file in which the daemon instance
+// claims its managed cpus.
+const managedCpusClaimSuffix = ".managed-cpus"
+
+// instanceClaim is the claim of managed cpus held by a running daemon instance. The claim is valid as
+// long as its file is locked, so claims of stopped instances are ignored.
+type instanceClaim struct {
+	file *os.File
+}
+
+// claimManagedCpus claims cpus for the daemon instance using given state path. It fails if another
+// running instance uses the same state path, or if the cpus overlap with cpus claimed by other running
+// instances keeping state in the same directory.
+func claimManagedCpus(statePath string, cpus CPUSet) (*instanceClaim, error) {
+	claimPath := statePath + managedCpusClaimSuffix
+	f, err := os.OpenFile(claimPath, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, DaemonError{ErrorType: ConfigurationError, ErrorMessage: err.Error()}
+	}
+	if err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			err = fmt.Errorf("state path %s is used by another daemon instance", statePath)
+		}
+		return nil, DaemonError{ErrorType: ConfigurationError, ErrorMessage: err.Error()}
+	}
+	claim := &instanceClaim{file: f}
+
+	if err = checkClaimsOverlap(claimPath, cpus); err == nil {
+		err = claim.write(cpus)
+	}
+	if err != nil {
+		claim.release()
+		return nil, err
+	}
+	return claim, nil
+}
+
+// checkClaimsOverlap compares cpus with cpus claimed by other running instances.
+func checkClaimsOverlap(claimPath string, cpus CPUSet) error {
+	others, err := filepath.Glob(filepath.Join(filepath.Dir(claimPath), "*"+managedCpusClaimSuffix))
+	if err != nil {
+		return DaemonError{ErrorType: ConfigurationError, ErrorMessage: err.Error()}
+	}
+	for _, other := range others {
+		if other == claimPath {
+			continue
+		}
+		otherCpus, running, err := readClaim(other)
+		if err != nil {
+			return DaemonError{ErrorType: ConfigurationError, ErrorMessage: err.Error()}
+		}
+		if !running {
+			continue
+		}
+		if overlap := cpus.Clone().RemoveAll(cpus.Clone().RemoveAll(otherCpus)); overlap.Count() > 0 {
+			return DaemonError{
+				ErrorType: ConfigurationError,
+				ErrorMessage: fmt.Sprintf(
+					"managed cpus %s overlap with cpus claimed by another daemon instance in %s",
+					overlap,
+					other,
+				),
+			}
+		}
+	}
+	return nil
+}
+
+// readClaim returns cpus claimed in the given file and whether the claiming instance is still running.
+func readClaim(path string) (CPUSet, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	defer f.Close()
+
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_SH|syscall.LOCK_NB)
+	if err == nil {
+		return nil, false, syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	}
+	if !errors.Is(err, syscall.EWOULDBLOCK) {
+		return nil, false, err
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	cpus, err := CPUSetFromString(string(b))
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot parse claim %s: %w", path, err)
+	}
+	return cpus, true, nil
+}
+
+func (c *instanceClaim) write(cpus CPUSet) error {
+	if err := c.file.Truncate(0); err != nil {
+		return DaemonError{ErrorType: ConfigurationError, ErrorMessage: err.Error()}
+	}
+	if _, err := c.file.WriteAt([]byte(cpus.ToCpuString()+"\n"), 0); err != nil {
+		return DaemonError{ErrorType: ConfigurationError, ErrorMessage: err.Error()}
+	}
+	return nil
+}
+
+// release unlocks the claim, so that its cpus can be claimed by other instances.
+func (c *instanceClaim) release() {
+	if c == nil || c.file == nil {
+		return
+	}
+	_ = syscall.Flock(int(c.file.Fd()), syscall.LOCK_UN)
+	c.file.Close()
+	c.file = nil
+}
@@ -0,0 +1,73 @@
+package cpudaemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimManagedCpusWritesClaim(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "daemon.state")
+
+	claim, err := claimManagedCpus(statePath, CPUSet{1: {}, 2: {}, 3: {}})
+	require.Nil(t, err)
+	defer claim.release()
+
+	b, err := os.ReadFile(statePath + managedCpusClaimSuffix)
+	require.Nil(t, err)
+	assert.Equal(t, "1,2,3\n", string(b))
+}
+
+func TestClaimManagedCpusAllowsDisjointInstances(t *testing.T) {
+	dir := t.TempDir()
+
+	infra, err := claimManagedCpus(filepath.Join(dir, "infra.state"), CPUSet{0: {}, 1: {}})
+	require.Nil(t, err)
+	defer infra.release()
+
+	tenant, err := claimManagedCpus(filepath.Join(dir, "tenant.state"), CPUSet{2: {}, 3: {}})
+	require.Nil(t, err)
+	tenant.release()
+}
+
+func TestClaimManagedCpusFailsOnOverlap(t *testing.T) {
+	dir := t.TempDir()
+
+	infra, err := claimManagedCpus(filepath.Join(dir, "infra.state"), CPUSet{0: {}, 1: {}})
+	require.Nil(t, err)
+	defer infra.release()
+
+	_, err = claimManagedCpus(filepath.Join(dir, "tenant.state"), CPUSet{1: {}, 2: {}})
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, ConfigurationError, daemonErr.ErrorType)
+	assert.Contains(t, daemonErr.ErrorMessage, "overlap")
+}
+
+func TestClaimManagedCpusFailsOnSameStatePath(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "daemon.state")
+
+	claim, err := claimManagedCpus(statePath, CPUSet{0: {}})
+	require.Nil(t, err)
+	defer claim.release()
+
+	_, err = claimManagedCpus(statePath, CPUSet{1: {}})
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, ConfigurationError, daemonErr.ErrorType)
+}
+
+func TestClaimManagedCpusIgnoresReleasedClaims(t *testing.T) {
+	dir := t.TempDir()
+
+	infra, err := claimManagedCpus(filepath.Join(dir, "infra.state"), CPUSet{0: {}, 1: {}})
+	require.Nil(t, err)
+	infra.release()
+
+	tenant, err := claimManagedCpus(filepath.Join(dir, "tenant.state"), CPUSet{0: {}, 1: {}})
+	require.Nil(t, err)
+	tenant.release()
+}
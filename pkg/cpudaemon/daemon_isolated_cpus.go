@@ -0,0 +1,133 @@
+package cpudaemon
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	// irqbalanceSuffix is appended to the isolated cpus file path to create the irqbalance environment file.
+	irqbalanceSuffix = ".irqbalance"
+	irqbalanceComm   = "irqbalance"
+	defaultProcPath  = "/proc"
+	// hexDigitsPerMaskWord is the number of hex digits of a 32 bit word of irqbalance cpu mask.
+	hexDigitsPerMaskWord = 8
+)
+
+// exclusiveCpus returns cpus exclusively allocated to guaranteed containers.
+func (d *DaemonState) exclusiveCpus() CPUSet {
+	cpus := CPUSet{}
+	for _, pod := range d.Pods {
+		for _, c := range allocatedContainers(pod) {
+			if c.QS == Guaranteed {
+				cpus.Merge(CPUSetFromBucketList(d.Allocated[c.CID]))
+			}
+		}
+	}
+	return cpus
+}
+
+// exportIsolatedCpus writes exclusively allocated cpus to the isolated cpus file (cpu list, eg. for
+// tuned profiles) and to the irqbalance environment file, whenever they change. Irqbalance is then
+// signalled to reload its configuration. Failures are logged only, as they do not affect allocations.
+func (d *Daemon) exportIsolatedCpus() {
+	if d.options.isolatedCpusPath == "" {
+		return
+	}
+	cpus := d.state.exclusiveCpus()
+	if d.exportedIsolatedCpus != nil && d.exportedIsolatedCpus.ToCpuString() == cpus.ToCpuString() {
+		return
+	}
+
+	err := writeFileAtomically(d.options.isolatedCpusPath, cpus.ToCpuString()+"\n")
+	if err == nil {
+		err = writeFileAtomically(d.options.isolatedCpusPath+irqbalanceSuffix, irqbalanceEnv(cpus))
+	}
+	if err != nil {
+		d.logger.Error(err, "cannot export isolated cpus", "path", d.options.isolatedCpusPath)
+		return
+	}
+	d.exportedIsolatedCpus = cpus
+	d.logger.Info("isolated cpus exported", "cpus", cpus)
+
+	if d.options.irqbalanceReload {
+		d.reloadIrqbalance()
+	}
+}
+
+// reloadIrqbalance sends SIGHUP to all irqbalance processes, so that they read banned cpus again.
+func (d *Daemon) reloadIrqbalance() {
+	pids, err := findProcesses(d.options.procPath, irqbalanceComm)
+	if err != nil {
+		d.logger.Error(err, "cannot find irqbalance processes")
+		return
+	}
+	for _, pid := range pids {
+		if err := d.options.signal(pid, syscall.SIGHUP); err != nil {
+			d.logger.Error(err, "cannot signal irqbalance", "pid", pid)
+		}
+	}
+}
+
+// irqbalanceEnv returns irqbalance environment file content banning given cpus.
+func irqbalanceEnv(cpus CPUSet) string {
+	return fmt.Sprintf(
+		"IRQBALANCE_BANNED_CPUS=%s\nIRQBALANCE_BANNED_CPULIST=%s\n",
+		cpuMask(cpus),
+		cpus.ToCpuString(),
+	)
+}
+
+// cpuMask returns cpus as a hex mask with comma separated 32 bit words, eg. "00000001,0000000f".
+func cpuMask(cpus CPUSet) string {
+	mask := new(big.Int)
+	for cpu := range cpus {
+		mask.SetBit(mask, cpu, 1)
+	}
+	hex := mask.Text(16)
+	if pad := len(hex) % hexDigitsPerMaskWord; pad != 0 {
+		hex = strings.Repeat("0", hexDigitsPerMaskWord-pad) + hex
+	}
+	words := make([]string, 0, len(hex)/hexDigitsPerMaskWord)
+	for i := 0; i < len(hex); i += hexDigitsPerMaskWord {
+		words = append(words, hex[i:i+hexDigitsPerMaskWord])
+	}
+	return strings.Join(words, ",")
+}
+
+// findProcesses returns pids of processes with given command name.
+func findProcesses(procPath string, comm string) ([]int, error) {
+	entries, err := os.ReadDir(procPath)
+	if err != nil {
+		return nil, err
+	}
+	pids := []int{}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(procPath, entry.Name(), "comm"))
+		if err != nil {
+			continue // process already finished
+		}
+		if strings.TrimSpace(string(b)) == comm {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+// writeFileAtomically replaces the file content, so that readers never see partially written file.
+func writeFileAtomically(path string, content string) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), daemonFilePermission); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,162 @@
+package cpudaemon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+type signalRecorder struct {
+	pids []int
+	err  error
+}
+
+func (r *signalRecorder) signal(pid int, sig syscall.Signal) error {
+	if sig == syscall.SIGHUP {
+		r.pids = append(r.pids, pid)
+	}
+	return r.err
+}
+
+func addProcessForTest(t *testing.T, procPath string, pid string, comm string) {
+	require.Nil(t, os.MkdirAll(filepath.Join(procPath, pid), 0o755))
+	require.Nil(t, os.WriteFile(filepath.Join(procPath, pid, "comm"), []byte(comm+"\n"), 0o600))
+}
+
+func newDaemonForIsolatedCpus(t *testing.T, reload bool) (*Daemon, string, *signalRecorder) {
+	dir := t.TempDir()
+	procPath := filepath.Join(dir, "proc")
+	addProcessForTest(t, procPath, "1", "systemd")
+	addProcessForTest(t, procPath, "42", "irqbalance")
+	require.Nil(t, os.WriteFile(filepath.Join(procPath, "uptime"), []byte("1.0 1.0\n"), 0o600))
+
+	recorder := &signalRecorder{}
+	options := newDaemonOptions([]Option{WithIsolatedCpusExport(filepath.Join(dir, "isolated_cpus"), reload)})
+	options.procPath = procPath
+	options.signal = recorder.signal
+	d := &Daemon{
+		state:   guaranteedAndBurstablePodState(),
+		logger:  logr.Discard(),
+		options: options,
+	}
+	return d, filepath.Join(dir, "isolated_cpus"), recorder
+}
+
+// guaranteedAndBurstablePodState returns state with a pod of a guaranteed container allocated cpus 1-2
+// and a burstable container allocated cpus 3-7.
+func guaranteedAndBurstablePodState() DaemonState {
+	return DaemonState{
+		Pods: map[string]PodMetadata{
+			"pod": {
+				PID: "pod",
+				Containers: []Container{
+					{CID: "guaranteed", PID: "pod", Cpus: 2, QS: Guaranteed},
+					{CID: "burstable", PID: "pod", Cpus: 1, QS: Burstable},
+				},
+			},
+		},
+		Allocated: map[string][]ctlplaneapi.CPUBucket{
+			"guaranteed": {{StartCPU: 1, EndCPU: 2}},
+			"burstable":  {{StartCPU: 3, EndCPU: 7}},
+		},
+	}
+}
+
+func readFileForTest(t *testing.T, path string) string {
+	b, err := os.ReadFile(path)
+	require.Nil(t, err)
+	return string(b)
+}
+
+func TestExclusiveCpus(t *testing.T) {
+	d, _, _ := newDaemonForIsolatedCpus(t, false)
+	assert.Equal(t, "1,2", d.state.exclusiveCpus().ToCpuString())
+
+	pod := d.state.Pods["pod"]
+	pod.LeaseExpired = true
+	d.state.Pods["pod"] = pod
+	assert.Equal(t, 0, d.state.exclusiveCpus().Count())
+}
+
+func TestCpuMask(t *testing.T) {
+	assert.Equal(t, "00000000", cpuMask(CPUSet{}))
+	assert.Equal(t, "00000006", cpuMask(cpuSetForTest(t, "1-2")))
+	assert.Equal(t, "00000001,80000000", cpuMask(cpuSetForTest(t, "31-32")))
+}
+
+func TestExportIsolatedCpusWritesFiles(t *testing.T) {
+	d, path, recorder := newDaemonForIsolatedCpus(t, true)
+
+	d.exportIsolatedCpus()
+
+	assert.Equal(t, "1,2\n", readFileForTest(t, path))
+	assert.Equal(
+		t,
+		"IRQBALANCE_BANNED_CPUS=00000006\nIRQBALANCE_BANNED_CPULIST=1,2\n",
+		readFileForTest(t, path+irqbalanceSuffix),
+	)
+	assert.Equal(t, []int{42}, recorder.pids)
+}
+
+func TestExportIsolatedCpusOnlyWhenChanged(t *testing.T) {
+	d, path, recorder := newDaemonForIsolatedCpus(t, true)
+	d.exportIsolatedCpus()
+	require.Nil(t, os.Remove(path))
+
+	d.exportIsolatedCpus()
+	assert.NoFileExists(t, path)
+	assert.Len(t, recorder.pids, 1)
+
+	d.state.Allocated["guaranteed"] = []ctlplaneapi.CPUBucket{{StartCPU: 4, EndCPU: 4}}
+	d.exportIsolatedCpus()
+	assert.Equal(t, "4\n", readFileForTest(t, path))
+	assert.Len(t, recorder.pids, 2)
+}
+
+func TestExportIsolatedCpusWithoutIrqbalanceReload(t *testing.T) {
+	d, path, recorder := newDaemonForIsolatedCpus(t, false)
+
+	d.exportIsolatedCpus()
+
+	assert.FileExists(t, path)
+	assert.Empty(t, recorder.pids)
+}
+
+func TestExportIsolatedCpusDisabled(t *testing.T) {
+	d, path, recorder := newDaemonForIsolatedCpus(t, true)
+	d.options.isolatedCpusPath = ""
+
+	d.exportIsolatedCpus()
+
+	assert.NoFileExists(t, path)
+	assert.Empty(t, recorder.pids)
+}
+
+func TestExportIsolatedCpusRetriesAfterWriteFailure(t *testing.T) {
+	d, path, _ := newDaemonForIsolatedCpus(t, false)
+	d.options.isolatedCpusPath = filepath.Join(path, "missing", "isolated_cpus")
+
+	d.exportIsolatedCpus()
+	assert.Nil(t, d.exportedIsolatedCpus)
+
+	d.options.isolatedCpusPath = path
+	d.exportIsolatedCpus()
+	assert.Equal(t, "1,2\n", readFileForTest(t, path))
+}
+
+func TestReloadIrqbalanceIgnoresSignalErrors(t *testing.T) {
+	d, _, recorder := newDaemonForIsolatedCpus(t, true)
+	addProcessForTest(t, d.options.procPath, "43", "irqbalance")
+	recorder.err = errors.New("no such process")
+
+	d.reloadIrqbalance()
+
+	assert.ElementsMatch(t, []int{42, 43}, recorder.pids)
+}
@@ -0,0 +1,138 @@
+package cpudaemon
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// KindKubepodsCgroup is the kubepods cgroup created by kubelet of a kind node, relative to the cgroup of
+// the node container. It is the cgroup of kind pods when the daemon sees the node container cgroup as
+// the root of the hierarchy (eg. with private cgroup namespace).
+const KindKubepodsCgroup = "kubelet/kubepods"
+
+// maxKindCgroupDepth limits the search of nested kind cgroups, enough for layouts of docker and podman
+// with both cgroupfs and systemd drivers (eg. system.slice/docker-<id>.scope/kubelet/kubepods).
+const maxKindCgroupDepth = 4
+
+// procSelfCgroup lists cgroups of the daemon process.
+var procSelfCgroup = "/proc/self/cgroup"
+
+// WithKindKubepodsCgroup sets kubepods cgroup of the kind node (relative to the cpuset hierarchy root),
+// which is the parent of container cgroups with Kind runtime. Defaults to KindKubepodsCgroup.
+func WithKindKubepodsCgroup(cgroup string) CgroupOption {
+	return func(cgc *CgroupControllerImpl) {
+		cgc.kindKubepodsCgroup = cgroup
+	}
+}
+
+// DetectKindKubepodsCgroup returns kubepods cgroup of the kind node the daemon runs in, relative to the
+// cpuset hierarchy root under cgroupPath. Depending on the cgroup version and the cgroup namespace of
+// the daemon, the cgroup of the node container can be nested in the hierarchy (eg.
+// docker/<id>/kubelet/kubepods), so the cgroup of the daemon itself is checked first. If it is not in a
+// kind pod, the hierarchy is searched, which succeeds only if there is a single kind node.
+func DetectKindKubepodsCgroup(cgroupPath string, version CgroupVersion) (string, error) {
+	root, _ := version.cpusetPaths(cgroupPath)
+	if cgroup, ok := kindCgroupOfProcess(procSelfCgroup, version); ok && isDir(filepath.Join(root, cgroup)) {
+		return cgroup, nil
+	}
+	return findKindKubepodsCgroup(root)
+}
+
+// kindCgroupOfProcess returns kubepods cgroup of the kind node from the cpuset cgroup of the process
+// listed in given cgroup file, if the process runs in a kind pod.
+func kindCgroupOfProcess(cgroupFile string, version CgroupVersion) (string, bool) {
+	f, err := os.Open(cgroupFile)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	marker := "/" + KindKubepodsCgroup
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// hierarchy-ID:controller-list:cgroup-path
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 || !cpusetHierarchy(fields[1], version) {
+			continue
+		}
+		cgroup := fields[2]
+		if i := strings.Index(cgroup+"/", marker+"/"); i >= 0 {
+			return strings.TrimPrefix(cgroup[:i+len(marker)], "/"), true
+		}
+	}
+	return "", false
+}
+
+// cpusetHierarchy checks if the controller list of /proc/<pid>/cgroup line belongs to the hierarchy
+// with cpusets.
+func cpusetHierarchy(controllers string, version CgroupVersion) bool {
+	if version.unified() {
+		return controllers == ""
+	}
+	for _, c := range strings.Split(controllers, ",") {
+		if c == "cpuset" {
+			return true
+		}
+	}
+	return false
+}
+
+// findKindKubepodsCgroup searches the hierarchy for kubepods cgroups of kind nodes and returns the
+// shallowest one. Nested kind nodes are not searched. Several cgroups at the same depth belong to
+// several kind nodes, the one of the daemon cannot be told then.
+func findKindKubepodsCgroup(root string) (string, error) {
+	found := []string{}
+	level := []string{""}
+	for depth := 0; depth < maxKindCgroupDepth && len(found) == 0; depth++ {
+		next := []string{}
+		for _, dir := range level {
+			entries, err := os.ReadDir(filepath.Join(root, dir))
+			if err != nil {
+				continue // cgroups can disappear while searched
+			}
+			for _, e := range entries {
+				if !e.IsDir() {
+					continue
+				}
+				cgroup := path.Join(dir, e.Name())
+				if cgroup == KindKubepodsCgroup || strings.HasSuffix(cgroup, "/"+KindKubepodsCgroup) {
+					found = append(found, cgroup)
+					continue
+				}
+				next = append(next, cgroup)
+			}
+		}
+		level = next
+	}
+
+	switch len(found) {
+	case 0:
+		return "", DaemonError{
+			ErrorType:    ConfigurationError,
+			ErrorMessage: fmt.Sprintf("cgroup %s of kind node not found in %s", KindKubepodsCgroup, root),
+		}
+	case 1:
+		return found[0], nil
+	default:
+		sort.Strings(found)
+		return "", DaemonError{
+			ErrorType: ConfigurationError,
+			ErrorMessage: fmt.Sprintf(
+				"cgroups of several kind nodes found in %s: %s, run the daemon in a pod of the kind node",
+				root,
+				strings.Join(found, ", "),
+			),
+		}
+	}
+}
+
+// isDir checks if the path is an existing directory.
+func isDir(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && info.IsDir()
+}
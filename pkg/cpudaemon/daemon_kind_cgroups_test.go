@@ -0,0 +1,187 @@
+package cpudaemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createCgroups creates cgroup directories under the root.
+func createCgroups(t *testing.T, root string, cgroups ...string) {
+	for _, cgroup := range cgroups {
+		require.Nil(t, os.MkdirAll(filepath.Join(root, cgroup), 0o755))
+	}
+}
+
+// writeProcCgroup writes /proc/self/cgroup replacement with given lines.
+func writeProcCgroup(t *testing.T, content string) {
+	file := filepath.Join(t.TempDir(), "cgroup")
+	require.Nil(t, os.WriteFile(file, []byte(content), 0o644))
+	orig := procSelfCgroup
+	procSelfCgroup = file
+	t.Cleanup(func() { procSelfCgroup = orig })
+}
+
+func TestFindKindKubepodsCgroup(t *testing.T) {
+	tests := []struct {
+		name     string
+		cgroups  []string
+		expected string
+	}{
+		{
+			name:     "private cgroup namespace",
+			cgroups:  []string{"init.scope", "kubelet/kubepods/burstable", "system.slice/containerd.service"},
+			expected: "kubelet/kubepods",
+		},
+		{
+			name:     "docker with cgroupfs driver",
+			cgroups:  []string{"docker/0123abcd/kubelet/kubepods/besteffort", "docker/0123abcd/system.slice"},
+			expected: "docker/0123abcd/kubelet/kubepods",
+		},
+		{
+			name:     "docker with systemd driver",
+			cgroups:  []string{"system.slice/docker-0123abcd.scope/kubelet/kubepods", "user.slice"},
+			expected: "system.slice/docker-0123abcd.scope/kubelet/kubepods",
+		},
+		{
+			name:     "podman",
+			cgroups:  []string{"machine.slice/libpod-0123abcd.scope/kubelet/kubepods/burstable"},
+			expected: "machine.slice/libpod-0123abcd.scope/kubelet/kubepods",
+		},
+		{
+			name: "nested kind node",
+			cgroups: []string{
+				"kubelet/kubepods",
+				"docker/0123abcd/kubelet/kubepods",
+			},
+			expected: "kubelet/kubepods",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			createCgroups(t, root, tc.cgroups...)
+
+			cgroup, err := findKindKubepodsCgroup(root)
+
+			require.Nil(t, err)
+			assert.Equal(t, tc.expected, cgroup)
+		})
+	}
+}
+
+func TestFindKindKubepodsCgroupFails(t *testing.T) {
+	for _, cgroups := range [][]string{
+		{"kubepods.slice", "docker/0123abcd/kubepods"},
+		{"docker/0123abcd/kubelet/kubepods", "docker/4567cdef/kubelet/kubepods"},
+		{"a/b/c/d/kubelet/kubepods"},
+	} {
+		root := t.TempDir()
+		createCgroups(t, root, cgroups...)
+
+		_, err := findKindKubepodsCgroup(root)
+
+		var daemonErr DaemonError
+		require.ErrorAs(t, err, &daemonErr, cgroups)
+		assert.Equal(t, ConfigurationError, daemonErr.ErrorType)
+	}
+}
+
+func TestKindCgroupOfProcess(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		version  CgroupVersion
+		expected string
+		found    bool
+	}{
+		{
+			name:     "cgroups v2",
+			content:  "0::/system.slice/docker-0123abcd.scope/kubelet/kubepods/besteffort/podpid/cid\n",
+			version:  CgroupV2,
+			expected: "system.slice/docker-0123abcd.scope/kubelet/kubepods",
+			found:    true,
+		},
+		{
+			name: "cgroups v1",
+			content: "12:memory:/docker/4567cdef/kubelet/kubepods/podpid/cid\n" +
+				"5:cpu,cpuacct:/docker/0123abcd/kubelet/kubepods/podpid/cid\n" +
+				"3:cpuset:/docker/0123abcd/kubelet/kubepods/podpid/cid\n",
+			version:  CgroupV1,
+			expected: "docker/0123abcd/kubelet/kubepods",
+			found:    true,
+		},
+		{
+			name:     "private cgroup namespace",
+			content:  "0::/kubelet/kubepods/burstable/podpid/cid\n",
+			version:  CgroupV2,
+			expected: "kubelet/kubepods",
+			found:    true,
+		},
+		{
+			name:    "not in kind pod",
+			content: "0::/kubepods/burstable/podpid/cid\n",
+			version: CgroupV2,
+		},
+		{
+			name:    "similar cgroup name",
+			content: "0::/kubelet/kubepods-old/cid\n",
+			version: CgroupV2,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			writeProcCgroup(t, tc.content)
+
+			cgroup, found := kindCgroupOfProcess(procSelfCgroup, tc.version)
+
+			assert.Equal(t, tc.found, found)
+			assert.Equal(t, tc.expected, cgroup)
+		})
+	}
+}
+
+func TestDetectKindKubepodsCgroup(t *testing.T) {
+	root := t.TempDir()
+	createCgroups(t, root, "docker/0123abcd/kubelet/kubepods", "docker/4567cdef/kubelet/kubepods")
+	writeProcCgroup(t, "0::/docker/4567cdef/kubelet/kubepods/podpid/cid\n")
+
+	cgroup, err := DetectKindKubepodsCgroup(root, CgroupV2)
+
+	require.Nil(t, err)
+	assert.Equal(t, "docker/4567cdef/kubelet/kubepods", cgroup, "cgroup of the daemon shall be preferred")
+}
+
+func TestDetectKindKubepodsCgroupSearchesIfDaemonCgroupIsNotVisible(t *testing.T) {
+	root := t.TempDir()
+	createCgroups(t, root, "cpuset/kubelet/kubepods")
+	writeProcCgroup(t, "3:cpuset:/docker/0123abcd/kubelet/kubepods/podpid/cid\n")
+
+	cgroup, err := DetectKindKubepodsCgroup(root, CgroupV1)
+
+	require.Nil(t, err)
+	assert.Equal(t, KindKubepodsCgroup, cgroup)
+}
+
+func TestKindCgroupPath(t *testing.T) {
+	c := Container{CID: "containerd://cid", PID: "pid-01", QS: Guaranteed}
+	cgc := NewCgroupController(
+		Kind,
+		DriverCgroupfs,
+		logr.Discard(),
+		WithControllerCgroupVersion(CgroupV2),
+		WithKindKubepodsCgroup("docker/0123abcd/kubelet/kubepods"),
+	)
+
+	assert.Equal(t, "/sys/fs/cgroup/docker/0123abcd/kubelet/kubepods/podpid-01/cid", cgc.CgroupPath("/sys/fs/cgroup", c))
+	assert.Equal(
+		t,
+		"/sys/fs/cgroup/kubelet/kubepods/podpid-01/cid",
+		NewCgroupController(Kind, DriverCgroupfs, logr.Discard(), WithControllerCgroupVersion(CgroupV2)).
+			CgroupPath("/sys/fs/cgroup", c),
+	)
+}
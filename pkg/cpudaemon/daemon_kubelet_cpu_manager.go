@@ -0,0 +1,201 @@
+package cpudaemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"resourcemanagement.controlplane/pkg/numautils"
+)
+
+// DefaultKubeletCPUManagerStatePath is the default location of kubelet cpu manager checkpoint.
+const DefaultKubeletCPUManagerStatePath = "/var/lib/kubelet/cpu_manager_state"
+
+const kubeletStaticPolicy = "static"
+
+// KubeletCoexistence defines behaviour of the daemon when kubelet cpu manager static policy is enabled.
+type KubeletCoexistence int
+
+const (
+	// KubeletCoexistenceRefuse makes the daemon refuse to start if kubelet static policy is enabled.
+	KubeletCoexistenceRefuse KubeletCoexistence = iota
+	// KubeletCoexistenceCooperate makes the daemon avoid cpus exclusively assigned by kubelet.
+	KubeletCoexistenceCooperate
+	// KubeletCoexistenceIgnore disables detection of kubelet cpu manager.
+	KubeletCoexistenceIgnore
+)
+
+// KubeletCPUManagerState is the checkpoint of kubelet cpu manager.
+type KubeletCPUManagerState struct {
+	PolicyName    string                       `json:"policyName"`
+	DefaultCPUSet string                       `json:"defaultCpuSet"`
+	Entries       map[string]map[string]string `json:"entries,omitempty"` // pod uid -> container name -> cpuset
+}
+
+// LoadKubeletCPUManagerState reads kubelet cpu manager checkpoint from given path.
+func LoadKubeletCPUManagerState(path string) (*KubeletCPUManagerState, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := KubeletCPUManagerState{}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("cannot parse kubelet cpu manager state %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// StaticPolicy checks if kubelet assigns exclusive cpus to containers.
+func (k *KubeletCPUManagerState) StaticPolicy() bool {
+	return k.PolicyName == kubeletStaticPolicy
+}
+
+// AssignedCpus returns cpus exclusively assigned by kubelet to containers.
+func (k *KubeletCPUManagerState) AssignedCpus() (CPUSet, error) {
+	cpus := CPUSet{}
+	for pod, containers := range k.Entries {
+		for container, cpuSet := range containers {
+			assigned, err := CPUSetFromString(cpuSet)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse cpus of container %s of pod %s: %w", container, pod, err)
+			}
+			cpus.Merge(assigned)
+		}
+	}
+	return cpus, nil
+}
+
+// checkKubeletCPUManager detects kubelet cpu manager static policy and, depending on coexistence mode,
+// fails or makes cpus assigned by kubelet unavailable. Missing kubelet state means that cpu manager is
+// not used.
+func (d *Daemon) checkKubeletCPUManager() error {
+	if d.options.kubeletCoexistence == KubeletCoexistenceIgnore || d.options.kubeletStatePath == "" {
+		return nil
+	}
+	k, err := LoadKubeletCPUManagerState(d.options.kubeletStatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		d.logger.Info("kubelet cpu manager state not found", "path", d.options.kubeletStatePath)
+		k, err = &KubeletCPUManagerState{}, nil
+	}
+	if err != nil {
+		return DaemonError{ErrorType: ConfigurationError, ErrorMessage: err.Error()}
+	}
+	if k.StaticPolicy() && d.options.kubeletCoexistence == KubeletCoexistenceRefuse {
+		return DaemonError{
+			ErrorType:    ConfigurationError,
+			ErrorMessage: "kubelet cpu manager static policy is enabled, use cooperative mode or disable it",
+		}
+	}
+	if d.options.kubeletCoexistence == KubeletCoexistenceCooperate {
+		// also releases cpus assigned by kubelet before the restart of the daemon
+		_, err = d.applyKubeletState(k)
+	}
+	return err
+}
+
+// applyKubeletState makes cpus assigned by kubelet unavailable to the daemon and returns cpus which
+// could not be taken, because they are allocated by the daemon.
+func (d *Daemon) applyKubeletState(k *KubeletCPUManagerState) (CPUSet, error) {
+	assigned := CPUSet{}
+	if k.StaticPolicy() {
+		var err error
+		if assigned, err = k.AssignedCpus(); err != nil {
+			return nil, DaemonError{ErrorType: ConfigurationError, ErrorMessage: err.Error()}
+		}
+	}
+
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+
+	before := d.traceHash()
+	conflicts := d.state.setKubeletCpus(assigned)
+	d.traceChange(traceKubeletCpus, []string{assigned.ToCpuString()}, before, time.Now())
+	if conflicts.Count() > 0 {
+		d.logger.Info("cpus assigned by kubelet are already allocated by the daemon", "cpus", conflicts)
+	}
+	d.logger.Info("cooperating with kubelet cpu manager", "kubeletCpus", CPUSetFromBucketList(d.state.KubeletCPUs))
+	if err := d.saveState(); err != nil {
+		return nil, *err
+	}
+	return conflicts, nil
+}
+
+// WatchKubeletCPUManager refreshes cpus assigned by kubelet whenever kubelet cpu manager state
+// changes, or while some of them are still allocated by the daemon. It checks the state every interval
+// until stop is closed, and does nothing outside of the cooperative mode.
+func (d *Daemon) WatchKubeletCPUManager(interval time.Duration, stop <-chan struct{}) {
+	if d.options.kubeletCoexistence != KubeletCoexistenceCooperate || d.options.kubeletStatePath == "" {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		lastModified time.Time // zero, so that the state is refreshed on the first tick
+		conflicts    = CPUSet{}
+	)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		info, err := os.Stat(d.options.kubeletStatePath)
+		if err != nil {
+			d.logger.V(2).Info("cannot stat kubelet cpu manager state", "error", err)
+			continue
+		}
+		if info.ModTime().Equal(lastModified) && conflicts.Count() == 0 {
+			continue
+		}
+		k, err := LoadKubeletCPUManagerState(d.options.kubeletStatePath)
+		if err != nil {
+			d.logger.Error(err, "cannot load kubelet cpu manager state")
+			continue
+		}
+		if conflicts, err = d.applyKubeletState(k); err != nil {
+			d.logger.Error(err, "cannot refresh cpus assigned by kubelet")
+			continue
+		}
+		lastModified = info.ModTime()
+	}
+}
+
+// setKubeletCpus makes cpus assigned by kubelet unavailable and returns to the pool cpus no longer
+// assigned by kubelet. Cpus allocated by the daemon cannot be taken and are returned as conflicts.
+func (d *DaemonState) setKubeletCpus(assigned CPUSet) CPUSet {
+	current := CPUSetFromBucketList(d.KubeletCPUs)
+	available := CPUSetFromBucketList(d.AvailableCPUs)
+	allocated := CPUSet{}
+	for _, buckets := range d.Allocated {
+		allocated.Merge(CPUSetFromBucketList(buckets))
+	}
+
+	for cpu := range current.Clone().RemoveAll(assigned) {
+		available.Add(cpu)
+		_ = d.Topology.Return(cpu)
+		current.Remove(cpu)
+	}
+
+	conflicts := CPUSet{}
+	for cpu := range assigned.Clone().RemoveAll(current) {
+		if allocated.Contains(cpu) {
+			conflicts.Add(cpu)
+			continue
+		}
+		if err := d.Topology.TakeCpu(cpu); errors.Is(err, numautils.ErrNotFound) && !available.Contains(cpu) {
+			continue // cpu not managed by the daemon
+		}
+		available.Remove(cpu)
+		current.Add(cpu)
+	}
+
+	d.AvailableCPUs = available.ToCompactBucketList()
+	d.KubeletCPUs = nil
+	if current.Count() > 0 {
+		d.KubeletCPUs = current.ToCompactBucketList()
+	}
+	return conflicts
+}
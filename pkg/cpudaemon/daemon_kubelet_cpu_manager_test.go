@@ -0,0 +1,223 @@
+package cpudaemon
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+const (
+	staticKubeletState = "testdata/kubelet/static_cpu_manager_state"
+	noneKubeletState   = "testdata/kubelet/none_cpu_manager_state"
+)
+
+func newDaemonWithKubeletState(path string, mode KubeletCoexistence) (*Daemon, error) {
+	return New(
+		"testdata/no_state",
+		"testdata/node_info",
+		"daemon.state",
+		&MockedPolicy{},
+		logr.Discard(),
+		WithKubeletCPUManager(path, mode),
+	)
+}
+
+func TestLoadKubeletCPUManagerState(t *testing.T) {
+	k, err := LoadKubeletCPUManagerState(staticKubeletState)
+	require.Nil(t, err)
+	assert.True(t, k.StaticPolicy())
+	cpus, err := k.AssignedCpus()
+	require.Nil(t, err)
+	assert.Equal(t, "2,3,6", cpus.ToCpuString())
+
+	k, err = LoadKubeletCPUManagerState(noneKubeletState)
+	require.Nil(t, err)
+	assert.False(t, k.StaticPolicy())
+	cpus, err = k.AssignedCpus()
+	require.Nil(t, err)
+	assert.Empty(t, cpus)
+}
+
+func TestAssignedCpusFailsWithInvalidCpuset(t *testing.T) {
+	k := KubeletCPUManagerState{
+		PolicyName: kubeletStaticPolicy,
+		Entries:    map[string]map[string]string{"pod": {"app": "a-b"}},
+	}
+	_, err := k.AssignedCpus()
+	assert.NotNil(t, err)
+}
+
+func TestNewDaemonRefusesKubeletStaticPolicy(t *testing.T) {
+	_, tearDown := setupTest()
+	defer tearDown(t)
+
+	_, err := newDaemonWithKubeletState(staticKubeletState, KubeletCoexistenceRefuse)
+
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, ConfigurationError, daemonErr.ErrorType)
+}
+
+func TestNewDaemonWithoutKubeletStaticPolicy(t *testing.T) {
+	for _, path := range []string{noneKubeletState, "testdata/kubelet/missing"} {
+		_, tearDown := setupTest()
+		d, err := newDaemonWithKubeletState(path, KubeletCoexistenceRefuse)
+		require.Nil(t, err)
+		assert.Empty(t, d.state.KubeletCPUs)
+		tearDown(t)
+	}
+}
+
+func TestNewDaemonIgnoresKubeletStaticPolicy(t *testing.T) {
+	_, tearDown := setupTest()
+	defer tearDown(t)
+
+	d, err := newDaemonWithKubeletState(staticKubeletState, KubeletCoexistenceIgnore)
+	require.Nil(t, err)
+	assert.Empty(t, d.state.KubeletCPUs)
+}
+
+func TestNewDaemonCooperatesWithKubeletStaticPolicy(t *testing.T) {
+	_, tearDown := setupTest()
+	defer tearDown(t)
+
+	d, err := newDaemonWithKubeletState(staticKubeletState, KubeletCoexistenceCooperate)
+	require.Nil(t, err)
+
+	assert.Equal(t, []ctlplaneapi.CPUBucket{{StartCPU: 2, EndCPU: 3}, {StartCPU: 6, EndCPU: 6}}, d.state.KubeletCPUs)
+	available := CPUSetFromBucketList(d.state.AvailableCPUs)
+	for _, cpu := range []int{2, 3, 6} {
+		assert.False(t, available.Contains(cpu))
+		leaf, err := d.state.Topology.FindCpu(cpu)
+		require.Nil(t, err)
+		assert.False(t, leaf.Available())
+	}
+	leaf, err := d.state.Topology.FindCpu(4)
+	require.Nil(t, err)
+	assert.True(t, leaf.Available())
+}
+
+func TestSetKubeletCpusReturnsReleasedCpus(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	s, err := newState("testdata/no_state", "testdata/node_info", daemonStateFile)
+	require.Nil(t, err)
+
+	assert.Empty(t, s.setKubeletCpus(CPUSet{2: {}, 3: {}}))
+	assert.Empty(t, s.setKubeletCpus(CPUSet{3: {}, 4: {}}))
+
+	assert.Equal(t, []ctlplaneapi.CPUBucket{{StartCPU: 3, EndCPU: 4}}, s.KubeletCPUs)
+	available := CPUSetFromBucketList(s.AvailableCPUs)
+	assert.True(t, available.Contains(2))
+	assert.False(t, available.Contains(3))
+	assert.False(t, available.Contains(4))
+	leaf, err := s.Topology.FindCpu(2)
+	require.Nil(t, err)
+	assert.True(t, leaf.Available())
+
+	s.setKubeletCpus(CPUSet{})
+	assert.Nil(t, s.KubeletCPUs)
+	assert.True(t, CPUSetFromBucketList(s.AvailableCPUs).Contains(4))
+}
+
+func TestSetKubeletCpusReportsConflicts(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	s, err := newState("testdata/no_state", "testdata/node_info", daemonStateFile)
+	require.Nil(t, err)
+	require.Nil(t, s.Topology.TakeCpu(5))
+	s.Allocated["container"] = []ctlplaneapi.CPUBucket{{StartCPU: 5, EndCPU: 5}}
+
+	conflicts := s.setKubeletCpus(CPUSet{5: {}, 6: {}, 200: {}})
+
+	assert.Equal(t, "5", conflicts.ToCpuString())
+	assert.Equal(t, []ctlplaneapi.CPUBucket{{StartCPU: 6, EndCPU: 6}}, s.KubeletCPUs)
+}
+
+func writeKubeletState(t *testing.T, path string, entries map[string]map[string]string, modified time.Time) {
+	b, err := json.Marshal(KubeletCPUManagerState{PolicyName: kubeletStaticPolicy, Entries: entries})
+	require.Nil(t, err)
+	require.Nil(t, os.WriteFile(path, b, 0o600))
+	require.Nil(t, os.Chtimes(path, modified, modified))
+}
+
+func kubeletCpus(d *Daemon) string {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	return CPUSetFromBucketList(d.state.KubeletCPUs).ToCpuString()
+}
+
+func TestWatchKubeletCPUManagerRefreshesCpus(t *testing.T) {
+	_, tearDown := setupTest()
+	defer tearDown(t)
+	path := filepath.Join(t.TempDir(), "cpu_manager_state")
+	now := time.Now()
+	writeKubeletState(t, path, map[string]map[string]string{"pod-1": {"app": "2-3"}}, now)
+	d, err := newDaemonWithKubeletState(path, KubeletCoexistenceCooperate)
+	require.Nil(t, err)
+	require.Equal(t, "2,3", kubeletCpus(d))
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go d.WatchKubeletCPUManager(time.Millisecond, stop)
+
+	writeKubeletState(t, path, map[string]map[string]string{"pod-2": {"app": "6"}}, now.Add(time.Second))
+	assert.Eventually(t, func() bool { return kubeletCpus(d) == "6" }, time.Second, time.Millisecond)
+}
+
+func TestWatchKubeletCPUManagerRetriesConflicts(t *testing.T) {
+	_, tearDown := setupTest()
+	defer tearDown(t)
+	path := filepath.Join(t.TempDir(), "cpu_manager_state")
+	now := time.Now()
+	writeKubeletState(t, path, map[string]map[string]string{}, now)
+	d, err := newDaemonWithKubeletState(path, KubeletCoexistenceCooperate)
+	require.Nil(t, err)
+	d.stateMu.Lock()
+	require.Nil(t, d.state.Topology.TakeCpu(5))
+	d.state.Allocated["container"] = []ctlplaneapi.CPUBucket{{StartCPU: 5, EndCPU: 5}}
+	d.stateMu.Unlock()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go d.WatchKubeletCPUManager(time.Millisecond, stop)
+
+	writeKubeletState(t, path, map[string]map[string]string{"pod-1": {"app": "5-6"}}, now.Add(time.Second))
+	assert.Eventually(t, func() bool { return kubeletCpus(d) == "6" }, time.Second, time.Millisecond)
+
+	d.stateMu.Lock()
+	delete(d.state.Allocated, "container")
+	require.Nil(t, d.state.Topology.Return(5))
+	d.stateMu.Unlock()
+	assert.Eventually(t, func() bool { return kubeletCpus(d) == "5,6" }, time.Second, time.Millisecond)
+}
+
+func TestWatchKubeletCPUManagerOutsideCooperativeMode(t *testing.T) {
+	_, tearDown := setupTest()
+	defer tearDown(t)
+	d, err := newDaemonWithKubeletState(noneKubeletState, KubeletCoexistenceRefuse)
+	require.Nil(t, err)
+
+	// returns immediately, without waiting for stop
+	d.WatchKubeletCPUManager(time.Millisecond, nil)
+}
+
+func TestNewDaemonReleasesKubeletCpusOfPreviousRun(t *testing.T) {
+	_, tearDown := setupTest()
+	defer tearDown(t)
+	d, err := newDaemonWithKubeletState(staticKubeletState, KubeletCoexistenceCooperate)
+	require.Nil(t, err)
+	require.NotEmpty(t, d.state.KubeletCPUs)
+
+	d, err = newDaemonWithKubeletState(noneKubeletState, KubeletCoexistenceCooperate)
+	require.Nil(t, err)
+	assert.Empty(t, d.state.KubeletCPUs)
+	assert.True(t, CPUSetFromBucketList(d.state.AvailableCPUs).Contains(2))
+}
@@ -0,0 +1,79 @@
+package cpudaemon
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// applyKubepodsCpuset sets cpuset of the kubepods cgroup to given cpus, so that no pod (including
+// unmanaged ones) runs on cpus left out, eg. reserved for system daemons. Descendant cgroups using cpus
+// left out are restricted first, deepest ones first, so that no child cpuset is ever a superset of its
+// parent, which cgroups v1 rejects.
+func applyKubepodsCpuset(cgroupPath string, version CgroupVersion, cpus CPUSet) error {
+	parent, _ := version.cpusetPaths(cgroupPath)
+	kubepods := ""
+	for _, it := range kubepodsCgroups {
+		if info, err := os.Stat(filepath.Join(parent, it)); err == nil && info.IsDir() {
+			kubepods = filepath.Join(parent, it)
+			break
+		}
+	}
+	if kubepods == "" {
+		return cgroupWriteError(parent, errors.New("kubepods cgroup not found"))
+	}
+
+	descendants := []string{}
+	err := filepath.WalkDir(kubepods, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && path != kubepods {
+			descendants = append(descendants, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return cgroupWriteError(kubepods, err)
+	}
+	for i := len(descendants) - 1; i >= 0; i-- {
+		if err := restrictCpuset(descendants[i], cpus); err != nil {
+			return cgroupWriteError(descendants[i], err)
+		}
+	}
+	err = os.WriteFile(filepath.Join(kubepods, "cpuset.cpus"), []byte(cpus.ToCpuString()), os.FileMode(0))
+	if err != nil {
+		return cgroupWriteError(kubepods, err)
+	}
+	return nil
+}
+
+// restrictCpuset removes cpus other than given ones from the cpuset of the cgroup. Cgroups without
+// cpuset (eg. cgroups v2 with cpuset controller not enabled) or with empty one inheriting parent cpus
+// are left intact, as well as cgroups removed in the meantime. If no cpus would be left, the cgroup gets
+// all given cpus.
+func restrictCpuset(cgroup string, cpus CPUSet) error {
+	cpusetPath := filepath.Join(cgroup, "cpuset.cpus")
+	buckets, err := LoadCpuSet(cpusetPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	current := CPUSetFromBucketList(buckets)
+	outside := current.Clone().RemoveAll(cpus)
+	if outside.Count() == 0 {
+		return nil
+	}
+	restricted := current.RemoveAll(outside)
+	if restricted.Count() == 0 {
+		restricted = cpus
+	}
+	err = os.WriteFile(cpusetPath, []byte(restricted.ToCpuString()), os.FileMode(0))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
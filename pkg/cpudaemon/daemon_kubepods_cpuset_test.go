@@ -0,0 +1,75 @@
+package cpudaemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCpusetForTest writes cpuset of given cgroup in both cgroups v1 and v2 layouts.
+func writeCpusetForTest(t *testing.T, dir string, cgroup string, cpus string) {
+	for _, parent := range []string{dir, filepath.Join(dir, "cpuset")} {
+		require.Nil(t, os.MkdirAll(filepath.Join(parent, cgroup), 0o755))
+		require.Nil(t, os.WriteFile(filepath.Join(parent, cgroup, "cpuset.cpus"), []byte(cpus), 0o600))
+	}
+}
+
+// readCpusetForTest reads cpuset of given cgroup in the layout of given cgroup version.
+func readCpusetForTest(t *testing.T, dir string, version CgroupVersion, cgroup string) string {
+	parent, _ := version.cpusetPaths(dir)
+	b, err := os.ReadFile(filepath.Join(parent, cgroup, "cpuset.cpus"))
+	require.Nil(t, err)
+	return string(b)
+}
+
+func TestApplyKubepodsCpuset(t *testing.T) {
+	for _, version := range []CgroupVersion{CgroupV1, CgroupV2} {
+		dir := t.TempDir()
+		writeCpusetForTest(t, dir, "kubepods.slice", "0-7")
+		writeCpusetForTest(t, dir, "kubepods.slice/kubepods-burstable.slice", "0-7")
+		writeCpusetForTest(t, dir, "kubepods.slice/kubepods-burstable.slice/pod/container", "1,2,5")
+		writeCpusetForTest(t, dir, "kubepods.slice/kubepods-besteffort.slice", "")
+		writeCpusetForTest(t, dir, "kubepods.slice/pod/container", "0,1")
+
+		require.Nil(t, applyKubepodsCpuset(dir, version, CPUSet{2: {}, 3: {}, 4: {}, 5: {}, 6: {}, 7: {}}))
+
+		assert.Equal(t, "2,3,4,5,6,7", readCpusetForTest(t, dir, version, "kubepods.slice"))
+		assert.Equal(t, "2,3,4,5,6,7", readCpusetForTest(t, dir, version, "kubepods.slice/kubepods-burstable.slice"))
+		container := readCpusetForTest(t, dir, version, "kubepods.slice/kubepods-burstable.slice/pod/container")
+		assert.Equal(t, "2,5", container, "cpus inside the kubepods cpuset are kept")
+		besteffort := readCpusetForTest(t, dir, version, "kubepods.slice/kubepods-besteffort.slice")
+		assert.Empty(t, besteffort, "empty cpuset inherits parent cpus")
+		reservedOnly := readCpusetForTest(t, dir, version, "kubepods.slice/pod/container")
+		assert.Equal(t, "2,3,4,5,6,7", reservedOnly, "cgroup pinned only to reserved cpus gets all kubepods cpus")
+	}
+}
+
+func TestApplyKubepodsCpusetFailsWithoutKubepodsCgroup(t *testing.T) {
+	err := applyKubepodsCpuset(t.TempDir(), CgroupV2, CPUSet{1: {}})
+
+	var dErr DaemonError
+	require.ErrorAs(t, err, &dErr)
+	assert.Equal(t, ConfigurationError, dErr.ErrorType)
+}
+
+func TestRestrictCpusetSkipsCgroupWithoutCpuset(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.Nil(t, restrictCpuset(dir, CPUSet{1: {}}))
+	assert.NoFileExists(t, filepath.Join(dir, "cpuset.cpus"))
+}
+
+func TestKubepodsCpusetOptionConflictsWithManagedCpus(t *testing.T) {
+	o := newDaemonOptions([]Option{WithKubepodsCpuset(CPUSet{0: {}}), WithManagedCpus(CPUSet{1: {}})})
+
+	assert.NotNil(t, o.validate())
+}
+
+func TestKubepodsCpusetOptionConflictsWithReservedCpus(t *testing.T) {
+	o := newDaemonOptions([]Option{WithKubepodsCpuset(CPUSet{0: {}}), WithReservedCpus(CPUSet{1: {}})})
+
+	assert.NotNil(t, o.validate())
+}
@@ -0,0 +1,119 @@
+package cpudaemon
+
+import (
+	"context"
+	"time"
+
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+// leaseExpiry returns expiry time of exclusive cpus lease, zero time if the lease is not set.
+func leaseExpiry(now time.Time, seconds uint32) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return now.Add(time.Duration(seconds) * time.Second)
+}
+
+// RunLeaseExpiration checks every interval, until stop is closed, for pods with expired exclusive cpus
+// lease and moves their guaranteed containers to the shared pool.
+func (d *Daemon) RunLeaseExpiration(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			d.expireLeases(now)
+		}
+	}
+}
+
+// expireLeases moves guaranteed containers of pods with exclusive cpus lease expired before now to the
+// shared pool.
+func (d *Daemon) expireLeases(now time.Time) {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+
+	before := d.traceHash()
+	expired := []string{}
+	for pid, pod := range d.state.Pods {
+		if pod.LeaseExpired || pod.LeaseExpiry.IsZero() || now.Before(pod.LeaseExpiry) {
+			continue
+		}
+		d.logger.Info("exclusive cpus lease expired", "pid", pid, "expiry", pod.LeaseExpiry)
+		d.expireLease(&pod)
+		d.state.Pods[pid] = pod
+		metrics.ExpiredLeases.Inc()
+		expired = append(expired, pid)
+	}
+	if len(expired) == 0 {
+		return
+	}
+	d.traceChange(traceExpireLeases, expired, before, now)
+	if err := d.saveState(); err != nil {
+		d.logger.Error(err, "cannot save state")
+	}
+}
+
+// expireLease frees exclusive cpus of guaranteed containers of the pod and moves them to the shared pool.
+func (d *Daemon) expireLease(pod *PodMetadata) {
+	ctx := context.Background()
+	for _, c := range pod.Containers {
+		if c.QS != Guaranteed {
+			continue
+		}
+		if err := d.policyFor(c).DeleteContainer(ctx, c, &d.state); err != nil {
+			d.logger.Error(err, "failed to free container resources", "cid", c.CID)
+		}
+		delete(d.state.Allocated, c.CID)
+		if err := d.policyFor(c).ClearContainer(ctx, c, &d.state); err != nil {
+			d.logger.Error(err, "failed to move container to shared pool", "cid", c.CID)
+		}
+		d.state.clearMemoryNodes(c.CID)
+		d.state.releaseAllocatedAt(c.CID, time.Now())
+	}
+	pod.LeaseExpired = true
+}
+
+// renewLease assigns exclusive cpus again to guaranteed containers of the pod with expired lease. Either
+// all containers get their cpus, or the pod stays in the shared pool.
+func (d *Daemon) renewLease(ctx context.Context, pod *PodMetadata) error {
+	snapshot := d.state.snapshot()
+	assigned := []Container{}
+	for _, c := range sortedBySize(pod.Containers) {
+		if c.QS != Guaranteed {
+			continue
+		}
+		err := d.assignContainer(ctx, c)
+		if err != nil {
+			d.logger.Error(err, "cannot renew exclusive cpus lease", "container", c)
+			d.rollbackContainers(assigned)
+			d.state.restore(snapshot)
+			d.state.clearMemoryNodes(c.CID)
+			return err
+		}
+		assigned = append(assigned, c)
+	}
+	now := time.Now()
+	for _, c := range assigned {
+		d.state.setAllocatedAt(c.CID, now)
+	}
+	pod.LeaseExpired = false
+	return nil
+}
+
+// allocatedContainers returns containers of the pod which hold cpus allocated by the policy.
+func allocatedContainers(pod PodMetadata) []Container {
+	if !pod.LeaseExpired {
+		return pod.Containers
+	}
+	containers := []Container{}
+	for _, c := range pod.Containers {
+		if c.QS != Guaranteed {
+			containers = append(containers, c)
+		}
+	}
+	return containers
+}
@@ -0,0 +1,210 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+func newDaemonWithLeasedPod(t *testing.T, m *MockedPolicy, p PodMetaData, seconds uint32) *Daemon {
+	d := newTestDaemon(t, m)
+	for _, c := range p.containers {
+		m.On("AssignContainer", c, &d.state).Return(nil).Once()
+	}
+	_, err := d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:                 p.pid,
+			PodName:               p.name,
+			PodNamespace:          p.namespace,
+			Resources:             p.resources,
+			Containers:            p.containersResources,
+			ExclusiveLeaseSeconds: seconds,
+		},
+	)
+	require.Nil(t, err)
+	for _, c := range p.containers {
+		d.state.Allocated[c.CID] = []ctlplaneapi.CPUBucket{{StartCPU: 1, EndCPU: 1}}
+	}
+	return d
+}
+
+func TestLeaseExpiry(t *testing.T) {
+	now := time.Now()
+	assert.True(t, leaseExpiry(now, 0).IsZero())
+	assert.Equal(t, now.Add(time.Minute), leaseExpiry(now, 60))
+}
+
+func TestCreatePodSetsLeaseExpiry(t *testing.T) {
+	m := MockedPolicy{}
+	p := createTestPod(1)
+	before := time.Now()
+
+	d := newDaemonWithLeasedPod(t, &m, p, 60)
+
+	expiry := d.state.Pods[p.pid].LeaseExpiry
+	assert.False(t, expiry.Before(before.Add(time.Minute)))
+	assert.False(t, expiry.After(time.Now().Add(time.Minute)))
+	assert.False(t, d.state.Pods[p.pid].LeaseExpired)
+}
+
+func TestExpireLeasesMovesGuaranteedContainersToSharedPool(t *testing.T) {
+	m := MockedPolicy{}
+	p := createTestPod(2)
+	d := newDaemonWithLeasedPod(t, &m, p, 60)
+	for _, c := range p.containers {
+		m.On("DeleteContainer", c, &d.state).Return(nil).Once()
+		m.On("ClearContainer", c, &d.state).Return(nil).Once()
+	}
+	expired := testutil.ToFloat64(metrics.ExpiredLeases)
+
+	d.expireLeases(time.Now().Add(time.Second))
+	m.AssertNotCalled(t, "DeleteContainer", mock.Anything, mock.Anything)
+
+	d.expireLeases(time.Now().Add(2 * time.Minute))
+	m.AssertExpectations(t)
+	assert.True(t, d.state.Pods[p.pid].LeaseExpired)
+	assert.Empty(t, d.state.Allocated)
+	assert.Empty(t, d.state.AllocatedAt)
+	assert.Equal(t, expired+1, testutil.ToFloat64(metrics.ExpiredLeases))
+
+	// expired lease is not expired again
+	d.expireLeases(time.Now().Add(3 * time.Minute))
+	m.AssertExpectations(t)
+}
+
+func TestExpireLeasesIgnoresPodsWithoutLease(t *testing.T) {
+	m := MockedPolicy{}
+	p := createTestPod(1)
+	d := newDaemonWithLeasedPod(t, &m, p, 0)
+
+	d.expireLeases(time.Now().Add(time.Hour))
+
+	m.AssertNotCalled(t, "DeleteContainer", mock.Anything, mock.Anything)
+	assert.False(t, d.state.Pods[p.pid].LeaseExpired)
+}
+
+func TestUpdatePodRenewsExpiredLease(t *testing.T) {
+	m := MockedPolicy{}
+	p := createTestPod(2)
+	d := newDaemonWithLeasedPod(t, &m, p, 60)
+	for _, c := range p.containers {
+		m.On("DeleteContainer", c, &d.state).Return(nil).Once()
+		m.On("ClearContainer", c, &d.state).Return(nil).Once()
+		m.On("AssignContainer", c, &d.state).Return(nil).Once()
+	}
+	d.expireLeases(time.Now().Add(2 * time.Minute))
+	require.True(t, d.state.Pods[p.pid].LeaseExpired)
+
+	_, err := d.UpdatePod(
+		context.Background(),
+		&ctlplaneapi.UpdatePodRequest{
+			PodId:                 p.pid,
+			Resources:             p.resources,
+			Containers:            p.containersResources,
+			ExclusiveLeaseSeconds: 120,
+		},
+	)
+
+	require.Nil(t, err)
+	m.AssertExpectations(t)
+	assert.False(t, d.state.Pods[p.pid].LeaseExpired)
+	assert.True(t, d.state.Pods[p.pid].LeaseExpiry.After(time.Now().Add(time.Minute)))
+	assert.Len(t, d.state.AllocatedAt, 2)
+}
+
+func TestUpdatePodWithoutLeaseMakesExclusivityPermanent(t *testing.T) {
+	m := MockedPolicy{}
+	p := createTestPod(1)
+	d := newDaemonWithLeasedPod(t, &m, p, 60)
+
+	_, err := d.UpdatePod(
+		context.Background(),
+		&ctlplaneapi.UpdatePodRequest{
+			PodId:      p.pid,
+			Resources:  p.resources,
+			Containers: p.containersResources,
+		},
+	)
+
+	require.Nil(t, err)
+	assert.True(t, d.state.Pods[p.pid].LeaseExpiry.IsZero())
+}
+
+func TestUpdatePodKeepsLeaseExpiredIfRenewalFails(t *testing.T) {
+	m := MockedPolicy{}
+	p := createTestPod(2)
+	d := newDaemonWithLeasedPod(t, &m, p, 60)
+	for _, c := range p.containers {
+		m.On("DeleteContainer", c, &d.state).Return(nil).Once()
+		m.On("ClearContainer", c, &d.state).Return(nil).Once()
+	}
+	d.expireLeases(time.Now().Add(2 * time.Minute))
+	// biggest container is renewed first and rolled back after the second one fails
+	m.On("AssignContainer", p.containers[1], &d.state).Return(nil).Once()
+	m.On("AssignContainer", p.containers[0], &d.state).Return(DaemonError{ErrorType: CpusNotAvailable}).Once()
+	m.On("DeleteContainer", p.containers[1], &d.state).Return(nil).Once()
+	m.On("ClearContainer", p.containers[1], &d.state).Return(nil).Once()
+
+	_, err := d.UpdatePod(
+		context.Background(),
+		&ctlplaneapi.UpdatePodRequest{
+			PodId:                 p.pid,
+			Resources:             p.resources,
+			Containers:            p.containersResources,
+			ExclusiveLeaseSeconds: 60,
+		},
+	)
+
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, CpusNotAvailable, daemonErr.ErrorType)
+	m.AssertExpectations(t)
+	assert.True(t, d.state.Pods[p.pid].LeaseExpired)
+}
+
+func TestDeletePodWithExpiredLease(t *testing.T) {
+	m := MockedPolicy{}
+	p := createTestPod(1)
+	d := newDaemonWithLeasedPod(t, &m, p, 60)
+	m.On("DeleteContainer", p.containers[0], &d.state).Return(nil).Once()
+	m.On("ClearContainer", p.containers[0], &d.state).Return(nil).Once()
+	d.expireLeases(time.Now().Add(2 * time.Minute))
+
+	_, err := d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: p.pid})
+
+	require.Nil(t, err)
+	m.AssertExpectations(t)
+	assert.NotContains(t, d.state.Pods, p.pid)
+}
+
+func TestRunLeaseExpirationStops(t *testing.T) {
+	m := MockedPolicy{}
+	p := createTestPod(1)
+	d := newDaemonWithLeasedPod(t, &m, p, 1)
+	m.On("DeleteContainer", p.containers[0], &d.state).Return(nil).Once()
+	m.On("ClearContainer", p.containers[0], &d.state).Return(nil).Once()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		d.RunLeaseExpiration(10*time.Millisecond, stop)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		d.stateMu.Lock()
+		defer d.stateMu.Unlock()
+		return d.state.Pods[p.pid].LeaseExpired
+	}, 3*time.Second, 10*time.Millisecond)
+	close(stop)
+	<-done
+	m.AssertExpectations(t)
+}
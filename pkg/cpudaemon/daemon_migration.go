@@ -0,0 +1,163 @@
+package cpudaemon
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/numautils"
+)
+
+// MigrationPolicy is implemented by policies able to move exclusive cpus of containers between numa nodes.
+type MigrationPolicy interface {
+	MigrateContainer(ctx context.Context, c Container, node int, s *DaemonState) error
+}
+
+var _ MigrationPolicy = &StaticPolicy{}
+
+// migratingAllocator is implemented by allocators able to move container cpus to another numa node.
+type migratingAllocator interface {
+	migrateContainer(ctx context.Context, c Container, node int, s *DaemonState) error
+}
+
+var _ migratingAllocator = &NumaAwareAllocator{}
+
+var errMigrationNotSupported = DaemonError{
+	ErrorType:    NotImplemented,
+	ErrorMessage: "container migration is not supported by the allocator",
+}
+
+// MigrateContainer moves cpus and memory of the running guaranteed container to given numa node, so that
+// operators can defragment the node without restarting pods. The container keeps its allocation if the
+// node cannot host it.
+func (d *Daemon) MigrateContainer(
+	ctx context.Context,
+	req *ctlplaneapi.MigrateContainerRequest,
+) (*ctlplaneapi.ContainerAllocation, error) {
+	if err := ctlplaneapi.ValidateMigrateContainerRequest(req); err != nil {
+		d.logger.Error(err, "validation error")
+		return nil, DaemonError{ErrorType: PodSpecError, ErrorMessage: err.Error()}
+	}
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	p, ok := d.policy.(MigrationPolicy)
+	if !ok {
+		return nil, errMigrationNotSupported
+	}
+	defer d.traceRequest(traceMigrateContainer, req, d.traceHash())
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c, err := findContainer(&d.state, req.ContainerId)
+	if err != nil {
+		return nil, DaemonError{ErrorType: ContainerNotFound, ErrorMessage: err.Error()}
+	}
+	if _, allocated := d.state.Allocated[c.CID]; c.QS != Guaranteed || !allocated {
+		return nil, DaemonError{
+			ErrorType:    PodSpecError,
+			ErrorMessage: fmt.Sprintf("Container %s has no exclusive cpus, it runs in the shared pool", c.CID),
+		}
+	}
+	if d.policyFor(c) != d.policy {
+		// cpus of the container were allocated by the policy of its tier, not by the migrating allocator
+		return nil, errMigrationNotSupported
+	}
+
+	if err := p.MigrateContainer(ctx, c, int(req.TargetNode), &d.state); err != nil {
+		d.logger.Error(err, "cannot migrate container", "cid", c.CID, "node", req.TargetNode)
+		return nil, err
+	}
+	d.logger.Info("container migrated", "cid", c.CID, "node", req.TargetNode, "cpus", d.state.Allocated[c.CID])
+	if err := d.saveState(); err != nil {
+		return nil, *err
+	}
+	return &ctlplaneapi.ContainerAllocation{
+		PodID:         c.PID,
+		ContainerName: c.Name,
+		Allocation:    d.state.allocatedContainerResource(c),
+	}, nil
+}
+
+// MigrateContainer moves cpus of the container to the numa node, if the allocator supports it.
+func (p *StaticPolicy) MigrateContainer(ctx context.Context, c Container, node int, s *DaemonState) error {
+	a, ok := p.allocator.(migratingAllocator)
+	if !ok {
+		return errMigrationNotSupported
+	}
+	return a.migrateContainer(ctx, c, node, s)
+}
+
+// migrateContainer moves cpus of the container to the numa node, taking cpus with minimal topology
+// distance. Cpus of the container already on the node can be kept. Memory of the container is pinned to
+// the node, so that it is migrated together with cpus, regardless of memory pinning settings.
+func (d *NumaAwareAllocator) migrateContainer(ctx context.Context, c Container, node int, s *DaemonState) error {
+	target, err := numaNode(&s.Topology, node)
+	if err != nil {
+		return err
+	}
+	current := s.Allocated[c.CID]
+	currentMems := s.getMemoryNodes(c.CID)
+
+	returnTopologyCpus(&s.Topology, current)
+	cpuIds, err := s.Topology.TakeFrom(target, c.Cpus)
+	if err != nil {
+		takeTopologyCpus(&s.Topology, current)
+		return DaemonError{
+			ErrorType:    CpusNotAvailable,
+			ErrorMessage: fmt.Sprintf("numa node %d has less than %d free cpus", node, c.Cpus),
+		}
+	}
+	migrated := CPUSet{}
+	for _, cpu := range cpuIds {
+		migrated.Add(cpu)
+	}
+	s.Allocated[c.CID] = migrated.ToBucketList()
+
+	if err := updateContainerCPUSet(ctx, d.ctrl, s, c, migrated.ToCpuString(), strconv.Itoa(node)); err != nil {
+		returnTopologyCpus(&s.Topology, s.Allocated[c.CID])
+		takeTopologyCpus(&s.Topology, current)
+		s.Allocated[c.CID] = current
+		// the cgroup might have been written partially, restoring it is best effort
+		_ = updateContainerCPUSet(context.Background(), d.ctrl, s, c, CPUSetFromBucketList(current).ToCpuString(), currentMems)
+		return err
+	}
+	return nil
+}
+
+// numaNode returns the topology subtree with cpus of given numa node. Topology of machines with a single
+// numa node has no node level, the whole topology is returned for the node then.
+func numaNode(t *numautils.NumaTopology, node int) (*numautils.TopologyNode, error) {
+	hasNodes := false
+	for _, child := range t.Topology.Children {
+		if child.Type != numautils.Node {
+			continue
+		}
+		if child.Value == node {
+			return child, nil
+		}
+		hasNodes = true
+	}
+	if !hasNodes && len(t.CpusOnNodes([]int{node})) > 0 {
+		return t.Topology, nil
+	}
+	return nil, DaemonError{
+		ErrorType:    UnknownTopology,
+		ErrorMessage: fmt.Sprintf("numa node %d does not exist", node),
+	}
+}
+
+// returnTopologyCpus returns cpus of the buckets to the topology.
+func returnTopologyCpus(t *numautils.NumaTopology, buckets []ctlplaneapi.CPUBucket) {
+	for _, cpu := range CPUSetFromBucketList(buckets).Sorted() {
+		_ = t.Return(cpu)
+	}
+}
+
+// takeTopologyCpus takes cpus of the buckets from the topology.
+func takeTopologyCpus(t *numautils.NumaTopology, buckets []ctlplaneapi.CPUBucket) {
+	for _, cpu := range CPUSetFromBucketList(buckets).Sorted() {
+		_ = t.TakeCpu(cpu)
+	}
+}
@@ -0,0 +1,161 @@
+package cpudaemon
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+func newDaemonForMigrationTest(t *testing.T, allocator func(CgroupController) Allocator) (*Daemon, *CgroupsMock) {
+	m := CgroupsMock{}
+	return newTestDaemon(t, NewStaticPolocy(allocator(&m))), &m
+}
+
+func newNumaAllocatorForMigrationTest(ctrl CgroupController) Allocator {
+	return NewNumaAwareAllocator(ctrl, false)
+}
+
+// createPodForMigration creates pod with containers of 1 and 2 cpus and returns the node of the second one.
+func createPodForMigration(t *testing.T, d *Daemon, m *CgroupsMock) (PodMetaData, int) {
+	p := createTestPod(2)
+	m.On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, "").Return(nil)
+	_, err := d.CreatePod(context.Background(), createPodRequest(p))
+	require.Nil(t, err)
+	nodes := cpuNodes(d, p.containers[1].CID)
+	require.Len(t, nodes, 1)
+	for node := range nodes {
+		return p, node
+	}
+	return p, 0
+}
+
+// cpuNodes returns numa nodes of cpus allocated to the container.
+func cpuNodes(d *Daemon, cid string) map[int]struct{} {
+	nodes := map[int]struct{}{}
+	for _, cpu := range CPUSetFromBucketList(d.state.Allocated[cid]).Sorted() {
+		nodes[d.state.Topology.CpuInformation[cpu].Node] = struct{}{}
+	}
+	return nodes
+}
+
+func TestMigrateContainerMovesCpusAndMemory(t *testing.T) {
+	d, m := newDaemonForMigrationTest(t, newNumaAllocatorForMigrationTest)
+	p, node := createPodForMigration(t, d, m)
+	target := 1 - node
+	c := p.containers[1]
+	m.On("UpdateCPUSet", mock.Anything, c, mock.Anything, strconv.Itoa(target)).Return(nil).Once()
+	available := d.state.Topology.Topology.NumAvailable
+
+	reply, err := d.MigrateContainer(
+		context.Background(),
+		&ctlplaneapi.MigrateContainerRequest{ContainerId: c.CID, TargetNode: uint32(target)},
+	)
+
+	require.Nil(t, err)
+	assert.Equal(t, p.pid, reply.PodID)
+	assert.Equal(t, c.Name, reply.ContainerName)
+	assert.Equal(t, strconv.Itoa(target), reply.Allocation.MemoryNodes)
+	assert.Equal(t, 2, CPUSetFromBucketList(reply.Allocation.CPUSet).Count())
+	assert.Equal(t, map[int]struct{}{target: {}}, cpuNodes(d, c.CID))
+	assert.Equal(t, available, d.state.Topology.Topology.NumAvailable)
+	assert.Equal(t, d.state.Allocated[c.CID], d.readableState().Allocated[c.CID], "migration shall be published")
+	m.AssertExpectations(t)
+}
+
+func TestMigrateContainerFailsIfNodeIsFull(t *testing.T) {
+	d, m := newDaemonForMigrationTest(t, newNumaAllocatorForMigrationTest)
+	p, node := createPodForMigration(t, d, m)
+	target := 1 - node
+	free := []int{}
+	for _, cpu := range d.state.Topology.CpusOnNodes([]int{target}) {
+		if d.state.Topology.TakeCpu(cpu) == nil {
+			free = append(free, cpu)
+		}
+	}
+	require.Nil(t, d.state.Topology.Return(free[0])) // one cpu is left free, the container needs two
+	allocated := d.state.Allocated[p.containers[1].CID]
+	available := d.state.Topology.Topology.NumAvailable
+	m.Calls = nil
+
+	_, err := d.MigrateContainer(
+		context.Background(),
+		&ctlplaneapi.MigrateContainerRequest{ContainerId: p.containers[1].CID, TargetNode: uint32(target)},
+	)
+
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, CpusNotAvailable, daemonErr.ErrorType)
+	assert.Equal(t, allocated, d.state.Allocated[p.containers[1].CID])
+	assert.Equal(t, available, d.state.Topology.Topology.NumAvailable)
+	m.AssertNotCalled(t, "UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMigrateContainerRestoresAllocationIfCgroupUpdateFails(t *testing.T) {
+	d, m := newDaemonForMigrationTest(t, newNumaAllocatorForMigrationTest)
+	p, node := createPodForMigration(t, d, m)
+	target := 1 - node
+	c := p.containers[1]
+	allocated := d.state.Allocated[c.CID]
+	available := d.state.Topology.Topology.NumAvailable
+	m.On("UpdateCPUSet", mock.Anything, c, mock.Anything, strconv.Itoa(target)).Return(errors.New("write error")).Once()
+
+	_, err := d.MigrateContainer(
+		context.Background(),
+		&ctlplaneapi.MigrateContainerRequest{ContainerId: c.CID, TargetNode: uint32(target)},
+	)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, allocated, d.state.Allocated[c.CID])
+	assert.Equal(t, available, d.state.Topology.Topology.NumAvailable)
+	m.AssertCalled(t, "UpdateCPUSet", mock.Anything, c, CPUSetFromBucketList(allocated).ToCpuString(), "")
+}
+
+func TestMigrateContainerFailsForUnknownNode(t *testing.T) {
+	d, m := newDaemonForMigrationTest(t, newNumaAllocatorForMigrationTest)
+	p, _ := createPodForMigration(t, d, m)
+
+	_, err := d.MigrateContainer(
+		context.Background(),
+		&ctlplaneapi.MigrateContainerRequest{ContainerId: p.containers[1].CID, TargetNode: 7},
+	)
+
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, UnknownTopology, daemonErr.ErrorType)
+}
+
+func TestMigrateContainerFailsForContainersWithoutExclusiveCpus(t *testing.T) {
+	d, m := newDaemonForMigrationTest(t, newNumaAllocatorForMigrationTest)
+	p, _ := createPodForMigration(t, d, m)
+	c := p.containers[0]
+	c.QS = Burstable
+	pod := d.state.Pods[p.pid]
+	pod.Containers[0] = c
+	d.state.Pods[p.pid] = pod
+
+	for _, cid := range []string{c.CID, "unknown"} {
+		_, err := d.MigrateContainer(
+			context.Background(),
+			&ctlplaneapi.MigrateContainerRequest{ContainerId: cid, TargetNode: 1},
+		)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestMigrateContainerNotSupported(t *testing.T) {
+	d, m := newDaemonForMigrationTest(t, func(ctrl CgroupController) Allocator { return NewDefaultAllocator(ctrl) })
+	p, _ := createPodForMigration(t, d, m)
+
+	_, err := d.MigrateContainer(
+		context.Background(),
+		&ctlplaneapi.MigrateContainerRequest{ContainerId: p.containers[1].CID, TargetNode: 1},
+	)
+
+	assert.ErrorIs(t, err, errMigrationNotSupported)
+}
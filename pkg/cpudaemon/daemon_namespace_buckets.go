@@ -0,0 +1,187 @@
+package cpudaemon
+
+import (
+	"fmt"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// NamespaceBucketPolicy is implemented by policies able to manage cpu buckets of namespaces explicitly,
+// instead of creating them on the first container of the namespace.
+type NamespaceBucketPolicy interface {
+	CreateNamespaceBucket(
+		namespace string,
+		minCpus int,
+		cpuWeight uint64,
+		s *DaemonState,
+	) (ctlplaneapi.NamespaceBucketInfo, error)
+	DeleteNamespaceBucket(namespace string, s *DaemonState) (ctlplaneapi.NamespaceBucketInfo, error)
+}
+
+var _ NamespaceBucketPolicy = &StaticPolicy{}
+
+// namespaceBucketAllocator is implemented by allocators keeping cpu buckets of namespaces.
+type namespaceBucketAllocator interface {
+	createNamespaceBucket(
+		namespace string,
+		minCpus int,
+		cpuWeight uint64,
+		s *DaemonState,
+	) (ctlplaneapi.NamespaceBucketInfo, error)
+	deleteNamespaceBucket(namespace string, s *DaemonState) (ctlplaneapi.NamespaceBucketInfo, error)
+}
+
+var _ namespaceBucketAllocator = &NumaPerNamespaceAllocator{}
+
+var errNamespaceBucketsNotSupported = DaemonError{
+	ErrorType:    NotImplemented,
+	ErrorMessage: "namespace buckets are supported only by numa-namespace allocators",
+}
+
+// CreateNamespaceBucket creates cpu bucket of the namespace, if the allocator keeps namespace buckets.
+func (p *StaticPolicy) CreateNamespaceBucket(
+	namespace string,
+	minCpus int,
+	cpuWeight uint64,
+	s *DaemonState,
+) (ctlplaneapi.NamespaceBucketInfo, error) {
+	a, ok := p.allocator.(namespaceBucketAllocator)
+	if !ok {
+		return ctlplaneapi.NamespaceBucketInfo{}, errNamespaceBucketsNotSupported
+	}
+	return a.createNamespaceBucket(namespace, minCpus, cpuWeight, s)
+}
+
+// DeleteNamespaceBucket releases cpu bucket of the namespace, if the allocator keeps namespace buckets.
+func (p *StaticPolicy) DeleteNamespaceBucket(
+	namespace string,
+	s *DaemonState,
+) (ctlplaneapi.NamespaceBucketInfo, error) {
+	a, ok := p.allocator.(namespaceBucketAllocator)
+	if !ok {
+		return ctlplaneapi.NamespaceBucketInfo{}, errNamespaceBucketsNotSupported
+	}
+	return a.deleteNamespaceBucket(namespace, s)
+}
+
+// createNamespaceBucket assigns the namespace to the bucket with at least minCpus cpus used by the
+// smallest number of namespaces, and keeps the assignment until the bucket is deleted, even if the
+// namespace has no containers. For namespaces already assigned, the bucket is kept if large enough.
+// Non-zero cpu weight per requested cpu is set on containers sharing the bucket, even if bucket weights
+// are not enabled.
+func (d *NumaPerNamespaceAllocator) createNamespaceBucket(
+	namespace string,
+	minCpus int,
+	cpuWeight uint64,
+	s *DaemonState,
+) (ctlplaneapi.NamespaceBucketInfo, error) {
+	index, ok := d.NamespaceToBucket[namespace]
+	if ok && len(d.bucketCpus(s, index)) < minCpus {
+		return ctlplaneapi.NamespaceBucketInfo{}, DaemonError{
+			ErrorType: CpusNotAvailable,
+			ErrorMessage: fmt.Sprintf(
+				"namespace %s already uses bucket %d with %d cpus, %d requested",
+				namespace,
+				index,
+				len(d.bucketCpus(s, index)),
+				minCpus,
+			),
+		}
+	}
+	if !ok {
+		var err error
+		if index, err = d.selectBucket(s, minCpus); err != nil {
+			return ctlplaneapi.NamespaceBucketInfo{}, DaemonError{
+				ErrorType:    CpusNotAvailable,
+				ErrorMessage: err.Error(),
+			}
+		}
+		d.NamespaceToBucket[namespace] = index
+		d.logger.Info("created namespace bucket", "name", namespace, "bucket", index)
+	}
+
+	if d.pinnedNamespaces == nil {
+		d.pinnedNamespaces = make(map[string]struct{})
+	}
+	d.pinnedNamespaces[namespace] = struct{}{}
+	if d.namespaceWeights == nil {
+		d.namespaceWeights = make(map[string]uint64)
+	}
+	delete(d.namespaceWeights, namespace)
+	if cpuWeight > 0 {
+		d.namespaceWeights[namespace] = cpuWeight
+	}
+	return d.namespaceBucketInfo(namespace, index, s), nil
+}
+
+// selectBucket returns index of the bucket with at least minCpus cpus used by the smallest number of
+// namespaces. Equally used buckets are taken in the round robin order of implicitly created ones.
+func (d *NumaPerNamespaceAllocator) selectBucket(s *DaemonState, minCpus int) (int, error) {
+	used := make(map[int]int)
+	for _, index := range d.NamespaceToBucket {
+		used[index]++
+	}
+	selected := -1
+	for i := 0; i < d.NumBuckets; i++ {
+		index := (d.globalBucket + i) % d.NumBuckets
+		if len(d.bucketCpus(s, index)) < minCpus {
+			continue
+		}
+		if selected < 0 || used[index] < used[selected] {
+			selected = index
+		}
+	}
+	if selected < 0 {
+		return 0, fmt.Errorf("%w: no bucket has %d cpus", ErrNotEnoughSpaceInBucket, minCpus)
+	}
+	d.globalBucket++
+	return selected, nil
+}
+
+// deleteNamespaceBucket releases the bucket of the namespace. If containers of the namespace still use
+// the bucket, it is released together with the last of them.
+func (d *NumaPerNamespaceAllocator) deleteNamespaceBucket(
+	namespace string,
+	s *DaemonState,
+) (ctlplaneapi.NamespaceBucketInfo, error) {
+	index, ok := d.NamespaceToBucket[namespace]
+	if !ok {
+		return ctlplaneapi.NamespaceBucketInfo{}, DaemonError{
+			ErrorType:    NamespaceNotFound,
+			ErrorMessage: fmt.Sprintf("namespace %s has no bucket", namespace),
+		}
+	}
+	info := d.namespaceBucketInfo(namespace, index, s)
+	delete(d.pinnedNamespaces, namespace)
+	delete(d.namespaceWeights, namespace)
+
+	for _, pod := range s.Pods {
+		for _, c := range pod.Containers {
+			if _, allocated := s.Allocated[c.CID]; allocated && pod.Namespace == namespace {
+				d.logger.Info("namespace bucket still in use", "name", namespace, "cid", c.CID)
+				return info, nil
+			}
+		}
+	}
+	delete(d.NamespaceToBucket, namespace)
+	d.logger.Info("deleted namespace bucket", "name", namespace)
+	info.Released = true
+	return info, nil
+}
+
+func (d *NumaPerNamespaceAllocator) namespaceBucketInfo(
+	namespace string,
+	index int,
+	s *DaemonState,
+) ctlplaneapi.NamespaceBucketInfo {
+	cpus := CPUSet{}
+	for _, cpu := range d.bucketCpus(s, index) {
+		cpus.Add(cpu.Value)
+	}
+	return ctlplaneapi.NamespaceBucketInfo{
+		Namespace: namespace,
+		Bucket:    index,
+		CPUSet:    cpus.ToCompactBucketList(),
+		CPUWeight: d.namespaceWeights[namespace],
+	}
+}
@@ -0,0 +1,158 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+func TestCreateNamespaceBucket(t *testing.T) {
+	s := getTestDaemonState(t.TempDir(), 4)
+	allocator := newMockedNumaPerNamespaceAllocator(2, true)
+
+	bucket, err := allocator.createNamespaceBucket("team-a", 0, 0, s)
+
+	require.Nil(t, err)
+	assert.Equal(t, ctlplaneapi.NamespaceBucketInfo{
+		Namespace: "team-a",
+		Bucket:    0,
+		CPUSet:    []ctlplaneapi.CPUBucket{{StartCPU: 0, EndCPU: 1}},
+	}, bucket)
+
+	bucket, err = allocator.createNamespaceBucket("team-b", 0, 200, s)
+
+	require.Nil(t, err)
+	assert.Equal(t, 1, bucket.Bucket, "least used bucket is selected")
+	assert.Equal(t, uint64(200), bucket.CPUWeight)
+}
+
+func TestCreateNamespaceBucketIsIdempotent(t *testing.T) {
+	s := getTestDaemonState(t.TempDir(), 4)
+	allocator := newMockedNumaPerNamespaceAllocator(2, true)
+
+	first, err := allocator.createNamespaceBucket("team-a", 2, 0, s)
+	require.Nil(t, err)
+	second, err := allocator.createNamespaceBucket("team-a", 2, 300, s)
+	require.Nil(t, err)
+
+	assert.Equal(t, first.Bucket, second.Bucket)
+	assert.Equal(t, uint64(300), second.CPUWeight)
+}
+
+func TestCreateNamespaceBucketFailsIfBucketsTooSmall(t *testing.T) {
+	s := getTestDaemonState(t.TempDir(), 4)
+	allocator := newMockedNumaPerNamespaceAllocator(2, true)
+
+	_, err := allocator.createNamespaceBucket("team-a", 3, 0, s)
+
+	var dErr DaemonError
+	require.ErrorAs(t, err, &dErr)
+	assert.Equal(t, CpusNotAvailable, dErr.ErrorType)
+	assert.NotContains(t, allocator.NamespaceToBucket, "team-a")
+}
+
+func TestCreatedNamespaceBucketIsKeptWhenEmpty(t *testing.T) {
+	s := getTestDaemonState(t.TempDir(), 4)
+	allocator := newMockedNumaPerNamespaceAllocator(1, true)
+	_, burstable := getGuaranteedAndBurstableContainers()
+	_, err := allocator.createNamespaceBucket("pod1_namespace", 0, 0, s)
+	require.Nil(t, err)
+
+	allocator.ctrl.(*CgroupsMock).On("UpdateCPUSet", s.CGroupPath, burstable, "0,1,2,3", "0").Return(nil)
+	require.Nil(t, allocator.takeCpus(context.Background(), burstable, s))
+	require.Nil(t, allocator.freeCpus(context.Background(), burstable, s))
+
+	assert.Contains(t, allocator.NamespaceToBucket, "pod1_namespace")
+}
+
+func TestNamespaceBucketWeight(t *testing.T) {
+	s := getTestDaemonState(t.TempDir(), 4)
+	allocator := newMockedNumaPerNamespaceAllocator(1, true)
+	m := &WeightCgroupsMock{}
+	allocator.ctrl = m
+	_, burstable := getGuaranteedAndBurstableContainers()
+	_, err := allocator.createNamespaceBucket("pod1_namespace", 0, 50, s)
+	require.Nil(t, err)
+
+	m.On("UpdateCPUSet", s.CGroupPath, burstable, "0,1,2,3", "0").Return(nil)
+	m.On("SetCPUWeight", s.CGroupPath, burstable, uint64(50)).Once()
+	require.Nil(t, allocator.takeCpus(context.Background(), burstable, s))
+
+	m.AssertExpectations(t)
+}
+
+func TestDeleteNamespaceBucket(t *testing.T) {
+	s := getTestDaemonState(t.TempDir(), 4)
+	allocator := newMockedNumaPerNamespaceAllocator(2, true)
+	_, err := allocator.createNamespaceBucket("team-a", 0, 200, s)
+	require.Nil(t, err)
+
+	bucket, err := allocator.deleteNamespaceBucket("team-a", s)
+
+	require.Nil(t, err)
+	assert.True(t, bucket.Released)
+	assert.Equal(t, uint64(200), bucket.CPUWeight)
+	assert.NotContains(t, allocator.NamespaceToBucket, "team-a")
+
+	_, err = allocator.deleteNamespaceBucket("team-a", s)
+	var dErr DaemonError
+	require.ErrorAs(t, err, &dErr)
+	assert.Equal(t, NamespaceNotFound, dErr.ErrorType)
+}
+
+func TestDeleteNamespaceBucketInUse(t *testing.T) {
+	s := getTestDaemonState(t.TempDir(), 4)
+	allocator := newMockedNumaPerNamespaceAllocator(1, true)
+	_, burstable := getGuaranteedAndBurstableContainers()
+	_, err := allocator.createNamespaceBucket("pod1_namespace", 0, 0, s)
+	require.Nil(t, err)
+	allocator.ctrl.(*CgroupsMock).On("UpdateCPUSet", s.CGroupPath, burstable, "0,1,2,3", "0").Return(nil)
+	require.Nil(t, allocator.takeCpus(context.Background(), burstable, s))
+	addContainerToState(s, burstable)
+
+	bucket, err := allocator.deleteNamespaceBucket("pod1_namespace", s)
+
+	require.Nil(t, err)
+	assert.False(t, bucket.Released)
+	assert.Contains(t, allocator.NamespaceToBucket, "pod1_namespace")
+
+	require.Nil(t, allocator.freeCpus(context.Background(), burstable, s))
+	assert.NotContains(t, allocator.NamespaceToBucket, "pod1_namespace")
+}
+
+func TestNamespaceBucketsNotSupported(t *testing.T) {
+	d := Daemon{
+		policy: NewStaticPolocy(NewDefaultAllocator(&CgroupsMock{})),
+		logger: logr.Discard(),
+	}
+
+	_, err := d.CreateNamespaceBucket(context.Background(), &ctlplaneapi.CreateNamespaceBucketRequest{Namespace: "team-a"})
+
+	var dErr DaemonError
+	require.ErrorAs(t, err, &dErr)
+	assert.Equal(t, NotImplemented, dErr.ErrorType)
+}
+
+func TestDaemonCreateNamespaceBucket(t *testing.T) {
+	s := getTestDaemonState(t.TempDir(), 4)
+	d := Daemon{
+		state:  *s,
+		policy: NewStaticPolocy(newMockedNumaPerNamespaceAllocator(2, true)),
+		logger: logr.Discard(),
+	}
+
+	_, err := d.CreateNamespaceBucket(context.Background(), &ctlplaneapi.CreateNamespaceBucketRequest{})
+	require.NotNil(t, err)
+
+	bucket, err := d.CreateNamespaceBucket(context.Background(), &ctlplaneapi.CreateNamespaceBucketRequest{Namespace: "team-a", MinCpus: 2})
+	require.Nil(t, err)
+	assert.Equal(t, "team-a", bucket.Namespace)
+
+	bucket, err = d.DeleteNamespaceBucket(context.Background(), &ctlplaneapi.DeleteNamespaceBucketRequest{Namespace: "team-a"})
+	require.Nil(t, err)
+	assert.True(t, bucket.Released)
+}
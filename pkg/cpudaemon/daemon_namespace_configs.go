@@ -0,0 +1,193 @@
+package cpudaemon
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// allocatorTierPrefix prefixes names of policy tiers of allocators selectable by namespace configurations.
+const allocatorTierPrefix = "allocator/"
+
+// NamespaceConfig configures allocations of new pods of a namespace at runtime, eg. from ControlPlaneConfig
+// custom resource watched by the agent. Pods keep the allocator of their namespace from their creation.
+type NamespaceConfig struct {
+	Allocator  string // allocator of the namespace, empty for the daemon allocator
+	Exclusive  bool   // false if guaranteed containers of the namespace run in the shared pool
+	BucketCpus int    // minimal cpus of the namespace bucket of numa-namespace allocators, 0 if not reserved
+}
+
+func (c NamespaceConfig) info(namespace string) ctlplaneapi.NamespaceConfigInfo {
+	return ctlplaneapi.NamespaceConfigInfo{
+		Namespace:  namespace,
+		Allocator:  c.Allocator,
+		Exclusive:  c.Exclusive,
+		BucketCpus: c.BucketCpus,
+	}
+}
+
+// allocatorTier returns name of the policy tier of the allocator selectable by namespace configurations.
+func allocatorTier(allocator string) string {
+	return allocatorTierPrefix + allocator
+}
+
+// namespaceAllocatorPolicies maps names of policy tiers of allocators selectable by namespace
+// configurations to static policies of the allocators.
+func namespaceAllocatorPolicies(allocators map[string]Allocator) map[string]Policy {
+	res := make(map[string]Policy, len(allocators))
+	for name, a := range allocators {
+		res[allocatorTier(name)] = NewStaticPolocy(a)
+	}
+	return res
+}
+
+// namespaceTier returns the policy tier of new pods of the namespace: the tier of the allocator configured
+// for the namespace, or the tier the namespace is assigned to by options.
+func (d *Daemon) namespaceTier(namespace string) string {
+	if c, ok := d.state.NamespaceConfigs[namespace]; ok && c.Allocator != "" {
+		return allocatorTier(c.Allocator)
+	}
+	return d.options.namespaceTier(namespace)
+}
+
+// exclusiveNamespace checks if guaranteed containers of the namespace get exclusive cpus, which is the
+// case unless the namespace is configured otherwise.
+func (d *DaemonState) exclusiveNamespace(namespace string) bool {
+	c, ok := d.NamespaceConfigs[namespace]
+	return !ok || c.Exclusive
+}
+
+// namespaceAllocators returns sorted names of allocators selectable by namespace configurations.
+func (d *Daemon) namespaceAllocators() []string {
+	res := []string{}
+	for tier := range d.tierPolicies {
+		if name, ok := strings.CutPrefix(tier, allocatorTierPrefix); ok {
+			res = append(res, name)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// SetNamespaceConfig configures allocations of new pods of the namespace: their allocator, exclusivity
+// of cpus of guaranteed containers and the minimal size of the namespace bucket, which is created at once.
+// Pods already running keep their allocation.
+func (d *Daemon) SetNamespaceConfig(
+	_ context.Context,
+	req *ctlplaneapi.SetNamespaceConfigRequest,
+) (*ctlplaneapi.NamespaceConfigInfo, error) {
+	if err := ctlplaneapi.ValidateSetNamespaceConfigRequest(req); err != nil {
+		d.logger.Error(err, "validation error")
+		return nil, DaemonError{ErrorType: PodSpecError, ErrorMessage: err.Error()}
+	}
+
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	defer d.traceRequest(traceSetNamespaceConfig, req, d.traceHash())
+
+	if _, ok := d.tierPolicies[allocatorTier(req.Allocator)]; req.Allocator != "" && !ok {
+		return nil, DaemonError{
+			ErrorType: ConfigurationError,
+			ErrorMessage: fmt.Sprintf(
+				"allocator %s is not available for namespaces, available are: %s",
+				req.Allocator,
+				strings.Join(d.namespaceAllocators(), ", "),
+			),
+		}
+	}
+	prev, hadConfig := d.state.NamespaceConfigs[req.Namespace]
+	config := NamespaceConfig{Allocator: req.Allocator, Exclusive: req.Exclusive, BucketCpus: int(req.BucketCpus)}
+	if err := d.applyNamespaceBucket(req.Namespace, prev, config, hadConfig); err != nil {
+		return nil, err
+	}
+	if d.state.NamespaceConfigs == nil {
+		d.state.NamespaceConfigs = make(map[string]NamespaceConfig)
+	}
+	d.state.NamespaceConfigs[req.Namespace] = config
+	d.logger.Info(
+		"namespace configured",
+		"namespace", req.Namespace,
+		"allocator", config.Allocator,
+		"exclusive", config.Exclusive,
+		"bucketCpus", config.BucketCpus,
+	)
+
+	if err := d.saveState(); err != nil {
+		return nil, *err
+	}
+	info := config.info(req.Namespace)
+	return &info, nil
+}
+
+// DeleteNamespaceConfig removes configuration of the namespace, new pods of the namespace are allocated as
+// configured by options of the daemon. Bucket reserved by the configuration is deleted.
+func (d *Daemon) DeleteNamespaceConfig(
+	_ context.Context,
+	req *ctlplaneapi.DeleteNamespaceConfigRequest,
+) (*ctlplaneapi.NamespaceConfigInfo, error) {
+	if err := ctlplaneapi.ValidateDeleteNamespaceConfigRequest(req); err != nil {
+		d.logger.Error(err, "validation error")
+		return nil, DaemonError{ErrorType: PodSpecError, ErrorMessage: err.Error()}
+	}
+
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	defer d.traceRequest(traceDeleteNamespaceConfig, req, d.traceHash())
+
+	config, ok := d.state.NamespaceConfigs[req.Namespace]
+	if !ok {
+		return nil, DaemonError{
+			ErrorType:    NamespaceNotFound,
+			ErrorMessage: "namespace " + req.Namespace + " is not configured",
+		}
+	}
+	if err := d.applyNamespaceBucket(req.Namespace, config, NamespaceConfig{}, true); err != nil {
+		return nil, err
+	}
+	delete(d.state.NamespaceConfigs, req.Namespace)
+	d.logger.Info("namespace configuration deleted", "namespace", req.Namespace)
+
+	if err := d.saveState(); err != nil {
+		return nil, *err
+	}
+	info := config.info(req.Namespace)
+	info.Deleted = true
+	return &info, nil
+}
+
+// applyNamespaceBucket creates bucket of the namespace with the policy of the next configuration, or
+// deletes the bucket created by the previous configuration if the next one reserves none.
+func (d *Daemon) applyNamespaceBucket(namespace string, prev, next NamespaceConfig, hadConfig bool) error {
+	if next.BucketCpus > 0 {
+		p, ok := d.tierPolicy(next.Allocator, namespace).(NamespaceBucketPolicy)
+		if !ok {
+			return errNamespaceBucketsNotSupported
+		}
+		_, err := p.CreateNamespaceBucket(namespace, next.BucketCpus, 0, &d.state)
+		return err
+	}
+	if !hadConfig || prev.BucketCpus == 0 {
+		return nil
+	}
+	if p, ok := d.tierPolicy(prev.Allocator, namespace).(NamespaceBucketPolicy); ok {
+		_, err := p.DeleteNamespaceBucket(namespace, &d.state)
+		return err
+	}
+	return nil
+}
+
+// tierPolicy returns the policy of the allocator selectable by namespace configurations, or the policy of
+// the namespace given by options if the allocator is empty.
+func (d *Daemon) tierPolicy(allocator string, namespace string) Policy {
+	tier := d.options.namespaceTier(namespace)
+	if allocator != "" {
+		tier = allocatorTier(allocator)
+	}
+	if p, ok := d.tierPolicies[tier]; ok {
+		return p
+	}
+	return d.policy
+}
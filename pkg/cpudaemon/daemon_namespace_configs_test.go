@@ -0,0 +1,161 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// newDaemonWithNamespaceAllocators returns numa daemon with scatter and numa-namespace allocators
+// selectable by namespace configurations.
+func newDaemonWithNamespaceAllocators(t *testing.T) *Daemon {
+	m := newMockedCgroups()
+	return newTestDaemon(
+		t, NewStaticPolocy(NewNumaAwareAllocator(m, false)),
+		WithNamespaceAllocators(map[string]Allocator{
+			"scatter":        NewScatterAllocator(m, false),
+			"numa-namespace": NewNumaPerNamespaceAllocator(2, m, false, false, logr.Discard()),
+		}),
+	)
+}
+
+func TestNewDaemonValidatesNamespaceAllocators(t *testing.T) {
+	_, err := New(
+		"testdata/no_state",
+		"testdata/node_info",
+		"daemon.state",
+		&MockedPolicy{},
+		logr.Discard(),
+		WithNamespaceAllocators(map[string]Allocator{"numa": nil}),
+	)
+
+	assert.Equal(t, ConfigurationError, err.(DaemonError).ErrorType)
+}
+
+func TestPodsOfConfiguredNamespaceUseItsAllocator(t *testing.T) {
+	d := newDaemonWithNamespaceAllocators(t)
+	p := podWithPlacement(ctlplaneapi.Placement_DEFAULT)
+	_, err := d.SetNamespaceConfig(context.Background(), &ctlplaneapi.SetNamespaceConfigRequest{
+		Namespace: p.namespace,
+		Allocator: "scatter",
+		Exclusive: true,
+	})
+	require.Nil(t, err)
+
+	createPodForRepack(t, d, p)
+
+	assert.Equal(t, "allocator/scatter", d.state.Pods[p.pid].Tier)
+	assert.ElementsMatch(t, []int{0, 1}, containerNodes(t, d, p), "scatter allocator spreads containers")
+}
+
+func TestPodKeepsNamespaceAllocatorUntilDeleted(t *testing.T) {
+	d := newDaemonWithNamespaceAllocators(t)
+	p := podWithPlacement(ctlplaneapi.Placement_DEFAULT)
+	_, err := d.SetNamespaceConfig(context.Background(), &ctlplaneapi.SetNamespaceConfigRequest{
+		Namespace: p.namespace,
+		Allocator: "scatter",
+		Exclusive: true,
+	})
+	require.Nil(t, err)
+	createPodForRepack(t, d, p)
+
+	_, err = d.DeleteNamespaceConfig(context.Background(), &ctlplaneapi.DeleteNamespaceConfigRequest{
+		Namespace: p.namespace,
+	})
+	require.Nil(t, err)
+
+	assert.Equal(t, "allocator/scatter", d.state.Pods[p.pid].Tier)
+	assert.Empty(t, d.namespaceTier(p.namespace))
+}
+
+func TestGuaranteedContainersOfNonExclusiveNamespaceRunShared(t *testing.T) {
+	d := newDaemonWithNamespaceAllocators(t)
+	p := podWithPlacement(ctlplaneapi.Placement_DEFAULT)
+	_, err := d.SetNamespaceConfig(context.Background(), &ctlplaneapi.SetNamespaceConfigRequest{
+		Namespace: p.namespace,
+	})
+	require.Nil(t, err)
+
+	createPodForRepack(t, d, p)
+
+	assert.Empty(t, d.state.Allocated)
+	for _, c := range d.state.Pods[p.pid].Containers {
+		assert.Equal(t, Burstable, c.QS)
+		assert.True(t, c.SharedPoolFallback)
+		assert.Contains(t, d.state.getAllocationExplanation(c.CID), "namespace configured without exclusive cpus")
+	}
+}
+
+func TestSetNamespaceConfigFailsForUnknownAllocator(t *testing.T) {
+	d := newDaemonWithNamespaceAllocators(t)
+
+	_, err := d.SetNamespaceConfig(context.Background(), &ctlplaneapi.SetNamespaceConfigRequest{
+		Namespace: "shop",
+		Allocator: "default",
+	})
+
+	var dErr DaemonError
+	require.ErrorAs(t, err, &dErr)
+	assert.Equal(t, ConfigurationError, dErr.ErrorType)
+	assert.Contains(t, dErr.ErrorMessage, "available are: numa-namespace, scatter")
+	assert.Empty(t, d.state.NamespaceConfigs)
+}
+
+func TestNamespaceConfigReservesBucket(t *testing.T) {
+	d := newDaemonWithNamespaceAllocators(t)
+	allocator := d.tierPolicies["allocator/numa-namespace"].(*StaticPolicy).allocator.(*NumaPerNamespaceAllocator)
+
+	reply, err := d.SetNamespaceConfig(context.Background(), &ctlplaneapi.SetNamespaceConfigRequest{
+		Namespace:  "shop",
+		Allocator:  "numa-namespace",
+		Exclusive:  true,
+		BucketCpus: 2,
+	})
+
+	require.Nil(t, err)
+	assert.Equal(t, ctlplaneapi.NamespaceConfigInfo{
+		Namespace:  "shop",
+		Allocator:  "numa-namespace",
+		Exclusive:  true,
+		BucketCpus: 2,
+	}, *reply)
+	assert.Contains(t, allocator.NamespaceToBucket, "shop")
+
+	reply, err = d.DeleteNamespaceConfig(context.Background(), &ctlplaneapi.DeleteNamespaceConfigRequest{
+		Namespace: "shop",
+	})
+
+	require.Nil(t, err)
+	assert.True(t, reply.Deleted)
+	assert.NotContains(t, allocator.NamespaceToBucket, "shop")
+	assert.Empty(t, d.state.NamespaceConfigs)
+}
+
+func TestNamespaceConfigFailsToReserveBucketOfOtherAllocators(t *testing.T) {
+	d := newDaemonWithNamespaceAllocators(t)
+
+	_, err := d.SetNamespaceConfig(context.Background(), &ctlplaneapi.SetNamespaceConfigRequest{
+		Namespace:  "shop",
+		Allocator:  "scatter",
+		BucketCpus: 2,
+	})
+
+	assert.Equal(t, errNamespaceBucketsNotSupported, err)
+	assert.Empty(t, d.state.NamespaceConfigs)
+}
+
+func TestDeleteNamespaceConfigFailsIfNotConfigured(t *testing.T) {
+	d := newDaemonWithNamespaceAllocators(t)
+
+	_, err := d.DeleteNamespaceConfig(context.Background(), &ctlplaneapi.DeleteNamespaceConfigRequest{
+		Namespace: "shop",
+	})
+
+	var dErr DaemonError
+	require.ErrorAs(t, err, &dErr)
+	assert.Equal(t, NamespaceNotFound, dErr.ErrorType)
+}
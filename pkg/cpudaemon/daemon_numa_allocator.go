@@ -1,6 +1,8 @@
 package cpudaemon
 
 import (
+	"context"
+	"fmt"
 	"strconv"
 	"strings"
 
@@ -12,19 +14,62 @@ import (
 // whose leafs are cpus and nodes are next levels of topology organization. For each request alllocator
 // find such allocation, that will minimize the topology distance between cpus. In our case the topology
 // distance between n leafs is defined as maximal path length from any of those leafs to the nearest
-// common predecessor.
+// common predecessor. The first level topology node (eg. numa node or socket) is selected by the placement
+// pipeline.
 type NumaAwareAllocator struct {
 	ctrl          CgroupController
 	memoryPinning bool
+	placement     PlacementPipeline
+	fullCores     bool // cpus of guaranteed containers are taken as whole physical cores
 }
 
 var _ Allocator = &NumaAwareAllocator{}
 
 // NewNumaAwareAllocator Creates new numa-aware allocator with default cgroup controller.
 func NewNumaAwareAllocator(cgroupController CgroupController, memoryPinning bool) *NumaAwareAllocator {
+	return NewNumaAwareAllocatorWithPlacement(cgroupController, memoryPinning, DefaultPlacementPipeline())
+}
+
+// NewNumaAwareAllocatorWithPlacement Creates new numa-aware allocator using given placement pipeline.
+func NewNumaAwareAllocatorWithPlacement(
+	cgroupController CgroupController,
+	memoryPinning bool,
+	placement PlacementPipeline,
+) *NumaAwareAllocator {
 	return &NumaAwareAllocator{
 		ctrl:          cgroupController,
 		memoryPinning: memoryPinning,
+		placement:     placement,
+	}
+}
+
+// EnableFullCores makes the allocator take cpus of guaranteed containers as whole physical cores, with all
+// their hardware threads, so that no physical core is shared between two guaranteed containers. Requests
+// are rounded up to whole cores, eg. a container requesting 3 cpus gets 2 cores (4 cpus) on a machine with
+// 2 threads per core.
+func (d *NumaAwareAllocator) EnableFullCores() {
+	d.fullCores = true
+}
+
+// take takes n cpus from the subtree of given node, rounded up to whole physical cores if the allocator
+// takes full cores.
+func (d *NumaAwareAllocator) take(t *numautils.NumaTopology, node *numautils.TopologyNode, n int) ([]int, error) {
+	if d.fullCores {
+		return t.TakeCoresFrom(node, n)
+	}
+	return t.TakeFrom(node, n)
+}
+
+// isMemoryPinningEnabled returns memory pinning setting of container's pod. If pod does not override it,
+// allocator setting is used.
+func isMemoryPinningEnabled(allocatorMemoryPinning bool, c Container, s *DaemonState) bool {
+	switch s.Pods[c.PID].MemoryPinning {
+	case ctlplaneapi.MemoryPinning_MEMORY_PINNING_ENABLED:
+		return true
+	case ctlplaneapi.MemoryPinning_MEMORY_PINNING_DISABLED:
+		return false
+	default:
+		return allocatorMemoryPinning
 	}
 }
 
@@ -58,12 +103,16 @@ func getMemoryPinning(topology *numautils.NumaTopology, cpuIds []int) string {
 	return strings.Join(nodesList, ",")
 }
 
-func (d *NumaAwareAllocator) takeCpus(c Container, s *DaemonState) error {
-	if c.QS != Guaranteed {
+func (d *NumaAwareAllocator) takeCpus(ctx context.Context, c Container, s *DaemonState) error {
+	if !takesExclusiveCpus(c) {
+		s.setAllocationExplanation(c.CID, fmt.Sprintf(sharedPoolExplanation, "numa"))
 		return nil
 	}
 
-	cpuIds, err := s.Topology.Take(c.Cpus)
+	cpuIds, selection, err := d.takeCpusWithHint(c, s)
+	if err != nil {
+		cpuIds, selection, err = d.takeCpusFromBestNode(c, s)
+	}
 	if err != nil {
 		return DaemonError{
 			ErrorType:    CpusNotAvailable,
@@ -72,7 +121,7 @@ func (d *NumaAwareAllocator) takeCpus(c Container, s *DaemonState) error {
 	}
 
 	allocatedList := s.Allocated[c.CID]
-	cpuSetList := make([]string, 0, c.Cpus)
+	cpuSetList := make([]string, 0, len(cpuIds))
 	for _, cpuID := range cpuIds {
 		allocatedList = append(allocatedList, ctlplaneapi.CPUBucket{
 			StartCPU: cpuID,
@@ -81,17 +130,160 @@ func (d *NumaAwareAllocator) takeCpus(c Container, s *DaemonState) error {
 		cpuSetList = append(cpuSetList, strconv.Itoa(cpuID))
 	}
 	s.Allocated[c.CID] = allocatedList
+	taken := countCpus(len(cpuIds))
+	if d.fullCores {
+		taken += " of full cores"
+	}
+	s.setAllocationExplanation(c.CID, fmt.Sprintf(
+		"numa allocator: %s, %s taken from %s",
+		selection,
+		taken,
+		describeNode(lowestCommonNode(s.Topology.Topology, CPUSetFromBucketList(allocatedList))),
+	))
 
-	return d.ctrl.UpdateCPUSet(
-		s.CGroupPath,
+	return updateContainerCPUSet(
+		ctx,
+		d.ctrl,
+		s,
 		c,
 		strings.Join(cpuSetList, ","),
-		getMemoryPinningIfEnabled(d.memoryPinning, &s.Topology, cpuIds),
+		getMemoryPinningIfEnabled(isMemoryPinningEnabled(d.memoryPinning, c, s), &s.Topology, cpuIds),
+	)
+}
+
+// takeCpusFromBestNode takes container cpus from the node selected by the placement pipeline. Pods
+// with COMPACT or SCATTER placement use their own pipelines, pods of a spread group are also spread
+// across nodes. If no node can host the whole container, cpus are taken from the whole topology.
+// Returns also description of the selection.
+func (d *NumaAwareAllocator) takeCpusFromBestNode(c Container, s *DaemonState) ([]int, string, error) {
+	pod := s.Pods[c.PID]
+	placement := podPlacementPipeline(pod.Placement, d.placement)
+	if pod.Annotations[SpreadGroupAnnotation] != "" {
+		placement = placement.withSpreadGroup()
+	}
+	if node := placement.selectNode(c, s); node != nil {
+		selection := fmt.Sprintf(
+			"%s selected %s with score %d", placementName(pod), describeNode(node), placement.score(c, s, node),
+		)
+		if cpuIds, err := d.take(&s.Topology, node, c.Cpus); err == nil {
+			return cpuIds, selection, nil
+		}
+	}
+	cpuIds, err := d.take(&s.Topology, s.Topology.Topology, c.Cpus)
+	return cpuIds, fmt.Sprintf("no %s fits the whole container", placementLevel(&s.Topology)), err
+}
+
+// takeCpusWithHint takes container cpus preferring cpus of its allocation hint, eg. cpus of the container
+// before resize. Cpus missing to the hinted ones are taken from the first level topology node of the hint,
+// so that the container does not span nodes. Fails if the container has no hint, the hint spans nodes or
+// its node cannot fit the container; the container is then placed as a new one.
+func (d *NumaAwareAllocator) takeCpusWithHint(c Container, s *DaemonState) ([]int, string, error) {
+	hint := s.allocationHint(c.CID)
+	node := hintNode(s.Topology.Topology, hint)
+	if node == nil {
+		return nil, "", numautils.ErrNotAvailable
+	}
+	var (
+		cpuIds []int
+		err    error
 	)
+	if d.fullCores {
+		cpuIds, err = takeCoresWithHint(&s.Topology, node, c.Cpus, hint)
+	} else {
+		cpuIds, err = takeCpusWithHint(&s.Topology, node, c.Cpus, hint)
+	}
+	return cpuIds, "allocation hint " + hint.ToCpuString() + " preferred", err
+}
+
+// hintNode returns the first level topology node containing all hinted cpus, the root on machines without
+// nodes above cores. Returns nil if there is no hint or it spans nodes.
+func hintNode(root *numautils.TopologyNode, hint CPUSet) *numautils.TopologyNode {
+	if root == nil || hint.Count() == 0 {
+		return nil
+	}
+	for _, child := range root.Children {
+		if child.IsLeaf() || child.Type == numautils.Core {
+			return root
+		}
+		if hint.Clone().RemoveAll(nodeCpuSet(child)).Count() == 0 {
+			return child
+		}
+	}
+	return nil
+}
+
+// takeCpusWithHint takes n cpus from the subtree of given node, preferring hinted cpus as long as they are
+// available.
+func takeCpusWithHint(t *numautils.NumaTopology, node *numautils.TopologyNode, n int, hint CPUSet) ([]int, error) {
+	cpuIds := make([]int, 0, n)
+	for _, cpu := range hint.Sorted() {
+		if len(cpuIds) == n {
+			break
+		}
+		if err := t.TakeCpu(cpu); err == nil {
+			cpuIds = append(cpuIds, cpu)
+		}
+	}
+	if len(cpuIds) == n {
+		return cpuIds, nil
+	}
+
+	rest, err := t.TakeFrom(node, n-len(cpuIds))
+	if err != nil {
+		for _, cpu := range cpuIds {
+			_ = t.Return(cpu)
+		}
+		return []int{}, err
+	}
+	return append(cpuIds, rest...), nil
+}
+
+// takeCoresWithHint works as takeCpusWithHint, but takes whole physical cores until they have at least n
+// cpus. Cores of hinted cpus are preferred as long as all their cpus are available.
+func takeCoresWithHint(t *numautils.NumaTopology, node *numautils.TopologyNode, n int, hint CPUSet) ([]int, error) {
+	cpuIds := make([]int, 0, n)
+	taken := CPUSet{}
+	for _, cpu := range hint.Sorted() {
+		if len(cpuIds) >= n {
+			break
+		}
+		siblings, err := t.Siblings(cpu)
+		if err != nil || taken.Contains(cpu) || !allAvailable(t, siblings) {
+			continue
+		}
+		for _, sibling := range siblings {
+			_ = t.TakeCpu(sibling)
+			taken.Add(sibling)
+		}
+		cpuIds = append(cpuIds, siblings...)
+	}
+	if len(cpuIds) >= n {
+		return cpuIds, nil
+	}
+
+	rest, err := t.TakeCoresFrom(node, n-len(cpuIds))
+	if err != nil {
+		for _, cpu := range cpuIds {
+			_ = t.Return(cpu)
+		}
+		return []int{}, err
+	}
+	return append(cpuIds, rest...), nil
+}
+
+// allAvailable checks if all given cpus are available in the topology.
+func allAvailable(t *numautils.NumaTopology, cpus []int) bool {
+	for _, cpu := range cpus {
+		leaf, err := t.FindCpu(cpu)
+		if err != nil || !leaf.Available() {
+			return false
+		}
+	}
+	return true
 }
 
-func (d *NumaAwareAllocator) freeCpus(c Container, s *DaemonState) error {
-	if c.QS != Guaranteed {
+func (d *NumaAwareAllocator) freeCpus(ctx context.Context, c Container, s *DaemonState) error {
+	if !takesExclusiveCpus(c) {
 		return nil
 	}
 
@@ -118,17 +310,19 @@ func (d *NumaAwareAllocator) freeCpus(c Container, s *DaemonState) error {
 	return nil
 }
 
-func (d *NumaAwareAllocator) clearCpus(c Container, s *DaemonState) error {
+func (d *NumaAwareAllocator) clearCpus(ctx context.Context, c Container, s *DaemonState) error {
 	allCpus := s.Topology.Topology.GetLeafs()
 	cpuSet := CPUSet{}
 	for _, leaf := range allCpus {
 		cpuSet.Add(leaf.Value)
 	}
 
-	return d.ctrl.UpdateCPUSet(
-		s.CGroupPath,
+	return updateContainerCPUSet(
+		ctx,
+		d.ctrl,
+		s,
 		c,
 		cpuSet.ToCpuString(),
-		getMemoryPinningIfEnabledFromCpuSet(d.memoryPinning, &s.Topology, cpuSet),
+		getMemoryPinningIfEnabledFromCpuSet(isMemoryPinningEnabled(d.memoryPinning, c, s), &s.Topology, cpuSet),
 	)
 }
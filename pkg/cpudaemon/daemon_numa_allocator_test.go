@@ -1,10 +1,15 @@
 package cpudaemon
 
 import (
+	"context"
 	"os"
 	"testing"
 
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/numautils/testtopo"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -13,6 +18,7 @@ func newMockedNumaAllocator() *NumaAwareAllocator {
 	allocator := &NumaAwareAllocator{
 		ctrl:          &cgroupMock,
 		memoryPinning: true,
+		placement:     DefaultPlacementPipeline(),
 	}
 	return allocator
 }
@@ -33,7 +39,7 @@ func TestNumaTakeCpuWithoutMemoryPinning(t *testing.T) {
 	mock := allocator.ctrl.(*CgroupsMock)
 	mock.On("UpdateCPUSet", s.CGroupPath, container, "0,1", "").Return(nil)
 
-	assert.Nil(t, allocator.takeCpus(container, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), container, s))
 
 	assertCpuState(t, s, &container, "0,1")
 	mock.AssertExpectations(t)
@@ -54,9 +60,10 @@ func TestNumaTakeCpu(t *testing.T) {
 	mock := allocator.ctrl.(*CgroupsMock)
 	mock.On("UpdateCPUSet", s.CGroupPath, container, "0,1", "0").Return(nil)
 
-	assert.Nil(t, allocator.takeCpus(container, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), container, s))
 
 	assertCpuState(t, s, &container, "0,1")
+	assert.Equal(t, "0", s.getMemoryNodes(container.CID))
 	mock.AssertExpectations(t)
 }
 
@@ -72,7 +79,7 @@ func TestNumaTakeCpuFailsIfTooMuchCpus(t *testing.T) {
 	container := baseContainer(1)
 	container.Cpus = 3
 
-	assert.NotNil(t, allocator.takeCpus(container, s))
+	assert.NotNil(t, allocator.takeCpus(context.Background(), container, s))
 }
 
 func TestNumaFreeCpu(t *testing.T) {
@@ -90,10 +97,10 @@ func TestNumaFreeCpu(t *testing.T) {
 	mock := allocator.ctrl.(*CgroupsMock)
 	mock.On("UpdateCPUSet", s.CGroupPath, container, "0", "0").Return(nil)
 
-	assert.Nil(t, allocator.takeCpus(container, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), container, s))
 	assert.Contains(t, s.Allocated, container.CID)
 
-	assert.Nil(t, allocator.freeCpus(container, s))
+	assert.Nil(t, allocator.freeCpus(context.Background(), container, s))
 	assert.NotContains(t, s.Allocated, container.CID)
 	mock.AssertExpectations(t)
 }
@@ -113,7 +120,216 @@ func TestNumaClearCpu(t *testing.T) {
 	mock := allocator.ctrl.(*CgroupsMock)
 	mock.On("UpdateCPUSet", s.CGroupPath, container, "0,1", "0").Return(nil)
 
-	assert.Nil(t, allocator.clearCpus(container, s))
+	assert.Nil(t, allocator.clearCpus(context.Background(), container, s))
 
 	mock.AssertExpectations(t)
 }
+
+func TestNumaTakeCpuPodOverridesMemoryPinning(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		allocatorMemoryPinning bool
+		podMemoryPinning       ctlplaneapi.MemoryPinning
+		expectedMemSet         string
+	}{
+		{"pod enables", false, ctlplaneapi.MemoryPinning_MEMORY_PINNING_ENABLED, "0"},
+		{"pod disables", true, ctlplaneapi.MemoryPinning_MEMORY_PINNING_DISABLED, ""},
+		{"pod uses default", true, ctlplaneapi.MemoryPinning_MEMORY_PINNING_DEFAULT, "0"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			s := getTestDaemonState(t.TempDir(), 2)
+			s.Topology = oneLevelTopology(2)
+
+			allocator := newMockedNumaAllocator()
+			allocator.memoryPinning = testCase.allocatorMemoryPinning
+			container := baseContainer(1)
+			podMeta := s.Pods[container.PID]
+			podMeta.MemoryPinning = testCase.podMemoryPinning
+			s.Pods[container.PID] = podMeta
+
+			mock := allocator.ctrl.(*CgroupsMock)
+			mock.On("UpdateCPUSet", s.CGroupPath, container, "0", testCase.expectedMemSet).Return(nil)
+
+			assert.Nil(t, allocator.takeCpus(context.Background(), container, s))
+			mock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNumaTakeCpuPrefersHintedCpus(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test_cpu")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	s := getTestDaemonState(dir, 4)
+	s.Topology = oneLevelTopology(4)
+	require.Nil(t, s.Topology.TakeCpu(3))
+
+	allocator := newMockedNumaAllocator()
+	container := baseContainer(1)
+	container.Cpus = 2
+	s.setAllocationHint(container.CID, CPUSet{2: struct{}{}, 3: struct{}{}})
+
+	mock := allocator.ctrl.(*CgroupsMock)
+	mock.On("UpdateCPUSet", s.CGroupPath, container, "2,0", "0").Return(nil)
+
+	assert.Nil(t, allocator.takeCpus(context.Background(), container, s))
+
+	assertCpuState(t, s, &container, "0,2")
+	mock.AssertExpectations(t)
+}
+
+// newResizeTestState returns state of two sockets with cpus 0-7 and 8-15 and their siblings 16-31, where
+// only given cpus of socket 0 are available.
+func newResizeTestState(t *testing.T, free ...int) *DaemonState {
+	s := getTestDaemonState(t.TempDir(), testtopo.TwoSockets.NumCpus())
+	topology, err := testtopo.TwoSockets.Topology()
+	require.Nil(t, err)
+	s.Topology = topology
+	available := CPUSet{}
+	for _, cpu := range free {
+		available.Add(cpu)
+	}
+	for cpu, info := range topology.CpuInformation {
+		if info.Node == 0 && !available.Contains(cpu) {
+			require.Nil(t, s.Topology.TakeCpu(cpu))
+		}
+	}
+	return s
+}
+
+func TestNumaTakeCpuGrowsContainerOnHintNode(t *testing.T) {
+	s := newResizeTestState(t, 0, 1, 2, 3)
+	allocator := newMockedNumaAllocator()
+	allocator.ctrl.(*CgroupsMock).On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	container := baseContainer(1)
+	container.Cpus = 4
+	s.setAllocationHint(container.CID, CPUSet{0: {}, 1: {}})
+
+	require.Nil(t, allocator.takeCpus(context.Background(), container, s))
+
+	assertCpuState(t, s, &container, "0-3")
+}
+
+func TestNumaTakeCpuMovesGrownContainerIfHintNodeIsFull(t *testing.T) {
+	s := newResizeTestState(t, 0, 1, 2)
+	allocator := newMockedNumaAllocator()
+	allocator.ctrl.(*CgroupsMock).On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	container := baseContainer(1)
+	container.Cpus = 4
+	s.setAllocationHint(container.CID, CPUSet{0: {}, 1: {}})
+
+	require.Nil(t, allocator.takeCpus(context.Background(), container, s))
+
+	cpus := CPUSetFromBucketList(s.Allocated[container.CID])
+	require.Equal(t, 4, cpus.Count())
+	for cpu := range cpus {
+		assert.Equal(t, 1, s.Topology.CpuInformation[cpu].Node, "cpu %d is not on the node with free cpus", cpu)
+	}
+	available := topologyAvailableCpus(&s.Topology)
+	assert.True(t, available.Contains(0) && available.Contains(1), "hinted cpus shall be returned")
+	assert.NotContains(t, s.getAllocationExplanation(container.CID), "allocation hint")
+}
+
+func TestNumaTakeCpuKeepsContainersOnSingleNode(t *testing.T) {
+	for _, spec := range []testtopo.Spec{testtopo.TwoSockets, testtopo.SubNumaSocket, testtopo.LargeServer} {
+		s := getTestDaemonState(t.TempDir(), spec.NumCpus())
+		topology, err := spec.Topology()
+		require.Nil(t, err)
+		s.Topology = topology
+		allocator := newMockedNumaAllocator()
+		allocator.ctrl.(*CgroupsMock).On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		size := spec.NumCpus() / spec.NumNodes() / 2
+
+		allocated := CPUSet{}
+		for i := 0; i < 2*spec.NumNodes(); i++ {
+			c := baseContainer(i)
+			c.Cpus = size
+			require.Nil(t, allocator.takeCpus(context.Background(), c, s), spec)
+
+			cpus := CPUSetFromBucketList(s.Allocated[c.CID])
+			require.Equal(t, size, cpus.Count(), spec)
+			nodes := map[int]struct{}{}
+			for cpu := range cpus {
+				assert.False(t, allocated.Contains(cpu), "cpu %d allocated twice on %+v", cpu, spec)
+				allocated.Add(cpu)
+				nodes[topology.CpuInformation[cpu].Node] = struct{}{}
+				sibling := (cpu + spec.NumCores()) % spec.NumCpus()
+				assert.True(t, cpus.Contains(sibling), "cpu %d without its sibling on %+v", cpu, spec)
+			}
+			assert.Len(t, nodes, 1, spec)
+		}
+		assert.Equal(t, spec.NumCpus(), allocated.Count(), spec)
+	}
+}
+
+// newFullCoresTestState returns state of a socket with four cores of two threads: cpus 0-3 and their
+// siblings 4-7.
+func newFullCoresTestState(t *testing.T) *DaemonState {
+	s := getTestDaemonState(t.TempDir(), 8)
+	topology, err := testtopo.Spec{Sockets: 1, DiesPerSocket: 1, CoresPerDie: 4, ThreadsPerCore: 2}.Topology()
+	require.Nil(t, err)
+	s.Topology = topology
+	return s
+}
+
+func TestNumaTakeCpuFullCoresRoundsUpToCores(t *testing.T) {
+	s := newFullCoresTestState(t)
+	require.Nil(t, s.Topology.TakeCpu(0)) // core of cpus 0 and 4 is partially taken
+	allocator := newMockedNumaAllocator()
+	allocator.EnableFullCores()
+	allocator.ctrl.(*CgroupsMock).On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	container := baseContainer(1)
+	container.Cpus = 3
+
+	require.Nil(t, allocator.takeCpus(context.Background(), container, s))
+
+	assertCpuState(t, s, &container, "1,2,5,6")
+	assert.Contains(t, s.getAllocationExplanation(container.CID), "4 cpus of full cores taken")
+	assert.Equal(t, 3, topologyAvailableCpus(&s.Topology).Count())
+}
+
+func TestNumaTakeCpuFullCoresNeverSharesCore(t *testing.T) {
+	s := newFullCoresTestState(t)
+	allocator := newMockedNumaAllocator()
+	allocator.EnableFullCores()
+	allocator.ctrl.(*CgroupsMock).On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	for i := 0; i < 4; i++ {
+		c := baseContainer(i)
+		c.Cpus = 1
+		require.Nil(t, allocator.takeCpus(context.Background(), c, s))
+		cpus := CPUSetFromBucketList(s.Allocated[c.CID])
+		require.Equal(t, 2, cpus.Count())
+		for cpu := range cpus {
+			assert.True(t, cpus.Contains((cpu+4)%8), "cpu %d without its sibling", cpu)
+		}
+	}
+	c := baseContainer(4)
+	c.Cpus = 1
+	err := allocator.takeCpus(context.Background(), c, s)
+
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, CpusNotAvailable, daemonErr.ErrorType)
+}
+
+func TestNumaTakeCpuFullCoresPrefersHintedCores(t *testing.T) {
+	s := newFullCoresTestState(t)
+	require.Nil(t, s.Topology.TakeCpu(6))
+	allocator := newMockedNumaAllocator()
+	allocator.EnableFullCores()
+	allocator.ctrl.(*CgroupsMock).On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	container := baseContainer(1)
+	container.Cpus = 4
+	s.setAllocationHint(container.CID, CPUSet{2: struct{}{}, 3: struct{}{}})
+
+	require.Nil(t, allocator.takeCpus(context.Background(), container, s))
+
+	cpus := CPUSetFromBucketList(s.Allocated[container.CID])
+	assert.True(t, cpus.Contains(3) && cpus.Contains(7), "hinted free core shall be taken")
+	assert.False(t, cpus.Contains(2), "hinted core with taken sibling shall be skipped")
+	assert.Equal(t, 4, cpus.Count())
+}
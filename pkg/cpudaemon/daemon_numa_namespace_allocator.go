@@ -1,8 +1,10 @@
 package cpudaemon
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -29,6 +31,11 @@ type NumaPerNamespaceAllocator struct {
 	NamespaceToBucket     map[string]int
 	BucketToNumContainers map[int]int
 	globalBucket          int
+	namespaceMemoryNodes  map[string]string   // memory nodes of namespaces, regardless of nodes of their cpus
+	burstableSoftPinning  bool                // pin burstable containers to cpus sized to their request
+	bucketWeights         bool                // set cpu weight of shared containers proportional to their request
+	pinnedNamespaces      map[string]struct{} // namespaces with explicitly created buckets, kept when empty
+	namespaceWeights      map[string]uint64   // cpu weight per requested cpu of namespaces, if not default
 }
 
 var _ Allocator = &NumaPerNamespaceAllocator{}
@@ -53,41 +60,71 @@ func NewNumaPerNamespaceAllocator(
 	}
 }
 
+// SetNamespaceMemoryNodes binds memory of all containers of given namespaces to given numa nodes (eg.
+// "team-a" to "0"), regardless of the placement of their cpus and of the memory pinning setting of the
+// allocator. Pods which explicitly disable memory pinning are not affected.
+func (d *NumaPerNamespaceAllocator) SetNamespaceMemoryNodes(nodes map[string]string) {
+	d.namespaceMemoryNodes = make(map[string]string, len(nodes))
+	for namespace, mems := range nodes {
+		d.namespaceMemoryNodes[namespace] = mems
+	}
+}
+
+// EnableBurstableSoftPinning pins burstable containers requesting whole cpus to as many shared cpus of
+// their namespace bucket as they request, instead of all of them. Cpus are not taken exclusively, each
+// of them is given to the smallest possible number of such containers. Cpu quota of containers set by
+// kubelet is not changed, so they can still use the whole selected cpus above their request.
+func (d *NumaPerNamespaceAllocator) EnableBurstableSoftPinning() {
+	d.burstableSoftPinning = true
+}
+
+// EnableBucketWeights sets cpu weight of containers sharing cpus of their namespace bucket proportional
+// to the number of cpus they request, so that cpu time inside the bucket is shared proportionally. The
+// weight decides between sibling cgroups, so it splits cpu time between containers of the same pod, while
+// pods are still weighted by kubelet.
+func (d *NumaPerNamespaceAllocator) EnableBucketWeights() {
+	d.bucketWeights = true
+}
+
+// softPinned checks if the container is pinned to cpus sized to its request.
+func (d *NumaPerNamespaceAllocator) softPinned(c Container) bool {
+	return d.burstableSoftPinning && c.QS == Burstable && c.Cpus > 0
+}
+
+// memoryNodes returns memory nodes of the container: nodes configured for its namespace, or nodes of
+// given cpus if memory pinning is enabled.
+func (d *NumaPerNamespaceAllocator) memoryNodes(c Container, s *DaemonState, cpus CPUSet) string {
+	if mems, ok := d.namespaceMemoryNodes[c.Namespace]; ok &&
+		s.Pods[c.PID].MemoryPinning != ctlplaneapi.MemoryPinning_MEMORY_PINNING_DISABLED {
+		return mems
+	}
+	return getMemoryPinningIfEnabledFromCpuSet(isMemoryPinningEnabled(d.memoryPinning, c, s), &s.Topology, cpus)
+}
+
 // getBucket returns list of cpus associated with given namespace.
 func (d *NumaPerNamespaceAllocator) getBucket(s *DaemonState, namespace string) ([]*numautils.TopologyNode, error) {
-	leafs := s.Topology.Topology.GetLeafs()
-	bucketSize := len(leafs) / d.NumBuckets
-
 	namespaceBucket, ok := d.NamespaceToBucket[namespace]
 
 	if !ok {
 		return []*numautils.TopologyNode{}, ErrBucketNotFound
 	}
-
-	if namespaceBucket == d.NumBuckets-1 { // it is last bucket, might be larger
-		return leafs[bucketSize*namespaceBucket:], nil
-	}
-	return leafs[bucketSize*namespaceBucket : bucketSize*(namespaceBucket+1)], nil
+	return d.bucketCpus(s, namespaceBucket), nil
 }
 
-func (d *NumaPerNamespaceAllocator) takeCpus(c Container, s *DaemonState) error {
-	if c.QS == Guaranteed && c.Cpus == 0 {
-		return DaemonError{
-			ErrorType:    NotImplemented,
-			ErrorMessage: "number of guaranteed container cpus shall be greater than 0",
-		}
-	}
+// bucketCpus returns list of cpus of the bucket with given index.
+func (d *NumaPerNamespaceAllocator) bucketCpus(s *DaemonState, index int) []*numautils.TopologyNode {
+	leafs := s.Topology.Topology.GetLeafs()
+	bucketSize := len(leafs) / d.NumBuckets
 
-	podMetadata, ok := s.Pods[c.PID]
-	if !ok {
-		return DaemonError{
-			ErrorType:    PodNotFound,
-			ErrorMessage: fmt.Sprintf("cannot retrieve pod %s metadata", c.PID),
-		}
+	if index == d.NumBuckets-1 { // it is last bucket, might be larger
+		return leafs[bucketSize*index:]
 	}
+	return leafs[bucketSize*index : bucketSize*(index+1)]
+}
 
-	if _, ok := d.NamespaceToBucket[podMetadata.Namespace]; !ok {
-		if err := d.newNamespace(podMetadata.Namespace); err != nil {
+func (d *NumaPerNamespaceAllocator) takeCpus(ctx context.Context, c Container, s *DaemonState) error {
+	if _, ok := d.NamespaceToBucket[c.Namespace]; !ok {
+		if err := d.newNamespace(c.Namespace); err != nil {
 			return DaemonError{
 				ErrorType:    CpusNotAvailable,
 				ErrorMessage: err.Error(),
@@ -95,7 +132,7 @@ func (d *NumaPerNamespaceAllocator) takeCpus(c Container, s *DaemonState) error
 		}
 	}
 
-	bucket, err := d.getBucket(s, podMetadata.Namespace)
+	bucket, err := d.getBucket(s, c.Namespace)
 	if err != nil {
 		return DaemonError{
 			ErrorType:    CpusNotAvailable,
@@ -103,13 +140,16 @@ func (d *NumaPerNamespaceAllocator) takeCpus(c Container, s *DaemonState) error
 		}
 	}
 
-	namespaceBucket := d.NamespaceToBucket[podMetadata.Namespace]
+	namespaceBucket := d.NamespaceToBucket[c.Namespace]
 	d.BucketToNumContainers[namespaceBucket]++
 
 	var cpuIds []int
-	if c.QS == Guaranteed {
-		cpuIds, err = d.takeGuaranteedCpusFromBucket(bucket, c)
-	} else {
+	switch {
+	case takesExclusiveCpus(c):
+		cpuIds, err = d.takeGuaranteedCpusFromBucket(preferHinted(bucket, s.allocationHint(c.CID)), c)
+	case d.softPinned(c):
+		cpuIds = d.selectSoftPinnedCpus(s, bucket, c, CPUSet{})
+	default:
 		cpuIds, err = d.takeAllCpusFromBucket(bucket, c)
 	}
 	if err != nil {
@@ -129,16 +169,61 @@ func (d *NumaPerNamespaceAllocator) takeCpus(c Container, s *DaemonState) error
 	}
 
 	s.Allocated[c.CID] = allocatedList
-	if err = d.ctrl.UpdateCPUSet(s.CGroupPath, c, strings.Join(cpuSetList, ","), getMemoryPinningIfEnabled(d.memoryPinning, &s.Topology, cpuIds)); err != nil {
+	s.setAllocationExplanation(c.CID, d.explainAllocation(c, s, namespaceBucket, bucket))
+	if err = updateContainerCPUSet(ctx, d.ctrl, s, c, strings.Join(cpuSetList, ","), d.memoryNodes(c, s, CPUSetFromBucketList(allocatedList))); err != nil {
 		return err
 	}
+	perCpu, weighted := d.namespaceWeights[c.Namespace]
+	if !weighted {
+		perCpu, weighted = cpuWeightPerCpu, d.bucketWeights
+	}
+	if wc, ok := d.ctrl.(WeightController); ok && weighted && !takesExclusiveCpus(c) {
+		wc.SetCPUWeight(s.CGroupPath, c, containerCPUWeight(c, perCpu))
+	}
 
-	if d.exclusive && c.QS == Guaranteed {
-		return d.removeCpusFromCommonPool(s, podMetadata.Namespace, CPUSetFromBucketList(allocatedList))
+	if d.exclusive && takesExclusiveCpus(c) {
+		return d.removeCpusFromCommonPool(ctx, s, c.Namespace, CPUSetFromBucketList(allocatedList))
 	}
 	return nil
 }
 
+// explainAllocation describes how cpus of the container are selected from the bucket of its namespace.
+func (d *NumaPerNamespaceAllocator) explainAllocation(
+	c Container,
+	s *DaemonState,
+	index int,
+	bucket []*numautils.TopologyNode,
+) string {
+	name := "numa-namespace"
+	if d.exclusive {
+		name = "numa-namespace-exclusive"
+	}
+	cpus := CPUSet{}
+	for _, cpu := range bucket {
+		cpus.Add(cpu.Value)
+	}
+	var selection string
+	switch {
+	case takesExclusiveCpus(c) && d.exclusive:
+		selection = countCpus(c.Cpus) + " taken exclusively"
+	case takesExclusiveCpus(c):
+		selection = countCpus(c.Cpus) + " not shared with other guaranteed containers"
+	case d.softPinned(c):
+		selection = fmt.Sprintf(
+			"soft pinned to %s of the bucket least used by other soft pinned containers", countCpus(c.Cpus),
+		)
+	case d.exclusive:
+		selection = "runs on cpus not taken exclusively"
+	default:
+		selection = "runs on all cpus of the bucket"
+	}
+	if hint := s.allocationHint(c.CID); hint.Count() > 0 && takesExclusiveCpus(c) {
+		selection += ", allocation hint " + hint.ToCpuString() + " preferred"
+	}
+	return fmt.Sprintf("%s allocator: namespace %s uses bucket %d (cpus %s), %s",
+		name, c.Namespace, index, cpus.ToCpuString(), selection)
+}
+
 func (d *NumaPerNamespaceAllocator) takeGuaranteedCpusFromBucket(
 	bucket []*numautils.TopologyNode,
 	c Container,
@@ -180,6 +265,25 @@ func (d *NumaPerNamespaceAllocator) takeGuaranteedCpusFromBucket(
 	return cpuIds, nil
 }
 
+// preferHinted reorders bucket cpus, so that hinted cpus come first.
+func preferHinted(bucket []*numautils.TopologyNode, hint CPUSet) []*numautils.TopologyNode {
+	if hint.Count() == 0 {
+		return bucket
+	}
+	ordered := make([]*numautils.TopologyNode, 0, len(bucket))
+	for _, cpu := range bucket {
+		if hint.Contains(cpu.Value) {
+			ordered = append(ordered, cpu)
+		}
+	}
+	for _, cpu := range bucket {
+		if !hint.Contains(cpu.Value) {
+			ordered = append(ordered, cpu)
+		}
+	}
+	return ordered
+}
+
 func (d *NumaPerNamespaceAllocator) takeAllCpusFromBucket(
 	bucket []*numautils.TopologyNode,
 	c Container,
@@ -193,7 +297,47 @@ func (d *NumaPerNamespaceAllocator) takeAllCpusFromBucket(
 	return cpuIds, nil
 }
 
-func (d *NumaPerNamespaceAllocator) freeCpus(c Container, s *DaemonState) error {
+// selectSoftPinnedCpus returns cpus of the soft pinned container: given cpus it keeps, completed up
+// to its request with shared cpus of the bucket used by the smallest number of other soft pinned
+// containers of the namespace.
+func (d *NumaPerNamespaceAllocator) selectSoftPinnedCpus(
+	s *DaemonState,
+	bucket []*numautils.TopologyNode,
+	c Container,
+	keep CPUSet,
+) []int {
+	load := make(map[int]int)
+	for cid, allocatedList := range s.Allocated {
+		other, err := findContainer(s, cid)
+		if err != nil || cid == c.CID || !d.softPinned(other) || other.Namespace != c.Namespace {
+			continue
+		}
+		for cpu := range CPUSetFromBucketList(allocatedList) {
+			load[cpu]++
+		}
+	}
+
+	candidates := make([]int, 0, len(bucket))
+	for _, cpu := range bucket {
+		if (!d.exclusive || cpu.Available()) && !keep.Contains(cpu.Value) {
+			candidates = append(candidates, cpu.Value)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return load[candidates[i]] < load[candidates[j]]
+	})
+
+	cpuIds := keep.Sorted()
+	for _, cpu := range candidates {
+		if len(cpuIds) >= c.Cpus {
+			break
+		}
+		cpuIds = append(cpuIds, cpu)
+	}
+	return cpuIds
+}
+
+func (d *NumaPerNamespaceAllocator) freeCpus(ctx context.Context, c Container, s *DaemonState) error {
 	v, ok := s.Allocated[c.CID]
 	if !ok {
 		return DaemonError{
@@ -203,22 +347,17 @@ func (d *NumaPerNamespaceAllocator) freeCpus(c Container, s *DaemonState) error
 	}
 	delete(s.Allocated, c.CID)
 
-	podMetadata, ok := s.Pods[c.PID]
-	if !ok {
-		return DaemonError{
-			ErrorType:    PodNotFound,
-			ErrorMessage: fmt.Sprintf("cannot retrieve pod %s metadata", c.PID),
-		}
-	}
-
-	namespaceBucket := d.NamespaceToBucket[podMetadata.Namespace]
+	namespaceBucket := d.NamespaceToBucket[c.Namespace]
 	d.BucketToNumContainers[namespaceBucket]--
-	if d.BucketToNumContainers[namespaceBucket] == 0 {
-		if err := d.freeNamespace(podMetadata.Namespace); err != nil {
+	if _, pinned := d.pinnedNamespaces[c.Namespace]; !pinned && d.BucketToNumContainers[namespaceBucket] == 0 {
+		if err := d.freeNamespace(c.Namespace); err != nil {
 			return DaemonError{RuntimeError, err.Error()}
 		}
 	}
 
+	if !takesExclusiveCpus(c) {
+		return nil // shared cpus stay taken by their exclusive owners
+	}
 	for _, cpuBucket := range v {
 		for cpu := cpuBucket.StartCPU; cpu <= cpuBucket.EndCPU; cpu++ {
 			err := s.Topology.Return(cpu)
@@ -230,23 +369,25 @@ func (d *NumaPerNamespaceAllocator) freeCpus(c Container, s *DaemonState) error
 			}
 		}
 	}
-	if d.exclusive && c.QS == Guaranteed {
-		return d.addCpusToCommonPool(s, podMetadata.Namespace, CPUSetFromBucketList(v))
+	if d.exclusive {
+		return d.addCpusToCommonPool(ctx, s, c.Namespace, CPUSetFromBucketList(v))
 	}
 	return nil
 }
 
-func (d *NumaPerNamespaceAllocator) clearCpus(c Container, s *DaemonState) error {
+func (d *NumaPerNamespaceAllocator) clearCpus(ctx context.Context, c Container, s *DaemonState) error {
 	allCpus := s.Topology.Topology.GetLeafs()
 	cpuSet := CPUSet{}
 	for _, leaf := range allCpus {
 		cpuSet.Add(leaf.Value)
 	}
-	return d.ctrl.UpdateCPUSet(
-		s.CGroupPath,
+	return updateContainerCPUSet(
+		ctx,
+		d.ctrl,
+		s,
 		c,
 		cpuSet.ToCpuString(),
-		getMemoryPinningIfEnabledFromCpuSet(d.memoryPinning, &s.Topology, cpuSet),
+		d.memoryNodes(c, s, cpuSet),
 	)
 }
 
@@ -269,83 +410,192 @@ func (d *NumaPerNamespaceAllocator) freeNamespace(namespace string) error {
 	return nil
 }
 
-func (d *NumaPerNamespaceAllocator) removeCpusFromCommonPool(s *DaemonState, namespace string, cpus CPUSet) error {
-	for cid, allocatedList := range s.Allocated {
+// collectOrphans recounts containers of buckets from the state, as orphaned containers are counted
+// without their namespace being known, and frees buckets left empty. In exclusive mode, freed cpus are
+// returned to the common pool of namespaces of their bucket.
+func (d *NumaPerNamespaceAllocator) collectOrphans(ctx context.Context, freed CPUSet, s *DaemonState) error {
+	counts := map[int]int{}
+	for cid := range s.Allocated {
 		c, err := findContainer(s, cid)
 		if err != nil {
-			d.logger.Error(err, "cannot find container")
 			continue
 		}
-		if s.Pods[c.PID].Namespace != namespace || c.QS == Guaranteed {
-			continue
+		if bucket, ok := d.NamespaceToBucket[c.Namespace]; ok {
+			counts[bucket]++
 		}
+	}
+	for bucket := range d.BucketToNumContainers {
+		d.BucketToNumContainers[bucket] = counts[bucket]
+	}
 
-		originalCPUs := CPUSetFromBucketList(allocatedList)
-		newCPUs := originalCPUs.Clone().RemoveAll(cpus)
-		d.logger.Info(
-			"reallocating container",
-			"reason",
-			"remove",
-			"cid",
-			cid,
-			"originalBuckets",
-			originalCPUs,
-			"newBucket",
-			newCPUs,
-		)
-		err = d.ctrl.UpdateCPUSet(
-			s.CGroupPath,
-			c,
-			newCPUs.ToCpuString(),
-			getMemoryPinningIfEnabledFromCpuSet(d.memoryPinning, &s.Topology, newCPUs),
-		)
-		if err != nil {
-			d.logger.Error(err, "could not remove cpus from common pool", "cid", cid)
-			return err
+	namespaces := make([]string, 0, len(d.NamespaceToBucket))
+	for namespace := range d.NamespaceToBucket {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		bucket := d.NamespaceToBucket[namespace]
+		cpus := CPUSet{}
+		for _, leaf := range d.bucketCpus(s, bucket) {
+			if freed.Contains(leaf.Value) {
+				cpus.Add(leaf.Value)
+			}
+		}
+		if d.exclusive && cpus.Count() > 0 {
+			if err := d.addCpusToCommonPool(ctx, s, namespace, cpus); err != nil {
+				return err
+			}
+		}
+		if _, pinned := d.pinnedNamespaces[namespace]; !pinned && d.BucketToNumContainers[bucket] == 0 {
+			if err := d.freeNamespace(namespace); err != nil {
+				return err
+			}
 		}
-		s.Allocated[cid] = newCPUs.ToBucketList()
 	}
 	return nil
 }
 
-func (d *NumaPerNamespaceAllocator) addCpusToCommonPool(s *DaemonState, namespace string, cpus CPUSet) error {
-	for cid, allocatedList := range s.Allocated {
+// commonPoolUpdate is a planned reallocation of a container sharing cpus of its namespace bucket.
+type commonPoolUpdate struct {
+	container Container
+	original  []ctlplaneapi.CPUBucket
+	cpus      CPUSet
+}
+
+// sharedContainers returns allocated non-guaranteed containers of the namespace, ordered by id.
+func (d *NumaPerNamespaceAllocator) sharedContainers(s *DaemonState, namespace string) []Container {
+	containers := make([]Container, 0, len(s.Allocated))
+	for cid := range s.Allocated {
 		c, err := findContainer(s, cid)
 		if err != nil {
 			d.logger.Error(err, "cannot find container")
 			continue
 		}
-		if s.Pods[c.PID].Namespace != namespace || c.QS == Guaranteed {
+		if c.Namespace == namespace && c.QS != Guaranteed {
+			containers = append(containers, c)
+		}
+	}
+	sort.Slice(containers, func(i, j int) bool {
+		return containers[i].CID < containers[j].CID
+	})
+	return containers
+}
+
+func (d *NumaPerNamespaceAllocator) removeCpusFromCommonPool(
+	ctx context.Context,
+	s *DaemonState,
+	namespace string,
+	cpus CPUSet,
+) error {
+	var bucket []*numautils.TopologyNode
+	if d.burstableSoftPinning {
+		var err error
+		if bucket, err = d.getBucket(s, namespace); err != nil {
+			return err
+		}
+	}
+
+	// new cpus are stored in the state while planning, so that soft pinned containers see the load of
+	// containers planned before them
+	containers := d.sharedContainers(s, namespace)
+	updates := make([]commonPoolUpdate, 0, len(containers))
+	for _, c := range containers {
+		original := s.Allocated[c.CID]
+		newCPUs := CPUSetFromBucketList(original).RemoveAll(cpus)
+		if d.softPinned(c) && newCPUs.Count() < c.Cpus {
+			for _, cpu := range d.selectSoftPinnedCpus(s, bucket, c, newCPUs) {
+				newCPUs.Add(cpu)
+			}
+		}
+		s.Allocated[c.CID] = newCPUs.ToBucketList()
+		updates = append(updates, commonPoolUpdate{container: c, original: original, cpus: newCPUs})
+	}
+	return d.applyCommonPoolUpdates(ctx, s, "remove", updates)
+}
+
+func (d *NumaPerNamespaceAllocator) addCpusToCommonPool(
+	ctx context.Context,
+	s *DaemonState,
+	namespace string,
+	cpus CPUSet,
+) error {
+	containers := d.sharedContainers(s, namespace)
+	updates := make([]commonPoolUpdate, 0, len(containers))
+	for _, c := range containers {
+		if d.softPinned(c) {
 			continue
 		}
+		original := s.Allocated[c.CID]
+		newCPUs := CPUSetFromBucketList(original).Merge(cpus)
+		s.Allocated[c.CID] = newCPUs.ToBucketList()
+		updates = append(updates, commonPoolUpdate{container: c, original: original, cpus: newCPUs})
+	}
+	return d.applyCommonPoolUpdates(ctx, s, "add", updates)
+}
 
-		originalCPUs := CPUSetFromBucketList(allocatedList)
-		newCPUs := originalCPUs.Clone().Merge(cpus)
+// applyCommonPoolUpdates writes planned cpus of shared containers, already stored in the state, to
+// their cgroups. The common pool is reallocated all or nothing: if any write fails, all containers are
+// restored to their original cpus.
+func (d *NumaPerNamespaceAllocator) applyCommonPoolUpdates(
+	ctx context.Context,
+	s *DaemonState,
+	reason string,
+	updates []commonPoolUpdate,
+) error {
+	for i, u := range updates {
 		d.logger.Info(
 			"reallocating container",
 			"reason",
-			"add",
+			reason,
 			"cid",
-			cid,
+			u.container.CID,
 			"originalBuckets",
-			originalCPUs,
+			CPUSetFromBucketList(u.original),
 			"newBucket",
-			newCPUs,
+			u.cpus,
 		)
-		err = d.ctrl.UpdateCPUSet(
-			s.CGroupPath,
-			c,
-			newCPUs.ToCpuString(),
-			getMemoryPinningIfEnabledFromCpuSet(d.memoryPinning, &s.Topology, newCPUs),
+		err := updateContainerCPUSet(
+			ctx,
+			d.ctrl,
+			s,
+			u.container,
+			u.cpus.ToCpuString(),
+			d.memoryNodes(u.container, s, u.cpus),
 		)
 		if err != nil {
+			d.logger.Error(err, "could not reallocate common pool, restoring original cpus", "reason", reason, "cid", u.container.CID)
+			d.restoreCommonPool(s, updates, i)
 			return err
 		}
-		s.Allocated[cid] = newCPUs.ToBucketList()
 	}
 	return nil
 }
 
+// restoreCommonPool restores original cpus of all planned containers in the state, and in cgroups of
+// the given number of containers already reallocated. Cgroups are restored even if the request was
+// canceled.
+func (d *NumaPerNamespaceAllocator) restoreCommonPool(s *DaemonState, updates []commonPoolUpdate, applied int) {
+	for i := len(updates) - 1; i >= 0; i-- {
+		u := updates[i]
+		s.Allocated[u.container.CID] = u.original
+		if i >= applied {
+			continue
+		}
+		cpus := CPUSetFromBucketList(u.original)
+		err := updateContainerCPUSet(
+			context.Background(),
+			d.ctrl,
+			s,
+			u.container,
+			cpus.ToCpuString(),
+			d.memoryNodes(u.container, s, cpus),
+		)
+		if err != nil {
+			d.logger.Error(err, "cannot restore cpus of container", "cid", u.container.CID)
+		}
+	}
+}
+
 func findContainer(s *DaemonState, cid string) (Container, error) {
 	for _, podMeta := range s.Pods {
 		for _, container := range podMeta.Containers {
@@ -1,6 +1,8 @@
 package cpudaemon
 
 import (
+	"context"
+	"errors"
 	"os"
 	"strconv"
 	"testing"
@@ -76,11 +78,12 @@ func newMockedNumaPerNamespaceAllocator(numBuckets int, exclusive bool) *NumaPer
 func baseContainer(num int) Container {
 	numStr := strconv.Itoa(num)
 	return Container{
-		CID:  "cid" + numStr,
-		PID:  "pod" + numStr,
-		Name: "cid" + numStr + "_name",
-		Cpus: 1,
-		QS:   Guaranteed,
+		CID:       "cid" + numStr,
+		PID:       "pod" + numStr,
+		Name:      "cid" + numStr + "_name",
+		Cpus:      1,
+		QS:        Guaranteed,
+		Namespace: "pod" + numStr + "_namespace",
 	}
 }
 
@@ -88,6 +91,7 @@ func getGuaranteedAndBurstableContainers() (Container, Container) {
 	guaranteed := baseContainer(1)
 	burstable := baseContainer(2)
 	burstable.PID = "pod1"
+	burstable.Namespace = "pod1_namespace"
 	burstable.QS = Burstable
 	return guaranteed, burstable
 }
@@ -120,8 +124,8 @@ func TestNumaNamespaceTakeCpuWithoutMemoryPinning(t *testing.T) {
 	mock.On("UpdateCPUSet", s.CGroupPath, containerNs1, "0", "").Return(nil)
 	mock.On("UpdateCPUSet", s.CGroupPath, containerNs2, "1", "").Return(nil)
 
-	assert.Nil(t, allocator.takeCpus(containerNs1, s))
-	assert.Nil(t, allocator.takeCpus(containerNs2, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), containerNs1, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), containerNs2, s))
 
 	assertCpuState(t, s, &containerNs1, "0")
 	assertCpuState(t, s, &containerNs2, "1")
@@ -142,8 +146,8 @@ func TestNumaNamespaceTakeCpu(t *testing.T) {
 	mock.On("UpdateCPUSet", s.CGroupPath, containerNs1, "0", "0").Return(nil)
 	mock.On("UpdateCPUSet", s.CGroupPath, containerNs2, "1", "0").Return(nil)
 
-	assert.Nil(t, allocator.takeCpus(containerNs1, s))
-	assert.Nil(t, allocator.takeCpus(containerNs2, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), containerNs1, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), containerNs2, s))
 
 	assertCpuState(t, s, &containerNs1, "0")
 	assertCpuState(t, s, &containerNs2, "1")
@@ -166,9 +170,9 @@ func TestNumaNamespaceOversubscribedTakeCpu(t *testing.T) {
 	mock.On("UpdateCPUSet", s.CGroupPath, containerNs2, "2", "0").Return(nil)
 	mock.On("UpdateCPUSet", s.CGroupPath, containerNs3, "1", "0").Return(nil)
 
-	assert.Nil(t, allocator.takeCpus(containerNs1, s))
-	assert.Nil(t, allocator.takeCpus(containerNs2, s))
-	assert.Nil(t, allocator.takeCpus(containerNs3, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), containerNs1, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), containerNs2, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), containerNs3, s))
 
 	assertCpuState(t, s, &containerNs1, "0")
 	assertCpuState(t, s, &containerNs2, "2")
@@ -192,9 +196,9 @@ func TestNumaNamespaceExclusiveTakeCpu(t *testing.T) {
 	mock.On("UpdateCPUSet", s.CGroupPath, containerBurstable, "1,2,3", "0").Return(nil)
 	mock.On("UpdateCPUSet", s.CGroupPath, containerBurstable2, "1,2,3", "0").Return(nil)
 
-	assert.Nil(t, allocator.takeCpus(containerGuaranteed, s))
-	assert.Nil(t, allocator.takeCpus(containerBurstable, s))
-	assert.Nil(t, allocator.takeCpus(containerBurstable2, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), containerGuaranteed, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), containerBurstable, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), containerBurstable2, s))
 	mock.AssertExpectations(t)
 
 	assertCpuState(t, s, &containerGuaranteed, "0")
@@ -215,13 +219,13 @@ func TestNumaNamespaceExclusiveTakeCpuWithReallocation(t *testing.T) {
 	mock := allocator.ctrl.(*CgroupsMock)
 
 	mock.On("UpdateCPUSet", s.CGroupPath, containerBurstable, "0,1", "0").Return(nil) // 1st allocation of burstable
-	assert.Nil(t, allocator.takeCpus(containerBurstable, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), containerBurstable, s))
 	assertCpuState(t, s, &containerBurstable, "0,1")
 	addContainerToState(s, containerBurstable)
 
 	mock.On("UpdateCPUSet", s.CGroupPath, containerGuaranteed, "0", "0").Return(nil) // allocation of guaranteed
 	mock.On("UpdateCPUSet", s.CGroupPath, containerBurstable, "1", "0").Return(nil)  // reallocation of burstable
-	assert.Nil(t, allocator.takeCpus(containerGuaranteed, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), containerGuaranteed, s))
 	mock.AssertExpectations(t)
 
 	assertCpuState(t, s, &containerBurstable, "1")
@@ -243,7 +247,7 @@ func TestNumaNamespaceTakeCpuNonGuaranteed(t *testing.T) {
 	mock := allocator.ctrl.(*CgroupsMock)
 	mock.On("UpdateCPUSet", s.CGroupPath, container, "0,1", "0").Return(nil)
 
-	assert.Nil(t, allocator.takeCpus(container, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), container, s))
 	mock.AssertExpectations(t)
 
 	assertCpuState(t, s, &container, "0,1")
@@ -263,14 +267,43 @@ func TestNumaNamespaceFreeCpu(t *testing.T) {
 	mock := allocator.ctrl.(*CgroupsMock)
 	mock.On("UpdateCPUSet", s.CGroupPath, container, "0", "0").Return(nil)
 
-	assert.Nil(t, allocator.takeCpus(container, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), container, s))
 	assert.Contains(t, s.Allocated, container.CID)
 
-	assert.Nil(t, allocator.freeCpus(container, s))
+	assert.Nil(t, allocator.freeCpus(context.Background(), container, s))
 	assert.NotContains(t, s.Allocated, container.CID)
 	mock.AssertExpectations(t)
 }
 
+func TestNumaNamespaceFreeCpuAfterPodDeletion(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test_cpu")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	s := getTestDaemonState(dir, 4)
+	allocator := newMockedNumaPerNamespaceAllocator(2, false)
+	first, second := getGuaranteedAndBurstableContainers()
+	second.QS = Guaranteed
+
+	mock := allocator.ctrl.(*CgroupsMock)
+	mock.On("UpdateCPUSet", s.CGroupPath, first, "0", "0").Return(nil)
+	mock.On("UpdateCPUSet", s.CGroupPath, second, "1", "0").Return(nil)
+	require.Nil(t, allocator.takeCpus(context.Background(), first, s))
+	require.Nil(t, allocator.takeCpus(context.Background(), second, s))
+	delete(s.Pods, first.PID)
+
+	assert.Nil(t, allocator.freeCpus(context.Background(), first, s))
+	assert.Nil(t, allocator.freeCpus(context.Background(), second, s))
+
+	assert.Empty(t, s.Allocated)
+	assert.Zero(t, allocator.BucketToNumContainers[0])
+	assert.NotContains(t, allocator.NamespaceToBucket, "pod1_namespace", "bucket of the namespace shall be released")
+	for _, leaf := range s.Topology.Topology.GetLeafs() {
+		assert.True(t, leaf.Available(), "cpu %d shall be returned", leaf.Value)
+	}
+	mock.AssertExpectations(t)
+}
+
 func TestNumaNamespaceExclusiveFreeCpu(t *testing.T) {
 	dir, err := os.MkdirTemp("", "test_cpu")
 	require.Nil(t, err)
@@ -285,19 +318,19 @@ func TestNumaNamespaceExclusiveFreeCpu(t *testing.T) {
 
 	// add guaranteed container for cpu 0
 	mock.On("UpdateCPUSet", s.CGroupPath, containerGuaranteed, "0", "0").Return(nil)
-	assert.Nil(t, allocator.takeCpus(containerGuaranteed, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), containerGuaranteed, s))
 	addContainerToState(s, containerGuaranteed)
 
 	// add burstable container for cpu 1,2,3
 	mock.On("UpdateCPUSet", s.CGroupPath, containerBurstable, "1,2,3", "0").Return(nil)
-	assert.Nil(t, allocator.takeCpus(containerBurstable, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), containerBurstable, s))
 	addContainerToState(s, containerBurstable)
 
 	assert.Contains(t, s.Allocated, containerGuaranteed.CID)
 
 	// remove guaranteed container, the burstable container shall now be reassigned to cpus 0,1,2,3
 	mock.On("UpdateCPUSet", s.CGroupPath, containerBurstable, "0,1,2,3", "0").Return(nil)
-	assert.Nil(t, allocator.freeCpus(containerGuaranteed, s))
+	assert.Nil(t, allocator.freeCpus(context.Background(), containerGuaranteed, s))
 
 	assert.NotContains(t, s.Allocated, containerGuaranteed.CID)
 
@@ -313,7 +346,7 @@ func TestNumaNamespaceTakeCpuFailsIfNotEnoughSpace(t *testing.T) {
 
 	allocator := newMockedNumaPerNamespaceAllocator(2, false)
 
-	assert.Error(t, allocator.takeCpus(Container{
+	assert.Error(t, allocator.takeCpus(context.Background(), Container{
 		CID:  "cid1",
 		PID:  "pod1",
 		Name: "cid1_name",
@@ -333,9 +366,9 @@ func TestNumaNamespaceTakeCpuFailsIfAllBucketsTaken(t *testing.T) {
 	cmock := allocator.ctrl.(*CgroupsMock)
 	cmock.On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-	assert.Nil(t, allocator.takeCpus(baseContainer(1), s))
-	assert.Nil(t, allocator.takeCpus(baseContainer(2), s))
-	assert.Error(t, allocator.takeCpus(baseContainer(3), s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), baseContainer(1), s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), baseContainer(2), s))
+	assert.Error(t, allocator.takeCpus(context.Background(), baseContainer(3), s))
 	cmock.AssertExpectations(t)
 }
 
@@ -354,6 +387,272 @@ func TestNumaNamespaceClearCpu(t *testing.T) {
 	mock := allocator.ctrl.(*CgroupsMock)
 	mock.On("UpdateCPUSet", s.CGroupPath, container, "0,1,2,3", "0").Return(nil)
 
-	assert.Nil(t, allocator.clearCpus(container, s))
+	assert.Nil(t, allocator.clearCpus(context.Background(), container, s))
+	mock.AssertExpectations(t)
+}
+
+func TestNumaNamespaceTakeCpuPrefersHintedCpus(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test_cpu")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	s := getTestDaemonState(dir, 4)
+
+	allocator := newMockedNumaPerNamespaceAllocator(1, false)
+	container := baseContainer(1)
+	s.setAllocationHint(container.CID, CPUSet{3: struct{}{}})
+
+	mock := allocator.ctrl.(*CgroupsMock)
+	mock.On("UpdateCPUSet", s.CGroupPath, container, "3", "0").Return(nil)
+
+	assert.Nil(t, allocator.takeCpus(context.Background(), container, s))
+
+	assertCpuState(t, s, &container, "3")
+	mock.AssertExpectations(t)
+}
+
+func TestNumaNamespaceTakeCpuWithNamespaceMemoryNodes(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test_cpu")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	s := getTestDaemonState(dir, 2)
+
+	allocator := newMockedNumaPerNamespaceAllocator(2, false)
+	allocator.memoryPinning = false
+	allocator.SetNamespaceMemoryNodes(map[string]string{"pod1_namespace": "1"})
+	containerNs1 := baseContainer(1)
+	containerNs2 := baseContainer(2)
+
+	mock := allocator.ctrl.(*CgroupsMock)
+	mock.On("UpdateCPUSet", s.CGroupPath, containerNs1, "0", "1").Return(nil)
+	mock.On("UpdateCPUSet", s.CGroupPath, containerNs2, "1", "").Return(nil)
+
+	assert.Nil(t, allocator.takeCpus(context.Background(), containerNs1, s))
+	assert.Nil(t, allocator.takeCpus(context.Background(), containerNs2, s))
+
+	mock.AssertExpectations(t)
+	assert.Equal(t, "1", s.MemoryNodes[containerNs1.CID])
+}
+
+func TestNumaNamespaceMemoryNodesRespectDisabledPodPinning(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test_cpu")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	s := getTestDaemonState(dir, 2)
+	pod := s.Pods["pod1"]
+	pod.MemoryPinning = ctlplaneapi.MemoryPinning_MEMORY_PINNING_DISABLED
+	s.Pods["pod1"] = pod
+
+	allocator := newMockedNumaPerNamespaceAllocator(2, false)
+	allocator.SetNamespaceMemoryNodes(map[string]string{"pod1_namespace": "1"})
+	container := baseContainer(1)
+
+	mock := allocator.ctrl.(*CgroupsMock)
+	mock.On("UpdateCPUSet", s.CGroupPath, container, "0", "").Return(nil)
+
+	assert.Nil(t, allocator.takeCpus(context.Background(), container, s))
+	mock.AssertExpectations(t)
+}
+
+func TestNumaNamespaceClearCpuWithNamespaceMemoryNodes(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test_cpu")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	s := getTestDaemonState(dir, 4)
+
+	allocator := newMockedNumaPerNamespaceAllocator(1, false)
+	allocator.SetNamespaceMemoryNodes(map[string]string{"pod1_namespace": "0-1"})
+	container := baseContainer(1)
+
+	mock := allocator.ctrl.(*CgroupsMock)
+	mock.On("UpdateCPUSet", s.CGroupPath, container, "0,1,2,3", "0-1").Return(nil)
+
+	assert.Nil(t, allocator.clearCpus(context.Background(), container, s))
+	mock.AssertExpectations(t)
+}
+
+func TestNewDaemonFailsWithUnknownNamespaceMemoryNodes(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	allocator := newMockedNumaPerNamespaceAllocator(2, false)
+	allocator.SetNamespaceMemoryNodes(map[string]string{"team-a": "1", "team-b": "1-2"})
+
+	_, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, NewStaticPolocy(allocator), logr.Discard())
+
+	require.NotNil(t, err)
+	assert.Equal(t, ConfigurationError, err.(DaemonError).ErrorType) //nolint: errorlint
+	assert.Contains(t, err.Error(), "numa node 2")
+
+	allocator.SetNamespaceMemoryNodes(map[string]string{"team-a": "0-1"})
+	_, err = New("testdata/no_state", "testdata/node_info", daemonStateFile, NewStaticPolocy(allocator), logr.Discard())
+	assert.Nil(t, err)
+}
+
+func TestNumaNamespaceSoftPinnedBurstableContainers(t *testing.T) {
+	s := getTestDaemonState(t.TempDir(), 4)
+	allocator := newMockedNumaPerNamespaceAllocator(1, false)
+	allocator.EnableBurstableSoftPinning()
+	_, first := getGuaranteedAndBurstableContainers()
+	first.Cpus = 2
+	second, third := first, first
+	second.CID, third.CID, third.Cpus = "cid3", "cid4", 1
+
+	mock := allocator.ctrl.(*CgroupsMock)
+	mock.On("UpdateCPUSet", s.CGroupPath, first, "0,1", "0").Return(nil)
+	mock.On("UpdateCPUSet", s.CGroupPath, second, "2,3", "0").Return(nil)
+	mock.On("UpdateCPUSet", s.CGroupPath, third, "0", "0").Return(nil)
+
+	for _, c := range []Container{first, second, third} {
+		require.Nil(t, allocator.takeCpus(context.Background(), c, s))
+		addContainerToState(s, c)
+	}
+	mock.AssertExpectations(t)
+
+	assertCpuState(t, s, &first, "0,1")
+	assertCpuState(t, s, &second, "2,3")
+	assertCpuState(t, s, &third, "0")
+}
+
+func TestNumaNamespaceSoftPinnedBurstableKeepsRequestedCpus(t *testing.T) {
+	s := getTestDaemonState(t.TempDir(), 4)
+	allocator := newMockedNumaPerNamespaceAllocator(1, true)
+	allocator.EnableBurstableSoftPinning()
+	guaranteed, burstable := getGuaranteedAndBurstableContainers()
+	burstable.Cpus = 2
+
+	mock := allocator.ctrl.(*CgroupsMock)
+	mock.On("UpdateCPUSet", s.CGroupPath, burstable, "0,1", "0").Return(nil).Once()
+	require.Nil(t, allocator.takeCpus(context.Background(), burstable, s))
+	addContainerToState(s, burstable)
+
+	mock.On("UpdateCPUSet", s.CGroupPath, guaranteed, "0", "0").Return(nil).Once()
+	mock.On("UpdateCPUSet", s.CGroupPath, burstable, "1,2", "0").Return(nil).Once()
+	require.Nil(t, allocator.takeCpus(context.Background(), guaranteed, s))
+	addContainerToState(s, guaranteed)
+	assertCpuState(t, s, &burstable, "1,2")
+
+	require.Nil(t, allocator.freeCpus(context.Background(), guaranteed, s))
 	mock.AssertExpectations(t)
+	assertCpuState(t, s, &burstable, "1,2")
+}
+
+func TestNumaNamespaceBucketWeights(t *testing.T) {
+	s := getTestDaemonState(t.TempDir(), 4)
+	allocator := newMockedNumaPerNamespaceAllocator(1, true)
+	allocator.EnableBucketWeights()
+	m := &WeightCgroupsMock{}
+	allocator.ctrl = m
+	guaranteed, burstable := getGuaranteedAndBurstableContainers()
+	burstable.Cpus = 2
+
+	m.On("UpdateCPUSet", s.CGroupPath, burstable, "0,1,2,3", "0").Return(nil).Once()
+	m.On("SetCPUWeight", s.CGroupPath, burstable, uint64(200)).Once()
+	require.Nil(t, allocator.takeCpus(context.Background(), burstable, s))
+	addContainerToState(s, burstable)
+
+	m.On("UpdateCPUSet", s.CGroupPath, guaranteed, "0", "0").Return(nil).Once()
+	m.On("UpdateCPUSet", s.CGroupPath, burstable, "1,2,3", "0").Return(nil).Once()
+	require.Nil(t, allocator.takeCpus(context.Background(), guaranteed, s))
+
+	m.AssertExpectations(t)
+	m.AssertNumberOfCalls(t, "SetCPUWeight", 1)
+}
+
+func TestNumaNamespaceBucketWeightsDisabled(t *testing.T) {
+	s := getTestDaemonState(t.TempDir(), 4)
+	allocator := newMockedNumaPerNamespaceAllocator(1, true)
+	m := &WeightCgroupsMock{}
+	allocator.ctrl = m
+	_, burstable := getGuaranteedAndBurstableContainers()
+
+	m.On("UpdateCPUSet", s.CGroupPath, burstable, "0,1,2,3", "0").Return(nil).Once()
+	require.Nil(t, allocator.takeCpus(context.Background(), burstable, s))
+
+	m.AssertNotCalled(t, "SetCPUWeight", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// addSharedContainers adds burstable containers of pod1 namespace with given cpus to the state.
+func addSharedContainers(s *DaemonState, n int, cpus string) []Container {
+	cpuSet, err := CPUSetFromString(cpus)
+	if err != nil {
+		panic(err)
+	}
+	containers := make([]Container, 0, n)
+	for i := 0; i < n; i++ {
+		c := baseContainer(i + 1)
+		c.PID = "pod1"
+		c.Namespace = "pod1_namespace"
+		c.QS = Burstable
+		addContainerToState(s, c)
+		s.Allocated[c.CID] = cpuSet.ToBucketList()
+		containers = append(containers, c)
+	}
+	return containers
+}
+
+// testCommonPoolRollback fails reallocation of each of the shared containers in turn and checks that
+// containers reallocated before are restored to their original cpus.
+func testCommonPoolRollback(
+	t *testing.T,
+	original string,
+	reallocated string,
+	reallocate func(*NumaPerNamespaceAllocator, *DaemonState) error,
+) {
+	t.Helper()
+	errWrite := errors.New("cgroup write failed")
+	for fail := 0; fail < 3; fail++ {
+		s := getTestDaemonState(t.TempDir(), 8)
+		allocator := newMockedNumaPerNamespaceAllocator(1, true)
+		containers := addSharedContainers(s, 3, original)
+
+		mock := allocator.ctrl.(*CgroupsMock)
+		for _, c := range containers[:fail] {
+			mock.On("UpdateCPUSet", s.CGroupPath, c, reallocated, "0").Return(nil).Once()
+			mock.On("UpdateCPUSet", s.CGroupPath, c, original, "0").Return(nil).Once()
+		}
+		mock.On("UpdateCPUSet", s.CGroupPath, containers[fail], reallocated, "0").Return(errWrite).Once()
+
+		assert.ErrorIs(t, reallocate(allocator, s), errWrite)
+
+		mock.AssertExpectations(t)
+		mock.AssertNumberOfCalls(t, "UpdateCPUSet", 2*fail+1)
+		for i := range containers {
+			assertCpuState(t, s, &containers[i], original)
+		}
+	}
+}
+
+func TestNumaNamespaceRemoveCpusFromCommonPoolRollsBack(t *testing.T) {
+	testCommonPoolRollback(t, "0,1,2,3,4,5,6,7", "1,2,3,4,5,6,7", func(a *NumaPerNamespaceAllocator, s *DaemonState) error {
+		return a.removeCpusFromCommonPool(context.Background(), s, "pod1_namespace", CPUSet{0: {}})
+	})
+}
+
+func TestNumaNamespaceAddCpusToCommonPoolRollsBack(t *testing.T) {
+	testCommonPoolRollback(t, "1,2,3,4,5,6,7", "0,1,2,3,4,5,6,7", func(a *NumaPerNamespaceAllocator, s *DaemonState) error {
+		return a.addCpusToCommonPool(context.Background(), s, "pod1_namespace", CPUSet{0: {}})
+	})
+}
+
+func TestNumaNamespaceCommonPoolReallocatedInContainerOrder(t *testing.T) {
+	s := getTestDaemonState(t.TempDir(), 8)
+	allocator := newMockedNumaPerNamespaceAllocator(1, true)
+	containers := addSharedContainers(s, 3, "1,2,3")
+
+	mock := allocator.ctrl.(*CgroupsMock)
+	for _, c := range containers {
+		mock.On("UpdateCPUSet", s.CGroupPath, c, "0,1,2,3", "0").Return(nil).Once()
+	}
+	require.Nil(t, allocator.addCpusToCommonPool(context.Background(), s, "pod1_namespace", CPUSet{0: {}}))
+
+	mock.AssertExpectations(t)
+	for i, call := range mock.Calls {
+		assert.Equal(t, containers[i], call.Arguments.Get(1))
+	}
+	for i := range containers {
+		assertCpuState(t, s, &containers[i], "0,1,2,3")
+	}
 }
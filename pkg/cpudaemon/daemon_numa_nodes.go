@@ -0,0 +1,65 @@
+package cpudaemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// NumaNodesPolicy is implemented by policies configured with numa nodes, eg. memory nodes of namespaces,
+// which shall be present on the node.
+type NumaNodesPolicy interface {
+	NumaNodes() CPUSet
+}
+
+var _ NumaNodesPolicy = &StaticPolicy{}
+
+// numaNodesAllocator is implemented by allocators configured with numa nodes.
+type numaNodesAllocator interface {
+	numaNodes() CPUSet
+}
+
+var _ numaNodesAllocator = &NumaPerNamespaceAllocator{}
+
+// NumaNodes returns numa nodes configured for the allocator of the policy, empty if none.
+func (p *StaticPolicy) NumaNodes() CPUSet {
+	if a, ok := p.allocator.(numaNodesAllocator); ok {
+		return a.numaNodes()
+	}
+	return CPUSet{}
+}
+
+// numaNodes returns memory nodes of all namespaces.
+func (d *NumaPerNamespaceAllocator) numaNodes() CPUSet {
+	nodes := CPUSet{}
+	for _, mems := range d.namespaceMemoryNodes {
+		if n, err := CPUSetFromString(mems); err == nil {
+			nodes.Merge(n)
+		}
+	}
+	return nodes
+}
+
+// checkNumaNodes fails with configuration error if numa nodes configured for the default policy or policies
+// of tiers are not present in the topology, including memory-only nodes.
+func (d *Daemon) checkNumaNodes() error {
+	policies := []Policy{d.policy}
+	for _, p := range d.tierPolicies {
+		policies = append(policies, p)
+	}
+	for _, p := range policies {
+		p, ok := p.(NumaNodesPolicy)
+		if !ok {
+			continue
+		}
+		for _, node := range p.NumaNodes().Sorted() {
+			if _, err := os.Stat(filepath.Join(d.numaPath, fmt.Sprintf("node%d", node))); err != nil {
+				return DaemonError{
+					ErrorType:    ConfigurationError,
+					ErrorMessage: fmt.Sprintf("numa node %d is not present on the node", node),
+				}
+			}
+		}
+	}
+	return nil
+}
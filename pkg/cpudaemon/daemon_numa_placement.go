@@ -0,0 +1,221 @@
+package cpudaemon
+
+import (
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/numautils"
+)
+
+// SpreadGroupAnnotation assigns the pod to a spread group (eg. "frontend"). The numa allocator places
+// guaranteed containers of pods of the same group and namespace on different nodes whenever possible, so
+// that replicas of a workload do not compete for memory bandwidth of one node.
+const SpreadGroupAnnotation = "ctlplane.intel.com/spread-group"
+
+// podPlacementWeight makes pod locality dominate other scorers in pipelines of pod placements, on
+// nodes with up to 1024 cpus.
+const podPlacementWeight = 1024
+
+// NodeFilter rejects topology nodes which cannot host the container.
+type NodeFilter interface {
+	Filter(c Container, s *DaemonState, node *numautils.TopologyNode) bool
+}
+
+// NodeScorer rates topology nodes which passed all filters. Higher score means better placement.
+type NodeScorer interface {
+	Score(c Container, s *DaemonState, node *numautils.TopologyNode) int
+}
+
+// WeightedScorer multiplies score of the scorer by its weight.
+type WeightedScorer struct {
+	Scorer NodeScorer
+	Weight int
+}
+
+// PlacementPipeline selects the first level topology node (eg. numa node or socket) for the container.
+// All nodes are filtered, then remaining ones are scored and the node with the highest score sum
+// wins; ties are resolved in topology order. Cpus are then taken from the selected node, so that the
+// topology distance between them is minimal.
+type PlacementPipeline struct {
+	Filters []NodeFilter
+	Scorers []WeightedScorer
+}
+
+// DefaultPlacementPipeline returns pipeline which places container in the first node where the
+// topology distance between its cpus is minimal.
+func DefaultPlacementPipeline() PlacementPipeline {
+	return PlacementPipeline{
+		Filters: []NodeFilter{EnoughCpusFilter{}},
+		Scorers: []WeightedScorer{{Scorer: DistanceScorer{}, Weight: 1}},
+	}
+}
+
+// CompactPlacementPipeline returns pipeline used for pods with COMPACT placement, which places
+// containers of the pod on the same node whenever possible.
+func CompactPlacementPipeline() PlacementPipeline {
+	return PlacementPipeline{
+		Filters: []NodeFilter{EnoughCpusFilter{}},
+		Scorers: []WeightedScorer{
+			{Scorer: PodLocalityScorer{}, Weight: podPlacementWeight},
+			{Scorer: DistanceScorer{}, Weight: 1},
+		},
+	}
+}
+
+// ScatterPlacementPipeline returns pipeline used for pods with SCATTER placement, which places
+// containers of the pod on different nodes whenever possible.
+func ScatterPlacementPipeline() PlacementPipeline {
+	return PlacementPipeline{
+		Filters: []NodeFilter{EnoughCpusFilter{}},
+		Scorers: []WeightedScorer{
+			{Scorer: PodLocalityScorer{}, Weight: -podPlacementWeight},
+			{Scorer: FreeCpusScorer{}, Weight: 1},
+		},
+	}
+}
+
+// podPlacementPipeline returns pipeline of the pod placement, or the given default pipeline if the
+// pod does not request any specific placement.
+func podPlacementPipeline(placement ctlplaneapi.Placement, defaultPipeline PlacementPipeline) PlacementPipeline {
+	switch placement {
+	case ctlplaneapi.Placement_COMPACT:
+		return CompactPlacementPipeline()
+	case ctlplaneapi.Placement_SCATTER:
+		return ScatterPlacementPipeline()
+	default:
+		return defaultPipeline
+	}
+}
+
+// withSpreadGroup returns copy of the pipeline which also spreads pods of the same spread group across
+// nodes, with priority over other scorers.
+func (p PlacementPipeline) withSpreadGroup() PlacementPipeline {
+	scorers := make([]WeightedScorer, 0, len(p.Scorers)+1)
+	scorers = append(scorers, WeightedScorer{Scorer: SpreadGroupScorer{}, Weight: -podPlacementWeight})
+	return PlacementPipeline{
+		Filters: p.Filters,
+		Scorers: append(scorers, p.Scorers...),
+	}
+}
+
+// selectNode returns the best node for the container or nil if no node passed the filters.
+func (p PlacementPipeline) selectNode(c Container, s *DaemonState) *numautils.TopologyNode {
+	var (
+		best      *numautils.TopologyNode
+		bestScore int
+	)
+	for _, node := range s.Topology.Topology.Children {
+		if !p.filter(c, s, node) {
+			continue
+		}
+		score := p.score(c, s, node)
+		if best == nil || score > bestScore {
+			best, bestScore = node, score
+		}
+	}
+	return best
+}
+
+func (p PlacementPipeline) filter(c Container, s *DaemonState, node *numautils.TopologyNode) bool {
+	for _, f := range p.Filters {
+		if !f.Filter(c, s, node) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p PlacementPipeline) score(c Container, s *DaemonState, node *numautils.TopologyNode) int {
+	score := 0
+	for _, ws := range p.Scorers {
+		score += ws.Weight * ws.Scorer.Score(c, s, node)
+	}
+	return score
+}
+
+// EnoughCpusFilter accepts nodes with enough available cpus to host the whole container.
+type EnoughCpusFilter struct{}
+
+// Filter implements NodeFilter.
+func (EnoughCpusFilter) Filter(c Container, _ *DaemonState, node *numautils.TopologyNode) bool {
+	return node.NumAvailable >= c.Cpus
+}
+
+// DistanceScorer prefers nodes where the topology distance between allocated cpus is minimal.
+type DistanceScorer struct{}
+
+// Score implements NodeScorer.
+func (DistanceScorer) Score(c Container, _ *DaemonState, node *numautils.TopologyNode) int {
+	return node.AvailabilityDepth(c.Cpus)
+}
+
+// FreeCpusScorer prefers nodes with more available cpus, spreading containers across nodes. Use
+// negative weight to pack containers instead.
+type FreeCpusScorer struct{}
+
+// Score implements NodeScorer.
+func (FreeCpusScorer) Score(_ Container, _ *DaemonState, node *numautils.TopologyNode) int {
+	return node.NumAvailable
+}
+
+// PodLocalityScorer prefers nodes hosting cpus of other containers of the same pod.
+type PodLocalityScorer struct{}
+
+// Score implements NodeScorer.
+func (PodLocalityScorer) Score(c Container, s *DaemonState, node *numautils.TopologyNode) int {
+	nodeCpus := nodeCpuSet(node)
+	score := 0
+	for _, other := range s.Pods[c.PID].Containers {
+		if other.CID == c.CID {
+			continue
+		}
+		for cpu := range CPUSetFromBucketList(s.Allocated[other.CID]) {
+			if nodeCpus.Contains(cpu) {
+				score++
+			}
+		}
+	}
+	return score
+}
+
+// SpreadGroupScorer scores nodes by the number of other pods of the same spread group and namespace with
+// cpus allocated on the node. Use negative weight to spread pods of the group. Pods without spread group
+// get the same score on all nodes.
+type SpreadGroupScorer struct{}
+
+// Score implements NodeScorer.
+func (SpreadGroupScorer) Score(c Container, s *DaemonState, node *numautils.TopologyNode) int {
+	pod := s.Pods[c.PID]
+	group := pod.Annotations[SpreadGroupAnnotation]
+	if group == "" {
+		return 0
+	}
+	nodeCpus := nodeCpuSet(node)
+	score := 0
+	for pid, other := range s.Pods {
+		if pid == c.PID || other.Namespace != pod.Namespace || other.Annotations[SpreadGroupAnnotation] != group {
+			continue
+		}
+		if podHasCpusIn(other, s, nodeCpus) {
+			score++
+		}
+	}
+	return score
+}
+
+func podHasCpusIn(pod PodMetadata, s *DaemonState, cpus CPUSet) bool {
+	for _, c := range pod.Containers {
+		for cpu := range CPUSetFromBucketList(s.Allocated[c.CID]) {
+			if cpus.Contains(cpu) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func nodeCpuSet(node *numautils.TopologyNode) CPUSet {
+	cpus := CPUSet{}
+	for _, leaf := range node.GetLeafs() {
+		cpus.Add(leaf.Value)
+	}
+	return cpus
+}
@@ -0,0 +1,190 @@
+package cpudaemon
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/numautils"
+	"resourcemanagement.controlplane/pkg/numautils/testtopo"
+)
+
+// twoNodesTopology returns topology with cpus 0-3 on node 0 and cpus 4-7 on node 1.
+func twoNodesTopology() numautils.NumaTopology {
+	topology, err := testtopo.Spec{Sockets: 2, DiesPerSocket: 1, CoresPerDie: 4, ThreadsPerCore: 1}.Topology()
+	if err != nil {
+		panic(err)
+	}
+	return topology
+}
+
+func getPlacementTestState(t *testing.T) *DaemonState {
+	s := getTestDaemonState(t.TempDir(), 8)
+	s.Topology = twoNodesTopology()
+	return s
+}
+
+func TestDefaultPlacementSkipsNodesWithoutEnoughCpus(t *testing.T) {
+	s := getPlacementTestState(t)
+	require.Nil(t, s.Topology.TakeCpu(0))
+	c := baseContainer(1)
+	c.Cpus = 4
+
+	node := DefaultPlacementPipeline().selectNode(c, s)
+
+	require.NotNil(t, node)
+	assert.Equal(t, 1, node.Value)
+}
+
+func TestDefaultPlacementPrefersFirstNode(t *testing.T) {
+	s := getPlacementTestState(t)
+	require.Nil(t, s.Topology.TakeCpu(0))
+
+	node := DefaultPlacementPipeline().selectNode(baseContainer(1), s)
+
+	require.NotNil(t, node)
+	assert.Equal(t, 0, node.Value)
+}
+
+func TestPlacementReturnsNilIfNoNodeFits(t *testing.T) {
+	s := getPlacementTestState(t)
+	c := baseContainer(1)
+	c.Cpus = 5
+
+	assert.Nil(t, DefaultPlacementPipeline().selectNode(c, s))
+}
+
+func TestFreeCpusScorerSpreadsContainers(t *testing.T) {
+	s := getPlacementTestState(t)
+	require.Nil(t, s.Topology.TakeCpu(0))
+	pipeline := PlacementPipeline{
+		Filters: []NodeFilter{EnoughCpusFilter{}},
+		Scorers: []WeightedScorer{{Scorer: FreeCpusScorer{}, Weight: 1}},
+	}
+
+	node := pipeline.selectNode(baseContainer(1), s)
+	require.NotNil(t, node)
+	assert.Equal(t, 1, node.Value)
+
+	pipeline.Scorers[0].Weight = -1 // pack instead
+	node = pipeline.selectNode(baseContainer(1), s)
+	require.NotNil(t, node)
+	assert.Equal(t, 0, node.Value)
+}
+
+func TestPodLocalityScorerPrefersNodesOfSamePod(t *testing.T) {
+	s := getPlacementTestState(t)
+	first, second := baseContainer(1), baseContainer(2)
+	second.PID = first.PID
+	addContainerToState(s, first)
+	addContainerToState(s, second)
+	require.Nil(t, s.Topology.TakeCpu(5))
+	s.Allocated[first.CID] = []ctlplaneapi.CPUBucket{{StartCPU: 5, EndCPU: 5}}
+
+	pipeline := PlacementPipeline{
+		Filters: []NodeFilter{EnoughCpusFilter{}},
+		Scorers: []WeightedScorer{
+			{Scorer: DistanceScorer{}, Weight: 1},
+			{Scorer: PodLocalityScorer{}, Weight: 10},
+		},
+	}
+	node := pipeline.selectNode(second, s)
+
+	require.NotNil(t, node)
+	assert.Equal(t, 1, node.Value)
+}
+
+func TestNumaTakeCpuUsesPlacementPipeline(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test_cpu")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	s := getTestDaemonState(dir, 8)
+	s.Topology = twoNodesTopology()
+	require.Nil(t, s.Topology.TakeCpu(0))
+
+	cgroupMock := CgroupsMock{}
+	allocator := NewNumaAwareAllocatorWithPlacement(&cgroupMock, true, PlacementPipeline{
+		Scorers: []WeightedScorer{{Scorer: FreeCpusScorer{}, Weight: 1}},
+	})
+	container := baseContainer(1)
+	container.Cpus = 2
+	cgroupMock.On("UpdateCPUSet", s.CGroupPath, container, "4,5", "1").Return(nil)
+
+	assert.Nil(t, allocator.takeCpus(context.Background(), container, s))
+	assertCpuState(t, s, &container, "4,5")
+	cgroupMock.AssertExpectations(t)
+}
+
+func TestNumaTakeCpuSpansNodesIfNoNodeFits(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test_cpu")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	s := getTestDaemonState(dir, 8)
+	s.Topology = twoNodesTopology()
+
+	cgroupMock := CgroupsMock{}
+	allocator := NewNumaAwareAllocator(&cgroupMock, false)
+	container := baseContainer(1)
+	container.Cpus = 6
+	cgroupMock.On("UpdateCPUSet", s.CGroupPath, container, "0,1,2,3,4,5", "").Return(nil)
+
+	assert.Nil(t, allocator.takeCpus(context.Background(), container, s))
+	assertCpuState(t, s, &container, "0-5")
+	cgroupMock.AssertExpectations(t)
+}
+
+func addSpreadGroupPodForTest(s *DaemonState, c Container, namespace, group string) {
+	addContainerToState(s, c)
+	pod := s.Pods[c.PID]
+	pod.Namespace = namespace
+	pod.Annotations = map[string]string{SpreadGroupAnnotation: group}
+	s.Pods[c.PID] = pod
+}
+
+func TestSpreadGroupScorerCountsPodsOfTheGroup(t *testing.T) {
+	s := getPlacementTestState(t)
+	member, otherGroup, otherNamespace, placed := baseContainer(1), baseContainer(2), baseContainer(3), baseContainer(4)
+	addSpreadGroupPodForTest(s, member, "default", "frontend")
+	addSpreadGroupPodForTest(s, otherGroup, "default", "backend")
+	addSpreadGroupPodForTest(s, otherNamespace, "test", "frontend")
+	addSpreadGroupPodForTest(s, placed, "default", "frontend")
+	s.Allocated[member.CID] = []ctlplaneapi.CPUBucket{{StartCPU: 0, EndCPU: 1}}
+	s.Allocated[otherGroup.CID] = []ctlplaneapi.CPUBucket{{StartCPU: 4, EndCPU: 4}}
+	s.Allocated[otherNamespace.CID] = []ctlplaneapi.CPUBucket{{StartCPU: 5, EndCPU: 5}}
+
+	scorer := SpreadGroupScorer{}
+
+	assert.Equal(t, 1, scorer.Score(placed, s, s.Topology.Topology.Children[0]))
+	assert.Equal(t, 0, scorer.Score(placed, s, s.Topology.Topology.Children[1]))
+	assert.Equal(t, 0, scorer.Score(baseContainer(5), s, s.Topology.Topology.Children[0]))
+}
+
+func TestNumaTakeCpuSpreadsPodsOfSpreadGroup(t *testing.T) {
+	s := getPlacementTestState(t)
+	first, second := baseContainer(1), baseContainer(2)
+	addSpreadGroupPodForTest(s, first, "default", "frontend")
+	addSpreadGroupPodForTest(s, second, "default", "frontend")
+
+	cgroupMock := CgroupsMock{}
+	allocator := NewNumaAwareAllocator(&cgroupMock, false)
+	cgroupMock.On("UpdateCPUSet", s.CGroupPath, first, "0", "").Return(nil)
+	cgroupMock.On("UpdateCPUSet", s.CGroupPath, second, "4", "").Return(nil)
+
+	require.Nil(t, allocator.takeCpus(context.Background(), first, s))
+	require.Nil(t, allocator.takeCpus(context.Background(), second, s))
+	assertCpuState(t, s, &second, "4")
+	cgroupMock.AssertExpectations(t)
+}
+
+func TestSpreadGroupKeepsPipeline(t *testing.T) {
+	pipeline := DefaultPlacementPipeline()
+
+	spread := pipeline.withSpreadGroup()
+
+	assert.Len(t, pipeline.Scorers, 1)
+	assert.Len(t, spread.Scorers, 2)
+	assert.Equal(t, WeightedScorer{Scorer: SpreadGroupScorer{}, Weight: -podPlacementWeight}, spread.Scorers[0])
+}
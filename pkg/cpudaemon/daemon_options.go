@@ -0,0 +1,407 @@
+package cpudaemon
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+
+	"resourcemanagement.controlplane/pkg/events"
+)
+
+const (
+	maxExclusiveCpusCap = 100
+	defaultTombstoneTTL = 5 * time.Minute
+)
+
+// ValidationFailureAction defines how the daemon handles pod requests failing validation.
+type ValidationFailureAction int
+
+const (
+	// ValidationFailureReject makes the daemon return an error for invalid pod requests.
+	ValidationFailureReject ValidationFailureAction = iota
+	// ValidationFailureBestEffort makes the daemon record pods with invalid requests as unmanaged; their
+	// containers are not pinned and run in the shared pool.
+	ValidationFailureBestEffort
+)
+
+// PartialAllocationAction defines how the daemon handles pods whose containers do not all fit.
+type PartialAllocationAction int
+
+const (
+	// PartialAllocationReject makes the daemon reject the pod if any of its containers does not fit.
+	PartialAllocationReject PartialAllocationAction = iota
+	// PartialAllocationSharedPool makes the daemon admit pods listing critical containers with
+	// CriticalContainersAnnotation if the critical containers fit; other guaranteed containers which do
+	// not fit run in the shared pool.
+	PartialAllocationSharedPool
+)
+
+// Option configures optional behaviour of the daemon.
+type Option func(*daemonOptions)
+
+type daemonOptions struct {
+	excludedCPUs            CPUSet
+	excludedNodes           []int
+	memoryPinningNamespaces map[string]struct{}
+	exclusiveCpusCap        int    // percent of managed cpus which can be exclusively allocated
+	managedCPUs             CPUSet // if not nil, only these cpus are managed by the daemon
+	tombstoneTTL            time.Duration
+	kubeletStatePath        string // path to kubelet cpu manager state, empty disables detection
+	kubeletCoexistence      KubeletCoexistence
+	validationFailureAction ValidationFailureAction
+	partialAllocationAction PartialAllocationAction
+	isolatedCpusPath        string // if set, exclusively allocated cpus are written to this file
+	irqbalanceReload        bool   // signal irqbalance when isolated cpus change
+	procPath                string
+	signal                  func(pid int, sig syscall.Signal) error
+	cgroupWriteCheck        bool        // verify at startup that cgroups can be modified
+	sharedPoolCgroups       bool        // restrict besteffort and burstable parent cgroups to the shared pool
+	kubepodsReservedCPUs    CPUSet      // if not nil, kubepods cgroup cpuset is managed and excludes these cpus
+	reservedCPUs            CPUSet      // cpus reserved for system and kubelet in addition to detected ones
+	eventSink               events.Sink // if set, allocation events are published to the sink
+	profiles                map[string]Profile
+	stateSaveDelay          time.Duration // if positive, changes are journaled and the state file is written with delay
+	stateEncoding           StateEncoding
+	cgroupVersion           CgroupVersion // cgroup version used by the daemon, detected at startup by default
+	cgroupRetryDelay        time.Duration // delay of re-apply of cpusets of containers whose cgroups did not exist yet
+	cgroupRetryAttempts     int
+	podCgroupPinning        bool    // set cpuset of pod cgroups to cpus allocated to their containers
+	nodeName                string  // if set, pod requests for other nodes are rejected
+	cpuBorrowing            bool    // lend idle exclusive cpus to throttled burstable containers
+	borrowingThrottling     float64 // fraction of throttled periods above which a container is throttled
+	borrowingChecks         int     // consecutive checks after which cpus are borrowed or returned
+	allocationTracePath     string  // if set, inputs of allocation decisions are appended to this file
+	policyTiers             []PolicyTier
+	namespaceAllocators     map[string]Allocator
+	pressureSource          PressureSource // pressure checked before exclusive allocations shrinking the shared pool
+	pressureThreshold       float64        // pressure above which such allocations are refused
+	pressureMinSharedCpus   int            // shared pool size below which pressure is checked
+}
+
+func newDaemonOptions(opts []Option) daemonOptions {
+	o := daemonOptions{
+		excludedCPUs:            CPUSet{},
+		memoryPinningNamespaces: make(map[string]struct{}),
+		exclusiveCpusCap:        maxExclusiveCpusCap,
+		tombstoneTTL:            defaultTombstoneTTL,
+		procPath:                defaultProcPath,
+		signal:                  syscall.Kill,
+		cgroupVersion:           DetectCgroupVersion(),
+		cgroupRetryDelay:        defaultCgroupRetryDelay,
+		cgroupRetryAttempts:     defaultCgroupRetryAttempts,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithExcludedCpus removes given cpus from the management of the daemon. Excluded cpus are never
+// allocated to any container.
+func WithExcludedCpus(cpus CPUSet) Option {
+	return func(o *daemonOptions) {
+		o.excludedCPUs.Merge(cpus)
+	}
+}
+
+// WithExcludedNumaNodes removes all cpus of given numa nodes from the management of the daemon.
+func WithExcludedNumaNodes(nodes []int) Option {
+	return func(o *daemonOptions) {
+		o.excludedNodes = append(o.excludedNodes, nodes...)
+	}
+}
+
+// WithMemoryPinningNamespaces enables memory pinning for all pods in given namespaces, unless the pod
+// explicitly disables it.
+func WithMemoryPinningNamespaces(namespaces []string) Option {
+	return func(o *daemonOptions) {
+		for _, namespace := range namespaces {
+			o.memoryPinningNamespaces[namespace] = struct{}{}
+		}
+	}
+}
+
+// WithExclusiveCpusCap limits percent of managed cpus which can be exclusively allocated to
+// guaranteed containers. Remaining cpus are left as headroom for system daemons and shared pool.
+func WithExclusiveCpusCap(percent int) Option {
+	return func(o *daemonOptions) {
+		o.exclusiveCpusCap = percent
+	}
+}
+
+// WithManagedCpus restricts the daemon to given cpus; all other cpus are left to other daemon
+// instances. Instances started with managed cpus and state files in the same directory detect
+// overlapping cpu ranges and refuse to start.
+func WithManagedCpus(cpus CPUSet) Option {
+	return func(o *daemonOptions) {
+		o.managedCPUs = cpus.Clone()
+	}
+}
+
+// WithTombstoneTTL sets how long ids of deleted pods are remembered, so that late updates of these
+// pods are rejected instead of recreating their state. Zero disables tombstones.
+func WithTombstoneTTL(ttl time.Duration) Option {
+	return func(o *daemonOptions) {
+		o.tombstoneTTL = ttl
+	}
+}
+
+// WithKubeletCPUManager enables detection of kubelet cpu manager static policy using kubelet state
+// file at given path. Depending on the mode, the daemon refuses to start when static policy is enabled,
+// or avoids cpus exclusively assigned by kubelet.
+func WithKubeletCPUManager(statePath string, mode KubeletCoexistence) Option {
+	return func(o *daemonOptions) {
+		o.kubeletStatePath = statePath
+		o.kubeletCoexistence = mode
+	}
+}
+
+// WithValidationFailureAction sets how pod requests failing validation are handled: either rejected
+// with an error, or recorded as unmanaged pods running in the shared pool.
+func WithValidationFailureAction(action ValidationFailureAction) Option {
+	return func(o *daemonOptions) {
+		o.validationFailureAction = action
+	}
+}
+
+// WithPartialAllocationAction sets how pods whose containers do not all fit are handled: either rejected
+// with an error, or admitted with non-critical containers running in the shared pool.
+func WithPartialAllocationAction(action PartialAllocationAction) Option {
+	return func(o *daemonOptions) {
+		o.partialAllocationAction = action
+	}
+}
+
+// WithIsolatedCpusExport writes exclusively allocated cpus to the file at given path whenever they
+// change: as cpu list (eg. for tuned profiles) and, with ".irqbalance" suffix, as irqbalance environment
+// file with IRQBALANCE_BANNED_CPUS. If reloadIrqbalance is set, irqbalance is sent SIGHUP afterwards.
+func WithIsolatedCpusExport(path string, reloadIrqbalance bool) Option {
+	return func(o *daemonOptions) {
+		o.isolatedCpusPath = path
+		o.irqbalanceReload = reloadIrqbalance
+	}
+}
+
+// WithCgroupWriteCheck makes the daemon verify at startup that it can modify cgroups, so that
+// read-only cgroup mounts and missing privileges fail the startup with a remediation message.
+func WithCgroupWriteCheck() Option {
+	return func(o *daemonOptions) {
+		o.cgroupWriteCheck = true
+	}
+}
+
+// WithSharedPoolCgroups restricts cpusets of besteffort and burstable parent cgroups to managed cpus
+// not exclusively allocated, whenever allocations change. Supported only with cgroups v2.
+func WithSharedPoolCgroups() Option {
+	return func(o *daemonOptions) {
+		o.sharedPoolCgroups = true
+	}
+}
+
+// WithPodCgroupPinning sets cpuset of pod cgroups to union of cpus allocated to their containers,
+// whenever allocations change. Supported only with cgroups v2.
+func WithPodCgroupPinning() Option {
+	return func(o *daemonOptions) {
+		o.podCgroupPinning = true
+	}
+}
+
+// WithKubepodsCpuset makes the daemon set cpuset of the kubepods cgroup to managed cpus without given
+// reserved cpus at startup, so that no pod can run on reserved cpus. Reserved cpus are then taken from
+// the option instead of being detected from the kubepods cgroup, so it cannot be combined with
+// WithReservedCpus.
+func WithKubepodsCpuset(reserved CPUSet) Option {
+	return func(o *daemonOptions) {
+		o.kubepodsReservedCPUs = reserved.Clone()
+	}
+}
+
+// WithReservedCpus reserves given cpus for system and kubelet daemons (eg. kubelet --reserved-cpus), so
+// that they are never allocated to containers, nor included in the shared pool of best-effort and burstable
+// containers. They are reserved in addition to cpus detected outside of the kubepods cgroup, whose cpuset
+// is left to kubelet; use WithKubepodsCpuset instead to have the daemon narrow the kubepods cgroup.
+func WithReservedCpus(reserved CPUSet) Option {
+	return func(o *daemonOptions) {
+		o.reservedCPUs = reserved.Clone()
+	}
+}
+
+// WithEventSink publishes allocated and freed events of containers to the sink whenever allocations
+// change. All current allocations are published at startup.
+func WithEventSink(sink events.Sink) Option {
+	return func(o *daemonOptions) {
+		o.eventSink = sink
+	}
+}
+
+// WithProfiles defines allocation profiles which pods select by name with ProfileAnnotation.
+func WithProfiles(profiles map[string]Profile) Option {
+	return func(o *daemonOptions) {
+		o.profiles = profiles
+	}
+}
+
+// WithPolicyTiers routes pods of namespaces assigned to the tiers to the policies of the tiers, see
+// PolicyTier.
+func WithPolicyTiers(tiers []PolicyTier) Option {
+	return func(o *daemonOptions) {
+		o.policyTiers = tiers
+	}
+}
+
+// WithNamespaceAllocators makes given allocators selectable by configurations of namespaces, see
+// NamespaceConfig. Allocators shall keep cpus in the same way as the daemon allocator, eg. numa and
+// scatter allocators take cpus from the topology.
+func WithNamespaceAllocators(allocators map[string]Allocator) Option {
+	return func(o *daemonOptions) {
+		o.namespaceAllocators = allocators
+	}
+}
+
+// WithPressureGuardrail makes the daemon refuse exclusive allocations which would shrink the shared pool
+// below minSharedCpus while pressure of given source reaches the threshold.
+func WithPressureGuardrail(source PressureSource, threshold float64, minSharedCpus int) Option {
+	return func(o *daemonOptions) {
+		o.pressureSource = source
+		o.pressureThreshold = threshold
+		o.pressureMinSharedCpus = minSharedCpus
+	}
+}
+
+// WithStateSaveDelay makes the daemon append changes of the state to a journal next to the state file
+// instead of writing the whole state file on every pod operation. The state file is written at most
+// given delay after the first journaled change, and the journal is replayed when the state is loaded.
+func WithStateSaveDelay(delay time.Duration) Option {
+	return func(o *daemonOptions) {
+		o.stateSaveDelay = delay
+	}
+}
+
+// WithStateEncoding sets the format in which the state file is written. State files written in another
+// format are still loaded and get converted with the next write.
+func WithStateEncoding(encoding StateEncoding) Option {
+	return func(o *daemonOptions) {
+		o.stateEncoding = encoding
+	}
+}
+
+// WithCgroupVersion overrides cgroup version detected at startup.
+func WithCgroupVersion(version CgroupVersion) Option {
+	return func(o *daemonOptions) {
+		o.cgroupVersion = version
+	}
+}
+
+// WithCgroupRetry configures updates of containers whose cgroups do not exist yet, eg. because the runtime
+// did not create the systemd scope of the container yet. Such updates do not fail the request, the daemon
+// re-applies them after given delay, up to given number of attempts. Zero attempts make such updates fail.
+func WithCgroupRetry(delay time.Duration, attempts int) Option {
+	return func(o *daemonOptions) {
+		o.cgroupRetryDelay = delay
+		o.cgroupRetryAttempts = attempts
+	}
+}
+
+// WithCPUBorrowing lends idle exclusive cpus of guaranteed containers to burstable containers of the
+// same bucket, whose fraction of throttled cpu periods is at least given threshold in given number of
+// consecutive checks. Cpus are returned as soon as their owners use them, or when the container is not
+// throttled in as many checks. Checks are run by RunCPUBorrowing. Supported only with cgroups v2.
+func WithCPUBorrowing(throttling float64, checks int) Option {
+	return func(o *daemonOptions) {
+		o.cpuBorrowing = true
+		o.borrowingThrottling = throttling
+		o.borrowingChecks = checks
+	}
+}
+
+// WithNodeName sets name of the node the daemon runs on. Pod requests carrying another node name are
+// rejected, so that requests misrouted to the daemon of another node do not change its allocations.
+func WithNodeName(name string) Option {
+	return func(o *daemonOptions) {
+		o.nodeName = name
+	}
+}
+
+func (o *daemonOptions) validate() error {
+	if o.exclusiveCpusCap <= 0 || o.exclusiveCpusCap > maxExclusiveCpusCap {
+		return DaemonError{
+			ErrorType:    ConfigurationError,
+			ErrorMessage: fmt.Sprintf("exclusive cpus cap shall be in range (0, 100], got %d", o.exclusiveCpusCap),
+		}
+	}
+	if o.tombstoneTTL < 0 {
+		return DaemonError{
+			ErrorType:    ConfigurationError,
+			ErrorMessage: fmt.Sprintf("tombstone ttl shall not be negative, got %s", o.tombstoneTTL),
+		}
+	}
+	if o.stateSaveDelay < 0 {
+		return DaemonError{
+			ErrorType:    ConfigurationError,
+			ErrorMessage: fmt.Sprintf("state save delay shall not be negative, got %s", o.stateSaveDelay),
+		}
+	}
+	if o.cgroupRetryAttempts < 0 || o.cgroupRetryAttempts > 0 && o.cgroupRetryDelay <= 0 {
+		return DaemonError{
+			ErrorType: ConfigurationError,
+			ErrorMessage: fmt.Sprintf(
+				"cgroup retry attempts shall not be negative and delay shall be positive, got %d attempts and %s delay",
+				o.cgroupRetryAttempts, o.cgroupRetryDelay,
+			),
+		}
+	}
+	if o.managedCPUs != nil && o.managedCPUs.Count() == 0 {
+		return DaemonError{
+			ErrorType:    ConfigurationError,
+			ErrorMessage: "managed cpus shall not be empty",
+		}
+	}
+	if o.cpuBorrowing && (o.borrowingThrottling <= 0 || o.borrowingThrottling > 1 || o.borrowingChecks <= 0) {
+		return DaemonError{
+			ErrorType: ConfigurationError,
+			ErrorMessage: fmt.Sprintf(
+				"cpu borrowing throttling shall be in range (0, 1] and checks shall be positive, got %g and %d",
+				o.borrowingThrottling, o.borrowingChecks,
+			),
+		}
+	}
+	if o.cpuBorrowing && o.sharedPoolCgroups {
+		return DaemonError{
+			ErrorType:    ConfigurationError,
+			ErrorMessage: "cpu borrowing cannot be used with shared pool cgroups, which keep burstable containers off exclusive cpus",
+		}
+	}
+	if o.kubepodsReservedCPUs != nil && o.managedCPUs != nil {
+		return DaemonError{
+			ErrorType:    ConfigurationError,
+			ErrorMessage: "kubepods cpuset cannot be managed by a daemon instance managing only subset of cpus",
+		}
+	}
+	if o.kubepodsReservedCPUs != nil && o.reservedCPUs != nil {
+		return DaemonError{
+			ErrorType:    ConfigurationError,
+			ErrorMessage: "reserved cpus cannot be given together with reserved cpus of managed kubepods cpuset",
+		}
+	}
+	if o.pressureSource != PressureNone && (o.pressureThreshold <= 0 || o.pressureMinSharedCpus < 0) {
+		return DaemonError{
+			ErrorType: ConfigurationError,
+			ErrorMessage: fmt.Sprintf(
+				"pressure threshold shall be positive and minimal shared cpus shall not be negative, got %g and %d",
+				o.pressureThreshold, o.pressureMinSharedCpus,
+			),
+		}
+	}
+	if err := validatePolicyTiers(o.policyTiers); err != nil {
+		return DaemonError{ErrorType: ConfigurationError, ErrorMessage: err.Error()}
+	}
+	for name, a := range o.namespaceAllocators {
+		if name == "" || a == nil {
+			return DaemonError{
+				ErrorType:    ConfigurationError,
+				ErrorMessage: fmt.Sprintf("namespace allocator %q shall have a name and an allocator", name),
+			}
+		}
+	}
+	return nil
+}
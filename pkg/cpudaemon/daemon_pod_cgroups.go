@@ -0,0 +1,73 @@
+package cpudaemon
+
+import (
+	"os"
+	"path/filepath"
+
+	"resourcemanagement.controlplane/pkg/utils"
+)
+
+// checkPodCgroupPinningSupport disables pod cgroup pinning on cgroups v1, where cpuset of a container
+// cannot be extended beyond cpuset of its pod cgroup.
+func (d *Daemon) checkPodCgroupPinningSupport() {
+	if d.options.podCgroupPinning && !d.state.CgroupVersion.unified() {
+		d.logger.Info("pod cgroup pinning is supported only with cgroups v2, pod cgroups are not updated")
+		d.options.podCgroupPinning = false
+	}
+}
+
+// podCpuset returns cgroup directory of the pod and union of cpus allocated to its containers. Cpuset is
+// empty (inherited from the parent cgroup) if any container of the pod has no cpus allocated. Returns
+// false if no cgroup of the pod containers was written yet.
+func (d *DaemonState) podCpuset(pod PodMetadata) (string, string, bool) {
+	dir := ""
+	cpus := CPUSet{}
+	pinned := len(pod.Containers) > 0
+	for _, c := range pod.Containers {
+		if cgroupPath := d.getCgroupPath(c.CID); cgroupPath != "" && dir == "" {
+			dir = filepath.Dir(cgroupPath)
+		}
+		allocated, ok := d.Allocated[c.CID]
+		if !ok {
+			pinned = false
+			continue
+		}
+		cpus.Merge(CPUSetFromBucketList(allocated))
+	}
+	if !pinned {
+		return dir, "", dir != ""
+	}
+	return dir, cpus.ToCpuString(), dir != ""
+}
+
+// applyPodCgroups sets cpuset of pod cgroups to union of cpus allocated to their containers, so that
+// containers started later in the pod (eg. ephemeral debug containers) do not escape onto exclusive
+// cpus of other pods. Failures are logged only and retried on the next state change.
+func (d *Daemon) applyPodCgroups() {
+	if !d.options.podCgroupPinning {
+		return
+	}
+	applied := make(map[string]string, len(d.state.Pods))
+	for pid, pod := range d.state.Pods {
+		dir, cpus, ok := d.state.podCpuset(pod)
+		if !ok {
+			continue
+		}
+		if prev, ok := d.appliedPodCpusets[pid]; ok && prev == cpus {
+			applied[pid] = cpus
+			continue
+		}
+		cpusetPath := filepath.Join(dir, "cpuset.cpus")
+		if err := utils.ValidatePathInsideBase(cpusetPath, d.state.CGroupPath); err != nil {
+			d.logger.Error(err, "invalid pod cgroup path", "pid", pid, "path", cpusetPath)
+			continue
+		}
+		if err := os.WriteFile(cpusetPath, []byte(cpus), os.FileMode(0)); err != nil {
+			d.logger.Error(err, "cannot apply pod cgroup cpuset", "pid", pid, "path", cpusetPath)
+			continue
+		}
+		d.logger.V(2).Info("pod cgroup cpuset applied", "pid", pid, "path", cpusetPath, "cpus", cpus)
+		applied[pid] = cpus
+	}
+	d.appliedPodCpusets = applied
+}
@@ -0,0 +1,97 @@
+package cpudaemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+func newDaemonForPodCgroupsTest(t *testing.T, version CgroupVersion) *Daemon {
+	d := newTestDaemon(t, &MockedPolicy{}, WithCgroupVersion(version), WithPodCgroupPinning())
+	d.state.CGroupPath = t.TempDir()
+	return d
+}
+
+// addPodForPodCgroupsTest adds pod with given containers to the state, containers with nil buckets
+// have no cpus allocated. Returns cpuset file of the pod cgroup.
+func addPodForPodCgroupsTest(t *testing.T, d *Daemon, pid string, containers map[string][]ctlplaneapi.CPUBucket) string {
+	podDir := filepath.Join(d.state.CGroupPath, "kubepods.slice", "kubepods-pod"+pid+".slice")
+	require.Nil(t, os.MkdirAll(podDir, 0o755))
+	cpusetPath := filepath.Join(podDir, "cpuset.cpus")
+	require.Nil(t, os.WriteFile(cpusetPath, []byte{}, 0o600))
+	pod := PodMetadata{PID: pid}
+	for cid, buckets := range containers {
+		pod.Containers = append(pod.Containers, Container{CID: cid, PID: pid})
+		d.state.setCgroupPath(cid, filepath.Join(podDir, cid+".scope"))
+		if buckets != nil {
+			d.state.Allocated[cid] = buckets
+		}
+	}
+	d.state.Pods[pid] = pod
+	return cpusetPath
+}
+
+func readPodCpuset(t *testing.T, path string) string {
+	b, err := os.ReadFile(path)
+	require.Nil(t, err)
+	return string(b)
+}
+
+func TestApplyPodCgroups(t *testing.T) {
+	d := newDaemonForPodCgroupsTest(t, CgroupV2)
+	pinned := addPodForPodCgroupsTest(t, d, "p1", map[string][]ctlplaneapi.CPUBucket{
+		"c1": {{StartCPU: 2, EndCPU: 3}},
+		"c2": {{StartCPU: 6, EndCPU: 6}},
+	})
+	shared := addPodForPodCgroupsTest(t, d, "p2", map[string][]ctlplaneapi.CPUBucket{
+		"c3": {{StartCPU: 4, EndCPU: 4}},
+		"c4": nil,
+	})
+
+	d.applyPodCgroups()
+
+	assert.Equal(t, "2,3,6", readPodCpuset(t, pinned))
+	assert.Empty(t, readPodCpuset(t, shared), "pod with unpinned container inherits parent cpus")
+	assert.Equal(t, map[string]string{"p1": "2,3,6", "p2": ""}, d.appliedPodCpusets)
+}
+
+func TestApplyPodCgroupsSkipsUnchangedPods(t *testing.T) {
+	d := newDaemonForPodCgroupsTest(t, CgroupV2)
+	cpusetPath := addPodForPodCgroupsTest(t, d, "p1", map[string][]ctlplaneapi.CPUBucket{
+		"c1": {{StartCPU: 2, EndCPU: 3}},
+	})
+	d.applyPodCgroups()
+	require.Nil(t, os.WriteFile(cpusetPath, []byte("changed"), 0o600))
+
+	d.applyPodCgroups()
+	assert.Equal(t, "changed", readPodCpuset(t, cpusetPath))
+
+	d.state.Allocated["c1"] = []ctlplaneapi.CPUBucket{{StartCPU: 1, EndCPU: 1}}
+	d.applyPodCgroups()
+	assert.Equal(t, "1", readPodCpuset(t, cpusetPath))
+}
+
+func TestApplyPodCgroupsForgetsDeletedPods(t *testing.T) {
+	d := newDaemonForPodCgroupsTest(t, CgroupV2)
+	addPodForPodCgroupsTest(t, d, "p1", map[string][]ctlplaneapi.CPUBucket{"c1": {{StartCPU: 2, EndCPU: 3}}})
+	d.applyPodCgroups()
+
+	delete(d.state.Pods, "p1")
+	d.applyPodCgroups()
+
+	assert.Empty(t, d.appliedPodCpusets)
+}
+
+func TestPodCgroupPinningDisabledOnCgroupsV1(t *testing.T) {
+	d := newDaemonForPodCgroupsTest(t, CgroupV1)
+	cpusetPath := addPodForPodCgroupsTest(t, d, "p1", map[string][]ctlplaneapi.CPUBucket{"c1": {{StartCPU: 2, EndCPU: 3}}})
+
+	d.applyPodCgroups()
+
+	assert.False(t, d.options.podCgroupPinning)
+	assert.Empty(t, readPodCpuset(t, cpusetPath))
+}
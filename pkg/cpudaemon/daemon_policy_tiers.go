@@ -0,0 +1,151 @@
+package cpudaemon
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// PolicyTier routes pods of a set of namespaces to their own policy, eg. StaticPolicy for production
+// namespaces and ObservePolicy for development ones. Pods of namespaces not assigned to any tier are
+// managed by the policy the daemon was created with.
+type PolicyTier struct {
+	Name       string
+	PolicyName string // name of the policy reported by GetDaemonInfo, eg. static
+	Policy     Policy
+	Namespaces []string
+}
+
+// ParsePolicyTiers parses semicolon separated list of tiers given as name=policy:namespaces, where
+// namespaces is comma separated list, eg. "prod=static:shop,payments;dev=observe:sandbox". Policies are
+// looked up by name in given map.
+func ParsePolicyTiers(tiers string, policies map[string]Policy) ([]PolicyTier, error) {
+	res := []PolicyTier{}
+	for _, entry := range strings.Split(tiers, ";") {
+		name, spec, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		policyName, namespaces, okSpec := strings.Cut(spec, ":")
+		if !ok || !okSpec || name == "" {
+			return nil, fmt.Errorf("invalid policy tier %q, expected name=policy:namespaces", entry)
+		}
+		policyName = strings.TrimSpace(policyName)
+		policy, ok := policies[policyName]
+		if !ok {
+			return nil, fmt.Errorf("unknown policy %q of tier %s", policyName, name)
+		}
+		t := PolicyTier{Name: name, PolicyName: policyName, Policy: policy}
+		for _, namespace := range strings.Split(namespaces, ",") {
+			if namespace = strings.TrimSpace(namespace); namespace != "" {
+				t.Namespaces = append(t.Namespaces, namespace)
+			}
+		}
+		res = append(res, t)
+	}
+	return res, validatePolicyTiers(res)
+}
+
+// validatePolicyTiers checks that tiers have unique names and policies, and that each namespace is
+// assigned to at most one of them.
+func validatePolicyTiers(tiers []PolicyTier) error {
+	names := make(map[string]struct{}, len(tiers))
+	namespaces := map[string]string{}
+	for _, t := range tiers {
+		if t.Name == "" || t.Policy == nil || len(t.Namespaces) == 0 {
+			return fmt.Errorf("policy tier %q shall have a name, a policy and namespaces", t.Name)
+		}
+		if _, ok := names[t.Name]; ok {
+			return fmt.Errorf("duplicated policy tier %s", t.Name)
+		}
+		names[t.Name] = struct{}{}
+		for _, namespace := range t.Namespaces {
+			if other, ok := namespaces[namespace]; ok {
+				return fmt.Errorf("namespace %s assigned to policy tiers %s and %s", namespace, other, t.Name)
+			}
+			namespaces[namespace] = t.Name
+		}
+	}
+	return nil
+}
+
+// namespaceTier returns name of the policy tier of the namespace, empty if the namespace is managed by
+// the default policy.
+func (o *daemonOptions) namespaceTier(namespace string) string {
+	for _, t := range o.policyTiers {
+		for _, ns := range t.Namespaces {
+			if ns == namespace {
+				return t.Name
+			}
+		}
+	}
+	return ""
+}
+
+// tierPolicies maps names of policy tiers to their policies.
+func tierPolicies(tiers []PolicyTier) map[string]Policy {
+	res := make(map[string]Policy, len(tiers))
+	for _, t := range tiers {
+		res[t.Name] = t.Policy
+	}
+	return res
+}
+
+// policyFor returns policy managing the container: the policy of the tier recorded in its pod when the
+// pod was created, or the default policy. Pods keep their tier until deleted, so that cpus allocated by
+// one policy are never released by another one after the tiers are reconfigured.
+func (d *Daemon) policyFor(c Container) Policy {
+	return d.podPolicy(d.state.Pods[c.PID])
+}
+
+// podPolicy returns policy managing containers of the pod, see policyFor.
+func (d *Daemon) podPolicy(pod PodMetadata) Policy {
+	if p, ok := d.tierPolicies[pod.Tier]; ok {
+		return p
+	}
+	return d.policy
+}
+
+// checkPolicyTiers logs pods of tiers which are no longer configured; they are managed by the default
+// policy from now on.
+func (d *Daemon) checkPolicyTiers() {
+	for _, pod := range d.state.Pods {
+		if _, ok := d.tierPolicies[pod.Tier]; pod.Tier != "" && !ok {
+			d.logger.Info("policy tier of the pod is not configured, using default policy", "pid", pod.PID, "tier", pod.Tier)
+		}
+	}
+}
+
+// dryRunTierPolicies returns copies of tier policies for a dry run daemon. Tiers sharing the default
+// policy share its copy as well.
+func (d *Daemon) dryRunTierPolicies(dryPolicy Policy) (map[string]Policy, error) {
+	res := make(map[string]Policy, len(d.tierPolicies))
+	for name, policy := range d.tierPolicies {
+		if policy == d.policy {
+			res[name] = dryPolicy
+			continue
+		}
+		p, ok := policy.(DryRunPolicy)
+		if !ok {
+			return nil, errDryRunNotSupported
+		}
+		if res[name], ok = p.DryRun(); !ok {
+			return nil, errDryRunNotSupported
+		}
+	}
+	return res, nil
+}
+
+// policyTiersInfo describes configured policy tiers, ordered by name.
+func (o *daemonOptions) policyTiersInfo() []ctlplaneapi.PolicyTierInfo {
+	res := make([]ctlplaneapi.PolicyTierInfo, 0, len(o.policyTiers))
+	for _, t := range o.policyTiers {
+		namespaces := append([]string{}, t.Namespaces...)
+		sort.Strings(namespaces)
+		res = append(res, ctlplaneapi.PolicyTierInfo{Name: t.Name, Policy: t.PolicyName, Namespaces: namespaces})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
@@ -0,0 +1,137 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+func TestParsePolicyTiers(t *testing.T) {
+	static := NewStaticPolocy(nil)
+	policies := map[string]Policy{"static": static, "observe": NewObservePolicy()}
+
+	tiers, err := ParsePolicyTiers("prod=static:shop, payments; dev=observe:sandbox", policies)
+
+	require.Nil(t, err)
+	assert.Equal(t, []PolicyTier{
+		{Name: "prod", PolicyName: "static", Policy: static, Namespaces: []string{"shop", "payments"}},
+		{Name: "dev", PolicyName: "observe", Policy: NewObservePolicy(), Namespaces: []string{"sandbox"}},
+	}, tiers)
+}
+
+func TestParsePolicyTiersFails(t *testing.T) {
+	policies := map[string]Policy{"observe": NewObservePolicy()}
+	for _, tiers := range []string{
+		"",
+		"dev",
+		"dev=observe",
+		"=observe:sandbox",
+		"dev=random:sandbox",
+		"dev=observe:",
+		"dev=observe:a;dev=observe:b",
+		"dev=observe:a;test=observe:a",
+	} {
+		_, err := ParsePolicyTiers(tiers, policies)
+		assert.NotNil(t, err, tiers)
+	}
+}
+
+func TestNewDaemonValidatesPolicyTiers(t *testing.T) {
+	_, err := New(
+		"testdata/no_state",
+		"testdata/node_info",
+		"daemon.state",
+		&MockedPolicy{},
+		logr.Discard(),
+		WithPolicyTiers([]PolicyTier{{Name: "dev", Policy: NewObservePolicy()}}),
+	)
+
+	assert.Equal(t, ConfigurationError, err.(DaemonError).ErrorType)
+}
+
+// newDaemonWithObserveTier returns daemon with the namespace of test pods in observe-only tier.
+func newDaemonWithObserveTier(t *testing.T) *Daemon {
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)))
+	d.options.policyTiers = []PolicyTier{
+		{Name: "dev", PolicyName: "observe", Policy: NewObservePolicy(), Namespaces: []string{"testPid"}},
+	}
+	d.tierPolicies = tierPolicies(d.options.policyTiers)
+	return d
+}
+
+func TestPodsOfObserveTierAreNotAllocated(t *testing.T) {
+	d := newDaemonWithObserveTier(t)
+	available := d.state.AvailableCPUs
+	p := podWithPlacement(ctlplaneapi.Placement_DEFAULT)
+
+	createPodForRepack(t, d, p)
+
+	assert.Equal(t, "dev", d.state.Pods[p.pid].Tier)
+	assert.Len(t, d.state.Pods[p.pid].Containers, 2)
+	assert.Empty(t, d.state.Allocated)
+	assert.Equal(t, available, d.state.AvailableCPUs)
+
+	_, err := d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: p.pid})
+	require.Nil(t, err)
+	assert.Empty(t, d.state.Pods)
+}
+
+func TestPodsOfOtherNamespacesUseDefaultPolicy(t *testing.T) {
+	d := newDaemonWithObserveTier(t)
+	p := podWithPlacement(ctlplaneapi.Placement_DEFAULT)
+	p.namespace = "prod"
+
+	createPodForRepack(t, d, p)
+
+	assert.Empty(t, d.state.Pods[p.pid].Tier)
+	assert.Len(t, d.state.Allocated, 2)
+}
+
+func TestPodKeepsPolicyTierUntilDeleted(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)))
+	p := podWithPlacement(ctlplaneapi.Placement_DEFAULT)
+	createPodForRepack(t, d, p)
+	require.Len(t, d.state.Allocated, 2)
+
+	// the namespace is moved to observe-only tier while its pod is running
+	d.options.policyTiers = []PolicyTier{
+		{Name: "dev", PolicyName: "observe", Policy: NewObservePolicy(), Namespaces: []string{p.namespace}},
+	}
+	d.tierPolicies = tierPolicies(d.options.policyTiers)
+	_, err := d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: p.pid})
+
+	require.Nil(t, err)
+	assert.Empty(t, d.state.Allocated)
+	assert.Equal(t, 8, d.state.Topology.Topology.NumAvailable, "cpus shall be freed by the default policy")
+}
+
+func TestDryRunWithPolicyTiers(t *testing.T) {
+	d := newDaemonWithObserveTier(t)
+	d.options.policyTiers = append(d.options.policyTiers, PolicyTier{
+		Name: "prod", PolicyName: "static", Policy: d.policy, Namespaces: []string{"prod"},
+	})
+	d.tierPolicies = tierPolicies(d.options.policyTiers)
+
+	dry, err := d.dryRunDaemon()
+
+	require.Nil(t, err)
+	assert.Equal(t, dry.policy, dry.tierPolicies["prod"])
+	assert.NotEqual(t, d.policy, dry.tierPolicies["prod"])
+	assert.Equal(t, NewObservePolicy(), dry.tierPolicies["dev"])
+}
+
+func TestGetDaemonInfoReportsPolicyTiers(t *testing.T) {
+	d := newDaemonWithObserveTier(t)
+	d.options.policyTiers[0].Namespaces = []string{"b", "a"}
+
+	info, err := d.GetDaemonInfo(context.Background(), &ctlplaneapi.GetDaemonInfoRequest{})
+
+	require.Nil(t, err)
+	assert.Equal(t, []ctlplaneapi.PolicyTierInfo{
+		{Name: "dev", Policy: "observe", Namespaces: []string{"a", "b"}},
+	}, info.PolicyTiers)
+}
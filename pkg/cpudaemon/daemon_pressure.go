@@ -0,0 +1,115 @@
+package cpudaemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+// PressureSource selects the measure of system pressure checked before exclusive allocations.
+type PressureSource int
+
+const (
+	// PressureNone disables the pressure guardrail.
+	PressureNone PressureSource = iota
+	// PressureLoadAverage reads 1 minute load average from /proc/loadavg.
+	PressureLoadAverage
+	// PressurePSI reads percent of time some tasks stalled on cpu in the last 10 seconds from
+	// /proc/pressure/cpu.
+	PressurePSI
+)
+
+func (p PressureSource) String() string {
+	switch p {
+	case PressureLoadAverage:
+		return "loadavg"
+	case PressurePSI:
+		return "psi"
+	default:
+		return "none"
+	}
+}
+
+// read returns current pressure of the node.
+func (p PressureSource) read(procPath string) (float64, error) {
+	switch p {
+	case PressureLoadAverage:
+		return readLoadAverage(filepath.Join(procPath, "loadavg"))
+	case PressurePSI:
+		return readCPUPressure(filepath.Join(procPath, "pressure", "cpu"))
+	default:
+		return 0, nil
+	}
+}
+
+// readLoadAverage parses 1 minute load average, the first field of /proc/loadavg.
+func readLoadAverage(path string) (float64, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("empty load average in %s", path)
+	}
+	return strconv.ParseFloat(fields[0], 64)
+}
+
+// readCPUPressure parses avg10 of the "some" line of cpu pressure stall information, eg.
+// "some avg10=1.53 avg60=0.87 avg300=0.22 total=1234".
+func readCPUPressure(path string) (float64, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != "some" {
+			continue
+		}
+		for _, field := range fields[1:] {
+			if value, ok := strings.CutPrefix(field, "avg10="); ok {
+				return strconv.ParseFloat(value, 64)
+			}
+		}
+	}
+	return 0, fmt.Errorf("no cpu pressure found in %s", path)
+}
+
+// checkSystemPressure refuses exclusive allocation of the container which would shrink the shared pool
+// below the configured number of cpus while the node is under pressure, so that system daemons and
+// burstable containers keep room during incidents. The allocation is allowed if the pressure cannot be
+// read.
+func (d *Daemon) checkSystemPressure(c Container) error {
+	if c.QS != Guaranteed || d.options.pressureSource == PressureNone {
+		return nil
+	}
+	shared := len(d.state.Topology.Topology.GetLeafs()) - d.state.exclusiveCpusCount(c.CID) - c.Cpus
+	if shared >= d.options.pressureMinSharedCpus {
+		return nil
+	}
+	pressure, err := d.options.pressureSource.read(d.options.procPath)
+	if err != nil {
+		d.logger.Error(err, "cannot read system pressure, allocation allowed", "source", d.options.pressureSource)
+		return nil
+	}
+	if pressure < d.options.pressureThreshold {
+		return nil
+	}
+	metrics.PressureRefusals.Inc()
+	return DaemonError{
+		ErrorType: SystemPressure,
+		ErrorMessage: fmt.Sprintf(
+			"cannot allocate %d exclusive cpus under system pressure, %s %.2f reached %.2f and shared pool would shrink to %d cpus",
+			c.Cpus,
+			d.options.pressureSource,
+			pressure,
+			d.options.pressureThreshold,
+			shared,
+		),
+	}
+}
@@ -0,0 +1,89 @@
+package cpudaemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newDaemonForPressureTest(t *testing.T, source PressureSource, minSharedCpus int) *Daemon {
+	d := newTestDaemon(t, &MockedPolicy{}, WithPressureGuardrail(source, 8, minSharedCpus))
+	d.options.procPath = t.TempDir()
+	return d
+}
+
+func TestReadLoadAverage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loadavg")
+	require.Nil(t, os.WriteFile(path, []byte("9.50 4.25 1.00 3/512 1234\n"), 0o600))
+
+	load, err := readLoadAverage(path)
+
+	require.Nil(t, err)
+	assert.Equal(t, 9.5, load)
+}
+
+func TestReadCPUPressure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cpu")
+	require.Nil(t, os.WriteFile(path, []byte(
+		"some avg10=12.34 avg60=5.00 avg300=1.00 total=1234\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n",
+	), 0o600))
+
+	pressure, err := readCPUPressure(path)
+
+	require.Nil(t, err)
+	assert.Equal(t, 12.34, pressure)
+
+	require.Nil(t, os.WriteFile(path, []byte("full avg10=0.00\n"), 0o600))
+	_, err = readCPUPressure(path)
+	assert.NotNil(t, err)
+}
+
+func TestCheckSystemPressure(t *testing.T) {
+	d := newDaemonForPressureTest(t, PressureLoadAverage, 4) // 8 cpus in the node
+	require.Nil(t, os.WriteFile(filepath.Join(d.options.procPath, "loadavg"), []byte("9.50 4.25 1.00 3/512 1\n"), 0o600))
+	c := createTestPod(1).containers[0]
+
+	c.Cpus = 4
+	assert.Nil(t, d.checkSystemPressure(c), "shared pool keeps enough cpus")
+	c.Cpus = 5
+	err := d.checkSystemPressure(c)
+	require.NotNil(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, err.Error(), "loadavg 9.50 reached 8.00")
+	assert.True(t, notFitting(err))
+	c.QS = Burstable
+	assert.Nil(t, d.checkSystemPressure(c), "burstable containers run in shared pool")
+
+	c.QS = Guaranteed
+	require.Nil(t, os.WriteFile(filepath.Join(d.options.procPath, "loadavg"), []byte("7.50 4.25 1.00 3/512 1\n"), 0o600))
+	assert.Nil(t, d.checkSystemPressure(c), "pressure below threshold")
+}
+
+func TestCheckSystemPressureAllowsUnreadablePressure(t *testing.T) {
+	d := newDaemonForPressureTest(t, PressurePSI, 8)
+	c := createTestPod(1).containers[0]
+
+	assert.Nil(t, d.checkSystemPressure(c))
+}
+
+func TestCreatePodRefusedUnderPressure(t *testing.T) {
+	d := newDaemonForPressureTest(t, PressurePSI, 8)
+	require.Nil(t, os.MkdirAll(filepath.Join(d.options.procPath, "pressure"), 0o755))
+	require.Nil(t, os.WriteFile(
+		filepath.Join(d.options.procPath, "pressure", "cpu"),
+		[]byte("some avg10=40.00 avg60=20.00 avg300=5.00 total=1234\n"),
+		0o600,
+	))
+	p := createTestPod(1)
+
+	_, err := d.CreatePod(context.Background(), createPodRequest(p))
+
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Empty(t, d.state.Allocated)
+}
@@ -0,0 +1,78 @@
+package cpudaemon
+
+import (
+	"fmt"
+	"strings"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// ProfileAnnotation selects allocation profile of the pod by its name (eg. "latency").
+const ProfileAnnotation = "ctlplane.intel.com/profile"
+
+// Profile is a named set of allocation settings selected by pods with a single annotation. Settings
+// given explicitly in pod requests take precedence over the profile.
+type Profile struct {
+	MemoryPinning ctlplaneapi.MemoryPinning
+	Placement     ctlplaneapi.Placement
+}
+
+// profileSettings maps names of profile settings to their effect on the profile.
+var profileSettings = map[string]func(*Profile){
+	"memory-pinning":    func(p *Profile) { p.MemoryPinning = ctlplaneapi.MemoryPinning_MEMORY_PINNING_ENABLED },
+	"no-memory-pinning": func(p *Profile) { p.MemoryPinning = ctlplaneapi.MemoryPinning_MEMORY_PINNING_DISABLED },
+	"compact":           func(p *Profile) { p.Placement = ctlplaneapi.Placement_COMPACT },
+	"scatter":           func(p *Profile) { p.Placement = ctlplaneapi.Placement_SCATTER },
+}
+
+// ParseProfiles parses semicolon separated list of profiles given as name=settings pairs, where settings
+// is comma separated list of memory-pinning, no-memory-pinning, compact and scatter, eg.
+// "latency=memory-pinning,compact;throughput=no-memory-pinning,scatter".
+func ParseProfiles(profiles string) (map[string]Profile, error) {
+	res := make(map[string]Profile)
+	for _, entry := range strings.Split(profiles, ";") {
+		name, settings, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid profile %q, expected name=settings", entry)
+		}
+		if _, ok := res[name]; ok {
+			return nil, fmt.Errorf("duplicated profile %s", name)
+		}
+		p := Profile{}
+		for _, setting := range strings.Split(settings, ",") {
+			apply, ok := profileSettings[strings.TrimSpace(setting)]
+			if !ok {
+				return nil, fmt.Errorf("unknown setting %q of profile %s", setting, name)
+			}
+			apply(&p)
+		}
+		res[name] = p
+	}
+	return res, nil
+}
+
+// podProfile returns profile selected by pod annotations, or empty profile if the pod selects none.
+func (d *Daemon) podProfile(annotations map[string]string) (Profile, error) {
+	name, ok := annotations[ProfileAnnotation]
+	if !ok {
+		return Profile{}, nil
+	}
+	p, ok := d.options.profiles[name]
+	if !ok {
+		return Profile{}, DaemonError{
+			ErrorType:    PodSpecError,
+			ErrorMessage: fmt.Sprintf("unknown allocation profile %q", name),
+		}
+	}
+	return p, nil
+}
+
+// placement returns the requested placement, or placement of the profile if the request does not ask for
+// any specific one.
+func (p Profile) placement(requested ctlplaneapi.Placement) ctlplaneapi.Placement {
+	if requested != ctlplaneapi.Placement_DEFAULT {
+		return requested
+	}
+	return p.Placement
+}
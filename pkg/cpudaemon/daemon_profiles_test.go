@@ -0,0 +1,110 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+func TestParseProfiles(t *testing.T) {
+	profiles, err := ParseProfiles("latency=memory-pinning,compact; throughput=no-memory-pinning, scatter")
+
+	require.Nil(t, err)
+	assert.Equal(t, map[string]Profile{
+		"latency": {
+			MemoryPinning: ctlplaneapi.MemoryPinning_MEMORY_PINNING_ENABLED,
+			Placement:     ctlplaneapi.Placement_COMPACT,
+		},
+		"throughput": {
+			MemoryPinning: ctlplaneapi.MemoryPinning_MEMORY_PINNING_DISABLED,
+			Placement:     ctlplaneapi.Placement_SCATTER,
+		},
+	}, profiles)
+}
+
+func TestParseProfilesFails(t *testing.T) {
+	for _, profiles := range []string{"", "latency", "=compact", "latency=fast", "latency=compact;latency=scatter"} {
+		_, err := ParseProfiles(profiles)
+		assert.NotNil(t, err, profiles)
+	}
+}
+
+func newDaemonWithProfiles(t *testing.T) *Daemon {
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)))
+	d.options.profiles = map[string]Profile{
+		"latency": {
+			MemoryPinning: ctlplaneapi.MemoryPinning_MEMORY_PINNING_ENABLED,
+			Placement:     ctlplaneapi.Placement_COMPACT,
+		},
+	}
+	return d
+}
+
+func createPodWithProfile(d *Daemon, p PodMetaData, profile string) error {
+	_, err := d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+			Containers:   p.containersResources,
+			Annotations:  map[string]string{ProfileAnnotation: profile},
+		},
+	)
+	return err
+}
+
+func TestCreatePodAppliesProfile(t *testing.T) {
+	d := newDaemonWithProfiles(t)
+	p := podWithPlacement(ctlplaneapi.Placement_DEFAULT)
+
+	require.Nil(t, createPodWithProfile(d, p, "latency"))
+
+	pod := d.state.Pods[p.pid]
+	assert.Equal(t, ctlplaneapi.MemoryPinning_MEMORY_PINNING_ENABLED, pod.MemoryPinning)
+	assert.Equal(t, ctlplaneapi.Placement_COMPACT, pod.Placement)
+}
+
+func TestRequestOverridesProfile(t *testing.T) {
+	d := newDaemonWithProfiles(t)
+	p := podWithPlacement(ctlplaneapi.Placement_SCATTER)
+
+	require.Nil(t, createPodWithProfile(d, p, "latency"))
+
+	assert.Equal(t, ctlplaneapi.Placement_SCATTER, d.state.Pods[p.pid].Placement)
+}
+
+func TestCreatePodWithUnknownProfileFails(t *testing.T) {
+	d := newDaemonWithProfiles(t)
+	p := podWithPlacement(ctlplaneapi.Placement_DEFAULT)
+
+	err := createPodWithProfile(d, p, "throughput")
+
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, PodSpecError, daemonErr.ErrorType)
+	assert.NotContains(t, d.state.Pods, p.pid)
+}
+
+func TestUpdatePodAppliesProfilePlacement(t *testing.T) {
+	d := newDaemonWithProfiles(t)
+	p := podWithPlacement(ctlplaneapi.Placement_DEFAULT)
+	createPodForRepack(t, d, p)
+
+	_, err := d.UpdatePod(
+		context.Background(),
+		&ctlplaneapi.UpdatePodRequest{
+			PodId:       p.pid,
+			Resources:   p.resources,
+			Containers:  p.containersResources,
+			Annotations: map[string]string{ProfileAnnotation: "latency"},
+		},
+	)
+
+	require.Nil(t, err)
+	assert.Equal(t, ctlplaneapi.Placement_COMPACT, d.state.Pods[p.pid].Placement)
+}
@@ -0,0 +1,92 @@
+package cpudaemon
+
+import (
+	"time"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// publishReadState makes the current state visible to read requests. Read requests work on the
+// published copy without taking the state lock, so they never observe partially applied updates nor
+// delay them. Shall be called with the state lock held, once the update is complete.
+func (d *Daemon) publishReadState() {
+	d.readState.Store(d.state.clone())
+}
+
+// readableState returns the state published for read requests, empty state if none was published yet.
+// The returned state shall not be modified.
+func (d *Daemon) readableState() *DaemonState {
+	if s := d.readState.Load(); s != nil {
+		return s
+	}
+	return &DaemonState{
+		Allocated: make(map[string][]ctlplaneapi.CPUBucket),
+		Pods:      make(map[string]PodMetadata),
+	}
+}
+
+// clone returns a deep copy of the state. Allocation hints and tombstones, which are not used by read
+// requests, are left out.
+func (d *DaemonState) clone() *DaemonState {
+	c := &DaemonState{
+		AvailableCPUs:    cloneBuckets(d.AvailableCPUs),
+		Allocated:        make(map[string][]ctlplaneapi.CPUBucket, len(d.Allocated)),
+		Pods:             make(map[string]PodMetadata, len(d.Pods)),
+		Topology:         d.Topology.Clone(),
+		CGroupPath:       d.CGroupPath,
+		StatePath:        d.StatePath,
+		ExcludedCPUs:     cloneBuckets(d.ExcludedCPUs),
+		ReservedCPUs:     cloneBuckets(d.ReservedCPUs),
+		ManagedCPUs:      cloneBuckets(d.ManagedCPUs),
+		KubeletCPUs:      cloneBuckets(d.KubeletCPUs),
+		AllocatedAt:      cloneMap(d.AllocatedAt),
+		CgroupPaths:      cloneMap(d.CgroupPaths),
+		EffectiveCPUs:    cloneMap(d.EffectiveCPUs),
+		NamespaceConfigs: cloneMap(d.NamespaceConfigs),
+		Usage:            cloneMap(d.Usage),
+		Explanations:     cloneMap(d.Explanations),
+		MemoryNodes:      cloneMap(d.MemoryNodes),
+		CgroupVersion:    d.CgroupVersion,
+	}
+	for cid, buckets := range d.Allocated {
+		c.Allocated[cid] = cloneBuckets(buckets)
+	}
+	if d.Borrowed != nil {
+		c.Borrowed = make(map[string][]ctlplaneapi.CPUBucket, len(d.Borrowed))
+		for cid, buckets := range d.Borrowed {
+			c.Borrowed[cid] = cloneBuckets(buckets)
+		}
+	}
+	if d.CPUPools != nil {
+		c.CPUPools = make(map[string]CPUPool, len(d.CPUPools))
+		for name, pool := range d.CPUPools {
+			pool.CPUs = cloneBuckets(pool.CPUs)
+			c.CPUPools[name] = pool
+		}
+	}
+	for pid, pod := range d.Pods {
+		pod.Containers = append([]Container(nil), pod.Containers...)
+		pod.Labels = cloneMap(pod.Labels)
+		pod.Annotations = cloneMap(pod.Annotations)
+		c.Pods[pid] = pod
+	}
+	return c
+}
+
+func cloneBuckets(buckets []ctlplaneapi.CPUBucket) []ctlplaneapi.CPUBucket {
+	if buckets == nil {
+		return nil
+	}
+	return append([]ctlplaneapi.CPUBucket{}, buckets...)
+}
+
+func cloneMap[V string | time.Time | NamespaceConfig | NamespaceUsage](m map[string]V) map[string]V {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
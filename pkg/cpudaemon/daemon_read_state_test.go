@@ -0,0 +1,62 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+func TestCloneIsDeepCopy(t *testing.T) {
+	s := getPlacementTestState(t)
+	c := baseContainer(1)
+	addContainerToState(s, c)
+	s.Allocated[c.CID] = []ctlplaneapi.CPUBucket{{StartCPU: 1, EndCPU: 1}}
+	pod := s.Pods[c.PID]
+	pod.Annotations = map[string]string{"a": "b"}
+	s.Pods[c.PID] = pod
+	s.setMemoryNodes(c.CID, "0")
+
+	cloned := s.clone()
+	s.Allocated[c.CID][0].EndCPU = 2
+	s.Pods[c.PID].Containers[0].Cpus = 2
+	s.Pods[c.PID].Annotations["a"] = "c"
+	require.Nil(t, s.Topology.TakeCpu(1))
+
+	assert.Equal(t, []ctlplaneapi.CPUBucket{{StartCPU: 1, EndCPU: 1}}, cloned.Allocated[c.CID])
+	assert.Equal(t, 1, cloned.Pods[c.PID].Containers[0].Cpus)
+	assert.Equal(t, "b", cloned.Pods[c.PID].Annotations["a"])
+	assert.Equal(t, "0", cloned.getMemoryNodes(c.CID))
+	assert.Equal(t, 8, cloned.Topology.Topology.NumAvailable)
+}
+
+func TestReadsSeePublishedState(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)))
+	p := podWithPlacement(ctlplaneapi.Placement_DEFAULT)
+	createPodForRepack(t, d, p)
+
+	d.state.Pods["partial"] = PodMetadata{PID: "partial"} // update in progress, not published yet
+
+	_, err := d.GetPod(context.Background(), &ctlplaneapi.GetPodRequest{PodId: "partial"})
+	assert.NotNil(t, err)
+	pods, err := d.ListPods(context.Background(), &ctlplaneapi.ListPodsRequest{})
+	require.Nil(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, p.pid, pods[0].PodID)
+	assert.NotContains(t, d.GetState(), "partial")
+
+	d.publishReadState()
+	_, err = d.GetPod(context.Background(), &ctlplaneapi.GetPodRequest{PodId: "partial"})
+	assert.Nil(t, err)
+}
+
+func TestReadsWithoutPublishedState(t *testing.T) {
+	d := Daemon{}
+
+	pods, err := d.ListPods(context.Background(), &ctlplaneapi.ListPodsRequest{})
+
+	require.Nil(t, err)
+	assert.Empty(t, pods)
+}
@@ -0,0 +1,63 @@
+package cpudaemon
+
+import (
+	"fmt"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// SharedPool is the pool cpus of deleted containers return to, unless the allocator keeps other pools.
+const SharedPool = "shared"
+
+// ReleasedPoolPolicy is implemented by policies able to tell which pool cpus of a container return to when
+// the container is deleted.
+type ReleasedPoolPolicy interface {
+	ReleasedPool(c Container) string
+}
+
+var _ ReleasedPoolPolicy = &StaticPolicy{}
+
+// releasedPoolAllocator is implemented by allocators keeping pools other than the shared one.
+type releasedPoolAllocator interface {
+	releasedPool(c Container) string
+}
+
+var _ releasedPoolAllocator = &NumaPerNamespaceAllocator{}
+
+// ReleasedPool returns the pool cpus of the container return to, SharedPool unless the allocator keeps
+// other pools.
+func (p *StaticPolicy) ReleasedPool(c Container) string {
+	a, ok := p.allocator.(releasedPoolAllocator)
+	if !ok {
+		return SharedPool
+	}
+	return a.releasedPool(c)
+}
+
+// releasedPool returns the bucket of the container namespace, eg. "namespace-bucket-1".
+func (d *NumaPerNamespaceAllocator) releasedPool(c Container) string {
+	bucket, ok := d.NamespaceToBucket[c.Namespace]
+	if !ok {
+		return SharedPool
+	}
+	return fmt.Sprintf("namespace-bucket-%d", bucket)
+}
+
+// releasedResources describes allocations of the containers before they are deleted, with pools their
+// cpus return to. Shall be called before the containers are deleted, as allocators may release the pools.
+func (d *Daemon) releasedResources(containers []Container) []ctlplaneapi.AllocatedContainerResource {
+	p, _ := d.policy.(ReleasedPoolPolicy)
+	released := make([]ctlplaneapi.AllocatedContainerResource, 0, len(containers))
+	for _, c := range containers {
+		if _, ok := d.state.Allocated[c.CID]; !ok {
+			continue
+		}
+		r := d.state.allocatedContainerResource(c)
+		r.ReleasedPool = SharedPool
+		if p != nil {
+			r.ReleasedPool = p.ReleasedPool(c)
+		}
+		released = append(released, r)
+	}
+	return released
+}
@@ -0,0 +1,55 @@
+package cpudaemon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+func TestDeletePodReturnsReleasedCpus(t *testing.T) {
+	d, m := newDaemonForMigrationTest(t, newNumaAllocatorForMigrationTest)
+	p, _ := createPodForMigration(t, d, m)
+	allocated := []ctlplaneapi.AllocatedContainerResource{}
+	for _, c := range p.containers {
+		allocated = append(allocated, d.state.allocatedContainerResource(c))
+	}
+
+	released, err := d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: p.pid})
+
+	require.Nil(t, err)
+	assert.Equal(t, p.pid, released.PodID)
+	require.Len(t, released.ContainerResources, 2)
+	for i, r := range released.ContainerResources {
+		assert.Equal(t, allocated[i].ContainerID, r.ContainerID)
+		assert.Equal(t, allocated[i].CPUSet, r.CPUSet)
+		assert.True(t, r.Exclusive)
+		assert.Equal(t, SharedPool, r.ReleasedPool)
+	}
+}
+
+func TestDeletePodReturnsNamespaceBucket(t *testing.T) {
+	d, m, a := newDaemonForDryRunTest(t)
+	m.On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	p := createTestPod(1)
+	_, err := d.CreatePod(context.Background(), createPodRequest(p))
+	require.Nil(t, err)
+	bucket := a.NamespaceToBucket[p.namespace]
+
+	released, err := d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: p.pid})
+
+	require.Nil(t, err)
+	require.Len(t, released.ContainerResources, 1)
+	assert.Equal(t, fmt.Sprintf("namespace-bucket-%d", bucket), released.ContainerResources[0].ReleasedPool)
+	assert.NotContains(t, a.NamespaceToBucket, p.namespace, "bucket shall be released after the pool is read")
+}
+
+func TestReleasedPoolWithoutBucket(t *testing.T) {
+	d, _, _ := newDaemonForDryRunTest(t)
+
+	assert.Equal(t, SharedPool, d.policy.(ReleasedPoolPolicy).ReleasedPool(Container{Namespace: "unknown"}))
+}
@@ -0,0 +1,162 @@
+package cpudaemon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+// reloadedTierPrefix prefixes names of policy tiers keeping policies replaced by Reload for pods created
+// before the replacement.
+const reloadedTierPrefix = "reloaded/"
+
+// ReloadConfig is the part of the daemon configuration which can be changed without restart.
+type ReloadConfig struct {
+	Policy       Policy // default policy of new pods, nil keeps the current one
+	ReservedCPUs CPUSet // cpus reserved as with WithReservedCpus, nil keeps the current ones; not allowed with WithKubepodsCpuset
+}
+
+// Reload applies the configuration to the running daemon without dropping allocations kept in the state.
+// Reserved cpus are applied first: cpus reserved anew shall not be in use, cpus no longer reserved become
+// available. The replaced policy keeps managing pods created before the reload until they are deleted,
+// policy tiers sharing it switch to the new policy as well. Tiers of replaced policies are not persisted,
+// pods are managed by the default policy again after restart of the daemon, so policies shall be replaced
+// only by ones able to manage pods of the replaced policy from the state, eg. numa by scatter allocator.
+func (d *Daemon) Reload(config ReloadConfig) error {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+
+	if config.ReservedCPUs != nil && d.options.kubepodsReservedCPUs != nil {
+		return DaemonError{
+			ErrorType:    ConfigurationError,
+			ErrorMessage: "reserved cpus cannot be reloaded while kubepods cpuset is managed by the daemon",
+		}
+	}
+	if config.ReservedCPUs != nil {
+		if err := d.reloadReservedCpus(config.ReservedCPUs); err != nil {
+			return err
+		}
+	}
+	if config.Policy != nil && config.Policy != d.policy {
+		d.replacePolicy(config.Policy)
+	}
+	metrics.ConfigReloads.Inc()
+
+	if err := d.saveState(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// reloadReservedCpus reserves given cpus in addition to cpus reserved by kubelet, which are detected again.
+func (d *Daemon) reloadReservedCpus(reserved CPUSet) error {
+	current := CPUSetFromBucketList(d.state.ReservedCPUs)
+	next, err := d.detectedReservedCpus()
+	if err != nil {
+		return err
+	}
+	next.Merge(reserved)
+	if next.ToCpuString() == current.ToCpuString() {
+		d.options.reservedCPUs = reserved.Clone()
+		return nil
+	}
+	if unknown := next.Clone().RemoveAll(d.state.allCpus()).RemoveAll(current); unknown.Count() > 0 {
+		return DaemonError{
+			ErrorType:    ConfigurationError,
+			ErrorMessage: fmt.Sprintf("reserved cpus %s are not managed by the daemon", unknown),
+		}
+	}
+
+	prev := d.state.ReservedCPUs
+	d.state.ReservedCPUs = nil
+	if next.Count() > 0 {
+		d.state.ReservedCPUs = next.ToCompactBucketList()
+	}
+	t, excluded, err := d.loadTopology()
+	if err != nil {
+		d.state.ReservedCPUs = prev
+		return err
+	}
+	if _, _, missing := d.replaceTopology(t, excluded); missing.Count() > 0 {
+		d.state.ReservedCPUs = prev
+		return DaemonError{
+			ErrorType:    ConfigurationError,
+			ErrorMessage: fmt.Sprintf("cannot reserve cpus %s, they are in use", missing),
+		}
+	}
+	d.options.reservedCPUs = reserved.Clone()
+	d.logger.Info(
+		"reloaded reserved cpus",
+		"reservedCpus", next,
+		"addedCpus", next.Clone().RemoveAll(current),
+		"releasedCpus", current.RemoveAll(next),
+	)
+	return nil
+}
+
+// detectedReservedCpus returns cpus reserved by kubelet, detected as at startup.
+func (d *Daemon) detectedReservedCpus() (CPUSet, error) {
+	gCgroupPath, gCpusetFilePath := d.state.CgroupVersion.cpusetPaths(d.state.CGroupPath)
+	rootCpus, err := getValues(gCgroupPath, gCpusetFilePath)
+	if err != nil {
+		return nil, DaemonError{ErrorType: MissingCgroup, ErrorMessage: err.Error()}
+	}
+	return getReservedCpus(rootCpus, gCgroupPath, gCpusetFilePath), nil
+}
+
+// replacePolicy makes p the default policy. Pods managed by the replaced policy are moved to a new policy
+// tier of the replaced policy, tiers without pods left from previous reloads are removed.
+func (d *Daemon) replacePolicy(p Policy) {
+	d.removeReloadedTiers()
+	prev := d.policy
+	tier := d.reloadedTier()
+	moved := 0
+	for pid, pod := range d.state.Pods {
+		if d.podPolicy(pod) == prev {
+			pod.Tier = tier
+			d.state.Pods[pid] = pod
+			moved++
+		}
+	}
+	for name, policy := range d.tierPolicies {
+		if policy == prev {
+			d.tierPolicies[name] = p
+		}
+	}
+	d.policy = p
+	if moved > 0 {
+		d.tierPolicies[tier] = prev
+	}
+	d.logger.Info("replaced default policy", "podsKeepingPolicy", moved, "tier", tier)
+}
+
+// reloadedTier returns name of a policy tier for the policy replaced by the next reload, which is not
+// configured nor recorded in any pod.
+func (d *Daemon) reloadedTier() string {
+	used := map[string]struct{}{}
+	for _, pod := range d.state.Pods {
+		used[pod.Tier] = struct{}{}
+	}
+	for i := 1; ; i++ {
+		tier := reloadedTierPrefix + strconv.Itoa(i)
+		_, configured := d.tierPolicies[tier]
+		if _, ok := used[tier]; !ok && !configured {
+			return tier
+		}
+	}
+}
+
+// removeReloadedTiers removes policy tiers of replaced policies which no longer manage any pod.
+func (d *Daemon) removeReloadedTiers() {
+	used := map[string]struct{}{}
+	for _, pod := range d.state.Pods {
+		used[pod.Tier] = struct{}{}
+	}
+	for tier := range d.tierPolicies {
+		if _, ok := used[tier]; !ok && strings.HasPrefix(tier, reloadedTierPrefix) {
+			delete(d.tierPolicies, tier)
+		}
+	}
+}
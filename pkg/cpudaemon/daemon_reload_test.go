@@ -0,0 +1,114 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// renamedPod returns the test pod with given pod id prefixing ids of its containers.
+func renamedPod(p PodMetaData, pid string) PodMetaData {
+	p.pid = pid
+	for i := range p.containers {
+		p.containers[i].PID = pid
+		p.containers[i].CID = pid + "-" + p.containers[i].CID
+		p.containersResources[i].ContainerId = p.containers[i].CID
+	}
+	return p
+}
+
+func TestReloadKeepsPolicyOfExistingPods(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)))
+	numa := d.policy
+	existing := podWithPlacement(ctlplaneapi.Placement_DEFAULT)
+	createPodForRepack(t, d, existing)
+
+	require.Nil(t, d.Reload(ReloadConfig{Policy: NewObservePolicy()}))
+	created := renamedPod(podWithPlacement(ctlplaneapi.Placement_DEFAULT), "created")
+	createPodForRepack(t, d, created)
+
+	assert.Equal(t, "reloaded/1", d.state.Pods[existing.pid].Tier)
+	assert.True(t, d.policyFor(existing.containers[0]) == numa)
+	assert.Empty(t, d.state.Pods[created.pid].Tier)
+	assert.Len(t, d.state.Allocated, 2, "only containers of the existing pod have exclusive cpus")
+
+	_, err := d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: existing.pid})
+	require.Nil(t, err)
+	assert.Empty(t, d.state.Allocated, "cpus are freed by the replaced policy")
+
+	require.Nil(t, d.Reload(ReloadConfig{Policy: numa}))
+	assert.Equal(t, "reloaded/1", d.state.Pods[created.pid].Tier)
+	assert.Len(t, d.tierPolicies, 1, "tiers of replaced policies without pods are removed")
+}
+
+func TestReloadSwitchesTiersSharingThePolicy(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)))
+	d.tierPolicies = map[string]Policy{"prod": d.policy}
+	observe := NewObservePolicy()
+
+	require.Nil(t, d.Reload(ReloadConfig{Policy: observe}))
+
+	assert.True(t, d.tierPolicies["prod"] == observe)
+	assert.Len(t, d.tierPolicies, 1, "no tier is added without pods of the replaced policy")
+}
+
+func TestReloadReservedCpus(t *testing.T) {
+	d, _ := newDaemonForTopologyReloadTest(t)
+	reserved, err := CPUSetFromString("3")
+	require.Nil(t, err)
+
+	require.Nil(t, d.Reload(ReloadConfig{ReservedCPUs: reserved}))
+
+	assert.Equal(t, "3", CPUSetFromBucketList(d.state.ReservedCPUs).ToCpuString())
+	assert.Equal(t, "0,1,2", topologyCpus(&d.state.Topology).ToCpuString())
+	assert.False(t, CPUSetFromBucketList(d.state.AvailableCPUs).Contains(3))
+	assert.Equal(t, "3", CPUSetFromBucketList(d.readableState().ReservedCPUs).ToCpuString())
+
+	require.Nil(t, d.Reload(ReloadConfig{ReservedCPUs: CPUSet{}}))
+
+	assert.Empty(t, d.state.ReservedCPUs)
+	assert.Equal(t, "0,1,2,3", topologyCpus(&d.state.Topology).ToCpuString())
+	assert.True(t, CPUSetFromBucketList(d.state.AvailableCPUs).Contains(3))
+}
+
+func TestReloadRefusesReservingCpusInUse(t *testing.T) {
+	d, _ := newDaemonForTopologyReloadTest(t)
+	pool, err := d.ReserveCPUPool(
+		context.Background(),
+		&ctlplaneapi.ReserveCPUPoolRequest{Name: "dpdk", Cpus: 1, NumaNodes: []uint32{1}},
+	)
+	require.Nil(t, err)
+
+	err = d.Reload(ReloadConfig{ReservedCPUs: CPUSetFromBucketList(pool.CPUSet)})
+
+	require.NotNil(t, err)
+	assert.Equal(t, ConfigurationError, err.(DaemonError).ErrorType)
+	assert.Empty(t, d.state.ReservedCPUs)
+	assert.Equal(t, "0,1,2,3", topologyCpus(&d.state.Topology).ToCpuString())
+}
+
+func TestReloadRefusesReservedCpusWithKubepodsCpuset(t *testing.T) {
+	d, _ := newDaemonForTopologyReloadTest(t)
+	d.options.kubepodsReservedCPUs = CPUSet{0: {}}
+
+	err := d.Reload(ReloadConfig{ReservedCPUs: CPUSet{}})
+
+	require.NotNil(t, err)
+	assert.Equal(t, ConfigurationError, err.(DaemonError).ErrorType)
+	assert.Nil(t, d.Reload(ReloadConfig{}))
+}
+
+func TestReloadRefusesUnknownReservedCpus(t *testing.T) {
+	d, _ := newDaemonForTopologyReloadTest(t)
+	reserved, err := CPUSetFromString("1000")
+	require.Nil(t, err)
+
+	err = d.Reload(ReloadConfig{ReservedCPUs: reserved})
+
+	require.NotNil(t, err)
+	assert.Equal(t, ConfigurationError, err.(DaemonError).ErrorType)
+	assert.Empty(t, d.state.ReservedCPUs)
+}
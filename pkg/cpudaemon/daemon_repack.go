@@ -0,0 +1,118 @@
+package cpudaemon
+
+import (
+	"context"
+	"time"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// repackPod changes placement of the pod and allocates exclusive cpus of its guaranteed containers
+// again under the new placement. Either all containers are repacked, or the pod keeps its previous
+// placement and cpus.
+func (d *Daemon) repackPod(ctx context.Context, pod *PodMetadata, placement ctlplaneapi.Placement) error {
+	containers := []Container{}
+	for _, c := range allocatedContainers(*pod) {
+		if c.QS == Guaranteed {
+			containers = append(containers, c)
+		}
+	}
+	d.logger.Info("repacking pod", "pid", pod.PID, "from", pod.Placement, "to", placement)
+	repacked := *pod
+	repacked.Placement = placement
+	return d.reassignPod(ctx, pod, repacked, containers, false)
+}
+
+// repinPodMemory changes memory pinning of the pod and allocates its containers again, preferring
+// their current cpus, so that allocators set memory nodes of the containers under the new setting.
+// Either all containers are updated, or the pod keeps its previous setting and cpus.
+func (d *Daemon) repinPodMemory(ctx context.Context, pod *PodMetadata, pinning ctlplaneapi.MemoryPinning) error {
+	d.logger.Info("changing memory pinning of pod", "pid", pod.PID, "from", pod.MemoryPinning, "to", pinning)
+	repinned := *pod
+	repinned.MemoryPinning = pinning
+	return d.reassignPod(ctx, pod, repinned, allocatedContainers(*pod), true)
+}
+
+// reassignPod replaces metadata of the pod with the changed one and assigns the containers again.
+// Containers keep their cpus where allocators can, if keepCpus is set. On failure the previous
+// metadata and cpus of the pod are restored.
+func (d *Daemon) reassignPod(
+	ctx context.Context,
+	pod *PodMetadata,
+	changed PodMetadata,
+	containers []Container,
+	keepCpus bool,
+) error {
+	previous := *pod
+	previousCpus := make(map[string]CPUSet, len(containers))
+	previousAllocatedAt := make(map[string]time.Time, len(containers))
+	for _, c := range containers {
+		previousCpus[c.CID] = CPUSetFromBucketList(d.state.Allocated[c.CID])
+		previousAllocatedAt[c.CID] = d.state.getAllocatedAt(c.CID)
+	}
+
+	d.freeContainers(containers)
+	d.setPodMetadata(pod, changed)
+	if keepCpus {
+		for _, c := range containers {
+			d.state.setAllocationHint(c.CID, previousCpus[c.CID])
+		}
+	}
+	assigned, err := d.assignContainers(ctx, containers)
+	if err == nil {
+		now := time.Now()
+		for _, c := range containers {
+			d.state.clearAllocationHint(c.CID)
+			if CPUSetFromBucketList(d.state.Allocated[c.CID]).ToCpuString() != previousCpus[c.CID].ToCpuString() {
+				d.state.setAllocatedAt(c.CID, now)
+			}
+		}
+		return nil
+	}
+
+	d.logger.Error(err, "cannot reassign pod, restoring previous settings", "pid", pod.PID)
+	d.freeContainers(assigned)
+	d.setPodMetadata(pod, previous)
+	for _, c := range containers {
+		d.state.setAllocationHint(c.CID, previousCpus[c.CID])
+	}
+	// the previous cpus are restored also when the request was canceled
+	if _, restoreErr := d.assignContainers(context.Background(), containers); restoreErr != nil {
+		d.logger.Error(restoreErr, "cannot restore previous cpus of the pod", "pid", pod.PID)
+	}
+	for _, c := range containers {
+		d.state.clearAllocationHint(c.CID)
+		d.state.setAllocatedAt(c.CID, previousAllocatedAt[c.CID])
+	}
+	return err
+}
+
+func (d *Daemon) setPodMetadata(pod *PodMetadata, changed PodMetadata) {
+	*pod = changed
+	d.state.Pods[pod.PID] = *pod
+}
+
+// freeContainers returns cpus of the containers to the pool, without changing their cpusets.
+func (d *Daemon) freeContainers(containers []Container) {
+	ctx := context.Background()
+	for _, c := range containers {
+		if err := d.policyFor(c).DeleteContainer(ctx, c, &d.state); err != nil {
+			d.logger.Error(err, "failed to free container resources", "cid", c.CID)
+		}
+		delete(d.state.Allocated, c.CID)
+	}
+}
+
+// assignContainers assigns cpus to the containers, biggest first, and stops at the first failure.
+// Successfully assigned containers are returned.
+func (d *Daemon) assignContainers(ctx context.Context, containers []Container) ([]Container, error) {
+	assigned := []Container{}
+	for _, c := range sortedBySize(containers) {
+		err := d.assignContainer(ctx, c)
+		if err != nil {
+			return assigned, err
+		}
+		assigned = append(assigned, c)
+	}
+	return assigned, nil
+}
@@ -0,0 +1,136 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// podWithPlacement returns test pod with two single cpu containers and given placement.
+func podWithPlacement(placement ctlplaneapi.Placement) PodMetaData {
+	p := createTestPod(2)
+	p.resources.CpuAffinity = placement
+	for _, c := range p.containersResources {
+		c.Resources.RequestedCpus, c.Resources.LimitCpus = 1, 1
+	}
+	for i := range p.containers {
+		p.containers[i].Cpus = 1
+	}
+	return p
+}
+
+// containerNodes returns numa nodes hosting cpus of pod containers.
+func containerNodes(t *testing.T, d *Daemon, p PodMetaData) []int {
+	nodes := []int{}
+	for _, c := range p.containers {
+		buckets := d.state.Allocated[c.CID]
+		require.Len(t, buckets, 1)
+		nodes = append(nodes, d.state.Topology.CpuInformation[buckets[0].StartCPU].Node)
+	}
+	return nodes
+}
+
+func createPodForRepack(t *testing.T, d *Daemon, p PodMetaData) {
+	_, err := d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+			Containers:   p.containersResources,
+		},
+	)
+	require.Nil(t, err)
+}
+
+func updatePodPlacement(d *Daemon, p PodMetaData, placement ctlplaneapi.Placement) error {
+	p.resources.CpuAffinity = placement
+	_, err := d.UpdatePod(
+		context.Background(),
+		&ctlplaneapi.UpdatePodRequest{
+			PodId:      p.pid,
+			Resources:  p.resources,
+			Containers: p.containersResources,
+		},
+	)
+	return err
+}
+
+func TestCreatePodUsesPodPlacement(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)))
+	p := podWithPlacement(ctlplaneapi.Placement_SCATTER)
+
+	createPodForRepack(t, d, p)
+
+	nodes := containerNodes(t, d, p)
+	assert.NotEqual(t, nodes[0], nodes[1])
+	assert.Equal(t, ctlplaneapi.Placement_SCATTER, d.state.Pods[p.pid].Placement)
+}
+
+func TestUpdatePodRepacksCompactToScatter(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)))
+	p := podWithPlacement(ctlplaneapi.Placement_COMPACT)
+	createPodForRepack(t, d, p)
+	nodes := containerNodes(t, d, p)
+	require.Equal(t, nodes[0], nodes[1])
+
+	require.Nil(t, updatePodPlacement(d, p, ctlplaneapi.Placement_SCATTER))
+
+	nodes = containerNodes(t, d, p)
+	assert.NotEqual(t, nodes[0], nodes[1])
+	assert.Equal(t, ctlplaneapi.Placement_SCATTER, d.state.Pods[p.pid].Placement)
+	assert.Len(t, d.state.Pods[p.pid].Containers, 2)
+}
+
+func TestUpdatePodRepacksScatterToCompact(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)))
+	p := podWithPlacement(ctlplaneapi.Placement_SCATTER)
+	createPodForRepack(t, d, p)
+
+	require.Nil(t, updatePodPlacement(d, p, ctlplaneapi.Placement_COMPACT))
+
+	nodes := containerNodes(t, d, p)
+	assert.Equal(t, nodes[0], nodes[1])
+	assert.Equal(t, ctlplaneapi.Placement_COMPACT, d.state.Pods[p.pid].Placement)
+}
+
+func TestUpdatePodKeepsPlacementIfRepackFails(t *testing.T) {
+	m := MockedPolicy{}
+	d := newDaemonWithLeasedPod(t, &m, createTestPod(2), 0)
+	p := createTestPod(2)
+	cpusErr := DaemonError{ErrorType: CpusNotAvailable}
+	for _, c := range p.containers {
+		m.On("DeleteContainer", c, &d.state).Return(nil).Once()
+	}
+	// biggest container is repacked first, the second one fails
+	m.On("AssignContainer", p.containers[1], &d.state).Return(nil).Once()
+	m.On("AssignContainer", p.containers[0], &d.state).Return(cpusErr).Once()
+	// and previous cpus are restored
+	m.On("DeleteContainer", p.containers[1], &d.state).Return(nil).Once()
+	m.On("AssignContainer", p.containers[1], &d.state).Return(nil).Once()
+	m.On("AssignContainer", p.containers[0], &d.state).Return(nil).Once()
+	allocatedAt := d.state.getAllocatedAt(p.containers[0].CID)
+
+	err := updatePodPlacement(d, p, ctlplaneapi.Placement_SCATTER)
+
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, CpusNotAvailable, daemonErr.ErrorType)
+	m.AssertExpectations(t)
+	assert.Equal(t, ctlplaneapi.Placement_COMPACT, d.state.Pods[p.pid].Placement)
+	assert.Equal(t, allocatedAt, d.state.getAllocatedAt(p.containers[0].CID))
+	assert.Empty(t, d.state.allocationHints)
+}
+
+func TestPodPlacementPipeline(t *testing.T) {
+	defaultPipeline := PlacementPipeline{Scorers: []WeightedScorer{{Scorer: FreeCpusScorer{}, Weight: 7}}}
+
+	assert.Equal(t, defaultPipeline, podPlacementPipeline(ctlplaneapi.Placement_DEFAULT, defaultPipeline))
+	assert.Equal(t, defaultPipeline, podPlacementPipeline(ctlplaneapi.Placement_POOL, defaultPipeline))
+	assert.Equal(t, CompactPlacementPipeline(), podPlacementPipeline(ctlplaneapi.Placement_COMPACT, defaultPipeline))
+	assert.Equal(t, ScatterPlacementPipeline(), podPlacementPipeline(ctlplaneapi.Placement_SCATTER, defaultPipeline))
+}
@@ -0,0 +1,136 @@
+package cpudaemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/numautils"
+)
+
+// ScatterAllocator spreads exclusive cpus of each guaranteed container evenly across topology nodes
+// (eg. numa nodes or dies), so that the container can use memory bandwidth of all of them. Cpus are
+// distributed round robin across nodes with free cpus, and taken from each node with minimal topology
+// distance. Pods requesting COMPACT placement get cpus of a single node instead, as
+// with the numa allocator.
+type ScatterAllocator struct {
+	numa NumaAwareAllocator
+}
+
+var _ Allocator = &ScatterAllocator{}
+
+// NewScatterAllocator creates new scatter allocator.
+func NewScatterAllocator(cgroupController CgroupController, memoryPinning bool) *ScatterAllocator {
+	return &ScatterAllocator{numa: *NewNumaAwareAllocatorWithPlacement(
+		cgroupController,
+		memoryPinning,
+		CompactPlacementPipeline(),
+	)}
+}
+
+// scatterNodes returns topology nodes cpus are spread across: children of the highest topology node
+// with more than one child.
+func scatterNodes(root *numautils.TopologyNode) []*numautils.TopologyNode {
+	node := root
+	for len(node.Children) == 1 && !node.Children[0].IsLeaf() {
+		node = node.Children[0]
+	}
+	return node.Children
+}
+
+// scatterCounts returns number of cpus taken from each node. Cpus are given one by one to the node with
+// free cpus which got the fewest of them, the node with the most free cpus left on ties.
+func scatterCounts(nodes []*numautils.TopologyNode, n int) ([]int, error) {
+	counts := make([]int, len(nodes))
+	for i := 0; i < n; i++ {
+		best := -1
+		for j, node := range nodes {
+			free := node.NumAvailable - counts[j]
+			if free == 0 {
+				continue
+			}
+			if best < 0 || counts[j] < counts[best] ||
+				counts[j] == counts[best] && free > nodes[best].NumAvailable-counts[best] {
+				best = j
+			}
+		}
+		if best < 0 {
+			return nil, numautils.ErrNotAvailable
+		}
+		counts[best]++
+	}
+	return counts, nil
+}
+
+// takeScatteredCpus takes n cpus spread across nodes and returns them with description of the spread.
+func takeScatteredCpus(t *numautils.NumaTopology, n int) ([]int, string, error) {
+	nodes := scatterNodes(t.Topology)
+	counts, err := scatterCounts(nodes, n)
+	if err != nil {
+		return nil, "", err
+	}
+	cpuIds := make([]int, 0, n)
+	spread := []string{}
+	for i, node := range nodes {
+		if counts[i] == 0 {
+			continue
+		}
+		taken, err := t.TakeFrom(node, counts[i])
+		if err != nil {
+			for _, cpu := range cpuIds {
+				_ = t.Return(cpu)
+			}
+			return nil, "", err
+		}
+		cpuIds = append(cpuIds, taken...)
+		spread = append(spread, fmt.Sprintf("%s (%s)", describeNode(node), countCpus(counts[i])))
+	}
+	return cpuIds, strings.Join(spread, ", "), nil
+}
+
+func (d *ScatterAllocator) takeCpus(ctx context.Context, c Container, s *DaemonState) error {
+	if !takesExclusiveCpus(c) {
+		s.setAllocationExplanation(c.CID, fmt.Sprintf(sharedPoolExplanation, "scatter"))
+		return nil
+	}
+	if s.Pods[c.PID].Placement == ctlplaneapi.Placement_COMPACT {
+		if err := d.numa.takeCpus(ctx, c, s); err != nil {
+			return err
+		}
+		explanation := strings.TrimPrefix(s.getAllocationExplanation(c.CID), "numa allocator: ")
+		s.setAllocationExplanation(c.CID, "scatter allocator: "+explanation)
+		return nil
+	}
+
+	cpuIds, spread, err := takeScatteredCpus(&s.Topology, c.Cpus)
+	if err != nil {
+		return DaemonError{
+			ErrorType:    CpusNotAvailable,
+			ErrorMessage: err.Error(),
+		}
+	}
+	cpus := CPUSet{}
+	for _, cpu := range cpuIds {
+		cpus.Add(cpu)
+	}
+	s.Allocated[c.CID] = append(s.Allocated[c.CID], cpus.ToBucketList()...)
+	s.setAllocationExplanation(c.CID, fmt.Sprintf("scatter allocator: %s spread across %s", countCpus(c.Cpus), spread))
+
+	return updateContainerCPUSet(
+		ctx,
+		d.numa.ctrl,
+		s,
+		c,
+		cpus.ToCpuString(),
+		getMemoryPinningIfEnabled(isMemoryPinningEnabled(d.numa.memoryPinning, c, s), &s.Topology, cpuIds),
+	)
+}
+
+func (d *ScatterAllocator) freeCpus(ctx context.Context, c Container, s *DaemonState) error {
+	return d.numa.freeCpus(ctx, c, s)
+}
+
+func (d *ScatterAllocator) clearCpus(ctx context.Context, c Container, s *DaemonState) error {
+	return d.numa.clearCpus(ctx, c, s)
+}
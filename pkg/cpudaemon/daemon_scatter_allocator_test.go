@@ -0,0 +1,100 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/numautils/testtopo"
+)
+
+// newScatterTestState returns state of two sockets with four cpus each: cpus 0-3 and 4-7.
+func newScatterTestState(t *testing.T) *DaemonState {
+	s := getTestDaemonState(t.TempDir(), 1)
+	topology, err := testtopo.Spec{Sockets: 2, DiesPerSocket: 1, CoresPerDie: 4, ThreadsPerCore: 1}.Topology()
+	require.Nil(t, err)
+	s.Topology = topology
+	return s
+}
+
+func newMockedScatterAllocator() (*ScatterAllocator, *CgroupsMock) {
+	m := CgroupsMock{}
+	m.On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return NewScatterAllocator(&m, false), &m
+}
+
+func TestScatterTakeCpusSpreadsAcrossNodes(t *testing.T) {
+	s := newScatterTestState(t)
+	allocator, m := newMockedScatterAllocator()
+	container := baseContainer(1)
+	container.Cpus = 4
+
+	require.Nil(t, allocator.takeCpus(context.Background(), container, s))
+
+	assertCpuState(t, s, &container, "0,1,4,5")
+	assert.Equal(
+		t,
+		"scatter allocator: 4 cpus spread across node 0 (2 cpus), node 1 (2 cpus)",
+		s.getAllocationExplanation(container.CID),
+	)
+	m.AssertCalled(t, "UpdateCPUSet", s.CGroupPath, container, "0,1,4,5", "")
+}
+
+func TestScatterTakeCpusBalancesContainerAcrossNodes(t *testing.T) {
+	s := newScatterTestState(t)
+	allocator, _ := newMockedScatterAllocator()
+	for _, cpu := range []int{0, 1, 2} {
+		require.Nil(t, s.Topology.TakeCpu(cpu))
+	}
+	container := baseContainer(1)
+	container.Cpus = 3
+
+	require.Nil(t, allocator.takeCpus(context.Background(), container, s))
+
+	assertCpuState(t, s, &container, "3,4,5")
+}
+
+func TestScatterTakeCpusOfCompactPodFromSingleNode(t *testing.T) {
+	s := newScatterTestState(t)
+	allocator, _ := newMockedScatterAllocator()
+	container := baseContainer(1)
+	container.Cpus = 3
+	pod := s.Pods[container.PID]
+	pod.Placement = ctlplaneapi.Placement_COMPACT
+	s.Pods[container.PID] = pod
+
+	require.Nil(t, allocator.takeCpus(context.Background(), container, s))
+
+	cpus := CPUSetFromBucketList(s.Allocated[container.CID])
+	assert.Equal(t, 3, cpus.Count())
+	assert.NotEqual(t, "machine", describeNode(lowestCommonNode(s.Topology.Topology, cpus)))
+	assert.Contains(t, s.getAllocationExplanation(container.CID), "scatter allocator: compact placement selected")
+}
+
+func TestScatterTakeCpusFailsIfTooManyCpus(t *testing.T) {
+	s := newScatterTestState(t)
+	allocator, _ := newMockedScatterAllocator()
+	container := baseContainer(1)
+	container.Cpus = 9
+
+	err := allocator.takeCpus(context.Background(), container, s)
+
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, CpusNotAvailable, daemonErr.ErrorType)
+	assert.Empty(t, s.Allocated)
+	assert.Equal(t, 8, topologyAvailableCpus(&s.Topology).Count())
+}
+
+func TestScatterAllocatorConfig(t *testing.T) {
+	allocator := NewScatterAllocator(&CgroupsMock{}, true)
+
+	config := allocator.config(nil)
+
+	assert.Equal(t, "scatter", config.Name)
+	assert.True(t, config.Exclusive)
+	assert.True(t, config.MemoryPinning)
+}
@@ -0,0 +1,64 @@
+package cpudaemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+var procSelfTask = "/proc/self/task"
+
+// PinProcess sets cpu affinity of all threads of the current process to given cpus, so that the
+// management plane does not compete with exclusively pinned workloads. Threads started later inherit
+// the affinity. Threads are listed until no unpinned one is found, as the runtime may start new ones
+// while the affinity is being set.
+func PinProcess(cpus CPUSet) error {
+	if cpus.Count() == 0 {
+		return errors.New("no cpus to pin the process to")
+	}
+	set := unix.CPUSet{}
+	for cpu := range cpus {
+		set.Set(cpu)
+	}
+	pinned := map[int]struct{}{}
+	for {
+		tids, err := processThreads()
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, tid := range tids {
+			if _, ok := pinned[tid]; ok {
+				continue
+			}
+			found = true
+			err := unix.SchedSetaffinity(tid, &set)
+			if err != nil && !errors.Is(err, unix.ESRCH) { // thread exited in the meantime
+				return fmt.Errorf("cannot set affinity of thread %d to cpus %s: %w", tid, cpus, err)
+			}
+			pinned[tid] = struct{}{}
+		}
+		if !found {
+			return nil
+		}
+	}
+}
+
+func processThreads() ([]int, error) {
+	entries, err := os.ReadDir(procSelfTask)
+	if err != nil {
+		return nil, err
+	}
+	tids := make([]int, 0, len(entries))
+	for _, e := range entries {
+		tid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		tids = append(tids, tid)
+	}
+	return tids, nil
+}
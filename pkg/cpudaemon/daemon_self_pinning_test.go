@@ -0,0 +1,45 @@
+package cpudaemon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func currentAffinity(t *testing.T, tid int) CPUSet {
+	set := unix.CPUSet{}
+	require.Nil(t, unix.SchedGetaffinity(tid, &set))
+	cpus := CPUSet{}
+	for cpu := 0; cpu < len(set)*64; cpu++ {
+		if set.IsSet(cpu) {
+			cpus.Add(cpu)
+		}
+	}
+	return cpus
+}
+
+func TestPinProcess(t *testing.T) {
+	allowed := currentAffinity(t, 0)
+	defer func() { require.Nil(t, PinProcess(allowed)) }()
+	pinned := CPUSet{}
+	pinned.Add(allowed.Sorted()[0])
+
+	require.Nil(t, PinProcess(pinned))
+
+	tids, err := processThreads()
+	require.Nil(t, err)
+	require.NotEmpty(t, tids)
+	for _, tid := range tids {
+		assert.Equal(t, pinned, currentAffinity(t, tid), tid)
+	}
+}
+
+func TestPinProcessFails(t *testing.T) {
+	assert.NotNil(t, PinProcess(CPUSet{}))
+
+	procSelfTask = "/nonexistent"
+	defer func() { procSelfTask = "/proc/self/task" }()
+	assert.NotNil(t, PinProcess(currentAffinity(t, 0)))
+}
@@ -0,0 +1,73 @@
+package cpudaemon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// sharedPoolCgroups lists parent cgroups of besteffort and burstable pods: with systemd and cgroupfs
+// drivers, and inside kind nodes.
+var sharedPoolCgroups = []string{
+	"kubepods.slice/kubepods-besteffort.slice",
+	"kubepods.slice/kubepods-burstable.slice",
+	"kubepods/besteffort",
+	"kubepods/burstable",
+	"kubelet/kubepods/besteffort",
+	"kubelet/kubepods/burstable",
+}
+
+// sharedPool returns managed cpus which are neither exclusively allocated to guaranteed containers, nor
+// assigned by kubelet cpu manager, nor reserved in cpu pools.
+func (d *DaemonState) sharedPool() CPUSet {
+	cpus := CPUSet{}
+	for _, leaf := range d.Topology.Topology.GetLeafs() {
+		cpus.Add(leaf.Value)
+	}
+	return cpus.RemoveAll(d.exclusiveCpus()).RemoveAll(CPUSetFromBucketList(d.KubeletCPUs)).RemoveAll(d.cpuPoolCpus())
+}
+
+// checkSharedPoolSupport disables shared pool cgroups on cgroups v1, where cpuset of a parent cgroup
+// cannot be shrunk below cpusets of its children.
+func (d *Daemon) checkSharedPoolSupport() {
+	if d.options.sharedPoolCgroups && !d.state.CgroupVersion.unified() {
+		d.logger.Info("shared pool cgroups are supported only with cgroups v2, parent cgroups are not updated")
+		d.options.sharedPoolCgroups = false
+	}
+}
+
+// applySharedPool restricts cpusets of besteffort and burstable parent cgroups to the shared pool
+// whenever it changes, so that containers of these pods (including unmanaged ones) never run on
+// exclusive cpus. Empty pool is not applied, as empty cpuset makes the cgroup inherit all cpus of its
+// parent. Failures are logged only, as they do not affect allocations.
+func (d *Daemon) applySharedPool() {
+	if !d.options.sharedPoolCgroups {
+		return
+	}
+	cpus := d.state.sharedPool()
+	if cpus.Count() == 0 {
+		d.logger.Info("shared pool is empty, parent cgroups are not updated")
+		return
+	}
+	if d.appliedSharedPool != nil && d.appliedSharedPool.ToCpuString() == cpus.ToCpuString() {
+		return
+	}
+
+	var failed bool
+	for _, cgroup := range sharedPoolCgroups {
+		cpusetPath := filepath.Join(d.state.CGroupPath, cgroup, "cpuset.cpus")
+		err := os.WriteFile(cpusetPath, []byte(cpus.ToCpuString()), os.FileMode(0))
+		if errors.Is(err, os.ErrNotExist) {
+			continue // cgroup layout of another driver
+		}
+		if err != nil {
+			d.logger.Error(err, "cannot apply shared pool", "cgroup", cgroup)
+			failed = true
+		}
+	}
+	if failed {
+		return // retried on the next state change
+	}
+	d.appliedSharedPool = cpus
+	d.logger.Info("shared pool applied", "cpus", cpus)
+}
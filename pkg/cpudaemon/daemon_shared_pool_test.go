@@ -0,0 +1,85 @@
+package cpudaemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+func newDaemonForSharedPool(t *testing.T) *Daemon {
+	dir := t.TempDir()
+	for _, cgroup := range []string{"kubepods/besteffort", "kubepods/burstable"} {
+		require.Nil(t, os.MkdirAll(filepath.Join(dir, cgroup), 0o755))
+		require.Nil(t, os.WriteFile(filepath.Join(dir, cgroup, "cpuset.cpus"), []byte{}, 0o600))
+	}
+	s := guaranteedAndBurstablePodState()
+	s.KubeletCPUs = []ctlplaneapi.CPUBucket{{StartCPU: 7, EndCPU: 7}}
+	s.Topology = oneLevelTopology(8)
+	s.CGroupPath = dir
+	return &Daemon{
+		state:   s,
+		logger:  logr.Discard(),
+		options: newDaemonOptions([]Option{WithSharedPoolCgroups()}),
+	}
+}
+
+func readSharedPoolForTest(t *testing.T, d *Daemon, cgroup string) string {
+	b, err := os.ReadFile(filepath.Join(d.state.CGroupPath, cgroup, "cpuset.cpus"))
+	require.Nil(t, err)
+	return string(b)
+}
+
+func TestSharedPool(t *testing.T) {
+	d := newDaemonForSharedPool(t)
+
+	assert.Equal(t, "0,3,4,5,6", d.state.sharedPool().ToCpuString())
+}
+
+func TestApplySharedPool(t *testing.T) {
+	d := newDaemonForSharedPool(t)
+
+	d.applySharedPool()
+
+	assert.Equal(t, "0,3,4,5,6", readSharedPoolForTest(t, d, "kubepods/besteffort"))
+	assert.Equal(t, "0,3,4,5,6", readSharedPoolForTest(t, d, "kubepods/burstable"))
+	assert.NoDirExists(t, filepath.Join(d.state.CGroupPath, "kubepods.slice"))
+
+	delete(d.state.Allocated, "guaranteed")
+	d.applySharedPool()
+
+	assert.Equal(t, "0,1,2,3,4,5,6", readSharedPoolForTest(t, d, "kubepods/burstable"))
+}
+
+func TestApplySharedPoolWritesOnlyChanges(t *testing.T) {
+	d := newDaemonForSharedPool(t)
+	d.applySharedPool()
+	require.Nil(t, os.WriteFile(filepath.Join(d.state.CGroupPath, "kubepods/burstable", "cpuset.cpus"), []byte{}, 0o600))
+
+	d.applySharedPool()
+
+	assert.Empty(t, readSharedPoolForTest(t, d, "kubepods/burstable"))
+}
+
+func TestApplySharedPoolSkipsEmptyPool(t *testing.T) {
+	d := newDaemonForSharedPool(t)
+	d.state.Allocated["guaranteed"] = []ctlplaneapi.CPUBucket{{StartCPU: 0, EndCPU: 6}}
+
+	d.applySharedPool()
+
+	assert.Empty(t, readSharedPoolForTest(t, d, "kubepods/burstable"))
+	assert.Nil(t, d.appliedSharedPool)
+}
+
+func TestApplySharedPoolDisabled(t *testing.T) {
+	d := newDaemonForSharedPool(t)
+	d.options = newDaemonOptions(nil)
+
+	d.applySharedPool()
+
+	assert.Empty(t, readSharedPoolForTest(t, d, "kubepods/burstable"))
+}
@@ -0,0 +1,43 @@
+package cpudaemon
+
+// RequestSizedPolicy is implemented by policies whose allocations of containers without exclusive cpus
+// depend on their request, eg. burstable containers soft pinned to cpus sized to their request.
+type RequestSizedPolicy interface {
+	SizedByRequest(c Container) bool
+}
+
+var _ RequestSizedPolicy = &StaticPolicy{}
+
+// requestSizedAllocator is implemented by allocators sizing cpus or cpu weight of shared containers by
+// their request.
+type requestSizedAllocator interface {
+	sizedByRequest(c Container) bool
+}
+
+var _ requestSizedAllocator = &NumaPerNamespaceAllocator{}
+
+// SizedByRequest checks if the allocation of the container changes with its request, false unless the
+// allocator sizes shared containers by their request.
+func (p *StaticPolicy) SizedByRequest(c Container) bool {
+	a, ok := p.allocator.(requestSizedAllocator)
+	return ok && a.sizedByRequest(c)
+}
+
+// sizedByRequest checks if the container without exclusive cpus is soft pinned or weighted by its request.
+func (d *NumaPerNamespaceAllocator) sizedByRequest(c Container) bool {
+	if takesExclusiveCpus(c) {
+		return false
+	}
+	_, weighted := d.namespaceWeights[c.Namespace]
+	return d.softPinned(c) || weighted || d.bucketWeights
+}
+
+// resizesSharedContainer checks if the change of request of a container without exclusive cpus affects
+// its allocation, so that the container shall be reallocated even though requiresReallocation says no.
+func (d *Daemon) resizesSharedContainer(current, wanted Container) bool {
+	if current.Cpus == wanted.Cpus || current.QS == Guaranteed || wanted.QS == Guaranteed {
+		return false
+	}
+	p, ok := d.policyFor(current).(RequestSizedPolicy)
+	return ok && (p.SizedByRequest(current) || p.SizedByRequest(wanted))
+}
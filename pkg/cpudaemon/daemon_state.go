@@ -1,13 +1,15 @@
 package cpudaemon
 
 import (
-	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"time"
 
-	"github.com/containerd/cgroups"
 	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
 	"resourcemanagement.controlplane/pkg/numautils"
 	"resourcemanagement.controlplane/pkg/utils"
 )
@@ -16,33 +18,53 @@ const daemonFilePermission = 0600
 
 // DaemonState struct holding the current daemon state.
 type DaemonState struct {
-	AvailableCPUs []ctlplaneapi.CPUBucket            // Used ony with default allocator
-	Allocated     map[string][]ctlplaneapi.CPUBucket // Maps container id to allocated cpus
-	Pods          map[string]PodMetadata             // Maps pod id to its metadata
-	Topology      numautils.NumaTopology             // Used with numa and numa-namespace allocators
-	CGroupPath    string                             // Path to cgroup main folder (usually /sys/fs/cgroup)
-	StatePath     string                             // Path to state file where DaemonState is marshalled/unmarshalled
+	AvailableCPUs    []ctlplaneapi.CPUBucket            // Used ony with default allocator
+	Allocated        map[string][]ctlplaneapi.CPUBucket // Maps container id to allocated cpus
+	Pods             map[string]PodMetadata             // Maps pod id to its metadata
+	Topology         numautils.NumaTopology             // Used with numa and numa-namespace allocators
+	CGroupPath       string                             // Path to cgroup main folder (usually /sys/fs/cgroup)
+	StatePath        string                             // Path to state file where DaemonState is marshalled/unmarshalled
+	ExcludedCPUs     []ctlplaneapi.CPUBucket            // Cpus removed from daemon management
+	ReservedCPUs     []ctlplaneapi.CPUBucket            // Cpus outside of kubepods cgroup, reserved by kubelet or the daemon
+	ManagedCPUs      []ctlplaneapi.CPUBucket            // Cpus managed by this daemon instance, empty if all
+	KubeletCPUs      []ctlplaneapi.CPUBucket            // Cpus exclusively assigned by kubelet cpu manager
+	AllocatedAt      map[string]time.Time               // Maps container id to time of its cpus allocation
+	CgroupPaths      map[string]string                  // Maps container id to cgroup path written by the last update
+	CgroupVersion    CgroupVersion                      // Cgroup version detected at startup
+	Borrowed         map[string][]ctlplaneapi.CPUBucket // Maps container id to exclusive cpus it borrowed, included in Allocated
+	Explanations     map[string]string                  // Maps container id to explanation of its last allocation
+	MemoryNodes      map[string]string                  // Maps container id to memory nodes set by the last allocation
+	CPUPools         map[string]CPUPool                 // Maps name of cpu pool reserved outside of kubernetes to its cpus
+	EffectiveCPUs    map[string]string                  // Maps container id to cpus read back from its cgroup after the last update
+	NamespaceConfigs map[string]NamespaceConfig         // Maps namespace to configuration of allocations of its new pods
+	Usage            map[string]NamespaceUsage          // Maps namespace to cpu time pinned to its guaranteed containers
+
+	allocationHints map[string]CPUSet    // Maps container id to cpus preferred by the next allocation
+	tombstones      map[string]time.Time // Maps id of recently deleted pod to its deletion time
+	encoding        StateEncoding        // Format in which the state file is written
+
+	deferredCgroupUpdates map[string]deferredCgroupUpdate // Maps container id to cpuset update waiting for its cgroup
+	appliedCgroupUpdates  map[string]appliedCgroupUpdate  // Maps container id to the last cpuset update written
+	cgroupRetryAttempts   int                             // Re-applies of deferred cgroup updates, 0 disables deferring
 }
 
-func newState(cgroupPath string, numaPath string, statePath string) (*DaemonState, error) {
+func newState(cgroupPath string, numaPath string, statePath string, opts ...Option) (*DaemonState, error) {
+	o := newDaemonOptions(opts)
 	s := DaemonState{
-		CGroupPath: cgroupPath,
-		Allocated:  make(map[string][]ctlplaneapi.CPUBucket),
-		Pods:       make(map[string]PodMetadata),
-		StatePath:  statePath,
-	}
-
-	var (
-		gCgroupPath     string
-		gCpusetFilePath string
-	)
-	if cgroups.Mode() != cgroups.Unified {
-		gCgroupPath = cgroupPath + "/cpuset"
-		gCpusetFilePath = "cpuset.cpus"
-	} else {
-		gCgroupPath = cgroupPath
-		gCpusetFilePath = "cpuset.cpus.effective"
+		CGroupPath:    cgroupPath,
+		Allocated:     make(map[string][]ctlplaneapi.CPUBucket),
+		Pods:          make(map[string]PodMetadata),
+		StatePath:     statePath,
+		CgroupVersion: o.cgroupVersion,
+		encoding:      o.stateEncoding,
+
+		cgroupRetryAttempts: o.cgroupRetryAttempts,
 	}
+	if err := validateCgroupVersion(o.cgroupVersion, cgroupFsVersion(cgroupPath), CgroupVersionUnknown, cgroupPath); err != nil {
+		return nil, err
+	}
+
+	gCgroupPath, gCpusetFilePath := o.cgroupVersion.cpusetPaths(cgroupPath)
 	c, err := getValues(gCgroupPath, gCpusetFilePath)
 
 	if err == nil {
@@ -62,11 +84,79 @@ func newState(cgroupPath string, numaPath string, statePath string) (*DaemonStat
 			ErrorMessage: err.Error(),
 		}
 	}
+
+	excluded := o.excludedCPUs.Clone()
+	for _, cpu := range s.Topology.CpusOnNodes(o.excludedNodes) {
+		excluded.Add(cpu)
+	}
+	reserved := getReservedCpus(s.AvailableCPUs, gCgroupPath, gCpusetFilePath)
+	if o.kubepodsReservedCPUs != nil {
+		if unknown := o.kubepodsReservedCPUs.Clone().RemoveAll(s.allCpus()); unknown.Count() > 0 {
+			return nil, DaemonError{
+				ErrorType:    ConfigurationError,
+				ErrorMessage: fmt.Sprintf("reserved cpus %s are not present on the node", unknown),
+			}
+		}
+		reserved = o.kubepodsReservedCPUs.Clone()
+	}
+	if o.reservedCPUs != nil {
+		if unknown := o.reservedCPUs.Clone().RemoveAll(s.allCpus()); unknown.Count() > 0 {
+			return nil, DaemonError{
+				ErrorType:    ConfigurationError,
+				ErrorMessage: fmt.Sprintf("reserved cpus %s are not present on the node", unknown),
+			}
+		}
+		reserved.Merge(o.reservedCPUs)
+	}
+	unmanaged := CPUSet{}
+	if o.managedCPUs != nil {
+		if unknown := o.managedCPUs.Clone().RemoveAll(s.allCpus()); unknown.Count() > 0 {
+			return nil, DaemonError{
+				ErrorType:    ConfigurationError,
+				ErrorMessage: fmt.Sprintf("managed cpus %s are not present on the node", unknown),
+			}
+		}
+		unmanaged = s.allCpus().RemoveAll(o.managedCPUs)
+		s.ManagedCPUs = o.managedCPUs.ToCompactBucketList()
+	}
+	if err = s.excludeCpus(excluded.Clone().Merge(reserved).Merge(unmanaged)); err != nil {
+		return nil, err
+	}
+	if excluded.Count() > 0 {
+		s.ExcludedCPUs = excluded.ToCompactBucketList()
+	}
+	if reserved.Count() > 0 {
+		s.ReservedCPUs = reserved.ToCompactBucketList()
+	}
+
 	_, errSt := os.Stat(statePath)
 	if errSt != nil && errors.Is(errSt, os.ErrNotExist) {
 		err = s.SaveState()
 	} else {
+		s.ReservedCPUs = []ctlplaneapi.CPUBucket{} // kept only by state files written without reserved cpus
 		err = s.LoadState()
+		if err == nil && s.ReservedCPUs != nil && len(s.ReservedCPUs) == 0 {
+			err = s.reserveDetectedCpus(reserved)
+		}
+		if err == nil {
+			err = validateCgroupVersion(o.cgroupVersion, CgroupVersionUnknown, s.CgroupVersion, cgroupPath)
+			s.CgroupVersion = o.cgroupVersion
+		}
+		if err == nil {
+			err = validateSameCpus("excluded", s.ExcludedCPUs, excluded)
+		}
+		if err == nil {
+			err = validateSameCpus("reserved", s.ReservedCPUs, reserved)
+		}
+		if err == nil {
+			err = validateSameCpus("managed", s.ManagedCPUs, managedOrEmpty(o.managedCPUs))
+		}
+		if err == nil {
+			err = s.compactJournal()
+		}
+		if err == nil {
+			err = s.migrateEncoding()
+		}
 	}
 	_ = errSt
 	if err != nil {
@@ -75,17 +165,275 @@ func newState(cgroupPath string, numaPath string, statePath string) (*DaemonStat
 	return &s, err
 }
 
-// SaveState saves state to file given in StatePath.
+// excludeCpus removes given cpus from the topology and the list of available cpus.
+func (d *DaemonState) excludeCpus(cpus CPUSet) error {
+	if cpus.Count() == 0 {
+		return nil
+	}
+	if err := d.Topology.RemoveCpus(cpus.Sorted()); err != nil {
+		return DaemonError{
+			ErrorType:    ConfigurationError,
+			ErrorMessage: err.Error(),
+		}
+	}
+	d.AvailableCPUs = CPUSetFromBucketList(d.AvailableCPUs).RemoveAll(cpus).ToCompactBucketList()
+	return nil
+}
+
+// reserveDetectedCpus excludes cpus reserved at startup from state loaded from state file written before
+// reserved cpus were stored. Fails if reserved cpus are exclusively allocated to containers.
+func (d *DaemonState) reserveDetectedCpus(reserved CPUSet) error {
+	d.ReservedCPUs = nil
+	if reserved.Count() == 0 {
+		return nil
+	}
+	allocated, free := d.exclusiveCpus(), d.allCpus()
+	inUse, freeReserved := CPUSet{}, CPUSet{}
+	for _, cpu := range reserved.Sorted() {
+		if allocated.Contains(cpu) {
+			inUse.Add(cpu)
+		} else if free.Contains(cpu) {
+			freeReserved.Add(cpu)
+		}
+	}
+	if inUse.Count() > 0 {
+		return DaemonError{
+			ErrorType:    ConfigurationError,
+			ErrorMessage: fmt.Sprintf("cannot reserve cpus %s, they are in use", inUse),
+		}
+	}
+	if err := d.excludeCpus(freeReserved); err != nil {
+		return err
+	}
+	d.ReservedCPUs = reserved.ToCompactBucketList()
+	return nil
+}
+
+// allCpus returns cpus available in the cgroup or present in the topology.
+func (d *DaemonState) allCpus() CPUSet {
+	cpus := CPUSetFromBucketList(d.AvailableCPUs)
+	for _, leaf := range d.Topology.Topology.GetLeafs() {
+		cpus.Add(leaf.Value)
+	}
+	return cpus
+}
+
+func managedOrEmpty(cpus CPUSet) CPUSet {
+	if cpus == nil {
+		return CPUSet{}
+	}
+	return cpus
+}
+
+// validateSameCpus checks if cpus stored in loaded state are the same as the current ones.
+func validateSameCpus(kind string, state []ctlplaneapi.CPUBucket, cpus CPUSet) error {
+	stateCpus := CPUSetFromBucketList(state)
+	if stateCpus.ToCpuString() != cpus.ToCpuString() {
+		return DaemonError{
+			ErrorType: ConfigurationError,
+			ErrorMessage: fmt.Sprintf(
+				"%s cpus %s differ from %s cpus in state %s",
+				kind,
+				cpus,
+				kind,
+				stateCpus,
+			),
+		}
+	}
+	return nil
+}
+
+// kubepodsCgroups lists possible cgroups of kubernetes pods: with systemd and cgroupfs drivers, and
+// inside kind nodes.
+var kubepodsCgroups = []string{"kubepods.slice", "kubepods", "kubelet/kubepods"}
+
+// getReservedCpus returns cpus reserved by kubelet (eg. with --reserved-cpus), that is cpus available
+// in root cgroup, but not in the kubepods cgroup. If kubepods cgroup cannot be found, no cpus are
+// reserved.
+func getReservedCpus(rootCpus []ctlplaneapi.CPUBucket, cgroupPath string, cpusetFileName string) CPUSet {
+	for _, kubepods := range kubepodsCgroups {
+		kubepodsCpus, err := getValues(filepath.Join(cgroupPath, kubepods), cpusetFileName)
+		if err != nil || len(kubepodsCpus) == 0 {
+			continue
+		}
+		return CPUSetFromBucketList(rootCpus).RemoveAll(CPUSetFromBucketList(kubepodsCpus))
+	}
+	return CPUSet{}
+}
+
+// exclusiveCpusCount returns number of cpus allocated to guaranteed containers, except the given one.
+func (d *DaemonState) exclusiveCpusCount(skipCID string) int {
+	count := 0
+	for _, pod := range d.Pods {
+		for _, c := range pod.Containers {
+			if c.QS == Guaranteed && c.CID != skipCID {
+				count += CPUSetFromBucketList(d.Allocated[c.CID]).Count()
+			}
+		}
+	}
+	return count
+}
+
+// allocationSnapshot holds a copy of the allocation related part of DaemonState.
+type allocationSnapshot struct {
+	availableCPUs []ctlplaneapi.CPUBucket
+	allocated     map[string][]ctlplaneapi.CPUBucket
+	topology      numautils.NumaTopology
+}
+
+// snapshot returns a deep copy of available cpus, allocations and topology.
+func (d *DaemonState) snapshot() allocationSnapshot {
+	snapshot := allocationSnapshot{
+		availableCPUs: append([]ctlplaneapi.CPUBucket{}, d.AvailableCPUs...),
+		allocated:     make(map[string][]ctlplaneapi.CPUBucket, len(d.Allocated)),
+		topology:      d.Topology.Clone(),
+	}
+	for cid, buckets := range d.Allocated {
+		snapshot.allocated[cid] = append([]ctlplaneapi.CPUBucket{}, buckets...)
+	}
+	return snapshot
+}
+
+// restore brings back available cpus, allocations and topology from the snapshot.
+func (d *DaemonState) restore(snapshot allocationSnapshot) {
+	d.AvailableCPUs = snapshot.availableCPUs
+	d.Allocated = snapshot.allocated
+	d.Topology = snapshot.topology
+}
+
+// setAllocationHint makes allocators prefer given cpus when allocating the container.
+func (d *DaemonState) setAllocationHint(cid string, cpus CPUSet) {
+	if d.allocationHints == nil {
+		d.allocationHints = make(map[string]CPUSet)
+	}
+	d.allocationHints[cid] = cpus
+}
+
+// allocationHint returns cpus preferred by the allocation of the container.
+func (d *DaemonState) allocationHint(cid string) CPUSet {
+	if hint, ok := d.allocationHints[cid]; ok {
+		return hint
+	}
+	return CPUSet{}
+}
+
+func (d *DaemonState) clearAllocationHint(cid string) {
+	delete(d.allocationHints, cid)
+}
+
+func (d *DaemonState) setMemoryNodes(cid string, memSet string) {
+	if d.MemoryNodes == nil {
+		d.MemoryNodes = make(map[string]string)
+	}
+	d.MemoryNodes[cid] = memSet
+}
+
+// getMemoryNodes returns memory nodes container is pinned to, empty string if memory is not pinned.
+func (d *DaemonState) getMemoryNodes(cid string) string {
+	return d.MemoryNodes[cid]
+}
+
+func (d *DaemonState) clearMemoryNodes(cid string) {
+	delete(d.MemoryNodes, cid)
+}
+
+// setCgroupPath records path of the container cgroup written by the last cpuset update.
+func (d *DaemonState) setCgroupPath(cid string, cgroupPath string) {
+	if d.CgroupPaths == nil {
+		d.CgroupPaths = make(map[string]string)
+	}
+	d.CgroupPaths[cid] = cgroupPath
+}
+
+// getCgroupPath returns path of the container cgroup, empty string if no cgroup was written.
+func (d *DaemonState) getCgroupPath(cid string) string {
+	return d.CgroupPaths[cid]
+}
+
+// clearCgroupPath forgets the container cgroup together with cpus read back from it.
+func (d *DaemonState) clearCgroupPath(cid string) {
+	delete(d.CgroupPaths, cid)
+	delete(d.EffectiveCPUs, cid)
+}
+
+// setAllocationExplanation records why the allocator selected cpus of the container.
+func (d *DaemonState) setAllocationExplanation(cid string, explanation string) {
+	if d.Explanations == nil {
+		d.Explanations = make(map[string]string)
+	}
+	d.Explanations[cid] = explanation
+}
+
+// getAllocationExplanation returns explanation of the container allocation, empty string if none.
+func (d *DaemonState) getAllocationExplanation(cid string) string {
+	return d.Explanations[cid]
+}
+
+func (d *DaemonState) clearAllocationExplanation(cid string) {
+	delete(d.Explanations, cid)
+}
+
+// setAllocatedAt records time of the container cpus allocation.
+func (d *DaemonState) setAllocatedAt(cid string, now time.Time) {
+	if d.AllocatedAt == nil {
+		d.AllocatedAt = make(map[string]time.Time)
+	}
+	d.AllocatedAt[cid] = now
+}
+
+// getAllocatedAt returns time of the container cpus allocation, zero time if it is not allocated.
+func (d *DaemonState) getAllocatedAt(cid string) time.Time {
+	return d.AllocatedAt[cid]
+}
+
+// releaseAllocatedAt forgets time of the container cpus allocation and observes age of the allocation.
+func (d *DaemonState) releaseAllocatedAt(cid string, now time.Time) {
+	allocatedAt, ok := d.AllocatedAt[cid]
+	if !ok {
+		return
+	}
+	metrics.AllocationAge.Observe(now.Sub(allocatedAt).Seconds())
+	delete(d.AllocatedAt, cid)
+}
+
+// addTombstone records deletion of the pod and forgets pods deleted earlier than ttl ago.
+func (d *DaemonState) addTombstone(pid string, now time.Time, ttl time.Duration) {
+	if ttl == 0 {
+		return
+	}
+	if d.tombstones == nil {
+		d.tombstones = make(map[string]time.Time)
+	}
+	for id, deleted := range d.tombstones {
+		if now.Sub(deleted) >= ttl {
+			delete(d.tombstones, id)
+		}
+	}
+	d.tombstones[pid] = now
+}
+
+// recentlyDeleted checks if the pod was deleted less than ttl ago.
+func (d *DaemonState) recentlyDeleted(pid string, now time.Time, ttl time.Duration) bool {
+	deleted, ok := d.tombstones[pid]
+	return ok && now.Sub(deleted) < ttl
+}
+
+// SaveState saves state to file given in StatePath. The file is replaced atomically, so that a crash
+// never leaves partially written state.
 func (d *DaemonState) SaveState() error {
-	b, err := json.Marshal(d)
+	b, err := d.encode()
 	if err != nil {
 		return err
 	}
-	err = os.WriteFile(d.StatePath, b, daemonFilePermission)
-	return err
+	tmpPath := d.StatePath + ".tmp"
+	if err = os.WriteFile(tmpPath, b, daemonFilePermission); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, d.StatePath)
 }
 
-// LoadState loads state from StatePath. StatePath value is always preserved.
+// LoadState loads state from StatePath and applies changes recorded in its journal. StatePath value is
+// always preserved.
 func (d *DaemonState) LoadState() error {
 	statePath := d.StatePath
 	if err := utils.ErrorIfSymlink(statePath); err != nil {
@@ -95,18 +443,41 @@ func (d *DaemonState) LoadState() error {
 	if err != nil {
 		return err
 	}
-	err = json.Unmarshal(b, d)
+	err = d.decode(b)
 	d.StatePath = statePath // do not modify statePath, even if different (eg. state file was copied)
-	return err
+	if err != nil {
+		return err
+	}
+	if err := d.replayJournal(); err != nil {
+		return err
+	}
+	d.fillContainerPodFields()
+	return nil
+}
+
+// fillContainerPodFields sets namespace and QoS class of the pod on containers of states saved by older
+// versions of the daemon, which kept them in pod metadata only.
+func (d *DaemonState) fillContainerPodFields() {
+	for pid, pod := range d.Pods {
+		podQS := podQoS(pod.Containers)
+		for i := range pod.Containers {
+			if pod.Containers[i].Namespace == "" {
+				pod.Containers[i].Namespace = pod.Namespace
+				pod.Containers[i].PodQS = podQS
+			}
+		}
+		d.Pods[pid] = pod
+	}
 }
 
-// DaemonStateFromReader loads the state of the daemon from a stream.
+// DaemonStateFromReader loads the state of the daemon from a stream in any of supported encodings.
 func DaemonStateFromReader(reader io.Reader) (DaemonState, error) {
 	d := DaemonState{}
 	b, err := io.ReadAll(reader)
 	if err != nil {
 		return DaemonState{}, err
 	}
-	err = json.Unmarshal(b, &d)
+	err = d.decode(b)
+	d.fillContainerPodFields()
 	return d, err
 }
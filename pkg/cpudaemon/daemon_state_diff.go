@@ -0,0 +1,76 @@
+package cpudaemon
+
+import (
+	"sort"
+)
+
+// stateDiff describes changes of allocations between two states, so that allocation history can be
+// followed from the log.
+type stateDiff struct {
+	addedPods   []string          // ids of added pods, sorted
+	removedPods []string          // ids of removed pods, sorted
+	allocated   map[string]string // maps container id to cpus allocated to it
+	freed       map[string]string // maps container id to cpus it no longer uses
+}
+
+// diffAllocations returns pods added and removed, and cpus allocated and freed by containers between
+// prev and next state.
+func diffAllocations(prev, next *DaemonState) stateDiff {
+	diff := stateDiff{
+		addedPods:   []string{},
+		removedPods: []string{},
+		allocated:   make(map[string]string),
+		freed:       make(map[string]string),
+	}
+	for pid := range next.Pods {
+		if _, ok := prev.Pods[pid]; !ok {
+			diff.addedPods = append(diff.addedPods, pid)
+		}
+	}
+	for pid := range prev.Pods {
+		if _, ok := next.Pods[pid]; !ok {
+			diff.removedPods = append(diff.removedPods, pid)
+		}
+	}
+	sort.Strings(diff.addedPods)
+	sort.Strings(diff.removedPods)
+
+	for cid, buckets := range next.Allocated {
+		cpus := CPUSetFromBucketList(buckets).RemoveAll(CPUSetFromBucketList(prev.Allocated[cid]))
+		if cpus.Count() > 0 {
+			diff.allocated[cid] = cpus.String()
+		}
+	}
+	for cid, buckets := range prev.Allocated {
+		cpus := CPUSetFromBucketList(buckets).RemoveAll(CPUSetFromBucketList(next.Allocated[cid]))
+		if cpus.Count() > 0 {
+			diff.freed[cid] = cpus.String()
+		}
+	}
+	return diff
+}
+
+func (s stateDiff) empty() bool {
+	return len(s.addedPods) == 0 && len(s.removedPods) == 0 && len(s.allocated) == 0 && len(s.freed) == 0
+}
+
+// logStateDiff logs changes of allocations since the previously saved state at verbosity 2. The previous
+// state is the copy published for read requests, so no other snapshot is kept.
+func (d *Daemon) logStateDiff(prev *DaemonState) {
+	logger := d.logger.V(2)
+	if !logger.Enabled() {
+		return
+	}
+	diff := diffAllocations(prev, &d.state)
+	if diff.empty() {
+		return
+	}
+	logger.Info(
+		"state changed",
+		"addedPods", diff.addedPods,
+		"removedPods", diff.removedPods,
+		"allocatedCpus", diff.allocated,
+		"freedCpus", diff.freed,
+		"availableCpus", CPUSetFromBucketList(d.state.AvailableCPUs).String(),
+	)
+}
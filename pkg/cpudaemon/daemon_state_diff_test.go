@@ -0,0 +1,64 @@
+package cpudaemon
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+func statesForDiffTest() (*DaemonState, *DaemonState) {
+	prev := &DaemonState{
+		Pods: map[string]PodMetadata{"kept": {PID: "kept"}, "removed": {PID: "removed"}},
+		Allocated: map[string][]ctlplaneapi.CPUBucket{
+			"resized": {{StartCPU: 1, EndCPU: 4}},
+			"deleted": {{StartCPU: 5, EndCPU: 5}},
+		},
+	}
+	next := &DaemonState{
+		Pods: map[string]PodMetadata{"kept": {PID: "kept"}, "added": {PID: "added"}},
+		Allocated: map[string][]ctlplaneapi.CPUBucket{
+			"resized": {{StartCPU: 3, EndCPU: 6}},
+			"created": {{StartCPU: 7, EndCPU: 8}},
+		},
+		AvailableCPUs: []ctlplaneapi.CPUBucket{{StartCPU: 1, EndCPU: 2}},
+	}
+	return prev, next
+}
+
+func TestDiffAllocations(t *testing.T) {
+	prev, next := statesForDiffTest()
+
+	diff := diffAllocations(prev, next)
+
+	assert.Equal(t, []string{"added"}, diff.addedPods)
+	assert.Equal(t, []string{"removed"}, diff.removedPods)
+	assert.Equal(t, map[string]string{"resized": "5,6", "created": "7,8"}, diff.allocated)
+	assert.Equal(t, map[string]string{"resized": "1,2", "deleted": "5"}, diff.freed)
+	assert.True(t, diffAllocations(next, next).empty())
+}
+
+func TestLogStateDiff(t *testing.T) {
+	prev, next := statesForDiffTest()
+	for verbosity, logged := range map[int]bool{1: false, 2: true} {
+		lines := []string{}
+		logger := funcr.New(func(prefix, args string) {
+			lines = append(lines, args)
+		}, funcr.Options{Verbosity: verbosity})
+		d := &Daemon{logger: logger, state: *next}
+
+		d.logStateDiff(prev)
+		d.logStateDiff(next)
+
+		if !logged {
+			assert.Empty(t, lines, "verbosity %d", verbosity)
+			continue
+		}
+		require.Len(t, lines, 1, "unchanged state shall not be logged")
+		assert.Contains(t, lines[0], `"addedPods"=["added"]`)
+		assert.Contains(t, lines[0], `"deleted":"5"`)
+		assert.Contains(t, lines[0], `"availableCpus"="1,2"`)
+	}
+}
@@ -0,0 +1,92 @@
+package cpudaemon
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// StateEncoding defines the format in which the state file is written. State files are read in any
+// format, and the daemon converts the state file to the configured format at startup.
+type StateEncoding int
+
+const (
+	// StateEncodingAuto selects gob encoding for state paths with ".gob" extension, JSON otherwise.
+	StateEncodingAuto StateEncoding = iota
+	// StateEncodingJSON writes the state as JSON document.
+	StateEncodingJSON
+	// StateEncodingGob writes the state in gob format, which is smaller and faster to write and read on
+	// nodes with many containers.
+	StateEncodingGob
+)
+
+const gobStateExtension = ".gob"
+
+// gobStateHeader starts state files in gob format, so that they are told apart from JSON ones.
+var gobStateHeader = []byte("ctlplane-state-gob\n")
+
+func (e StateEncoding) String() string {
+	return []string{"auto", "json", "gob"}[e]
+}
+
+// forPath resolves automatic encoding using extension of the state path.
+func (e StateEncoding) forPath(statePath string) StateEncoding {
+	if e != StateEncodingAuto {
+		return e
+	}
+	if filepath.Ext(statePath) == gobStateExtension {
+		return StateEncodingGob
+	}
+	return StateEncodingJSON
+}
+
+func (d *DaemonState) encode() ([]byte, error) {
+	if d.encoding.forPath(d.StatePath) == StateEncodingGob {
+		buf := bytes.NewBuffer(append([]byte{}, gobStateHeader...))
+		if err := gob.NewEncoder(buf).Encode(d); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return json.Marshal(d)
+}
+
+// decode reads the state encoded in any of supported formats. JSON is decoded over the current state,
+// while gob replaces it, as gob leaves out empty fields.
+func (d *DaemonState) decode(b []byte) error {
+	if !isGobState(b) {
+		return json.Unmarshal(b, d)
+	}
+	s := DaemonState{
+		Allocated: make(map[string][]ctlplaneapi.CPUBucket),
+		Pods:      make(map[string]PodMetadata),
+		encoding:  d.encoding,
+
+		cgroupRetryAttempts: d.cgroupRetryAttempts,
+	}
+	if err := gob.NewDecoder(bytes.NewReader(b[len(gobStateHeader):])).Decode(&s); err != nil {
+		return err
+	}
+	*d = s
+	return nil
+}
+
+func isGobState(b []byte) bool {
+	return bytes.HasPrefix(b, gobStateHeader)
+}
+
+// migrateEncoding rewrites the state file if it is written in other format than the configured one.
+func (d *DaemonState) migrateEncoding() error {
+	b, err := os.ReadFile(d.StatePath)
+	if err != nil {
+		return err
+	}
+	if isGobState(b) == (d.encoding.forPath(d.StatePath) == StateEncodingGob) {
+		return nil
+	}
+	return d.SaveState()
+}
@@ -0,0 +1,139 @@
+package cpudaemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+// largeTestState returns state of a node running given number of pods with two containers each.
+func largeTestState(tb testing.TB, statePath string, pods int) *DaemonState {
+	s := &DaemonState{
+		AvailableCPUs: []ctlplaneapi.CPUBucket{{StartCPU: 0, EndCPU: 127}},
+		Allocated:     make(map[string][]ctlplaneapi.CPUBucket),
+		Pods:          make(map[string]PodMetadata),
+		AllocatedAt:   make(map[string]time.Time),
+		CgroupPaths:   make(map[string]string),
+		StatePath:     statePath,
+	}
+	require.Nil(tb, s.Topology.Load("testdata/node_info"))
+	for i := 0; i < pods; i++ {
+		pid := fmt.Sprintf("pod-%d", i)
+		meta := PodMetadata{
+			PID:       pid,
+			Name:      pid,
+			Namespace: "default",
+			Labels:    map[string]string{"app": "test"},
+		}
+		for j := 0; j < 2; j++ {
+			cid := fmt.Sprintf("containerd://%s-%d", pid, j)
+			meta.Containers = append(meta.Containers, Container{
+				CID: cid, PID: pid, Name: cid, Cpus: 1, QS: Guaranteed, Namespace: "default", PodQS: Guaranteed,
+			})
+			s.Allocated[cid] = []ctlplaneapi.CPUBucket{{StartCPU: i % 128, EndCPU: i % 128}}
+			s.AllocatedAt[cid] = time.Unix(int64(i), 0).UTC()
+			s.CgroupPaths[cid] = "/sys/fs/cgroup/kubepods/" + cid
+		}
+		s.Pods[pid] = meta
+	}
+	return s
+}
+
+func TestStateEncodingForPath(t *testing.T) {
+	assert.Equal(t, StateEncodingJSON, StateEncodingAuto.forPath("/var/lib/daemon.state"))
+	assert.Equal(t, StateEncodingGob, StateEncodingAuto.forPath("/var/lib/daemon.gob"))
+	assert.Equal(t, StateEncodingJSON, StateEncodingJSON.forPath("/var/lib/daemon.gob"))
+	assert.Equal(t, StateEncodingGob, StateEncodingGob.forPath("/var/lib/daemon.state"))
+}
+
+func TestSaveAndLoadGobState(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "daemon.gob")
+	saved := largeTestState(t, statePath, 10)
+	_, err := saved.Topology.Take(3)
+	require.Nil(t, err)
+	require.Nil(t, saved.SaveState())
+
+	b, err := os.ReadFile(statePath)
+	require.Nil(t, err)
+	assert.True(t, isGobState(b))
+	loaded := DaemonState{StatePath: statePath}
+	require.Nil(t, loaded.LoadState())
+
+	assert.Equal(t, *saved, loaded)
+}
+
+func TestLoadGobStateWithoutPods(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "daemon.state")
+	saved := DaemonState{StatePath: statePath, encoding: StateEncodingGob}
+	require.Nil(t, saved.SaveState())
+
+	loaded := DaemonState{StatePath: statePath, AvailableCPUs: []ctlplaneapi.CPUBucket{{StartCPU: 0, EndCPU: 1}}}
+	require.Nil(t, loaded.LoadState())
+
+	assert.Nil(t, loaded.AvailableCPUs)
+	assert.NotNil(t, loaded.Pods)
+	assert.NotNil(t, loaded.Allocated)
+}
+
+func TestNewStateMigratesJSONStateToGob(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "daemon.state")
+	d, err := New("testdata/no_state", "testdata/node_info", statePath, &MockedPolicy{}, logr.Discard())
+	require.Nil(t, err)
+	d.state.Pods["pod"] = PodMetadata{PID: "pod"}
+	require.Nil(t, d.state.SaveState())
+
+	migrated, err := New(
+		"testdata/no_state", "testdata/node_info", statePath, &MockedPolicy{}, logr.Discard(),
+		WithStateEncoding(StateEncodingGob),
+	)
+	require.Nil(t, err)
+
+	assert.Contains(t, migrated.state.Pods, "pod")
+	b, err := os.ReadFile(statePath)
+	require.Nil(t, err)
+	assert.True(t, isGobState(b))
+	assert.Contains(t, readStateFile(t, statePath).Pods, "pod")
+}
+
+func BenchmarkSaveState(b *testing.B) {
+	for _, encoding := range []StateEncoding{StateEncodingJSON, StateEncodingGob} {
+		for _, pods := range []int{100, 5000} {
+			b.Run(fmt.Sprintf("%s/pods=%d", encoding, pods), func(b *testing.B) {
+				s := largeTestState(b, filepath.Join(b.TempDir(), "daemon.state"), pods)
+				s.encoding = encoding
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					require.Nil(b, s.SaveState())
+				}
+				b.StopTimer()
+				info, err := os.Stat(s.StatePath)
+				require.Nil(b, err)
+				b.ReportMetric(float64(info.Size()), "file-bytes")
+			})
+		}
+	}
+}
+
+func BenchmarkLoadState(b *testing.B) {
+	for _, encoding := range []StateEncoding{StateEncodingJSON, StateEncodingGob} {
+		for _, pods := range []int{100, 5000} {
+			b.Run(fmt.Sprintf("%s/pods=%d", encoding, pods), func(b *testing.B) {
+				s := largeTestState(b, filepath.Join(b.TempDir(), "daemon.state"), pods)
+				s.encoding = encoding
+				require.Nil(b, s.SaveState())
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					loaded := DaemonState{StatePath: s.StatePath}
+					require.Nil(b, loaded.LoadState())
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package cpudaemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"time"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/numautils"
+	"resourcemanagement.controlplane/pkg/utils"
+)
+
+// stateJournalSuffix is appended to the state path to create the journal of state changes not yet
+// written to the state file.
+const stateJournalSuffix = ".journal"
+
+// maxJournalEntrySize limits the size of a single journal entry read while replaying the journal.
+const maxJournalEntrySize = 64 * 1024 * 1024
+
+// stateDelta is a journal entry holding changes of the persisted state made by a single update. Values
+// are absolute, so replaying an entry more than once gives the same state.
+type stateDelta struct {
+	Allocated        mapDelta[[]ctlplaneapi.CPUBucket]
+	Pods             mapDelta[PodMetadata]
+	AllocatedAt      mapDelta[time.Time]
+	CgroupPaths      mapDelta[string]
+	Borrowed         mapDelta[[]ctlplaneapi.CPUBucket]
+	Explanations     mapDelta[string]
+	MemoryNodes      mapDelta[string]
+	CPUPools         mapDelta[CPUPool]
+	EffectiveCPUs    mapDelta[string]
+	NamespaceConfigs mapDelta[NamespaceConfig]
+	Usage            mapDelta[NamespaceUsage]
+	AvailableCPUs    *[]ctlplaneapi.CPUBucket `json:",omitempty"`
+	KubeletCPUs      *[]ctlplaneapi.CPUBucket `json:",omitempty"`
+	TopologyCPUs     *[]ctlplaneapi.CPUBucket `json:",omitempty"` // cpus available in the topology tree
+}
+
+// mapDelta holds changed and deleted entries of a map.
+type mapDelta[V any] struct {
+	Set     map[string]V `json:",omitempty"`
+	Deleted []string     `json:",omitempty"`
+}
+
+func diffMap[V any](prev, next map[string]V) mapDelta[V] {
+	delta := mapDelta[V]{}
+	for k, v := range next {
+		if old, ok := prev[k]; ok && reflect.DeepEqual(old, v) {
+			continue
+		}
+		if delta.Set == nil {
+			delta.Set = make(map[string]V)
+		}
+		delta.Set[k] = v
+	}
+	for k := range prev {
+		if _, ok := next[k]; !ok {
+			delta.Deleted = append(delta.Deleted, k)
+		}
+	}
+	return delta
+}
+
+func (m mapDelta[V]) empty() bool {
+	return len(m.Set) == 0 && len(m.Deleted) == 0
+}
+
+func (m mapDelta[V]) apply(target map[string]V) map[string]V {
+	if target == nil && len(m.Set) > 0 {
+		target = make(map[string]V, len(m.Set))
+	}
+	for k, v := range m.Set {
+		target[k] = v
+	}
+	for _, k := range m.Deleted {
+		delete(target, k)
+	}
+	return target
+}
+
+func diffBuckets(prev, next []ctlplaneapi.CPUBucket) *[]ctlplaneapi.CPUBucket {
+	if reflect.DeepEqual(prev, next) {
+		return nil
+	}
+	if next == nil {
+		next = []ctlplaneapi.CPUBucket{}
+	}
+	return &next
+}
+
+// diffStates returns changes of the persisted state between prev and next.
+func diffStates(prev, next *DaemonState) stateDelta {
+	return stateDelta{
+		Allocated:        diffMap(prev.Allocated, next.Allocated),
+		Pods:             diffMap(prev.Pods, next.Pods),
+		AllocatedAt:      diffMap(prev.AllocatedAt, next.AllocatedAt),
+		CgroupPaths:      diffMap(prev.CgroupPaths, next.CgroupPaths),
+		Borrowed:         diffMap(prev.Borrowed, next.Borrowed),
+		Explanations:     diffMap(prev.Explanations, next.Explanations),
+		MemoryNodes:      diffMap(prev.MemoryNodes, next.MemoryNodes),
+		CPUPools:         diffMap(prev.CPUPools, next.CPUPools),
+		EffectiveCPUs:    diffMap(prev.EffectiveCPUs, next.EffectiveCPUs),
+		NamespaceConfigs: diffMap(prev.NamespaceConfigs, next.NamespaceConfigs),
+		Usage:            diffMap(prev.Usage, next.Usage),
+		AvailableCPUs:    diffBuckets(prev.AvailableCPUs, next.AvailableCPUs),
+		KubeletCPUs:      diffBuckets(prev.KubeletCPUs, next.KubeletCPUs),
+		TopologyCPUs: diffBuckets(
+			topologyAvailableCpus(&prev.Topology).ToCompactBucketList(),
+			topologyAvailableCpus(&next.Topology).ToCompactBucketList(),
+		),
+	}
+}
+
+func (s stateDelta) empty() bool {
+	return s.Allocated.empty() && s.Pods.empty() && s.AllocatedAt.empty() && s.CgroupPaths.empty() &&
+		s.Borrowed.empty() && s.Explanations.empty() && s.MemoryNodes.empty() && s.CPUPools.empty() &&
+		s.EffectiveCPUs.empty() && s.NamespaceConfigs.empty() && s.Usage.empty() &&
+		s.AvailableCPUs == nil && s.KubeletCPUs == nil && s.TopologyCPUs == nil
+}
+
+func (s stateDelta) apply(d *DaemonState) {
+	d.Allocated = s.Allocated.apply(d.Allocated)
+	d.Pods = s.Pods.apply(d.Pods)
+	d.AllocatedAt = s.AllocatedAt.apply(d.AllocatedAt)
+	d.CgroupPaths = s.CgroupPaths.apply(d.CgroupPaths)
+	d.Borrowed = s.Borrowed.apply(d.Borrowed)
+	d.Explanations = s.Explanations.apply(d.Explanations)
+	d.MemoryNodes = s.MemoryNodes.apply(d.MemoryNodes)
+	d.CPUPools = s.CPUPools.apply(d.CPUPools)
+	d.EffectiveCPUs = s.EffectiveCPUs.apply(d.EffectiveCPUs)
+	d.NamespaceConfigs = s.NamespaceConfigs.apply(d.NamespaceConfigs)
+	d.Usage = s.Usage.apply(d.Usage)
+	if s.AvailableCPUs != nil {
+		d.AvailableCPUs = nilIfEmpty(*s.AvailableCPUs)
+	}
+	if s.KubeletCPUs != nil {
+		d.KubeletCPUs = nilIfEmpty(*s.KubeletCPUs)
+	}
+	if s.TopologyCPUs != nil {
+		setTopologyAvailableCpus(&d.Topology, CPUSetFromBucketList(*s.TopologyCPUs))
+	}
+}
+
+func nilIfEmpty(buckets []ctlplaneapi.CPUBucket) []ctlplaneapi.CPUBucket {
+	if len(buckets) == 0 {
+		return nil
+	}
+	return buckets
+}
+
+// topologyAvailableCpus returns cpus which are available in the topology tree.
+func topologyAvailableCpus(t *numautils.NumaTopology) CPUSet {
+	cpus := CPUSet{}
+	if t.Topology == nil {
+		return cpus
+	}
+	for _, leaf := range t.Topology.GetLeafs() {
+		if leaf.Available() {
+			cpus.Add(leaf.Value)
+		}
+	}
+	return cpus
+}
+
+// setTopologyAvailableCpus takes and returns cpus of the topology tree, so that exactly given cpus are
+// available.
+func setTopologyAvailableCpus(t *numautils.NumaTopology, cpus CPUSet) {
+	if t.Topology == nil {
+		return
+	}
+	for _, leaf := range t.Topology.GetLeafs() {
+		switch {
+		case leaf.Available() && !cpus.Contains(leaf.Value):
+			_ = t.TakeCpu(leaf.Value)
+		case !leaf.Available() && cpus.Contains(leaf.Value):
+			_ = t.Return(leaf.Value)
+		}
+	}
+}
+
+func (d *DaemonState) journalPath() string {
+	return d.StatePath + stateJournalSuffix
+}
+
+// replayJournal applies changes recorded in the journal to the state. Replay stops at the first
+// incomplete entry, which is left by a write interrupted by a crash.
+func (d *DaemonState) replayJournal() error {
+	if err := utils.ErrorIfSymlink(d.journalPath()); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	f, err := os.Open(d.journalPath())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxJournalEntrySize)
+	for scanner.Scan() {
+		delta := stateDelta{}
+		if err := json.Unmarshal(scanner.Bytes(), &delta); err != nil {
+			return nil //nolint: nilerr // incomplete tail of the journal is dropped
+		}
+		delta.apply(d)
+	}
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		return nil
+	}
+	return scanner.Err()
+}
+
+// compactJournal writes the state file and removes the journal, if there is any.
+func (d *DaemonState) compactJournal() error {
+	if _, err := os.Lstat(d.journalPath()); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err := d.SaveState(); err != nil {
+		return err
+	}
+	return os.Remove(d.journalPath())
+}
+
+// persistState persists the update from prev, the last published state, to the current state: either by
+// writing the whole state file or, with state save delay, by appending the change to the journal and
+// scheduling the state file write. Shall be called with the state lock held.
+func (d *Daemon) persistState(prev *DaemonState) error {
+	if d.options.stateSaveDelay <= 0 {
+		return d.state.SaveState()
+	}
+	delta := diffStates(prev, &d.state)
+	if delta.empty() {
+		return nil
+	}
+	if err := d.appendJournal(delta); err != nil {
+		return err
+	}
+	if d.saveTimer == nil {
+		d.saveTimer = time.AfterFunc(d.options.stateSaveDelay, d.flushState)
+	}
+	return nil
+}
+
+func (d *Daemon) appendJournal(delta stateDelta) error {
+	if d.journal == nil {
+		if err := utils.ErrorIfSymlink(d.state.journalPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		f, err := os.OpenFile(d.state.journalPath(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, daemonFilePermission)
+		if err != nil {
+			return err
+		}
+		d.journal = f
+	}
+	b, err := json.Marshal(delta)
+	if err != nil {
+		return err
+	}
+	_, err = d.journal.Write(append(b, '\n'))
+	return err
+}
+
+// flushState writes the state file and truncates the journal, whose changes are now part of the state
+// file.
+func (d *Daemon) flushState() {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	d.writeStateFile()
+}
+
+// writeStateFile writes pending changes of the state to the state file. Shall be called with the state
+// lock held.
+func (d *Daemon) writeStateFile() {
+	if d.saveTimer == nil {
+		return
+	}
+	d.saveTimer.Stop()
+	d.saveTimer = nil
+	if err := d.state.SaveState(); err != nil {
+		d.logger.Error(err, "cannot save daemon state, changes are kept in the journal")
+		return
+	}
+	if err := d.journal.Truncate(0); err != nil {
+		d.logger.Error(err, "cannot truncate state journal")
+	}
+}
@@ -0,0 +1,164 @@
+package cpudaemon
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+func journalTestState(t *testing.T) *DaemonState {
+	s := &DaemonState{
+		AvailableCPUs: []ctlplaneapi.CPUBucket{{StartCPU: 0, EndCPU: 127}},
+		Allocated:     map[string][]ctlplaneapi.CPUBucket{"c1": {{StartCPU: 0, EndCPU: 1}}},
+		Pods:          map[string]PodMetadata{"p1": {PID: "p1", Containers: []Container{{CID: "c1", PID: "p1"}}}},
+		AllocatedAt:   map[string]time.Time{"c1": time.Unix(100, 0)},
+	}
+	require.Nil(t, s.Topology.Load("testdata/node_info"))
+	return s
+}
+
+// readStateFile reads the state file without replaying its journal.
+func readStateFile(t *testing.T, statePath string) DaemonState {
+	f, err := os.Open(statePath)
+	require.Nil(t, err)
+	defer f.Close()
+	s, err := DaemonStateFromReader(f)
+	require.Nil(t, err)
+	return s
+}
+
+func TestStateDeltaAppliesChanges(t *testing.T) {
+	prev := journalTestState(t)
+	next := prev.clone()
+	delete(next.Allocated, "c1")
+	delete(next.Pods, "p1")
+	delete(next.AllocatedAt, "c1")
+	next.Allocated["c2"] = []ctlplaneapi.CPUBucket{{StartCPU: 4, EndCPU: 5}}
+	next.Pods["p2"] = PodMetadata{PID: "p2", Containers: []Container{{CID: "c2", PID: "p2"}}}
+	next.CgroupPaths = map[string]string{"c2": "/cgroup/c2"}
+	next.Borrowed = map[string][]ctlplaneapi.CPUBucket{"c2": {{StartCPU: 0, EndCPU: 0}}}
+	next.Explanations = map[string]string{"c2": "default allocator: first 2 cpus of free range 4-127"}
+	next.EffectiveCPUs = map[string]string{"c2": "4"}
+	next.MemoryNodes = map[string]string{"c2": "0"}
+	next.AvailableCPUs = []ctlplaneapi.CPUBucket{{StartCPU: 0, EndCPU: 3}, {StartCPU: 6, EndCPU: 127}}
+	next.KubeletCPUs = []ctlplaneapi.CPUBucket{{StartCPU: 8, EndCPU: 8}}
+	require.Nil(t, next.Topology.TakeCpu(4))
+	require.Nil(t, next.Topology.TakeCpu(5))
+
+	b, err := json.Marshal(diffStates(prev, next))
+	require.Nil(t, err)
+	delta := stateDelta{}
+	require.Nil(t, json.Unmarshal(b, &delta))
+	delta.apply(prev)
+
+	assert.Equal(t, next.Allocated, prev.Allocated)
+	assert.Equal(t, next.Pods, prev.Pods)
+	assert.Empty(t, prev.AllocatedAt)
+	assert.Equal(t, next.CgroupPaths, prev.CgroupPaths)
+	assert.Equal(t, next.Borrowed, prev.Borrowed)
+	assert.Equal(t, next.Explanations, prev.Explanations)
+	assert.Equal(t, next.EffectiveCPUs, prev.EffectiveCPUs)
+	assert.Equal(t, next.MemoryNodes, prev.MemoryNodes)
+	assert.Equal(t, next.AvailableCPUs, prev.AvailableCPUs)
+	assert.Equal(t, next.KubeletCPUs, prev.KubeletCPUs)
+	assert.Equal(t, next.Topology.Topology.String(), prev.Topology.Topology.String())
+	assert.True(t, diffStates(prev, next).empty())
+}
+
+func TestStateDeltaOfSameStatesIsEmpty(t *testing.T) {
+	s := journalTestState(t)
+
+	assert.True(t, diffStates(s, s.clone()).empty())
+}
+
+func TestStateSaveDelayJournalsChanges(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "daemon.state")
+	m := MockedPolicy{}
+	p := createTestPod(1)
+	d, err := New("testdata/no_state", "testdata/node_info", statePath, &m, logr.Discard(), WithStateSaveDelay(time.Hour))
+	require.Nil(t, err)
+	m.On("AssignContainer", p.containers[0], &d.state).Return(nil).Once()
+
+	_, err = d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+			Containers:   p.containersResources,
+		},
+	)
+	require.Nil(t, err)
+
+	assert.NotContains(t, readStateFile(t, statePath).Pods, p.pid, "state file shall be written with delay")
+
+	loaded := DaemonState{StatePath: statePath}
+	require.Nil(t, loaded.LoadState())
+	assert.Equal(t, d.state.Pods, loaded.Pods)
+	assert.Equal(t, d.state.Allocated, loaded.Allocated)
+
+	d.Close()
+	journal, err := os.ReadFile(statePath + stateJournalSuffix)
+	require.Nil(t, err)
+	assert.Empty(t, journal)
+	assert.Contains(t, readStateFile(t, statePath).Pods, p.pid)
+}
+
+func TestNewStateCompactsJournal(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "daemon.state")
+	m := MockedPolicy{}
+	p := createTestPod(1)
+	d, err := New("testdata/no_state", "testdata/node_info", statePath, &m, logr.Discard(), WithStateSaveDelay(time.Hour))
+	require.Nil(t, err)
+	m.On("AssignContainer", p.containers[0], &d.state).Return(nil).Once()
+	_, err = d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+			Containers:   p.containersResources,
+		},
+	)
+	require.Nil(t, err)
+
+	restarted, err := New("testdata/no_state", "testdata/node_info", statePath, &m, logr.Discard())
+	require.Nil(t, err)
+
+	assert.Contains(t, restarted.state.Pods, p.pid)
+	assert.NoFileExists(t, statePath+stateJournalSuffix)
+	assert.Contains(t, readStateFile(t, statePath).Pods, p.pid)
+}
+
+func TestReplayJournalDropsIncompleteEntry(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "daemon.state")
+	s := DaemonState{StatePath: statePath}
+	require.Nil(t, s.SaveState())
+	entry, err := json.Marshal(stateDelta{Pods: mapDelta[PodMetadata]{Set: map[string]PodMetadata{"p1": {PID: "p1"}}}})
+	require.Nil(t, err)
+	journal := append(entry, '\n')
+	journal = append(journal, entry[:len(entry)/2]...)
+	require.Nil(t, os.WriteFile(statePath+stateJournalSuffix, journal, 0o600))
+
+	loaded := DaemonState{StatePath: statePath}
+	require.Nil(t, loaded.LoadState())
+
+	assert.Equal(t, map[string]PodMetadata{"p1": {PID: "p1"}}, loaded.Pods)
+}
+
+func TestNegativeStateSaveDelayFails(t *testing.T) {
+	_, err := New("testdata/no_state", "testdata/node_info", "daemon.state", &MockedPolicy{}, logr.Discard(), WithStateSaveDelay(-time.Second))
+
+	require.NotNil(t, err)
+	assert.Equal(t, ConfigurationError, err.(DaemonError).ErrorType) //nolint: errorlint
+}
@@ -4,8 +4,10 @@ import (
 	"os"
 	"path"
 	"testing"
+	"time"
 
 	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/numautils"
 	"resourcemanagement.controlplane/pkg/utils"
 
 	"github.com/stretchr/testify/assert"
@@ -85,3 +87,274 @@ func TestDoNotLoadDaemonStateIfSymlink(t *testing.T) {
 
 	require.ErrorIs(t, state.LoadState(), utils.ErrFileIsSymlink)
 }
+
+func TestNewStateWithExcludedCpus(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	excluded, err := CPUSetFromString("1,3,100")
+	require.Nil(t, err)
+
+	s, err := newState(
+		"testdata/no_state",
+		"testdata/node_info",
+		daemonStateFile,
+		WithExcludedCpus(excluded),
+		WithExcludedNumaNodes([]int{1}),
+	)
+	require.Nil(t, err)
+
+	expectedAvailable, err := CPUSetFromString("0,5,7,9-99,101-127")
+	require.Nil(t, err)
+	assert.Equal(t, expectedAvailable.ToCompactBucketList(), s.AvailableCPUs)
+	assert.Equal(t, "1,2,3,4,6,8,100", CPUSetFromBucketList(s.ExcludedCPUs).ToCpuString())
+
+	leafs := []int{}
+	for _, leaf := range s.Topology.Topology.GetLeafs() {
+		leafs = append(leafs, leaf.Value)
+	}
+	assert.ElementsMatch(t, []int{5, 7}, leafs)
+}
+
+func TestNewStateFailsIfExcludedCpusDifferFromState(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	excluded, err := CPUSetFromString("1")
+	require.Nil(t, err)
+
+	_, err = newState("testdata/no_state", "testdata/node_info", daemonStateFile, WithExcludedCpus(excluded))
+	require.Nil(t, err)
+
+	_, err = newState("testdata/no_state", "testdata/node_info", daemonStateFile, WithExcludedCpus(excluded))
+	assert.Nil(t, err)
+
+	_, err = newState("testdata/no_state", "testdata/node_info", daemonStateFile)
+	require.NotNil(t, err)
+	assert.Equal(t, ConfigurationError, err.(DaemonError).ErrorType) //nolint: errorlint
+}
+
+func TestNewStateExcludesCpusReservedByKubelet(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+
+	s, err := newState("testdata/kubepods", "testdata/node_info", daemonStateFile)
+	require.Nil(t, err)
+
+	assert.Equal(t, []ctlplaneapi.CPUBucket{{StartCPU: 2, EndCPU: 127}}, s.AvailableCPUs)
+	assert.Equal(t, []ctlplaneapi.CPUBucket{{StartCPU: 0, EndCPU: 1}}, s.ReservedCPUs)
+	assert.Empty(t, s.ExcludedCPUs)
+	leafs := []int{}
+	for _, leaf := range s.Topology.Topology.GetLeafs() {
+		leafs = append(leafs, leaf.Value)
+	}
+	assert.NotContains(t, leafs, 1)
+	assert.Len(t, leafs, 7)
+}
+
+func TestNewStateDetectsReservedCpusMissingInStateFile(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	b, err := os.ReadFile("testdata/pre_reserved_cpus.state")
+	require.Nil(t, err)
+	require.Nil(t, os.WriteFile(daemonStateFile, b, 0o600))
+
+	s, err := newState("testdata/kubepods", "testdata/node_info", daemonStateFile)
+	require.Nil(t, err)
+
+	assert.Equal(t, []ctlplaneapi.CPUBucket{{StartCPU: 2, EndCPU: 127}}, s.AvailableCPUs)
+	assert.Equal(t, []ctlplaneapi.CPUBucket{{StartCPU: 0, EndCPU: 1}}, s.ReservedCPUs)
+	for _, leaf := range s.Topology.Topology.GetLeafs() {
+		assert.NotEqual(t, 1, leaf.Value)
+	}
+}
+
+func TestNewStateFailsIfDetectedReservedCpusAreAllocated(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	b, err := os.ReadFile("testdata/pre_reserved_cpus.state")
+	require.Nil(t, err)
+	require.Nil(t, os.WriteFile(daemonStateFile, b, 0o600))
+	s, err := newState("testdata/no_state", "testdata/node_info", daemonStateFile)
+	require.Nil(t, err)
+	c := Container{CID: "c1", PID: "p1", QS: Guaranteed}
+	s.Pods["p1"] = PodMetadata{PID: "p1", Containers: []Container{c}}
+	s.Allocated[c.CID] = []ctlplaneapi.CPUBucket{{StartCPU: 1, EndCPU: 1}}
+	s.ReservedCPUs = []ctlplaneapi.CPUBucket{} // written as by daemon not storing reserved cpus
+	require.Nil(t, s.SaveState())
+
+	_, err = newState("testdata/kubepods", "testdata/node_info", daemonStateFile)
+
+	require.NotNil(t, err)
+	assert.Equal(t, ConfigurationError, err.(DaemonError).ErrorType) //nolint: errorlint
+}
+
+func TestNewStateFailsIfReservedCpusDifferFromState(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+
+	_, err := newState("testdata/kubepods", "testdata/node_info", daemonStateFile)
+	require.Nil(t, err)
+
+	_, err = newState("testdata/no_state", "testdata/node_info", daemonStateFile)
+	require.NotNil(t, err)
+	assert.Equal(t, ConfigurationError, err.(DaemonError).ErrorType) //nolint: errorlint
+}
+
+func TestNewStateRestrictsToManagedCpus(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+
+	s, err := newState(
+		"testdata/no_state",
+		"testdata/node_info",
+		daemonStateFile,
+		WithManagedCpus(CPUSet{2: {}, 4: {}, 5: {}}),
+	)
+	require.Nil(t, err)
+
+	assert.Equal(t, []ctlplaneapi.CPUBucket{{StartCPU: 2, EndCPU: 2}, {StartCPU: 4, EndCPU: 5}}, s.AvailableCPUs)
+	assert.Equal(t, s.AvailableCPUs, s.ManagedCPUs)
+	assert.Empty(t, s.ExcludedCPUs)
+	leafs := []int{}
+	for _, leaf := range s.Topology.Topology.GetLeafs() {
+		leafs = append(leafs, leaf.Value)
+	}
+	assert.ElementsMatch(t, []int{2, 4, 5}, leafs)
+}
+
+func TestNewStateFailsWithUnknownManagedCpus(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+
+	_, err := newState(
+		"testdata/no_state",
+		"testdata/node_info",
+		daemonStateFile,
+		WithManagedCpus(CPUSet{2: {}, 200: {}}),
+	)
+	require.NotNil(t, err)
+	assert.Equal(t, ConfigurationError, err.(DaemonError).ErrorType) //nolint: errorlint
+}
+
+func TestNewStateFailsIfManagedCpusDifferFromState(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+
+	_, err := newState("testdata/no_state", "testdata/node_info", daemonStateFile, WithManagedCpus(CPUSet{2: {}}))
+	require.Nil(t, err)
+
+	_, err = newState("testdata/no_state", "testdata/node_info", daemonStateFile)
+	require.NotNil(t, err)
+	assert.Equal(t, ConfigurationError, err.(DaemonError).ErrorType) //nolint: errorlint
+}
+
+func TestTombstones(t *testing.T) {
+	s := DaemonState{}
+	now := time.Now()
+	ttl := time.Minute
+
+	assert.False(t, s.recentlyDeleted("pod1", now, ttl))
+
+	s.addTombstone("pod1", now, ttl)
+	assert.True(t, s.recentlyDeleted("pod1", now.Add(ttl/2), ttl))
+	assert.False(t, s.recentlyDeleted("pod1", now.Add(ttl), ttl))
+
+	s.addTombstone("pod2", now.Add(ttl), ttl)
+	assert.NotContains(t, s.tombstones, "pod1")
+	assert.Contains(t, s.tombstones, "pod2")
+}
+
+func TestTombstonesDisabled(t *testing.T) {
+	s := DaemonState{}
+	now := time.Now()
+
+	s.addTombstone("pod1", now, 0)
+	assert.False(t, s.recentlyDeleted("pod1", now, 0))
+}
+
+func TestNewStateTakesReservedCpusFromKubepodsCpusetOption(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+
+	s, err := newState(
+		"testdata/kubepods",
+		"testdata/node_info",
+		daemonStateFile,
+		WithKubepodsCpuset(CPUSet{0: {}}),
+	)
+	require.Nil(t, err)
+
+	assert.Equal(t, []ctlplaneapi.CPUBucket{{StartCPU: 0, EndCPU: 0}}, s.ReservedCPUs)
+	assert.Equal(t, []ctlplaneapi.CPUBucket{{StartCPU: 1, EndCPU: 127}}, s.AvailableCPUs)
+}
+
+func TestNewStateAddsReservedCpusOption(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+
+	s, err := newState(
+		"testdata/kubepods",
+		"testdata/node_info",
+		daemonStateFile,
+		WithReservedCpus(CPUSet{4: {}, 5: {}}),
+	)
+	require.Nil(t, err)
+
+	assert.Equal(t, []ctlplaneapi.CPUBucket{{StartCPU: 0, EndCPU: 1}, {StartCPU: 4, EndCPU: 5}}, s.ReservedCPUs)
+	assert.Equal(t, []ctlplaneapi.CPUBucket{{StartCPU: 2, EndCPU: 3}, {StartCPU: 6, EndCPU: 127}}, s.AvailableCPUs)
+	_, err = s.Topology.FindCpu(4)
+	assert.ErrorIs(t, err, numautils.ErrNotFound, "reserved cpus shall not be allocated")
+}
+
+func TestNewStateFailsWithUnknownReservedCpusOption(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+
+	_, err := newState(
+		"testdata/no_state",
+		"testdata/node_info",
+		daemonStateFile,
+		WithReservedCpus(CPUSet{200: {}}),
+	)
+	require.NotNil(t, err)
+	assert.Equal(t, ConfigurationError, err.(DaemonError).ErrorType) //nolint: errorlint
+}
+
+func TestNewStateFailsWithUnknownReservedCpus(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+
+	_, err := newState(
+		"testdata/no_state",
+		"testdata/node_info",
+		daemonStateFile,
+		WithKubepodsCpuset(CPUSet{200: {}}),
+	)
+	require.NotNil(t, err)
+	assert.Equal(t, ConfigurationError, err.(DaemonError).ErrorType) //nolint: errorlint
+}
+
+func TestLoadStateFillsNamespaceAndQoSOfContainers(t *testing.T) {
+	statePath := path.Join(t.TempDir(), "daemon.state")
+	saved := DaemonState{
+		StatePath: statePath,
+		Pods: map[string]PodMetadata{
+			"pod": {
+				PID:       "pod",
+				Namespace: "ns",
+				Containers: []Container{
+					{CID: "c1", PID: "pod", Cpus: 1, QS: Guaranteed},
+					{CID: "c2", PID: "pod", QS: BestEffort},
+				},
+			},
+		},
+	}
+	require.Nil(t, saved.SaveState())
+
+	loaded := DaemonState{StatePath: statePath}
+	require.Nil(t, loaded.LoadState())
+
+	for _, c := range loaded.Pods["pod"].Containers {
+		assert.Equal(t, "ns", c.Namespace)
+		assert.Equal(t, Burstable, c.PodQS)
+	}
+}
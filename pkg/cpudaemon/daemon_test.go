@@ -1,17 +1,24 @@
 package cpudaemon
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/resource"
 	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
+	"resourcemanagement.controlplane/pkg/version"
 
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type PodMetaData struct {
@@ -35,17 +42,17 @@ type MockedPolicy struct {
 	mock.Mock
 }
 
-func (m *MockedPolicy) AssignContainer(c Container, s *DaemonState) error {
+func (m *MockedPolicy) AssignContainer(_ context.Context, c Container, s *DaemonState) error {
 	args := m.Called(c, s)
 	return args.Error(0)
 }
 
-func (m *MockedPolicy) DeleteContainer(c Container, s *DaemonState) error {
+func (m *MockedPolicy) DeleteContainer(_ context.Context, c Container, s *DaemonState) error {
 	args := m.Called(c, s)
 	return args.Error(0)
 }
 
-func (m *MockedPolicy) ClearContainer(c Container, s *DaemonState) error {
+func (m *MockedPolicy) ClearContainer(_ context.Context, c Container, s *DaemonState) error {
 	args := m.Called(c, s)
 	return args.Error(0)
 }
@@ -56,6 +63,41 @@ func setupTest() (string, func(tb testing.TB)) {
 	}
 }
 
+// newTestDaemon creates daemon with the policy and options on the test node. Daemon is closed and its
+// state file removed when the test ends.
+func newTestDaemon(t *testing.T, policy Policy, opts ...Option) *Daemon {
+	return newTestDaemonOnTopology(t, "testdata/node_info", policy, opts...)
+}
+
+// newTestDaemonOnTopology creates daemon like newTestDaemon, with node topology read from numaPath.
+func newTestDaemonOnTopology(t *testing.T, numaPath string, policy Policy, opts ...Option) *Daemon {
+	daemonStateFile, tearDown := setupTest()
+	t.Cleanup(func() { tearDown(t) })
+	d, err := New("testdata/no_state", numaPath, daemonStateFile, policy, logr.Discard(), opts...)
+	require.Nil(t, err)
+	t.Cleanup(d.Close)
+	return d
+}
+
+// newMockedCgroups returns cgroup controller mock accepting any cpuset update.
+func newMockedCgroups() *CgroupsMock {
+	m := CgroupsMock{}
+	m.On("UpdateCPUSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return &m
+}
+
+// withoutAllocationTime checks that allocation time of all containers is set and clears it, so that
+// allocations can be compared with expectations.
+func withoutAllocationTime(t *testing.T, r ctlplaneapi.AllocatedPodResources) ctlplaneapi.AllocatedPodResources {
+	res := r
+	res.ContainerResources = append([]ctlplaneapi.AllocatedContainerResource{}, r.ContainerResources...)
+	for i := range res.ContainerResources {
+		assert.False(t, res.ContainerResources[i].AllocatedAt.IsZero())
+		res.ContainerResources[i].AllocatedAt = time.Time{}
+	}
+	return res
+}
+
 func createTestPod(n int) PodMetaData {
 	r := ctlplaneapi.ResourceInfo{
 		RequestedCpus:   2,
@@ -85,11 +127,13 @@ func createTestPod(n int) PodMetaData {
 		}
 		p.containers = append(p.containers,
 			Container{
-				CID:  cid,
-				PID:  pid,
-				Name: cid,
-				Cpus: i + 1,
-				QS:   Guaranteed,
+				CID:       cid,
+				PID:       pid,
+				Name:      cid,
+				Cpus:      i + 1,
+				QS:        Guaranteed,
+				Namespace: pid,
+				PodQS:     Guaranteed,
 			},
 		)
 		p.containersResources = append(p.containersResources,
@@ -102,6 +146,8 @@ func createTestPod(n int) PodMetaData {
 		p.expectations.ContainerResources = append(p.expectations.ContainerResources,
 			ctlplaneapi.AllocatedContainerResource{
 				ContainerID: cid,
+				QoS:         ctlplaneapi.QoSClass_GUARANTEED,
+				Exclusive:   true,
 				CPUSet: []ctlplaneapi.CPUBucket{
 					{
 						StartCPU: 0,
@@ -110,6 +156,13 @@ func createTestPod(n int) PodMetaData {
 				},
 			},
 		)
+		p.expectations.ContainerResults = append(p.expectations.ContainerResults,
+			ctlplaneapi.ContainerResult{
+				ContainerID: cid,
+				Status:      ctlplaneapi.ContainerStatus_SUCCESS,
+				Reason:      resultAllocated,
+			},
+		)
 	}
 	return p
 }
@@ -144,11 +197,13 @@ func modifyTestPod(p PodMetaData, d int, u int) PodMetaData {
 		}
 		mp.containers = append(mp.containers,
 			Container{
-				CID:  p.containers[i].CID,
-				PID:  p.containers[i].PID,
-				Name: p.containers[i].Name,
-				Cpus: cpus,
-				QS:   Guaranteed,
+				CID:       p.containers[i].CID,
+				PID:       p.containers[i].PID,
+				Name:      p.containers[i].Name,
+				Cpus:      cpus,
+				QS:        Guaranteed,
+				Namespace: p.containers[i].Namespace,
+				PodQS:     Guaranteed,
 			},
 		)
 		mp.containersResources = append(mp.containersResources,
@@ -161,6 +216,8 @@ func modifyTestPod(p PodMetaData, d int, u int) PodMetaData {
 		mp.expectations.ContainerResources = append(mp.expectations.ContainerResources,
 			ctlplaneapi.AllocatedContainerResource{
 				ContainerID: p.containers[i].CID,
+				QoS:         ctlplaneapi.QoSClass_GUARANTEED,
+				Exclusive:   true,
 				CPUSet: []ctlplaneapi.CPUBucket{
 					{
 						StartCPU: 0,
@@ -169,6 +226,24 @@ func modifyTestPod(p PodMetaData, d int, u int) PodMetaData {
 				},
 			},
 		)
+		result := ctlplaneapi.ContainerResult{
+			ContainerID: p.containers[i].CID,
+			Status:      ctlplaneapi.ContainerStatus_SUCCESS,
+			Reason:      resultUpdated,
+		}
+		if i >= u {
+			result.Status, result.Reason = ctlplaneapi.ContainerStatus_SKIPPED, resultUnchanged
+		}
+		mp.expectations.ContainerResults = append(mp.expectations.ContainerResults, result)
+	}
+	for i := len(p.containers) - d; i < len(p.containers); i++ {
+		mp.expectations.ContainerResults = append(mp.expectations.ContainerResults,
+			ctlplaneapi.ContainerResult{
+				ContainerID: p.containers[i].CID,
+				Status:      ctlplaneapi.ContainerStatus_SUCCESS,
+				Reason:      resultDeleted,
+			},
+		)
 	}
 
 	return mp
@@ -181,9 +256,12 @@ func TestNewDaemonNoState(t *testing.T) {
 	require.Nil(t, err)
 	assert.NotNil(t, d)
 	expectedState := DaemonState{
-		CGroupPath: "testdata/no_state",
-		Pods:       make(map[string]PodMetadata),
-		StatePath:  daemonStateFile,
+		CGroupPath:    "testdata/no_state",
+		Pods:          make(map[string]PodMetadata),
+		StatePath:     daemonStateFile,
+		CgroupVersion: DetectCgroupVersion(),
+
+		cgroupRetryAttempts: defaultCgroupRetryAttempts,
 	}
 	expectedState.AvailableCPUs = append(expectedState.AvailableCPUs,
 		ctlplaneapi.CPUBucket{
@@ -201,9 +279,12 @@ func TestCreateDaemonWithState(t *testing.T) {
 	assert.NotNil(t, d)
 
 	expectedState := DaemonState{
-		CGroupPath: "testdata/with_state/",
-		Pods:       make(map[string]PodMetadata),
-		StatePath:  "testdata/with_state/daemon.state",
+		CGroupPath:    "testdata/with_state/",
+		Pods:          make(map[string]PodMetadata),
+		StatePath:     "testdata/with_state/daemon.state",
+		CgroupVersion: DetectCgroupVersion(),
+
+		cgroupRetryAttempts: defaultCgroupRetryAttempts,
 	}
 	expectedState.AvailableCPUs = append(expectedState.AvailableCPUs,
 		ctlplaneapi.CPUBucket{
@@ -244,6 +325,7 @@ func TestCreateAndModifyPodDefaultPolity(t *testing.T) {
 		m.On("AssignContainer", c, &d.state).Return(nil).Once()
 	}
 	allocCPUs, err := d.CreatePod(
+		context.Background(),
 		&ctlplaneapi.CreatePodRequest{
 			PodId:        p.pid,
 			PodName:      p.name,
@@ -255,7 +337,7 @@ func TestCreateAndModifyPodDefaultPolity(t *testing.T) {
 
 	assert.Nil(t, err)
 	if err == nil {
-		assert.Equal(t, p.expectations, *allocCPUs)
+		assert.Equal(t, p.expectations, withoutAllocationTime(t, *allocCPUs))
 	}
 	del := 2
 	mod := 1
@@ -280,6 +362,7 @@ func TestCreateAndModifyPodDefaultPolity(t *testing.T) {
 	}
 
 	allocCPUs, err = d.UpdatePod(
+		context.Background(),
 		&ctlplaneapi.UpdatePodRequest{
 			PodId:      p.pid,
 			Resources:  mp.resources,
@@ -289,7 +372,7 @@ func TestCreateAndModifyPodDefaultPolity(t *testing.T) {
 	assert.Nil(t, err)
 	if err == nil {
 		assert.Equal(t, 1, len(allocCPUs.ContainerResources))
-		assert.Equal(t, mp.expectations, *allocCPUs)
+		assert.Equal(t, mp.expectations, withoutAllocationTime(t, *allocCPUs))
 	}
 }
 
@@ -308,6 +391,7 @@ func TestCreatePodDefaultPolicyNoSuffcientCPUsError(t *testing.T) {
 		).Once()
 	}
 	allocCPUs, err := d.CreatePod(
+		context.Background(),
 		&ctlplaneapi.CreatePodRequest{
 			PodId:        p.pid,
 			PodName:      p.name,
@@ -318,7 +402,13 @@ func TestCreatePodDefaultPolicyNoSuffcientCPUsError(t *testing.T) {
 	)
 	expErr := DaemonError{ErrorType: CpusNotAvailable, ErrorMessage: " No Cpus avaialbe!"}
 	assert.Equal(t, expErr, err)
-	assert.Nil(t, allocCPUs)
+	require.NotNil(t, allocCPUs)
+	assert.Empty(t, allocCPUs.ContainerResources)
+	assert.Equal(t, ctlplaneapi.ContainerResult{
+		ContainerID: p.containers[2].CID,
+		Status:      ctlplaneapi.ContainerStatus_FAILED,
+		Reason:      expErr.Error(),
+	}, allocCPUs.ContainerResults[2], "the biggest container is allocated first")
 }
 
 func TestDeletePodDefaultPolicy(t *testing.T) {
@@ -333,7 +423,7 @@ func TestDeletePodDefaultPolicy(t *testing.T) {
 	d.state.Pods[p.pid] = meta
 	m.On("DeleteContainer", p.containers[0], &d.state).Return(nil).Once()
 	m.On("DeleteContainer", p.containers[1], &d.state).Return(nil).Once()
-	err = d.DeletePod(&ctlplaneapi.DeletePodRequest{PodId: p.pid})
+	_, err = d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: p.pid})
 	assert.Nil(t, err)
 }
 
@@ -344,7 +434,7 @@ func TestDeletePodDefaultPolicyError(t *testing.T) {
 	p := createTestPod(1)
 	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
 	require.Nil(t, err)
-	err = d.DeletePod(&ctlplaneapi.DeletePodRequest{PodId: p.pid})
+	_, err = d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: p.pid})
 	expErr := DaemonError{ErrorType: PodNotFound, ErrorMessage: "Pod not found in CPU State"}
 	assert.Equal(t, expErr, err)
 }
@@ -362,9 +452,11 @@ func TestDaemonCreatePodRollbacks(t *testing.T) {
 	m.On("AssignContainer", p.containers[1], &d.state).Return(
 		DaemonError{ErrorType: CpusNotAvailable, ErrorMessage: " No Cpus avaialbe!"},
 	).Once()
+	m.On("DeleteContainer", p.containers[0], &d.state).Return(nil).Once()
 	m.On("ClearContainer", p.containers[0], &d.state).Return(nil).Once()
 
 	allocCPUs, err := d.CreatePod(
+		context.Background(),
 		&ctlplaneapi.CreatePodRequest{
 			PodId:        p.pid,
 			PodName:      p.name,
@@ -375,7 +467,12 @@ func TestDaemonCreatePodRollbacks(t *testing.T) {
 	)
 	expErr := DaemonError{ErrorType: CpusNotAvailable, ErrorMessage: " No Cpus avaialbe!"}
 	assert.Equal(t, expErr, err)
-	assert.Nil(t, allocCPUs)
+	require.NotNil(t, allocCPUs)
+	assert.Empty(t, allocCPUs.ContainerResources)
+	assert.Equal(t, []ctlplaneapi.ContainerResult{
+		{ContainerID: p.containers[0].CID, Status: ctlplaneapi.ContainerStatus_SKIPPED, Reason: resultRolledBack},
+		{ContainerID: p.containers[1].CID, Status: ctlplaneapi.ContainerStatus_FAILED, Reason: expErr.Error()},
+	}, allocCPUs.ContainerResults)
 	assert.NotContains(t, d.state.Pods, p.pid)
 }
 
@@ -393,7 +490,7 @@ func TestDeletePodContinuesDeletionAfterError(t *testing.T) {
 	m.On("DeleteContainer", p.containers[0], &d.state).Return(expectedError).Once()
 	m.On("DeleteContainer", p.containers[1], &d.state).Return(nil).Once()
 
-	err = d.DeletePod(&ctlplaneapi.DeletePodRequest{PodId: p.pid})
+	_, err = d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: p.pid})
 
 	assert.Equal(t, failedContainersErrors{failedContainer{p.containers[0].CID, expectedError}}, err)
 	m.AssertExpectations(t)
@@ -419,6 +516,7 @@ func TestUpdatePodContinuesAfterError(t *testing.T) {
 		m.On("AssignContainer", c, &d.state).Return(nil).Once()
 	}
 	allocCPUs, err := d.CreatePod(
+		context.Background(),
 		&ctlplaneapi.CreatePodRequest{
 			PodId:        p.pid,
 			PodName:      p.name,
@@ -430,7 +528,7 @@ func TestUpdatePodContinuesAfterError(t *testing.T) {
 
 	assert.Nil(t, err)
 	if err == nil {
-		assert.Equal(t, p.expectations, *allocCPUs)
+		assert.Equal(t, p.expectations, withoutAllocationTime(t, *allocCPUs))
 	}
 	del := 2
 	mod := 1
@@ -469,6 +567,7 @@ func TestUpdatePodContinuesAfterError(t *testing.T) {
 	}
 
 	_, err = d.UpdatePod(
+		context.Background(),
 		&ctlplaneapi.UpdatePodRequest{
 			PodId:      p.pid,
 			Resources:  mp.resources,
@@ -478,3 +577,1110 @@ func TestUpdatePodContinuesAfterError(t *testing.T) {
 	assert.Equal(t, expectedErr, err)
 	assert.Empty(t, d.state.Pods[p.pid].Containers) // because update pod failed
 }
+
+func TestCreatePodMemoryPinningFromNamespace(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	d, err := New(
+		"testdata/no_state",
+		"testdata/node_info",
+		daemonStateFile,
+		&m,
+		logr.Discard(),
+		WithMemoryPinningNamespaces([]string{"pinned"}),
+	)
+	require.Nil(t, err)
+	m.On("AssignContainer", mock.Anything, &d.state).Return(nil)
+
+	testCases := []struct {
+		pid           string
+		namespace     string
+		memoryPinning ctlplaneapi.MemoryPinning
+		expected      ctlplaneapi.MemoryPinning
+	}{
+		{"pod1", "pinned", ctlplaneapi.MemoryPinning_MEMORY_PINNING_DEFAULT, ctlplaneapi.MemoryPinning_MEMORY_PINNING_ENABLED},
+		{"pod2", "pinned", ctlplaneapi.MemoryPinning_MEMORY_PINNING_DISABLED, ctlplaneapi.MemoryPinning_MEMORY_PINNING_DISABLED},
+		{"pod3", "other", ctlplaneapi.MemoryPinning_MEMORY_PINNING_DEFAULT, ctlplaneapi.MemoryPinning_MEMORY_PINNING_DEFAULT},
+		{"pod4", "other", ctlplaneapi.MemoryPinning_MEMORY_PINNING_ENABLED, ctlplaneapi.MemoryPinning_MEMORY_PINNING_ENABLED},
+	}
+
+	for _, testCase := range testCases {
+		p := createTestPod(1)
+		_, err := d.CreatePod(context.Background(), &ctlplaneapi.CreatePodRequest{
+			PodId:         testCase.pid,
+			PodName:       testCase.pid,
+			PodNamespace:  testCase.namespace,
+			Resources:     p.resources,
+			Containers:    p.containersResources,
+			MemoryPinning: testCase.memoryPinning,
+		})
+		require.Nil(t, err)
+		assert.Equal(t, testCase.expected, d.state.Pods[testCase.pid].MemoryPinning, testCase.pid)
+	}
+}
+
+func TestUpdatePodChangesMemoryPinning(t *testing.T) {
+	d := newTestDaemon(
+		t,
+		NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)),
+		WithMemoryPinningNamespaces([]string{"pinned"}),
+	)
+	p := createTestPod(1)
+	createPodForRepack(t, d, p)
+	cid := p.containers[0].CID
+	cpus := d.state.Allocated[cid]
+	require.Equal(t, "", d.state.MemoryNodes[cid])
+
+	testCases := []struct {
+		namespace     string
+		memoryPinning ctlplaneapi.MemoryPinning
+		expected      ctlplaneapi.MemoryPinning
+		memoryNodes   string
+	}{
+		{p.namespace, ctlplaneapi.MemoryPinning_MEMORY_PINNING_ENABLED, ctlplaneapi.MemoryPinning_MEMORY_PINNING_ENABLED, "0"},
+		{p.namespace, ctlplaneapi.MemoryPinning_MEMORY_PINNING_DEFAULT, ctlplaneapi.MemoryPinning_MEMORY_PINNING_DEFAULT, ""},
+		{"pinned", ctlplaneapi.MemoryPinning_MEMORY_PINNING_DEFAULT, ctlplaneapi.MemoryPinning_MEMORY_PINNING_ENABLED, "0"},
+		{"pinned", ctlplaneapi.MemoryPinning_MEMORY_PINNING_DISABLED, ctlplaneapi.MemoryPinning_MEMORY_PINNING_DISABLED, ""},
+	}
+
+	for _, testCase := range testCases {
+		pod := d.state.Pods[p.pid]
+		pod.Namespace = testCase.namespace
+		d.state.Pods[p.pid] = pod
+		_, err := d.UpdatePod(context.Background(), &ctlplaneapi.UpdatePodRequest{
+			PodId:         p.pid,
+			Resources:     p.resources,
+			Containers:    p.containersResources,
+			MemoryPinning: testCase.memoryPinning,
+		})
+		require.Nil(t, err)
+		assert.Equal(t, testCase.expected, d.state.Pods[p.pid].MemoryPinning)
+		assert.Equal(t, testCase.memoryNodes, d.state.MemoryNodes[cid])
+		assert.Equal(t, cpus, d.state.Allocated[cid])
+	}
+}
+
+func TestDaemonCreatePodRollbacksOnlyAssignedContainers(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+	require.Nil(t, err)
+	p := createTestPod(4)
+
+	assignErr := DaemonError{ErrorType: CpusNotAvailable, ErrorMessage: " No Cpus avaialbe!"}
+	// containers are assigned starting from the biggest one
+	m.On("AssignContainer", p.containers[3], &d.state).Return(nil).Once()
+	m.On("AssignContainer", p.containers[2], &d.state).Return(nil).Once()
+	m.On("AssignContainer", p.containers[1], &d.state).Return(assignErr).Once()
+	for _, c := range p.containers[2:] {
+		m.On("DeleteContainer", c, &d.state).Return(nil).Once()
+		m.On("ClearContainer", c, &d.state).Return(nil).Once()
+	}
+
+	_, err = d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+			Containers:   p.containersResources,
+		},
+	)
+	assert.Equal(t, assignErr, err)
+	m.AssertExpectations(t)
+	m.AssertNotCalled(t, "DeleteContainer", p.containers[1], &d.state)
+	m.AssertNotCalled(t, "ClearContainer", p.containers[1], &d.state)
+	m.AssertNotCalled(t, "AssignContainer", p.containers[0], &d.state)
+	assert.NotContains(t, d.state.Pods, p.pid)
+}
+
+func TestDaemonCreatePodRollbackRestoresAllocations(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+	require.Nil(t, err)
+	p := createTestPod(2)
+	expectedState := d.state.snapshot()
+
+	// allocator which takes cpus, but does not release them
+	takeCpu := func(args mock.Arguments) {
+		c := args.Get(0).(Container)
+		cpus, err := d.state.Topology.Take(c.Cpus)
+		require.Nil(t, err)
+		d.state.Allocated[c.CID] = CPUSetFromBucketList(nil).Merge(CPUSet{cpus[0]: struct{}{}}).ToBucketList()
+	}
+	m.On("AssignContainer", p.containers[1], &d.state).Return(nil).Run(takeCpu).Once()
+	m.On("AssignContainer", p.containers[0], &d.state).Return(
+		DaemonError{ErrorType: RuntimeError, ErrorMessage: "cgroup error"},
+	).Run(takeCpu).Once()
+	m.On("DeleteContainer", p.containers[1], &d.state).Return(nil).Once()
+	m.On("ClearContainer", p.containers[1], &d.state).Return(nil).Once()
+
+	_, err = d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+			Containers:   p.containersResources,
+		},
+	)
+	assert.NotNil(t, err)
+	m.AssertExpectations(t)
+	assert.Empty(t, d.state.Allocated)
+	assert.Equal(t, expectedState.topology, d.state.Topology)
+	assert.Equal(t, expectedState.availableCPUs, d.state.AvailableCPUs)
+}
+
+func TestDeletePodRemovesLeftAllocations(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	p := createTestPod(2)
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+	require.Nil(t, err)
+	meta := d.state.Pods[p.pid]
+	meta.Containers = p.containers
+	d.state.Pods[p.pid] = meta
+	for _, c := range p.containers {
+		d.state.Allocated[c.CID] = []ctlplaneapi.CPUBucket{{StartCPU: 1, EndCPU: 1}}
+	}
+	m.On("DeleteContainer", p.containers[0], &d.state).Return(nil).Once()
+	m.On("DeleteContainer", p.containers[1], &d.state).Return(DaemonError{ErrorMessage: "test"}).Once()
+
+	_, err = d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: p.pid})
+
+	assert.NotNil(t, err)
+	assert.Empty(t, d.state.Allocated)
+	m.AssertExpectations(t)
+}
+
+func TestDeletePodForgetsCgroupPaths(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	p := createTestPod(2)
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+	require.Nil(t, err)
+	p.containers[1].QS = Burstable
+	meta := d.state.Pods[p.pid]
+	meta.Containers = p.containers
+	meta.LeaseExpired = true // guaranteed container is not allocated, but its cgroup was written
+	d.state.Pods[p.pid] = meta
+	for _, c := range p.containers {
+		d.state.setCgroupPath(c.CID, "/cgroup/"+c.CID)
+	}
+	m.On("DeleteContainer", p.containers[1], &d.state).Return(nil).Once()
+
+	_, err = d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: p.pid})
+	require.Nil(t, err)
+
+	assert.Empty(t, d.state.CgroupPaths)
+	m.AssertExpectations(t)
+}
+
+func TestPodMetadataKeepsLabelsAndAnnotations(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	p := createTestPod(1)
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+	require.Nil(t, err)
+	m.On("AssignContainer", p.containers[0], &d.state).Return(nil).Once()
+	_, err = d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+			Containers:   p.containersResources,
+			Labels:       map[string]string{"ctlplane.intel.com/tier": "gold"},
+			Annotations:  map[string]string{"ctlplane.intel.com/smt": "off"},
+		},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"ctlplane.intel.com/tier": "gold"}, d.state.Pods[p.pid].Labels)
+	assert.Equal(t, map[string]string{"ctlplane.intel.com/smt": "off"}, d.state.Pods[p.pid].Annotations)
+
+	_, err = d.UpdatePod(
+		context.Background(),
+		&ctlplaneapi.UpdatePodRequest{
+			PodId:       p.pid,
+			Resources:   p.resources,
+			Containers:  p.containersResources,
+			Annotations: map[string]string{"ctlplane.intel.com/smt": "on"},
+		},
+	)
+	require.Nil(t, err)
+	assert.Nil(t, d.state.Pods[p.pid].Labels)
+	assert.Equal(t, map[string]string{"ctlplane.intel.com/smt": "on"}, d.state.Pods[p.pid].Annotations)
+}
+
+func TestGetPodReportsCgroupPath(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	p := createTestPod(1)
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+	require.Nil(t, err)
+	m.On("AssignContainer", p.containers[0], &d.state).Return(nil).Run(func(args mock.Arguments) {
+		d.state.setCgroupPath(p.containers[0].CID, "/cgroup/slice")
+	}).Once()
+	_, err = d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+			Containers:   p.containersResources,
+		},
+	)
+	require.Nil(t, err)
+
+	reply, err := d.GetPod(context.Background(), &ctlplaneapi.GetPodRequest{PodId: p.pid})
+
+	require.Nil(t, err)
+	require.Len(t, reply.ContainerResources, 1)
+	assert.Equal(t, "/cgroup/slice", reply.ContainerResources[0].CgroupPath)
+}
+
+func TestUpdatePodClearsContainerLeavingGuaranteed(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+	require.Nil(t, err)
+	p := createTestPod(2)
+
+	for _, c := range p.containers {
+		m.On("AssignContainer", c, &d.state).Return(nil).Once()
+	}
+	_, err = d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+			Containers:   p.containersResources,
+		},
+	)
+	require.Nil(t, err)
+
+	// first container becomes burstable, second one stays guaranteed with more cpus
+	p.containersResources[0].Resources.LimitCpus = 2
+	p.containersResources[1].Resources.RequestedCpus = 3
+	p.containersResources[1].Resources.LimitCpus = 3
+	burstable := p.containers[0]
+	burstable.QS = Burstable
+	burstable.PodQS = Burstable
+	resized := p.containers[1]
+	resized.Cpus = 3
+	resized.PodQS = Burstable
+
+	m.On("DeleteContainer", p.containers[0], &d.state).Return(nil).Once()
+	m.On("ClearContainer", p.containers[0], &d.state).Return(nil).Once()
+	m.On("AssignContainer", burstable, &d.state).Return(nil).Once()
+	m.On("DeleteContainer", p.containers[1], &d.state).Return(nil).Once()
+	m.On("AssignContainer", resized, &d.state).Return(nil).Once()
+
+	_, err = d.UpdatePod(
+		context.Background(),
+		&ctlplaneapi.UpdatePodRequest{
+			PodId:      p.pid,
+			Resources:  p.resources,
+			Containers: p.containersResources,
+		},
+	)
+	assert.Nil(t, err)
+	m.AssertExpectations(t)
+	m.AssertNotCalled(t, "ClearContainer", p.containers[1], &d.state)
+}
+
+func TestRequiresReallocation(t *testing.T) {
+	guaranteed := Container{CID: "c", PID: "p", Name: "c", Cpus: 2, QS: Guaranteed}
+	testCases := []struct {
+		name     string
+		wanted   func(c Container) Container
+		expected bool
+	}{
+		{"cpus changed", func(c Container) Container { c.Cpus = 3; return c }, true},
+		{"qos changed", func(c Container) Container { c.QS = Burstable; return c }, true},
+		{"name changed", func(c Container) Container { c.Name = "n"; return c }, false},
+		{"nothing changed", func(c Container) Container { return c }, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, requiresReallocation(guaranteed, tc.wanted(guaranteed)))
+		})
+	}
+
+	burstable := Container{CID: "c", PID: "p", Name: "c", Cpus: 1, QS: Burstable}
+	bestEffort := Container{CID: "c", PID: "p", Name: "c", Cpus: 2, QS: BestEffort}
+	assert.False(t, requiresReallocation(burstable, bestEffort))
+}
+
+func TestUpdatePodMemoryOnlyChangeSkipsReallocation(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+	require.Nil(t, err)
+	p := createTestPod(1)
+	p.containersResources[0].Resources.LimitCpus = 2
+	p.containers[0].QS = Burstable
+	p.containers[0].PodQS = Burstable
+
+	m.On("AssignContainer", p.containers[0], &d.state).Return(nil).Once()
+	_, err = d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+			Containers:   p.containersResources,
+		},
+	)
+	require.Nil(t, err)
+
+	p.containersResources[0].Resources.RequestedMemory = newQuantityAsBytes(4)
+	p.containersResources[0].ContainerName = "renamed"
+
+	_, err = d.UpdatePod(
+		context.Background(),
+		&ctlplaneapi.UpdatePodRequest{
+			PodId:      p.pid,
+			Resources:  p.resources,
+			Containers: p.containersResources,
+		},
+	)
+	assert.Nil(t, err)
+	m.AssertExpectations(t)
+	m.AssertNotCalled(t, "DeleteContainer", mock.Anything, mock.Anything)
+	require.Len(t, d.state.Pods[p.pid].Containers, 1)
+	assert.Equal(t, "renamed", d.state.Pods[p.pid].Containers[0].Name)
+}
+
+func TestUpdatePodHintsPreviouslyAllocatedCpus(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+	require.Nil(t, err)
+	p := createTestPod(1)
+
+	m.On("AssignContainer", p.containers[0], &d.state).Return(nil).Run(func(args mock.Arguments) {
+		d.state.Allocated[p.containers[0].CID] = []ctlplaneapi.CPUBucket{{StartCPU: 4, EndCPU: 4}}
+	}).Once()
+	_, err = d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+			Containers:   p.containersResources,
+		},
+	)
+	require.Nil(t, err)
+
+	p.containersResources[0].Resources.RequestedCpus = 2
+	p.containersResources[0].Resources.LimitCpus = 2
+	resized := p.containers[0]
+	resized.Cpus = 2
+	m.On("DeleteContainer", p.containers[0], &d.state).Return(nil).Once()
+	m.On("AssignContainer", resized, &d.state).Return(nil).Run(func(args mock.Arguments) {
+		assert.Equal(t, CPUSet{4: struct{}{}}, d.state.allocationHint(resized.CID))
+	}).Once()
+
+	_, err = d.UpdatePod(
+		context.Background(),
+		&ctlplaneapi.UpdatePodRequest{
+			PodId:      p.pid,
+			Resources:  p.resources,
+			Containers: p.containersResources,
+		},
+	)
+	assert.Nil(t, err)
+	m.AssertExpectations(t)
+	assert.Empty(t, d.state.allocationHint(resized.CID))
+}
+
+func TestUpdatePodResizesSoftPinnedBurstableContainer(t *testing.T) {
+	allocator := newMockedNumaPerNamespaceAllocator(1, false)
+	allocator.ctrl = newMockedCgroups()
+	allocator.EnableBurstableSoftPinning()
+	d := newTestDaemon(t, NewStaticPolocy(allocator))
+	p := createTestPod(1)
+	p.resources.RequestedCpus, p.resources.LimitCpus = 1, 4
+	p.containersResources[0].Resources.RequestedCpus = 1
+	p.containersResources[0].Resources.LimitCpus = 4
+	_, err := d.CreatePod(context.Background(), createPodRequest(p))
+	require.Nil(t, err)
+	require.Equal(t, Burstable, d.state.Pods[p.pid].Containers[0].QS)
+	assert.Equal(t, 1, CPUSetFromBucketList(d.state.Allocated[p.containers[0].CID]).Count())
+
+	p.resources.RequestedCpus = 2
+	p.containersResources[0].Resources.RequestedCpus = 2
+	_, err = d.UpdatePod(
+		context.Background(),
+		&ctlplaneapi.UpdatePodRequest{
+			PodId:      p.pid,
+			Resources:  p.resources,
+			Containers: p.containersResources,
+		},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, 2, d.state.Pods[p.pid].Containers[0].Cpus)
+	assert.Equal(t, 2, CPUSetFromBucketList(d.state.Allocated[p.containers[0].CID]).Count())
+}
+
+func TestQoSToQoSClass(t *testing.T) {
+	assert.Equal(t, ctlplaneapi.QoSClass_GUARANTEED, Guaranteed.toQoSClass())
+	assert.Equal(t, ctlplaneapi.QoSClass_BURSTABLE, Burstable.toQoSClass())
+	assert.Equal(t, ctlplaneapi.QoSClass_BEST_EFFORT, BestEffort.toQoSClass())
+}
+
+func TestCreatePodReplyDescribesSharedContainer(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+	require.Nil(t, err)
+	p := createTestPod(1)
+	p.containersResources[0].Resources.LimitCpus = 2
+	p.containers[0].QS = Burstable
+	p.containers[0].PodQS = Burstable
+
+	m.On("AssignContainer", p.containers[0], &d.state).Return(nil).Run(func(args mock.Arguments) {
+		d.state.setMemoryNodes(p.containers[0].CID, "0")
+	}).Once()
+	reply, err := d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+			Containers:   p.containersResources,
+		},
+	)
+	require.Nil(t, err)
+	require.Len(t, reply.ContainerResources, 1)
+	assert.Equal(t, ctlplaneapi.QoSClass_BURSTABLE, reply.ContainerResources[0].QoS)
+	assert.Equal(t, "0", reply.ContainerResources[0].MemoryNodes)
+	assert.False(t, reply.ContainerResources[0].Exclusive)
+}
+
+func TestMemoryNodesSurviveRestart(t *testing.T) {
+	m := MockedPolicy{}
+	d := newTestDaemon(t, &m)
+	p := createTestPod(1)
+	m.On("AssignContainer", p.containers[0], &d.state).Return(nil).Run(func(args mock.Arguments) {
+		d.state.setMemoryNodes(p.containers[0].CID, "1")
+	}).Once()
+	_, err := d.CreatePod(context.Background(), createPodRequest(p))
+	require.Nil(t, err)
+
+	s, err := newState("testdata/no_state", "testdata/node_info", d.state.StatePath)
+
+	require.Nil(t, err)
+	assert.Equal(t, "1", s.getMemoryNodes(p.containers[0].CID))
+}
+
+func TestNewDaemonFailsWithInvalidExclusiveCpusCap(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	for _, percent := range []int{0, -1, 101} {
+		_, err := New(
+			"testdata/no_state",
+			"testdata/node_info",
+			daemonStateFile,
+			&MockedPolicy{},
+			logr.Discard(),
+			WithExclusiveCpusCap(percent),
+		)
+		var daemonErr DaemonError
+		require.ErrorAs(t, err, &daemonErr)
+		assert.Equal(t, ConfigurationError, daemonErr.ErrorType)
+	}
+}
+
+func TestCreatePodFailsIfExclusiveCpusCapExceeded(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	d, err := New(
+		"testdata/no_state",
+		"testdata/node_info",
+		daemonStateFile,
+		&m,
+		logr.Discard(),
+		WithExclusiveCpusCap(50), // 4 out of 8 cpus
+	)
+	require.Nil(t, err)
+	p := createTestPod(3) // takes 3, 2 and 1 cpus (biggest first)
+
+	c := p.containers[2]
+	m.On("AssignContainer", c, &d.state).Return(nil).Run(func(args mock.Arguments) {
+		d.state.Allocated[c.CID] = []ctlplaneapi.CPUBucket{{StartCPU: 1, EndCPU: 3}}
+	}).Once()
+	m.On("DeleteContainer", c, &d.state).Return(nil).Once()
+	m.On("ClearContainer", c, &d.state).Return(nil).Once()
+	exceeded := testutil.ToFloat64(metrics.ExclusiveCpusCapExceeded)
+
+	_, err = d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+			Containers:   p.containersResources,
+		},
+	)
+
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, ExclusiveCpusCapExceeded, daemonErr.ErrorType)
+	assert.Equal(t, exceeded+1, testutil.ToFloat64(metrics.ExclusiveCpusCapExceeded))
+	m.AssertExpectations(t)
+	m.AssertNotCalled(t, "AssignContainer", p.containers[1], &d.state)
+	m.AssertNotCalled(t, "AssignContainer", p.containers[0], &d.state)
+}
+
+func TestExclusiveCpusCapSkipsReallocatedContainer(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	d, err := New(
+		"testdata/no_state",
+		"testdata/node_info",
+		daemonStateFile,
+		&MockedPolicy{},
+		logr.Discard(),
+		WithExclusiveCpusCap(50),
+	)
+	require.Nil(t, err)
+	p := createTestPod(1)
+	c := p.containers[0]
+	d.state.Pods[p.pid] = PodMetadata{PID: p.pid, Containers: []Container{c}}
+	d.state.Allocated[c.CID] = []ctlplaneapi.CPUBucket{{StartCPU: 1, EndCPU: 3}}
+
+	c.Cpus = 4
+	assert.Nil(t, d.checkExclusiveCpusCap(c))
+	c.Cpus = 5
+	assert.NotNil(t, d.checkExclusiveCpusCap(c))
+	c.QS = Burstable
+	assert.Nil(t, d.checkExclusiveCpusCap(c))
+}
+
+func TestCreatePodAssignsBiggestContainersFirst(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+	require.Nil(t, err)
+	p := createTestPod(3)
+
+	assignOrder := []string{}
+	for _, c := range p.containers {
+		m.On("AssignContainer", c, &d.state).Return(nil).Run(func(args mock.Arguments) {
+			assignOrder = append(assignOrder, args.Get(0).(Container).CID)
+		}).Once()
+	}
+
+	reply, err := d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+			Containers:   p.containersResources,
+		},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"testCid-2", "testCid-1", "testCid-0"}, assignOrder)
+	require.Len(t, reply.ContainerResources, 3)
+	for i, c := range p.containers {
+		assert.Equal(t, c.CID, reply.ContainerResources[i].ContainerID)
+	}
+	assert.Equal(t, p.containers, d.state.Pods[p.pid].Containers)
+}
+
+func TestSortedBySizeIsStable(t *testing.T) {
+	containers := []Container{
+		{CID: "a", Cpus: 1},
+		{CID: "b", Cpus: 2},
+		{CID: "c", Cpus: 1},
+		{CID: "d", Cpus: 2},
+	}
+	sorted := sortedBySize(containers)
+	assert.Equal(t, []string{"b", "d", "a", "c"}, []string{sorted[0].CID, sorted[1].CID, sorted[2].CID, sorted[3].CID})
+	assert.Equal(t, "a", containers[0].CID) // input is not modified
+}
+
+func TestNewDaemonReleasesManagedCpusOnClose(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	defer os.Remove(daemonStateFile + managedCpusClaimSuffix)
+
+	d, err := New(
+		"testdata/no_state",
+		"testdata/node_info",
+		daemonStateFile,
+		&MockedPolicy{},
+		logr.Discard(),
+		WithManagedCpus(CPUSet{2: {}, 4: {}}),
+	)
+	require.Nil(t, err)
+
+	_, err = New(
+		"testdata/no_state",
+		"testdata/node_info",
+		daemonStateFile,
+		&MockedPolicy{},
+		logr.Discard(),
+		WithManagedCpus(CPUSet{2: {}, 4: {}}),
+	)
+	require.NotNil(t, err)
+
+	d.Close()
+	d, err = New(
+		"testdata/no_state",
+		"testdata/node_info",
+		daemonStateFile,
+		&MockedPolicy{},
+		logr.Discard(),
+		WithManagedCpus(CPUSet{2: {}, 4: {}}),
+	)
+	require.Nil(t, err)
+	d.Close()
+}
+
+func TestUpdatePodRejectsRecentlyDeletedPod(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+	require.Nil(t, err)
+	p := createTestPod(1)
+	m.On("AssignContainer", p.containers[0], &d.state).Return(nil).Once()
+	m.On("DeleteContainer", p.containers[0], &d.state).Return(nil).Once()
+	_, err = d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+			Containers:   p.containersResources,
+		},
+	)
+	require.Nil(t, err)
+	_, err = d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: p.pid})
+	require.Nil(t, err)
+	rejected := testutil.ToFloat64(metrics.RecentlyDeletedPodUpdates)
+
+	_, err = d.UpdatePod(
+		context.Background(),
+		&ctlplaneapi.UpdatePodRequest{
+			PodId:      p.pid,
+			Resources:  p.resources,
+			Containers: p.containersResources,
+		},
+	)
+
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, PodNotFound, daemonErr.ErrorType)
+	assert.Contains(t, daemonErr.ErrorMessage, "recently deleted")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, rejected+1, testutil.ToFloat64(metrics.RecentlyDeletedPodUpdates))
+	assert.NotContains(t, d.state.Pods, p.pid)
+	m.AssertExpectations(t)
+}
+
+func TestUpdatePodOfUnknownPodIsNotFound(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &MockedPolicy{}, logr.Discard())
+	require.Nil(t, err)
+	p := createTestPod(1)
+
+	_, err = d.UpdatePod(
+		context.Background(),
+		&ctlplaneapi.UpdatePodRequest{
+			PodId:      p.pid,
+			Resources:  p.resources,
+			Containers: p.containersResources,
+		},
+	)
+
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, PodNotFound, daemonErr.ErrorType)
+	assert.NotContains(t, daemonErr.ErrorMessage, "recently deleted")
+}
+
+func TestNewDaemonFailsWithNegativeTombstoneTTL(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	_, err := New(
+		"testdata/no_state",
+		"testdata/node_info",
+		daemonStateFile,
+		&MockedPolicy{},
+		logr.Discard(),
+		WithTombstoneTTL(-time.Second),
+	)
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, ConfigurationError, daemonErr.ErrorType)
+}
+
+func TestDaemonErrorGRPCStatus(t *testing.T) {
+	assert.Equal(t, codes.NotFound, DaemonError{ErrorType: PodNotFound}.GRPCStatus().Code())
+	assert.Equal(t, codes.NotFound, DaemonError{ErrorType: ContainerNotFound}.GRPCStatus().Code())
+	assert.Equal(t, codes.Unavailable, DaemonError{ErrorType: CpusNotAvailable}.GRPCStatus().Code())
+	assert.Equal(t, codes.FailedPrecondition, DaemonError{ErrorType: NodeMismatch}.GRPCStatus().Code())
+	assert.Equal(t, "Daemon Error: test", DaemonError{ErrorMessage: "test"}.GRPCStatus().Message())
+}
+
+func TestGetPodReturnsAllocationTime(t *testing.T) {
+	m := MockedPolicy{}
+	p := createTestPod(2)
+	before := time.Now()
+	d := newDaemonWithLeasedPod(t, &m, p, 0)
+
+	podResources, err := d.GetPod(context.Background(), &ctlplaneapi.GetPodRequest{PodId: p.pid})
+
+	require.Nil(t, err)
+	assert.Equal(t, p.pid, podResources.PodID)
+	require.Len(t, podResources.ContainerResources, 2)
+	for i, c := range podResources.ContainerResources {
+		assert.Equal(t, p.containers[i].CID, c.ContainerID)
+		assert.False(t, c.AllocatedAt.Before(before))
+		assert.False(t, c.AllocatedAt.After(time.Now()))
+	}
+}
+
+func TestGetPodErrors(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &MockedPolicy{}, logr.Discard())
+	require.Nil(t, err)
+
+	_, err = d.GetPod(context.Background(), &ctlplaneapi.GetPodRequest{PodId: "unknown"})
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, PodNotFound, daemonErr.ErrorType)
+
+	_, err = d.GetPod(context.Background(), &ctlplaneapi.GetPodRequest{})
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, PodSpecError, daemonErr.ErrorType)
+}
+
+func TestGetContainer(t *testing.T) {
+	m := MockedPolicy{}
+	p := createTestPod(2)
+	d := newDaemonWithLeasedPod(t, &m, p, 0)
+
+	container, err := d.GetContainer(context.Background(), &ctlplaneapi.GetContainerRequest{PodId: p.pid, ContainerName: p.containers[1].Name})
+
+	require.Nil(t, err)
+	assert.Equal(t, p.containers[1].CID, container.ContainerID)
+	assert.False(t, container.AllocatedAt.IsZero())
+
+	var daemonErr DaemonError
+	_, err = d.GetContainer(context.Background(), &ctlplaneapi.GetContainerRequest{PodId: p.pid, ContainerName: "unknown"})
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, ContainerNotFound, daemonErr.ErrorType)
+
+	_, err = d.GetContainer(context.Background(), &ctlplaneapi.GetContainerRequest{PodId: "unknown", ContainerName: p.containers[1].Name})
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, PodNotFound, daemonErr.ErrorType)
+
+	_, err = d.GetContainer(context.Background(), &ctlplaneapi.GetContainerRequest{PodId: p.pid})
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, PodSpecError, daemonErr.ErrorType)
+}
+
+func TestListPodsSortedByPodID(t *testing.T) {
+	m := MockedPolicy{}
+	p := createTestPod(1)
+	d := newDaemonWithLeasedPod(t, &m, p, 0)
+	d.state.Pods["a-pod"] = PodMetadata{PID: "a-pod"}
+	d.publishReadState()
+
+	pods, err := d.ListPods(context.Background(), &ctlplaneapi.ListPodsRequest{})
+
+	require.Nil(t, err)
+	require.Len(t, pods, 2)
+	assert.Equal(t, "a-pod", pods[0].PodID)
+	assert.Empty(t, pods[0].ContainerResources)
+	assert.Equal(t, p.pid, pods[1].PodID)
+	assert.Len(t, pods[1].ContainerResources, 1)
+}
+
+func TestAllocationTimeIsReleasedWithContainer(t *testing.T) {
+	m := MockedPolicy{}
+	p := createTestPod(1)
+	d := newDaemonWithLeasedPod(t, &m, p, 0)
+	require.Contains(t, d.state.AllocatedAt, p.containers[0].CID)
+	m.On("DeleteContainer", p.containers[0], &d.state).Return(nil).Once()
+
+	_, err := d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: p.pid})
+	require.Nil(t, err)
+
+	assert.Empty(t, d.state.AllocatedAt)
+}
+
+func TestAllocationTimeIsPersisted(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	p := createTestPod(1)
+	d := newDaemonWithLeasedPod(t, &m, p, 0)
+
+	restarted, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+
+	require.Nil(t, err)
+	cid := p.containers[0].CID
+	assert.True(t, d.state.AllocatedAt[cid].Equal(restarted.state.AllocatedAt[cid]))
+}
+
+func TestStaleUpdatePodIsRejected(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	p := createTestPod(1)
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+	require.Nil(t, err)
+	m.On("AssignContainer", p.containers[0], &d.state).Return(nil).Once()
+	_, err = d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+			Containers:   p.containersResources,
+			Generation:   10,
+		},
+	)
+	require.Nil(t, err)
+	update := func(generation int64) error {
+		_, err := d.UpdatePod(
+			context.Background(),
+			&ctlplaneapi.UpdatePodRequest{
+				PodId:       p.pid,
+				Resources:   p.resources,
+				Containers:  p.containersResources,
+				Annotations: map[string]string{"generation": fmt.Sprint(generation)},
+				Generation:  generation,
+			},
+		)
+		return err
+	}
+
+	require.Nil(t, update(12))
+	err = update(11)
+
+	require.NotNil(t, err)
+	assert.Equal(t, StaleRequest, err.(DaemonError).ErrorType) //nolint: errorlint
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	assert.Equal(t, int64(12), d.state.Pods[p.pid].Generation)
+	assert.Equal(t, map[string]string{"generation": "12"}, d.state.Pods[p.pid].Annotations)
+	require.Nil(t, update(0), "requests without generation shall not be rejected")
+	assert.Equal(t, int64(12), d.state.Pods[p.pid].Generation)
+}
+
+func TestMisroutedPodRequestsAreRejected(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	p := createTestPod(1)
+	d, err := New(
+		"testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard(), WithNodeName("node-1"),
+	)
+	require.Nil(t, err)
+	m.On("AssignContainer", p.containers[0], &d.state).Return(nil).Once()
+	create := func(nodeName string) error {
+		_, err := d.CreatePod(
+			context.Background(),
+			&ctlplaneapi.CreatePodRequest{
+				PodId:        p.pid,
+				PodName:      p.name,
+				PodNamespace: p.namespace,
+				Resources:    p.resources,
+				Containers:   p.containersResources,
+				NodeName:     nodeName,
+			},
+		)
+		return err
+	}
+	misrouted := testutil.ToFloat64(metrics.MisroutedPodRequests)
+
+	err = create("node-2")
+	require.NotNil(t, err)
+	assert.Equal(t, NodeMismatch, err.(DaemonError).ErrorType) //nolint: errorlint
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.NotContains(t, d.state.Pods, p.pid)
+
+	require.Nil(t, create("node-1"))
+	_, err = d.UpdatePod(context.Background(), &ctlplaneapi.UpdatePodRequest{PodId: p.pid, NodeName: "node-2"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: p.pid, NodeName: "node-2"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Contains(t, d.state.Pods, p.pid)
+	assert.Equal(t, misrouted+3, testutil.ToFloat64(metrics.MisroutedPodRequests))
+
+	m.On("DeleteContainer", p.containers[0], &d.state).Return(nil).Once()
+	_, err = d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: p.pid})
+	require.Nil(t, err, "requests without node name shall be accepted")
+}
+
+func TestGetDaemonInfo(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	d, err := New(
+		"testdata/no_state", "testdata/node_info", daemonStateFile, &MockedPolicy{}, logr.Discard(),
+		WithCgroupVersion(CgroupV2),
+	)
+	require.Nil(t, err)
+
+	info, err := d.GetDaemonInfo(context.Background(), &ctlplaneapi.GetDaemonInfoRequest{})
+
+	require.Nil(t, err)
+	assert.Equal(t, ctlplaneapi.CgroupVersion_CGROUP_V2, info.CgroupVersion)
+	assert.Equal(t, version.Get(), info.Build)
+}
+
+func TestCanceledCreatePodRollsBack(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+	require.Nil(t, err)
+	p := createTestPod(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// request is canceled after the first (biggest) container is assigned
+	m.On("AssignContainer", p.containers[1], &d.state).Return(nil).Run(func(mock.Arguments) { cancel() }).Once()
+	m.On("DeleteContainer", p.containers[1], &d.state).Return(nil).Once()
+	m.On("ClearContainer", p.containers[1], &d.state).Return(nil).Once()
+
+	_, err = d.CreatePod(ctx, &ctlplaneapi.CreatePodRequest{
+		PodId:        p.pid,
+		PodName:      p.name,
+		PodNamespace: p.namespace,
+		Resources:    p.resources,
+		Containers:   p.containersResources,
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	m.AssertExpectations(t)
+	m.AssertNotCalled(t, "AssignContainer", p.containers[0], &d.state)
+	assert.NotContains(t, d.state.Pods, p.pid)
+}
+
+func TestCanceledRequestsDoNotChangeState(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	m := MockedPolicy{}
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &m, logr.Discard())
+	require.Nil(t, err)
+	p := createTestPod(1)
+	m.On("AssignContainer", p.containers[0], &d.state).Return(nil).Once()
+	_, err = d.CreatePod(context.Background(), &ctlplaneapi.CreatePodRequest{
+		PodId:        p.pid,
+		PodName:      p.name,
+		PodNamespace: p.namespace,
+		Resources:    p.resources,
+		Containers:   p.containersResources,
+	})
+	require.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	_, err = d.UpdatePod(ctx, &ctlplaneapi.UpdatePodRequest{
+		PodId:      p.pid,
+		Resources:  p.resources,
+		Containers: p.containersResources,
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = d.DeletePod(ctx, &ctlplaneapi.DeletePodRequest{PodId: p.pid})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	m.AssertExpectations(t)
+	m.AssertNotCalled(t, "DeleteContainer", mock.Anything, mock.Anything)
+	assert.Equal(t, []Container{p.containers[0]}, d.state.Pods[p.pid].Containers)
+}
+
+func TestPodQoS(t *testing.T) {
+	assert.Equal(t, BestEffort, podQoS(nil))
+	assert.Equal(t, Guaranteed, podQoS([]Container{{QS: Guaranteed}, {QS: Guaranteed}}))
+	assert.Equal(t, BestEffort, podQoS([]Container{{QS: BestEffort}, {QS: BestEffort}}))
+	assert.Equal(t, Burstable, podQoS([]Container{{QS: Burstable}}))
+	assert.Equal(t, Burstable, podQoS([]Container{{QS: Guaranteed}, {QS: BestEffort}}))
+}
+
+func TestContainersFromRequestSetPodFields(t *testing.T) {
+	p := createTestPod(2)
+	p.containersResources[1].Resources.LimitCpus = 4
+
+	containers := containersFromRequest(logr.Discard(), p.containersResources, p.pid, "ns")
+
+	require.Len(t, containers, 2)
+	for i, c := range containers {
+		assert.Equal(t, p.containers[i].CID, c.CID)
+		assert.Equal(t, "ns", c.Namespace)
+		assert.Equal(t, Burstable, c.PodQS)
+	}
+	assert.Equal(t, Guaranteed, containers[0].QS)
+	assert.Equal(t, Burstable, containers[1].QS)
+}
+
+func TestPartialUpdatePodKeepsUnlistedContainers(t *testing.T) {
+	d := newDaemonForCriticalContainersTest(t)
+	p, req := createCriticalPodRequest("")
+	req.Containers = req.Containers[:2]
+	_, err := d.CreatePod(context.Background(), req)
+	require.Nil(t, err)
+	retried := &ctlplaneapi.ContainerInfo{
+		ContainerId:   p.containers[1].CID,
+		ContainerName: p.containers[1].Name,
+		Resources: &ctlplaneapi.ResourceInfo{
+			RequestedCpus:   3,
+			LimitCpus:       3,
+			RequestedMemory: newQuantityAsBytes(8),
+			LimitMemory:     newQuantityAsBytes(8),
+		},
+	}
+
+	reply, err := d.UpdatePod(context.Background(), &ctlplaneapi.UpdatePodRequest{
+		PodId:      req.PodId,
+		Resources:  req.Resources,
+		Containers: []*ctlplaneapi.ContainerInfo{retried},
+		Partial:    true,
+	})
+
+	require.Nil(t, err)
+	assert.Equal(t, []ctlplaneapi.ContainerResult{
+		{ContainerID: p.containers[1].CID, Status: ctlplaneapi.ContainerStatus_SUCCESS, Reason: resultUpdated},
+	}, reply.ContainerResults)
+	require.Len(t, d.state.Pods[req.PodId].Containers, 2)
+	assert.Len(t, d.state.Allocated[p.containers[0].CID], 1)
+	assert.Equal(t, 3, CPUSetFromBucketList(d.state.Allocated[p.containers[1].CID]).Count())
+}
@@ -0,0 +1,143 @@
+package cpudaemon
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"resourcemanagement.controlplane/pkg/metrics"
+	"resourcemanagement.controlplane/pkg/numautils"
+)
+
+// WatchTopology reloads the topology whenever cpus found in the node info path differ from the topology
+// of the daemon, eg. in test environments whose fixtures change, or after numa configuration of the node
+// changed. The path is checked every interval, if positive, and whenever reload receives, until stop is
+// closed.
+func (d *Daemon) WatchTopology(interval time.Duration, reload <-chan struct{}, stop <-chan struct{}) {
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-stop:
+			return
+		case <-tick:
+		case <-reload:
+		}
+		if _, err := d.ReloadTopology(); err != nil {
+			d.logger.Error(err, "cannot reload topology")
+		}
+	}
+}
+
+// ReloadTopology loads the topology from the node info path again and returns true if it changed. Cpus
+// excluded, reserved or not managed by the daemon are left out as at startup, and cpus taken in the
+// current topology are taken in the new one. The reload is refused if cpus in use (allocated to containers,
+// assigned by kubelet or reserved in cpu pools) are missing from the new topology, as their allocations
+// cannot be kept.
+func (d *Daemon) ReloadTopology() (bool, error) {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+
+	t, excluded, err := d.loadTopology()
+	if err != nil {
+		return false, err
+	}
+	if reflect.DeepEqual(t.CpuInformation, d.state.Topology.CpuInformation) {
+		return false, nil
+	}
+	added, removed, missing := d.replaceTopology(t, excluded)
+	if missing.Count() > 0 {
+		return false, DaemonError{
+			ErrorType: ConfigurationError,
+			ErrorMessage: fmt.Sprintf(
+				"cannot reload topology from %s, cpus %s in use are missing from it",
+				d.numaPath,
+				missing,
+			),
+		}
+	}
+	metrics.TopologyReloads.Inc()
+	d.logger.Info("reloaded topology", "path", d.numaPath, "addedCpus", added, "removedCpus", removed)
+
+	if err := d.saveState(); err != nil {
+		return true, *err
+	}
+	return true, nil
+}
+
+// replaceTopology makes t the topology of the daemon and returns cpus added to and removed from it. Cpus
+// taken in the current topology are taken in t. If cpus in use are missing from t, they are returned as
+// missing and the topology is not replaced.
+func (d *Daemon) replaceTopology(t numautils.NumaTopology, excluded CPUSet) (added, removed, missing CPUSet) {
+	current := topologyCpus(&d.state.Topology)
+	inUse := CPUSet{}
+	for _, buckets := range d.state.Allocated {
+		inUse.Merge(CPUSetFromBucketList(buckets))
+	}
+	available := topologyAvailableCpus(&d.state.Topology)
+	missing = CPUSet{}
+	for cpu := range current {
+		taken := !available.Contains(cpu)
+		if !taken && !inUse.Contains(cpu) {
+			continue
+		}
+		if _, err := t.FindCpu(cpu); err != nil {
+			missing.Add(cpu)
+		} else if taken {
+			_ = t.TakeCpu(cpu)
+		}
+	}
+	if missing.Count() > 0 {
+		return nil, nil, missing
+	}
+
+	added, removed = topologyCpus(&t).RemoveAll(current), current.Clone().RemoveAll(topologyCpus(&t))
+	d.state.Topology = t
+	d.state.ExcludedCPUs = nil
+	if excluded.Count() > 0 {
+		d.state.ExcludedCPUs = excluded.ToCompactBucketList()
+	}
+	d.state.AvailableCPUs = CPUSetFromBucketList(d.state.AvailableCPUs).
+		Merge(added).
+		RemoveAll(removed).
+		RemoveAll(excluded).
+		ToCompactBucketList()
+	return added, removed, missing
+}
+
+// loadTopology loads the topology from the node info path without cpus excluded, reserved or not managed
+// by the daemon, and returns it together with the excluded cpus.
+func (d *Daemon) loadTopology() (numautils.NumaTopology, CPUSet, error) {
+	t := numautils.NumaTopology{}
+	if err := t.Load(d.numaPath); err != nil {
+		return t, nil, DaemonError{ErrorType: UnknownTopology, ErrorMessage: err.Error()}
+	}
+	excluded := d.options.excludedCPUs.Clone()
+	for _, cpu := range t.CpusOnNodes(d.options.excludedNodes) {
+		excluded.Add(cpu)
+	}
+	removed := excluded.Clone().Merge(CPUSetFromBucketList(d.state.ReservedCPUs))
+	if d.options.managedCPUs != nil {
+		removed.Merge(topologyCpus(&t).RemoveAll(d.options.managedCPUs))
+	}
+	if err := t.RemoveCpus(removed.Sorted()); err != nil {
+		return t, nil, DaemonError{ErrorType: ConfigurationError, ErrorMessage: err.Error()}
+	}
+	return t, excluded, nil
+}
+
+// topologyCpus returns all cpus of the topology tree.
+func topologyCpus(t *numautils.NumaTopology) CPUSet {
+	cpus := CPUSet{}
+	if t.Topology == nil {
+		return cpus
+	}
+	for _, leaf := range t.Topology.GetLeafs() {
+		cpus.Add(leaf.Value)
+	}
+	return cpus
+}
@@ -0,0 +1,84 @@
+package cpudaemon
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/numautils/testtopo"
+)
+
+// twoNodes has cpus 0-1 on node 0 and cpus 2-3 on node 1.
+var twoNodes = testtopo.Spec{Sockets: 2, DiesPerSocket: 1, CoresPerDie: 2, ThreadsPerCore: 1}
+
+func writeTopologyForTest(t *testing.T, dir string, spec testtopo.Spec) {
+	require.Nil(t, os.RemoveAll(dir))
+	require.Nil(t, spec.WriteSysfs(dir))
+}
+
+func newDaemonForTopologyReloadTest(t *testing.T) (*Daemon, string) {
+	numaPath := t.TempDir()
+	writeTopologyForTest(t, numaPath, twoNodes)
+	return newTestDaemonOnTopology(t, numaPath, &MockedPolicy{}), numaPath
+}
+
+func TestReloadTopology(t *testing.T) {
+	d, numaPath := newDaemonForTopologyReloadTest(t)
+	pool, err := d.ReserveCPUPool(
+		context.Background(),
+		&ctlplaneapi.ReserveCPUPoolRequest{Name: "dpdk", Cpus: 1, NumaNodes: []uint32{1}},
+	)
+	require.Nil(t, err)
+
+	changed, err := d.ReloadTopology()
+	require.Nil(t, err)
+	assert.False(t, changed)
+
+	writeTopologyForTest(t, numaPath, testtopo.Spec{Sockets: 2, DiesPerSocket: 1, CoresPerDie: 3, ThreadsPerCore: 1})
+	changed, err = d.ReloadTopology()
+
+	require.Nil(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "0,1,2,3,4,5", topologyCpus(&d.state.Topology).ToCpuString())
+	reserved := CPUSetFromBucketList(pool.CPUSet)
+	assert.False(t, topologyAvailable(d, reserved), "cpus taken before the reload stay taken")
+	assert.Equal(t, 5, topologyAvailableCpus(&d.state.Topology).Count())
+	assert.Zero(t, overlapping(d.state.AvailableCPUs, reserved))
+	assert.Len(t, d.readableState().Topology.Topology.GetLeafs(), 6)
+}
+
+func TestReloadTopologyRefusesMissingCpusInUse(t *testing.T) {
+	d, numaPath := newDaemonForTopologyReloadTest(t)
+	_, err := d.ReserveCPUPool(
+		context.Background(),
+		&ctlplaneapi.ReserveCPUPoolRequest{Name: "dpdk", Cpus: 1, NumaNodes: []uint32{1}},
+	)
+	require.Nil(t, err)
+
+	writeTopologyForTest(t, numaPath, testtopo.Spec{Sockets: 1, DiesPerSocket: 1, CoresPerDie: 2, ThreadsPerCore: 1})
+	changed, err := d.ReloadTopology()
+
+	assert.False(t, changed)
+	assert.NotNil(t, err)
+	assert.Equal(t, "0,1,2,3", topologyCpus(&d.state.Topology).ToCpuString())
+}
+
+func TestWatchTopologyReloadsOnRequest(t *testing.T) {
+	d, numaPath := newDaemonForTopologyReloadTest(t)
+	reload, stop, done := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	go func() {
+		d.WatchTopology(0, reload, stop)
+		close(done)
+	}()
+
+	writeTopologyForTest(t, numaPath, testtopo.Spec{Sockets: 2, DiesPerSocket: 1, CoresPerDie: 3, ThreadsPerCore: 1})
+	reload <- struct{}{}
+	reload <- struct{}{} // received once the first reload is done
+	close(stop)
+	<-done
+
+	assert.Equal(t, "0,1,2,3,4,5", topologyCpus(&d.readableState().Topology).ToCpuString())
+}
@@ -0,0 +1,75 @@
+package cpudaemon
+
+import (
+	"context"
+	"time"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+// bestEffortValidation checks if the pod failing validation shall be recorded as unmanaged instead of
+// being rejected. Requests without pod id are always rejected.
+func (d *Daemon) bestEffortValidation(pid string) bool {
+	return d.options.validationFailureAction == ValidationFailureBestEffort && pid != ""
+}
+
+// createUnmanagedPod records the pod whose creation request failed validation as unmanaged.
+func (d *Daemon) createUnmanagedPod(
+	req *ctlplaneapi.CreatePodRequest,
+	validationErr error,
+) (*ctlplaneapi.AllocatedPodResources, error) {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	defer d.traceRequest(traceCreatePod, req, d.traceHash())
+
+	if _, ok := d.state.Pods[req.PodId]; !ok {
+		d.state.Pods[req.PodId] = PodMetadata{
+			PID:         req.PodId,
+			Name:        req.PodName,
+			Namespace:   req.PodNamespace,
+			Labels:      req.Labels,
+			Annotations: req.Annotations,
+			Tier:        d.options.namespaceTier(req.PodNamespace),
+		}
+	}
+	return d.makePodUnmanaged(req.PodId, validationErr)
+}
+
+// makePodUnmanaged releases all cpus allocated to the pod, moves its containers to the shared pool
+// and marks the pod as unmanaged, so that no further cgroup changes are done until a valid update
+// arrives. Shall be called with the state lock held.
+func (d *Daemon) makePodUnmanaged(pid string, validationErr error) (*ctlplaneapi.AllocatedPodResources, error) {
+	pod := d.state.Pods[pid]
+	d.logger.Info("pod recorded as unmanaged", "pid", pid, "reason", validationErr.Error())
+
+	released := allocatedContainers(pod)
+	if err := d.deleteContainers(released); err != nil {
+		d.logger.Error(err, "cannot delete containers") // ignore deletion errors
+	}
+	ctx := context.Background() // released containers are moved to the shared pool also on canceled requests
+	for _, c := range released {
+		if c.QS != Guaranteed {
+			continue
+		}
+		if err := d.policyFor(c).ClearContainer(ctx, c, &d.state); err != nil {
+			d.logger.Error(err, "failed to move container to shared pool", "cid", c.CID)
+		}
+	}
+
+	for _, c := range pod.Containers {
+		d.state.clearCgroupPath(c.CID)
+		d.state.clearAllocationExplanation(c.CID)
+	}
+	pod.Containers = nil
+	pod.LeaseExpiry, pod.LeaseExpired = time.Time{}, false
+	pod.Unmanaged, pod.UnmanagedReason = true, validationErr.Error()
+	d.state.Pods[pid] = pod
+	metrics.UnmanagedPods.Inc()
+
+	if err := d.saveState(); err != nil {
+		return nil, *err
+	}
+	podResources := d.state.allocatedPodResources(pod)
+	return &podResources, nil
+}
@@ -0,0 +1,144 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+func TestCreateInvalidPodIsRecordedAsUnmanaged(t *testing.T) {
+	m := MockedPolicy{}
+	d := newTestDaemon(t, &m, WithValidationFailureAction(ValidationFailureBestEffort))
+	p := createTestPod(1)
+	unmanaged := testutil.ToFloat64(metrics.UnmanagedPods)
+
+	podResources, err := d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+		},
+	)
+
+	require.Nil(t, err)
+	assert.True(t, podResources.Unmanaged)
+	assert.Contains(t, podResources.UnmanagedReason, ctlplaneapi.ErrNoContainers.Error())
+	assert.Empty(t, podResources.ContainerResources)
+	require.Contains(t, d.state.Pods, p.pid)
+	assert.True(t, d.state.Pods[p.pid].Unmanaged)
+	assert.Equal(t, p.namespace, d.state.Pods[p.pid].Namespace)
+	assert.Equal(t, unmanaged+1, testutil.ToFloat64(metrics.UnmanagedPods))
+	m.AssertNotCalled(t, "AssignContainer", mock.Anything, mock.Anything)
+}
+
+func TestCreateInvalidPodIsRejectedByDefault(t *testing.T) {
+	daemonStateFile, tearDown := setupTest()
+	defer tearDown(t)
+	d, err := New("testdata/no_state", "testdata/node_info", daemonStateFile, &MockedPolicy{}, logr.Discard())
+	require.Nil(t, err)
+	p := createTestPod(1)
+
+	_, err = d.CreatePod(context.Background(), &ctlplaneapi.CreatePodRequest{PodId: p.pid, PodName: p.name, PodNamespace: p.namespace})
+
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, PodSpecError, daemonErr.ErrorType)
+	assert.NotContains(t, d.state.Pods, p.pid)
+}
+
+func TestCreateInvalidPodWithoutIDIsRejected(t *testing.T) {
+	d := newTestDaemon(t, &MockedPolicy{}, WithValidationFailureAction(ValidationFailureBestEffort))
+
+	_, err := d.CreatePod(context.Background(), &ctlplaneapi.CreatePodRequest{PodName: "name", PodNamespace: "namespace"})
+
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, PodSpecError, daemonErr.ErrorType)
+	assert.Empty(t, d.state.Pods)
+}
+
+func TestInvalidUpdateMakesPodUnmanaged(t *testing.T) {
+	m := MockedPolicy{}
+	d := newTestDaemon(t, &m, WithValidationFailureAction(ValidationFailureBestEffort))
+	p := createTestPod(2)
+	for _, c := range p.containers {
+		m.On("AssignContainer", c, &d.state).Return(nil).Once()
+		m.On("DeleteContainer", c, &d.state).Return(nil).Once()
+		m.On("ClearContainer", c, &d.state).Return(nil).Once()
+	}
+	_, err := d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+			Containers:   p.containersResources,
+		},
+	)
+	require.Nil(t, err)
+
+	podResources, err := d.UpdatePod(context.Background(), &ctlplaneapi.UpdatePodRequest{PodId: p.pid, Resources: p.resources})
+
+	require.Nil(t, err)
+	assert.True(t, podResources.Unmanaged)
+	m.AssertExpectations(t)
+	assert.Empty(t, d.state.Pods[p.pid].Containers)
+	assert.Empty(t, d.state.Allocated)
+}
+
+func TestValidUpdateMakesPodManaged(t *testing.T) {
+	m := MockedPolicy{}
+	d := newTestDaemon(t, &m, WithValidationFailureAction(ValidationFailureBestEffort))
+	p := createTestPod(2)
+	_, err := d.CreatePod(
+		context.Background(),
+		&ctlplaneapi.CreatePodRequest{
+			PodId:        p.pid,
+			PodName:      p.name,
+			PodNamespace: p.namespace,
+			Resources:    p.resources,
+		},
+	)
+	require.Nil(t, err)
+	for _, c := range p.containers {
+		m.On("AssignContainer", c, &d.state).Return(nil).Once()
+	}
+
+	podResources, err := d.UpdatePod(
+		context.Background(),
+		&ctlplaneapi.UpdatePodRequest{
+			PodId:      p.pid,
+			Resources:  p.resources,
+			Containers: p.containersResources,
+		},
+	)
+
+	require.Nil(t, err)
+	m.AssertExpectations(t)
+	assert.False(t, podResources.Unmanaged)
+	assert.Len(t, podResources.ContainerResources, 2)
+	assert.False(t, d.state.Pods[p.pid].Unmanaged)
+	assert.Empty(t, d.state.Pods[p.pid].UnmanagedReason)
+	assert.Len(t, d.state.Pods[p.pid].Containers, 2)
+}
+
+func TestInvalidUpdateOfUnknownPodIsNotFound(t *testing.T) {
+	d := newTestDaemon(t, &MockedPolicy{}, WithValidationFailureAction(ValidationFailureBestEffort))
+
+	_, err := d.UpdatePod(context.Background(), &ctlplaneapi.UpdatePodRequest{PodId: "unknown"})
+
+	var daemonErr DaemonError
+	require.ErrorAs(t, err, &daemonErr)
+	assert.Equal(t, PodNotFound, daemonErr.ErrorType)
+	assert.Empty(t, d.state.Pods)
+}
@@ -0,0 +1,131 @@
+package cpudaemon
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+// NamespaceUsage accumulates cpu time of exclusive cpus pinned to guaranteed containers of a namespace, eg.
+// for chargeback of exclusive cores.
+type NamespaceUsage struct {
+	PinnedCPUSeconds    float64   // exclusive cpus allocated to the containers times duration of the allocations
+	RequestedCPUSeconds float64   // cpus requested by the containers times duration of the allocations
+	AccountedAt         time.Time // end of the accounted time, usage after it is not yet included
+}
+
+// pinnedCpus holds number of exclusive cpus allocated to and requested by guaranteed containers.
+type pinnedCpus struct {
+	pinned    int
+	requested int
+}
+
+// RunUsageAccounting accounts usage of namespaces every interval, until stop is closed, so that usage
+// metrics grow while allocations do not change. Usage is accounted on every state change regardless of
+// it, non-positive interval disables only the periodic accounting.
+func (d *Daemon) RunUsageAccounting(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			d.stateMu.Lock()
+			d.accountUsage(now)
+			d.stateMu.Unlock()
+		}
+	}
+}
+
+// namespacePinnedCpus returns exclusive cpus allocated to and requested by guaranteed containers of each
+// namespace having any. Containers of pods with expired lease run in the shared pool and are left out.
+func (d *DaemonState) namespacePinnedCpus() map[string]pinnedCpus {
+	res := map[string]pinnedCpus{}
+	for _, pod := range d.Pods {
+		if pod.LeaseExpired {
+			continue
+		}
+		for _, c := range pod.Containers {
+			n := CPUSetFromBucketList(d.Allocated[c.CID]).Count()
+			if c.QS != Guaranteed || n == 0 {
+				continue
+			}
+			p := res[pod.Namespace]
+			p.pinned += n
+			p.requested += c.Cpus
+			res[pod.Namespace] = p
+		}
+	}
+	return res
+}
+
+// accountUsage adds cpu time pinned until now to the usage of namespaces. Allocations of the published
+// state were in effect since the previous accounting, so they are accounted; namespaces getting exclusive
+// cpus with the pending state change start to be accounted now. Shall be called with the state lock held,
+// before the changed state is published.
+func (d *Daemon) accountUsage(now time.Time) {
+	prev := d.readableState().namespacePinnedCpus()
+	namespaces := d.state.namespacePinnedCpus()
+	for namespace, p := range prev {
+		namespaces[namespace] = p
+	}
+	for namespace := range namespaces {
+		u, ok := d.state.Usage[namespace]
+		if ok && !now.After(u.AccountedAt) {
+			continue
+		}
+		if ok {
+			p, seconds := prev[namespace], now.Sub(u.AccountedAt).Seconds()
+			u.PinnedCPUSeconds += float64(p.pinned) * seconds
+			u.RequestedCPUSeconds += float64(p.requested) * seconds
+			metrics.NamespacePinnedCPUSeconds.WithLabelValues(namespace).Add(float64(p.pinned) * seconds)
+			metrics.NamespaceRequestedCPUSeconds.WithLabelValues(namespace).Add(float64(p.requested) * seconds)
+		}
+		u.AccountedAt = now
+		if d.state.Usage == nil {
+			d.state.Usage = make(map[string]NamespaceUsage)
+		}
+		d.state.Usage[namespace] = u
+	}
+}
+
+// ListUsage returns usage of the namespace, or of all namespaces ever having exclusive cpus if no namespace
+// is given, sorted by namespace. Usage is read from the published state and includes cpu time pinned since
+// the last accounting.
+func (d *Daemon) ListUsage(
+	_ context.Context,
+	req *ctlplaneapi.ListUsageRequest,
+) ([]ctlplaneapi.NamespaceUsageInfo, error) {
+	s, now := d.readableState(), time.Now()
+	pinned := s.namespacePinnedCpus()
+	res := []ctlplaneapi.NamespaceUsageInfo{}
+	for namespace, u := range s.Usage {
+		if req.Namespace != "" && req.Namespace != namespace {
+			continue
+		}
+		p := pinned[namespace]
+		info := ctlplaneapi.NamespaceUsageInfo{
+			Namespace:           namespace,
+			PinnedCPUSeconds:    u.PinnedCPUSeconds,
+			RequestedCPUSeconds: u.RequestedCPUSeconds,
+			PinnedCpus:          p.pinned,
+			RequestedCpus:       p.requested,
+		}
+		if now.After(u.AccountedAt) {
+			info.PinnedCPUSeconds += float64(p.pinned) * now.Sub(u.AccountedAt).Seconds()
+			info.RequestedCPUSeconds += float64(p.requested) * now.Sub(u.AccountedAt).Seconds()
+		}
+		res = append(res, info)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Namespace < res[j].Namespace
+	})
+	return res, nil
+}
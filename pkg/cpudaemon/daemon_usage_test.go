@@ -0,0 +1,99 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+func TestAccountUsage(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)))
+	p := podWithPlacement(ctlplaneapi.Placement_DEFAULT)
+	createPodForRepack(t, d, p)
+	start := d.state.Usage[p.namespace].AccountedAt
+	require.False(t, start.IsZero(), "namespace is accounted since its containers got exclusive cpus")
+	pinned := testutil.ToFloat64(metrics.NamespacePinnedCPUSeconds.WithLabelValues(p.namespace))
+
+	d.accountUsage(start.Add(10 * time.Second))
+
+	u := d.state.Usage[p.namespace]
+	assert.Equal(t, 20.0, u.PinnedCPUSeconds)
+	assert.Equal(t, 20.0, u.RequestedCPUSeconds)
+	assert.Equal(t, pinned+20, testutil.ToFloat64(metrics.NamespacePinnedCPUSeconds.WithLabelValues(p.namespace)))
+
+	d.accountUsage(start.Add(5 * time.Second))
+	assert.Equal(t, u, d.state.Usage[p.namespace], "earlier time is not accounted again")
+
+	_, err := d.DeletePod(context.Background(), &ctlplaneapi.DeletePodRequest{PodId: p.pid})
+	require.Nil(t, err)
+	d.accountUsage(start.Add(time.Hour))
+
+	assert.Equal(t, 20.0, d.state.Usage[p.namespace].PinnedCPUSeconds, "deleted pod is no longer accounted")
+}
+
+func TestAccountUsageSkipsSharedPool(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)))
+	p := podWithPlacement(ctlplaneapi.Placement_DEFAULT)
+	createPodForRepack(t, d, p)
+	pod := d.state.Pods[p.pid]
+	pod.LeaseExpired = true
+	d.state.Pods[p.pid] = pod
+	d.publishReadState()
+	start := d.state.Usage[p.namespace].AccountedAt
+
+	d.accountUsage(start.Add(10 * time.Second))
+
+	assert.Zero(t, d.state.Usage[p.namespace].PinnedCPUSeconds)
+}
+
+func TestListUsage(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)))
+	p := podWithPlacement(ctlplaneapi.Placement_DEFAULT)
+	createPodForRepack(t, d, p)
+	other := renamedPod(podWithPlacement(ctlplaneapi.Placement_DEFAULT), "other")
+	other.namespace = "other"
+	createPodForRepack(t, d, other)
+	u := d.state.Usage[p.namespace]
+	u.PinnedCPUSeconds, u.RequestedCPUSeconds = 100, 50
+	u.AccountedAt = u.AccountedAt.Add(-10 * time.Second)
+	d.state.Usage[p.namespace] = u
+	d.publishReadState()
+
+	usage, err := d.ListUsage(context.Background(), &ctlplaneapi.ListUsageRequest{})
+
+	require.Nil(t, err)
+	require.Len(t, usage, 2)
+	assert.Equal(t, "other", usage[0].Namespace)
+	assert.Equal(t, p.namespace, usage[1].Namespace)
+	assert.Equal(t, 2, usage[1].PinnedCpus)
+	assert.Equal(t, 2, usage[1].RequestedCpus)
+	assert.GreaterOrEqual(t, usage[1].PinnedCPUSeconds, 120.0, "cpu time since the last accounting is included")
+	assert.GreaterOrEqual(t, usage[1].RequestedCPUSeconds, 70.0)
+
+	usage, err = d.ListUsage(context.Background(), &ctlplaneapi.ListUsageRequest{Namespace: "other"})
+
+	require.Nil(t, err)
+	require.Len(t, usage, 1)
+	assert.Equal(t, "other", usage[0].Namespace)
+}
+
+func TestUsageSurvivesRestart(t *testing.T) {
+	d := newTestDaemon(t, NewStaticPolocy(NewNumaAwareAllocator(newMockedCgroups(), false)))
+	p := podWithPlacement(ctlplaneapi.Placement_DEFAULT)
+	createPodForRepack(t, d, p)
+	d.accountUsage(d.state.Usage[p.namespace].AccountedAt.Add(10 * time.Second))
+	require.Nil(t, d.saveState())
+	usage := d.state.Usage[p.namespace]
+
+	s, err := newState("testdata/no_state", "testdata/node_info", d.state.StatePath)
+
+	require.Nil(t, err)
+	assert.Equal(t, usage.PinnedCPUSeconds, s.Usage[p.namespace].PinnedCPUSeconds)
+	assert.True(t, usage.AccountedAt.Equal(s.Usage[p.namespace].AccountedAt))
+}
@@ -0,0 +1,37 @@
+package cpudaemon
+
+import "context"
+
+// ObservePolicy records containers in the state without allocating cpus nor changing their cgroups, eg.
+// for development namespaces whose pods shall only be observed.
+type ObservePolicy struct{}
+
+var (
+	_ Policy       = ObservePolicy{}
+	_ DryRunPolicy = ObservePolicy{}
+)
+
+// NewObservePolicy constructs a new observe-only policy.
+func NewObservePolicy() ObservePolicy {
+	return ObservePolicy{}
+}
+
+// AssignContainer does nothing, the container keeps the cpuset set by the container runtime.
+func (ObservePolicy) AssignContainer(ctx context.Context, _ Container, _ *DaemonState) error {
+	return ctx.Err()
+}
+
+// DeleteContainer does nothing, as no cpus were allocated to the container.
+func (ObservePolicy) DeleteContainer(context.Context, Container, *DaemonState) error {
+	return nil
+}
+
+// ClearContainer does nothing, as the cpuset of the container was never changed.
+func (ObservePolicy) ClearContainer(context.Context, Container, *DaemonState) error {
+	return nil
+}
+
+// DryRun returns the policy itself, as it changes nothing.
+func (p ObservePolicy) DryRun() (Policy, bool) {
+	return p, true
+}
@@ -0,0 +1,32 @@
+package cpudaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"resourcemanagement.controlplane/pkg/ctlplaneapi"
+)
+
+func TestObservePolicyChangesNothing(t *testing.T) {
+	s := getPlacementTestState(t)
+	c := baseContainer(1)
+	p := NewObservePolicy()
+
+	assert.Nil(t, p.AssignContainer(context.Background(), c, s))
+	assert.Nil(t, p.DeleteContainer(context.Background(), c, s))
+	assert.Nil(t, p.ClearContainer(context.Background(), c, s))
+	assert.Empty(t, s.Allocated)
+	assert.Equal(t, 8, s.Topology.Topology.NumAvailable)
+}
+
+func TestObservePolicyAbortsCanceledAssignment(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := NewObservePolicy().AssignContainer(ctx, baseContainer(1), &DaemonState{
+		Allocated: map[string][]ctlplaneapi.CPUBucket{},
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -1,10 +1,12 @@
 package cpudaemon
 
+import "context"
+
 // Policy interface of cpu management policies.
 type Policy interface {
-	AssignContainer(c Container, s *DaemonState) error
-	DeleteContainer(c Container, s *DaemonState) error
-	ClearContainer(c Container, s *DaemonState) error
+	AssignContainer(ctx context.Context, c Container, s *DaemonState) error
+	DeleteContainer(ctx context.Context, c Container, s *DaemonState) error
+	ClearContainer(ctx context.Context, c Container, s *DaemonState) error
 }
 
 // StaticPolicy Static Policy type holding assigned containers.
@@ -23,17 +25,17 @@ func NewStaticPolocy(a Allocator) *StaticPolicy {
 }
 
 // AssignContainer tries to allocate a container.
-func (p *StaticPolicy) AssignContainer(c Container, s *DaemonState) error {
-	return p.allocator.takeCpus(c, s)
+func (p *StaticPolicy) AssignContainer(ctx context.Context, c Container, s *DaemonState) error {
+	return p.allocator.takeCpus(ctx, c, s)
 }
 
 // DeleteContainer delete allocated containers (without deleting cgroup config - it will be clered by k8s GC).
-func (p *StaticPolicy) DeleteContainer(c Container, s *DaemonState) error {
-	return p.allocator.freeCpus(c, s)
+func (p *StaticPolicy) DeleteContainer(ctx context.Context, c Container, s *DaemonState) error {
+	return p.allocator.freeCpus(ctx, c, s)
 }
 
 // ClearContainer reverts cpuset configuration to default one (use all available cpus). It does not
 // remove container from the state - this should be done with DeleteContainer.
-func (p *StaticPolicy) ClearContainer(c Container, s *DaemonState) error {
-	return p.allocator.clearCpus(c, s)
+func (p *StaticPolicy) ClearContainer(ctx context.Context, c Container, s *DaemonState) error {
+	return p.allocator.clearCpus(ctx, c, s)
 }
@@ -1,6 +1,7 @@
 package cpudaemon
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,17 +14,17 @@ type AllocatorMock struct {
 
 var _ Allocator = &AllocatorMock{}
 
-func (m *AllocatorMock) takeCpus(c Container, s *DaemonState) error {
+func (m *AllocatorMock) takeCpus(_ context.Context, c Container, s *DaemonState) error {
 	args := m.Called(c, s)
 	return args.Error(0)
 }
 
-func (m *AllocatorMock) freeCpus(c Container, s *DaemonState) error {
+func (m *AllocatorMock) freeCpus(_ context.Context, c Container, s *DaemonState) error {
 	args := m.Called(c, s)
 	return args.Error(0)
 }
 
-func (m *AllocatorMock) clearCpus(c Container, s *DaemonState) error {
+func (m *AllocatorMock) clearCpus(_ context.Context, c Container, s *DaemonState) error {
 	args := m.Called(c, s)
 	return args.Error(0)
 }
@@ -46,11 +47,11 @@ func TestAssignContainerMocked(t *testing.T) {
 	}
 	st := DaemonState{}
 	a.On("takeCpus", c, &st).Return(nil)
-	err := s.AssignContainer(c, &st)
+	err := s.AssignContainer(context.Background(), c, &st)
 	assert.Nil(t, err)
 	c.QS = BestEffort
 	a.On("takeCpus", c, &st).Return(nil)
-	err = s.AssignContainer(c, &st)
+	err = s.AssignContainer(context.Background(), c, &st)
 	assert.Nil(t, err)
 	a.AssertNumberOfCalls(t, "takeCpus", 2)
 }
@@ -68,9 +69,9 @@ func TestDeleteContainerMocked(t *testing.T) {
 	}
 	st := DaemonState{}
 	a.On("freeCpus", c, &st).Return(nil)
-	assert.Nil(t, s.DeleteContainer(c, &st))
+	assert.Nil(t, s.DeleteContainer(context.Background(), c, &st))
 	c.QS = BestEffort
 	a.On("freeCpus", c, &st).Return(nil)
-	assert.Nil(t, s.DeleteContainer(c, &st))
+	assert.Nil(t, s.DeleteContainer(context.Background(), c, &st))
 	a.AssertNumberOfCalls(t, "freeCpus", 2)
 }
@@ -0,0 +1,70 @@
+// Package crd defines ControlPlaneConfig custom resource which configures allocations of a namespace
+package crd
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	Group    = "ctlplane.intel.com"
+	Version  = "v1alpha1"
+	Resource = "controlplaneconfigs"
+	Kind     = "ControlPlaneConfig"
+
+	// ConfigName is the name of ControlPlaneConfig applied to its namespace, resources with other names
+	// are ignored so that a namespace has at most one configuration.
+	ConfigName = "default"
+)
+
+// GroupVersionResource identifies ControlPlaneConfig resources for dynamic clients.
+var GroupVersionResource = schema.GroupVersionResource{Group: Group, Version: Version, Resource: Resource}
+
+// ControlPlaneConfig configures allocations of new pods of its namespace.
+type ControlPlaneConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ControlPlaneConfigSpec `json:"spec,omitempty"`
+}
+
+// ControlPlaneConfigSpec holds the namespace configuration.
+type ControlPlaneConfigSpec struct {
+	// Allocator of new pods of the namespace, one of allocators enabled by -namespace-allocators of the
+	// daemon. Empty selects the allocator of the daemon.
+	Allocator string `json:"allocator,omitempty"`
+	// Exclusive is false if guaranteed containers of the namespace shall run in the shared pool, true if
+	// not set.
+	Exclusive *bool `json:"exclusive,omitempty"`
+	// BucketCpus reserves the namespace bucket of numa-namespace allocators with at least so many cpus.
+	BucketCpus int `json:"bucketCpus,omitempty"`
+}
+
+// IsExclusive checks if guaranteed containers of the namespace get exclusive cpus.
+func (s ControlPlaneConfigSpec) IsExclusive() bool {
+	return s.Exclusive == nil || *s.Exclusive
+}
+
+// Validate checks values of the spec.
+func (s ControlPlaneConfigSpec) Validate() error {
+	if s.BucketCpus < 0 {
+		return fmt.Errorf("bucketCpus shall not be negative, got %d", s.BucketCpus)
+	}
+	return nil
+}
+
+// FromUnstructured converts ControlPlaneConfig read by a dynamic client and validates its spec.
+func FromUnstructured(u *unstructured.Unstructured) (*ControlPlaneConfig, error) {
+	config := &ControlPlaneConfig{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), config); err != nil {
+		return nil, fmt.Errorf("cannot convert %s %s/%s: %w", Kind, u.GetNamespace(), u.GetName(), err)
+	}
+	if err := config.Spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s %s/%s: %w", Kind, u.GetNamespace(), u.GetName(), err)
+	}
+	return config, nil
+}
@@ -0,0 +1,53 @@
+package crd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func testConfig(spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": Group + "/" + Version,
+		"kind":       Kind,
+		"metadata":   map[string]interface{}{"name": ConfigName, "namespace": "shop"},
+		"spec":       spec,
+	}}
+}
+
+func TestFromUnstructured(t *testing.T) {
+	config, err := FromUnstructured(testConfig(map[string]interface{}{
+		"allocator":  "numa-namespace",
+		"exclusive":  false,
+		"bucketCpus": int64(2),
+	}))
+
+	require.Nil(t, err)
+	assert.Equal(t, "shop", config.Namespace)
+	assert.Equal(t, ConfigName, config.Name)
+	assert.Equal(t, "numa-namespace", config.Spec.Allocator)
+	assert.False(t, config.Spec.IsExclusive())
+	assert.Equal(t, 2, config.Spec.BucketCpus)
+}
+
+func TestFromUnstructuredDefaults(t *testing.T) {
+	config, err := FromUnstructured(testConfig(map[string]interface{}{}))
+
+	require.Nil(t, err)
+	assert.Empty(t, config.Spec.Allocator)
+	assert.True(t, config.Spec.IsExclusive())
+	assert.Zero(t, config.Spec.BucketCpus)
+}
+
+func TestFromUnstructuredFails(t *testing.T) {
+	for name, spec := range map[string]map[string]interface{}{
+		"negative bucket cpus": {"bucketCpus": int64(-1)},
+		"invalid type":         {"exclusive": "no"},
+	} {
+		_, err := FromUnstructured(testConfig(spec))
+
+		assert.NotNil(t, err, name)
+	}
+}
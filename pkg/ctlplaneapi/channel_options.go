@@ -0,0 +1,74 @@
+package ctlplaneapi
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
+)
+
+// ChannelOptions configures keepalive and compression of the gRPC channel between the agent and the
+// daemon. Both sides shall use the same options.
+type ChannelOptions struct {
+	KeepaliveTime    time.Duration // interval of keepalive pings, 0 disables keepalive
+	KeepaliveTimeout time.Duration // time to wait for ping acknowledgement before closing the connection
+	Compression      bool          // enables gzip compression of requests and replies
+}
+
+// ServerOptions returns gRPC server options of the daemon.
+func (o ChannelOptions) ServerOptions() []grpc.ServerOption {
+	opts := []grpc.ServerOption{}
+	if o.KeepaliveTime > 0 {
+		opts = append(opts,
+			grpc.KeepaliveParams(keepalive.ServerParameters{
+				Time:    o.KeepaliveTime,
+				Timeout: o.KeepaliveTimeout,
+			}),
+			grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+				MinTime:             o.KeepaliveTime,
+				PermitWithoutStream: true,
+			}),
+		)
+	}
+	if o.Compression {
+		opts = append(opts, grpc.ChainUnaryInterceptor(compressReplies))
+	}
+	return opts
+}
+
+// compressReplies makes the server compress replies with gzip if the client supports it, regardless
+// of the compression of the request.
+func compressReplies(
+	ctx context.Context,
+	req interface{},
+	_ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if compressors, err := grpc.ClientSupportedCompressors(ctx); err == nil {
+		for _, name := range compressors {
+			if name == gzip.Name {
+				_ = grpc.SetSendCompressor(ctx, gzip.Name)
+				break
+			}
+		}
+	}
+	return handler(ctx, req)
+}
+
+// DialOptions returns gRPC dial options of the agent.
+func (o ChannelOptions) DialOptions() []grpc.DialOption {
+	opts := []grpc.DialOption{}
+	if o.KeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                o.KeepaliveTime,
+			Timeout:             o.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}))
+	}
+	if o.Compression {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
+	}
+	return opts
+}
@@ -0,0 +1,127 @@
+package ctlplaneapi
+
+import (
+	context "context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// compressionRecorder records compression of received headers.
+type compressionRecorder struct {
+	mu          sync.Mutex
+	compression []string
+}
+
+func (r *compressionRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if h, ok := s.(*stats.InHeader); ok {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.compression = append(r.compression, h.Compression)
+	}
+}
+
+func (r *compressionRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleConn(context.Context, stats.ConnStats) {}
+
+func (r *compressionRecorder) recorded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.compression...)
+}
+
+func newServerWithChannelOptions(
+	ctx context.Context,
+	o ChannelOptions,
+) (ControlPlaneClient, func(), *DaemonMock, *compressionRecorder, *compressionRecorder) {
+	listener := bufconn.Listen(1024 * 1024)
+	serverRecorder, clientRecorder := &compressionRecorder{}, &compressionRecorder{}
+	s := grpc.NewServer(append(o.ServerOptions(), grpc.StatsHandler(serverRecorder))...)
+	m := DaemonMock{}
+	RegisterControlPlaneServer(s, NewServer(&m))
+	go func() {
+		_ = s.Serve(listener)
+	}()
+
+	dialOpts := append(
+		o.DialOptions(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(clientRecorder),
+	)
+	conn, _ := grpc.DialContext(ctx, "", dialOpts...)
+
+	closer := func() {
+		conn.Close()
+		listener.Close()
+		s.Stop()
+	}
+	return NewControlPlaneClient(conn), closer, &m, serverRecorder, clientRecorder
+}
+
+func TestChannelOptionsDisabledByDefault(t *testing.T) {
+	o := ChannelOptions{}
+	assert.Empty(t, o.ServerOptions())
+	assert.Empty(t, o.DialOptions())
+}
+
+func TestChannelOptionsKeepalive(t *testing.T) {
+	o := ChannelOptions{KeepaliveTime: time.Minute, KeepaliveTimeout: 10 * time.Second}
+	assert.Len(t, o.ServerOptions(), 2)
+	assert.Len(t, o.DialOptions(), 1)
+}
+
+func TestChannelOptionsCompression(t *testing.T) {
+	o := ChannelOptions{Compression: true}
+	assert.Len(t, o.ServerOptions(), 1)
+	assert.Len(t, o.DialOptions(), 1)
+}
+
+func TestChannelCompressesRequestsAndReplies(t *testing.T) {
+	ctx := context.Background()
+	client, closer, m, serverRecorder, clientRecorder := newServerWithChannelOptions(ctx, ChannelOptions{
+		KeepaliveTime:    time.Minute,
+		KeepaliveTimeout: 10 * time.Second,
+		Compression:      true,
+	})
+	defer closer()
+
+	req, expected := createTestDeletion(m, "pod", nil)
+	reply, err := client.DeletePod(ctx, req)
+	require.Nil(t, err)
+	assert.Equal(t, expected.AllocState, reply.AllocState)
+
+	assert.Equal(t, []string{gzip.Name}, serverRecorder.recorded())
+	assert.Equal(t, []string{gzip.Name}, clientRecorder.recorded())
+}
+
+func TestChannelWithoutCompression(t *testing.T) {
+	ctx := context.Background()
+	client, closer, m, serverRecorder, clientRecorder := newServerWithChannelOptions(ctx, ChannelOptions{})
+	defer closer()
+
+	req, _ := createTestDeletion(m, "pod", nil)
+	_, err := client.DeletePod(ctx, req)
+	require.Nil(t, err)
+
+	assert.Equal(t, []string{""}, serverRecorder.recorded())
+	assert.Equal(t, []string{""}, clientRecorder.recorded())
+}
@@ -121,16 +121,223 @@ func (Placement) EnumDescriptor() ([]byte, []int) {
 	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{1}
 }
 
+// Overrides daemon memory pinning setting for a pod
+type MemoryPinning int32
+
+const (
+	MemoryPinning_MEMORY_PINNING_DEFAULT  MemoryPinning = 0
+	MemoryPinning_MEMORY_PINNING_ENABLED  MemoryPinning = 1
+	MemoryPinning_MEMORY_PINNING_DISABLED MemoryPinning = 2
+)
+
+// Enum value maps for MemoryPinning.
+var (
+	MemoryPinning_name = map[int32]string{
+		0: "MEMORY_PINNING_DEFAULT",
+		1: "MEMORY_PINNING_ENABLED",
+		2: "MEMORY_PINNING_DISABLED",
+	}
+	MemoryPinning_value = map[string]int32{
+		"MEMORY_PINNING_DEFAULT":  0,
+		"MEMORY_PINNING_ENABLED":  1,
+		"MEMORY_PINNING_DISABLED": 2,
+	}
+)
+
+func (x MemoryPinning) Enum() *MemoryPinning {
+	p := new(MemoryPinning)
+	*p = x
+	return p
+}
+
+func (x MemoryPinning) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MemoryPinning) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_ctlplaneapi_controlplane_proto_enumTypes[2].Descriptor()
+}
+
+func (MemoryPinning) Type() protoreflect.EnumType {
+	return &file_pkg_ctlplaneapi_controlplane_proto_enumTypes[2]
+}
+
+func (x MemoryPinning) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MemoryPinning.Descriptor instead.
+func (MemoryPinning) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{2}
+}
+
+// Version of cgroups detected by the daemon at startup
+type CgroupVersion int32
+
+const (
+	CgroupVersion_CGROUP_VERSION_UNKNOWN CgroupVersion = 0
+	CgroupVersion_CGROUP_V1              CgroupVersion = 1
+	CgroupVersion_CGROUP_V2              CgroupVersion = 2
+)
+
+// Enum value maps for CgroupVersion.
+var (
+	CgroupVersion_name = map[int32]string{
+		0: "CGROUP_VERSION_UNKNOWN",
+		1: "CGROUP_V1",
+		2: "CGROUP_V2",
+	}
+	CgroupVersion_value = map[string]int32{
+		"CGROUP_VERSION_UNKNOWN": 0,
+		"CGROUP_V1":              1,
+		"CGROUP_V2":              2,
+	}
+)
+
+func (x CgroupVersion) Enum() *CgroupVersion {
+	p := new(CgroupVersion)
+	*p = x
+	return p
+}
+
+func (x CgroupVersion) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CgroupVersion) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_ctlplaneapi_controlplane_proto_enumTypes[3].Descriptor()
+}
+
+func (CgroupVersion) Type() protoreflect.EnumType {
+	return &file_pkg_ctlplaneapi_controlplane_proto_enumTypes[3]
+}
+
+func (x CgroupVersion) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CgroupVersion.Descriptor instead.
+func (CgroupVersion) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{3}
+}
+
+// Result of a single container of a pod request
+type ContainerStatus int32
+
+const (
+	ContainerStatus_SUCCESS ContainerStatus = 0 // container was allocated, updated or deleted as requested
+	ContainerStatus_FAILED  ContainerStatus = 1 // container could not be processed, it is processed again by the next request of the pod
+	ContainerStatus_SKIPPED ContainerStatus = 2 // container was not processed, eg. it did not change or the request was aborted
+)
+
+// Enum value maps for ContainerStatus.
+var (
+	ContainerStatus_name = map[int32]string{
+		0: "SUCCESS",
+		1: "FAILED",
+		2: "SKIPPED",
+	}
+	ContainerStatus_value = map[string]int32{
+		"SUCCESS": 0,
+		"FAILED":  1,
+		"SKIPPED": 2,
+	}
+)
+
+func (x ContainerStatus) Enum() *ContainerStatus {
+	p := new(ContainerStatus)
+	*p = x
+	return p
+}
+
+func (x ContainerStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ContainerStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_ctlplaneapi_controlplane_proto_enumTypes[4].Descriptor()
+}
+
+func (ContainerStatus) Type() protoreflect.EnumType {
+	return &file_pkg_ctlplaneapi_controlplane_proto_enumTypes[4]
+}
+
+func (x ContainerStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ContainerStatus.Descriptor instead.
+func (ContainerStatus) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{4}
+}
+
+// QoS class of a container, as derived by the daemon
+type QoSClass int32
+
+const (
+	QoSClass_GUARANTEED  QoSClass = 0
+	QoSClass_BEST_EFFORT QoSClass = 1
+	QoSClass_BURSTABLE   QoSClass = 2
+)
+
+// Enum value maps for QoSClass.
+var (
+	QoSClass_name = map[int32]string{
+		0: "GUARANTEED",
+		1: "BEST_EFFORT",
+		2: "BURSTABLE",
+	}
+	QoSClass_value = map[string]int32{
+		"GUARANTEED":  0,
+		"BEST_EFFORT": 1,
+		"BURSTABLE":   2,
+	}
+)
+
+func (x QoSClass) Enum() *QoSClass {
+	p := new(QoSClass)
+	*p = x
+	return p
+}
+
+func (x QoSClass) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (QoSClass) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_ctlplaneapi_controlplane_proto_enumTypes[5].Descriptor()
+}
+
+func (QoSClass) Type() protoreflect.EnumType {
+	return &file_pkg_ctlplaneapi_controlplane_proto_enumTypes[5]
+}
+
+func (x QoSClass) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use QoSClass.Descriptor instead.
+func (QoSClass) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{5}
+}
+
 type CreatePodRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	PodId        string           `protobuf:"bytes,1,opt,name=podId,proto3" json:"podId,omitempty"`
-	PodName      string           `protobuf:"bytes,2,opt,name=podName,proto3" json:"podName,omitempty"`
-	PodNamespace string           `protobuf:"bytes,3,opt,name=podNamespace,proto3" json:"podNamespace,omitempty"`
-	Resources    *ResourceInfo    `protobuf:"bytes,4,opt,name=resources,proto3" json:"resources,omitempty"`
-	Containers   []*ContainerInfo `protobuf:"bytes,5,rep,name=containers,proto3" json:"containers,omitempty"`
+	PodId                 string            `protobuf:"bytes,1,opt,name=podId,proto3" json:"podId,omitempty"`
+	PodName               string            `protobuf:"bytes,2,opt,name=podName,proto3" json:"podName,omitempty"`
+	PodNamespace          string            `protobuf:"bytes,3,opt,name=podNamespace,proto3" json:"podNamespace,omitempty"`
+	Resources             *ResourceInfo     `protobuf:"bytes,4,opt,name=resources,proto3" json:"resources,omitempty"`
+	Containers            []*ContainerInfo  `protobuf:"bytes,5,rep,name=containers,proto3" json:"containers,omitempty"`
+	MemoryPinning         MemoryPinning     `protobuf:"varint,6,opt,name=memoryPinning,proto3,enum=ctlplaneapi.MemoryPinning" json:"memoryPinning,omitempty"`
+	ExclusiveLeaseSeconds uint32            `protobuf:"varint,7,opt,name=exclusiveLeaseSeconds,proto3" json:"exclusiveLeaseSeconds,omitempty"`                                                                    // if set, exclusive cpus return to shared pool after the lease expires
+	Labels                map[string]string `protobuf:"bytes,8,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`           // pod labels selected by the agent, for daemon-side policies
+	Annotations           map[string]string `protobuf:"bytes,9,rep,name=annotations,proto3" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"` // pod annotations selected by the agent, for daemon-side policies
+	Generation            int64             `protobuf:"varint,10,opt,name=generation,proto3" json:"generation,omitempty"`                                                                                         // version of the pod seen by the agent, 0 if unknown
+	NodeName              string            `protobuf:"bytes,11,opt,name=nodeName,proto3" json:"nodeName,omitempty"`                                                                                              // node the pod is scheduled to, requests for other nodes are rejected; empty if unknown
+	DryRun                bool              `protobuf:"varint,12,opt,name=dryRun,proto3" json:"dryRun,omitempty"`                                                                                                 // allocation is computed and returned, but neither cgroups nor the state are changed
 }
 
 func (x *CreatePodRequest) Reset() {
@@ -200,94 +407,80 @@ func (x *CreatePodRequest) GetContainers() []*ContainerInfo {
 	return nil
 }
 
-type UpdatePodRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	PodId      string           `protobuf:"bytes,1,opt,name=podId,proto3" json:"podId,omitempty"`
-	Resources  *ResourceInfo    `protobuf:"bytes,2,opt,name=resources,proto3" json:"resources,omitempty"`
-	Containers []*ContainerInfo `protobuf:"bytes,3,rep,name=containers,proto3" json:"containers,omitempty"`
-}
-
-func (x *UpdatePodRequest) Reset() {
-	*x = UpdatePodRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[1]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *CreatePodRequest) GetMemoryPinning() MemoryPinning {
+	if x != nil {
+		return x.MemoryPinning
 	}
+	return MemoryPinning_MEMORY_PINNING_DEFAULT
 }
 
-func (x *UpdatePodRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *CreatePodRequest) GetExclusiveLeaseSeconds() uint32 {
+	if x != nil {
+		return x.ExclusiveLeaseSeconds
+	}
+	return 0
 }
 
-func (*UpdatePodRequest) ProtoMessage() {}
-
-func (x *UpdatePodRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[1]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+func (x *CreatePodRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use UpdatePodRequest.ProtoReflect.Descriptor instead.
-func (*UpdatePodRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{1}
+func (x *CreatePodRequest) GetAnnotations() map[string]string {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
 }
 
-func (x *UpdatePodRequest) GetPodId() string {
+func (x *CreatePodRequest) GetGeneration() int64 {
 	if x != nil {
-		return x.PodId
+		return x.Generation
 	}
-	return ""
+	return 0
 }
 
-func (x *UpdatePodRequest) GetResources() *ResourceInfo {
+func (x *CreatePodRequest) GetNodeName() string {
 	if x != nil {
-		return x.Resources
+		return x.NodeName
 	}
-	return nil
+	return ""
 }
 
-func (x *UpdatePodRequest) GetContainers() []*ContainerInfo {
+func (x *CreatePodRequest) GetDryRun() bool {
 	if x != nil {
-		return x.Containers
+		return x.DryRun
 	}
-	return nil
+	return false
 }
 
-type DeletePodRequest struct {
+type CreatePodsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	PodId string `protobuf:"bytes,1,opt,name=podId,proto3" json:"podId,omitempty"`
+	Pods []*CreatePodRequest `protobuf:"bytes,1,rep,name=pods,proto3" json:"pods,omitempty"`
 }
 
-func (x *DeletePodRequest) Reset() {
-	*x = DeletePodRequest{}
+func (x *CreatePodsRequest) Reset() {
+	*x = CreatePodsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[2]
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[1]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *DeletePodRequest) String() string {
+func (x *CreatePodsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeletePodRequest) ProtoMessage() {}
+func (*CreatePodsRequest) ProtoMessage() {}
 
-func (x *DeletePodRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[2]
+func (x *CreatePodsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[1]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -298,49 +491,53 @@ func (x *DeletePodRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeletePodRequest.ProtoReflect.Descriptor instead.
-func (*DeletePodRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use CreatePodsRequest.ProtoReflect.Descriptor instead.
+func (*CreatePodsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *DeletePodRequest) GetPodId() string {
+func (x *CreatePodsRequest) GetPods() []*CreatePodRequest {
 	if x != nil {
-		return x.PodId
+		return x.Pods
 	}
-	return ""
+	return nil
 }
 
-type ResourceInfo struct {
+type UpdatePodRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	RequestedCpus      int32     `protobuf:"varint,1,opt,name=requestedCpus,proto3" json:"requestedCpus,omitempty"`
-	LimitCpus          int32     `protobuf:"varint,2,opt,name=limitCpus,proto3" json:"limitCpus,omitempty"`
-	RequestedMemory    []byte    `protobuf:"bytes,3,opt,name=requestedMemory,proto3" json:"requestedMemory,omitempty"`
-	RequestedMemoryExp int32     `protobuf:"varint,4,opt,name=requestedMemoryExp,proto3" json:"requestedMemoryExp,omitempty"`
-	LimitMemory        []byte    `protobuf:"bytes,5,opt,name=limitMemory,proto3" json:"limitMemory,omitempty"`
-	LimitMemoryExp     int32     `protobuf:"varint,6,opt,name=limitMemoryExp,proto3" json:"limitMemoryExp,omitempty"`
-	CpuAffinity        Placement `protobuf:"varint,7,opt,name=cpuAffinity,proto3,enum=ctlplaneapi.Placement" json:"cpuAffinity,omitempty"`
+	PodId                 string            `protobuf:"bytes,1,opt,name=podId,proto3" json:"podId,omitempty"`
+	Resources             *ResourceInfo     `protobuf:"bytes,2,opt,name=resources,proto3" json:"resources,omitempty"`
+	Containers            []*ContainerInfo  `protobuf:"bytes,3,rep,name=containers,proto3" json:"containers,omitempty"`
+	ExclusiveLeaseSeconds uint32            `protobuf:"varint,4,opt,name=exclusiveLeaseSeconds,proto3" json:"exclusiveLeaseSeconds,omitempty"`                                                                    // renews exclusive cpus lease, 0 makes the exclusivity permanent
+	Labels                map[string]string `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`           // replace labels given on pod creation
+	Annotations           map[string]string `protobuf:"bytes,6,rep,name=annotations,proto3" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"` // replace annotations given on pod creation
+	Generation            int64             `protobuf:"varint,7,opt,name=generation,proto3" json:"generation,omitempty"`                                                                                          // version of the pod seen by the agent, requests older than the applied version are rejected
+	NodeName              string            `protobuf:"bytes,8,opt,name=nodeName,proto3" json:"nodeName,omitempty"`                                                                                               // node the pod is scheduled to, requests for other nodes are rejected; empty if unknown
+	DryRun                bool              `protobuf:"varint,9,opt,name=dryRun,proto3" json:"dryRun,omitempty"`                                                                                                  // allocation is computed and returned, but neither cgroups nor the state are changed
+	Partial               bool              `protobuf:"varint,10,opt,name=partial,proto3" json:"partial,omitempty"`                                                                                               // only listed containers are updated, other containers of the pod are kept instead of deleted
+	MemoryPinning         MemoryPinning     `protobuf:"varint,11,opt,name=memoryPinning,proto3,enum=ctlplaneapi.MemoryPinning" json:"memoryPinning,omitempty"`                                                    // replaces memory pinning given on pod creation, default applies the profile or namespace setting
 }
 
-func (x *ResourceInfo) Reset() {
-	*x = ResourceInfo{}
+func (x *UpdatePodRequest) Reset() {
+	*x = UpdatePodRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[3]
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ResourceInfo) String() string {
+func (x *UpdatePodRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ResourceInfo) ProtoMessage() {}
+func (*UpdatePodRequest) ProtoMessage() {}
 
-func (x *ResourceInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[3]
+func (x *UpdatePodRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -351,87 +548,114 @@ func (x *ResourceInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ResourceInfo.ProtoReflect.Descriptor instead.
-func (*ResourceInfo) Descriptor() ([]byte, []int) {
-	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use UpdatePodRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePodRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *ResourceInfo) GetRequestedCpus() int32 {
+func (x *UpdatePodRequest) GetPodId() string {
 	if x != nil {
-		return x.RequestedCpus
+		return x.PodId
 	}
-	return 0
+	return ""
 }
 
-func (x *ResourceInfo) GetLimitCpus() int32 {
+func (x *UpdatePodRequest) GetResources() *ResourceInfo {
 	if x != nil {
-		return x.LimitCpus
+		return x.Resources
 	}
-	return 0
+	return nil
 }
 
-func (x *ResourceInfo) GetRequestedMemory() []byte {
+func (x *UpdatePodRequest) GetContainers() []*ContainerInfo {
 	if x != nil {
-		return x.RequestedMemory
+		return x.Containers
 	}
 	return nil
 }
 
-func (x *ResourceInfo) GetRequestedMemoryExp() int32 {
+func (x *UpdatePodRequest) GetExclusiveLeaseSeconds() uint32 {
 	if x != nil {
-		return x.RequestedMemoryExp
+		return x.ExclusiveLeaseSeconds
 	}
 	return 0
 }
 
-func (x *ResourceInfo) GetLimitMemory() []byte {
+func (x *UpdatePodRequest) GetLabels() map[string]string {
 	if x != nil {
-		return x.LimitMemory
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *UpdatePodRequest) GetAnnotations() map[string]string {
+	if x != nil {
+		return x.Annotations
 	}
 	return nil
 }
 
-func (x *ResourceInfo) GetLimitMemoryExp() int32 {
+func (x *UpdatePodRequest) GetGeneration() int64 {
 	if x != nil {
-		return x.LimitMemoryExp
+		return x.Generation
 	}
 	return 0
 }
 
-func (x *ResourceInfo) GetCpuAffinity() Placement {
+func (x *UpdatePodRequest) GetNodeName() string {
 	if x != nil {
-		return x.CpuAffinity
+		return x.NodeName
 	}
-	return Placement_DEFAULT
+	return ""
 }
 
-type ContainerInfo struct {
+func (x *UpdatePodRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *UpdatePodRequest) GetPartial() bool {
+	if x != nil {
+		return x.Partial
+	}
+	return false
+}
+
+func (x *UpdatePodRequest) GetMemoryPinning() MemoryPinning {
+	if x != nil {
+		return x.MemoryPinning
+	}
+	return MemoryPinning_MEMORY_PINNING_DEFAULT
+}
+
+type DeletePodRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ContainerId   string        `protobuf:"bytes,1,opt,name=containerId,proto3" json:"containerId,omitempty"`
-	ContainerName string        `protobuf:"bytes,2,opt,name=containerName,proto3" json:"containerName,omitempty"`
-	Resources     *ResourceInfo `protobuf:"bytes,3,opt,name=resources,proto3" json:"resources,omitempty"`
+	PodId    string `protobuf:"bytes,1,opt,name=podId,proto3" json:"podId,omitempty"`
+	NodeName string `protobuf:"bytes,2,opt,name=nodeName,proto3" json:"nodeName,omitempty"` // node the pod is scheduled to, requests for other nodes are rejected; empty if unknown
 }
 
-func (x *ContainerInfo) Reset() {
-	*x = ContainerInfo{}
+func (x *DeletePodRequest) Reset() {
+	*x = DeletePodRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[4]
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ContainerInfo) String() string {
+func (x *DeletePodRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ContainerInfo) ProtoMessage() {}
+func (*DeletePodRequest) ProtoMessage() {}
 
-func (x *ContainerInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[4]
+func (x *DeletePodRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -442,59 +666,50 @@ func (x *ContainerInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ContainerInfo.ProtoReflect.Descriptor instead.
-func (*ContainerInfo) Descriptor() ([]byte, []int) {
-	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{4}
-}
-
-func (x *ContainerInfo) GetContainerId() string {
-	if x != nil {
-		return x.ContainerId
-	}
-	return ""
+// Deprecated: Use DeletePodRequest.ProtoReflect.Descriptor instead.
+func (*DeletePodRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *ContainerInfo) GetContainerName() string {
+func (x *DeletePodRequest) GetPodId() string {
 	if x != nil {
-		return x.ContainerName
+		return x.PodId
 	}
 	return ""
 }
 
-func (x *ContainerInfo) GetResources() *ResourceInfo {
+func (x *DeletePodRequest) GetNodeName() string {
 	if x != nil {
-		return x.Resources
+		return x.NodeName
 	}
-	return nil
+	return ""
 }
 
-type ContainerAllocationInfo struct {
+type GetPodRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ContainerId string          `protobuf:"bytes,1,opt,name=containerId,proto3" json:"containerId,omitempty"`
-	AllocState  AllocationState `protobuf:"varint,2,opt,name=allocState,proto3,enum=ctlplaneapi.AllocationState" json:"allocState,omitempty"`
-	CpuSet      []*CPUSet       `protobuf:"bytes,3,rep,name=cpuSet,proto3" json:"cpuSet,omitempty"`
+	PodId string `protobuf:"bytes,1,opt,name=podId,proto3" json:"podId,omitempty"`
 }
 
-func (x *ContainerAllocationInfo) Reset() {
-	*x = ContainerAllocationInfo{}
+func (x *GetPodRequest) Reset() {
+	*x = GetPodRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[5]
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ContainerAllocationInfo) String() string {
+func (x *GetPodRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ContainerAllocationInfo) ProtoMessage() {}
+func (*GetPodRequest) ProtoMessage() {}
 
-func (x *ContainerAllocationInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[5]
+func (x *GetPodRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -505,43 +720,67 @@ func (x *ContainerAllocationInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ContainerAllocationInfo.ProtoReflect.Descriptor instead.
-func (*ContainerAllocationInfo) Descriptor() ([]byte, []int) {
-	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use GetPodRequest.ProtoReflect.Descriptor instead.
+func (*GetPodRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *ContainerAllocationInfo) GetContainerId() string {
+func (x *GetPodRequest) GetPodId() string {
 	if x != nil {
-		return x.ContainerId
+		return x.PodId
 	}
 	return ""
 }
 
-func (x *ContainerAllocationInfo) GetAllocState() AllocationState {
-	if x != nil {
-		return x.AllocState
+type ListPodsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListPodsRequest) Reset() {
+	*x = ListPodsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return AllocationState_CREATED
 }
 
-func (x *ContainerAllocationInfo) GetCpuSet() []*CPUSet {
-	if x != nil {
-		return x.CpuSet
+func (x *ListPodsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPodsRequest) ProtoMessage() {}
+
+func (x *ListPodsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-type CPUSet struct {
+// Deprecated: Use ListPodsRequest.ProtoReflect.Descriptor instead.
+func (*ListPodsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{5}
+}
+
+type GetContainerRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	StartCPU int32 `protobuf:"varint,1,opt,name=startCPU,proto3" json:"startCPU,omitempty"`
-	EndCPU   int32 `protobuf:"varint,2,opt,name=endCPU,proto3" json:"endCPU,omitempty"`
+	PodId         string `protobuf:"bytes,1,opt,name=podId,proto3" json:"podId,omitempty"`
+	ContainerName string `protobuf:"bytes,2,opt,name=containerName,proto3" json:"containerName,omitempty"`
 }
 
-func (x *CPUSet) Reset() {
-	*x = CPUSet{}
+func (x *GetContainerRequest) Reset() {
+	*x = GetContainerRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -549,13 +788,13 @@ func (x *CPUSet) Reset() {
 	}
 }
 
-func (x *CPUSet) String() string {
+func (x *GetContainerRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CPUSet) ProtoMessage() {}
+func (*GetContainerRequest) ProtoMessage() {}
 
-func (x *CPUSet) ProtoReflect() protoreflect.Message {
+func (x *GetContainerRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -567,38 +806,37 @@ func (x *CPUSet) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CPUSet.ProtoReflect.Descriptor instead.
-func (*CPUSet) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetContainerRequest.ProtoReflect.Descriptor instead.
+func (*GetContainerRequest) Descriptor() ([]byte, []int) {
 	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *CPUSet) GetStartCPU() int32 {
+func (x *GetContainerRequest) GetPodId() string {
 	if x != nil {
-		return x.StartCPU
+		return x.PodId
 	}
-	return 0
+	return ""
 }
 
-func (x *CPUSet) GetEndCPU() int32 {
+func (x *GetContainerRequest) GetContainerName() string {
 	if x != nil {
-		return x.EndCPU
+		return x.ContainerName
 	}
-	return 0
+	return ""
 }
 
-type PodAllocationReply struct {
+type CreateNamespaceBucketRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	PodId                 string                     `protobuf:"bytes,1,opt,name=podId,proto3" json:"podId,omitempty"`
-	AllocState            AllocationState            `protobuf:"varint,2,opt,name=allocState,proto3,enum=ctlplaneapi.AllocationState" json:"allocState,omitempty"`
-	CpuSet                []*CPUSet                  `protobuf:"bytes,3,rep,name=cpuSet,proto3" json:"cpuSet,omitempty"`
-	ContainersAllocations []*ContainerAllocationInfo `protobuf:"bytes,4,rep,name=containersAllocations,proto3" json:"containersAllocations,omitempty"`
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	MinCpus   uint32 `protobuf:"varint,2,opt,name=minCpus,proto3" json:"minCpus,omitempty"`     // minimal number of cpus of the bucket, 0 accepts any bucket
+	CpuWeight uint32 `protobuf:"varint,3,opt,name=cpuWeight,proto3" json:"cpuWeight,omitempty"` // cpu weight per requested cpu of containers sharing the bucket, 0 for default
 }
 
-func (x *PodAllocationReply) Reset() {
-	*x = PodAllocationReply{}
+func (x *CreateNamespaceBucketRequest) Reset() {
+	*x = CreateNamespaceBucketRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -606,13 +844,13 @@ func (x *PodAllocationReply) Reset() {
 	}
 }
 
-func (x *PodAllocationReply) String() string {
+func (x *CreateNamespaceBucketRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PodAllocationReply) ProtoMessage() {}
+func (*CreateNamespaceBucketRequest) ProtoMessage() {}
 
-func (x *PodAllocationReply) ProtoReflect() protoreflect.Message {
+func (x *CreateNamespaceBucketRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -624,251 +862,3472 @@ func (x *PodAllocationReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PodAllocationReply.ProtoReflect.Descriptor instead.
-func (*PodAllocationReply) Descriptor() ([]byte, []int) {
+// Deprecated: Use CreateNamespaceBucketRequest.ProtoReflect.Descriptor instead.
+func (*CreateNamespaceBucketRequest) Descriptor() ([]byte, []int) {
 	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *PodAllocationReply) GetPodId() string {
+func (x *CreateNamespaceBucketRequest) GetNamespace() string {
 	if x != nil {
-		return x.PodId
+		return x.Namespace
 	}
 	return ""
 }
 
-func (x *PodAllocationReply) GetAllocState() AllocationState {
+func (x *CreateNamespaceBucketRequest) GetMinCpus() uint32 {
 	if x != nil {
-		return x.AllocState
+		return x.MinCpus
 	}
-	return AllocationState_CREATED
+	return 0
 }
 
-func (x *PodAllocationReply) GetCpuSet() []*CPUSet {
+func (x *CreateNamespaceBucketRequest) GetCpuWeight() uint32 {
 	if x != nil {
-		return x.CpuSet
+		return x.CpuWeight
 	}
-	return nil
+	return 0
 }
 
-func (x *PodAllocationReply) GetContainersAllocations() []*ContainerAllocationInfo {
+type DeleteNamespaceBucketRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+}
+
+func (x *DeleteNamespaceBucketRequest) Reset() {
+	*x = DeleteNamespaceBucketRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteNamespaceBucketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteNamespaceBucketRequest) ProtoMessage() {}
+
+func (x *DeleteNamespaceBucketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteNamespaceBucketRequest.ProtoReflect.Descriptor instead.
+func (*DeleteNamespaceBucketRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteNamespaceBucketRequest) GetNamespace() string {
 	if x != nil {
-		return x.ContainersAllocations
+		return x.Namespace
 	}
-	return nil
+	return ""
 }
 
-var File_pkg_ctlplaneapi_controlplane_proto protoreflect.FileDescriptor
+type GetDaemonInfoRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
 
-var file_pkg_ctlplaneapi_controlplane_proto_rawDesc = []byte{
-	0x0a, 0x22, 0x70, 0x6b, 0x67, 0x2f, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70,
-	0x69, 0x2f, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70,
-	0x69, 0x22, 0xdb, 0x01, 0x0a, 0x10, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07,
-	0x70, 0x6f, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70,
-	0x6f, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x70, 0x6f, 0x64, 0x4e, 0x61, 0x6d,
-	0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x6f,
-	0x64, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x37, 0x0a, 0x09, 0x72, 0x65,
-	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
-	0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x73, 0x6f,
-	0x75, 0x72, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x09, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72,
-	0x63, 0x65, 0x73, 0x12, 0x3a, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72,
-	0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61,
-	0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x49,
-	0x6e, 0x66, 0x6f, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x73, 0x22,
-	0x9d, 0x01, 0x0a, 0x10, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x37, 0x0a, 0x09, 0x72, 0x65,
-	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
-	0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x73, 0x6f,
-	0x75, 0x72, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x09, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72,
-	0x63, 0x65, 0x73, 0x12, 0x3a, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72,
-	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61,
-	0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x49,
-	0x6e, 0x66, 0x6f, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x73, 0x22,
-	0x28, 0x0a, 0x10, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x22, 0xb0, 0x02, 0x0a, 0x0c, 0x52, 0x65,
-	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x24, 0x0a, 0x0d, 0x72, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x43, 0x70, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x05, 0x52, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x43, 0x70, 0x75, 0x73,
-	0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x43, 0x70, 0x75, 0x73, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x05, 0x52, 0x09, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x43, 0x70, 0x75, 0x73, 0x12, 0x28,
-	0x0a, 0x0f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x4d, 0x65, 0x6d, 0x6f, 0x72,
-	0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x65, 0x64, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x12, 0x2e, 0x0a, 0x12, 0x72, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x65, 0x64, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x45, 0x78, 0x70, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x05, 0x52, 0x12, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x4d,
-	0x65, 0x6d, 0x6f, 0x72, 0x79, 0x45, 0x78, 0x70, 0x12, 0x20, 0x0a, 0x0b, 0x6c, 0x69, 0x6d, 0x69,
-	0x74, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x6c,
-	0x69, 0x6d, 0x69, 0x74, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x12, 0x26, 0x0a, 0x0e, 0x6c, 0x69,
-	0x6d, 0x69, 0x74, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x45, 0x78, 0x70, 0x18, 0x06, 0x20, 0x01,
-	0x28, 0x05, 0x52, 0x0e, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x45,
-	0x78, 0x70, 0x12, 0x38, 0x0a, 0x0b, 0x63, 0x70, 0x75, 0x41, 0x66, 0x66, 0x69, 0x6e, 0x69, 0x74,
-	0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61,
-	0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52,
-	0x0b, 0x63, 0x70, 0x75, 0x41, 0x66, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x79, 0x22, 0x90, 0x01, 0x0a,
-	0x0d, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x20,
-	0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x49, 0x64, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x49, 0x64,
-	0x12, 0x24, 0x0a, 0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x4e, 0x61, 0x6d,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e,
-	0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x37, 0x0a, 0x09, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72,
-	0x63, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x63, 0x74, 0x6c, 0x70,
-	0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
-	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x09, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x22,
-	0xa6, 0x01, 0x0a, 0x17, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x41, 0x6c, 0x6c,
-	0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x20, 0x0a, 0x0b, 0x63,
-	0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x49, 0x64, 0x12, 0x3c, 0x0a,
-	0x0a, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x53, 0x74, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0e, 0x32, 0x1c, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e,
-	0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
-	0x0a, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x2b, 0x0a, 0x06, 0x63,
-	0x70, 0x75, 0x53, 0x65, 0x74, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x74,
-	0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x50, 0x55, 0x53, 0x65, 0x74,
-	0x52, 0x06, 0x63, 0x70, 0x75, 0x53, 0x65, 0x74, 0x22, 0x3c, 0x0a, 0x06, 0x43, 0x50, 0x55, 0x53,
-	0x65, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x74, 0x61, 0x72, 0x74, 0x43, 0x50, 0x55, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x73, 0x74, 0x61, 0x72, 0x74, 0x43, 0x50, 0x55, 0x12, 0x16,
-	0x0a, 0x06, 0x65, 0x6e, 0x64, 0x43, 0x50, 0x55, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06,
-	0x65, 0x6e, 0x64, 0x43, 0x50, 0x55, 0x22, 0xf1, 0x01, 0x0a, 0x12, 0x50, 0x6f, 0x64, 0x41, 0x6c,
-	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x14, 0x0a,
-	0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f,
-	0x64, 0x49, 0x64, 0x12, 0x3c, 0x0a, 0x0a, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x53, 0x74, 0x61, 0x74,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61,
-	0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x0a, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x53, 0x74, 0x61, 0x74,
-	0x65, 0x12, 0x2b, 0x0a, 0x06, 0x63, 0x70, 0x75, 0x53, 0x65, 0x74, 0x18, 0x03, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x13, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e,
-	0x43, 0x50, 0x55, 0x53, 0x65, 0x74, 0x52, 0x06, 0x63, 0x70, 0x75, 0x53, 0x65, 0x74, 0x12, 0x5a,
-	0x0a, 0x15, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x73, 0x41, 0x6c, 0x6c, 0x6f,
-	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e,
-	0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6e, 0x74,
-	0x61, 0x69, 0x6e, 0x65, 0x72, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49,
-	0x6e, 0x66, 0x6f, 0x52, 0x15, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x73, 0x41,
-	0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2a, 0x38, 0x0a, 0x0f, 0x41, 0x6c,
-	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x0b, 0x0a,
-	0x07, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x50,
-	0x44, 0x41, 0x54, 0x45, 0x44, 0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07, 0x44, 0x45, 0x4c, 0x45, 0x54,
-	0x45, 0x44, 0x10, 0x02, 0x2a, 0x3c, 0x0a, 0x09, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x6d, 0x65, 0x6e,
-	0x74, 0x12, 0x0b, 0x0a, 0x07, 0x44, 0x45, 0x46, 0x41, 0x55, 0x4c, 0x54, 0x10, 0x00, 0x12, 0x0b,
-	0x0a, 0x07, 0x43, 0x4f, 0x4d, 0x50, 0x41, 0x43, 0x54, 0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07, 0x53,
-	0x43, 0x41, 0x54, 0x54, 0x45, 0x52, 0x10, 0x02, 0x12, 0x08, 0x0a, 0x04, 0x50, 0x4f, 0x4f, 0x4c,
-	0x10, 0x03, 0x32, 0xfb, 0x01, 0x0a, 0x0c, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x50, 0x6c,
-	0x61, 0x6e, 0x65, 0x12, 0x4d, 0x0a, 0x09, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64,
-	0x12, 0x1d, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43,
-	0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x1f, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x6f,
-	0x64, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79,
-	0x22, 0x00, 0x12, 0x4d, 0x0a, 0x09, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x12,
-	0x1d, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x55, 0x70,
-	0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f,
-	0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x6f, 0x64,
-	0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22,
-	0x00, 0x12, 0x4d, 0x0a, 0x09, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x12, 0x1d,
-	0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x65, 0x6c,
-	0x65, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e,
-	0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x6f, 0x64, 0x41,
-	0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00,
-	0x42, 0x0f, 0x5a, 0x0d, 0x2e, 0x2f, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70,
-	0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *GetDaemonInfoRequest) Reset() {
+	*x = GetDaemonInfoRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
 
-var (
-	file_pkg_ctlplaneapi_controlplane_proto_rawDescOnce sync.Once
-	file_pkg_ctlplaneapi_controlplane_proto_rawDescData = file_pkg_ctlplaneapi_controlplane_proto_rawDesc
-)
+func (x *GetDaemonInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
 
-func file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP() []byte {
-	file_pkg_ctlplaneapi_controlplane_proto_rawDescOnce.Do(func() {
-		file_pkg_ctlplaneapi_controlplane_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_ctlplaneapi_controlplane_proto_rawDescData)
-	})
-	return file_pkg_ctlplaneapi_controlplane_proto_rawDescData
+func (*GetDaemonInfoRequest) ProtoMessage() {}
+
+func (x *GetDaemonInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-var file_pkg_ctlplaneapi_controlplane_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_pkg_ctlplaneapi_controlplane_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
-var file_pkg_ctlplaneapi_controlplane_proto_goTypes = []interface{}{
-	(AllocationState)(0),            // 0: ctlplaneapi.AllocationState
-	(Placement)(0),                  // 1: ctlplaneapi.Placement
-	(*CreatePodRequest)(nil),        // 2: ctlplaneapi.CreatePodRequest
-	(*UpdatePodRequest)(nil),        // 3: ctlplaneapi.UpdatePodRequest
-	(*DeletePodRequest)(nil),        // 4: ctlplaneapi.DeletePodRequest
-	(*ResourceInfo)(nil),            // 5: ctlplaneapi.ResourceInfo
-	(*ContainerInfo)(nil),           // 6: ctlplaneapi.ContainerInfo
-	(*ContainerAllocationInfo)(nil), // 7: ctlplaneapi.ContainerAllocationInfo
-	(*CPUSet)(nil),                  // 8: ctlplaneapi.CPUSet
-	(*PodAllocationReply)(nil),      // 9: ctlplaneapi.PodAllocationReply
+// Deprecated: Use GetDaemonInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetDaemonInfoRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{9}
 }
-var file_pkg_ctlplaneapi_controlplane_proto_depIdxs = []int32{
-	5,  // 0: ctlplaneapi.CreatePodRequest.resources:type_name -> ctlplaneapi.ResourceInfo
-	6,  // 1: ctlplaneapi.CreatePodRequest.containers:type_name -> ctlplaneapi.ContainerInfo
-	5,  // 2: ctlplaneapi.UpdatePodRequest.resources:type_name -> ctlplaneapi.ResourceInfo
-	6,  // 3: ctlplaneapi.UpdatePodRequest.containers:type_name -> ctlplaneapi.ContainerInfo
-	1,  // 4: ctlplaneapi.ResourceInfo.cpuAffinity:type_name -> ctlplaneapi.Placement
-	5,  // 5: ctlplaneapi.ContainerInfo.resources:type_name -> ctlplaneapi.ResourceInfo
-	0,  // 6: ctlplaneapi.ContainerAllocationInfo.allocState:type_name -> ctlplaneapi.AllocationState
-	8,  // 7: ctlplaneapi.ContainerAllocationInfo.cpuSet:type_name -> ctlplaneapi.CPUSet
-	0,  // 8: ctlplaneapi.PodAllocationReply.allocState:type_name -> ctlplaneapi.AllocationState
-	8,  // 9: ctlplaneapi.PodAllocationReply.cpuSet:type_name -> ctlplaneapi.CPUSet
-	7,  // 10: ctlplaneapi.PodAllocationReply.containersAllocations:type_name -> ctlplaneapi.ContainerAllocationInfo
-	2,  // 11: ctlplaneapi.ControlPlane.CreatePod:input_type -> ctlplaneapi.CreatePodRequest
-	3,  // 12: ctlplaneapi.ControlPlane.UpdatePod:input_type -> ctlplaneapi.UpdatePodRequest
-	4,  // 13: ctlplaneapi.ControlPlane.DeletePod:input_type -> ctlplaneapi.DeletePodRequest
-	9,  // 14: ctlplaneapi.ControlPlane.CreatePod:output_type -> ctlplaneapi.PodAllocationReply
-	9,  // 15: ctlplaneapi.ControlPlane.UpdatePod:output_type -> ctlplaneapi.PodAllocationReply
-	9,  // 16: ctlplaneapi.ControlPlane.DeletePod:output_type -> ctlplaneapi.PodAllocationReply
-	14, // [14:17] is the sub-list for method output_type
-	11, // [11:14] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+
+type GetConfigRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 }
 
-func init() { file_pkg_ctlplaneapi_controlplane_proto_init() }
-func file_pkg_ctlplaneapi_controlplane_proto_init() {
-	if File_pkg_ctlplaneapi_controlplane_proto != nil {
-		return
+func (x *GetConfigRequest) Reset() {
+	*x = GetConfigRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreatePodRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UpdatePodRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+}
+
+func (x *GetConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConfigRequest) ProtoMessage() {}
+
+func (x *GetConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DeletePodRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetConfigRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{10}
+}
+
+type GetAllocationsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetAllocationsRequest) Reset() {
+	*x = GetAllocationsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetAllocationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAllocationsRequest) ProtoMessage() {}
+
+func (x *GetAllocationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAllocationsRequest.ProtoReflect.Descriptor instead.
+func (*GetAllocationsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{11}
+}
+
+type MigrateContainerRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ContainerId string `protobuf:"bytes,1,opt,name=containerId,proto3" json:"containerId,omitempty"`
+	TargetNode  uint32 `protobuf:"varint,2,opt,name=targetNode,proto3" json:"targetNode,omitempty"` // numa node the cpus and memory of the container are moved to
+}
+
+func (x *MigrateContainerRequest) Reset() {
+	*x = MigrateContainerRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MigrateContainerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MigrateContainerRequest) ProtoMessage() {}
+
+func (x *MigrateContainerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MigrateContainerRequest.ProtoReflect.Descriptor instead.
+func (*MigrateContainerRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *MigrateContainerRequest) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *MigrateContainerRequest) GetTargetNode() uint32 {
+	if x != nil {
+		return x.TargetNode
+	}
+	return 0
+}
+
+type ReserveCPUPoolRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name      string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                   // name of the pool, eg. dpdk
+	Cpus      uint32   `protobuf:"varint,2,opt,name=cpus,proto3" json:"cpus,omitempty"`                  // number of cpus of the pool
+	NumaNodes []uint32 `protobuf:"varint,3,rep,packed,name=numaNodes,proto3" json:"numaNodes,omitempty"` // numa nodes the pool may be reserved on, any node if empty
+}
+
+func (x *ReserveCPUPoolRequest) Reset() {
+	*x = ReserveCPUPoolRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReserveCPUPoolRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveCPUPoolRequest) ProtoMessage() {}
+
+func (x *ReserveCPUPoolRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveCPUPoolRequest.ProtoReflect.Descriptor instead.
+func (*ReserveCPUPoolRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ReserveCPUPoolRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ReserveCPUPoolRequest) GetCpus() uint32 {
+	if x != nil {
+		return x.Cpus
+	}
+	return 0
+}
+
+func (x *ReserveCPUPoolRequest) GetNumaNodes() []uint32 {
+	if x != nil {
+		return x.NumaNodes
+	}
+	return nil
+}
+
+type ReleaseCPUPoolRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *ReleaseCPUPoolRequest) Reset() {
+	*x = ReleaseCPUPoolRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReleaseCPUPoolRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseCPUPoolRequest) ProtoMessage() {}
+
+func (x *ReleaseCPUPoolRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseCPUPoolRequest.ProtoReflect.Descriptor instead.
+func (*ReleaseCPUPoolRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ReleaseCPUPoolRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type SetNamespaceConfigRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Namespace  string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Allocator  string `protobuf:"bytes,2,opt,name=allocator,proto3" json:"allocator,omitempty"`    // allocator of new pods of the namespace, daemon allocator if empty
+	Exclusive  bool   `protobuf:"varint,3,opt,name=exclusive,proto3" json:"exclusive,omitempty"`   // false if guaranteed containers of the namespace run in the shared pool
+	BucketCpus uint32 `protobuf:"varint,4,opt,name=bucketCpus,proto3" json:"bucketCpus,omitempty"` // minimal cpus of the namespace bucket of numa-namespace allocators, 0 if not reserved
+}
+
+func (x *SetNamespaceConfigRequest) Reset() {
+	*x = SetNamespaceConfigRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetNamespaceConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetNamespaceConfigRequest) ProtoMessage() {}
+
+func (x *SetNamespaceConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetNamespaceConfigRequest.ProtoReflect.Descriptor instead.
+func (*SetNamespaceConfigRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SetNamespaceConfigRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *SetNamespaceConfigRequest) GetAllocator() string {
+	if x != nil {
+		return x.Allocator
+	}
+	return ""
+}
+
+func (x *SetNamespaceConfigRequest) GetExclusive() bool {
+	if x != nil {
+		return x.Exclusive
+	}
+	return false
+}
+
+func (x *SetNamespaceConfigRequest) GetBucketCpus() uint32 {
+	if x != nil {
+		return x.BucketCpus
+	}
+	return 0
+}
+
+type DeleteNamespaceConfigRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+}
+
+func (x *DeleteNamespaceConfigRequest) Reset() {
+	*x = DeleteNamespaceConfigRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteNamespaceConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteNamespaceConfigRequest) ProtoMessage() {}
+
+func (x *DeleteNamespaceConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteNamespaceConfigRequest.ProtoReflect.Descriptor instead.
+func (*DeleteNamespaceConfigRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *DeleteNamespaceConfigRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+type ListUsageRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"` // namespace to report, all namespaces if empty
+}
+
+func (x *ListUsageRequest) Reset() {
+	*x = ListUsageRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsageRequest) ProtoMessage() {}
+
+func (x *ListUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsageRequest.ProtoReflect.Descriptor instead.
+func (*ListUsageRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListUsageRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+type PlanDefragmentationRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cpus    uint32 `protobuf:"varint,1,opt,name=cpus,proto3" json:"cpus,omitempty"`       // number of cpus which shall fit on a single numa node
+	Execute bool   `protobuf:"varint,2,opt,name=execute,proto3" json:"execute,omitempty"` // if set, migrations of the plan are executed
+}
+
+func (x *PlanDefragmentationRequest) Reset() {
+	*x = PlanDefragmentationRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PlanDefragmentationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanDefragmentationRequest) ProtoMessage() {}
+
+func (x *PlanDefragmentationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanDefragmentationRequest.ProtoReflect.Descriptor instead.
+func (*PlanDefragmentationRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *PlanDefragmentationRequest) GetCpus() uint32 {
+	if x != nil {
+		return x.Cpus
+	}
+	return 0
+}
+
+func (x *PlanDefragmentationRequest) GetExecute() bool {
+	if x != nil {
+		return x.Execute
+	}
+	return false
+}
+
+type ResourceInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestedCpus   int32     `protobuf:"varint,1,opt,name=requestedCpus,proto3" json:"requestedCpus,omitempty"`
+	LimitCpus       int32     `protobuf:"varint,2,opt,name=limitCpus,proto3" json:"limitCpus,omitempty"`
+	RequestedMemory []byte    `protobuf:"bytes,3,opt,name=requestedMemory,proto3" json:"requestedMemory,omitempty"`
+	LimitMemory     []byte    `protobuf:"bytes,5,opt,name=limitMemory,proto3" json:"limitMemory,omitempty"`
+	CpuAffinity     Placement `protobuf:"varint,7,opt,name=cpuAffinity,proto3,enum=ctlplaneapi.Placement" json:"cpuAffinity,omitempty"`
+}
+
+func (x *ResourceInfo) Reset() {
+	*x = ResourceInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResourceInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceInfo) ProtoMessage() {}
+
+func (x *ResourceInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceInfo.ProtoReflect.Descriptor instead.
+func (*ResourceInfo) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ResourceInfo) GetRequestedCpus() int32 {
+	if x != nil {
+		return x.RequestedCpus
+	}
+	return 0
+}
+
+func (x *ResourceInfo) GetLimitCpus() int32 {
+	if x != nil {
+		return x.LimitCpus
+	}
+	return 0
+}
+
+func (x *ResourceInfo) GetRequestedMemory() []byte {
+	if x != nil {
+		return x.RequestedMemory
+	}
+	return nil
+}
+
+func (x *ResourceInfo) GetLimitMemory() []byte {
+	if x != nil {
+		return x.LimitMemory
+	}
+	return nil
+}
+
+func (x *ResourceInfo) GetCpuAffinity() Placement {
+	if x != nil {
+		return x.CpuAffinity
+	}
+	return Placement_DEFAULT
+}
+
+type ContainerInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ContainerId   string        `protobuf:"bytes,1,opt,name=containerId,proto3" json:"containerId,omitempty"`
+	ContainerName string        `protobuf:"bytes,2,opt,name=containerName,proto3" json:"containerName,omitempty"`
+	Resources     *ResourceInfo `protobuf:"bytes,3,opt,name=resources,proto3" json:"resources,omitempty"`
+}
+
+func (x *ContainerInfo) Reset() {
+	*x = ContainerInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ContainerInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerInfo) ProtoMessage() {}
+
+func (x *ContainerInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerInfo.ProtoReflect.Descriptor instead.
+func (*ContainerInfo) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ContainerInfo) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *ContainerInfo) GetContainerName() string {
+	if x != nil {
+		return x.ContainerName
+	}
+	return ""
+}
+
+func (x *ContainerInfo) GetResources() *ResourceInfo {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+type ContainerAllocationInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ContainerId          string          `protobuf:"bytes,1,opt,name=containerId,proto3" json:"containerId,omitempty"`
+	AllocState           AllocationState `protobuf:"varint,2,opt,name=allocState,proto3,enum=ctlplaneapi.AllocationState" json:"allocState,omitempty"`
+	CpuSet               []*CPUSet       `protobuf:"bytes,3,rep,name=cpuSet,proto3" json:"cpuSet,omitempty"`
+	Qos                  QoSClass        `protobuf:"varint,4,opt,name=qos,proto3,enum=ctlplaneapi.QoSClass" json:"qos,omitempty"`
+	MemoryNodes          string          `protobuf:"bytes,5,opt,name=memoryNodes,proto3" json:"memoryNodes,omitempty"`                    // memory nodes container is pinned to, empty if memory is not pinned
+	Exclusive            bool            `protobuf:"varint,6,opt,name=exclusive,proto3" json:"exclusive,omitempty"`                       // true if cpus are exclusively allocated, false if container runs in shared pool
+	AllocationTimestamp  int64           `protobuf:"varint,7,opt,name=allocationTimestamp,proto3" json:"allocationTimestamp,omitempty"`   // unix time in seconds when cpus were allocated, 0 if not allocated
+	AllocationAgeSeconds int64           `protobuf:"varint,8,opt,name=allocationAgeSeconds,proto3" json:"allocationAgeSeconds,omitempty"` // time since the allocation, at the time of the reply
+	CgroupPath           string          `protobuf:"bytes,9,opt,name=cgroupPath,proto3" json:"cgroupPath,omitempty"`                      // cgroup directory written by the last cpuset update, empty if none
+	ReleasedPool         string          `protobuf:"bytes,10,opt,name=releasedPool,proto3" json:"releasedPool,omitempty"`                 // pool the freed cpus returned to, set only in DeletePod replies
+	Explanation          string          `protobuf:"bytes,11,opt,name=explanation,proto3" json:"explanation,omitempty"`                   // why the allocator selected the cpus (eg. bucket, topology node), empty if not recorded
+	EffectiveCpuSet      string          `protobuf:"bytes,12,opt,name=effectiveCpuSet,proto3" json:"effectiveCpuSet,omitempty"`           // cpus read back from the cgroup after the last update, empty if not verified
+}
+
+func (x *ContainerAllocationInfo) Reset() {
+	*x = ContainerAllocationInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ContainerAllocationInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerAllocationInfo) ProtoMessage() {}
+
+func (x *ContainerAllocationInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerAllocationInfo.ProtoReflect.Descriptor instead.
+func (*ContainerAllocationInfo) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ContainerAllocationInfo) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *ContainerAllocationInfo) GetAllocState() AllocationState {
+	if x != nil {
+		return x.AllocState
+	}
+	return AllocationState_CREATED
+}
+
+func (x *ContainerAllocationInfo) GetCpuSet() []*CPUSet {
+	if x != nil {
+		return x.CpuSet
+	}
+	return nil
+}
+
+func (x *ContainerAllocationInfo) GetQos() QoSClass {
+	if x != nil {
+		return x.Qos
+	}
+	return QoSClass_GUARANTEED
+}
+
+func (x *ContainerAllocationInfo) GetMemoryNodes() string {
+	if x != nil {
+		return x.MemoryNodes
+	}
+	return ""
+}
+
+func (x *ContainerAllocationInfo) GetExclusive() bool {
+	if x != nil {
+		return x.Exclusive
+	}
+	return false
+}
+
+func (x *ContainerAllocationInfo) GetAllocationTimestamp() int64 {
+	if x != nil {
+		return x.AllocationTimestamp
+	}
+	return 0
+}
+
+func (x *ContainerAllocationInfo) GetAllocationAgeSeconds() int64 {
+	if x != nil {
+		return x.AllocationAgeSeconds
+	}
+	return 0
+}
+
+func (x *ContainerAllocationInfo) GetCgroupPath() string {
+	if x != nil {
+		return x.CgroupPath
+	}
+	return ""
+}
+
+func (x *ContainerAllocationInfo) GetReleasedPool() string {
+	if x != nil {
+		return x.ReleasedPool
+	}
+	return ""
+}
+
+func (x *ContainerAllocationInfo) GetExplanation() string {
+	if x != nil {
+		return x.Explanation
+	}
+	return ""
+}
+
+func (x *ContainerAllocationInfo) GetEffectiveCpuSet() string {
+	if x != nil {
+		return x.EffectiveCpuSet
+	}
+	return ""
+}
+
+type CPUSet struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StartCPU int32 `protobuf:"varint,1,opt,name=startCPU,proto3" json:"startCPU,omitempty"`
+	EndCPU   int32 `protobuf:"varint,2,opt,name=endCPU,proto3" json:"endCPU,omitempty"`
+}
+
+func (x *CPUSet) Reset() {
+	*x = CPUSet{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CPUSet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CPUSet) ProtoMessage() {}
+
+func (x *CPUSet) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CPUSet.ProtoReflect.Descriptor instead.
+func (*CPUSet) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CPUSet) GetStartCPU() int32 {
+	if x != nil {
+		return x.StartCPU
+	}
+	return 0
+}
+
+func (x *CPUSet) GetEndCPU() int32 {
+	if x != nil {
+		return x.EndCPU
+	}
+	return 0
+}
+
+type ContainerStatusInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ContainerId string          `protobuf:"bytes,1,opt,name=containerId,proto3" json:"containerId,omitempty"`
+	Status      ContainerStatus `protobuf:"varint,2,opt,name=status,proto3,enum=ctlplaneapi.ContainerStatus" json:"status,omitempty"`
+	Reason      string          `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"` // error of failed container, otherwise what was done with the container
+}
+
+func (x *ContainerStatusInfo) Reset() {
+	*x = ContainerStatusInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ContainerStatusInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerStatusInfo) ProtoMessage() {}
+
+func (x *ContainerStatusInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerStatusInfo.ProtoReflect.Descriptor instead.
+func (*ContainerStatusInfo) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ContainerStatusInfo) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *ContainerStatusInfo) GetStatus() ContainerStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ContainerStatus_SUCCESS
+}
+
+func (x *ContainerStatusInfo) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type PodAllocationReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PodId                 string                     `protobuf:"bytes,1,opt,name=podId,proto3" json:"podId,omitempty"`
+	AllocState            AllocationState            `protobuf:"varint,2,opt,name=allocState,proto3,enum=ctlplaneapi.AllocationState" json:"allocState,omitempty"`
+	CpuSet                []*CPUSet                  `protobuf:"bytes,3,rep,name=cpuSet,proto3" json:"cpuSet,omitempty"`
+	ContainersAllocations []*ContainerAllocationInfo `protobuf:"bytes,4,rep,name=containersAllocations,proto3" json:"containersAllocations,omitempty"`
+	Unmanaged             bool                       `protobuf:"varint,5,opt,name=unmanaged,proto3" json:"unmanaged,omitempty"`                // pod failed validation and runs in shared pool, set only in best-effort validation mode
+	UnmanagedReason       string                     `protobuf:"bytes,6,opt,name=unmanagedReason,proto3" json:"unmanagedReason,omitempty"`     // validation error of unmanaged pod
+	DryRun                bool                       `protobuf:"varint,7,opt,name=dryRun,proto3" json:"dryRun,omitempty"`                      // allocation was computed by a dry run, it is not applied
+	ContainerStatuses     []*ContainerStatusInfo     `protobuf:"bytes,8,rep,name=containerStatuses,proto3" json:"containerStatuses,omitempty"` // result of each container, set only in CreatePod and UpdatePod replies
+}
+
+func (x *PodAllocationReply) Reset() {
+	*x = PodAllocationReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PodAllocationReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PodAllocationReply) ProtoMessage() {}
+
+func (x *PodAllocationReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PodAllocationReply.ProtoReflect.Descriptor instead.
+func (*PodAllocationReply) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *PodAllocationReply) GetPodId() string {
+	if x != nil {
+		return x.PodId
+	}
+	return ""
+}
+
+func (x *PodAllocationReply) GetAllocState() AllocationState {
+	if x != nil {
+		return x.AllocState
+	}
+	return AllocationState_CREATED
+}
+
+func (x *PodAllocationReply) GetCpuSet() []*CPUSet {
+	if x != nil {
+		return x.CpuSet
+	}
+	return nil
+}
+
+func (x *PodAllocationReply) GetContainersAllocations() []*ContainerAllocationInfo {
+	if x != nil {
+		return x.ContainersAllocations
+	}
+	return nil
+}
+
+func (x *PodAllocationReply) GetUnmanaged() bool {
+	if x != nil {
+		return x.Unmanaged
+	}
+	return false
+}
+
+func (x *PodAllocationReply) GetUnmanagedReason() string {
+	if x != nil {
+		return x.UnmanagedReason
+	}
+	return ""
+}
+
+func (x *PodAllocationReply) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *PodAllocationReply) GetContainerStatuses() []*ContainerStatusInfo {
+	if x != nil {
+		return x.ContainerStatuses
+	}
+	return nil
+}
+
+// Result of allocation of a single pod of CreatePodsRequest; either reply or error is set
+type CreatePodResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PodId         string              `protobuf:"bytes,1,opt,name=podId,proto3" json:"podId,omitempty"`
+	Reply         *PodAllocationReply `protobuf:"bytes,2,opt,name=reply,proto3" json:"reply,omitempty"`
+	Error         string              `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	ErrorReason   string              `protobuf:"bytes,4,opt,name=errorReason,proto3" json:"errorReason,omitempty"`                                                                                             // reason of ErrorInfo details of the error, eg. RUNTIME_MISMATCH
+	ErrorMetadata map[string]string   `protobuf:"bytes,5,rep,name=errorMetadata,proto3" json:"errorMetadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"` // metadata of ErrorInfo details of the error
+}
+
+func (x *CreatePodResult) Reset() {
+	*x = CreatePodResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreatePodResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePodResult) ProtoMessage() {}
+
+func (x *CreatePodResult) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePodResult.ProtoReflect.Descriptor instead.
+func (*CreatePodResult) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *CreatePodResult) GetPodId() string {
+	if x != nil {
+		return x.PodId
+	}
+	return ""
+}
+
+func (x *CreatePodResult) GetReply() *PodAllocationReply {
+	if x != nil {
+		return x.Reply
+	}
+	return nil
+}
+
+func (x *CreatePodResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *CreatePodResult) GetErrorReason() string {
+	if x != nil {
+		return x.ErrorReason
+	}
+	return ""
+}
+
+func (x *CreatePodResult) GetErrorMetadata() map[string]string {
+	if x != nil {
+		return x.ErrorMetadata
+	}
+	return nil
+}
+
+type CreatePodsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Results []*CreatePodResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // in order of pods in the request
+}
+
+func (x *CreatePodsReply) Reset() {
+	*x = CreatePodsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreatePodsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePodsReply) ProtoMessage() {}
+
+func (x *CreatePodsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePodsReply.ProtoReflect.Descriptor instead.
+func (*CreatePodsReply) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *CreatePodsReply) GetResults() []*CreatePodResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type ListPodsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pods []*PodAllocationReply `protobuf:"bytes,1,rep,name=pods,proto3" json:"pods,omitempty"`
+}
+
+func (x *ListPodsReply) Reset() {
+	*x = ListPodsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListPodsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPodsReply) ProtoMessage() {}
+
+func (x *ListPodsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPodsReply.ProtoReflect.Descriptor instead.
+func (*ListPodsReply) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ListPodsReply) GetPods() []*PodAllocationReply {
+	if x != nil {
+		return x.Pods
+	}
+	return nil
+}
+
+type NumaNodeInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Node          int32     `protobuf:"varint,1,opt,name=node,proto3" json:"node,omitempty"`                  // id of the first level topology node, usually numa node
+	CpuSet        []*CPUSet `protobuf:"bytes,2,rep,name=cpuSet,proto3" json:"cpuSet,omitempty"`               // cpus of the node managed by the daemon
+	AvailableCpus []*CPUSet `protobuf:"bytes,3,rep,name=availableCpus,proto3" json:"availableCpus,omitempty"` // cpus of the node which can still be allocated
+}
+
+func (x *NumaNodeInfo) Reset() {
+	*x = NumaNodeInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NumaNodeInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NumaNodeInfo) ProtoMessage() {}
+
+func (x *NumaNodeInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NumaNodeInfo.ProtoReflect.Descriptor instead.
+func (*NumaNodeInfo) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *NumaNodeInfo) GetNode() int32 {
+	if x != nil {
+		return x.Node
+	}
+	return 0
+}
+
+func (x *NumaNodeInfo) GetCpuSet() []*CPUSet {
+	if x != nil {
+		return x.CpuSet
+	}
+	return nil
+}
+
+func (x *NumaNodeInfo) GetAvailableCpus() []*CPUSet {
+	if x != nil {
+		return x.AvailableCpus
+	}
+	return nil
+}
+
+type TopologySummary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cpus           int32           `protobuf:"varint,1,opt,name=cpus,proto3" json:"cpus,omitempty"`                     // number of cpus managed by the daemon
+	ThreadsPerCore int32           `protobuf:"varint,2,opt,name=threadsPerCore,proto3" json:"threadsPerCore,omitempty"` // the largest number of hardware threads of a physical core
+	NumaNodes      []*NumaNodeInfo `protobuf:"bytes,3,rep,name=numaNodes,proto3" json:"numaNodes,omitempty"`
+}
+
+func (x *TopologySummary) Reset() {
+	*x = TopologySummary{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TopologySummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopologySummary) ProtoMessage() {}
+
+func (x *TopologySummary) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopologySummary.ProtoReflect.Descriptor instead.
+func (*TopologySummary) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *TopologySummary) GetCpus() int32 {
+	if x != nil {
+		return x.Cpus
+	}
+	return 0
+}
+
+func (x *TopologySummary) GetThreadsPerCore() int32 {
+	if x != nil {
+		return x.ThreadsPerCore
+	}
+	return 0
+}
+
+func (x *TopologySummary) GetNumaNodes() []*NumaNodeInfo {
+	if x != nil {
+		return x.NumaNodes
+	}
+	return nil
+}
+
+type AllocationsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pods          []*PodAllocationReply `protobuf:"bytes,1,rep,name=pods,proto3" json:"pods,omitempty"`                   // allocations of all pods, ordered by pod id
+	AvailableCpus []*CPUSet             `protobuf:"bytes,2,rep,name=availableCpus,proto3" json:"availableCpus,omitempty"` // cpus which can still be allocated
+	Topology      *TopologySummary      `protobuf:"bytes,3,opt,name=topology,proto3" json:"topology,omitempty"`
+	CpuPools      []*CPUPoolReply       `protobuf:"bytes,4,rep,name=cpuPools,proto3" json:"cpuPools,omitempty"` // reserved cpu pools, ordered by name
+}
+
+func (x *AllocationsReply) Reset() {
+	*x = AllocationsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AllocationsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllocationsReply) ProtoMessage() {}
+
+func (x *AllocationsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllocationsReply.ProtoReflect.Descriptor instead.
+func (*AllocationsReply) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *AllocationsReply) GetPods() []*PodAllocationReply {
+	if x != nil {
+		return x.Pods
+	}
+	return nil
+}
+
+func (x *AllocationsReply) GetAvailableCpus() []*CPUSet {
+	if x != nil {
+		return x.AvailableCpus
+	}
+	return nil
+}
+
+func (x *AllocationsReply) GetTopology() *TopologySummary {
+	if x != nil {
+		return x.Topology
+	}
+	return nil
+}
+
+func (x *AllocationsReply) GetCpuPools() []*CPUPoolReply {
+	if x != nil {
+		return x.CpuPools
+	}
+	return nil
+}
+
+type ContainerAllocationReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PodId         string                   `protobuf:"bytes,1,opt,name=podId,proto3" json:"podId,omitempty"`
+	ContainerName string                   `protobuf:"bytes,2,opt,name=containerName,proto3" json:"containerName,omitempty"`
+	Allocation    *ContainerAllocationInfo `protobuf:"bytes,3,opt,name=allocation,proto3" json:"allocation,omitempty"` // allocation of the container, containerId is its runtime id
+}
+
+func (x *ContainerAllocationReply) Reset() {
+	*x = ContainerAllocationReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ContainerAllocationReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerAllocationReply) ProtoMessage() {}
+
+func (x *ContainerAllocationReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerAllocationReply.ProtoReflect.Descriptor instead.
+func (*ContainerAllocationReply) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ContainerAllocationReply) GetPodId() string {
+	if x != nil {
+		return x.PodId
+	}
+	return ""
+}
+
+func (x *ContainerAllocationReply) GetContainerName() string {
+	if x != nil {
+		return x.ContainerName
+	}
+	return ""
+}
+
+func (x *ContainerAllocationReply) GetAllocation() *ContainerAllocationInfo {
+	if x != nil {
+		return x.Allocation
+	}
+	return nil
+}
+
+type ContainerMigrationInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PodId         string `protobuf:"bytes,1,opt,name=podId,proto3" json:"podId,omitempty"`
+	ContainerName string `protobuf:"bytes,2,opt,name=containerName,proto3" json:"containerName,omitempty"`
+	ContainerId   string `protobuf:"bytes,3,opt,name=containerId,proto3" json:"containerId,omitempty"`
+	Cpus          int32  `protobuf:"varint,4,opt,name=cpus,proto3" json:"cpus,omitempty"`             // number of cpus of the container
+	SourceNode    int32  `protobuf:"varint,5,opt,name=sourceNode,proto3" json:"sourceNode,omitempty"` // numa node the container leaves
+	TargetNode    int32  `protobuf:"varint,6,opt,name=targetNode,proto3" json:"targetNode,omitempty"` // numa node the container is moved to
+}
+
+func (x *ContainerMigrationInfo) Reset() {
+	*x = ContainerMigrationInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ContainerMigrationInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerMigrationInfo) ProtoMessage() {}
+
+func (x *ContainerMigrationInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerMigrationInfo.ProtoReflect.Descriptor instead.
+func (*ContainerMigrationInfo) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ContainerMigrationInfo) GetPodId() string {
+	if x != nil {
+		return x.PodId
+	}
+	return ""
+}
+
+func (x *ContainerMigrationInfo) GetContainerName() string {
+	if x != nil {
+		return x.ContainerName
+	}
+	return ""
+}
+
+func (x *ContainerMigrationInfo) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *ContainerMigrationInfo) GetCpus() int32 {
+	if x != nil {
+		return x.Cpus
+	}
+	return 0
+}
+
+func (x *ContainerMigrationInfo) GetSourceNode() int32 {
+	if x != nil {
+		return x.SourceNode
+	}
+	return 0
+}
+
+func (x *ContainerMigrationInfo) GetTargetNode() int32 {
+	if x != nil {
+		return x.TargetNode
+	}
+	return 0
+}
+
+type DefragmentationPlanReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Node       int32                     `protobuf:"varint,1,opt,name=node,proto3" json:"node,omitempty"`            // numa node fitting requested cpus after the migrations
+	Migrations []*ContainerMigrationInfo `protobuf:"bytes,2,rep,name=migrations,proto3" json:"migrations,omitempty"` // in order of execution, empty if the cpus already fit
+	Executed   bool                      `protobuf:"varint,3,opt,name=executed,proto3" json:"executed,omitempty"`    // migrations were executed
+}
+
+func (x *DefragmentationPlanReply) Reset() {
+	*x = DefragmentationPlanReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DefragmentationPlanReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DefragmentationPlanReply) ProtoMessage() {}
+
+func (x *DefragmentationPlanReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DefragmentationPlanReply.ProtoReflect.Descriptor instead.
+func (*DefragmentationPlanReply) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *DefragmentationPlanReply) GetNode() int32 {
+	if x != nil {
+		return x.Node
+	}
+	return 0
+}
+
+func (x *DefragmentationPlanReply) GetMigrations() []*ContainerMigrationInfo {
+	if x != nil {
+		return x.Migrations
+	}
+	return nil
+}
+
+func (x *DefragmentationPlanReply) GetExecuted() bool {
+	if x != nil {
+		return x.Executed
+	}
+	return false
+}
+
+type NamespaceBucketReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Namespace string    `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Bucket    int32     `protobuf:"varint,2,opt,name=bucket,proto3" json:"bucket,omitempty"`       // index of the bucket, buckets may be shared by namespaces
+	CpuSet    []*CPUSet `protobuf:"bytes,3,rep,name=cpuSet,proto3" json:"cpuSet,omitempty"`        // all cpus of the bucket
+	CpuWeight uint32    `protobuf:"varint,4,opt,name=cpuWeight,proto3" json:"cpuWeight,omitempty"` // cpu weight per requested cpu, 0 if default
+	Released  bool      `protobuf:"varint,5,opt,name=released,proto3" json:"released,omitempty"`   // set by DeleteNamespaceBucket if the bucket is released, false if still in use
+}
+
+func (x *NamespaceBucketReply) Reset() {
+	*x = NamespaceBucketReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NamespaceBucketReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NamespaceBucketReply) ProtoMessage() {}
+
+func (x *NamespaceBucketReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NamespaceBucketReply.ProtoReflect.Descriptor instead.
+func (*NamespaceBucketReply) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *NamespaceBucketReply) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *NamespaceBucketReply) GetBucket() int32 {
+	if x != nil {
+		return x.Bucket
+	}
+	return 0
+}
+
+func (x *NamespaceBucketReply) GetCpuSet() []*CPUSet {
+	if x != nil {
+		return x.CpuSet
+	}
+	return nil
+}
+
+func (x *NamespaceBucketReply) GetCpuWeight() uint32 {
+	if x != nil {
+		return x.CpuWeight
+	}
+	return 0
+}
+
+func (x *NamespaceBucketReply) GetReleased() bool {
+	if x != nil {
+		return x.Released
+	}
+	return false
+}
+
+type CPUPoolReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name     string    `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	NumaNode int32     `protobuf:"varint,2,opt,name=numaNode,proto3" json:"numaNode,omitempty"` // numa node the cpus of the pool belong to
+	CpuSet   []*CPUSet `protobuf:"bytes,3,rep,name=cpuSet,proto3" json:"cpuSet,omitempty"`      // cpus of the pool
+	Released bool      `protobuf:"varint,4,opt,name=released,proto3" json:"released,omitempty"` // set by ReleaseCPUPool
+}
+
+func (x *CPUPoolReply) Reset() {
+	*x = CPUPoolReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CPUPoolReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CPUPoolReply) ProtoMessage() {}
+
+func (x *CPUPoolReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CPUPoolReply.ProtoReflect.Descriptor instead.
+func (*CPUPoolReply) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *CPUPoolReply) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CPUPoolReply) GetNumaNode() int32 {
+	if x != nil {
+		return x.NumaNode
+	}
+	return 0
+}
+
+func (x *CPUPoolReply) GetCpuSet() []*CPUSet {
+	if x != nil {
+		return x.CpuSet
+	}
+	return nil
+}
+
+func (x *CPUPoolReply) GetReleased() bool {
+	if x != nil {
+		return x.Released
+	}
+	return false
+}
+
+type NamespaceConfigReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Namespace  string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Allocator  string `protobuf:"bytes,2,opt,name=allocator,proto3" json:"allocator,omitempty"`
+	Exclusive  bool   `protobuf:"varint,3,opt,name=exclusive,proto3" json:"exclusive,omitempty"`
+	BucketCpus uint32 `protobuf:"varint,4,opt,name=bucketCpus,proto3" json:"bucketCpus,omitempty"`
+	Deleted    bool   `protobuf:"varint,5,opt,name=deleted,proto3" json:"deleted,omitempty"` // set by DeleteNamespaceConfig
+}
+
+func (x *NamespaceConfigReply) Reset() {
+	*x = NamespaceConfigReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NamespaceConfigReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NamespaceConfigReply) ProtoMessage() {}
+
+func (x *NamespaceConfigReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NamespaceConfigReply.ProtoReflect.Descriptor instead.
+func (*NamespaceConfigReply) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *NamespaceConfigReply) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *NamespaceConfigReply) GetAllocator() string {
+	if x != nil {
+		return x.Allocator
+	}
+	return ""
+}
+
+func (x *NamespaceConfigReply) GetExclusive() bool {
+	if x != nil {
+		return x.Exclusive
+	}
+	return false
+}
+
+func (x *NamespaceConfigReply) GetBucketCpus() uint32 {
+	if x != nil {
+		return x.BucketCpus
+	}
+	return 0
+}
+
+func (x *NamespaceConfigReply) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+type NamespaceUsageReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Namespace           string  `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	PinnedCpuSeconds    float64 `protobuf:"fixed64,2,opt,name=pinnedCpuSeconds,proto3" json:"pinnedCpuSeconds,omitempty"`       // exclusive cpus allocated to guaranteed containers times allocation duration
+	RequestedCpuSeconds float64 `protobuf:"fixed64,3,opt,name=requestedCpuSeconds,proto3" json:"requestedCpuSeconds,omitempty"` // cpus requested by the containers times allocation duration
+	PinnedCpus          int32   `protobuf:"varint,4,opt,name=pinnedCpus,proto3" json:"pinnedCpus,omitempty"`                    // exclusive cpus allocated to guaranteed containers of the namespace now
+	RequestedCpus       int32   `protobuf:"varint,5,opt,name=requestedCpus,proto3" json:"requestedCpus,omitempty"`              // cpus requested by the containers now
+}
+
+func (x *NamespaceUsageReply) Reset() {
+	*x = NamespaceUsageReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NamespaceUsageReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NamespaceUsageReply) ProtoMessage() {}
+
+func (x *NamespaceUsageReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NamespaceUsageReply.ProtoReflect.Descriptor instead.
+func (*NamespaceUsageReply) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *NamespaceUsageReply) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *NamespaceUsageReply) GetPinnedCpuSeconds() float64 {
+	if x != nil {
+		return x.PinnedCpuSeconds
+	}
+	return 0
+}
+
+func (x *NamespaceUsageReply) GetRequestedCpuSeconds() float64 {
+	if x != nil {
+		return x.RequestedCpuSeconds
+	}
+	return 0
+}
+
+func (x *NamespaceUsageReply) GetPinnedCpus() int32 {
+	if x != nil {
+		return x.PinnedCpus
+	}
+	return 0
+}
+
+func (x *NamespaceUsageReply) GetRequestedCpus() int32 {
+	if x != nil {
+		return x.RequestedCpus
+	}
+	return 0
+}
+
+type ListUsageReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Namespaces []*NamespaceUsageReply `protobuf:"bytes,1,rep,name=namespaces,proto3" json:"namespaces,omitempty"` // sorted by namespace
+}
+
+func (x *ListUsageReply) Reset() {
+	*x = ListUsageReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListUsageReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsageReply) ProtoMessage() {}
+
+func (x *ListUsageReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsageReply.ProtoReflect.Descriptor instead.
+func (*ListUsageReply) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ListUsageReply) GetNamespaces() []*NamespaceUsageReply {
+	if x != nil {
+		return x.Namespaces
+	}
+	return nil
+}
+
+type BuildInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Version   string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`     // release version or git describe output, "dev" if not set at build time
+	Commit    string `protobuf:"bytes,2,opt,name=commit,proto3" json:"commit,omitempty"`       // git commit the daemon was built from
+	BuildDate string `protobuf:"bytes,3,opt,name=buildDate,proto3" json:"buildDate,omitempty"` // build time in RFC 3339 format
+	GoVersion string `protobuf:"bytes,4,opt,name=goVersion,proto3" json:"goVersion,omitempty"` // version of go toolchain the daemon was built with
+}
+
+func (x *BuildInfo) Reset() {
+	*x = BuildInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BuildInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuildInfo) ProtoMessage() {}
+
+func (x *BuildInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuildInfo.ProtoReflect.Descriptor instead.
+func (*BuildInfo) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *BuildInfo) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *BuildInfo) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *BuildInfo) GetBuildDate() string {
+	if x != nil {
+		return x.BuildDate
+	}
+	return ""
+}
+
+func (x *BuildInfo) GetGoVersion() string {
+	if x != nil {
+		return x.GoVersion
+	}
+	return ""
+}
+
+type PolicyTier struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name       string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Policy     string   `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"` // name of the policy managing pods of the tier, eg. static or observe
+	Namespaces []string `protobuf:"bytes,3,rep,name=namespaces,proto3" json:"namespaces,omitempty"`
+}
+
+func (x *PolicyTier) Reset() {
+	*x = PolicyTier{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[40]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PolicyTier) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PolicyTier) ProtoMessage() {}
+
+func (x *PolicyTier) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[40]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PolicyTier.ProtoReflect.Descriptor instead.
+func (*PolicyTier) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *PolicyTier) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PolicyTier) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *PolicyTier) GetNamespaces() []string {
+	if x != nil {
+		return x.Namespaces
+	}
+	return nil
+}
+
+type DaemonInfoReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CgroupVersion CgroupVersion `protobuf:"varint,1,opt,name=cgroupVersion,proto3,enum=ctlplaneapi.CgroupVersion" json:"cgroupVersion,omitempty"`
+	Build         *BuildInfo    `protobuf:"bytes,2,opt,name=build,proto3" json:"build,omitempty"`             // build of the running daemon
+	PolicyTiers   []*PolicyTier `protobuf:"bytes,3,rep,name=policyTiers,proto3" json:"policyTiers,omitempty"` // empty if all pods are managed by the default policy
+}
+
+func (x *DaemonInfoReply) Reset() {
+	*x = DaemonInfoReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[41]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DaemonInfoReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DaemonInfoReply) ProtoMessage() {}
+
+func (x *DaemonInfoReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[41]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DaemonInfoReply.ProtoReflect.Descriptor instead.
+func (*DaemonInfoReply) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *DaemonInfoReply) GetCgroupVersion() CgroupVersion {
+	if x != nil {
+		return x.CgroupVersion
+	}
+	return CgroupVersion_CGROUP_VERSION_UNKNOWN
+}
+
+func (x *DaemonInfoReply) GetBuild() *BuildInfo {
+	if x != nil {
+		return x.Build
+	}
+	return nil
+}
+
+func (x *DaemonInfoReply) GetPolicyTiers() []*PolicyTier {
+	if x != nil {
+		return x.PolicyTiers
+	}
+	return nil
+}
+
+type CPUBucketConfigInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Bucket     int32     `protobuf:"varint,1,opt,name=bucket,proto3" json:"bucket,omitempty"`        // index of the bucket
+	CpuSet     []*CPUSet `protobuf:"bytes,2,rep,name=cpuSet,proto3" json:"cpuSet,omitempty"`         // all cpus of the bucket
+	Namespaces []string  `protobuf:"bytes,3,rep,name=namespaces,proto3" json:"namespaces,omitempty"` // namespaces assigned to the bucket, sorted
+}
+
+func (x *CPUBucketConfigInfo) Reset() {
+	*x = CPUBucketConfigInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[42]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CPUBucketConfigInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CPUBucketConfigInfo) ProtoMessage() {}
+
+func (x *CPUBucketConfigInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[42]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CPUBucketConfigInfo.ProtoReflect.Descriptor instead.
+func (*CPUBucketConfigInfo) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *CPUBucketConfigInfo) GetBucket() int32 {
+	if x != nil {
+		return x.Bucket
+	}
+	return 0
+}
+
+func (x *CPUBucketConfigInfo) GetCpuSet() []*CPUSet {
+	if x != nil {
+		return x.CpuSet
+	}
+	return nil
+}
+
+func (x *CPUBucketConfigInfo) GetNamespaces() []string {
+	if x != nil {
+		return x.Namespaces
+	}
+	return nil
+}
+
+type AllocatorConfigInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name                 string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                                                                                                                         // allocator selected with -allocator, eg. numa-namespace
+	Exclusive            bool                   `protobuf:"varint,2,opt,name=exclusive,proto3" json:"exclusive,omitempty"`                                                                                                              // cpus of guaranteed containers are not shared with other containers
+	MemoryPinning        bool                   `protobuf:"varint,3,opt,name=memoryPinning,proto3" json:"memoryPinning,omitempty"`                                                                                                      // memory pinned to numa nodes of container cpus, unless pods override it
+	BurstableSoftPinning bool                   `protobuf:"varint,4,opt,name=burstableSoftPinning,proto3" json:"burstableSoftPinning,omitempty"`                                                                                        // burstable containers pinned to cpus sized to their request
+	BucketWeights        bool                   `protobuf:"varint,5,opt,name=bucketWeights,proto3" json:"bucketWeights,omitempty"`                                                                                                      // cpu weight of shared containers proportional to their request
+	Buckets              []*CPUBucketConfigInfo `protobuf:"bytes,6,rep,name=buckets,proto3" json:"buckets,omitempty"`                                                                                                                   // namespace cpu buckets, empty if the allocator has none
+	NamespaceMemoryNodes map[string]string      `protobuf:"bytes,7,rep,name=namespaceMemoryNodes,proto3" json:"namespaceMemoryNodes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"` // memory nodes configured for namespaces
+	FullCores            bool                   `protobuf:"varint,8,opt,name=fullCores,proto3" json:"fullCores,omitempty"`                                                                                                              // cpus of guaranteed containers taken as whole physical cores
+}
+
+func (x *AllocatorConfigInfo) Reset() {
+	*x = AllocatorConfigInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[43]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AllocatorConfigInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllocatorConfigInfo) ProtoMessage() {}
+
+func (x *AllocatorConfigInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[43]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllocatorConfigInfo.ProtoReflect.Descriptor instead.
+func (*AllocatorConfigInfo) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *AllocatorConfigInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AllocatorConfigInfo) GetExclusive() bool {
+	if x != nil {
+		return x.Exclusive
+	}
+	return false
+}
+
+func (x *AllocatorConfigInfo) GetMemoryPinning() bool {
+	if x != nil {
+		return x.MemoryPinning
+	}
+	return false
+}
+
+func (x *AllocatorConfigInfo) GetBurstableSoftPinning() bool {
+	if x != nil {
+		return x.BurstableSoftPinning
+	}
+	return false
+}
+
+func (x *AllocatorConfigInfo) GetBucketWeights() bool {
+	if x != nil {
+		return x.BucketWeights
+	}
+	return false
+}
+
+func (x *AllocatorConfigInfo) GetBuckets() []*CPUBucketConfigInfo {
+	if x != nil {
+		return x.Buckets
+	}
+	return nil
+}
+
+func (x *AllocatorConfigInfo) GetNamespaceMemoryNodes() map[string]string {
+	if x != nil {
+		return x.NamespaceMemoryNodes
+	}
+	return nil
+}
+
+func (x *AllocatorConfigInfo) GetFullCores() bool {
+	if x != nil {
+		return x.FullCores
+	}
+	return false
+}
+
+type ConfigReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Allocator    *AllocatorConfigInfo `protobuf:"bytes,1,opt,name=allocator,proto3" json:"allocator,omitempty"`       // not set if the policy does not report its allocator
+	ReservedCpus []*CPUSet            `protobuf:"bytes,2,rep,name=reservedCpus,proto3" json:"reservedCpus,omitempty"` // cpus outside of kubepods cgroup, never allocated
+	ExcludedCpus []*CPUSet            `protobuf:"bytes,3,rep,name=excludedCpus,proto3" json:"excludedCpus,omitempty"` // cpus removed from daemon management
+	ManagedCpus  []*CPUSet            `protobuf:"bytes,4,rep,name=managedCpus,proto3" json:"managedCpus,omitempty"`   // cpus managed by this daemon instance, empty if all
+	KubeletCpus  []*CPUSet            `protobuf:"bytes,5,rep,name=kubeletCpus,proto3" json:"kubeletCpus,omitempty"`   // cpus exclusively assigned by kubelet cpu manager
+}
+
+func (x *ConfigReply) Reset() {
+	*x = ConfigReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[44]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConfigReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigReply) ProtoMessage() {}
+
+func (x *ConfigReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_ctlplaneapi_controlplane_proto_msgTypes[44]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigReply.ProtoReflect.Descriptor instead.
+func (*ConfigReply) Descriptor() ([]byte, []int) {
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *ConfigReply) GetAllocator() *AllocatorConfigInfo {
+	if x != nil {
+		return x.Allocator
+	}
+	return nil
+}
+
+func (x *ConfigReply) GetReservedCpus() []*CPUSet {
+	if x != nil {
+		return x.ReservedCpus
+	}
+	return nil
+}
+
+func (x *ConfigReply) GetExcludedCpus() []*CPUSet {
+	if x != nil {
+		return x.ExcludedCpus
+	}
+	return nil
+}
+
+func (x *ConfigReply) GetManagedCpus() []*CPUSet {
+	if x != nil {
+		return x.ManagedCpus
+	}
+	return nil
+}
+
+func (x *ConfigReply) GetKubeletCpus() []*CPUSet {
+	if x != nil {
+		return x.KubeletCpus
+	}
+	return nil
+}
+
+var File_pkg_ctlplaneapi_controlplane_proto protoreflect.FileDescriptor
+
+var file_pkg_ctlplaneapi_controlplane_proto_rawDesc = []byte{
+	0x0a, 0x22, 0x70, 0x6b, 0x67, 0x2f, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70,
+	0x69, 0x2f, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70,
+	0x69, 0x22, 0xb7, 0x05, 0x0a, 0x10, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07,
+	0x70, 0x6f, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70,
+	0x6f, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x70, 0x6f, 0x64, 0x4e, 0x61, 0x6d,
+	0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x6f,
+	0x64, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x37, 0x0a, 0x09, 0x72, 0x65,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x09, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x73, 0x12, 0x3a, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72,
+	0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61,
+	0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x49,
+	0x6e, 0x66, 0x6f, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x73, 0x12,
+	0x40, 0x0a, 0x0d, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x50, 0x69, 0x6e, 0x6e, 0x69, 0x6e, 0x67,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e,
+	0x65, 0x61, 0x70, 0x69, 0x2e, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x50, 0x69, 0x6e, 0x6e, 0x69,
+	0x6e, 0x67, 0x52, 0x0d, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x50, 0x69, 0x6e, 0x6e, 0x69, 0x6e,
+	0x67, 0x12, 0x34, 0x0a, 0x15, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x76, 0x65, 0x4c, 0x65,
+	0x61, 0x73, 0x65, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x15, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x76, 0x65, 0x4c, 0x65, 0x61, 0x73, 0x65,
+	0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x41, 0x0a, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c,
+	0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61,
+	0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x50, 0x0a, 0x0b, 0x61, 0x6e,
+	0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x2e, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x41,
+	0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x0b, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1e, 0x0a, 0x0a,
+	0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08,
+	0x6e, 0x6f, 0x64, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x6e, 0x6f, 0x64, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x72, 0x79, 0x52,
+	0x75, 0x6e, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x72, 0x79, 0x52, 0x75, 0x6e,
+	0x1a, 0x39, 0x0a, 0x0b, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x3e, 0x0a, 0x10, 0x41,
+	0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x46, 0x0a, 0x11, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x31, 0x0a, 0x04, 0x70, 0x6f, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d,
+	0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x04, 0x70,
+	0x6f, 0x64, 0x73, 0x22, 0x93, 0x05, 0x0a, 0x10, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f,
+	0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x6f, 0x64, 0x49,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x37,
+	0x0a, 0x09, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e,
+	0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x09, 0x72, 0x65,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x12, 0x3a, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x61,
+	0x69, 0x6e, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x63, 0x74,
+	0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69,
+	0x6e, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e,
+	0x65, 0x72, 0x73, 0x12, 0x34, 0x0a, 0x15, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x76, 0x65,
+	0x4c, 0x65, 0x61, 0x73, 0x65, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x15, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x76, 0x65, 0x4c, 0x65, 0x61,
+	0x73, 0x65, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x41, 0x0a, 0x06, 0x6c, 0x61, 0x62,
+	0x65, 0x6c, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x63, 0x74, 0x6c, 0x70,
+	0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f,
+	0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x50, 0x0a, 0x0b,
+	0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x2e, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x2e, 0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x0b, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1e,
+	0x0a, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a,
+	0x0a, 0x08, 0x6e, 0x6f, 0x64, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x6e, 0x6f, 0x64, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x72,
+	0x79, 0x52, 0x75, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x72, 0x79, 0x52,
+	0x75, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x07, 0x70, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x12, 0x40, 0x0a, 0x0d,
+	0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x50, 0x69, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x0b, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70,
+	0x69, 0x2e, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x50, 0x69, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x52,
+	0x0d, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x50, 0x69, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x1a, 0x39,
+	0x0a, 0x0b, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x3e, 0x0a, 0x10, 0x41, 0x6e, 0x6e,
+	0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x44, 0x0a, 0x10, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a,
+	0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f,
+	0x64, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x6e, 0x6f, 0x64, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6e, 0x6f, 0x64, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x22,
+	0x25, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x14, 0x0a, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x22, 0x11, 0x0a, 0x0f, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x6f,
+	0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x51, 0x0a, 0x13, 0x47, 0x65, 0x74,
+	0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x14, 0x0a, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x24, 0x0a, 0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69,
+	0x6e, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63,
+	0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x74, 0x0a, 0x1c,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x42,
+	0x75, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09,
+	0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x69,
+	0x6e, 0x43, 0x70, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x6d, 0x69, 0x6e,
+	0x43, 0x70, 0x75, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x70, 0x75, 0x57, 0x65, 0x69, 0x67, 0x68,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x63, 0x70, 0x75, 0x57, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x22, 0x3c, 0x0a, 0x1c, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65,
+	0x73, 0x70, 0x61, 0x63, 0x65, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65,
+	0x22, 0x16, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x44, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x49, 0x6e, 0x66,
+	0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x12, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x17, 0x0a, 0x15,
+	0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x5b, 0x0a, 0x17, 0x4d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x65,
+	0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x49, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72,
+	0x49, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x4e, 0x6f, 0x64, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x4e, 0x6f,
+	0x64, 0x65, 0x22, 0x5d, 0x0a, 0x15, 0x52, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x43, 0x50, 0x55,
+	0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x63, 0x70, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x63,
+	0x70, 0x75, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x75, 0x6d, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x09, 0x6e, 0x75, 0x6d, 0x61, 0x4e, 0x6f, 0x64, 0x65,
+	0x73, 0x22, 0x2b, 0x0a, 0x15, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x43, 0x50, 0x55, 0x50,
+	0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x95,
+	0x01, 0x0a, 0x19, 0x53, 0x65, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09,
+	0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x6c,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61,
+	0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x65, 0x78, 0x63, 0x6c,
+	0x75, 0x73, 0x69, 0x76, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x65, 0x78, 0x63,
+	0x6c, 0x75, 0x73, 0x69, 0x76, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74,
+	0x43, 0x70, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x62, 0x75, 0x63, 0x6b,
+	0x65, 0x74, 0x43, 0x70, 0x75, 0x73, 0x22, 0x3c, 0x0a, 0x1c, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73,
+	0x70, 0x61, 0x63, 0x65, 0x22, 0x30, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x73, 0x61, 0x67,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65,
+	0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d,
+	0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x22, 0x4a, 0x0a, 0x1a, 0x50, 0x6c, 0x61, 0x6e, 0x44, 0x65,
+	0x66, 0x72, 0x61, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x70, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x04, 0x63, 0x70, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x78, 0x65, 0x63,
+	0x75, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x78, 0x65, 0x63, 0x75,
+	0x74, 0x65, 0x22, 0xe4, 0x01, 0x0a, 0x0c, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49,
+	0x6e, 0x66, 0x6f, 0x12, 0x24, 0x0a, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64,
+	0x43, 0x70, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x65, 0x64, 0x43, 0x70, 0x75, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x69, 0x6d,
+	0x69, 0x74, 0x43, 0x70, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x43, 0x70, 0x75, 0x73, 0x12, 0x28, 0x0a, 0x0f, 0x72, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x65, 0x64, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x0f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x4d, 0x65, 0x6d, 0x6f, 0x72,
+	0x79, 0x12, 0x20, 0x0a, 0x0b, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x4d, 0x65, 0x6d,
+	0x6f, 0x72, 0x79, 0x12, 0x38, 0x0a, 0x0b, 0x63, 0x70, 0x75, 0x41, 0x66, 0x66, 0x69, 0x6e, 0x69,
+	0x74, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c,
+	0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74,
+	0x52, 0x0b, 0x63, 0x70, 0x75, 0x41, 0x66, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x79, 0x4a, 0x04, 0x08,
+	0x04, 0x10, 0x05, 0x4a, 0x04, 0x08, 0x06, 0x10, 0x07, 0x22, 0x90, 0x01, 0x0a, 0x0d, 0x43, 0x6f,
+	0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x20, 0x0a, 0x0b, 0x63,
+	0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x49, 0x64, 0x12, 0x24, 0x0a,
+	0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x4e,
+	0x61, 0x6d, 0x65, 0x12, 0x37, 0x0a, 0x09, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e,
+	0x65, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x6e, 0x66,
+	0x6f, 0x52, 0x09, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x22, 0x85, 0x04, 0x0a,
+	0x17, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74,
+	0x61, 0x69, 0x6e, 0x65, 0x72, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63,
+	0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x49, 0x64, 0x12, 0x3c, 0x0a, 0x0a, 0x61, 0x6c,
+	0x6c, 0x6f, 0x63, 0x53, 0x74, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c,
+	0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x41, 0x6c, 0x6c,
+	0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x0a, 0x61, 0x6c,
+	0x6c, 0x6f, 0x63, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x2b, 0x0a, 0x06, 0x63, 0x70, 0x75, 0x53,
+	0x65, 0x74, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c,
+	0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x50, 0x55, 0x53, 0x65, 0x74, 0x52, 0x06, 0x63,
+	0x70, 0x75, 0x53, 0x65, 0x74, 0x12, 0x27, 0x0a, 0x03, 0x71, 0x6f, 0x73, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x15, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69,
+	0x2e, 0x51, 0x6f, 0x53, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x52, 0x03, 0x71, 0x6f, 0x73, 0x12, 0x20,
+	0x0a, 0x0b, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0b, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4e, 0x6f, 0x64, 0x65, 0x73,
+	0x12, 0x1c, 0x0a, 0x09, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x76, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x09, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x76, 0x65, 0x12, 0x30,
+	0x0a, 0x13, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x13, 0x61, 0x6c, 0x6c,
+	0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x12, 0x32, 0x0a, 0x14, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x67,
+	0x65, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x14,
+	0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x67, 0x65, 0x53, 0x65, 0x63,
+	0x6f, 0x6e, 0x64, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x50, 0x61,
+	0x74, 0x68, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70,
+	0x50, 0x61, 0x74, 0x68, 0x12, 0x22, 0x0a, 0x0c, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x64,
+	0x50, 0x6f, 0x6f, 0x6c, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x72, 0x65, 0x6c, 0x65,
+	0x61, 0x73, 0x65, 0x64, 0x50, 0x6f, 0x6f, 0x6c, 0x12, 0x20, 0x0a, 0x0b, 0x65, 0x78, 0x70, 0x6c,
+	0x61, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x65,
+	0x78, 0x70, 0x6c, 0x61, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x28, 0x0a, 0x0f, 0x65, 0x66,
+	0x66, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x43, 0x70, 0x75, 0x53, 0x65, 0x74, 0x18, 0x0c, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0f, 0x65, 0x66, 0x66, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x43, 0x70,
+	0x75, 0x53, 0x65, 0x74, 0x22, 0x3c, 0x0a, 0x06, 0x43, 0x50, 0x55, 0x53, 0x65, 0x74, 0x12, 0x1a,
+	0x0a, 0x08, 0x73, 0x74, 0x61, 0x72, 0x74, 0x43, 0x50, 0x55, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x08, 0x73, 0x74, 0x61, 0x72, 0x74, 0x43, 0x50, 0x55, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x6e,
+	0x64, 0x43, 0x50, 0x55, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x65, 0x6e, 0x64, 0x43,
+	0x50, 0x55, 0x22, 0x85, 0x01, 0x0a, 0x13, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f,
+	0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x49, 0x64, 0x12, 0x34, 0x0a, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x63,
+	0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x61,
+	0x69, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0xa1, 0x03, 0x0a, 0x12, 0x50,
+	0x6f, 0x64, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x3c, 0x0a, 0x0a, 0x61, 0x6c, 0x6c, 0x6f, 0x63,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x63, 0x74,
+	0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x0a, 0x61, 0x6c, 0x6c, 0x6f, 0x63,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x2b, 0x0a, 0x06, 0x63, 0x70, 0x75, 0x53, 0x65, 0x74, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65,
+	0x61, 0x70, 0x69, 0x2e, 0x43, 0x50, 0x55, 0x53, 0x65, 0x74, 0x52, 0x06, 0x63, 0x70, 0x75, 0x53,
+	0x65, 0x74, 0x12, 0x5a, 0x0a, 0x15, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x73,
+	0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x24, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e,
+	0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x15, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e,
+	0x65, 0x72, 0x73, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1c,
+	0x0a, 0x09, 0x75, 0x6e, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x09, 0x75, 0x6e, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x64, 0x12, 0x28, 0x0a, 0x0f,
+	0x75, 0x6e, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x64, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x75, 0x6e, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x64,
+	0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x72, 0x79, 0x52, 0x75, 0x6e,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x12, 0x4e,
+	0x0a, 0x11, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x63, 0x74, 0x6c, 0x70,
+	0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65,
+	0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x11, 0x63, 0x6f, 0x6e,
+	0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x22, 0xaf,
+	0x02, 0x0a, 0x0f, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x35, 0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c,
+	0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61,
+	0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x6f, 0x64, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x52, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x20, 0x0a, 0x0b, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x55, 0x0a, 0x0d, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2f,
+	0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x2e, 0x45, 0x72, 0x72,
+	0x6f, 0x72, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x0d, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x1a, 0x40,
+	0x0a, 0x12, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x22, 0x49, 0x0a, 0x0f, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x73, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x36, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61,
+	0x70, 0x69, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x22, 0x44, 0x0a, 0x0d, 0x4c,
+	0x69, 0x73, 0x74, 0x50, 0x6f, 0x64, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x33, 0x0a, 0x04,
+	0x70, 0x6f, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x63, 0x74, 0x6c,
+	0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x6f, 0x64, 0x41, 0x6c, 0x6c, 0x6f,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x52, 0x04, 0x70, 0x6f, 0x64,
+	0x73, 0x22, 0x8a, 0x01, 0x0a, 0x0c, 0x4e, 0x75, 0x6d, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x49, 0x6e,
+	0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x2b, 0x0a, 0x06, 0x63, 0x70, 0x75, 0x53, 0x65, 0x74,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e,
+	0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x50, 0x55, 0x53, 0x65, 0x74, 0x52, 0x06, 0x63, 0x70, 0x75,
+	0x53, 0x65, 0x74, 0x12, 0x39, 0x0a, 0x0d, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65,
+	0x43, 0x70, 0x75, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x74, 0x6c,
+	0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x50, 0x55, 0x53, 0x65, 0x74, 0x52,
+	0x0d, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x43, 0x70, 0x75, 0x73, 0x22, 0x86,
+	0x01, 0x0a, 0x0f, 0x54, 0x6f, 0x70, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x53, 0x75, 0x6d, 0x6d, 0x61,
+	0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x70, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x04, 0x63, 0x70, 0x75, 0x73, 0x12, 0x26, 0x0a, 0x0e, 0x74, 0x68, 0x72, 0x65, 0x61, 0x64,
+	0x73, 0x50, 0x65, 0x72, 0x43, 0x6f, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e,
+	0x74, 0x68, 0x72, 0x65, 0x61, 0x64, 0x73, 0x50, 0x65, 0x72, 0x43, 0x6f, 0x72, 0x65, 0x12, 0x37,
+	0x0a, 0x09, 0x6e, 0x75, 0x6d, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e,
+	0x4e, 0x75, 0x6d, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x09, 0x6e, 0x75,
+	0x6d, 0x61, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0xf3, 0x01, 0x0a, 0x10, 0x41, 0x6c, 0x6c, 0x6f,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x33, 0x0a, 0x04,
+	0x70, 0x6f, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x63, 0x74, 0x6c,
+	0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x6f, 0x64, 0x41, 0x6c, 0x6c, 0x6f,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x52, 0x04, 0x70, 0x6f, 0x64,
+	0x73, 0x12, 0x39, 0x0a, 0x0d, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x43, 0x70,
+	0x75, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c,
+	0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x50, 0x55, 0x53, 0x65, 0x74, 0x52, 0x0d, 0x61,
+	0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x43, 0x70, 0x75, 0x73, 0x12, 0x38, 0x0a, 0x08,
+	0x74, 0x6f, 0x70, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c,
+	0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x54, 0x6f, 0x70,
+	0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x52, 0x08, 0x74, 0x6f,
+	0x70, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x12, 0x35, 0x0a, 0x08, 0x63, 0x70, 0x75, 0x50, 0x6f, 0x6f,
+	0x6c, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c,
+	0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x50, 0x55, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x52, 0x08, 0x63, 0x70, 0x75, 0x50, 0x6f, 0x6f, 0x6c, 0x73, 0x22, 0x9c, 0x01,
+	0x0a, 0x18, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x41, 0x6c, 0x6c, 0x6f, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x6f,
+	0x64, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64,
+	0x12, 0x24, 0x0a, 0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x4e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e,
+	0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x44, 0x0a, 0x0a, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x63, 0x74, 0x6c,
+	0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e,
+	0x65, 0x72, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f,
+	0x52, 0x0a, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xca, 0x01, 0x0a,
+	0x16, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x4d, 0x69, 0x67, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x24, 0x0a,
+	0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x4e,
+	0x61, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72,
+	0x49, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69,
+	0x6e, 0x65, 0x72, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x70, 0x75, 0x73, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x04, 0x63, 0x70, 0x75, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x61, 0x72,
+	0x67, 0x65, 0x74, 0x4e, 0x6f, 0x64, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x74,
+	0x61, 0x72, 0x67, 0x65, 0x74, 0x4e, 0x6f, 0x64, 0x65, 0x22, 0x8f, 0x01, 0x0a, 0x18, 0x44, 0x65,
+	0x66, 0x72, 0x61, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6c, 0x61,
+	0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x43, 0x0a, 0x0a, 0x6d, 0x69,
+	0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23,
+	0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6e,
+	0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x4d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49,
+	0x6e, 0x66, 0x6f, 0x52, 0x0a, 0x6d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12,
+	0x1a, 0x0a, 0x08, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x08, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x64, 0x22, 0xb3, 0x01, 0x0a, 0x14,
+	0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61,
+	0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x06, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x2b, 0x0a, 0x06, 0x63, 0x70,
+	0x75, 0x53, 0x65, 0x74, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x74, 0x6c,
+	0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x50, 0x55, 0x53, 0x65, 0x74, 0x52,
+	0x06, 0x63, 0x70, 0x75, 0x53, 0x65, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x70, 0x75, 0x57, 0x65,
+	0x69, 0x67, 0x68, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x63, 0x70, 0x75, 0x57,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65,
+	0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65,
+	0x64, 0x22, 0x87, 0x01, 0x0a, 0x0c, 0x43, 0x50, 0x55, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6e, 0x75, 0x6d, 0x61, 0x4e, 0x6f,
+	0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6e, 0x75, 0x6d, 0x61, 0x4e, 0x6f,
+	0x64, 0x65, 0x12, 0x2b, 0x0a, 0x06, 0x63, 0x70, 0x75, 0x53, 0x65, 0x74, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69,
+	0x2e, 0x43, 0x50, 0x55, 0x53, 0x65, 0x74, 0x52, 0x06, 0x63, 0x70, 0x75, 0x53, 0x65, 0x74, 0x12,
+	0x1a, 0x0a, 0x08, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x08, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x64, 0x22, 0xaa, 0x01, 0x0a, 0x14,
+	0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61,
+	0x63, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72,
+	0x12, 0x1c, 0x0a, 0x09, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x76, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x09, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x76, 0x65, 0x12, 0x1e,
+	0x0a, 0x0a, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x43, 0x70, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x0a, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x43, 0x70, 0x75, 0x73, 0x12, 0x18,
+	0x0a, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x22, 0xd7, 0x01, 0x0a, 0x13, 0x4e, 0x61, 0x6d,
+	0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x2a,
+	0x0a, 0x10, 0x70, 0x69, 0x6e, 0x6e, 0x65, 0x64, 0x43, 0x70, 0x75, 0x53, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x10, 0x70, 0x69, 0x6e, 0x6e, 0x65, 0x64,
+	0x43, 0x70, 0x75, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x30, 0x0a, 0x13, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x43, 0x70, 0x75, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x13, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x65, 0x64, 0x43, 0x70, 0x75, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x1e, 0x0a, 0x0a,
+	0x70, 0x69, 0x6e, 0x6e, 0x65, 0x64, 0x43, 0x70, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0a, 0x70, 0x69, 0x6e, 0x6e, 0x65, 0x64, 0x43, 0x70, 0x75, 0x73, 0x12, 0x24, 0x0a, 0x0d,
+	0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x43, 0x70, 0x75, 0x73, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x43, 0x70,
+	0x75, 0x73, 0x22, 0x52, 0x0a, 0x0e, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x12, 0x40, 0x0a, 0x0a, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63,
+	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c,
+	0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65,
+	0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x52, 0x0a, 0x6e, 0x61, 0x6d, 0x65,
+	0x73, 0x70, 0x61, 0x63, 0x65, 0x73, 0x22, 0x79, 0x0a, 0x09, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x49,
+	0x6e, 0x66, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a,
+	0x06, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63,
+	0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x44, 0x61,
+	0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x44,
+	0x61, 0x74, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x67, 0x6f, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x67, 0x6f, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x22, 0x58, 0x0a, 0x0a, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x54, 0x69, 0x65, 0x72, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x1e, 0x0a, 0x0a, 0x6e,
+	0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x0a, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x73, 0x22, 0xbc, 0x01, 0x0a, 0x0f,
+	0x44, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
+	0x40, 0x0a, 0x0d, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e,
+	0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x52, 0x0d, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x2c, 0x0a, 0x05, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x16, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x42,
+	0x75, 0x69, 0x6c, 0x64, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x05, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x12,
+	0x39, 0x0a, 0x0b, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x54, 0x69, 0x65, 0x72, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61,
+	0x70, 0x69, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x54, 0x69, 0x65, 0x72, 0x52, 0x0b, 0x70,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x54, 0x69, 0x65, 0x72, 0x73, 0x22, 0x7a, 0x0a, 0x13, 0x43, 0x50,
+	0x55, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x49, 0x6e, 0x66,
+	0x6f, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x06, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x2b, 0x0a, 0x06, 0x63, 0x70, 0x75,
+	0x53, 0x65, 0x74, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x74, 0x6c, 0x70,
+	0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x50, 0x55, 0x53, 0x65, 0x74, 0x52, 0x06,
+	0x63, 0x70, 0x75, 0x53, 0x65, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x61, 0x6d, 0x65,
+	0x73, 0x70, 0x61, 0x63, 0x65, 0x73, 0x22, 0xda, 0x03, 0x0a, 0x13, 0x41, 0x6c, 0x6c, 0x6f, 0x63,
+	0x61, 0x74, 0x6f, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x76, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x76, 0x65,
+	0x12, 0x24, 0x0a, 0x0d, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x50, 0x69, 0x6e, 0x6e, 0x69, 0x6e,
+	0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x50,
+	0x69, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x32, 0x0a, 0x14, 0x62, 0x75, 0x72, 0x73, 0x74, 0x61,
+	0x62, 0x6c, 0x65, 0x53, 0x6f, 0x66, 0x74, 0x50, 0x69, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x14, 0x62, 0x75, 0x72, 0x73, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x53,
+	0x6f, 0x66, 0x74, 0x50, 0x69, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x24, 0x0a, 0x0d, 0x62, 0x75,
+	0x63, 0x6b, 0x65, 0x74, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0d, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73,
+	0x12, 0x3a, 0x0a, 0x07, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x20, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e,
+	0x43, 0x50, 0x55, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x49,
+	0x6e, 0x66, 0x6f, 0x52, 0x07, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x6e, 0x0a, 0x14,
+	0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4e,
+	0x6f, 0x64, 0x65, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x3a, 0x2e, 0x63, 0x74, 0x6c,
+	0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74,
+	0x6f, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x49, 0x6e, 0x66, 0x6f, 0x2e, 0x4e, 0x61, 0x6d,
+	0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4e, 0x6f, 0x64, 0x65,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x14, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63,
+	0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09,
+	0x66, 0x75, 0x6c, 0x6c, 0x43, 0x6f, 0x72, 0x65, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x09, 0x66, 0x75, 0x6c, 0x6c, 0x43, 0x6f, 0x72, 0x65, 0x73, 0x1a, 0x47, 0x0a, 0x19, 0x4e, 0x61,
+	0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4e, 0x6f, 0x64,
+	0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x22, 0xad, 0x02, 0x0a, 0x0b, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x3e, 0x0a, 0x09, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e,
+	0x65, 0x61, 0x70, 0x69, 0x2e, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x09, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x6f, 0x72, 0x12, 0x37, 0x0a, 0x0c, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x43,
+	0x70, 0x75, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x74, 0x6c, 0x70,
+	0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x50, 0x55, 0x53, 0x65, 0x74, 0x52, 0x0c,
+	0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x43, 0x70, 0x75, 0x73, 0x12, 0x37, 0x0a, 0x0c,
+	0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x64, 0x43, 0x70, 0x75, 0x73, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69,
+	0x2e, 0x43, 0x50, 0x55, 0x53, 0x65, 0x74, 0x52, 0x0c, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65,
+	0x64, 0x43, 0x70, 0x75, 0x73, 0x12, 0x35, 0x0a, 0x0b, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x64,
+	0x43, 0x70, 0x75, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x74, 0x6c,
+	0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x50, 0x55, 0x53, 0x65, 0x74, 0x52,
+	0x0b, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x64, 0x43, 0x70, 0x75, 0x73, 0x12, 0x35, 0x0a, 0x0b,
+	0x6b, 0x75, 0x62, 0x65, 0x6c, 0x65, 0x74, 0x43, 0x70, 0x75, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x13, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e,
+	0x43, 0x50, 0x55, 0x53, 0x65, 0x74, 0x52, 0x0b, 0x6b, 0x75, 0x62, 0x65, 0x6c, 0x65, 0x74, 0x43,
+	0x70, 0x75, 0x73, 0x2a, 0x38, 0x0a, 0x0f, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45,
+	0x44, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x44, 0x10, 0x01,
+	0x12, 0x0b, 0x0a, 0x07, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x44, 0x10, 0x02, 0x2a, 0x3c, 0x0a,
+	0x09, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x0b, 0x0a, 0x07, 0x44, 0x45,
+	0x46, 0x41, 0x55, 0x4c, 0x54, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x4f, 0x4d, 0x50, 0x41,
+	0x43, 0x54, 0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07, 0x53, 0x43, 0x41, 0x54, 0x54, 0x45, 0x52, 0x10,
+	0x02, 0x12, 0x08, 0x0a, 0x04, 0x50, 0x4f, 0x4f, 0x4c, 0x10, 0x03, 0x2a, 0x64, 0x0a, 0x0d, 0x4d,
+	0x65, 0x6d, 0x6f, 0x72, 0x79, 0x50, 0x69, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x1a, 0x0a, 0x16,
+	0x4d, 0x45, 0x4d, 0x4f, 0x52, 0x59, 0x5f, 0x50, 0x49, 0x4e, 0x4e, 0x49, 0x4e, 0x47, 0x5f, 0x44,
+	0x45, 0x46, 0x41, 0x55, 0x4c, 0x54, 0x10, 0x00, 0x12, 0x1a, 0x0a, 0x16, 0x4d, 0x45, 0x4d, 0x4f,
+	0x52, 0x59, 0x5f, 0x50, 0x49, 0x4e, 0x4e, 0x49, 0x4e, 0x47, 0x5f, 0x45, 0x4e, 0x41, 0x42, 0x4c,
+	0x45, 0x44, 0x10, 0x01, 0x12, 0x1b, 0x0a, 0x17, 0x4d, 0x45, 0x4d, 0x4f, 0x52, 0x59, 0x5f, 0x50,
+	0x49, 0x4e, 0x4e, 0x49, 0x4e, 0x47, 0x5f, 0x44, 0x49, 0x53, 0x41, 0x42, 0x4c, 0x45, 0x44, 0x10,
+	0x02, 0x2a, 0x49, 0x0a, 0x0d, 0x43, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x16, 0x43, 0x47, 0x52, 0x4f, 0x55, 0x50, 0x5f, 0x56, 0x45, 0x52,
+	0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x0d,
+	0x0a, 0x09, 0x43, 0x47, 0x52, 0x4f, 0x55, 0x50, 0x5f, 0x56, 0x31, 0x10, 0x01, 0x12, 0x0d, 0x0a,
+	0x09, 0x43, 0x47, 0x52, 0x4f, 0x55, 0x50, 0x5f, 0x56, 0x32, 0x10, 0x02, 0x2a, 0x37, 0x0a, 0x0f,
+	0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x0b, 0x0a, 0x07, 0x53, 0x55, 0x43, 0x43, 0x45, 0x53, 0x53, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06,
+	0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07, 0x53, 0x4b, 0x49, 0x50,
+	0x50, 0x45, 0x44, 0x10, 0x02, 0x2a, 0x3a, 0x0a, 0x08, 0x51, 0x6f, 0x53, 0x43, 0x6c, 0x61, 0x73,
+	0x73, 0x12, 0x0e, 0x0a, 0x0a, 0x47, 0x55, 0x41, 0x52, 0x41, 0x4e, 0x54, 0x45, 0x45, 0x44, 0x10,
+	0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x42, 0x45, 0x53, 0x54, 0x5f, 0x45, 0x46, 0x46, 0x4f, 0x52, 0x54,
+	0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x42, 0x55, 0x52, 0x53, 0x54, 0x41, 0x42, 0x4c, 0x45, 0x10,
+	0x02, 0x32, 0x83, 0x0d, 0x0a, 0x0c, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x50, 0x6c, 0x61,
+	0x6e, 0x65, 0x12, 0x4d, 0x0a, 0x09, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x12,
+	0x1d, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f,
+	0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x6f, 0x64,
+	0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22,
+	0x00, 0x12, 0x4d, 0x0a, 0x09, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x12, 0x1d,
+	0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e,
+	0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x6f, 0x64, 0x41,
+	0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00,
+	0x12, 0x4d, 0x0a, 0x09, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x12, 0x1d, 0x2e,
+	0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x63,
+	0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x6f, 0x64, 0x41, 0x6c,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12,
+	0x4c, 0x0a, 0x0a, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x64, 0x73, 0x12, 0x1e, 0x2e,
+	0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x50, 0x6f, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e,
+	0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x50, 0x6f, 0x64, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x47, 0x0a,
+	0x06, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x64, 0x12, 0x1a, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61,
+	0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70,
+	0x69, 0x2e, 0x50, 0x6f, 0x64, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x46, 0x0a, 0x08, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x6f,
+	0x64, 0x73, 0x12, 0x1c, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69,
+	0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x6f, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1a, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x50, 0x6f, 0x64, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x59,
+	0x0a, 0x0c, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x12, 0x20,
+	0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x47, 0x65, 0x74,
+	0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x25, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43,
+	0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x67, 0x0a, 0x15, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x42, 0x75, 0x63, 0x6b,
+	0x65, 0x74, 0x12, 0x29, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69,
+	0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65,
+	0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e,
+	0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x4e, 0x61, 0x6d, 0x65,
+	0x73, 0x70, 0x61, 0x63, 0x65, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x22, 0x00, 0x12, 0x67, 0x0a, 0x15, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65,
+	0x73, 0x70, 0x61, 0x63, 0x65, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x29, 0x2e, 0x63, 0x74,
+	0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e,
+	0x65, 0x61, 0x70, 0x69, 0x2e, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x42, 0x75,
+	0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x52, 0x0a, 0x0d, 0x47,
+	0x65, 0x74, 0x44, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x21, 0x2e, 0x63,
+	0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x61,
+	0x65, 0x6d, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1c, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x61,
+	0x65, 0x6d, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12,
+	0x46, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x1d, 0x2e, 0x63,
+	0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x63, 0x74,
+	0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x61, 0x0a, 0x10, 0x4d, 0x69, 0x67, 0x72, 0x61,
+	0x74, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x12, 0x24, 0x2e, 0x63, 0x74,
+	0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x4d, 0x69, 0x67, 0x72, 0x61, 0x74,
+	0x65, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x25, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e,
+	0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x67, 0x0a, 0x13, 0x50, 0x6c,
+	0x61, 0x6e, 0x44, 0x65, 0x66, 0x72, 0x61, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x27, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e,
+	0x50, 0x6c, 0x61, 0x6e, 0x44, 0x65, 0x66, 0x72, 0x61, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x63, 0x74, 0x6c,
+	0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x65, 0x66, 0x72, 0x61, 0x67, 0x6d,
+	0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6c, 0x61, 0x6e, 0x52, 0x65, 0x70, 0x6c,
+	0x79, 0x22, 0x00, 0x12, 0x55, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x22, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65,
+	0x61, 0x70, 0x69, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x63, 0x74, 0x6c, 0x70,
+	0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x51, 0x0a, 0x0e, 0x52, 0x65,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x43, 0x50, 0x55, 0x50, 0x6f, 0x6f, 0x6c, 0x12, 0x22, 0x2e, 0x63,
+	0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x43, 0x50, 0x55, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x19, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x43,
+	0x50, 0x55, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x51, 0x0a,
+	0x0e, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x43, 0x50, 0x55, 0x50, 0x6f, 0x6f, 0x6c, 0x12,
+	0x22, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65,
+	0x6c, 0x65, 0x61, 0x73, 0x65, 0x43, 0x50, 0x55, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70,
+	0x69, 0x2e, 0x43, 0x50, 0x55, 0x50, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00,
+	0x12, 0x61, 0x0a, 0x12, 0x53, 0x65, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x26, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e,
+	0x65, 0x61, 0x70, 0x69, 0x2e, 0x53, 0x65, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63,
+	0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21,
+	0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x4e, 0x61, 0x6d,
+	0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x70, 0x6c,
+	0x79, 0x22, 0x00, 0x12, 0x67, 0x0a, 0x15, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4e, 0x61, 0x6d,
+	0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x29, 0x2e, 0x63,
+	0x74, 0x6c, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c, 0x61,
+	0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x49, 0x0a, 0x09,
+	0x4c, 0x69, 0x73, 0x74, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1d, 0x2e, 0x63, 0x74, 0x6c, 0x70,
+	0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x73, 0x61, 0x67,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x63, 0x74, 0x6c, 0x70, 0x6c,
+	0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x73, 0x61, 0x67, 0x65,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x0f, 0x5a, 0x0d, 0x2e, 0x2f, 0x63, 0x74, 0x6c,
+	0x70, 0x6c, 0x61, 0x6e, 0x65, 0x61, 0x70, 0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pkg_ctlplaneapi_controlplane_proto_rawDescOnce sync.Once
+	file_pkg_ctlplaneapi_controlplane_proto_rawDescData = file_pkg_ctlplaneapi_controlplane_proto_rawDesc
+)
+
+func file_pkg_ctlplaneapi_controlplane_proto_rawDescGZIP() []byte {
+	file_pkg_ctlplaneapi_controlplane_proto_rawDescOnce.Do(func() {
+		file_pkg_ctlplaneapi_controlplane_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_ctlplaneapi_controlplane_proto_rawDescData)
+	})
+	return file_pkg_ctlplaneapi_controlplane_proto_rawDescData
+}
+
+var file_pkg_ctlplaneapi_controlplane_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_pkg_ctlplaneapi_controlplane_proto_msgTypes = make([]protoimpl.MessageInfo, 51)
+var file_pkg_ctlplaneapi_controlplane_proto_goTypes = []interface{}{
+	(AllocationState)(0),                 // 0: ctlplaneapi.AllocationState
+	(Placement)(0),                       // 1: ctlplaneapi.Placement
+	(MemoryPinning)(0),                   // 2: ctlplaneapi.MemoryPinning
+	(CgroupVersion)(0),                   // 3: ctlplaneapi.CgroupVersion
+	(ContainerStatus)(0),                 // 4: ctlplaneapi.ContainerStatus
+	(QoSClass)(0),                        // 5: ctlplaneapi.QoSClass
+	(*CreatePodRequest)(nil),             // 6: ctlplaneapi.CreatePodRequest
+	(*CreatePodsRequest)(nil),            // 7: ctlplaneapi.CreatePodsRequest
+	(*UpdatePodRequest)(nil),             // 8: ctlplaneapi.UpdatePodRequest
+	(*DeletePodRequest)(nil),             // 9: ctlplaneapi.DeletePodRequest
+	(*GetPodRequest)(nil),                // 10: ctlplaneapi.GetPodRequest
+	(*ListPodsRequest)(nil),              // 11: ctlplaneapi.ListPodsRequest
+	(*GetContainerRequest)(nil),          // 12: ctlplaneapi.GetContainerRequest
+	(*CreateNamespaceBucketRequest)(nil), // 13: ctlplaneapi.CreateNamespaceBucketRequest
+	(*DeleteNamespaceBucketRequest)(nil), // 14: ctlplaneapi.DeleteNamespaceBucketRequest
+	(*GetDaemonInfoRequest)(nil),         // 15: ctlplaneapi.GetDaemonInfoRequest
+	(*GetConfigRequest)(nil),             // 16: ctlplaneapi.GetConfigRequest
+	(*GetAllocationsRequest)(nil),        // 17: ctlplaneapi.GetAllocationsRequest
+	(*MigrateContainerRequest)(nil),      // 18: ctlplaneapi.MigrateContainerRequest
+	(*ReserveCPUPoolRequest)(nil),        // 19: ctlplaneapi.ReserveCPUPoolRequest
+	(*ReleaseCPUPoolRequest)(nil),        // 20: ctlplaneapi.ReleaseCPUPoolRequest
+	(*SetNamespaceConfigRequest)(nil),    // 21: ctlplaneapi.SetNamespaceConfigRequest
+	(*DeleteNamespaceConfigRequest)(nil), // 22: ctlplaneapi.DeleteNamespaceConfigRequest
+	(*ListUsageRequest)(nil),             // 23: ctlplaneapi.ListUsageRequest
+	(*PlanDefragmentationRequest)(nil),   // 24: ctlplaneapi.PlanDefragmentationRequest
+	(*ResourceInfo)(nil),                 // 25: ctlplaneapi.ResourceInfo
+	(*ContainerInfo)(nil),                // 26: ctlplaneapi.ContainerInfo
+	(*ContainerAllocationInfo)(nil),      // 27: ctlplaneapi.ContainerAllocationInfo
+	(*CPUSet)(nil),                       // 28: ctlplaneapi.CPUSet
+	(*ContainerStatusInfo)(nil),          // 29: ctlplaneapi.ContainerStatusInfo
+	(*PodAllocationReply)(nil),           // 30: ctlplaneapi.PodAllocationReply
+	(*CreatePodResult)(nil),              // 31: ctlplaneapi.CreatePodResult
+	(*CreatePodsReply)(nil),              // 32: ctlplaneapi.CreatePodsReply
+	(*ListPodsReply)(nil),                // 33: ctlplaneapi.ListPodsReply
+	(*NumaNodeInfo)(nil),                 // 34: ctlplaneapi.NumaNodeInfo
+	(*TopologySummary)(nil),              // 35: ctlplaneapi.TopologySummary
+	(*AllocationsReply)(nil),             // 36: ctlplaneapi.AllocationsReply
+	(*ContainerAllocationReply)(nil),     // 37: ctlplaneapi.ContainerAllocationReply
+	(*ContainerMigrationInfo)(nil),       // 38: ctlplaneapi.ContainerMigrationInfo
+	(*DefragmentationPlanReply)(nil),     // 39: ctlplaneapi.DefragmentationPlanReply
+	(*NamespaceBucketReply)(nil),         // 40: ctlplaneapi.NamespaceBucketReply
+	(*CPUPoolReply)(nil),                 // 41: ctlplaneapi.CPUPoolReply
+	(*NamespaceConfigReply)(nil),         // 42: ctlplaneapi.NamespaceConfigReply
+	(*NamespaceUsageReply)(nil),          // 43: ctlplaneapi.NamespaceUsageReply
+	(*ListUsageReply)(nil),               // 44: ctlplaneapi.ListUsageReply
+	(*BuildInfo)(nil),                    // 45: ctlplaneapi.BuildInfo
+	(*PolicyTier)(nil),                   // 46: ctlplaneapi.PolicyTier
+	(*DaemonInfoReply)(nil),              // 47: ctlplaneapi.DaemonInfoReply
+	(*CPUBucketConfigInfo)(nil),          // 48: ctlplaneapi.CPUBucketConfigInfo
+	(*AllocatorConfigInfo)(nil),          // 49: ctlplaneapi.AllocatorConfigInfo
+	(*ConfigReply)(nil),                  // 50: ctlplaneapi.ConfigReply
+	nil,                                  // 51: ctlplaneapi.CreatePodRequest.LabelsEntry
+	nil,                                  // 52: ctlplaneapi.CreatePodRequest.AnnotationsEntry
+	nil,                                  // 53: ctlplaneapi.UpdatePodRequest.LabelsEntry
+	nil,                                  // 54: ctlplaneapi.UpdatePodRequest.AnnotationsEntry
+	nil,                                  // 55: ctlplaneapi.CreatePodResult.ErrorMetadataEntry
+	nil,                                  // 56: ctlplaneapi.AllocatorConfigInfo.NamespaceMemoryNodesEntry
+}
+var file_pkg_ctlplaneapi_controlplane_proto_depIdxs = []int32{
+	25, // 0: ctlplaneapi.CreatePodRequest.resources:type_name -> ctlplaneapi.ResourceInfo
+	26, // 1: ctlplaneapi.CreatePodRequest.containers:type_name -> ctlplaneapi.ContainerInfo
+	2,  // 2: ctlplaneapi.CreatePodRequest.memoryPinning:type_name -> ctlplaneapi.MemoryPinning
+	51, // 3: ctlplaneapi.CreatePodRequest.labels:type_name -> ctlplaneapi.CreatePodRequest.LabelsEntry
+	52, // 4: ctlplaneapi.CreatePodRequest.annotations:type_name -> ctlplaneapi.CreatePodRequest.AnnotationsEntry
+	6,  // 5: ctlplaneapi.CreatePodsRequest.pods:type_name -> ctlplaneapi.CreatePodRequest
+	25, // 6: ctlplaneapi.UpdatePodRequest.resources:type_name -> ctlplaneapi.ResourceInfo
+	26, // 7: ctlplaneapi.UpdatePodRequest.containers:type_name -> ctlplaneapi.ContainerInfo
+	53, // 8: ctlplaneapi.UpdatePodRequest.labels:type_name -> ctlplaneapi.UpdatePodRequest.LabelsEntry
+	54, // 9: ctlplaneapi.UpdatePodRequest.annotations:type_name -> ctlplaneapi.UpdatePodRequest.AnnotationsEntry
+	2,  // 10: ctlplaneapi.UpdatePodRequest.memoryPinning:type_name -> ctlplaneapi.MemoryPinning
+	1,  // 11: ctlplaneapi.ResourceInfo.cpuAffinity:type_name -> ctlplaneapi.Placement
+	25, // 12: ctlplaneapi.ContainerInfo.resources:type_name -> ctlplaneapi.ResourceInfo
+	0,  // 13: ctlplaneapi.ContainerAllocationInfo.allocState:type_name -> ctlplaneapi.AllocationState
+	28, // 14: ctlplaneapi.ContainerAllocationInfo.cpuSet:type_name -> ctlplaneapi.CPUSet
+	5,  // 15: ctlplaneapi.ContainerAllocationInfo.qos:type_name -> ctlplaneapi.QoSClass
+	4,  // 16: ctlplaneapi.ContainerStatusInfo.status:type_name -> ctlplaneapi.ContainerStatus
+	0,  // 17: ctlplaneapi.PodAllocationReply.allocState:type_name -> ctlplaneapi.AllocationState
+	28, // 18: ctlplaneapi.PodAllocationReply.cpuSet:type_name -> ctlplaneapi.CPUSet
+	27, // 19: ctlplaneapi.PodAllocationReply.containersAllocations:type_name -> ctlplaneapi.ContainerAllocationInfo
+	29, // 20: ctlplaneapi.PodAllocationReply.containerStatuses:type_name -> ctlplaneapi.ContainerStatusInfo
+	30, // 21: ctlplaneapi.CreatePodResult.reply:type_name -> ctlplaneapi.PodAllocationReply
+	55, // 22: ctlplaneapi.CreatePodResult.errorMetadata:type_name -> ctlplaneapi.CreatePodResult.ErrorMetadataEntry
+	31, // 23: ctlplaneapi.CreatePodsReply.results:type_name -> ctlplaneapi.CreatePodResult
+	30, // 24: ctlplaneapi.ListPodsReply.pods:type_name -> ctlplaneapi.PodAllocationReply
+	28, // 25: ctlplaneapi.NumaNodeInfo.cpuSet:type_name -> ctlplaneapi.CPUSet
+	28, // 26: ctlplaneapi.NumaNodeInfo.availableCpus:type_name -> ctlplaneapi.CPUSet
+	34, // 27: ctlplaneapi.TopologySummary.numaNodes:type_name -> ctlplaneapi.NumaNodeInfo
+	30, // 28: ctlplaneapi.AllocationsReply.pods:type_name -> ctlplaneapi.PodAllocationReply
+	28, // 29: ctlplaneapi.AllocationsReply.availableCpus:type_name -> ctlplaneapi.CPUSet
+	35, // 30: ctlplaneapi.AllocationsReply.topology:type_name -> ctlplaneapi.TopologySummary
+	41, // 31: ctlplaneapi.AllocationsReply.cpuPools:type_name -> ctlplaneapi.CPUPoolReply
+	27, // 32: ctlplaneapi.ContainerAllocationReply.allocation:type_name -> ctlplaneapi.ContainerAllocationInfo
+	38, // 33: ctlplaneapi.DefragmentationPlanReply.migrations:type_name -> ctlplaneapi.ContainerMigrationInfo
+	28, // 34: ctlplaneapi.NamespaceBucketReply.cpuSet:type_name -> ctlplaneapi.CPUSet
+	28, // 35: ctlplaneapi.CPUPoolReply.cpuSet:type_name -> ctlplaneapi.CPUSet
+	43, // 36: ctlplaneapi.ListUsageReply.namespaces:type_name -> ctlplaneapi.NamespaceUsageReply
+	3,  // 37: ctlplaneapi.DaemonInfoReply.cgroupVersion:type_name -> ctlplaneapi.CgroupVersion
+	45, // 38: ctlplaneapi.DaemonInfoReply.build:type_name -> ctlplaneapi.BuildInfo
+	46, // 39: ctlplaneapi.DaemonInfoReply.policyTiers:type_name -> ctlplaneapi.PolicyTier
+	28, // 40: ctlplaneapi.CPUBucketConfigInfo.cpuSet:type_name -> ctlplaneapi.CPUSet
+	48, // 41: ctlplaneapi.AllocatorConfigInfo.buckets:type_name -> ctlplaneapi.CPUBucketConfigInfo
+	56, // 42: ctlplaneapi.AllocatorConfigInfo.namespaceMemoryNodes:type_name -> ctlplaneapi.AllocatorConfigInfo.NamespaceMemoryNodesEntry
+	49, // 43: ctlplaneapi.ConfigReply.allocator:type_name -> ctlplaneapi.AllocatorConfigInfo
+	28, // 44: ctlplaneapi.ConfigReply.reservedCpus:type_name -> ctlplaneapi.CPUSet
+	28, // 45: ctlplaneapi.ConfigReply.excludedCpus:type_name -> ctlplaneapi.CPUSet
+	28, // 46: ctlplaneapi.ConfigReply.managedCpus:type_name -> ctlplaneapi.CPUSet
+	28, // 47: ctlplaneapi.ConfigReply.kubeletCpus:type_name -> ctlplaneapi.CPUSet
+	6,  // 48: ctlplaneapi.ControlPlane.CreatePod:input_type -> ctlplaneapi.CreatePodRequest
+	8,  // 49: ctlplaneapi.ControlPlane.UpdatePod:input_type -> ctlplaneapi.UpdatePodRequest
+	9,  // 50: ctlplaneapi.ControlPlane.DeletePod:input_type -> ctlplaneapi.DeletePodRequest
+	7,  // 51: ctlplaneapi.ControlPlane.CreatePods:input_type -> ctlplaneapi.CreatePodsRequest
+	10, // 52: ctlplaneapi.ControlPlane.GetPod:input_type -> ctlplaneapi.GetPodRequest
+	11, // 53: ctlplaneapi.ControlPlane.ListPods:input_type -> ctlplaneapi.ListPodsRequest
+	12, // 54: ctlplaneapi.ControlPlane.GetContainer:input_type -> ctlplaneapi.GetContainerRequest
+	13, // 55: ctlplaneapi.ControlPlane.CreateNamespaceBucket:input_type -> ctlplaneapi.CreateNamespaceBucketRequest
+	14, // 56: ctlplaneapi.ControlPlane.DeleteNamespaceBucket:input_type -> ctlplaneapi.DeleteNamespaceBucketRequest
+	15, // 57: ctlplaneapi.ControlPlane.GetDaemonInfo:input_type -> ctlplaneapi.GetDaemonInfoRequest
+	16, // 58: ctlplaneapi.ControlPlane.GetConfig:input_type -> ctlplaneapi.GetConfigRequest
+	18, // 59: ctlplaneapi.ControlPlane.MigrateContainer:input_type -> ctlplaneapi.MigrateContainerRequest
+	24, // 60: ctlplaneapi.ControlPlane.PlanDefragmentation:input_type -> ctlplaneapi.PlanDefragmentationRequest
+	17, // 61: ctlplaneapi.ControlPlane.GetAllocations:input_type -> ctlplaneapi.GetAllocationsRequest
+	19, // 62: ctlplaneapi.ControlPlane.ReserveCPUPool:input_type -> ctlplaneapi.ReserveCPUPoolRequest
+	20, // 63: ctlplaneapi.ControlPlane.ReleaseCPUPool:input_type -> ctlplaneapi.ReleaseCPUPoolRequest
+	21, // 64: ctlplaneapi.ControlPlane.SetNamespaceConfig:input_type -> ctlplaneapi.SetNamespaceConfigRequest
+	22, // 65: ctlplaneapi.ControlPlane.DeleteNamespaceConfig:input_type -> ctlplaneapi.DeleteNamespaceConfigRequest
+	23, // 66: ctlplaneapi.ControlPlane.ListUsage:input_type -> ctlplaneapi.ListUsageRequest
+	30, // 67: ctlplaneapi.ControlPlane.CreatePod:output_type -> ctlplaneapi.PodAllocationReply
+	30, // 68: ctlplaneapi.ControlPlane.UpdatePod:output_type -> ctlplaneapi.PodAllocationReply
+	30, // 69: ctlplaneapi.ControlPlane.DeletePod:output_type -> ctlplaneapi.PodAllocationReply
+	32, // 70: ctlplaneapi.ControlPlane.CreatePods:output_type -> ctlplaneapi.CreatePodsReply
+	30, // 71: ctlplaneapi.ControlPlane.GetPod:output_type -> ctlplaneapi.PodAllocationReply
+	33, // 72: ctlplaneapi.ControlPlane.ListPods:output_type -> ctlplaneapi.ListPodsReply
+	37, // 73: ctlplaneapi.ControlPlane.GetContainer:output_type -> ctlplaneapi.ContainerAllocationReply
+	40, // 74: ctlplaneapi.ControlPlane.CreateNamespaceBucket:output_type -> ctlplaneapi.NamespaceBucketReply
+	40, // 75: ctlplaneapi.ControlPlane.DeleteNamespaceBucket:output_type -> ctlplaneapi.NamespaceBucketReply
+	47, // 76: ctlplaneapi.ControlPlane.GetDaemonInfo:output_type -> ctlplaneapi.DaemonInfoReply
+	50, // 77: ctlplaneapi.ControlPlane.GetConfig:output_type -> ctlplaneapi.ConfigReply
+	37, // 78: ctlplaneapi.ControlPlane.MigrateContainer:output_type -> ctlplaneapi.ContainerAllocationReply
+	39, // 79: ctlplaneapi.ControlPlane.PlanDefragmentation:output_type -> ctlplaneapi.DefragmentationPlanReply
+	36, // 80: ctlplaneapi.ControlPlane.GetAllocations:output_type -> ctlplaneapi.AllocationsReply
+	41, // 81: ctlplaneapi.ControlPlane.ReserveCPUPool:output_type -> ctlplaneapi.CPUPoolReply
+	41, // 82: ctlplaneapi.ControlPlane.ReleaseCPUPool:output_type -> ctlplaneapi.CPUPoolReply
+	42, // 83: ctlplaneapi.ControlPlane.SetNamespaceConfig:output_type -> ctlplaneapi.NamespaceConfigReply
+	42, // 84: ctlplaneapi.ControlPlane.DeleteNamespaceConfig:output_type -> ctlplaneapi.NamespaceConfigReply
+	44, // 85: ctlplaneapi.ControlPlane.ListUsage:output_type -> ctlplaneapi.ListUsageReply
+	67, // [67:86] is the sub-list for method output_type
+	48, // [48:67] is the sub-list for method input_type
+	48, // [48:48] is the sub-list for extension type_name
+	48, // [48:48] is the sub-list for extension extendee
+	0,  // [0:48] is the sub-list for field type_name
+}
+
+func init() { file_pkg_ctlplaneapi_controlplane_proto_init() }
+func file_pkg_ctlplaneapi_controlplane_proto_init() {
+	if File_pkg_ctlplaneapi_controlplane_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreatePodRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreatePodsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdatePodRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeletePodRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetPodRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListPodsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetContainerRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateNamespaceBucketRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteNamespaceBucketRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetDaemonInfoRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetConfigRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetAllocationsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MigrateContainerRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReserveCPUPoolRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReleaseCPUPoolRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
 				return nil
 			}
 		}
-		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetNamespaceConfigRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteNamespaceConfigRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListUsageRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PlanDefragmentationRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ResourceInfo); i {
 			case 0:
 				return &v.state
@@ -880,7 +4339,7 @@ func file_pkg_ctlplaneapi_controlplane_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ContainerInfo); i {
 			case 0:
 				return &v.state
@@ -892,7 +4351,7 @@ func file_pkg_ctlplaneapi_controlplane_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ContainerAllocationInfo); i {
 			case 0:
 				return &v.state
@@ -904,7 +4363,7 @@ func file_pkg_ctlplaneapi_controlplane_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CPUSet); i {
 			case 0:
 				return &v.state
@@ -916,7 +4375,19 @@ func file_pkg_ctlplaneapi_controlplane_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ContainerStatusInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*PodAllocationReply); i {
 			case 0:
 				return &v.state
@@ -928,14 +4399,254 @@ func file_pkg_ctlplaneapi_controlplane_proto_init() {
 				return nil
 			}
 		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreatePodResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreatePodsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListPodsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NumaNodeInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TopologySummary); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AllocationsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ContainerAllocationReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ContainerMigrationInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DefragmentationPlanReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NamespaceBucketReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CPUPoolReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NamespaceConfigReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NamespaceUsageReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListUsageReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BuildInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PolicyTier); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DaemonInfoReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[42].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CPUBucketConfigInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[43].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AllocatorConfigInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_ctlplaneapi_controlplane_proto_msgTypes[44].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConfigReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_ctlplaneapi_controlplane_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   8,
+			NumEnums:      6,
+			NumMessages:   51,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -28,6 +28,41 @@ type ControlPlaneClient interface {
 	UpdatePod(ctx context.Context, in *UpdatePodRequest, opts ...grpc.CallOption) (*PodAllocationReply, error)
 	// Deallocates a pod
 	DeletePod(ctx context.Context, in *DeletePodRequest, opts ...grpc.CallOption) (*PodAllocationReply, error)
+	// Requests allocation of multiple pods in one call, eg. on agent start
+	CreatePods(ctx context.Context, in *CreatePodsRequest, opts ...grpc.CallOption) (*CreatePodsReply, error)
+	// Returns current allocation of a pod
+	GetPod(ctx context.Context, in *GetPodRequest, opts ...grpc.CallOption) (*PodAllocationReply, error)
+	// Returns current allocations of all pods
+	ListPods(ctx context.Context, in *ListPodsRequest, opts ...grpc.CallOption) (*ListPodsReply, error)
+	// Returns current allocation of a container selected by pod id and container name
+	GetContainer(ctx context.Context, in *GetContainerRequest, opts ...grpc.CallOption) (*ContainerAllocationReply, error)
+	// Creates cpu bucket of a namespace before any pod of the namespace arrives (numa-namespace allocators)
+	CreateNamespaceBucket(ctx context.Context, in *CreateNamespaceBucketRequest, opts ...grpc.CallOption) (*NamespaceBucketReply, error)
+	// Releases cpu bucket of a namespace, created explicitly or by the first container of the namespace
+	DeleteNamespaceBucket(ctx context.Context, in *DeleteNamespaceBucketRequest, opts ...grpc.CallOption) (*NamespaceBucketReply, error)
+	// Returns information about the daemon, eg. cgroup version it manages cpusets with
+	GetDaemonInfo(ctx context.Context, in *GetDaemonInfoRequest, opts ...grpc.CallOption) (*DaemonInfoReply, error)
+	// Returns live configuration of the daemon and its allocator, eg. to validate pod annotations
+	GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*ConfigReply, error)
+	// Moves cpus and memory of a running guaranteed container to another numa node (numa allocator)
+	MigrateContainer(ctx context.Context, in *MigrateContainerRequest, opts ...grpc.CallOption) (*ContainerAllocationReply, error)
+	// Returns the shortest found sequence of container migrations making room for given number of cpus on a
+	// single numa node, optionally executing it (numa allocator)
+	PlanDefragmentation(ctx context.Context, in *PlanDefragmentationRequest, opts ...grpc.CallOption) (*DefragmentationPlanReply, error)
+	// Returns snapshot of the daemon state: allocations of all pods, available cpus and topology summary
+	GetAllocations(ctx context.Context, in *GetAllocationsRequest, opts ...grpc.CallOption) (*AllocationsReply, error)
+	// Reserves named pool of cpus on a single numa node for workloads running outside of kubernetes, no pod
+	// allocation uses cpus of the pool until it is released
+	ReserveCPUPool(ctx context.Context, in *ReserveCPUPoolRequest, opts ...grpc.CallOption) (*CPUPoolReply, error)
+	// Releases reserved cpu pool, its cpus return to the shared pool
+	ReleaseCPUPool(ctx context.Context, in *ReleaseCPUPoolRequest, opts ...grpc.CallOption) (*CPUPoolReply, error)
+	// Configures allocations of new pods of the namespace, eg. from ControlPlaneConfig custom resource
+	SetNamespaceConfig(ctx context.Context, in *SetNamespaceConfigRequest, opts ...grpc.CallOption) (*NamespaceConfigReply, error)
+	// Removes configuration of the namespace, new pods of the namespace use the daemon configuration
+	DeleteNamespaceConfig(ctx context.Context, in *DeleteNamespaceConfigRequest, opts ...grpc.CallOption) (*NamespaceConfigReply, error)
+	// Returns cpu seconds of exclusive cpus pinned to and requested by guaranteed containers of namespaces,
+	// eg. for chargeback of exclusive cores
+	ListUsage(ctx context.Context, in *ListUsageRequest, opts ...grpc.CallOption) (*ListUsageReply, error)
 }
 
 type controlPlaneClient struct {
@@ -65,6 +100,150 @@ func (c *controlPlaneClient) DeletePod(ctx context.Context, in *DeletePodRequest
 	return out, nil
 }
 
+func (c *controlPlaneClient) CreatePods(ctx context.Context, in *CreatePodsRequest, opts ...grpc.CallOption) (*CreatePodsReply, error) {
+	out := new(CreatePodsReply)
+	err := c.cc.Invoke(ctx, "/ctlplaneapi.ControlPlane/CreatePods", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) GetPod(ctx context.Context, in *GetPodRequest, opts ...grpc.CallOption) (*PodAllocationReply, error) {
+	out := new(PodAllocationReply)
+	err := c.cc.Invoke(ctx, "/ctlplaneapi.ControlPlane/GetPod", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) ListPods(ctx context.Context, in *ListPodsRequest, opts ...grpc.CallOption) (*ListPodsReply, error) {
+	out := new(ListPodsReply)
+	err := c.cc.Invoke(ctx, "/ctlplaneapi.ControlPlane/ListPods", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) GetContainer(ctx context.Context, in *GetContainerRequest, opts ...grpc.CallOption) (*ContainerAllocationReply, error) {
+	out := new(ContainerAllocationReply)
+	err := c.cc.Invoke(ctx, "/ctlplaneapi.ControlPlane/GetContainer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) CreateNamespaceBucket(ctx context.Context, in *CreateNamespaceBucketRequest, opts ...grpc.CallOption) (*NamespaceBucketReply, error) {
+	out := new(NamespaceBucketReply)
+	err := c.cc.Invoke(ctx, "/ctlplaneapi.ControlPlane/CreateNamespaceBucket", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) DeleteNamespaceBucket(ctx context.Context, in *DeleteNamespaceBucketRequest, opts ...grpc.CallOption) (*NamespaceBucketReply, error) {
+	out := new(NamespaceBucketReply)
+	err := c.cc.Invoke(ctx, "/ctlplaneapi.ControlPlane/DeleteNamespaceBucket", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) GetDaemonInfo(ctx context.Context, in *GetDaemonInfoRequest, opts ...grpc.CallOption) (*DaemonInfoReply, error) {
+	out := new(DaemonInfoReply)
+	err := c.cc.Invoke(ctx, "/ctlplaneapi.ControlPlane/GetDaemonInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*ConfigReply, error) {
+	out := new(ConfigReply)
+	err := c.cc.Invoke(ctx, "/ctlplaneapi.ControlPlane/GetConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) MigrateContainer(ctx context.Context, in *MigrateContainerRequest, opts ...grpc.CallOption) (*ContainerAllocationReply, error) {
+	out := new(ContainerAllocationReply)
+	err := c.cc.Invoke(ctx, "/ctlplaneapi.ControlPlane/MigrateContainer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) PlanDefragmentation(ctx context.Context, in *PlanDefragmentationRequest, opts ...grpc.CallOption) (*DefragmentationPlanReply, error) {
+	out := new(DefragmentationPlanReply)
+	err := c.cc.Invoke(ctx, "/ctlplaneapi.ControlPlane/PlanDefragmentation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) GetAllocations(ctx context.Context, in *GetAllocationsRequest, opts ...grpc.CallOption) (*AllocationsReply, error) {
+	out := new(AllocationsReply)
+	err := c.cc.Invoke(ctx, "/ctlplaneapi.ControlPlane/GetAllocations", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) ReserveCPUPool(ctx context.Context, in *ReserveCPUPoolRequest, opts ...grpc.CallOption) (*CPUPoolReply, error) {
+	out := new(CPUPoolReply)
+	err := c.cc.Invoke(ctx, "/ctlplaneapi.ControlPlane/ReserveCPUPool", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) ReleaseCPUPool(ctx context.Context, in *ReleaseCPUPoolRequest, opts ...grpc.CallOption) (*CPUPoolReply, error) {
+	out := new(CPUPoolReply)
+	err := c.cc.Invoke(ctx, "/ctlplaneapi.ControlPlane/ReleaseCPUPool", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) SetNamespaceConfig(ctx context.Context, in *SetNamespaceConfigRequest, opts ...grpc.CallOption) (*NamespaceConfigReply, error) {
+	out := new(NamespaceConfigReply)
+	err := c.cc.Invoke(ctx, "/ctlplaneapi.ControlPlane/SetNamespaceConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) DeleteNamespaceConfig(ctx context.Context, in *DeleteNamespaceConfigRequest, opts ...grpc.CallOption) (*NamespaceConfigReply, error) {
+	out := new(NamespaceConfigReply)
+	err := c.cc.Invoke(ctx, "/ctlplaneapi.ControlPlane/DeleteNamespaceConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) ListUsage(ctx context.Context, in *ListUsageRequest, opts ...grpc.CallOption) (*ListUsageReply, error) {
+	out := new(ListUsageReply)
+	err := c.cc.Invoke(ctx, "/ctlplaneapi.ControlPlane/ListUsage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ControlPlaneServer is the server API for ControlPlane service.
 // All implementations must embed UnimplementedControlPlaneServer
 // for forward compatibility
@@ -75,6 +254,41 @@ type ControlPlaneServer interface {
 	UpdatePod(context.Context, *UpdatePodRequest) (*PodAllocationReply, error)
 	// Deallocates a pod
 	DeletePod(context.Context, *DeletePodRequest) (*PodAllocationReply, error)
+	// Requests allocation of multiple pods in one call, eg. on agent start
+	CreatePods(context.Context, *CreatePodsRequest) (*CreatePodsReply, error)
+	// Returns current allocation of a pod
+	GetPod(context.Context, *GetPodRequest) (*PodAllocationReply, error)
+	// Returns current allocations of all pods
+	ListPods(context.Context, *ListPodsRequest) (*ListPodsReply, error)
+	// Returns current allocation of a container selected by pod id and container name
+	GetContainer(context.Context, *GetContainerRequest) (*ContainerAllocationReply, error)
+	// Creates cpu bucket of a namespace before any pod of the namespace arrives (numa-namespace allocators)
+	CreateNamespaceBucket(context.Context, *CreateNamespaceBucketRequest) (*NamespaceBucketReply, error)
+	// Releases cpu bucket of a namespace, created explicitly or by the first container of the namespace
+	DeleteNamespaceBucket(context.Context, *DeleteNamespaceBucketRequest) (*NamespaceBucketReply, error)
+	// Returns information about the daemon, eg. cgroup version it manages cpusets with
+	GetDaemonInfo(context.Context, *GetDaemonInfoRequest) (*DaemonInfoReply, error)
+	// Returns live configuration of the daemon and its allocator, eg. to validate pod annotations
+	GetConfig(context.Context, *GetConfigRequest) (*ConfigReply, error)
+	// Moves cpus and memory of a running guaranteed container to another numa node (numa allocator)
+	MigrateContainer(context.Context, *MigrateContainerRequest) (*ContainerAllocationReply, error)
+	// Returns the shortest found sequence of container migrations making room for given number of cpus on a
+	// single numa node, optionally executing it (numa allocator)
+	PlanDefragmentation(context.Context, *PlanDefragmentationRequest) (*DefragmentationPlanReply, error)
+	// Returns snapshot of the daemon state: allocations of all pods, available cpus and topology summary
+	GetAllocations(context.Context, *GetAllocationsRequest) (*AllocationsReply, error)
+	// Reserves named pool of cpus on a single numa node for workloads running outside of kubernetes, no pod
+	// allocation uses cpus of the pool until it is released
+	ReserveCPUPool(context.Context, *ReserveCPUPoolRequest) (*CPUPoolReply, error)
+	// Releases reserved cpu pool, its cpus return to the shared pool
+	ReleaseCPUPool(context.Context, *ReleaseCPUPoolRequest) (*CPUPoolReply, error)
+	// Configures allocations of new pods of the namespace, eg. from ControlPlaneConfig custom resource
+	SetNamespaceConfig(context.Context, *SetNamespaceConfigRequest) (*NamespaceConfigReply, error)
+	// Removes configuration of the namespace, new pods of the namespace use the daemon configuration
+	DeleteNamespaceConfig(context.Context, *DeleteNamespaceConfigRequest) (*NamespaceConfigReply, error)
+	// Returns cpu seconds of exclusive cpus pinned to and requested by guaranteed containers of namespaces,
+	// eg. for chargeback of exclusive cores
+	ListUsage(context.Context, *ListUsageRequest) (*ListUsageReply, error)
 	mustEmbedUnimplementedControlPlaneServer()
 }
 
@@ -91,6 +305,54 @@ func (UnimplementedControlPlaneServer) UpdatePod(context.Context, *UpdatePodRequ
 func (UnimplementedControlPlaneServer) DeletePod(context.Context, *DeletePodRequest) (*PodAllocationReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeletePod not implemented")
 }
+func (UnimplementedControlPlaneServer) CreatePods(context.Context, *CreatePodsRequest) (*CreatePodsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePods not implemented")
+}
+func (UnimplementedControlPlaneServer) GetPod(context.Context, *GetPodRequest) (*PodAllocationReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPod not implemented")
+}
+func (UnimplementedControlPlaneServer) ListPods(context.Context, *ListPodsRequest) (*ListPodsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPods not implemented")
+}
+func (UnimplementedControlPlaneServer) GetContainer(context.Context, *GetContainerRequest) (*ContainerAllocationReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetContainer not implemented")
+}
+func (UnimplementedControlPlaneServer) CreateNamespaceBucket(context.Context, *CreateNamespaceBucketRequest) (*NamespaceBucketReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateNamespaceBucket not implemented")
+}
+func (UnimplementedControlPlaneServer) DeleteNamespaceBucket(context.Context, *DeleteNamespaceBucketRequest) (*NamespaceBucketReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteNamespaceBucket not implemented")
+}
+func (UnimplementedControlPlaneServer) GetDaemonInfo(context.Context, *GetDaemonInfoRequest) (*DaemonInfoReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDaemonInfo not implemented")
+}
+func (UnimplementedControlPlaneServer) GetConfig(context.Context, *GetConfigRequest) (*ConfigReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConfig not implemented")
+}
+func (UnimplementedControlPlaneServer) MigrateContainer(context.Context, *MigrateContainerRequest) (*ContainerAllocationReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MigrateContainer not implemented")
+}
+func (UnimplementedControlPlaneServer) PlanDefragmentation(context.Context, *PlanDefragmentationRequest) (*DefragmentationPlanReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PlanDefragmentation not implemented")
+}
+func (UnimplementedControlPlaneServer) GetAllocations(context.Context, *GetAllocationsRequest) (*AllocationsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAllocations not implemented")
+}
+func (UnimplementedControlPlaneServer) ReserveCPUPool(context.Context, *ReserveCPUPoolRequest) (*CPUPoolReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReserveCPUPool not implemented")
+}
+func (UnimplementedControlPlaneServer) ReleaseCPUPool(context.Context, *ReleaseCPUPoolRequest) (*CPUPoolReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseCPUPool not implemented")
+}
+func (UnimplementedControlPlaneServer) SetNamespaceConfig(context.Context, *SetNamespaceConfigRequest) (*NamespaceConfigReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetNamespaceConfig not implemented")
+}
+func (UnimplementedControlPlaneServer) DeleteNamespaceConfig(context.Context, *DeleteNamespaceConfigRequest) (*NamespaceConfigReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteNamespaceConfig not implemented")
+}
+func (UnimplementedControlPlaneServer) ListUsage(context.Context, *ListUsageRequest) (*ListUsageReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUsage not implemented")
+}
 func (UnimplementedControlPlaneServer) mustEmbedUnimplementedControlPlaneServer() {}
 
 // UnsafeControlPlaneServer may be embedded to opt out of forward compatibility for this service.
@@ -158,6 +420,294 @@ func _ControlPlane_DeletePod_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ControlPlane_CreatePods_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePodsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).CreatePods(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ctlplaneapi.ControlPlane/CreatePods",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).CreatePods(ctx, req.(*CreatePodsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_GetPod_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPodRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).GetPod(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ctlplaneapi.ControlPlane/GetPod",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).GetPod(ctx, req.(*GetPodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_ListPods_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPodsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).ListPods(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ctlplaneapi.ControlPlane/ListPods",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).ListPods(ctx, req.(*ListPodsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_GetContainer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetContainerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).GetContainer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ctlplaneapi.ControlPlane/GetContainer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).GetContainer(ctx, req.(*GetContainerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_CreateNamespaceBucket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateNamespaceBucketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).CreateNamespaceBucket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ctlplaneapi.ControlPlane/CreateNamespaceBucket",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).CreateNamespaceBucket(ctx, req.(*CreateNamespaceBucketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_DeleteNamespaceBucket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteNamespaceBucketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).DeleteNamespaceBucket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ctlplaneapi.ControlPlane/DeleteNamespaceBucket",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).DeleteNamespaceBucket(ctx, req.(*DeleteNamespaceBucketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_GetDaemonInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDaemonInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).GetDaemonInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ctlplaneapi.ControlPlane/GetDaemonInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).GetDaemonInfo(ctx, req.(*GetDaemonInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).GetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ctlplaneapi.ControlPlane/GetConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).GetConfig(ctx, req.(*GetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_MigrateContainer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MigrateContainerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).MigrateContainer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ctlplaneapi.ControlPlane/MigrateContainer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).MigrateContainer(ctx, req.(*MigrateContainerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_PlanDefragmentation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanDefragmentationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).PlanDefragmentation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ctlplaneapi.ControlPlane/PlanDefragmentation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).PlanDefragmentation(ctx, req.(*PlanDefragmentationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_GetAllocations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAllocationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).GetAllocations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ctlplaneapi.ControlPlane/GetAllocations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).GetAllocations(ctx, req.(*GetAllocationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_ReserveCPUPool_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReserveCPUPoolRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).ReserveCPUPool(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ctlplaneapi.ControlPlane/ReserveCPUPool",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).ReserveCPUPool(ctx, req.(*ReserveCPUPoolRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_ReleaseCPUPool_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseCPUPoolRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).ReleaseCPUPool(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ctlplaneapi.ControlPlane/ReleaseCPUPool",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).ReleaseCPUPool(ctx, req.(*ReleaseCPUPoolRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_SetNamespaceConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetNamespaceConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).SetNamespaceConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ctlplaneapi.ControlPlane/SetNamespaceConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).SetNamespaceConfig(ctx, req.(*SetNamespaceConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_DeleteNamespaceConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteNamespaceConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).DeleteNamespaceConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ctlplaneapi.ControlPlane/DeleteNamespaceConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).DeleteNamespaceConfig(ctx, req.(*DeleteNamespaceConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_ListUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).ListUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ctlplaneapi.ControlPlane/ListUsage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).ListUsage(ctx, req.(*ListUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ControlPlane_ServiceDesc is the grpc.ServiceDesc for ControlPlane service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -177,6 +727,70 @@ var ControlPlane_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeletePod",
 			Handler:    _ControlPlane_DeletePod_Handler,
 		},
+		{
+			MethodName: "CreatePods",
+			Handler:    _ControlPlane_CreatePods_Handler,
+		},
+		{
+			MethodName: "GetPod",
+			Handler:    _ControlPlane_GetPod_Handler,
+		},
+		{
+			MethodName: "ListPods",
+			Handler:    _ControlPlane_ListPods_Handler,
+		},
+		{
+			MethodName: "GetContainer",
+			Handler:    _ControlPlane_GetContainer_Handler,
+		},
+		{
+			MethodName: "CreateNamespaceBucket",
+			Handler:    _ControlPlane_CreateNamespaceBucket_Handler,
+		},
+		{
+			MethodName: "DeleteNamespaceBucket",
+			Handler:    _ControlPlane_DeleteNamespaceBucket_Handler,
+		},
+		{
+			MethodName: "GetDaemonInfo",
+			Handler:    _ControlPlane_GetDaemonInfo_Handler,
+		},
+		{
+			MethodName: "GetConfig",
+			Handler:    _ControlPlane_GetConfig_Handler,
+		},
+		{
+			MethodName: "MigrateContainer",
+			Handler:    _ControlPlane_MigrateContainer_Handler,
+		},
+		{
+			MethodName: "PlanDefragmentation",
+			Handler:    _ControlPlane_PlanDefragmentation_Handler,
+		},
+		{
+			MethodName: "GetAllocations",
+			Handler:    _ControlPlane_GetAllocations_Handler,
+		},
+		{
+			MethodName: "ReserveCPUPool",
+			Handler:    _ControlPlane_ReserveCPUPool_Handler,
+		},
+		{
+			MethodName: "ReleaseCPUPool",
+			Handler:    _ControlPlane_ReleaseCPUPool_Handler,
+		},
+		{
+			MethodName: "SetNamespaceConfig",
+			Handler:    _ControlPlane_SetNamespaceConfig_Handler,
+		},
+		{
+			MethodName: "DeleteNamespaceConfig",
+			Handler:    _ControlPlane_DeleteNamespaceConfig_Handler,
+		},
+		{
+			MethodName: "ListUsage",
+			Handler:    _ControlPlane_ListUsage_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/ctlplaneapi/controlplane.proto",
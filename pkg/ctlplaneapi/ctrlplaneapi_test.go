@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
@@ -15,27 +17,41 @@ import (
 	"google.golang.org/grpc/test/bufconn"
 	"google.golang.org/protobuf/proto"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"resourcemanagement.controlplane/pkg/version"
 )
 
 type DaemonMock struct {
 	mock.Mock
 }
 
-func (m *DaemonMock) CreatePod(req *CreatePodRequest) (*AllocatedPodResources, error) {
+func (m *DaemonMock) CreatePod(_ context.Context, req *CreatePodRequest) (*AllocatedPodResources, error) {
 	args := m.Called(req)
 	return createTestCPUAllocation(req.Containers), args.Error(0)
 }
 
-func (m *DaemonMock) DeletePod(req *DeletePodRequest) error {
+func (m *DaemonMock) DeletePod(_ context.Context, req *DeletePodRequest) (*AllocatedPodResources, error) {
 	args := m.Called(req)
-	return args.Error(0)
+	pod, _ := args.Get(0).(*AllocatedPodResources)
+	return pod, args.Error(1)
 }
 
-func (m *DaemonMock) UpdatePod(req *UpdatePodRequest) (*AllocatedPodResources, error) {
+func (m *DaemonMock) UpdatePod(_ context.Context, req *UpdatePodRequest) (*AllocatedPodResources, error) {
 	args := m.Called(req)
 	return modifyCPUAllocation(req.Containers), args.Error(0)
 }
 
+func (m *DaemonMock) GetPod(_ context.Context, req *GetPodRequest) (*AllocatedPodResources, error) {
+	args := m.Called(req)
+	podResources, _ := args.Get(0).(*AllocatedPodResources)
+	return podResources, args.Error(1)
+}
+
+func (m *DaemonMock) ListPods(_ context.Context, req *ListPodsRequest) ([]AllocatedPodResources, error) {
+	args := m.Called(req)
+	pods, _ := args.Get(0).([]AllocatedPodResources)
+	return pods, args.Error(1)
+}
+
 // Creates a bufconn grpc server for testing.
 func NewMockedServer(ctx context.Context) (ControlPlaneClient, func(), *DaemonMock) {
 	buffer := 1024 * 1024
@@ -64,7 +80,7 @@ func NewMockedServer(ctx context.Context) (ControlPlaneClient, func(), *DaemonMo
 }
 
 func createTestDeletion(m *DaemonMock, pid string, err error) (*DeletePodRequest, *PodAllocationReply) {
-	m.On("DeletePod", &DeletePodRequest{PodId: pid}).Return(err).Once()
+	m.On("DeletePod", &DeletePodRequest{PodId: pid}).Return(nil, err).Once()
 	return &DeletePodRequest{
 			PodId: pid,
 		}, &PodAllocationReply{
@@ -101,6 +117,9 @@ func createTestCPUAllocation(container []*ContainerInfo) *AllocatedPodResources
 			AllocatedContainerResource{
 				ContainerID: c.ContainerId,
 				CPUSet:      defaultBuckets,
+				QoS:         QoSClass_BURSTABLE,
+				MemoryNodes: "0",
+				Exclusive:   false,
 			},
 		)
 	}
@@ -110,18 +129,6 @@ func createTestCPUAllocation(container []*ContainerInfo) *AllocatedPodResources
 	}
 }
 
-func toGRPCHelper4Containers(c []AllocatedContainerResource) []*ContainerAllocationInfo {
-	res := []*ContainerAllocationInfo{}
-	for _, it := range c {
-		res = append(res,
-			&ContainerAllocationInfo{
-				ContainerId: it.ContainerID,
-				CpuSet:      toGRPCHelper4CPUSet(it.CPUSet),
-			})
-	}
-	return res
-}
-
 func validateAllocatedPodReply(t *testing.T, eReply *PodAllocationReply, reply *PodAllocationReply) {
 	assert.Equal(t, eReply.PodId, reply.PodId)
 	assert.Equal(t, len(eReply.CpuSet), len(reply.CpuSet))
@@ -130,6 +137,10 @@ func validateAllocatedPodReply(t *testing.T, eReply *PodAllocationReply, reply *
 		assert.Equal(t, eReply.CpuSet[i].StartCPU, reply.CpuSet[i].StartCPU)
 		assert.Equal(t, eReply.CpuSet[i].EndCPU, reply.CpuSet[i].EndCPU)
 	}
+	assert.Equal(t, len(eReply.ContainersAllocations), len(reply.ContainersAllocations))
+	for i := 0; i < len(eReply.ContainersAllocations); i++ {
+		assert.True(t, proto.Equal(eReply.ContainersAllocations[i], reply.ContainersAllocations[i]))
+	}
 }
 
 func newQuantityAsBytes(v int64) []byte {
@@ -202,7 +213,7 @@ func updateTestPodRequest(t *testing.T, m *DaemonMock, cReq *CreatePodRequest, c
 	return &request, &PodAllocationReply{
 		PodId:                 cReq.PodId,
 		CpuSet:                toGRPCHelper4CPUSet(ePodAllock.CPUSet),
-		ContainersAllocations: toGRPCHelper4Containers(ePodAllock.ContainerResources),
+		ContainersAllocations: toGRPCHelper4Containers(ePodAllock.ContainerResources, time.Now()),
 		AllocState:            AllocationState_UPDATED,
 	}
 }
@@ -232,7 +243,7 @@ func createTestPodRequest(t *testing.T, pName, pNamespace string, m *DaemonMock,
 	return &request, &PodAllocationReply{
 		PodId:                 pid,
 		CpuSet:                toGRPCHelper4CPUSet(ePodAllock.CPUSet),
-		ContainersAllocations: toGRPCHelper4Containers(ePodAllock.ContainerResources),
+		ContainersAllocations: toGRPCHelper4Containers(ePodAllock.ContainerResources, time.Now()),
 		AllocState:            AllocationState_CREATED,
 	}
 }
@@ -305,3 +316,641 @@ func TestDeletePod(t *testing.T) {
 	validateAllocatedPodReply(t, eReply, reply)
 	assert.Nil(err)
 }
+
+func TestDeletePodReturnsReleasedCpus(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	req := &DeletePodRequest{PodId: "pod"}
+	mDaemon.On("DeletePod", mock.MatchedBy(func(r *DeletePodRequest) bool {
+		return r.PodId == req.PodId
+	})).Return(&AllocatedPodResources{
+		PodID: "pod",
+		ContainerResources: []AllocatedContainerResource{{
+			ContainerID:  "cid",
+			CPUSet:       []CPUBucket{{StartCPU: 2, EndCPU: 3}},
+			QoS:          QoSClass_GUARANTEED,
+			Exclusive:    true,
+			ReleasedPool: "namespace-bucket-1",
+		}},
+	}, nil)
+
+	reply, err := client.DeletePod(ctx, req)
+
+	require.Nil(t, err)
+	assert.Equal(t, AllocationState_DELETED, reply.AllocState)
+	require.Len(t, reply.ContainersAllocations, 1)
+	c := reply.ContainersAllocations[0]
+	assert.Equal(t, "cid", c.ContainerId)
+	assert.Equal(t, AllocationState_DELETED, c.AllocState)
+	assert.True(t, c.Exclusive)
+	assert.Equal(t, "namespace-bucket-1", c.ReleasedPool)
+	require.Len(t, c.CpuSet, 1)
+	assert.Equal(t, int32(2), c.CpuSet[0].StartCPU)
+	assert.Equal(t, int32(3), c.CpuSet[0].EndCPU)
+}
+
+func TestCreatePodsReportsPerPodResults(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	containers := createContainers(2, []Placement{Placement_DEFAULT})
+	pErr := status.Error(codes.Aborted, "error")
+	okReq, okReply := createTestPodRequest(t, "ok", "test", mDaemon, Placement_DEFAULT, containers, nil)
+	failReq, _ := createTestPodRequest(t, "fail", "test", mDaemon, Placement_DEFAULT, containers, pErr)
+
+	reply, err := client.CreatePods(ctx, &CreatePodsRequest{Pods: []*CreatePodRequest{failReq, okReq}})
+	require.Nil(t, err)
+	require.Len(t, reply.Results, 2)
+
+	assert.Equal(t, failReq.PodId, reply.Results[0].PodId)
+	assert.Nil(t, reply.Results[0].Reply)
+	assert.Contains(t, reply.Results[0].Error, pErr.Error())
+
+	assert.Equal(t, okReq.PodId, reply.Results[1].PodId)
+	assert.Empty(t, reply.Results[1].Error)
+	validateAllocatedPodReply(t, okReply, reply.Results[1].Reply)
+}
+
+func TestCreatePodsWithNoPods(t *testing.T) {
+	ctx := context.Background()
+	client, closer, _ := NewMockedServer(ctx)
+	defer closer()
+
+	reply, err := client.CreatePods(ctx, &CreatePodsRequest{})
+	require.Nil(t, err)
+	assert.Empty(t, reply.Results)
+}
+
+func TestErrorsKeepTheirStatusCode(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+
+	req, _ := createTestDeletion(mDaemon, "notFound", status.Error(codes.NotFound, "recently deleted"))
+	_, err := client.DeletePod(ctx, req)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	req, _ = createTestDeletion(mDaemon, "failed", fmt.Errorf("failure")) //nolint
+	_, err = client.DeletePod(ctx, req)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestGetPodReportsAllocationAge(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	allocatedAt := time.Now().Add(-time.Hour)
+	podResources := createTestCPUAllocation(createContainers(2, []Placement{Placement_DEFAULT}))
+	podResources.ContainerResources[0].AllocatedAt = allocatedAt
+	mDaemon.On("GetPod", &GetPodRequest{PodId: "pod"}).Return(podResources, nil)
+
+	reply, err := client.GetPod(ctx, &GetPodRequest{PodId: "pod"})
+
+	require.Nil(t, err)
+	assert.Equal(t, "pod", reply.PodId)
+	require.Len(t, reply.ContainersAllocations, 2)
+	assert.Equal(t, allocatedAt.Unix(), reply.ContainersAllocations[0].AllocationTimestamp)
+	assert.InDelta(t, time.Hour.Seconds(), reply.ContainersAllocations[0].AllocationAgeSeconds, 5)
+	assert.Zero(t, reply.ContainersAllocations[1].AllocationTimestamp)
+	assert.Zero(t, reply.ContainersAllocations[1].AllocationAgeSeconds)
+}
+
+func TestGetPodNotFound(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	mDaemon.On("GetPod", &GetPodRequest{PodId: "pod"}).Return(nil, status.Error(codes.NotFound, "not found"))
+
+	reply, err := client.GetPod(ctx, &GetPodRequest{PodId: "pod"})
+
+	assert.Nil(t, reply)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestListPods(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	first := createTestCPUAllocation(createContainers(1, []Placement{Placement_DEFAULT}))
+	first.PodID = "first"
+	second := createTestCPUAllocation(createContainers(2, []Placement{Placement_DEFAULT}))
+	second.PodID = "second"
+	mDaemon.On("ListPods", mock.Anything).Return([]AllocatedPodResources{*first, *second}, nil)
+
+	reply, err := client.ListPods(ctx, &ListPodsRequest{})
+
+	require.Nil(t, err)
+	require.Len(t, reply.Pods, 2)
+	assert.Equal(t, "first", reply.Pods[0].PodId)
+	assert.Len(t, reply.Pods[0].ContainersAllocations, 1)
+	assert.Equal(t, "second", reply.Pods[1].PodId)
+	assert.Len(t, reply.Pods[1].ContainersAllocations, 2)
+}
+
+func TestGetPodReportsUnmanagedPod(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	podResources := &AllocatedPodResources{Unmanaged: true, UnmanagedReason: "invalid"}
+	mDaemon.On("GetPod", &GetPodRequest{PodId: "pod"}).Return(podResources, nil)
+
+	reply, err := client.GetPod(ctx, &GetPodRequest{PodId: "pod"})
+
+	require.Nil(t, err)
+	assert.True(t, reply.Unmanaged)
+	assert.Equal(t, "invalid", reply.UnmanagedReason)
+}
+
+func TestGetPodReportsCgroupPath(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	podResources := createTestCPUAllocation(createContainers(1, []Placement{Placement_DEFAULT}))
+	podResources.ContainerResources[0].CgroupPath = "/sys/fs/cgroup/kubepods/pod/cid"
+	podResources.ContainerResources[0].EffectiveCPUSet = "1,2"
+	mDaemon.On("GetPod", &GetPodRequest{PodId: "pod"}).Return(podResources, nil)
+
+	reply, err := client.GetPod(ctx, &GetPodRequest{PodId: "pod"})
+
+	require.Nil(t, err)
+	require.Len(t, reply.ContainersAllocations, 1)
+	assert.Equal(t, "/sys/fs/cgroup/kubepods/pod/cid", reply.ContainersAllocations[0].CgroupPath)
+	assert.Equal(t, "1,2", reply.ContainersAllocations[0].EffectiveCpuSet)
+}
+
+func TestGetContainerReportsExplanation(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	allocation := &AllocatedContainerResource{
+		ContainerID: "cid",
+		Explanation: "default allocator: first 2 cpus of free range 0-127",
+	}
+	mDaemon.On("GetContainer", &GetContainerRequest{PodId: "pod", ContainerName: "c"}).Return(allocation, nil)
+
+	reply, err := client.GetContainer(ctx, &GetContainerRequest{PodId: "pod", ContainerName: "c"})
+
+	require.Nil(t, err)
+	assert.Equal(t, "default allocator: first 2 cpus of free range 0-127", reply.Allocation.Explanation)
+}
+
+func (m *DaemonMock) CreateNamespaceBucket(_ context.Context, req *CreateNamespaceBucketRequest) (*NamespaceBucketInfo, error) {
+	args := m.Called(req)
+	bucket, _ := args.Get(0).(*NamespaceBucketInfo)
+	return bucket, args.Error(1)
+}
+
+func (m *DaemonMock) DeleteNamespaceBucket(_ context.Context, req *DeleteNamespaceBucketRequest) (*NamespaceBucketInfo, error) {
+	args := m.Called(req)
+	bucket, _ := args.Get(0).(*NamespaceBucketInfo)
+	return bucket, args.Error(1)
+}
+
+func TestCreateNamespaceBucket(t *testing.T) {
+	ctx := context.Background()
+	client, closer, m := NewMockedServer(ctx)
+	defer closer()
+	req := &CreateNamespaceBucketRequest{Namespace: "team-a", MinCpus: 2, CpuWeight: 200}
+	m.On("CreateNamespaceBucket", mock.MatchedBy(func(r *CreateNamespaceBucketRequest) bool {
+		return r.Namespace == "team-a" && r.MinCpus == 2 && r.CpuWeight == 200
+	})).Return(&NamespaceBucketInfo{
+		Namespace: "team-a",
+		Bucket:    1,
+		CPUSet:    []CPUBucket{{StartCPU: 4, EndCPU: 7}},
+		CPUWeight: 200,
+	}, nil)
+
+	reply, err := client.CreateNamespaceBucket(ctx, req)
+
+	require.Nil(t, err)
+	assert.Equal(t, "team-a", reply.Namespace)
+	assert.Equal(t, int32(1), reply.Bucket)
+	require.Len(t, reply.CpuSet, 1)
+	assert.Equal(t, int32(4), reply.CpuSet[0].StartCPU)
+	assert.Equal(t, int32(7), reply.CpuSet[0].EndCPU)
+	assert.Equal(t, uint32(200), reply.CpuWeight)
+	assert.False(t, reply.Released)
+}
+
+func TestDeleteNamespaceBucketFails(t *testing.T) {
+	ctx := context.Background()
+	client, closer, m := NewMockedServer(ctx)
+	defer closer()
+	m.On("DeleteNamespaceBucket", mock.Anything).Return(nil, status.Error(codes.NotFound, "no bucket"))
+
+	_, err := client.DeleteNamespaceBucket(ctx, &DeleteNamespaceBucketRequest{Namespace: "team-a"})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func (m *DaemonMock) GetContainer(_ context.Context, req *GetContainerRequest) (*AllocatedContainerResource, error) {
+	args := m.Called(req)
+	container, _ := args.Get(0).(*AllocatedContainerResource)
+	return container, args.Error(1)
+}
+
+func TestGetContainer(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	mDaemon.On("GetContainer", &GetContainerRequest{PodId: "pod", ContainerName: "app"}).Return(&AllocatedContainerResource{
+		ContainerID: "containerd://app",
+		CPUSet:      []CPUBucket{{StartCPU: 2, EndCPU: 3}},
+		Exclusive:   true,
+	}, nil)
+
+	reply, err := client.GetContainer(ctx, &GetContainerRequest{PodId: "pod", ContainerName: "app"})
+
+	require.Nil(t, err)
+	assert.Equal(t, "pod", reply.PodId)
+	assert.Equal(t, "app", reply.ContainerName)
+	assert.Equal(t, "containerd://app", reply.Allocation.ContainerId)
+	assert.True(t, reply.Allocation.Exclusive)
+	require.Len(t, reply.Allocation.CpuSet, 1)
+	assert.Equal(t, int32(2), reply.Allocation.CpuSet[0].StartCPU)
+}
+
+func TestGetContainerNotFound(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	mDaemon.On("GetContainer", &GetContainerRequest{PodId: "pod", ContainerName: "app"}).
+		Return(nil, status.Error(codes.NotFound, "not found"))
+
+	reply, err := client.GetContainer(ctx, &GetContainerRequest{PodId: "pod", ContainerName: "app"})
+
+	assert.Nil(t, reply)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func (m *DaemonMock) GetDaemonInfo(_ context.Context, req *GetDaemonInfoRequest) (*DaemonInfo, error) {
+	args := m.Called(req)
+	info, _ := args.Get(0).(*DaemonInfo)
+	return info, args.Error(1)
+}
+
+func TestGetDaemonInfo(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	mDaemon.On("GetDaemonInfo", mock.Anything).Return(&DaemonInfo{
+		CgroupVersion: CgroupVersion_CGROUP_V2,
+		Build:         version.Info{Version: "0.2.0", Commit: "4f2a1c", BuildDate: "2023-06-01T10:00:00Z", GoVersion: "go1.20"},
+		PolicyTiers:   []PolicyTierInfo{{Name: "dev", Policy: "observe", Namespaces: []string{"sandbox"}}},
+	}, nil)
+
+	reply, err := client.GetDaemonInfo(ctx, &GetDaemonInfoRequest{})
+
+	require.Nil(t, err)
+	assert.Equal(t, CgroupVersion_CGROUP_V2, reply.CgroupVersion)
+	assert.Equal(t, "0.2.0", reply.Build.Version)
+	assert.Equal(t, "4f2a1c", reply.Build.Commit)
+	assert.Equal(t, "2023-06-01T10:00:00Z", reply.Build.BuildDate)
+	assert.Equal(t, "go1.20", reply.Build.GoVersion)
+	require.Len(t, reply.PolicyTiers, 1)
+	assert.Equal(t, "dev", reply.PolicyTiers[0].Name)
+	assert.Equal(t, "observe", reply.PolicyTiers[0].Policy)
+	assert.Equal(t, []string{"sandbox"}, reply.PolicyTiers[0].Namespaces)
+}
+
+func (m *DaemonMock) GetConfig(_ context.Context, req *GetConfigRequest) (*DaemonConfig, error) {
+	args := m.Called(req)
+	config, _ := args.Get(0).(*DaemonConfig)
+	return config, args.Error(1)
+}
+
+func TestGetConfig(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	mDaemon.On("GetConfig", mock.Anything).Return(&DaemonConfig{
+		Allocator: &AllocatorConfig{
+			Name:      "numa-namespace-exclusive",
+			Exclusive: true,
+			Buckets: []CPUBucketConfig{
+				{Bucket: 0, CPUSet: []CPUBucket{{StartCPU: 0, EndCPU: 3}}, Namespaces: []string{"a", "b"}},
+			},
+			NamespaceMemoryNodes: map[string]string{"a": "0"},
+		},
+		ReservedCPUs: []CPUBucket{{StartCPU: 4, EndCPU: 4}},
+	}, nil)
+
+	reply, err := client.GetConfig(ctx, &GetConfigRequest{})
+
+	require.Nil(t, err)
+	require.NotNil(t, reply.Allocator)
+	assert.Equal(t, "numa-namespace-exclusive", reply.Allocator.Name)
+	assert.True(t, reply.Allocator.Exclusive)
+	require.Len(t, reply.Allocator.Buckets, 1)
+	assert.Equal(t, []string{"a", "b"}, reply.Allocator.Buckets[0].Namespaces)
+	assert.Equal(t, int32(3), reply.Allocator.Buckets[0].CpuSet[0].EndCPU)
+	assert.Equal(t, map[string]string{"a": "0"}, reply.Allocator.NamespaceMemoryNodes)
+	require.Len(t, reply.ReservedCpus, 1)
+	assert.Equal(t, int32(4), reply.ReservedCpus[0].StartCPU)
+	assert.Empty(t, reply.ExcludedCpus)
+}
+
+func TestGetConfigWithoutAllocator(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	mDaemon.On("GetConfig", mock.Anything).Return(&DaemonConfig{}, nil)
+
+	reply, err := client.GetConfig(ctx, &GetConfigRequest{})
+
+	require.Nil(t, err)
+	assert.Nil(t, reply.Allocator)
+}
+
+func (m *DaemonMock) GetAllocations(_ context.Context, req *GetAllocationsRequest) (*Allocations, error) {
+	args := m.Called(req)
+	allocations, _ := args.Get(0).(*Allocations)
+	return allocations, args.Error(1)
+}
+
+func TestGetAllocations(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	mDaemon.On("GetAllocations", mock.Anything).Return(&Allocations{
+		Pods: []AllocatedPodResources{
+			{
+				PodID:  "pod",
+				CPUSet: []CPUBucket{{StartCPU: 0, EndCPU: 1}},
+				ContainerResources: []AllocatedContainerResource{
+					{ContainerID: "c", CPUSet: []CPUBucket{{StartCPU: 0, EndCPU: 1}}, Exclusive: true},
+				},
+			},
+		},
+		AvailableCPUs:  []CPUBucket{{StartCPU: 2, EndCPU: 3}},
+		Cpus:           4,
+		ThreadsPerCore: 2,
+		NumaNodes: []NumaNodeAllocation{
+			{Node: 0, CPUSet: []CPUBucket{{StartCPU: 0, EndCPU: 3}}, AvailableCPUs: []CPUBucket{{StartCPU: 2, EndCPU: 3}}},
+		},
+		CPUPools: []CPUPoolInfo{{Name: "dpdk", NumaNode: 0, CPUSet: []CPUBucket{{StartCPU: 3, EndCPU: 3}}}},
+	}, nil)
+
+	reply, err := client.GetAllocations(ctx, &GetAllocationsRequest{})
+
+	require.Nil(t, err)
+	require.Len(t, reply.Pods, 1)
+	assert.Equal(t, "pod", reply.Pods[0].PodId)
+	require.Len(t, reply.Pods[0].ContainersAllocations, 1)
+	assert.Equal(t, "c", reply.Pods[0].ContainersAllocations[0].ContainerId)
+	assert.Equal(t, int32(1), reply.Pods[0].ContainersAllocations[0].CpuSet[0].EndCPU)
+	require.Len(t, reply.AvailableCpus, 1)
+	assert.Equal(t, int32(2), reply.AvailableCpus[0].StartCPU)
+	assert.Equal(t, int32(4), reply.Topology.Cpus)
+	assert.Equal(t, int32(2), reply.Topology.ThreadsPerCore)
+	require.Len(t, reply.Topology.NumaNodes, 1)
+	assert.Equal(t, int32(3), reply.Topology.NumaNodes[0].CpuSet[0].EndCPU)
+	assert.Equal(t, int32(2), reply.Topology.NumaNodes[0].AvailableCpus[0].StartCPU)
+	require.Len(t, reply.CpuPools, 1)
+	assert.Equal(t, "dpdk", reply.CpuPools[0].Name)
+	assert.Equal(t, int32(3), reply.CpuPools[0].CpuSet[0].StartCPU)
+}
+
+func TestGetAllocationsError(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	mDaemon.On("GetAllocations", mock.Anything).Return(nil, status.Error(codes.Unavailable, "no state"))
+
+	_, err := client.GetAllocations(ctx, &GetAllocationsRequest{})
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func (m *DaemonMock) ReserveCPUPool(_ context.Context, req *ReserveCPUPoolRequest) (*CPUPoolInfo, error) {
+	args := m.Called(req)
+	pool, _ := args.Get(0).(*CPUPoolInfo)
+	return pool, args.Error(1)
+}
+
+func (m *DaemonMock) ReleaseCPUPool(_ context.Context, req *ReleaseCPUPoolRequest) (*CPUPoolInfo, error) {
+	args := m.Called(req)
+	pool, _ := args.Get(0).(*CPUPoolInfo)
+	return pool, args.Error(1)
+}
+
+func TestReserveCPUPool(t *testing.T) {
+	ctx := context.Background()
+	client, closer, m := NewMockedServer(ctx)
+	defer closer()
+	m.On("ReserveCPUPool", mock.MatchedBy(func(r *ReserveCPUPoolRequest) bool {
+		return r.Name == "dpdk" && r.Cpus == 4 && len(r.NumaNodes) == 1 && r.NumaNodes[0] == 1
+	})).Return(&CPUPoolInfo{
+		Name:     "dpdk",
+		NumaNode: 1,
+		CPUSet:   []CPUBucket{{StartCPU: 4, EndCPU: 7}},
+	}, nil)
+
+	reply, err := client.ReserveCPUPool(ctx, &ReserveCPUPoolRequest{Name: "dpdk", Cpus: 4, NumaNodes: []uint32{1}})
+
+	require.Nil(t, err)
+	assert.Equal(t, "dpdk", reply.Name)
+	assert.Equal(t, int32(1), reply.NumaNode)
+	require.Len(t, reply.CpuSet, 1)
+	assert.Equal(t, int32(4), reply.CpuSet[0].StartCPU)
+	assert.Equal(t, int32(7), reply.CpuSet[0].EndCPU)
+	assert.False(t, reply.Released)
+}
+
+func TestReleaseCPUPoolFails(t *testing.T) {
+	ctx := context.Background()
+	client, closer, m := NewMockedServer(ctx)
+	defer closer()
+	m.On("ReleaseCPUPool", mock.Anything).Return(nil, status.Error(codes.NotFound, "no pool"))
+
+	_, err := client.ReleaseCPUPool(ctx, &ReleaseCPUPoolRequest{Name: "dpdk"})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func (m *DaemonMock) SetNamespaceConfig(_ context.Context, req *SetNamespaceConfigRequest) (*NamespaceConfigInfo, error) {
+	args := m.Called(req)
+	config, _ := args.Get(0).(*NamespaceConfigInfo)
+	return config, args.Error(1)
+}
+
+func (m *DaemonMock) DeleteNamespaceConfig(
+	_ context.Context,
+	req *DeleteNamespaceConfigRequest,
+) (*NamespaceConfigInfo, error) {
+	args := m.Called(req)
+	config, _ := args.Get(0).(*NamespaceConfigInfo)
+	return config, args.Error(1)
+}
+
+func TestSetNamespaceConfig(t *testing.T) {
+	ctx := context.Background()
+	client, closer, m := NewMockedServer(ctx)
+	defer closer()
+	m.On("SetNamespaceConfig", mock.MatchedBy(func(r *SetNamespaceConfigRequest) bool {
+		return r.Namespace == "shop" && r.Allocator == "scatter" && !r.Exclusive && r.BucketCpus == 2
+	})).Return(&NamespaceConfigInfo{Namespace: "shop", Allocator: "scatter", BucketCpus: 2}, nil)
+
+	reply, err := client.SetNamespaceConfig(ctx, &SetNamespaceConfigRequest{
+		Namespace:  "shop",
+		Allocator:  "scatter",
+		BucketCpus: 2,
+	})
+
+	require.Nil(t, err)
+	assert.Equal(t, "shop", reply.Namespace)
+	assert.Equal(t, "scatter", reply.Allocator)
+	assert.False(t, reply.Exclusive)
+	assert.Equal(t, uint32(2), reply.BucketCpus)
+	assert.False(t, reply.Deleted)
+}
+
+func TestDeleteNamespaceConfigFails(t *testing.T) {
+	ctx := context.Background()
+	client, closer, m := NewMockedServer(ctx)
+	defer closer()
+	m.On("DeleteNamespaceConfig", mock.Anything).Return(nil, status.Error(codes.NotFound, "not configured"))
+
+	_, err := client.DeleteNamespaceConfig(ctx, &DeleteNamespaceConfigRequest{Namespace: "shop"})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func (m *DaemonMock) ListUsage(_ context.Context, req *ListUsageRequest) ([]NamespaceUsageInfo, error) {
+	args := m.Called(req)
+	usage, _ := args.Get(0).([]NamespaceUsageInfo)
+	return usage, args.Error(1)
+}
+
+func TestListUsage(t *testing.T) {
+	ctx := context.Background()
+	client, closer, m := NewMockedServer(ctx)
+	defer closer()
+	m.On("ListUsage", mock.MatchedBy(func(r *ListUsageRequest) bool {
+		return r.Namespace == "shop"
+	})).Return([]NamespaceUsageInfo{{
+		Namespace:           "shop",
+		PinnedCPUSeconds:    120,
+		RequestedCPUSeconds: 60,
+		PinnedCpus:          2,
+		RequestedCpus:       1,
+	}}, nil)
+
+	reply, err := client.ListUsage(ctx, &ListUsageRequest{Namespace: "shop"})
+
+	require.Nil(t, err)
+	require.Len(t, reply.Namespaces, 1)
+	assert.Equal(t, "shop", reply.Namespaces[0].Namespace)
+	assert.Equal(t, 120.0, reply.Namespaces[0].PinnedCpuSeconds)
+	assert.Equal(t, 60.0, reply.Namespaces[0].RequestedCpuSeconds)
+	assert.Equal(t, int32(2), reply.Namespaces[0].PinnedCpus)
+	assert.Equal(t, int32(1), reply.Namespaces[0].RequestedCpus)
+}
+
+func TestListUsageFails(t *testing.T) {
+	ctx := context.Background()
+	client, closer, m := NewMockedServer(ctx)
+	defer closer()
+	m.On("ListUsage", mock.Anything).Return(nil, status.Error(codes.Unavailable, "no state"))
+
+	_, err := client.ListUsage(ctx, &ListUsageRequest{})
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func (m *DaemonMock) MigrateContainer(_ context.Context, req *MigrateContainerRequest) (*ContainerAllocation, error) {
+	args := m.Called(req)
+	container, _ := args.Get(0).(*ContainerAllocation)
+	return container, args.Error(1)
+}
+
+func TestMigrateContainer(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	req := &MigrateContainerRequest{ContainerId: "cid", TargetNode: 1}
+	mDaemon.On("MigrateContainer", mock.MatchedBy(func(r *MigrateContainerRequest) bool {
+		return r.ContainerId == req.ContainerId && r.TargetNode == req.TargetNode
+	})).Return(&ContainerAllocation{
+		PodID:         "pod",
+		ContainerName: "app",
+		Allocation: AllocatedContainerResource{
+			ContainerID: "cid",
+			CPUSet:      []CPUBucket{{StartCPU: 4, EndCPU: 5}},
+			MemoryNodes: "1",
+			Exclusive:   true,
+		},
+	}, nil)
+
+	reply, err := client.MigrateContainer(ctx, req)
+
+	require.Nil(t, err)
+	assert.Equal(t, "pod", reply.PodId)
+	assert.Equal(t, "app", reply.ContainerName)
+	assert.Equal(t, "cid", reply.Allocation.ContainerId)
+	assert.Equal(t, "1", reply.Allocation.MemoryNodes)
+	require.Len(t, reply.Allocation.CpuSet, 1)
+	assert.Equal(t, int32(4), reply.Allocation.CpuSet[0].StartCPU)
+}
+
+func TestMigrateContainerFails(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	mDaemon.On("MigrateContainer", mock.Anything).Return(nil, status.Error(codes.NotFound, "no container"))
+
+	_, err := client.MigrateContainer(ctx, &MigrateContainerRequest{ContainerId: "cid"})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func (m *DaemonMock) PlanDefragmentation(
+	_ context.Context,
+	req *PlanDefragmentationRequest,
+) (*DefragmentationPlan, error) {
+	args := m.Called(req)
+	plan, _ := args.Get(0).(*DefragmentationPlan)
+	return plan, args.Error(1)
+}
+
+func TestPlanDefragmentation(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	req := &PlanDefragmentationRequest{Cpus: 4, Execute: true}
+	mDaemon.On("PlanDefragmentation", mock.MatchedBy(func(r *PlanDefragmentationRequest) bool {
+		return r.Cpus == req.Cpus && r.Execute
+	})).Return(&DefragmentationPlan{
+		Node: 1,
+		Migrations: []ContainerMigration{
+			{PodID: "pod", ContainerName: "app", ContainerID: "cid", Cpus: 2, SourceNode: 1, TargetNode: 0},
+		},
+		Executed: true,
+	}, nil)
+
+	reply, err := client.PlanDefragmentation(ctx, req)
+
+	require.Nil(t, err)
+	assert.Equal(t, int32(1), reply.Node)
+	assert.True(t, reply.Executed)
+	require.Len(t, reply.Migrations, 1)
+	m := reply.Migrations[0]
+	assert.Equal(t, "pod", m.PodId)
+	assert.Equal(t, "app", m.ContainerName)
+	assert.Equal(t, "cid", m.ContainerId)
+	assert.Equal(t, int32(2), m.Cpus)
+	assert.Equal(t, int32(1), m.SourceNode)
+	assert.Equal(t, int32(0), m.TargetNode)
+}
+
+func TestPlanDefragmentationFails(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	mDaemon.On("PlanDefragmentation", mock.Anything).Return(nil, status.Error(codes.Unavailable, "no plan"))
+
+	_, err := client.PlanDefragmentation(ctx, &PlanDefragmentationRequest{Cpus: 16})
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -3,9 +3,12 @@ package ctlplaneapi
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"resourcemanagement.controlplane/pkg/version"
 )
 
 // CPUBucket A cpu bucket describes a bucket of cpus by a given start CPU ID and
@@ -18,79 +21,686 @@ type CPUBucket struct {
 
 // AllocatedContainerResource represents single container allocation.
 type AllocatedContainerResource struct {
-	ContainerID string
-	CPUSet      []CPUBucket
+	ContainerID     string
+	CPUSet          []CPUBucket
+	QoS             QoSClass
+	MemoryNodes     string    // empty if memory is not pinned
+	Exclusive       bool      // false if container runs in shared pool
+	AllocatedAt     time.Time // zero if cpus are not allocated
+	CgroupPath      string    // empty if cgroup of the container was not written
+	ReleasedPool    string    // pool the freed cpus returned to, set only for deleted containers
+	Explanation     string    // why the allocator selected the cpus, empty if not recorded
+	EffectiveCPUSet string    // cpus read back from the cgroup after the last update, empty if not verified
 }
 
 // AllocatedPodResources repesents pod allocation, together with container sub-allocation.
 type AllocatedPodResources struct {
+	PodID              string
 	CPUSet             []CPUBucket
 	ContainerResources []AllocatedContainerResource
+	Unmanaged          bool              // pod failed validation and runs in shared pool
+	UnmanagedReason    string            // validation error of unmanaged pod
+	ContainerResults   []ContainerResult // result of each container, set only by CreatePod and UpdatePod
+}
+
+// ContainerResult represents result of a single container of a pod request.
+type ContainerResult struct {
+	ContainerID string
+	Status      ContainerStatus
+	Reason      string // error of failed container, otherwise what was done with the container
+}
+
+// ContainerAllocation represents allocation of a container together with its pod and name.
+type ContainerAllocation struct {
+	PodID         string
+	ContainerName string
+	Allocation    AllocatedContainerResource
+}
+
+// ContainerMigration represents move of a container to another numa node.
+type ContainerMigration struct {
+	PodID         string
+	ContainerName string
+	ContainerID   string
+	Cpus          int // number of cpus of the container
+	SourceNode    int // numa node the container leaves
+	TargetNode    int // numa node the container is moved to
+}
+
+// DefragmentationPlan represents container migrations making room for a request on a single numa node.
+type DefragmentationPlan struct {
+	Node       int                  // numa node fitting the request after the migrations
+	Migrations []ContainerMigration // in order of execution, empty if the request already fits
+	Executed   bool                 // migrations were executed
+}
+
+// NamespaceBucketInfo represents cpu bucket of a namespace.
+type NamespaceBucketInfo struct {
+	Namespace string
+	Bucket    int         // index of the bucket
+	CPUSet    []CPUBucket // all cpus of the bucket
+	CPUWeight uint64      // cpu weight per requested cpu, 0 if default
+	Released  bool        // bucket released by the delete request
+}
+
+// CPUPoolInfo represents cpu pool reserved for workloads running outside of kubernetes.
+type CPUPoolInfo struct {
+	Name     string
+	NumaNode int         // numa node the cpus of the pool belong to
+	CPUSet   []CPUBucket // cpus of the pool
+	Released bool        // pool released by the release request
+}
+
+// NamespaceConfigInfo represents configuration of allocations of new pods of a namespace.
+type NamespaceConfigInfo struct {
+	Namespace  string
+	Allocator  string // allocator of the namespace, empty for the daemon allocator
+	Exclusive  bool   // false if guaranteed containers run in the shared pool
+	BucketCpus int    // minimal cpus of the namespace bucket, 0 if not reserved
+	Deleted    bool   // configuration removed by the delete request
+}
+
+// NamespaceUsageInfo represents cpu time of exclusive cpus pinned to guaranteed containers of a namespace.
+type NamespaceUsageInfo struct {
+	Namespace           string
+	PinnedCPUSeconds    float64 // exclusive cpus allocated to guaranteed containers times allocation duration
+	RequestedCPUSeconds float64 // cpus requested by the containers times allocation duration
+	PinnedCpus          int     // exclusive cpus allocated to guaranteed containers now
+	RequestedCpus       int     // cpus requested by the containers now
+}
+
+// PolicyTierInfo represents policy tier routing pods of namespaces to their own policy.
+type PolicyTierInfo struct {
+	Name       string
+	Policy     string   // name of the policy, eg. static or observe
+	Namespaces []string // sorted namespaces assigned to the tier
+}
+
+// DaemonInfo represents information about the daemon.
+type DaemonInfo struct {
+	CgroupVersion CgroupVersion    // cgroup version detected by the daemon at startup
+	Build         version.Info     // build of the running daemon
+	PolicyTiers   []PolicyTierInfo // empty if all pods are managed by the default policy
+}
+
+// CPUBucketConfig represents cpu bucket of numa-namespace allocators with namespaces assigned to it.
+type CPUBucketConfig struct {
+	Bucket     int         // index of the bucket
+	CPUSet     []CPUBucket // all cpus of the bucket
+	Namespaces []string    // sorted namespaces assigned to the bucket
+}
+
+// AllocatorConfig represents live configuration of the allocator.
+type AllocatorConfig struct {
+	Name                 string            // allocator selected with -allocator, eg. numa-namespace
+	Exclusive            bool              // cpus of guaranteed containers are not shared
+	MemoryPinning        bool              // memory pinned to numa nodes of container cpus by default
+	BurstableSoftPinning bool              // burstable containers pinned to cpus sized to their request
+	BucketWeights        bool              // cpu weight of shared containers proportional to their request
+	Buckets              []CPUBucketConfig // namespace cpu buckets, empty if the allocator has none
+	NamespaceMemoryNodes map[string]string // memory nodes configured for namespaces
+	FullCores            bool              // cpus of guaranteed containers taken as whole physical cores
+}
+
+// DaemonConfig represents live configuration of the daemon.
+type DaemonConfig struct {
+	Allocator    *AllocatorConfig // nil if the policy does not report its allocator
+	ReservedCPUs []CPUBucket      // cpus outside of kubepods cgroup, never allocated
+	ExcludedCPUs []CPUBucket      // cpus removed from daemon management
+	ManagedCPUs  []CPUBucket      // cpus managed by this daemon instance, empty if all
+	KubeletCPUs  []CPUBucket      // cpus exclusively assigned by kubelet cpu manager
+}
+
+// NumaNodeAllocation represents cpus of a numa node and those not allocated to any container.
+type NumaNodeAllocation struct {
+	Node          int
+	CPUSet        []CPUBucket // all cpus of the numa node managed by the daemon
+	AvailableCPUs []CPUBucket // cpus of the numa node not allocated to any container
+}
+
+// Allocations represents snapshot of the daemon state.
+type Allocations struct {
+	Pods           []AllocatedPodResources // sorted by pod id
+	AvailableCPUs  []CPUBucket             // cpus not allocated to any container
+	Cpus           int                     // number of cpus in the topology, 0 if not discovered yet
+	ThreadsPerCore int
+	NumaNodes      []NumaNodeAllocation
+	CPUPools       []CPUPoolInfo // reserved cpu pools, sorted by name
 }
 
 // CtlPlane is a interface to be implmented by the Daemon.
 type CtlPlane interface {
 	// Creates a pod with given resource allocation for the parent pod and all
-	CreatePod(req *CreatePodRequest) (*AllocatedPodResources, error)
-	// Deletes pod and children containers allocations
-	DeletePod(req *DeletePodRequest) error
+	CreatePod(ctx context.Context, req *CreatePodRequest) (*AllocatedPodResources, error)
+	// Deletes pod and children containers allocations, returns allocations freed by the deletion
+	DeletePod(ctx context.Context, req *DeletePodRequest) (*AllocatedPodResources, error)
 	// Creates a pod with given resource allocation for the parent pod and all
-	UpdatePod(req *UpdatePodRequest) (*AllocatedPodResources, error)
+	UpdatePod(ctx context.Context, req *UpdatePodRequest) (*AllocatedPodResources, error)
+	// Returns current allocation of the pod
+	GetPod(ctx context.Context, req *GetPodRequest) (*AllocatedPodResources, error)
+	// Returns current allocations of all pods
+	ListPods(ctx context.Context, req *ListPodsRequest) ([]AllocatedPodResources, error)
+	// Returns current allocation of the container selected by pod id and container name
+	GetContainer(ctx context.Context, req *GetContainerRequest) (*AllocatedContainerResource, error)
+	// Creates cpu bucket of the namespace before any pod of the namespace arrives
+	CreateNamespaceBucket(ctx context.Context, req *CreateNamespaceBucketRequest) (*NamespaceBucketInfo, error)
+	// Releases cpu bucket of the namespace
+	DeleteNamespaceBucket(ctx context.Context, req *DeleteNamespaceBucketRequest) (*NamespaceBucketInfo, error)
+	// Returns information about the daemon
+	GetDaemonInfo(ctx context.Context, req *GetDaemonInfoRequest) (*DaemonInfo, error)
+	// Returns live configuration of the daemon and its allocator
+	GetConfig(ctx context.Context, req *GetConfigRequest) (*DaemonConfig, error)
+	// Returns snapshot of allocations of all pods, available cpus and topology summary
+	GetAllocations(ctx context.Context, req *GetAllocationsRequest) (*Allocations, error)
+	// Reserves named cpu pool which no pod allocation may use
+	ReserveCPUPool(ctx context.Context, req *ReserveCPUPoolRequest) (*CPUPoolInfo, error)
+	// Releases reserved cpu pool
+	ReleaseCPUPool(ctx context.Context, req *ReleaseCPUPoolRequest) (*CPUPoolInfo, error)
+	// Configures allocations of new pods of the namespace
+	SetNamespaceConfig(ctx context.Context, req *SetNamespaceConfigRequest) (*NamespaceConfigInfo, error)
+	// Removes configuration of the namespace
+	DeleteNamespaceConfig(ctx context.Context, req *DeleteNamespaceConfigRequest) (*NamespaceConfigInfo, error)
+	// Returns cpu seconds of exclusive cpus pinned to and requested by guaranteed containers of namespaces
+	ListUsage(ctx context.Context, req *ListUsageRequest) ([]NamespaceUsageInfo, error)
+	// Moves cpus and memory of the running container to another numa node
+	MigrateContainer(ctx context.Context, req *MigrateContainerRequest) (*ContainerAllocation, error)
+	// Plans container migrations making room for the request on a single numa node, optionally executes them
+	PlanDefragmentation(ctx context.Context, req *PlanDefragmentationRequest) (*DefragmentationPlan, error)
 }
 
 // Server implements CtlPlane GRPC Server protocol.
 type Server struct {
 	UnimplementedControlPlaneServer
-	ctl CtlPlane
+	ctl            CtlPlane
+	failures       *podFailures
+	requestTimeout time.Duration
 }
 
 // NewServer initializes new ctlplaneapi.Server.
-func NewServer(c CtlPlane) *Server {
-	return &Server{
-		ctl: c,
+func NewServer(c CtlPlane, opts ...ServerOption) *Server {
+	s := &Server{
+		ctl:      c,
+		failures: newPodFailures(),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
-// DeletePod deletes pod from allocator.
+// DeletePod deletes pod from allocator. The reply lists cpus freed by the deletion, together with pools
+// they returned to.
 func (d *Server) DeletePod(ctx context.Context, cP *DeletePodRequest) (*PodAllocationReply, error) {
-	if err := d.ctl.DeletePod(cP); err != nil {
-		return nil, status.Error(codes.Unavailable, err.Error())
+	d.failures.forget(cP.PodId)
+	ctx, cancel := d.requestContext(ctx)
+	defer cancel()
+	podResources, err := d.ctl.DeletePod(ctx, cP)
+	if err != nil {
+		return nil, statusError(err)
 	}
 	reply := PodAllocationReply{
 		PodId:      cP.PodId,
 		AllocState: AllocationState_DELETED,
 	}
+	if podResources != nil {
+		reply.ContainersAllocations = toGRPCHelper4Containers(podResources.ContainerResources, time.Now())
+		for _, c := range reply.ContainersAllocations {
+			c.AllocState = AllocationState_DELETED
+		}
+	}
 	return &reply, nil
 }
 
-// CreatePod creates pod inside allocator.
+// CreatePod creates pod inside allocator. Failures of dry runs are not counted as failures of the pod.
 func (d *Server) CreatePod(ctx context.Context, cP *CreatePodRequest) (*PodAllocationReply, error) {
-	podResources, err := d.ctl.CreatePod(cP)
+	ctx, cancel := d.requestContext(ctx)
+	defer cancel()
+	podResources, err := d.ctl.CreatePod(ctx, cP)
+	if err != nil && cP.DryRun {
+		return nil, statusError(err)
+	}
 	if err != nil {
-		return nil, status.Error(codes.Unavailable, err.Error())
+		// containers failed by the daemon are reported with the error, see PartialReply
+		statusErr := d.podStatusError(cP.PodId, err)
+		return nil, withPartialReply(statusErr, partialReply(cP.PodId, AllocationState_CREATED, podResources))
+	}
+	if !cP.DryRun {
+		d.failures.forget(cP.PodId)
 	}
 	reply := PodAllocationReply{
-		PodId:      cP.PodId,
-		CpuSet:     toGRPCHelper4CPUSet(podResources.CPUSet),
-		AllocState: AllocationState_CREATED,
+		PodId:                 cP.PodId,
+		CpuSet:                toGRPCHelper4CPUSet(podResources.CPUSet),
+		ContainersAllocations: toGRPCHelper4Containers(podResources.ContainerResources, time.Now()),
+		AllocState:            AllocationState_CREATED,
+		Unmanaged:             podResources.Unmanaged,
+		UnmanagedReason:       podResources.UnmanagedReason,
+		DryRun:                cP.DryRun,
+		ContainerStatuses:     toGRPCHelper4ContainerResults(podResources.ContainerResults),
+	}
+	return &reply, nil
+}
+
+// CreatePods creates multiple pods inside allocator. Pods are created one by one, failure of one pod
+// does not affect the others and is reported in its result.
+func (d *Server) CreatePods(ctx context.Context, cP *CreatePodsRequest) (*CreatePodsReply, error) {
+	reply := CreatePodsReply{
+		Results: make([]*CreatePodResult, 0, len(cP.Pods)),
+	}
+	for _, pod := range cP.Pods {
+		podReply, err := d.CreatePod(ctx, pod)
+		result := CreatePodResult{PodId: pod.GetPodId()}
+		if err != nil {
+			result.Error = err.Error()
+			if info := ErrorInfo(err); info != nil {
+				result.ErrorReason, result.ErrorMetadata = info.Reason, info.Metadata
+			}
+		} else {
+			result.Reply = podReply
+		}
+		reply.Results = append(reply.Results, &result)
 	}
 	return &reply, nil
 }
 
 // UpdatePod reallocates all changed containers of a pod.
 func (d *Server) UpdatePod(ctx context.Context, cP *UpdatePodRequest) (*PodAllocationReply, error) {
-	podResources, err := d.ctl.UpdatePod(cP)
+	ctx, cancel := d.requestContext(ctx)
+	defer cancel()
+	podResources, err := d.ctl.UpdatePod(ctx, cP)
+	if err != nil && cP.DryRun {
+		return nil, statusError(err)
+	}
 	if err != nil {
-		return nil, status.Error(codes.Unavailable, err.Error())
+		// containers failed by the daemon are reported with the error, see PartialReply
+		statusErr := d.podStatusError(cP.PodId, err)
+		return nil, withPartialReply(statusErr, partialReply(cP.PodId, AllocationState_UPDATED, podResources))
+	}
+	if !cP.DryRun {
+		d.failures.forget(cP.PodId)
 	}
 	reply := PodAllocationReply{
-		PodId:      cP.PodId,
-		CpuSet:     toGRPCHelper4CPUSet(podResources.CPUSet),
-		AllocState: AllocationState_UPDATED,
+		PodId:                 cP.PodId,
+		CpuSet:                toGRPCHelper4CPUSet(podResources.CPUSet),
+		ContainersAllocations: toGRPCHelper4Containers(podResources.ContainerResources, time.Now()),
+		AllocState:            AllocationState_UPDATED,
+		Unmanaged:             podResources.Unmanaged,
+		UnmanagedReason:       podResources.UnmanagedReason,
+		DryRun:                cP.DryRun,
+		ContainerStatuses:     toGRPCHelper4ContainerResults(podResources.ContainerResults),
+	}
+	return &reply, nil
+}
+
+// GetPod returns current allocation of a pod.
+func (d *Server) GetPod(ctx context.Context, cP *GetPodRequest) (*PodAllocationReply, error) {
+	podResources, err := d.ctl.GetPod(ctx, cP)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toGRPCHelper4Pod(cP.PodId, podResources, time.Now()), nil
+}
+
+// ListPods returns current allocations of all pods.
+func (d *Server) ListPods(ctx context.Context, cP *ListPodsRequest) (*ListPodsReply, error) {
+	pods, err := d.ctl.ListPods(ctx, cP)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	now := time.Now()
+	reply := ListPodsReply{
+		Pods: make([]*PodAllocationReply, 0, len(pods)),
+	}
+	for i := range pods {
+		reply.Pods = append(reply.Pods, toGRPCHelper4Pod(pods[i].PodID, &pods[i], now))
+	}
+	return &reply, nil
+}
+
+// GetContainer returns current allocation of a container selected by pod id and container name.
+func (d *Server) GetContainer(ctx context.Context, cP *GetContainerRequest) (*ContainerAllocationReply, error) {
+	container, err := d.ctl.GetContainer(ctx, cP)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &ContainerAllocationReply{
+		PodId:         cP.PodId,
+		ContainerName: cP.ContainerName,
+		Allocation:    toGRPCHelper4Containers([]AllocatedContainerResource{*container}, time.Now())[0],
+	}, nil
+}
+
+// CreateNamespaceBucket creates cpu bucket of a namespace.
+func (d *Server) CreateNamespaceBucket(
+	ctx context.Context,
+	cP *CreateNamespaceBucketRequest,
+) (*NamespaceBucketReply, error) {
+	bucket, err := d.ctl.CreateNamespaceBucket(ctx, cP)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toGRPCHelper4NamespaceBucket(bucket), nil
+}
+
+// DeleteNamespaceBucket releases cpu bucket of a namespace.
+func (d *Server) DeleteNamespaceBucket(
+	ctx context.Context,
+	cP *DeleteNamespaceBucketRequest,
+) (*NamespaceBucketReply, error) {
+	bucket, err := d.ctl.DeleteNamespaceBucket(ctx, cP)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toGRPCHelper4NamespaceBucket(bucket), nil
+}
+
+// GetDaemonInfo returns information about the daemon.
+func (d *Server) GetDaemonInfo(ctx context.Context, cP *GetDaemonInfoRequest) (*DaemonInfoReply, error) {
+	info, err := d.ctl.GetDaemonInfo(ctx, cP)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &DaemonInfoReply{
+		CgroupVersion: info.CgroupVersion,
+		Build: &BuildInfo{
+			Version:   info.Build.Version,
+			Commit:    info.Build.Commit,
+			BuildDate: info.Build.BuildDate,
+			GoVersion: info.Build.GoVersion,
+		},
+		PolicyTiers: toGRPCHelper4PolicyTiers(info.PolicyTiers),
+	}, nil
+}
+
+// GetConfig returns live configuration of the daemon and its allocator.
+func (d *Server) GetConfig(ctx context.Context, cP *GetConfigRequest) (*ConfigReply, error) {
+	config, err := d.ctl.GetConfig(ctx, cP)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &ConfigReply{
+		Allocator:    toGRPCHelper4AllocatorConfig(config.Allocator),
+		ReservedCpus: toGRPCHelper4CPUSet(config.ReservedCPUs),
+		ExcludedCpus: toGRPCHelper4CPUSet(config.ExcludedCPUs),
+		ManagedCpus:  toGRPCHelper4CPUSet(config.ManagedCPUs),
+		KubeletCpus:  toGRPCHelper4CPUSet(config.KubeletCPUs),
+	}, nil
+}
+
+// GetAllocations returns snapshot of the daemon state: allocations of all pods, available cpus and
+// summary of the topology.
+func (d *Server) GetAllocations(ctx context.Context, cP *GetAllocationsRequest) (*AllocationsReply, error) {
+	allocations, err := d.ctl.GetAllocations(ctx, cP)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	now := time.Now()
+	reply := AllocationsReply{
+		Pods:          make([]*PodAllocationReply, 0, len(allocations.Pods)),
+		AvailableCpus: toGRPCHelper4CPUSet(allocations.AvailableCPUs),
+		Topology: &TopologySummary{
+			Cpus:           int32(allocations.Cpus),
+			ThreadsPerCore: int32(allocations.ThreadsPerCore),
+			NumaNodes:      make([]*NumaNodeInfo, 0, len(allocations.NumaNodes)),
+		},
+	}
+	for i := range allocations.Pods {
+		reply.Pods = append(reply.Pods, toGRPCHelper4Pod(allocations.Pods[i].PodID, &allocations.Pods[i], now))
+	}
+	for _, n := range allocations.NumaNodes {
+		reply.Topology.NumaNodes = append(reply.Topology.NumaNodes, &NumaNodeInfo{
+			Node:          int32(n.Node),
+			CpuSet:        toGRPCHelper4CPUSet(n.CPUSet),
+			AvailableCpus: toGRPCHelper4CPUSet(n.AvailableCPUs),
+		})
+	}
+	for i := range allocations.CPUPools {
+		reply.CpuPools = append(reply.CpuPools, toGRPCHelper4CPUPool(&allocations.CPUPools[i]))
 	}
 	return &reply, nil
 }
 
+// ReserveCPUPool reserves named cpu pool for workloads running outside of kubernetes.
+func (d *Server) ReserveCPUPool(ctx context.Context, cP *ReserveCPUPoolRequest) (*CPUPoolReply, error) {
+	pool, err := d.ctl.ReserveCPUPool(ctx, cP)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toGRPCHelper4CPUPool(pool), nil
+}
+
+// ReleaseCPUPool releases reserved cpu pool.
+func (d *Server) ReleaseCPUPool(ctx context.Context, cP *ReleaseCPUPoolRequest) (*CPUPoolReply, error) {
+	pool, err := d.ctl.ReleaseCPUPool(ctx, cP)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toGRPCHelper4CPUPool(pool), nil
+}
+
+// SetNamespaceConfig configures allocations of new pods of the namespace.
+func (d *Server) SetNamespaceConfig(ctx context.Context, cP *SetNamespaceConfigRequest) (*NamespaceConfigReply, error) {
+	config, err := d.ctl.SetNamespaceConfig(ctx, cP)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toGRPCHelper4NamespaceConfig(config), nil
+}
+
+// DeleteNamespaceConfig removes configuration of the namespace.
+func (d *Server) DeleteNamespaceConfig(
+	ctx context.Context,
+	cP *DeleteNamespaceConfigRequest,
+) (*NamespaceConfigReply, error) {
+	config, err := d.ctl.DeleteNamespaceConfig(ctx, cP)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toGRPCHelper4NamespaceConfig(config), nil
+}
+
+// ListUsage returns cpu seconds of exclusive cpus pinned to and requested by guaranteed containers of
+// namespaces.
+func (d *Server) ListUsage(ctx context.Context, cP *ListUsageRequest) (*ListUsageReply, error) {
+	usage, err := d.ctl.ListUsage(ctx, cP)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	reply := ListUsageReply{Namespaces: make([]*NamespaceUsageReply, 0, len(usage))}
+	for _, u := range usage {
+		reply.Namespaces = append(reply.Namespaces, &NamespaceUsageReply{
+			Namespace:           u.Namespace,
+			PinnedCpuSeconds:    u.PinnedCPUSeconds,
+			RequestedCpuSeconds: u.RequestedCPUSeconds,
+			PinnedCpus:          int32(u.PinnedCpus),
+			RequestedCpus:       int32(u.RequestedCpus),
+		})
+	}
+	return &reply, nil
+}
+
+// MigrateContainer moves cpus and memory of a running container to another numa node.
+func (d *Server) MigrateContainer(ctx context.Context, cP *MigrateContainerRequest) (*ContainerAllocationReply, error) {
+	container, err := d.ctl.MigrateContainer(ctx, cP)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &ContainerAllocationReply{
+		PodId:         container.PodID,
+		ContainerName: container.ContainerName,
+		Allocation:    toGRPCHelper4Containers([]AllocatedContainerResource{container.Allocation}, time.Now())[0],
+	}, nil
+}
+
+// PlanDefragmentation returns container migrations making room for given number of cpus on a single numa
+// node.
+func (d *Server) PlanDefragmentation(
+	ctx context.Context,
+	cP *PlanDefragmentationRequest,
+) (*DefragmentationPlanReply, error) {
+	plan, err := d.ctl.PlanDefragmentation(ctx, cP)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	migrations := make([]*ContainerMigrationInfo, 0, len(plan.Migrations))
+	for _, m := range plan.Migrations {
+		migrations = append(migrations, &ContainerMigrationInfo{
+			PodId:         m.PodID,
+			ContainerName: m.ContainerName,
+			ContainerId:   m.ContainerID,
+			Cpus:          int32(m.Cpus),
+			SourceNode:    int32(m.SourceNode),
+			TargetNode:    int32(m.TargetNode),
+		})
+	}
+	return &DefragmentationPlanReply{
+		Node:       int32(plan.Node),
+		Migrations: migrations,
+		Executed:   plan.Executed,
+	}, nil
+}
+
+// statusError converts error to gRPC status error. Errors which carry their own gRPC status keep it,
+// requests aborted by their context are reported as Canceled or DeadlineExceeded, all other are
+// reported as Unavailable.
+func statusError(err error) error {
+	if s, ok := status.FromError(err); ok {
+		return s.Err()
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
+
+// isContextError returns true if the request was aborted by cancellation or deadline of its context.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func toGRPCHelper4Pod(podID string, p *AllocatedPodResources, now time.Time) *PodAllocationReply {
+	return &PodAllocationReply{
+		PodId:                 podID,
+		CpuSet:                toGRPCHelper4CPUSet(p.CPUSet),
+		ContainersAllocations: toGRPCHelper4Containers(p.ContainerResources, now),
+		Unmanaged:             p.Unmanaged,
+		UnmanagedReason:       p.UnmanagedReason,
+		ContainerStatuses:     toGRPCHelper4ContainerResults(p.ContainerResults),
+	}
+}
+
+// partialReply converts resources of the failed pod request, nil if the daemon reported none.
+func partialReply(podID string, state AllocationState, p *AllocatedPodResources) *PodAllocationReply {
+	if p == nil || len(p.ContainerResults) == 0 {
+		return nil
+	}
+	reply := toGRPCHelper4Pod(podID, p, time.Now())
+	reply.AllocState = state
+	return reply
+}
+
+func toGRPCHelper4ContainerResults(results []ContainerResult) []*ContainerStatusInfo {
+	if len(results) == 0 {
+		return nil
+	}
+	res := make([]*ContainerStatusInfo, 0, len(results))
+	for _, it := range results {
+		res = append(res, &ContainerStatusInfo{
+			ContainerId: it.ContainerID,
+			Status:      it.Status,
+			Reason:      it.Reason,
+		})
+	}
+	return res
+}
+
+// toGRPCHelper4Containers converts container allocations, computing their age at the given time.
+func toGRPCHelper4Containers(c []AllocatedContainerResource, now time.Time) []*ContainerAllocationInfo {
+	res := []*ContainerAllocationInfo{}
+	for _, it := range c {
+		info := &ContainerAllocationInfo{
+			ContainerId:     it.ContainerID,
+			CpuSet:          toGRPCHelper4CPUSet(it.CPUSet),
+			Qos:             it.QoS,
+			MemoryNodes:     it.MemoryNodes,
+			Exclusive:       it.Exclusive,
+			CgroupPath:      it.CgroupPath,
+			ReleasedPool:    it.ReleasedPool,
+			Explanation:     it.Explanation,
+			EffectiveCpuSet: it.EffectiveCPUSet,
+		}
+		if !it.AllocatedAt.IsZero() {
+			info.AllocationTimestamp = it.AllocatedAt.Unix()
+			info.AllocationAgeSeconds = int64(now.Sub(it.AllocatedAt) / time.Second)
+		}
+		res = append(res, info)
+	}
+	return res
+}
+
+func toGRPCHelper4NamespaceBucket(b *NamespaceBucketInfo) *NamespaceBucketReply {
+	return &NamespaceBucketReply{
+		Namespace: b.Namespace,
+		Bucket:    int32(b.Bucket),
+		CpuSet:    toGRPCHelper4CPUSet(b.CPUSet),
+		CpuWeight: uint32(b.CPUWeight),
+		Released:  b.Released,
+	}
+}
+
+func toGRPCHelper4CPUPool(p *CPUPoolInfo) *CPUPoolReply {
+	return &CPUPoolReply{
+		Name:     p.Name,
+		NumaNode: int32(p.NumaNode),
+		CpuSet:   toGRPCHelper4CPUSet(p.CPUSet),
+		Released: p.Released,
+	}
+}
+
+func toGRPCHelper4NamespaceConfig(c *NamespaceConfigInfo) *NamespaceConfigReply {
+	return &NamespaceConfigReply{
+		Namespace:  c.Namespace,
+		Allocator:  c.Allocator,
+		Exclusive:  c.Exclusive,
+		BucketCpus: uint32(c.BucketCpus),
+		Deleted:    c.Deleted,
+	}
+}
+
+func toGRPCHelper4AllocatorConfig(a *AllocatorConfig) *AllocatorConfigInfo {
+	if a == nil {
+		return nil
+	}
+	buckets := make([]*CPUBucketConfigInfo, 0, len(a.Buckets))
+	for _, b := range a.Buckets {
+		buckets = append(buckets, &CPUBucketConfigInfo{
+			Bucket:     int32(b.Bucket),
+			CpuSet:     toGRPCHelper4CPUSet(b.CPUSet),
+			Namespaces: b.Namespaces,
+		})
+	}
+	return &AllocatorConfigInfo{
+		Name:                 a.Name,
+		Exclusive:            a.Exclusive,
+		MemoryPinning:        a.MemoryPinning,
+		BurstableSoftPinning: a.BurstableSoftPinning,
+		BucketWeights:        a.BucketWeights,
+		Buckets:              buckets,
+		NamespaceMemoryNodes: a.NamespaceMemoryNodes,
+		FullCores:            a.FullCores,
+	}
+}
+
+func toGRPCHelper4PolicyTiers(tiers []PolicyTierInfo) []*PolicyTier {
+	res := make([]*PolicyTier, 0, len(tiers))
+	for _, t := range tiers {
+		res = append(res, &PolicyTier{Name: t.Name, Policy: t.Policy, Namespaces: t.Namespaces})
+	}
+	return res
+}
+
 func toGRPCHelper4CPUSet(b []CPUBucket) []*CPUSet {
 	res := []*CPUSet{}
 	for _, it := range b {
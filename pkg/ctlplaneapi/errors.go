@@ -0,0 +1,86 @@
+package ctlplaneapi
+
+import (
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the domain of ErrorInfo details of errors reported by the daemon.
+const ErrorDomain = "ctlplane.intel.com"
+
+// Reasons of ErrorInfo details of errors reported by the daemon.
+const (
+	// ReasonRuntimeMismatch is reported when container id does not match the runtime configured in the
+	// daemon. Metadata holds containerIdPrefix and expectedPrefix.
+	ReasonRuntimeMismatch = "RUNTIME_MISMATCH"
+	// ReasonRequestAborted is reported when the pod request is canceled or times out before all its
+	// containers are processed. Metadata holds processedContainers, totalContainers and abortedContainer.
+	ReasonRequestAborted = "REQUEST_ABORTED"
+)
+
+// ErrorInfo returns ErrorInfo details of the gRPC status error, or nil if the error carries none.
+func ErrorInfo(err error) *errdetails.ErrorInfo {
+	s, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+	for _, detail := range s.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+	return nil
+}
+
+// RetryDelay returns how long the daemon asked to wait before retrying the failed request, zero if the
+// error carries no RetryInfo details.
+func RetryDelay(err error) time.Duration {
+	s, ok := status.FromError(err)
+	if !ok {
+		return 0
+	}
+	for _, detail := range s.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			return info.GetRetryDelay().AsDuration()
+		}
+	}
+	return 0
+}
+
+// PartialReply returns reply attached to the error of partially failed CreatePod or UpdatePod request: status
+// of each container of the request and allocations applied before the failure. Nil if the error carries
+// none.
+func PartialReply(err error) *PodAllocationReply {
+	s, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+	for _, detail := range s.Details() {
+		if reply, ok := detail.(*PodAllocationReply); ok {
+			return reply
+		}
+	}
+	return nil
+}
+
+// withPartialReply attaches reply of the partially failed pod request to the status error.
+func withPartialReply(statusErr error, reply *PodAllocationReply) error {
+	s, ok := status.FromError(statusErr)
+	if !ok || reply == nil {
+		return statusErr
+	}
+	withReply, err := s.WithDetails(reply)
+	if err != nil {
+		return statusErr
+	}
+	return withReply.Err()
+}
+
+// IsStaleRequest returns true if the daemon rejected the pod request because it already applied newer
+// version of the pod.
+func IsStaleRequest(err error) bool {
+	return status.Code(err) == codes.Aborted
+}
@@ -0,0 +1,113 @@
+package ctlplaneapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func runtimeMismatchErrorForTest(t *testing.T) error {
+	s, err := status.New(codes.Unavailable, "runtime mismatch").WithDetails(&errdetails.ErrorInfo{
+		Reason:   ReasonRuntimeMismatch,
+		Domain:   ErrorDomain,
+		Metadata: map[string]string{"containerIdPrefix": "docker://", "expectedPrefix": "containerd://"},
+	})
+	require.Nil(t, err)
+	return s.Err()
+}
+
+func TestErrorInfo(t *testing.T) {
+	info := ErrorInfo(runtimeMismatchErrorForTest(t))
+
+	require.NotNil(t, info)
+	assert.Equal(t, ReasonRuntimeMismatch, info.Reason)
+	assert.Equal(t, "docker://", info.Metadata["containerIdPrefix"])
+}
+
+func TestErrorInfoWithoutDetails(t *testing.T) {
+	assert.Nil(t, ErrorInfo(status.Error(codes.Unavailable, "error")))
+	assert.Nil(t, ErrorInfo(errors.New("error"))) //nolint: goerr113
+	assert.Nil(t, ErrorInfo(nil))
+}
+
+func TestCreatePodsReportsErrorReason(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	containers := createContainers(1, []Placement{Placement_DEFAULT})
+	req, _ := createTestPodRequest(t, "fail", "test", mDaemon, Placement_DEFAULT, containers, runtimeMismatchErrorForTest(t))
+
+	reply, err := client.CreatePods(ctx, &CreatePodsRequest{Pods: []*CreatePodRequest{req}})
+
+	require.Nil(t, err)
+	require.Len(t, reply.Results, 1)
+	assert.Equal(t, ReasonRuntimeMismatch, reply.Results[0].ErrorReason)
+	assert.Equal(t, "containerd://", reply.Results[0].ErrorMetadata["expectedPrefix"])
+}
+
+func TestRetryDelay(t *testing.T) {
+	s, err := status.New(codes.Unavailable, "no cpus").WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(3 * time.Second),
+	})
+	require.Nil(t, err)
+
+	assert.Equal(t, 3*time.Second, RetryDelay(s.Err()))
+	assert.Zero(t, RetryDelay(status.Error(codes.Unavailable, "no cpus")))
+	assert.Zero(t, RetryDelay(nil))
+}
+
+func TestIsStaleRequest(t *testing.T) {
+	assert.True(t, IsStaleRequest(status.Error(codes.Aborted, "stale")))
+	assert.False(t, IsStaleRequest(status.Error(codes.Unavailable, "no cpus")))
+	assert.False(t, IsStaleRequest(nil))
+}
+
+// partiallyFailingDaemon fails update of the second container of every pod.
+type partiallyFailingDaemon struct {
+	DaemonMock
+}
+
+func (d *partiallyFailingDaemon) UpdatePod(_ context.Context, req *UpdatePodRequest) (*AllocatedPodResources, error) {
+	return &AllocatedPodResources{
+		PodID:              req.PodId,
+		ContainerResources: []AllocatedContainerResource{{ContainerID: "c1", Exclusive: true}},
+		ContainerResults: []ContainerResult{
+			{ContainerID: "c1", Status: ContainerStatus_SUCCESS, Reason: "allocated"},
+			{ContainerID: "c2", Status: ContainerStatus_FAILED, Reason: "no cpus"},
+		},
+	}, status.Error(codes.Unavailable, "Add errors: no cpus")
+}
+
+func TestPartialReply(t *testing.T) {
+	s := NewServer(&partiallyFailingDaemon{})
+
+	_, err := s.UpdatePod(context.Background(), &UpdatePodRequest{PodId: "pod"})
+
+	require.NotNil(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	reply := PartialReply(err)
+	require.NotNil(t, reply)
+	assert.Equal(t, "pod", reply.PodId)
+	assert.Equal(t, AllocationState_UPDATED, reply.AllocState)
+	require.Len(t, reply.ContainersAllocations, 1)
+	assert.Equal(t, "c1", reply.ContainersAllocations[0].ContainerId)
+	require.Len(t, reply.ContainerStatuses, 2)
+	assert.Equal(t, ContainerStatus_SUCCESS, reply.ContainerStatuses[0].Status)
+	assert.Equal(t, "c2", reply.ContainerStatuses[1].ContainerId)
+	assert.Equal(t, ContainerStatus_FAILED, reply.ContainerStatuses[1].Status)
+	assert.Equal(t, "no cpus", reply.ContainerStatuses[1].Reason)
+}
+
+func TestPartialReplyWithoutDetails(t *testing.T) {
+	assert.Nil(t, PartialReply(runtimeMismatchErrorForTest(t)))
+	assert.Nil(t, PartialReply(errors.New("error"))) //nolint: goerr113
+	assert.Nil(t, PartialReply(nil))
+}
@@ -0,0 +1,94 @@
+package ctlplaneapi
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+const unixPrefix = "unix:"
+
+// ListenAddress is an address the daemon gRPC server listens on: tcp host and port, or unix socket
+// path.
+type ListenAddress struct {
+	Network string // tcp or unix
+	Address string
+}
+
+// ParseListenAddresses parses comma separated list of listen addresses. Addresses prefixed with
+// "unix:" (eg. unix:///run/ctlplane/daemon.sock) are unix sockets, all others are tcp addresses
+// (eg. localhost:31000 or :31000).
+func ParseListenAddresses(addresses string) ([]ListenAddress, error) {
+	res := []ListenAddress{}
+	seen := make(map[ListenAddress]struct{})
+	for _, it := range strings.Split(addresses, ",") {
+		it = strings.TrimSpace(it)
+		addr := ListenAddress{Network: "tcp", Address: it}
+		if strings.HasPrefix(it, unixPrefix) {
+			addr = ListenAddress{Network: "unix", Address: "/" + strings.TrimLeft(it[len(unixPrefix):], "/")}
+		}
+		if it == "" || addr.Address == "/" {
+			return nil, fmt.Errorf("empty listen address in %q", addresses)
+		}
+		if _, ok := seen[addr]; ok {
+			return nil, fmt.Errorf("duplicated listen address %s", it)
+		}
+		seen[addr] = struct{}{}
+		res = append(res, addr)
+	}
+	return res, nil
+}
+
+// String returns the address in the format accepted by ParseListenAddresses, which is also a valid
+// gRPC dial target.
+func (a ListenAddress) String() string {
+	if a.Network == "unix" {
+		return unixPrefix + "//" + a.Address
+	}
+	return a.Address
+}
+
+// Listen opens the listener. Tcp listeners reuse addresses in TIME_WAIT state, so that restarted
+// daemon can bind immediately. Socket file left by a stopped daemon is removed, but the socket of a
+// running one is never taken over.
+func (a ListenAddress) Listen() (net.Listener, error) {
+	if a.Network == "unix" {
+		if err := removeStaleSocket(a.Address); err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen(a.Network, a.Address)
+}
+
+func removeStaleSocket(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("socket %s is used by a running process", path)
+	}
+	return os.Remove(path)
+}
+
+// Serve serves gRPC requests on all listeners and returns when serving on any of them fails.
+func Serve(srv *grpc.Server, listeners []net.Listener) error {
+	errs := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func(l net.Listener) {
+			errs <- srv.Serve(l)
+		}(l)
+	}
+	return <-errs
+}
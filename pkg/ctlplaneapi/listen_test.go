@@ -0,0 +1,85 @@
+package ctlplaneapi
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestParseListenAddresses(t *testing.T) {
+	addresses, err := ParseListenAddresses("localhost:31000, unix:///run/ctlplane/daemon.sock,unix:/tmp/d.sock")
+
+	require.Nil(t, err)
+	assert.Equal(t, []ListenAddress{
+		{Network: "tcp", Address: "localhost:31000"},
+		{Network: "unix", Address: "/run/ctlplane/daemon.sock"},
+		{Network: "unix", Address: "/tmp/d.sock"},
+	}, addresses)
+	assert.Equal(t, "unix:///run/ctlplane/daemon.sock", addresses[1].String())
+}
+
+func TestParseListenAddressesFails(t *testing.T) {
+	for _, addresses := range []string{"", ":31000,", "unix://", ":31000,:31000"} {
+		_, err := ParseListenAddresses(addresses)
+		assert.NotNil(t, err, addresses)
+	}
+}
+
+func TestListenRemovesStaleSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "daemon.sock")
+	addr := ListenAddress{Network: "unix", Address: socket}
+	l, err := addr.Listen()
+	require.Nil(t, err)
+
+	_, err = addr.Listen()
+	assert.NotNil(t, err, "socket of a running server shall not be taken over")
+
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+	l, err = addr.Listen()
+	require.Nil(t, err)
+	l.Close()
+}
+
+func TestListenFailsOnRegularFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.sock")
+	require.Nil(t, os.WriteFile(path, []byte{}, 0o600))
+
+	_, err := ListenAddress{Network: "unix", Address: path}.Listen()
+
+	assert.NotNil(t, err)
+}
+
+func TestServeOnAllListeners(t *testing.T) {
+	addresses := []ListenAddress{
+		{Network: "tcp", Address: "127.0.0.1:0"},
+		{Network: "unix", Address: filepath.Join(t.TempDir(), "daemon.sock")},
+	}
+	listeners := []net.Listener{}
+	for _, addr := range addresses {
+		l, err := addr.Listen()
+		require.Nil(t, err)
+		listeners = append(listeners, l)
+	}
+	srv := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(srv, health.NewServer()) //nolint: nosnakecase
+	go func() { _ = Serve(srv, listeners) }()
+	defer srv.Stop()
+
+	for _, target := range []string{listeners[0].Addr().String(), addresses[1].String()} {
+		conn, err := grpc.Dial(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.Nil(t, err)
+		_, err = grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}) //nolint: nosnakecase
+		assert.Nil(t, err, target)
+		conn.Close()
+	}
+}
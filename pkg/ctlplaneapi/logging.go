@@ -0,0 +1,75 @@
+package ctlplaneapi
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// loggedServicePrefix selects methods logged by the request logging interceptor, so that frequent
+// health checks and reflection calls do not flood the log.
+const loggedServicePrefix = "/ctlplaneapi."
+
+type requestLogger struct {
+	logger     logr.Logger
+	sampleRate float64
+	sample     func() float64 // returns random number in [0, 1)
+}
+
+// NewLoggingInterceptor returns interceptor logging method, pod id, duration and outcome of every
+// control plane request. Failed requests are always logged, successful ones with given probability in
+// range [0, 1]. With verbosity 2 and higher, requests are logged together with their content.
+func NewLoggingInterceptor(logger logr.Logger, sampleRate float64) grpc.UnaryServerInterceptor {
+	l := requestLogger{
+		logger:     logger.WithName("requests"),
+		sampleRate: sampleRate,
+		sample:     rand.Float64, //nolint: gosec
+	}
+	return l.intercept
+}
+
+func (l requestLogger) intercept(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if !strings.HasPrefix(info.FullMethod, loggedServicePrefix) {
+		return handler(ctx, req)
+	}
+	start := time.Now()
+	reply, err := handler(ctx, req)
+	if err == nil && l.sample() >= l.sampleRate {
+		return reply, err
+	}
+
+	keysAndValues := []interface{}{
+		"method", info.FullMethod,
+		"pid", requestPodID(req),
+		"duration", time.Since(start),
+		"code", status.Code(err).String(),
+	}
+	if l.logger.V(2).Enabled() {
+		keysAndValues = append(keysAndValues, "request", req)
+	}
+	if err != nil {
+		l.logger.Error(err, "request failed", keysAndValues...)
+	} else {
+		l.logger.Info("request handled", keysAndValues...)
+	}
+	return reply, err
+}
+
+// requestPodID returns id of the pod the request refers to, empty string for requests not referring to
+// a single pod.
+func requestPodID(req interface{}) string {
+	if r, ok := req.(interface{ GetPodId() string }); ok {
+		return r.GetPodId()
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package ctlplaneapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newRequestLoggerForTest(sampleRate float64, sample float64) (requestLogger, *[]string) {
+	lines := []string{}
+	logger := funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{})
+	return requestLogger{
+		logger:     logger,
+		sampleRate: sampleRate,
+		sample:     func() float64 { return sample },
+	}, &lines
+}
+
+func interceptForTest(l requestLogger, method string, req interface{}, err error) {
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	_, _ = l.intercept(context.Background(), req, info, func(context.Context, interface{}) (interface{}, error) {
+		return nil, err
+	})
+}
+
+func TestLoggingInterceptorLogsRequest(t *testing.T) {
+	l, lines := newRequestLoggerForTest(1, 0.5)
+
+	interceptForTest(l, "/ctlplaneapi.ControlPlane/DeletePod", &DeletePodRequest{PodId: "pod"}, nil)
+
+	require.Len(t, *lines, 1)
+	assert.Contains(t, (*lines)[0], `"method"="/ctlplaneapi.ControlPlane/DeletePod"`)
+	assert.Contains(t, (*lines)[0], `"pid"="pod"`)
+	assert.Contains(t, (*lines)[0], `"code"="OK"`)
+	assert.Contains(t, (*lines)[0], `"duration"=`)
+}
+
+func TestLoggingInterceptorSamplesSuccessfulRequests(t *testing.T) {
+	l, lines := newRequestLoggerForTest(0.1, 0.5)
+
+	interceptForTest(l, "/ctlplaneapi.ControlPlane/GetPod", &GetPodRequest{PodId: "pod"}, nil)
+	assert.Empty(t, *lines)
+
+	interceptForTest(l, "/ctlplaneapi.ControlPlane/GetPod", &GetPodRequest{PodId: "pod"}, status.Error(codes.NotFound, "not found"))
+	require.Len(t, *lines, 1)
+	assert.Contains(t, (*lines)[0], `"code"="NotFound"`)
+}
+
+func TestLoggingInterceptorSkipsOtherServices(t *testing.T) {
+	l, lines := newRequestLoggerForTest(1, 0)
+
+	interceptForTest(l, "/grpc.health.v1.Health/Check", nil, nil)
+
+	assert.Empty(t, *lines)
+}
+
+func TestRequestPodID(t *testing.T) {
+	assert.Equal(t, "pod", requestPodID(&UpdatePodRequest{PodId: "pod"}))
+	assert.Equal(t, "", requestPodID(&ListPodsRequest{}))
+}
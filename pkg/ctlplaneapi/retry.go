@@ -0,0 +1,90 @@
+package ctlplaneapi
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	defaultRetryBackoff    = time.Second
+	defaultMaxRetryBackoff = 5 * time.Minute
+)
+
+// ServerOption configures optional behaviour of the server.
+type ServerOption func(*Server)
+
+// WithRetryBackoff sets retry hints reported with repeated failures of the same pod: the hint is initial
+// after the second consecutive failure and doubles with every next one, up to max. Zero initial disables
+// the hints.
+func WithRetryBackoff(initial, max time.Duration) ServerOption {
+	return func(s *Server) {
+		s.failures.initial = initial
+		s.failures.max = max
+	}
+}
+
+// podFailures counts consecutive failures of create and update requests of each pod.
+type podFailures struct {
+	mu      sync.Mutex
+	counts  map[string]int
+	initial time.Duration
+	max     time.Duration
+}
+
+func newPodFailures() *podFailures {
+	return &podFailures{
+		counts:  make(map[string]int),
+		initial: defaultRetryBackoff,
+		max:     defaultMaxRetryBackoff,
+	}
+}
+
+// failed records failure of the pod request and returns how long the caller shall wait before retrying
+// it, zero if the failure is not repeated.
+func (f *podFailures) failed(podID string) time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.counts[podID]++
+	if f.initial <= 0 || f.counts[podID] < 2 {
+		return 0
+	}
+	delay := f.initial
+	for i := 2; i < f.counts[podID] && delay < f.max; i++ {
+		delay *= 2
+	}
+	if delay > f.max {
+		delay = f.max
+	}
+	return delay
+}
+
+// forget clears failures of the pod, after its request succeeded or the pod was deleted.
+func (f *podFailures) forget(podID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.counts, podID)
+}
+
+// podStatusError converts error of the pod request to gRPC status error. Repeated failures of the pod
+// carry RetryInfo details, so that the agent backs off the pod instead of retrying it on every update.
+// Stale and canceled requests are not failures of the pod.
+func (d *Server) podStatusError(podID string, err error) error {
+	statusErr := statusError(err)
+	if IsStaleRequest(statusErr) || isContextError(err) {
+		return statusErr
+	}
+	delay := d.failures.failed(podID)
+	if delay == 0 {
+		return statusErr
+	}
+	s, _ := status.FromError(statusErr)
+	withRetry, detailsErr := s.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+	if detailsErr != nil {
+		return statusErr
+	}
+	return withRetry.Err()
+}
@@ -0,0 +1,117 @@
+package ctlplaneapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPodFailuresBackoff(t *testing.T) {
+	f := newPodFailures()
+	f.initial, f.max = time.Second, 3*time.Second
+
+	delays := []time.Duration{}
+	for i := 0; i < 5; i++ {
+		delays = append(delays, f.failed("pod"))
+	}
+
+	assert.Equal(t, []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}, delays)
+	assert.Zero(t, f.failed("other"))
+	f.forget("pod")
+	assert.Zero(t, f.failed("pod"))
+}
+
+func TestPodFailuresBackoffDisabled(t *testing.T) {
+	f := newPodFailures()
+	f.initial = 0
+
+	f.failed("pod")
+
+	assert.Zero(t, f.failed("pod"))
+}
+
+func TestRepeatedFailuresReportRetryDelay(t *testing.T) {
+	ctx := context.Background()
+	client, closer, mDaemon := NewMockedServer(ctx)
+	defer closer()
+	containers := createContainers(1, []Placement{Placement_DEFAULT})
+	req, _ := createTestPodRequest(t, "fail", "test", mDaemon, Placement_DEFAULT, containers, runtimeMismatchErrorForTest(t))
+
+	_, err := client.CreatePod(ctx, req)
+	require.NotNil(t, err)
+	assert.Zero(t, RetryDelay(err))
+
+	_, err = client.CreatePod(ctx, req)
+	require.NotNil(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, defaultRetryBackoff, RetryDelay(err))
+	require.NotNil(t, ErrorInfo(err), "details of the daemon error shall be kept")
+	assert.Equal(t, ReasonRuntimeMismatch, ErrorInfo(err).Reason)
+
+	_, err = client.CreatePod(ctx, req)
+	assert.Equal(t, 2*defaultRetryBackoff, RetryDelay(err))
+}
+
+func TestDeletePodClearsFailures(t *testing.T) {
+	m := DaemonMock{}
+	s := NewServer(&m, WithRetryBackoff(time.Minute, time.Hour))
+	s.failures.failed("testPid")
+	req := &DeletePodRequest{PodId: "testPid"}
+	m.On("DeletePod", req).Return(nil, nil)
+
+	_, err := s.DeletePod(context.Background(), req)
+
+	require.Nil(t, err)
+	assert.Zero(t, s.failures.failed("testPid"))
+	assert.Equal(t, time.Minute, s.failures.failed("testPid"))
+}
+
+func TestStaleRequestsAreNotCountedAsFailures(t *testing.T) {
+	s := NewServer(&DaemonMock{}, WithRetryBackoff(time.Minute, time.Hour))
+	stale := status.Error(codes.Aborted, "stale")
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, stale, s.podStatusError("pod", stale))
+	}
+	assert.Zero(t, s.failures.failed("pod"))
+}
+
+func TestCanceledRequestsAreNotCountedAsFailures(t *testing.T) {
+	s := NewServer(&DaemonMock{}, WithRetryBackoff(time.Minute, time.Hour))
+
+	for i := 0; i < 3; i++ {
+		err := s.podStatusError("pod", fmt.Errorf("container c1: %w", context.Canceled))
+		assert.Equal(t, codes.Canceled, status.Code(err))
+		assert.Zero(t, RetryDelay(err))
+		err = s.podStatusError("pod", context.DeadlineExceeded)
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	}
+	assert.Zero(t, s.failures.failed("pod"))
+}
+
+func TestDryRunsDoNotChangeFailures(t *testing.T) {
+	m := DaemonMock{}
+	s := NewServer(&m, WithRetryBackoff(time.Minute, time.Hour))
+	s.failures.failed("testPid")
+	failing := &CreatePodRequest{PodId: "testPid", PodName: "failing", DryRun: true}
+	m.On("CreatePod", failing).Return(status.Error(codes.Unavailable, "not enough cpus"))
+	succeeding := &UpdatePodRequest{PodId: "testPid", DryRun: true}
+	m.On("UpdatePod", succeeding).Return(nil)
+
+	for i := 0; i < 3; i++ {
+		_, err := s.CreatePod(context.Background(), failing)
+		require.NotNil(t, err)
+		assert.Zero(t, RetryDelay(err))
+	}
+	reply, err := s.UpdatePod(context.Background(), succeeding)
+
+	require.Nil(t, err)
+	assert.True(t, reply.DryRun)
+	assert.Equal(t, time.Minute, s.failures.failed("testPid"), "dry runs shall neither count nor clear failures")
+}
@@ -0,0 +1,23 @@
+package ctlplaneapi
+
+import (
+	"context"
+	"time"
+)
+
+// WithRequestTimeout limits processing time of create, update and delete requests, so that a request
+// stuck on a cgroup write does not block the daemon. Requests exceeding the timeout are aborted with
+// DeadlineExceeded status. Deadline of the client applies if it is shorter. Zero disables the limit.
+func WithRequestTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.requestTimeout = timeout
+	}
+}
+
+// requestContext returns context of the request limited by the request timeout of the server.
+func (d *Server) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d.requestTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d.requestTimeout)
+}
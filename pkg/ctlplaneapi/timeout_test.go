@@ -0,0 +1,43 @@
+package ctlplaneapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestContextWithTimeout(t *testing.T) {
+	s := NewServer(&DaemonMock{}, WithRequestTimeout(time.Minute))
+
+	ctx, cancel := s.requestContext(context.Background())
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+}
+
+func TestRequestContextKeepsShorterClientDeadline(t *testing.T) {
+	s := NewServer(&DaemonMock{}, WithRequestTimeout(time.Hour))
+	client, clientCancel := context.WithTimeout(context.Background(), time.Second)
+	defer clientCancel()
+
+	ctx, cancel := s.requestContext(client)
+	defer cancel()
+
+	clientDeadline, _ := client.Deadline()
+	deadline, _ := ctx.Deadline()
+	assert.Equal(t, clientDeadline, deadline)
+}
+
+func TestRequestContextWithoutTimeout(t *testing.T) {
+	s := NewServer(&DaemonMock{})
+
+	ctx, cancel := s.requestContext(context.Background())
+	defer cancel()
+
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+}
@@ -10,6 +10,7 @@ import (
 var (
 	ErrEmptyString             = errors.New("string is empty")
 	ErrLessThanZero            = errors.New("value cannot be less than 0")
+	ErrZero                    = errors.New("value cannot be 0")
 	ErrLimitSmallerThanRequest = errors.New("limit cannot be smaller than request")
 	ErrNoContainers            = errors.New("pod spec does not include any containers")
 )
@@ -106,6 +107,107 @@ func ValidateDeletePodRequest(req *DeletePodRequest) error {
 	return nil
 }
 
+// ValidateGetPodRequest checks if GetPodRequest fulfills following requirements:
+//   - PodId cannot be empty string
+func ValidateGetPodRequest(req *GetPodRequest) error {
+	if req.PodId == "" {
+		return fmt.Errorf("pod id error: %w", ErrEmptyString)
+	}
+	return nil
+}
+
+// ValidateGetContainerRequest checks if GetContainerRequest fulfills following requirements:
+//   - PodId cannot be empty string
+//   - ContainerName cannot be empty string
+func ValidateGetContainerRequest(req *GetContainerRequest) error {
+	if req.PodId == "" {
+		return fmt.Errorf("pod id error: %w", ErrEmptyString)
+	}
+	if req.ContainerName == "" {
+		return fmt.Errorf("container name error: %w", ErrEmptyString)
+	}
+	return nil
+}
+
+// ValidateCreateNamespaceBucketRequest checks if CreateNamespaceBucketRequest fulfills following
+// requirements:
+//   - Namespace cannot be empty string
+func ValidateCreateNamespaceBucketRequest(req *CreateNamespaceBucketRequest) error {
+	if req.Namespace == "" {
+		return fmt.Errorf("namespace error: %w", ErrEmptyString)
+	}
+	return nil
+}
+
+// ValidateDeleteNamespaceBucketRequest checks if DeleteNamespaceBucketRequest fulfills following
+// requirements:
+//   - Namespace cannot be empty string
+func ValidateDeleteNamespaceBucketRequest(req *DeleteNamespaceBucketRequest) error {
+	if req.Namespace == "" {
+		return fmt.Errorf("namespace error: %w", ErrEmptyString)
+	}
+	return nil
+}
+
+// ValidateReserveCPUPoolRequest checks if ReserveCPUPoolRequest fulfills following requirements:
+//   - Name cannot be empty string
+//   - Cpus cannot be 0
+func ValidateReserveCPUPoolRequest(req *ReserveCPUPoolRequest) error {
+	if req.Name == "" {
+		return fmt.Errorf("name error: %w", ErrEmptyString)
+	}
+	if req.Cpus == 0 {
+		return fmt.Errorf("cpus error: %w", ErrZero)
+	}
+	return nil
+}
+
+// ValidateReleaseCPUPoolRequest checks if ReleaseCPUPoolRequest fulfills following requirements:
+//   - Name cannot be empty string
+func ValidateReleaseCPUPoolRequest(req *ReleaseCPUPoolRequest) error {
+	if req.Name == "" {
+		return fmt.Errorf("name error: %w", ErrEmptyString)
+	}
+	return nil
+}
+
+// ValidateSetNamespaceConfigRequest checks if SetNamespaceConfigRequest fulfills following requirements:
+//   - Namespace cannot be empty string
+func ValidateSetNamespaceConfigRequest(req *SetNamespaceConfigRequest) error {
+	if req.Namespace == "" {
+		return fmt.Errorf("namespace error: %w", ErrEmptyString)
+	}
+	return nil
+}
+
+// ValidateDeleteNamespaceConfigRequest checks if DeleteNamespaceConfigRequest fulfills following
+// requirements:
+//   - Namespace cannot be empty string
+func ValidateDeleteNamespaceConfigRequest(req *DeleteNamespaceConfigRequest) error {
+	if req.Namespace == "" {
+		return fmt.Errorf("namespace error: %w", ErrEmptyString)
+	}
+	return nil
+}
+
+// ValidateMigrateContainerRequest checks if MigrateContainerRequest fulfills following requirements:
+//   - ContainerId cannot be empty string
+func ValidateMigrateContainerRequest(req *MigrateContainerRequest) error {
+	if req.ContainerId == "" {
+		return fmt.Errorf("container id error: %w", ErrEmptyString)
+	}
+	return nil
+}
+
+// ValidatePlanDefragmentationRequest checks if PlanDefragmentationRequest fulfills following requirements:
+//   - Cpus cannot be 0
+func ValidatePlanDefragmentationRequest(req *PlanDefragmentationRequest) error {
+	if req.Cpus == 0 {
+		return fmt.Errorf("cpus error: %w", ErrZero)
+	}
+	return nil
+}
+
 // ValidateUpdatePodRequest checks if UpdatePodRequest fulfills following requirements:
 //   - number of containers must be greater than 0
 //   - pod id cannot be empty
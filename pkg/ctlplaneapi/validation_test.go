@@ -168,6 +168,17 @@ func TestValidateDeletePodRequest(t *testing.T) {
 	assert.ErrorIs(t, ValidateDeletePodRequest(&DeletePodRequest{}), ErrEmptyString)
 }
 
+func TestValidateGetPodRequest(t *testing.T) {
+	assert.Nil(t, ValidateGetPodRequest(&GetPodRequest{PodId: "i"}))
+	assert.ErrorIs(t, ValidateGetPodRequest(&GetPodRequest{}), ErrEmptyString)
+}
+
+func TestValidateGetContainerRequest(t *testing.T) {
+	assert.Nil(t, ValidateGetContainerRequest(&GetContainerRequest{PodId: "i", ContainerName: "c"}))
+	assert.ErrorIs(t, ValidateGetContainerRequest(&GetContainerRequest{ContainerName: "c"}), ErrEmptyString)
+	assert.ErrorIs(t, ValidateGetContainerRequest(&GetContainerRequest{PodId: "i"}), ErrEmptyString)
+}
+
 func TestValidateUpdatePodRequest(t *testing.T) {
 	properPodRequest := func() *UpdatePodRequest {
 		return &UpdatePodRequest{
@@ -209,3 +220,35 @@ func TestValidateUpdatePodRequest(t *testing.T) {
 		assert.ErrorIs(t, err, testCase.expectedErr)
 	}
 }
+
+func TestValidateNamespaceBucketRequests(t *testing.T) {
+	assert.Nil(t, ValidateCreateNamespaceBucketRequest(&CreateNamespaceBucketRequest{Namespace: "n"}))
+	assert.ErrorIs(t, ValidateCreateNamespaceBucketRequest(&CreateNamespaceBucketRequest{}), ErrEmptyString)
+	assert.Nil(t, ValidateDeleteNamespaceBucketRequest(&DeleteNamespaceBucketRequest{Namespace: "n"}))
+	assert.ErrorIs(t, ValidateDeleteNamespaceBucketRequest(&DeleteNamespaceBucketRequest{}), ErrEmptyString)
+}
+
+func TestValidateCPUPoolRequests(t *testing.T) {
+	assert.Nil(t, ValidateReserveCPUPoolRequest(&ReserveCPUPoolRequest{Name: "dpdk", Cpus: 4}))
+	assert.ErrorIs(t, ValidateReserveCPUPoolRequest(&ReserveCPUPoolRequest{Cpus: 4}), ErrEmptyString)
+	assert.ErrorIs(t, ValidateReserveCPUPoolRequest(&ReserveCPUPoolRequest{Name: "dpdk"}), ErrZero)
+	assert.Nil(t, ValidateReleaseCPUPoolRequest(&ReleaseCPUPoolRequest{Name: "dpdk"}))
+	assert.ErrorIs(t, ValidateReleaseCPUPoolRequest(&ReleaseCPUPoolRequest{}), ErrEmptyString)
+}
+
+func TestValidateNamespaceConfigRequests(t *testing.T) {
+	assert.Nil(t, ValidateSetNamespaceConfigRequest(&SetNamespaceConfigRequest{Namespace: "n", Allocator: "numa"}))
+	assert.ErrorIs(t, ValidateSetNamespaceConfigRequest(&SetNamespaceConfigRequest{Allocator: "numa"}), ErrEmptyString)
+	assert.Nil(t, ValidateDeleteNamespaceConfigRequest(&DeleteNamespaceConfigRequest{Namespace: "n"}))
+	assert.ErrorIs(t, ValidateDeleteNamespaceConfigRequest(&DeleteNamespaceConfigRequest{}), ErrEmptyString)
+}
+
+func TestValidateMigrateContainerRequest(t *testing.T) {
+	assert.Nil(t, ValidateMigrateContainerRequest(&MigrateContainerRequest{ContainerId: "cid"}))
+	assert.ErrorIs(t, ValidateMigrateContainerRequest(&MigrateContainerRequest{TargetNode: 1}), ErrEmptyString)
+}
+
+func TestValidatePlanDefragmentationRequest(t *testing.T) {
+	assert.Nil(t, ValidatePlanDefragmentationRequest(&PlanDefragmentationRequest{Cpus: 2}))
+	assert.ErrorIs(t, ValidatePlanDefragmentationRequest(&PlanDefragmentationRequest{Execute: true}), ErrZero)
+}
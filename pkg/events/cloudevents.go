@@ -0,0 +1,117 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Format of published allocation events.
+type Format string
+
+const (
+	// FormatJSON publishes AllocationEvent encoded as JSON.
+	FormatJSON Format = "json"
+	// FormatCloudEvents publishes structured-mode CloudEvents 1.0 with AllocationEvent as data.
+	FormatCloudEvents Format = "cloudevents"
+)
+
+// CloudEvents types of allocation lifecycle.
+const (
+	CloudEventCreated = "com.intel.ctlplane.allocation.created"
+	CloudEventUpdated = "com.intel.ctlplane.allocation.updated"
+	CloudEventDeleted = "com.intel.ctlplane.allocation.deleted"
+)
+
+const (
+	jsonContentType        = "application/json"
+	cloudEventsContentType = "application/cloudevents+json"
+	cloudEventsSpecVersion = "1.0"
+)
+
+// ParseFormat returns format of given name.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(name); f {
+	case FormatJSON, FormatCloudEvents:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown events format %q, expected %q or %q", name, FormatJSON, FormatCloudEvents)
+	}
+}
+
+// CloudEvent is an allocation event in structured mode of CloudEvents 1.0 JSON format.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            AllocationEvent `json:"data"`
+}
+
+// encoder converts allocation events into messages of given format. CloudEvents types are derived from the
+// order of events: the first allocated event of a container is created, next ones are updated and freed
+// is deleted. Not safe for concurrent use.
+type encoder struct {
+	format  Format
+	source  string
+	idBase  string // makes event ids unique across daemon restarts
+	seq     uint64
+	created map[string]struct{} // containers with published created event
+}
+
+func newEncoder(format Format, source string) *encoder {
+	return &encoder{
+		format:  format,
+		source:  source,
+		idBase:  fmt.Sprintf("%x", time.Now().UnixNano()),
+		created: make(map[string]struct{}),
+	}
+}
+
+func (c *encoder) contentType() string {
+	if c.format == FormatCloudEvents {
+		return cloudEventsContentType
+	}
+	return jsonContentType
+}
+
+func (c *encoder) encode(e AllocationEvent) ([]byte, error) {
+	if c.format != FormatCloudEvents {
+		return json.Marshal(e)
+	}
+	return json.Marshal(c.cloudEvent(e))
+}
+
+func (c *encoder) cloudEvent(e AllocationEvent) CloudEvent {
+	c.seq++
+	eventType := CloudEventDeleted
+	if e.Type == Freed {
+		delete(c.created, e.ContainerID)
+	} else if _, ok := c.created[e.ContainerID]; ok {
+		eventType = CloudEventUpdated
+	} else {
+		eventType = CloudEventCreated
+		c.created[e.ContainerID] = struct{}{}
+	}
+	return CloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              fmt.Sprintf("%s-%d", c.idBase, c.seq),
+		Source:          c.source,
+		Type:            eventType,
+		Subject:         subject(e),
+		Time:            e.Time,
+		DataContentType: jsonContentType,
+		Data:            e,
+	}
+}
+
+// subject identifies the container of the event, by names if known.
+func subject(e AllocationEvent) string {
+	if e.PodName == "" || e.ContainerName == "" {
+		return e.PodID + "/" + e.ContainerID
+	}
+	return e.PodNamespace + "/" + e.PodName + "/" + e.ContainerName
+}
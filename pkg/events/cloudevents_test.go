@@ -0,0 +1,74 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("cloudevents")
+	require.Nil(t, err)
+	assert.Equal(t, FormatCloudEvents, f)
+	f, err = ParseFormat("json")
+	require.Nil(t, err)
+	assert.Equal(t, FormatJSON, f)
+	_, err = ParseFormat("xml")
+	assert.NotNil(t, err)
+}
+
+func TestEncoderCloudEventsLifecycle(t *testing.T) {
+	c := newEncoder(FormatCloudEvents, "/ctlplane/node-1")
+	now := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	e := AllocationEvent{
+		Type:          Allocated,
+		Time:          now,
+		PodID:         "pid",
+		PodName:       "web",
+		PodNamespace:  "team-a",
+		ContainerID:   "cid",
+		ContainerName: "nginx",
+		Cpus:          "2,3",
+	}
+
+	types := []string{}
+	ids := map[string]struct{}{}
+	for _, eventType := range []Type{Allocated, Allocated, Freed, Allocated} {
+		e.Type = eventType
+		ce := c.cloudEvent(e)
+		types = append(types, ce.Type)
+		ids[ce.ID] = struct{}{}
+		assert.Equal(t, "1.0", ce.SpecVersion)
+		assert.Equal(t, "/ctlplane/node-1", ce.Source)
+		assert.Equal(t, "team-a/web/nginx", ce.Subject)
+		assert.Equal(t, now, ce.Time)
+		assert.Equal(t, e, ce.Data)
+	}
+
+	assert.Equal(t, []string{CloudEventCreated, CloudEventUpdated, CloudEventDeleted, CloudEventCreated}, types)
+	assert.Len(t, ids, 4, "event ids shall be unique")
+}
+
+func TestEncoderEncode(t *testing.T) {
+	e := AllocationEvent{Type: Allocated, PodID: "pid", ContainerID: "cid", Cpus: "1"}
+
+	body, err := newEncoder(FormatJSON, "").encode(e)
+	require.Nil(t, err)
+	decoded := AllocationEvent{}
+	require.Nil(t, json.Unmarshal(body, &decoded))
+	assert.Equal(t, e, decoded)
+
+	c := newEncoder(FormatCloudEvents, "/ctlplane")
+	body, err = c.encode(e)
+	require.Nil(t, err)
+	ce := map[string]interface{}{}
+	require.Nil(t, json.Unmarshal(body, &ce))
+	assert.Equal(t, "application/cloudevents+json", c.contentType())
+	assert.Equal(t, CloudEventCreated, ce["type"])
+	assert.Equal(t, "pid/cid", ce["subject"])
+	assert.Equal(t, "application/json", ce["datacontenttype"])
+	assert.Equal(t, "1", ce["data"].(map[string]interface{})["cpus"])
+}
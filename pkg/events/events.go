@@ -0,0 +1,33 @@
+// Package events publishes cpu allocation events of the daemon to external consumers.
+package events
+
+import "time"
+
+// Type of the allocation event.
+type Type string
+
+const (
+	// Allocated is published when cpus of a container are allocated or changed.
+	Allocated Type = "allocated"
+	// Freed is published when cpus of a container are released.
+	Freed Type = "freed"
+)
+
+// AllocationEvent describes allocation or release of cpus of a container.
+type AllocationEvent struct {
+	Type          Type      `json:"type"`
+	Time          time.Time `json:"time"`
+	PodID         string    `json:"podId"`
+	PodName       string    `json:"podName,omitempty"`
+	PodNamespace  string    `json:"podNamespace,omitempty"`
+	ContainerID   string    `json:"containerId"`
+	ContainerName string    `json:"containerName,omitempty"`
+	Cpus          string    `json:"cpus"`                  // cpu list, eg. "2,3"
+	Exclusive     bool      `json:"exclusive"`             // false if the container runs in shared pool
+	MemoryNodes   string    `json:"memoryNodes,omitempty"` // empty if memory is not pinned
+}
+
+// Sink receives allocation events. Publish shall not block the caller.
+type Sink interface {
+	Publish(e AllocationEvent)
+}
@@ -0,0 +1,147 @@
+package events
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+const (
+	defaultWebhookBuffer     = 1024
+	defaultWebhookRetries    = 5
+	defaultWebhookRetryDelay = time.Second
+	defaultWebhookTimeout    = 5 * time.Second
+)
+
+// WebhookSink posts allocation events as JSON to an http endpoint, one event per request. Events are
+// buffered and delivered in order by a background goroutine, so that slow or unavailable endpoints
+// never delay allocations. Events which do not fit into the buffer are dropped.
+type WebhookSink struct {
+	url        string
+	client     *http.Client
+	logger     logr.Logger
+	queue      chan AllocationEvent
+	retries    int
+	retryDelay time.Duration // delay before the first retry, doubled by each next one
+	encoder    *encoder
+	done       chan struct{}
+}
+
+var _ Sink = &WebhookSink{}
+
+// WebhookOption configures optional behaviour of the webhook sink.
+type WebhookOption func(*WebhookSink)
+
+// WithWebhookBuffer sets how many events wait for delivery before new ones are dropped.
+func WithWebhookBuffer(size int) WebhookOption {
+	return func(w *WebhookSink) {
+		w.queue = make(chan AllocationEvent, size)
+	}
+}
+
+// WithWebhookRetries sets how many times delivery of an event is retried, and the delay before the
+// first retry. Each next retry waits twice as long as the previous one.
+func WithWebhookRetries(retries int, delay time.Duration) WebhookOption {
+	return func(w *WebhookSink) {
+		w.retries = retries
+		w.retryDelay = delay
+	}
+}
+
+// WithWebhookTimeout sets timeout of a single delivery request.
+func WithWebhookTimeout(timeout time.Duration) WebhookOption {
+	return func(w *WebhookSink) {
+		w.client.Timeout = timeout
+	}
+}
+
+// WithWebhookFormat sets format of posted events. CloudEvents are posted in structured mode with given
+// source attribute.
+func WithWebhookFormat(format Format, source string) WebhookOption {
+	return func(w *WebhookSink) {
+		w.encoder = newEncoder(format, source)
+	}
+}
+
+// NewWebhookSink returns sink posting events to given url and starts its delivery goroutine.
+func NewWebhookSink(url string, logger logr.Logger, opts ...WebhookOption) *WebhookSink {
+	w := &WebhookSink{
+		url:        url,
+		client:     &http.Client{Timeout: defaultWebhookTimeout},
+		logger:     logger.WithName("webhook"),
+		queue:      make(chan AllocationEvent, defaultWebhookBuffer),
+		retries:    defaultWebhookRetries,
+		retryDelay: defaultWebhookRetryDelay,
+		encoder:    newEncoder(FormatJSON, ""),
+		done:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	go w.run()
+	return w
+}
+
+// Publish queues the event for delivery, or drops it if the buffer is full.
+func (w *WebhookSink) Publish(e AllocationEvent) {
+	select {
+	case w.queue <- e:
+	default:
+		metrics.WebhookEventsDropped.Inc()
+		w.logger.Info("webhook buffer full, event dropped", "type", e.Type, "cid", e.ContainerID)
+	}
+}
+
+// Close delivers events already queued and stops the delivery goroutine. No events shall be published
+// afterwards.
+func (w *WebhookSink) Close() {
+	close(w.queue)
+	<-w.done
+}
+
+func (w *WebhookSink) run() {
+	defer close(w.done)
+	for e := range w.queue {
+		w.deliver(e)
+	}
+}
+
+// deliver posts the event, retrying failed requests with exponential backoff.
+func (w *WebhookSink) deliver(e AllocationEvent) {
+	body, err := w.encoder.encode(e)
+	if err != nil {
+		w.logger.Error(err, "cannot encode event")
+		return
+	}
+	delay := w.retryDelay
+	for attempt := 0; ; attempt++ {
+		err = w.post(body)
+		if err == nil {
+			return
+		}
+		if attempt >= w.retries {
+			break
+		}
+		w.logger.V(2).Info("webhook delivery failed, retrying", "attempt", attempt+1, "error", err.Error())
+		time.Sleep(delay)
+		delay *= 2
+	}
+	metrics.WebhookDeliveryFailures.Inc()
+	w.logger.Error(err, "cannot deliver event", "type", e.Type, "cid", e.ContainerID)
+}
+
+func (w *WebhookSink) post(body []byte) error {
+	resp, err := w.client.Post(w.url, w.encoder.contentType(), bytes.NewReader(body)) //nolint: noctx
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package events
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/metrics"
+)
+
+// webhookForTest returns server recording received events, failing first given number of requests.
+func webhookForTest(t *testing.T, failures int) (*httptest.Server, func() []AllocationEvent) {
+	var mu sync.Mutex
+	received := []AllocationEvent{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		e := AllocationEvent{}
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&e))
+		received = append(received, e)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []AllocationEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]AllocationEvent{}, received...)
+	}
+}
+
+func TestWebhookSinkDeliversEventsInOrder(t *testing.T) {
+	srv, received := webhookForTest(t, 0)
+	w := NewWebhookSink(srv.URL, logr.Discard())
+
+	w.Publish(AllocationEvent{Type: Allocated, ContainerID: "c1", Cpus: "1,2"})
+	w.Publish(AllocationEvent{Type: Freed, ContainerID: "c1", Cpus: "1,2"})
+	w.Close()
+
+	events := received()
+	require.Len(t, events, 2)
+	assert.Equal(t, Allocated, events[0].Type)
+	assert.Equal(t, "1,2", events[0].Cpus)
+	assert.Equal(t, Freed, events[1].Type)
+}
+
+func TestWebhookSinkRetriesFailedDelivery(t *testing.T) {
+	srv, received := webhookForTest(t, 2)
+	w := NewWebhookSink(srv.URL, logr.Discard(), WithWebhookRetries(2, time.Millisecond))
+
+	w.Publish(AllocationEvent{Type: Allocated, ContainerID: "c1"})
+	w.Close()
+
+	assert.Len(t, received(), 1)
+}
+
+func TestWebhookSinkGivesUpAfterRetries(t *testing.T) {
+	srv, received := webhookForTest(t, 2)
+	w := NewWebhookSink(srv.URL, logr.Discard(), WithWebhookRetries(1, time.Millisecond))
+
+	w.Publish(AllocationEvent{Type: Allocated, ContainerID: "c1"})
+	w.Publish(AllocationEvent{Type: Allocated, ContainerID: "c2"})
+	w.Close()
+
+	events := received()
+	require.Len(t, events, 1)
+	assert.Equal(t, "c2", events[0].ContainerID)
+}
+
+func TestWebhookSinkDropsEventsWhenBufferFull(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	w := NewWebhookSink(srv.URL, logr.Discard(), WithWebhookBuffer(1))
+	dropped := testutil.ToFloat64(metrics.WebhookEventsDropped)
+
+	for i := 0; i < 3; i++ {
+		w.Publish(AllocationEvent{Type: Allocated})
+	}
+
+	assert.GreaterOrEqual(t, testutil.ToFloat64(metrics.WebhookEventsDropped)-dropped, float64(1))
+	close(release)
+	w.Close()
+}
+
+func TestWebhookSinkPostsCloudEvents(t *testing.T) {
+	received := make(chan CloudEvent, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e := CloudEvent{}
+		assert.Equal(t, "application/cloudevents+json", r.Header.Get("Content-Type"))
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&e))
+		received <- e
+	}))
+	defer srv.Close()
+	w := NewWebhookSink(srv.URL, logr.Discard(), WithWebhookFormat(FormatCloudEvents, "/ctlplane/node-1"))
+
+	w.Publish(AllocationEvent{Type: Allocated, PodID: "p1", ContainerID: "c1", Cpus: "1,2"})
+	w.Close()
+
+	e := <-received
+	assert.Equal(t, CloudEventCreated, e.Type)
+	assert.Equal(t, "/ctlplane/node-1", e.Source)
+	assert.Equal(t, "1,2", e.Data.Cpus)
+}
@@ -0,0 +1,56 @@
+package events
+
+import (
+	"io"
+	"sync"
+
+	"github.com/go-logr/logr"
+)
+
+// WriterSink writes allocation events to a writer, eg. stdout collected by a log shipper, one JSON
+// encoded event per line.
+type WriterSink struct {
+	mu      sync.Mutex
+	w       io.Writer
+	logger  logr.Logger
+	encoder *encoder
+}
+
+var _ Sink = &WriterSink{}
+
+// WriterOption configures optional behaviour of the writer sink.
+type WriterOption func(*WriterSink)
+
+// WithWriterFormat sets format of written events. CloudEvents are written in structured mode with given
+// source attribute.
+func WithWriterFormat(format Format, source string) WriterOption {
+	return func(s *WriterSink) {
+		s.encoder = newEncoder(format, source)
+	}
+}
+
+// NewWriterSink returns sink writing events to w.
+func NewWriterSink(w io.Writer, logger logr.Logger, opts ...WriterOption) *WriterSink {
+	s := &WriterSink{
+		w:       w,
+		logger:  logger.WithName("events"),
+		encoder: newEncoder(FormatJSON, ""),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Publish writes the event, errors are logged.
+func (s *WriterSink) Publish(e AllocationEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	line, err := s.encoder.encode(e)
+	if err == nil {
+		_, err = s.w.Write(append(line, '\n'))
+	}
+	if err != nil {
+		s.logger.Error(err, "cannot write event", "type", e.Type, "cid", e.ContainerID)
+	}
+}
@@ -0,0 +1,45 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterSinkWritesEventPerLine(t *testing.T) {
+	out := bytes.Buffer{}
+	s := NewWriterSink(&out, logr.Discard(), WithWriterFormat(FormatCloudEvents, "/ctlplane"))
+
+	s.Publish(AllocationEvent{Type: Allocated, ContainerID: "c1", Cpus: "1,2"})
+	s.Publish(AllocationEvent{Type: Freed, ContainerID: "c1", Cpus: "1,2"})
+
+	types := []string{}
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		e := CloudEvent{}
+		require.Nil(t, json.Unmarshal(scanner.Bytes(), &e))
+		assert.Equal(t, "c1", e.Data.ContainerID)
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []string{CloudEventCreated, CloudEventDeleted}, types)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("closed")
+}
+
+func TestWriterSinkIgnoresWriteErrors(t *testing.T) {
+	s := NewWriterSink(failingWriter{}, logr.Discard())
+
+	assert.NotPanics(t, func() {
+		s.Publish(AllocationEvent{Type: Allocated, ContainerID: "c1"})
+	})
+}
@@ -0,0 +1,290 @@
+// Package metrics holds prometheus metrics exported by the control plane.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"resourcemanagement.controlplane/pkg/version"
+)
+
+const namespace = "ctlplane"
+
+// Registry holds all control plane metrics.
+var Registry = prometheus.NewRegistry()
+
+// ExclusiveCpusCapExceeded counts container allocations rejected because of exclusive cpus cap.
+var ExclusiveCpusCapExceeded = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "exclusive_cpus_cap_exceeded_total",
+	Help:      "Number of container allocations rejected because of the exclusive cpus cap.",
+})
+
+// RecentlyDeletedPodUpdates counts updates rejected because the pod was recently deleted.
+var RecentlyDeletedPodUpdates = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "recently_deleted_pod_updates_total",
+	Help:      "Number of pod updates rejected because the pod was recently deleted.",
+})
+
+// ExpiredLeases counts pods whose guaranteed containers were moved to the shared pool after their
+// exclusive cpus lease expired.
+var ExpiredLeases = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "expired_leases_total",
+	Help:      "Number of pods whose exclusive cpus lease expired.",
+})
+
+// AllocationAge observes age of container cpu allocations when they are released.
+var AllocationAge = prometheus.NewHistogram(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "allocation_age_seconds",
+	Help:      "Age of container cpu allocations at the time of their release.",
+	Buckets:   prometheus.ExponentialBuckets(60, 4, 8), // 1 minute to about 11 days
+})
+
+// OrphanedAllocations counts allocations of containers not referenced by any pod, freed by the
+// garbage collector.
+var OrphanedAllocations = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "orphaned_allocations_total",
+	Help:      "Number of orphaned container allocations freed by the garbage collector.",
+})
+
+// UnmanagedPods counts pod requests which failed validation and were recorded as unmanaged pods.
+var UnmanagedPods = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "unmanaged_pods_total",
+	Help:      "Number of pod requests which failed validation and were recorded as unmanaged.",
+})
+
+// SharedPoolFallbacks counts guaranteed containers which did not fit and run in the shared pool instead.
+var SharedPoolFallbacks = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "shared_pool_fallbacks_total",
+	Help:      "Number of guaranteed containers which did not fit and run in the shared pool.",
+})
+
+// NumaNodeFreeCpus reports number of cpus of each first level topology node (eg. numa node) which
+// are not allocated.
+var NumaNodeFreeCpus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "numa_node_free_cpus",
+	Help:      "Number of free cpus of the numa node.",
+}, []string{"node"})
+
+// NumaNodeFragmentation reports fragmentation of free cpus of each first level topology node: 1 minus
+// the ratio of the largest block of adjacent free cpus to all free cpus of the node.
+var NumaNodeFragmentation = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "numa_node_fragmentation",
+	Help:      "Fragmentation of free cpus of the numa node, 0 if free cpus are adjacent, close to 1 if scattered.",
+}, []string{"node"})
+
+// FreeExclusiveCpus reports number of free cpus which can still be exclusively allocated to guaranteed
+// containers, limited by the exclusive cpus cap.
+var FreeExclusiveCpus = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "free_exclusive_cpus",
+	Help:      "Number of free cpus which can be exclusively allocated.",
+})
+
+// AllocatedContainers reports number of containers with allocated cpus.
+var AllocatedContainers = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "allocated_containers",
+	Help:      "Number of containers with allocated cpus.",
+})
+
+// EmptyCgroupPins counts cpuset writes to container cgroups without any live task.
+var EmptyCgroupPins = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "empty_cgroup_pins_total",
+	Help:      "Number of cpuset writes to container cgroups without any live task.",
+})
+
+// CpusetPartitionFallbacks counts containers with exclusive cpus whose cgroups could not be made cpuset
+// partition roots.
+var CpusetPartitionFallbacks = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "cpuset_partition_fallbacks_total",
+	Help:      "Number of containers with exclusive cpus whose cgroups could not be made cpuset partition roots.",
+})
+
+// RuntimeMismatches counts container allocations rejected because the container id does not match the
+// configured container runtime.
+var RuntimeMismatches = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "runtime_mismatches_total",
+	Help:      "Number of container allocations rejected because container id does not match the configured runtime.",
+})
+
+// WebhookEventsDropped counts allocation events dropped because the webhook buffer was full.
+var WebhookEventsDropped = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "webhook_events_dropped_total",
+	Help:      "Number of allocation events dropped because the webhook buffer was full.",
+})
+
+// WebhookDeliveryFailures counts allocation events not delivered to the webhook after all retries.
+var WebhookDeliveryFailures = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "webhook_delivery_failures_total",
+	Help:      "Number of allocation events not delivered to the webhook after all retries.",
+})
+
+// StalePodRequests counts pod requests rejected because they carried older version of the pod than the
+// applied one.
+var StalePodRequests = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "stale_pod_requests_total",
+	Help:      "Number of pod requests rejected because they carried older version of the pod than the applied one.",
+})
+
+// DeferredCgroupUpdates counts cpuset updates deferred because the container cgroup did not exist yet.
+var DeferredCgroupUpdates = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "deferred_cgroup_updates_total",
+	Help:      "Number of cpuset updates deferred because the container cgroup did not exist yet.",
+})
+
+// ExhaustedCgroupUpdates counts deferred cpuset updates dropped because the container cgroup did not
+// appear within the configured number of attempts.
+var ExhaustedCgroupUpdates = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "exhausted_cgroup_updates_total",
+	Help:      "Number of deferred cpuset updates dropped because the container cgroup did not appear.",
+})
+
+// CgroupDriftRepairs counts cpusets of container cgroups overwritten by another component and re-applied
+// by the reconciler.
+var CgroupDriftRepairs = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "cgroup_drift_repairs_total",
+	Help:      "Number of container cpusets overwritten by another component and re-applied by the daemon.",
+})
+
+// EffectiveCpusetMismatches counts cpuset updates of container cgroups read back with different
+// effective cpus, eg. narrowed by cpuset of a parent cgroup.
+var EffectiveCpusetMismatches = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "effective_cpuset_mismatches_total",
+	Help:      "Number of container cpuset updates whose effective cpus differ from the written ones.",
+})
+
+// TopologyReloads counts topology changes loaded from the node info path without restart.
+var TopologyReloads = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "topology_reloads_total",
+	Help:      "Number of topology changes loaded from the node info path without restart.",
+})
+
+// ConfigReloads counts configuration reloads applied to the daemon without restart.
+var ConfigReloads = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "config_reloads_total",
+	Help:      "Number of configuration reloads applied to the daemon without restart.",
+})
+
+// NamespacePinnedCPUSeconds accumulates cpu time of exclusive cpus pinned to guaranteed containers of each
+// namespace: number of allocated cpus times duration of the allocations.
+var NamespacePinnedCPUSeconds = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "namespace_pinned_cpu_seconds_total",
+	Help:      "Cpu seconds of exclusive cpus pinned to guaranteed containers of the namespace.",
+}, []string{"namespace"})
+
+// NamespaceRequestedCPUSeconds accumulates cpu time requested by guaranteed containers of each namespace
+// while they have exclusive cpus pinned, to be compared with NamespacePinnedCPUSeconds.
+var NamespaceRequestedCPUSeconds = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "namespace_requested_cpu_seconds_total",
+	Help:      "Cpu seconds requested by guaranteed containers of the namespace while they have exclusive cpus pinned.",
+}, []string{"namespace"})
+
+// PressureRefusals counts exclusive allocations refused because the node was under pressure.
+var PressureRefusals = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "pressure_refusals_total",
+	Help:      "Number of exclusive allocations refused because the node was under pressure.",
+})
+
+// MisroutedPodRequests counts pod requests rejected because they were meant for another node.
+var MisroutedPodRequests = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "misrouted_pod_requests_total",
+	Help:      "Number of pod requests rejected because they were meant for another node.",
+})
+
+// AbortedPodRequests counts pod requests aborted by cancellation or deadline of the request.
+var AbortedPodRequests = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "aborted_pod_requests_total",
+	Help:      "Number of pod requests aborted because they were canceled or exceeded their deadline.",
+})
+
+// BorrowedCpus reports number of exclusive cpus lent to throttled burstable containers.
+var BorrowedCpus = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "borrowed_cpus",
+	Help:      "Number of idle exclusive cpus lent to throttled burstable containers.",
+})
+
+// ReclaimedCpus counts borrowed cpus returned because their owners used them again.
+var ReclaimedCpus = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "reclaimed_cpus_total",
+	Help:      "Number of borrowed cpus returned because their owners used them again.",
+})
+
+// BuildInfo reports build of the running binary in its labels, its value is always 1.
+var BuildInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "build_info",
+	Help:      "Build information of the running binary, the value is always 1.",
+}, []string{"version", "commit", "build_date", "go_version"})
+
+func init() {
+	info := version.Get()
+	BuildInfo.WithLabelValues(info.Version, info.Commit, info.BuildDate, info.GoVersion).Set(1)
+
+	Registry.MustRegister(
+		ExclusiveCpusCapExceeded,
+		RecentlyDeletedPodUpdates,
+		ExpiredLeases,
+		AllocationAge,
+		OrphanedAllocations,
+		UnmanagedPods,
+		SharedPoolFallbacks,
+		NumaNodeFreeCpus,
+		NumaNodeFragmentation,
+		FreeExclusiveCpus,
+		AllocatedContainers,
+		EmptyCgroupPins,
+		CpusetPartitionFallbacks,
+		RuntimeMismatches,
+		WebhookEventsDropped,
+		WebhookDeliveryFailures,
+		StalePodRequests,
+		DeferredCgroupUpdates,
+		ExhaustedCgroupUpdates,
+		CgroupDriftRepairs,
+		EffectiveCpusetMismatches,
+		PressureRefusals,
+		TopologyReloads,
+		ConfigReloads,
+		NamespacePinnedCPUSeconds,
+		NamespaceRequestedCPUSeconds,
+		MisroutedPodRequests,
+		AbortedPodRequests,
+		BorrowedCpus,
+		ReclaimedCpus,
+		BuildInfo,
+	)
+}
+
+// Handler returns http handler serving metrics from Registry.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
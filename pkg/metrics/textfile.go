@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// WriteTextfile writes all metrics of Registry to the file at given path in the text exposition format
+// read by the node-exporter textfile collector. The file is replaced atomically, so that the collector
+// never reads partially written metrics.
+func WriteTextfile(path string) error {
+	return prometheus.WriteToTextfile(path, Registry)
+}
+
+// RunTextfileExport writes metrics to the file at given path immediately and then every interval, until
+// stop is closed. Non-positive interval or empty path disables the export.
+func RunTextfileExport(path string, interval time.Duration, stop <-chan struct{}, logger logr.Logger) {
+	if path == "" || interval <= 0 {
+		return
+	}
+	write := func() {
+		if err := WriteTextfile(path); err != nil {
+			logger.Error(err, "cannot write metrics textfile", "path", path)
+		}
+	}
+	write()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			write()
+		}
+	}
+}
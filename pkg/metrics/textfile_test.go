@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTextfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ctlplane.prom")
+	FreeExclusiveCpus.Set(3)
+	AllocatedContainers.Set(2)
+
+	require.Nil(t, WriteTextfile(path))
+
+	content, err := os.ReadFile(path)
+	require.Nil(t, err)
+	assert.Contains(t, string(content), "ctlplane_free_exclusive_cpus 3\n")
+	assert.Contains(t, string(content), "ctlplane_allocated_containers 2\n")
+}
+
+func TestWriteTextfileFailsOnMissingDirectory(t *testing.T) {
+	assert.NotNil(t, WriteTextfile(filepath.Join(t.TempDir(), "missing", "ctlplane.prom")))
+}
+
+func TestRunTextfileExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ctlplane.prom")
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		RunTextfileExport(path, time.Millisecond, stop, logr.Discard())
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, time.Millisecond)
+	close(stop)
+	<-done
+}
+
+func TestRunTextfileExportDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ctlplane.prom")
+
+	RunTextfileExport(path, 0, nil, logr.Discard())
+	RunTextfileExport("", time.Millisecond, nil, logr.Discard())
+
+	assert.NoFileExists(t, path)
+}
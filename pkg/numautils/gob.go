@@ -0,0 +1,91 @@
+package numautils
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+)
+
+// ErrInvalidEncoding is returned when encoded topology tree is malformed.
+var ErrInvalidEncoding = errors.New("invalid encoding of topology tree")
+
+// gobTopology is the gob encoding of NumaTopology. The topology tree is flattened, as gob skips fields of
+// embedded unexported structs and would encode recursive nodes one by one.
+type gobTopology struct {
+	Nodes          []gobTopologyNode // nodes of the tree in pre-order
+	CpuInformation map[int]CpuInfo
+}
+
+type gobTopologyNode struct {
+	Type         TopologyEntryType
+	Value        int
+	NumAvailable int
+	NumChildren  int
+}
+
+// GobEncode implements gob.GobEncoder interface.
+func (t NumaTopology) GobEncode() ([]byte, error) {
+	enc := gobTopology{CpuInformation: t.CpuInformation}
+	if t.Topology != nil {
+		enc.Nodes = t.Topology.flatten(nil)
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(enc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder interface.
+func (t *NumaTopology) GobDecode(b []byte) error {
+	dec := gobTopology{}
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&dec); err != nil {
+		return err
+	}
+	t.CpuInformation = dec.CpuInformation
+	t.Topology = nil
+	if len(dec.Nodes) == 0 {
+		return nil
+	}
+	root, rest := unflatten(dec.Nodes)
+	if root == nil || len(rest) > 0 {
+		return ErrInvalidEncoding
+	}
+	t.Topology = root
+	return nil
+}
+
+func (t *TopologyNode) flatten(nodes []gobTopologyNode) []gobTopologyNode {
+	nodes = append(nodes, gobTopologyNode{
+		Type:         t.Type,
+		Value:        t.Value,
+		NumAvailable: t.NumAvailable,
+		NumChildren:  len(t.Children),
+	})
+	for _, child := range t.Children {
+		nodes = child.flatten(nodes)
+	}
+	return nodes
+}
+
+// unflatten rebuilds the subtree of the first node and returns it together with nodes following the
+// subtree. Returns nil node if nodes end before the subtree is complete.
+func unflatten(nodes []gobTopologyNode) (*TopologyNode, []gobTopologyNode) {
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	n := nodes[0]
+	node := &TopologyNode{
+		nodeInfo:     nodeInfo{Type: n.Type, Value: n.Value},
+		NumAvailable: n.NumAvailable,
+	}
+	rest := nodes[1:]
+	for i := 0; i < n.NumChildren; i++ {
+		var child *TopologyNode
+		if child, rest = unflatten(rest); child == nil {
+			return nil, nil
+		}
+		node.Children = append(node.Children, child)
+	}
+	return node, rest
+}
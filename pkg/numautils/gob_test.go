@@ -0,0 +1,45 @@
+package numautils
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGobEncoding(t *testing.T) {
+	testDir, teardownFunc := setupNumaTest(t)
+	defer teardownFunc()
+
+	numa := NumaTopology{}
+	require.Nil(t, numa.Load(testDir))
+	_, err := numa.Take(3)
+	require.Nil(t, err)
+
+	var buf bytes.Buffer
+	require.Nil(t, gob.NewEncoder(&buf).Encode(numa))
+	decoded := NumaTopology{}
+	require.Nil(t, gob.NewDecoder(&buf).Decode(&decoded))
+
+	assert.Equal(t, numa, decoded)
+}
+
+func TestGobEncodingOfEmptyTopology(t *testing.T) {
+	var buf bytes.Buffer
+	require.Nil(t, gob.NewEncoder(&buf).Encode(NumaTopology{}))
+	decoded := NumaTopology{}
+	require.Nil(t, gob.NewDecoder(&buf).Decode(&decoded))
+
+	assert.Nil(t, decoded.Topology)
+}
+
+func TestUnflattenFailsOnTruncatedTree(t *testing.T) {
+	nodes := expectedTree.flatten(nil)
+
+	root, rest := unflatten(nodes[:len(nodes)-1])
+
+	assert.Nil(t, root)
+	assert.Empty(t, rest)
+}
@@ -6,6 +6,7 @@ package numautils
 import (
 	"errors"
 	"fmt"
+	"sort"
 )
 
 // ErrNotAvailable is returned when it is impossible to allocate cpus.
@@ -28,7 +29,12 @@ type NumaTopology struct {
 // distance between cpus. In our case the topology distance between n leafs is defined as maximal
 // path length from any of those leafs to the nearest common predecessor.
 func (t *NumaTopology) Take(n int) ([]int, error) {
-	l, _ := t.Topology.findLowestNodeWithEnoughAvailability(n, 0)
+	return t.TakeFrom(t.Topology, n)
+}
+
+// TakeFrom works as Take, but considers only cpus inside the subtree of given node.
+func (t *NumaTopology) TakeFrom(node *TopologyNode, n int) ([]int, error) {
+	l, _ := node.findLowestNodeWithEnoughAvailability(n, 0)
 	if l == nil {
 		return []int{}, ErrNotAvailable
 	}
@@ -51,6 +57,91 @@ func (t *NumaTopology) Take(n int) ([]int, error) {
 	return cpuIDs, nil
 }
 
+// TakeCoresFrom works as TakeFrom, but takes whole physical cores whose all hardware threads are available,
+// so that no core is shared with cpus taken by other calls. Cores are taken until they have at least n cpus,
+// all cpus of taken cores are returned.
+func (t *NumaTopology) TakeCoresFrom(node *TopologyNode, n int) ([]int, error) {
+	parent := t.parentOf(node)
+	l, _ := node.findLowestNodeWithEnoughFreeCores(n, parent, 0)
+	if l == nil {
+		return []int{}, ErrNotAvailable
+	}
+	if l != node {
+		parent = t.parentOf(l)
+	}
+	cpuIDs := l.freeCores(n, parent)
+	for i, cpu := range cpuIDs {
+		if err := t.TakeCpu(cpu); err != nil {
+			for _, taken := range cpuIDs[:i] {
+				_ = t.Return(taken)
+			}
+			return []int{}, err
+		}
+	}
+	return cpuIDs, nil
+}
+
+// Siblings returns sorted ids of cpus of the physical core of given cpu (its hardware threads), including
+// the cpu itself.
+func (t *NumaTopology) Siblings(cpuID int) ([]int, error) {
+	path := t.Topology.find(func(tl *TopologyNode) bool { return tl.IsLeaf() && tl.Value == cpuID })
+	if len(path) == 0 {
+		return []int{}, ErrNotFound
+	}
+	if len(path) < 2 || path[1].Type != Core {
+		return []int{cpuID}, nil
+	}
+	siblings := []int{}
+	for _, leaf := range path[1].GetLeafs() {
+		siblings = append(siblings, leaf.Value)
+	}
+	sort.Ints(siblings)
+	return siblings, nil
+}
+
+// ThreadsPerCore returns the largest number of hardware threads of a physical core of the machine.
+func (t *NumaTopology) ThreadsPerCore() int {
+	threads := 1
+	var walk func(node *TopologyNode)
+	walk = func(node *TopologyNode) {
+		if node.Type == Core {
+			if len(node.Children) > threads {
+				threads = len(node.Children)
+			}
+			return
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(t.Topology)
+	return threads
+}
+
+// parentOf returns parent of the node in the topology tree, nil for the root.
+func (t *NumaTopology) parentOf(node *TopologyNode) *TopologyNode {
+	path := t.Topology.find(func(tl *TopologyNode) bool { return tl == node })
+	if len(path) < 2 {
+		return nil
+	}
+	return path[1]
+}
+
+// TakeCpu marks given cpu as non-available. Returns ErrNotAvailable if cpu is already taken.
+func (t *NumaTopology) TakeCpu(cpuID int) error {
+	path := t.Topology.find(func(tl *TopologyNode) bool { return tl.IsLeaf() && tl.Value == cpuID })
+	if len(path) == 0 {
+		return ErrNotFound
+	}
+	if path[0].NumAvailable == 0 {
+		return ErrNotAvailable
+	}
+	for _, node := range path {
+		node.NumAvailable--
+	}
+	return nil
+}
+
 // FindCpu returns TopologyNode of given cpu. The node is guaranteed to be a leaf of the topology
 // tree.
 func (t *NumaTopology) FindCpu(cpuID int) (*TopologyNode, error) {
@@ -120,3 +211,58 @@ func (t *NumaTopology) cpuInfoToTopology(cpuInfos []CpuInfo) {
 		t.Topology.append(cpuInfoToNodeInfoList(cpu, topoTypes))
 	}
 }
+
+// RemoveCpus rebuilds topology tree without given cpus. Cpus not present in the topology are ignored.
+// Availability information is reset, so it shall be invoked before any cpu is taken.
+func (t *NumaTopology) RemoveCpus(cpuIDs []int) error {
+	removed := make(map[int]struct{}, len(cpuIDs))
+	for _, cpu := range cpuIDs {
+		removed[cpu] = struct{}{}
+	}
+
+	cpuInfos := make([]CpuInfo, 0, len(t.CpuInformation))
+	for _, leaf := range t.Topology.GetLeafs() {
+		if _, ok := removed[leaf.Value]; ok {
+			continue
+		}
+		cpuInfo, ok := t.CpuInformation[leaf.Value]
+		if !ok {
+			return fmt.Errorf("%w: %d", ErrNotFound, leaf.Value)
+		}
+		cpuInfos = append(cpuInfos, cpuInfo)
+	}
+
+	return t.LoadFromCpuInfo(cpuInfos)
+}
+
+// CpusOnNodes returns ids of all cpus which belong to given numa nodes.
+func (t *NumaTopology) CpusOnNodes(nodes []int) []int {
+	nodeSet := make(map[int]struct{}, len(nodes))
+	for _, node := range nodes {
+		nodeSet[node] = struct{}{}
+	}
+
+	cpuIDs := []int{}
+	for cpu, cpuInfo := range t.CpuInformation {
+		if _, ok := nodeSet[cpuInfo.Node]; ok {
+			cpuIDs = append(cpuIDs, cpu)
+		}
+	}
+	sort.Ints(cpuIDs)
+	return cpuIDs
+}
+
+// Clone returns deep copy of the topology.
+func (t *NumaTopology) Clone() NumaTopology {
+	cloned := NumaTopology{}
+	if t.Topology != nil {
+		cloned.Topology = t.Topology.clone()
+	}
+	if t.CpuInformation != nil {
+		cloned.CpuInformation = make(map[int]CpuInfo, len(t.CpuInformation))
+		for cpu, cpuInfo := range t.CpuInformation {
+			cloned.CpuInformation[cpu] = cpuInfo
+		}
+	}
+	return cloned
+}
@@ -211,3 +211,145 @@ func TestReturnIncorrect(t *testing.T) {
 	assert.Nil(t, numa.Return(1))
 	assert.True(t, verifyNumAvailable(numa.Topology))
 }
+
+func TestTakeCpu(t *testing.T) {
+	numa := newNuma(t)
+	assert.Nil(t, numa.TakeCpu(3))
+	assert.True(t, verifyNumAvailable(numa.Topology))
+	assert.ErrorIs(t, numa.TakeCpu(3), ErrNotAvailable)
+	assert.ErrorIs(t, numa.TakeCpu(42), ErrNotFound)
+
+	ids, err := numa.Take(2)
+	assert.Nil(t, err)
+	assert.NotContains(t, ids, 3)
+	assert.Nil(t, numa.Return(3))
+	assert.True(t, verifyNumAvailable(numa.Topology))
+}
+
+func TestTakeFrom(t *testing.T) {
+	numa := newNuma(t)
+	node1 := numa.Topology.Children[1]
+
+	ids, err := numa.TakeFrom(node1, 3)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []int{2, 4, 6}, ids)
+	assert.True(t, verifyNumAvailable(numa.Topology))
+	assert.Equal(t, 5, numa.Topology.NumAvailable)
+
+	_, err = numa.TakeFrom(node1, 2)
+	assert.ErrorIs(t, err, ErrNotAvailable)
+}
+
+func TestTakeCoresFrom(t *testing.T) {
+	numa := newNuma(t)
+	require.Nil(t, numa.TakeCpu(1)) // core 0 of node 0 is partially taken
+
+	ids, err := numa.TakeCoresFrom(numa.Topology, 3)
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []int{2, 4, 6, 8}, ids, "both cores of node 1 shall be taken")
+	assert.True(t, verifyNumAvailable(numa.Topology))
+
+	ids, err = numa.TakeCoresFrom(numa.Topology, 1)
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []int{5, 7}, ids, "sibling of taken cpu shall not be taken")
+
+	_, err = numa.TakeCoresFrom(numa.Topology, 1)
+	assert.ErrorIs(t, err, ErrNotAvailable)
+	assert.Equal(t, 1, numa.Topology.NumAvailable)
+}
+
+func TestTakeCoresFromNode(t *testing.T) {
+	numa := newNuma(t)
+	node0 := numa.Topology.Children[0]
+
+	ids, err := numa.TakeCoresFrom(node0, 2)
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []int{1, 3}, ids)
+
+	_, err = numa.TakeCoresFrom(node0, 3)
+	assert.ErrorIs(t, err, ErrNotAvailable)
+	assert.Equal(t, 6, numa.Topology.NumAvailable)
+}
+
+func TestSiblings(t *testing.T) {
+	numa := newNuma(t)
+
+	siblings, err := numa.Siblings(7)
+	require.Nil(t, err)
+	assert.Equal(t, []int{5, 7}, siblings)
+	assert.Equal(t, 2, numa.ThreadsPerCore())
+
+	_, err = numa.Siblings(42)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSiblingsWithoutCoreLevel(t *testing.T) {
+	numa := NumaTopology{}
+	require.Nil(t, numa.LoadFromCpuInfo([]CpuInfo{{Cpu: 0, Node: 0}, {Cpu: 1, Node: 1}}))
+
+	siblings, err := numa.Siblings(1)
+	require.Nil(t, err)
+	assert.Equal(t, []int{1}, siblings)
+	assert.Equal(t, 1, numa.ThreadsPerCore())
+	ids, err := numa.TakeCoresFrom(numa.Topology, 2)
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []int{0, 1}, ids)
+}
+
+func TestAvailabilityDepth(t *testing.T) {
+	numa := newNuma(t)
+	node0 := numa.Topology.Children[0]
+
+	assert.Equal(t, 2, node0.AvailabilityDepth(1))
+	assert.Equal(t, 1, node0.AvailabilityDepth(2))
+	assert.Equal(t, 0, node0.AvailabilityDepth(3))
+	assert.Equal(t, -1, node0.AvailabilityDepth(5))
+}
+
+func TestRemoveCpus(t *testing.T) {
+	testDir, teardownFunc := setupNumaTest(t)
+	defer teardownFunc()
+
+	numa := NumaTopology{}
+	require.Nil(t, numa.Load(testDir))
+	require.Nil(t, numa.RemoveCpus([]int{3, 4, 42}))
+
+	leafs := []int{}
+	for _, leaf := range numa.Topology.GetLeafs() {
+		leafs = append(leafs, leaf.Value)
+	}
+	assert.ElementsMatch(t, []int{1, 5, 7, 2, 6, 8}, leafs)
+	assert.Equal(t, 6, numa.Topology.NumAvailable)
+	assert.NotContains(t, numa.CpuInformation, 3)
+	assert.NotContains(t, numa.CpuInformation, 4)
+	assert.True(t, verifyNumAvailable(numa.Topology))
+}
+
+func TestCpusOnNodes(t *testing.T) {
+	testDir, teardownFunc := setupNumaTest(t)
+	defer teardownFunc()
+
+	numa := NumaTopology{}
+	require.Nil(t, numa.Load(testDir))
+
+	assert.Equal(t, []int{2, 4, 6, 8}, numa.CpusOnNodes([]int{1}))
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, numa.CpusOnNodes([]int{0, 1}))
+	assert.Empty(t, numa.CpusOnNodes([]int{5}))
+}
+
+func TestClone(t *testing.T) {
+	testDir, teardownFunc := setupNumaTest(t)
+	defer teardownFunc()
+
+	numa := NumaTopology{}
+	require.Nil(t, numa.Load(testDir))
+
+	cloned := numa.Clone()
+	assert.Equal(t, numa, cloned)
+
+	_, err := cloned.Take(3)
+	require.Nil(t, err)
+	assert.Equal(t, 8, numa.Topology.NumAvailable)
+	assert.Equal(t, 5, cloned.Topology.NumAvailable)
+	assert.True(t, verifyNumAvailable(numa.Topology))
+}
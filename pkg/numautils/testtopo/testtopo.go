@@ -0,0 +1,121 @@
+// Package testtopo generates synthetic cpu topologies of given shape for tests: cpu information, numa
+// topology trees and fake sysfs node directories read by numautils.NumaTopology.Load.
+package testtopo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"resourcemanagement.controlplane/pkg/numautils"
+)
+
+const (
+	dirMode  = 0o750
+	fileMode = 0o600
+)
+
+// Spec describes shape of the synthetic machine. Cpus are numbered as by Linux on x86: the first
+// thread of every core gets consecutive ids across all sockets, then the second thread of every core,
+// and so on, so that hyperthread siblings are NumCores() apart.
+type Spec struct {
+	Sockets        int
+	DiesPerSocket  int
+	CoresPerDie    int
+	ThreadsPerCore int
+	NodesPerSocket int // numa nodes of each socket (eg. sub-numa clustering), 1 if zero
+}
+
+// NumCores returns number of physical cores of the machine.
+func (s Spec) NumCores() int {
+	return s.Sockets * s.DiesPerSocket * s.CoresPerDie
+}
+
+// NumCpus returns number of logical cpus of the machine.
+func (s Spec) NumCpus() int {
+	return s.NumCores() * s.ThreadsPerCore
+}
+
+// NumNodes returns number of numa nodes of the machine.
+func (s Spec) NumNodes() int {
+	return s.Sockets * s.nodesPerSocket()
+}
+
+func (s Spec) nodesPerSocket() int {
+	if s.NodesPerSocket <= 0 {
+		return 1
+	}
+	return s.NodesPerSocket
+}
+
+// Validate checks that all dimensions are positive and cores of each socket split evenly across its
+// numa nodes.
+func (s Spec) Validate() error {
+	if s.Sockets <= 0 || s.DiesPerSocket <= 0 || s.CoresPerDie <= 0 || s.ThreadsPerCore <= 0 {
+		return fmt.Errorf("all dimensions of topology %+v shall be positive", s)
+	}
+	if (s.DiesPerSocket*s.CoresPerDie)%s.nodesPerSocket() != 0 {
+		return fmt.Errorf("cores of a socket of topology %+v cannot be split evenly across numa nodes", s)
+	}
+	return nil
+}
+
+// CpuInfos returns information of all cpus of the machine, ordered by cpu id.
+func (s Spec) CpuInfos() []numautils.CpuInfo {
+	coresPerSocket := s.DiesPerSocket * s.CoresPerDie
+	coresPerNode := coresPerSocket / s.nodesPerSocket()
+	cpus := make([]numautils.CpuInfo, 0, s.NumCpus())
+	for thread := 0; thread < s.ThreadsPerCore; thread++ {
+		for core := 0; core < s.NumCores(); core++ {
+			socket := core / coresPerSocket
+			socketCore := core % coresPerSocket
+			cpus = append(cpus, numautils.CpuInfo{
+				Cpu:     thread*s.NumCores() + core,
+				Node:    socket*s.nodesPerSocket() + socketCore/coresPerNode,
+				Package: socket,
+				Die:     socketCore / s.CoresPerDie,
+				Core:    socketCore,
+			})
+		}
+	}
+	return cpus
+}
+
+// Topology returns numa topology of the machine.
+func (s Spec) Topology() (numautils.NumaTopology, error) {
+	topology := numautils.NumaTopology{}
+	if err := s.Validate(); err != nil {
+		return topology, err
+	}
+	err := topology.LoadFromCpuInfo(s.CpuInfos())
+	return topology, err
+}
+
+// WriteSysfs writes node directories of the machine to dir, in the layout of /sys/devices/system/node:
+// node<N>/cpu<M>/topology/{package_id,die_id,core_id}.
+func (s Spec) WriteSysfs(dir string) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	for _, cpu := range s.CpuInfos() {
+		topologyPath := filepath.Join(dir, "node"+strconv.Itoa(cpu.Node), "cpu"+strconv.Itoa(cpu.Cpu), "topology")
+		if err := os.MkdirAll(topologyPath, dirMode); err != nil {
+			return err
+		}
+		for file, value := range map[string]int{"package_id": cpu.Package, "die_id": cpu.Die, "core_id": cpu.Core} {
+			if err := os.WriteFile(filepath.Join(topologyPath, file), []byte(strconv.Itoa(value)), fileMode); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Common shapes of machines used by table driven tests.
+var (
+	SingleNode    = Spec{Sockets: 1, DiesPerSocket: 1, CoresPerDie: 4, ThreadsPerCore: 1}
+	TwoSockets    = Spec{Sockets: 2, DiesPerSocket: 1, CoresPerDie: 8, ThreadsPerCore: 2}
+	SubNumaSocket = Spec{Sockets: 1, DiesPerSocket: 2, CoresPerDie: 8, ThreadsPerCore: 2, NodesPerSocket: 2}
+	LargeServer   = Spec{Sockets: 2, DiesPerSocket: 4, CoresPerDie: 16, ThreadsPerCore: 2, NodesPerSocket: 4}
+)
@@ -0,0 +1,67 @@
+package testtopo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"resourcemanagement.controlplane/pkg/numautils"
+)
+
+func TestCpuInfosNumbersSiblingsLikeLinux(t *testing.T) {
+	spec := Spec{Sockets: 2, DiesPerSocket: 1, CoresPerDie: 2, ThreadsPerCore: 2}
+
+	cpus := spec.CpuInfos()
+
+	require.Len(t, cpus, 8)
+	assert.Equal(t, numautils.CpuInfo{Cpu: 1, Node: 0, Package: 0, Core: 1}, cpus[1])
+	assert.Equal(t, numautils.CpuInfo{Cpu: 2, Node: 1, Package: 1, Core: 0}, cpus[2])
+	assert.Equal(t, numautils.CpuInfo{Cpu: 5, Node: 0, Package: 0, Core: 1}, cpus[5], "sibling of cpu 1")
+}
+
+func TestCpuInfosSplitsSocketsIntoNodes(t *testing.T) {
+	cpus := SubNumaSocket.CpuInfos()
+
+	nodes := map[int]int{}
+	for _, cpu := range cpus {
+		nodes[cpu.Node]++
+	}
+	assert.Equal(t, map[int]int{0: 16, 1: 16}, nodes)
+	assert.Equal(t, 1, cpus[8].Die)
+	assert.Equal(t, 1, cpus[8].Node)
+}
+
+func TestTopology(t *testing.T) {
+	for _, spec := range []Spec{SingleNode, TwoSockets, SubNumaSocket, LargeServer} {
+		topology, err := spec.Topology()
+
+		require.Nil(t, err, spec)
+		if spec.NumNodes() > 1 { // levels with the same value for all cpus are left out of the tree
+			assert.Len(t, topology.Topology.Children, spec.NumNodes(), spec)
+		}
+		assert.Len(t, topology.Topology.GetLeafs(), spec.NumCpus(), spec)
+		assert.Equal(t, spec.NumCpus(), topology.Topology.NumAvailable, spec)
+	}
+}
+
+func TestWriteSysfsLoadsSameTopology(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, TwoSockets.WriteSysfs(dir))
+
+	loaded := numautils.NumaTopology{}
+	require.Nil(t, loaded.Load(dir))
+	generated, err := TwoSockets.Topology()
+	require.Nil(t, err)
+
+	assert.Equal(t, generated.CpuInformation, loaded.CpuInformation)
+	assert.Len(t, loaded.Topology.Children, len(generated.Topology.Children))
+	assert.Equal(t, generated.Topology.NumAvailable, loaded.Topology.NumAvailable)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NotNil(t, Spec{}.Validate())
+	assert.NotNil(t, Spec{Sockets: 1, DiesPerSocket: 1, CoresPerDie: 3, ThreadsPerCore: 1, NodesPerSocket: 2}.Validate())
+	_, err := Spec{Sockets: 1}.Topology()
+	assert.NotNil(t, err)
+	assert.NotNil(t, Spec{Sockets: 1}.WriteSysfs(t.TempDir()))
+}
@@ -126,6 +126,20 @@ func (t TopologyEntryType) valueFromCpuInfo(c CpuInfo) int {
 	return -1
 }
 
+func (t *TopologyNode) clone() *TopologyNode {
+	cloned := &TopologyNode{
+		nodeInfo:     t.nodeInfo,
+		NumAvailable: t.NumAvailable,
+	}
+	if t.Children != nil {
+		cloned.Children = make([]*TopologyNode, 0, len(t.Children))
+		for _, child := range t.Children {
+			cloned.Children = append(cloned.Children, child.clone())
+		}
+	}
+	return cloned
+}
+
 func (t *TopologyNode) toString(level int) string {
 	var builder strings.Builder
 	builder.WriteString(
@@ -161,6 +175,14 @@ func (t *TopologyNode) append(nodeInfoPath []nodeInfo) {
 	nextChild.append(nodeInfoPath[1:])
 }
 
+// AvailabilityDepth returns depth (relative to the node) of the deepest subtree with at least n
+// available leafs, or -1 if the node itself does not have n available leafs. The deeper the subtree,
+// the lower the topology distance between cpus allocated from it.
+func (t *TopologyNode) AvailabilityDepth(n int) int {
+	_, depth := t.findLowestNodeWithEnoughAvailability(n, 0)
+	return depth
+}
+
 func (t *TopologyNode) findLowestNodeWithEnoughAvailability(n int, currentLevel int) (*TopologyNode, int) {
 	if t.NumAvailable < n {
 		return nil, -1
@@ -216,6 +238,78 @@ func (t *TopologyNode) takeLeaves(n int) ([]*TopologyNode, error) {
 	return leaves, nil
 }
 
+// isCore checks if the node is a physical core: a core level node, or a cpu of topology without core level.
+func (t *TopologyNode) isCore(parent *TopologyNode) bool {
+	return t.Type == Core || (t.IsLeaf() && (parent == nil || parent.Type != Core))
+}
+
+// freeCoreCpus returns number of cpus of physical cores in the subtree whose all cpus are available.
+func (t *TopologyNode) freeCoreCpus(parent *TopologyNode) int {
+	if t.isCore(parent) {
+		if t.NumAvailable == len(t.GetLeafs()) {
+			return t.NumAvailable
+		}
+		return 0
+	}
+	free := 0
+	for _, child := range t.Children {
+		free += child.freeCoreCpus(t)
+	}
+	return free
+}
+
+// findLowestNodeWithEnoughFreeCores works as findLowestNodeWithEnoughAvailability, but counts only cpus
+// of physical cores whose all cpus are available.
+func (t *TopologyNode) findLowestNodeWithEnoughFreeCores(
+	n int,
+	parent *TopologyNode,
+	currentLevel int,
+) (*TopologyNode, int) {
+	if t.NumAvailable < n || t.freeCoreCpus(parent) < n {
+		return nil, -1
+	}
+	var (
+		bestLevel    *TopologyNode
+		bestLevelNum int
+	)
+	if !t.isCore(parent) {
+		for _, child := range t.Children {
+			level, levelNum := child.findLowestNodeWithEnoughFreeCores(n, t, currentLevel+1)
+			if level != nil && levelNum > bestLevelNum {
+				bestLevel, bestLevelNum = level, levelNum
+			}
+		}
+	}
+	if bestLevel == nil {
+		return t, currentLevel
+	}
+	return bestLevel, bestLevelNum
+}
+
+// freeCores returns cpus of physical cores in the subtree whose all cpus are available, in tree order, until
+// at least n cpus are found.
+func (t *TopologyNode) freeCores(n int, parent *TopologyNode) []int {
+	if t.isCore(parent) {
+		leaves := t.GetLeafs()
+		if t.NumAvailable != len(leaves) {
+			return []int{}
+		}
+		cpuIDs := make([]int, 0, len(leaves))
+		for _, leaf := range leaves {
+			cpuIDs = append(cpuIDs, leaf.Value)
+		}
+		return cpuIDs
+	}
+	cpuIDs := []int{}
+	for _, child := range t.Children {
+		if len(cpuIDs) >= n {
+			break
+		}
+		cpuIDs = append(cpuIDs, child.freeCores(n-len(cpuIDs), t)...)
+	}
+	return cpuIDs
+}
+
 type nodeComparator func(*TopologyNode) bool
 
 func (t *TopologyNode) find(comparator nodeComparator) []*TopologyNode {
@@ -0,0 +1,202 @@
+// Package preflight verifies node prerequisites of the control plane daemon.
+package preflight
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/containerd/cgroups"
+	"resourcemanagement.controlplane/pkg/numautils"
+)
+
+const socketDialTimeout = time.Second
+
+// Cgroup drivers, as named in the daemon options.
+const (
+	DriverSystemd  = "systemd"
+	DriverCgroupfs = "cgroupfs"
+)
+
+// DefaultRuntimeSockets maps container runtimes to their default sockets.
+var DefaultRuntimeSockets = map[string]string{
+	"containerd": "/run/containerd/containerd.sock",
+	"docker":     "/var/run/docker.sock",
+}
+
+// kubepodsCgroups maps kubepods cgroups to cgroup drivers which create them.
+var kubepodsCgroups = []struct {
+	name   string
+	driver string
+}{
+	{"kubepods.slice", DriverSystemd},
+	{"kubepods", DriverCgroupfs},
+	{"kubelet/kubepods", DriverCgroupfs}, // kind nodes
+}
+
+// Config holds daemon settings verified by the checks.
+type Config struct {
+	CgroupPath    string
+	NumaPath      string
+	StatePath     string
+	CgroupDriver  string
+	RuntimeSocket string   // empty skips the runtime check
+	ListenAddrs   []string // tcp addresses of the daemon gRPC server
+	MetricsAddr   string   // empty skips the metrics port check
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Name    string
+	Passed  bool
+	Details string
+}
+
+func pass(name string, format string, args ...any) Result {
+	return Result{Name: name, Passed: true, Details: fmt.Sprintf(format, args...)}
+}
+
+func fail(name string, format string, args ...any) Result {
+	return Result{Name: name, Passed: false, Details: fmt.Sprintf(format, args...)}
+}
+
+// Run executes all checks.
+func Run(c Config) []Result {
+	results := []Result{
+		checkCgroupVersion(cgroups.Mode()),
+		checkCgroupMount(c.CgroupPath, cgroups.Mode()),
+		checkCgroupDriver(c.CgroupPath, cgroups.Mode(), c.CgroupDriver),
+		checkTopology(c.NumaPath),
+		checkStateDir(c.StatePath),
+	}
+	if c.RuntimeSocket != "" {
+		results = append(results, checkRuntimeSocket(c.RuntimeSocket))
+	}
+	for _, addr := range c.ListenAddrs {
+		results = append(results, checkPortFree("daemon port", addr))
+	}
+	if c.MetricsAddr != "" {
+		results = append(results, checkPortFree("metrics port", c.MetricsAddr))
+	}
+	return results
+}
+
+// Report prints results of the checks and returns whether all of them passed.
+func Report(w io.Writer, results []Result) bool {
+	passed := true
+	for _, r := range results {
+		status := "PASS"
+		if !r.Passed {
+			status = "FAIL"
+			passed = false
+		}
+		fmt.Fprintf(w, "[%s] %s: %s\n", status, r.Name, r.Details)
+	}
+	return passed
+}
+
+func checkCgroupVersion(mode cgroups.CGMode) Result {
+	const name = "cgroup version"
+	switch mode {
+	case cgroups.Unified:
+		return pass(name, "cgroups v2")
+	case cgroups.Legacy:
+		return pass(name, "cgroups v1")
+	case cgroups.Hybrid:
+		return pass(name, "cgroups v1 with v2 hierarchy (hybrid), cpusets are managed in v1 hierarchy")
+	default:
+		return fail(name, "cgroup filesystem not found, mount cgroup filesystem on /sys/fs/cgroup")
+	}
+}
+
+// cpusetPaths returns cgroup directory of cpuset controller and its cpuset file.
+func cpusetPaths(cgroupPath string, mode cgroups.CGMode) (string, string) {
+	if mode == cgroups.Unified {
+		return cgroupPath, "cpuset.cpus.effective"
+	}
+	return filepath.Join(cgroupPath, "cpuset"), "cpuset.cpus"
+}
+
+func checkCgroupMount(cgroupPath string, mode cgroups.CGMode) Result {
+	const name = "cgroup mount"
+	dir, file := cpusetPaths(cgroupPath, mode)
+	if _, err := os.ReadFile(filepath.Join(dir, file)); err != nil {
+		return fail(name, "cannot read cpuset of %s, mount host /sys/fs/cgroup and set -cpath: %s", dir, err)
+	}
+	return pass(name, "cpuset controller available in %s", dir)
+}
+
+func checkCgroupDriver(cgroupPath string, mode cgroups.CGMode, driver string) Result {
+	const name = "cgroup driver"
+	dir, _ := cpusetPaths(cgroupPath, mode)
+	for _, kubepods := range kubepodsCgroups {
+		if info, err := os.Stat(filepath.Join(dir, kubepods.name)); err != nil || !info.IsDir() {
+			continue
+		}
+		if kubepods.driver != driver {
+			return fail(
+				name,
+				"kubepods cgroup %s is created by %s driver, but -cgroup-driver is %s",
+				kubepods.name,
+				kubepods.driver,
+				driver,
+			)
+		}
+		return pass(name, "%s, kubepods cgroup %s", driver, kubepods.name)
+	}
+	return fail(name, "kubepods cgroup not found in %s, is kubelet running on the node?", dir)
+}
+
+func checkTopology(numaPath string) Result {
+	const name = "sysfs topology"
+	t := numautils.NumaTopology{}
+	if err := t.Load(numaPath); err != nil {
+		return fail(name, "cannot read topology from %s, mount host sysfs and set -npath: %s", numaPath, err)
+	}
+	return pass(name, "%d cpus read from %s", len(t.CpuInformation), numaPath)
+}
+
+func checkStateDir(statePath string) Result {
+	const name = "state directory"
+	dir := filepath.Dir(statePath)
+	f, err := os.CreateTemp(dir, ".preflight-*")
+	if err != nil {
+		return fail(name, "cannot write to %s, mount writable host directory and set -spath: %s", dir, err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return pass(name, "%s is writable", dir)
+}
+
+func checkRuntimeSocket(socket string) Result {
+	const name = "runtime socket"
+	info, err := os.Stat(socket)
+	if err != nil {
+		return fail(name, "%s not found, check -runtime option: %s", socket, err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fail(name, "%s is not a socket", socket)
+	}
+	conn, err := net.DialTimeout("unix", socket, socketDialTimeout)
+	if err != nil {
+		return fail(name, "cannot connect to %s: %s", socket, err)
+	}
+	conn.Close()
+	return pass(name, "%s accepts connections", socket)
+}
+
+func checkPortFree(name string, addr string) Result {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return fail(name, "no permission to listen on %s: %s", addr, err)
+		}
+		return fail(name, "cannot listen on %s, is another daemon instance running? %s", addr, err)
+	}
+	l.Close()
+	return pass(name, "%s is free", addr)
+}
@@ -0,0 +1,99 @@
+package preflight
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/cgroups"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createCgroupForTest(t *testing.T, kubepods string) string {
+	dir := t.TempDir()
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, kubepods), 0o755))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "cpuset.cpus.effective"), []byte("0-7\n"), 0o600))
+	return dir
+}
+
+func TestCheckCgroupVersion(t *testing.T) {
+	assert.True(t, checkCgroupVersion(cgroups.Unified).Passed)
+	assert.True(t, checkCgroupVersion(cgroups.Legacy).Passed)
+	assert.True(t, checkCgroupVersion(cgroups.Hybrid).Passed)
+	assert.False(t, checkCgroupVersion(cgroups.Unavailable).Passed)
+}
+
+func TestCheckCgroupMount(t *testing.T) {
+	dir := createCgroupForTest(t, "kubepods.slice")
+
+	assert.True(t, checkCgroupMount(dir, cgroups.Unified).Passed)
+	assert.False(t, checkCgroupMount(dir, cgroups.Legacy).Passed)
+	assert.False(t, checkCgroupMount(filepath.Join(dir, "missing"), cgroups.Unified).Passed)
+}
+
+func TestCheckCgroupDriver(t *testing.T) {
+	systemd := createCgroupForTest(t, "kubepods.slice")
+	cgroupfs := createCgroupForTest(t, "kubepods")
+
+	assert.True(t, checkCgroupDriver(systemd, cgroups.Unified, DriverSystemd).Passed)
+	assert.True(t, checkCgroupDriver(cgroupfs, cgroups.Unified, DriverCgroupfs).Passed)
+
+	r := checkCgroupDriver(systemd, cgroups.Unified, DriverCgroupfs)
+	assert.False(t, r.Passed)
+	assert.Contains(t, r.Details, "systemd")
+
+	assert.False(t, checkCgroupDriver(t.TempDir(), cgroups.Unified, DriverSystemd).Passed)
+}
+
+func TestCheckTopology(t *testing.T) {
+	assert.True(t, checkTopology("../cpudaemon/testdata/node_info").Passed)
+	assert.False(t, checkTopology(filepath.Join(t.TempDir(), "missing")).Passed)
+}
+
+func TestCheckStateDir(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.True(t, checkStateDir(filepath.Join(dir, "daemon.state")).Passed)
+	entries, err := os.ReadDir(dir)
+	require.Nil(t, err)
+	assert.Empty(t, entries)
+
+	assert.False(t, checkStateDir(filepath.Join(dir, "missing", "daemon.state")).Passed)
+}
+
+func TestCheckRuntimeSocket(t *testing.T) {
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "runtime.sock")
+	l, err := net.Listen("unix", socket)
+	require.Nil(t, err)
+	defer l.Close()
+	file := filepath.Join(dir, "file")
+	require.Nil(t, os.WriteFile(file, nil, 0o600))
+
+	assert.True(t, checkRuntimeSocket(socket).Passed)
+	assert.False(t, checkRuntimeSocket(file).Passed)
+	assert.False(t, checkRuntimeSocket(filepath.Join(dir, "missing.sock")).Passed)
+}
+
+func TestCheckPortFree(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer l.Close()
+
+	assert.False(t, checkPortFree("daemon port", l.Addr().String()).Passed)
+	assert.True(t, checkPortFree("daemon port", "127.0.0.1:0").Passed)
+}
+
+func TestReport(t *testing.T) {
+	out := bytes.Buffer{}
+
+	assert.True(t, Report(&out, []Result{pass("a", "ok")}))
+	assert.Equal(t, "[PASS] a: ok\n", out.String())
+
+	out.Reset()
+	assert.False(t, Report(&out, []Result{pass("a", "ok"), fail("b", "broken %d", 1)}))
+	assert.Equal(t, "[PASS] a: ok\n[FAIL] b: broken 1\n", out.String())
+}
@@ -0,0 +1,38 @@
+// Package version holds build information of ctlplane binaries, set with -ldflags at build time, eg.
+// -X resourcemanagement.controlplane/pkg/version.Version=0.2.0.
+package version
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Build information, overridden with -ldflags -X by make build.
+var (
+	Version   = "dev"     // release version or git describe output
+	Commit    = "unknown" // git commit the binary was built from
+	BuildDate = "unknown" // build time in RFC 3339 format
+)
+
+// Info describes the build of the running binary.
+type Info struct {
+	Version   string
+	Commit    string
+	BuildDate string
+	GoVersion string // version of go toolchain the binary was built with
+}
+
+// Get returns build information of the running binary.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// String formats build information as printed by -version.
+func (i Info) String() string {
+	return fmt.Sprintf("ctlplane %s (commit %s, built %s, %s)", i.Version, i.Commit, i.BuildDate, i.GoVersion)
+}
@@ -0,0 +1,23 @@
+package version
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	info := Get()
+
+	assert.Equal(t, Version, info.Version)
+	assert.Equal(t, Commit, info.Commit)
+	assert.Equal(t, BuildDate, info.BuildDate)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+}
+
+func TestString(t *testing.T) {
+	info := Info{Version: "0.2.0", Commit: "4f2a1c", BuildDate: "2023-06-01T10:00:00Z", GoVersion: "go1.20"}
+
+	assert.Equal(t, "ctlplane 0.2.0 (commit 4f2a1c, built 2023-06-01T10:00:00Z, go1.20)", info.String())
+}